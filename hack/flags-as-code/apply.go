@@ -0,0 +1,182 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/bucketeer-io/bucketeer/pkg/cli"
+	featurecommand "github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/metrics"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var errPlanHasBlockers = errors.New("flags-as-code: some features can't be reconciled")
+
+type applyCommand struct {
+	*kingpin.CmdClause
+	connectionFlags
+	file    *string
+	comment *string
+}
+
+func registerApplyCommand(r cli.CommandRegistry, p cli.ParentCommand) *applyCommand {
+	cmd := p.Command("apply", "Reconcile the feature targeting and variations of an environment with a file")
+	command := &applyCommand{
+		CmdClause:       cmd,
+		connectionFlags: registerConnectionFlags(cmd),
+		file:            cmd.Flag("file", "Path to the YAML file.").Required().String(),
+		comment:         cmd.Flag("comment", "(optional) Comment saved in the audit logs.").String(),
+	}
+	r.RegisterCommand(command)
+	return command
+}
+
+func (c *applyCommand) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Logger) error {
+	clients, err := newClients(c.connectionFlags, logger)
+	if err != nil {
+		logger.Error("Failed to create clients", zap.Error(err))
+		return err
+	}
+	defer clients.Close()
+	p, err := buildPlan(ctx, clients, *c.file, *c.environmentNamespace)
+	if err != nil {
+		logger.Error("Failed to build the plan", zap.Error(err))
+		return err
+	}
+	if err := p.write(os.Stdout); err != nil {
+		return err
+	}
+	if p.hasBlockers() {
+		logger.Error("Failed to apply the plan", zap.Error(errPlanHasBlockers))
+		return errPlanHasBlockers
+	}
+	desired := make(map[string]*featureproto.Feature, len(p.desired.Features))
+	for _, f := range p.desired.Features {
+		desired[f.Id] = f
+	}
+	for _, fp := range p.features {
+		if !fp.hasCommands() {
+			continue
+		}
+		if err := c.applyFeature(ctx, clients, p, desired[fp.featureID]); err != nil {
+			logger.Error("Failed to apply the feature changes", zap.Error(err), zap.String("featureId", fp.featureID))
+			return err
+		}
+		logger.Info("Feature reconciled", zap.String("featureId", fp.featureID))
+	}
+	return nil
+}
+
+// applyFeature runs the promotion steps against the environment,
+// fetching the feature again after each step because the server generates the IDs of the added variations.
+func (c *applyCommand) applyFeature(
+	ctx context.Context,
+	clients *clients,
+	p *plan,
+	desired *featureproto.Feature,
+) error {
+	env := *c.environmentNamespace
+	promotion, err := c.newPromotion(ctx, clients, p, desired)
+	if err != nil {
+		return err
+	}
+	if commands := featurecommand.PromotionVariationCommands(promotion); len(commands) > 0 {
+		if err := c.updateFeatureVariations(ctx, clients, desired.Id, commands); err != nil {
+			return err
+		}
+		if promotion, err = c.newPromotion(ctx, clients, p, desired); err != nil {
+			return err
+		}
+	}
+	commands, err := featurecommand.PromotionTargetingCommands(promotion)
+	if err != nil {
+		return err
+	}
+	if len(commands) > 0 {
+		packed, err := packCommands(commands)
+		if err != nil {
+			return err
+		}
+		_, err = clients.featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+			Id:                   desired.Id,
+			Commands:             packed,
+			EnvironmentNamespace: env,
+			Comment:              *c.comment,
+		})
+		if err != nil {
+			return err
+		}
+		if promotion, err = c.newPromotion(ctx, clients, p, desired); err != nil {
+			return err
+		}
+	}
+	if commands := featurecommand.PromotionRemoveVariationCommands(promotion); len(commands) > 0 {
+		return c.updateFeatureVariations(ctx, clients, desired.Id, commands)
+	}
+	return nil
+}
+
+func (c *applyCommand) newPromotion(
+	ctx context.Context,
+	clients *clients,
+	p *plan,
+	desired *featureproto.Feature,
+) (*domain.FeaturePromotion, error) {
+	current, err := clients.getFeature(ctx, desired.Id, *c.environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewFeaturePromotion(desired, &domain.Feature{Feature: current}, p.segmentIDs, p.variationIDs), nil
+}
+
+func (c *applyCommand) updateFeatureVariations(
+	ctx context.Context,
+	clients *clients,
+	id string,
+	commands []featurecommand.Command,
+) error {
+	packed, err := packCommands(commands)
+	if err != nil {
+		return err
+	}
+	_, err = clients.featureClient.UpdateFeatureVariations(ctx, &featureproto.UpdateFeatureVariationsRequest{
+		Id:                   id,
+		Commands:             packed,
+		EnvironmentNamespace: *c.environmentNamespace,
+		Comment:              *c.comment,
+	})
+	return err
+}
+
+func packCommands(commands []featurecommand.Command) ([]*featureproto.Command, error) {
+	packed := make([]*featureproto.Command, 0, len(commands))
+	for _, cmd := range commands {
+		any, err := ptypes.MarshalAny(cmd.(proto.Message))
+		if err != nil {
+			return nil, err
+		}
+		packed = append(packed, &featureproto.Command{Command: any})
+	}
+	return packed, nil
+}
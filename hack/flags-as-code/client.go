@@ -0,0 +1,236 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+
+	autoopsclient "github.com/bucketeer-io/bucketeer/pkg/autoops/client"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/rpc/client"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const listRequestSize = 500
+
+type connectionFlags struct {
+	certPath             *string
+	serviceTokenPath     *string
+	webGatewayAddress    *string
+	environmentNamespace *string
+}
+
+func registerConnectionFlags(cmd *kingpin.CmdClause) connectionFlags {
+	return connectionFlags{
+		certPath:          cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
+		serviceTokenPath:  cmd.Flag("service-token", "Path to service token file.").Required().String(),
+		webGatewayAddress: cmd.Flag("web-gateway", "Address of web-gateway.").Required().String(),
+		environmentNamespace: cmd.Flag(
+			"environment-namespace",
+			"Environment namespace of the flags.",
+		).Required().String(),
+	}
+}
+
+type clients struct {
+	featureClient    featureclient.Client
+	experimentClient experimentclient.Client
+	autoOpsClient    autoopsclient.Client
+}
+
+func newClients(f connectionFlags, logger *zap.Logger) (*clients, error) {
+	creds, err := client.NewPerRPCCredentials(*f.serviceTokenPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Option{
+		client.WithPerRPCCredentials(creds),
+		client.WithDialTimeout(10 * time.Second),
+		client.WithBlock(),
+		client.WithLogger(logger),
+	}
+	featureClient, err := featureclient.NewClient(*f.webGatewayAddress, *f.certPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	experimentClient, err := experimentclient.NewClient(*f.webGatewayAddress, *f.certPath, opts...)
+	if err != nil {
+		featureClient.Close()
+		return nil, err
+	}
+	autoOpsClient, err := autoopsclient.NewClient(*f.webGatewayAddress, *f.certPath, opts...)
+	if err != nil {
+		featureClient.Close()
+		experimentClient.Close()
+		return nil, err
+	}
+	return &clients{
+		featureClient:    featureClient,
+		experimentClient: experimentClient,
+		autoOpsClient:    autoOpsClient,
+	}, nil
+}
+
+func (c *clients) Close() {
+	c.featureClient.Close()
+	c.experimentClient.Close()
+	c.autoOpsClient.Close()
+}
+
+// getState fetches the current state of the environment.
+func (c *clients) getState(ctx context.Context, environmentNamespace string) (*state, error) {
+	features, err := c.listFeatures(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := c.listSegments(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := c.listGoals(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	autoOpsRules, err := c.listAutoOpsRules(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	s := &state{
+		EnvironmentNamespace: environmentNamespace,
+		Features:             features,
+		Segments:             segments,
+		Goals:                goals,
+		AutoOpsRules:         autoOpsRules,
+	}
+	s.normalize()
+	return s, nil
+}
+
+func (c *clients) getFeature(
+	ctx context.Context,
+	id, environmentNamespace string,
+) (*featureproto.Feature, error) {
+	resp, err := c.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   id,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Feature, nil
+}
+
+func (c *clients) listFeatures(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Feature, error) {
+	features := []*featureproto.Feature{}
+	cursor := ""
+	for {
+		resp, err := c.featureClient.ListFeatures(ctx, &featureproto.ListFeaturesRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, resp.Features...)
+		size := len(resp.Features)
+		if size == 0 || size < listRequestSize {
+			return features, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (c *clients) listSegments(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Segment, error) {
+	segments := []*featureproto.Segment{}
+	cursor := ""
+	for {
+		resp, err := c.featureClient.ListSegments(ctx, &featureproto.ListSegmentsRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, resp.Segments...)
+		size := len(resp.Segments)
+		if size == 0 || size < listRequestSize {
+			return segments, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (c *clients) listGoals(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*experimentproto.Goal, error) {
+	goals := []*experimentproto.Goal{}
+	cursor := ""
+	for {
+		resp, err := c.experimentClient.ListGoals(ctx, &experimentproto.ListGoalsRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, resp.Goals...)
+		size := len(resp.Goals)
+		if size == 0 || size < listRequestSize {
+			return goals, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (c *clients) listAutoOpsRules(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*autoopsproto.AutoOpsRule, error) {
+	rules := []*autoopsproto.AutoOpsRule{}
+	cursor := ""
+	for {
+		resp, err := c.autoOpsClient.ListAutoOpsRules(ctx, &autoopsproto.ListAutoOpsRulesRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, resp.AutoOpsRules...)
+		size := len(resp.AutoOpsRules)
+		if size == 0 || size < listRequestSize {
+			return rules, nil
+		}
+		cursor = resp.Cursor
+	}
+}
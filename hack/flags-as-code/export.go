@@ -0,0 +1,68 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/bucketeer-io/bucketeer/pkg/cli"
+	"github.com/bucketeer-io/bucketeer/pkg/metrics"
+)
+
+type exportCommand struct {
+	*kingpin.CmdClause
+	connectionFlags
+	file *string
+}
+
+func registerExportCommand(r cli.CommandRegistry, p cli.ParentCommand) *exportCommand {
+	cmd := p.Command("export", "Export the features, segments, goals and auto ops rules of an environment")
+	command := &exportCommand{
+		CmdClause:       cmd,
+		connectionFlags: registerConnectionFlags(cmd),
+		file:            cmd.Flag("file", "Path to the YAML file to write.").Required().String(),
+	}
+	r.RegisterCommand(command)
+	return command
+}
+
+func (c *exportCommand) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Logger) error {
+	clients, err := newClients(c.connectionFlags, logger)
+	if err != nil {
+		logger.Error("Failed to create clients", zap.Error(err))
+		return err
+	}
+	defer clients.Close()
+	s, err := clients.getState(ctx, *c.environmentNamespace)
+	if err != nil {
+		logger.Error("Failed to get the environment state", zap.Error(err))
+		return err
+	}
+	if err := saveState(*c.file, s); err != nil {
+		logger.Error("Failed to write the file", zap.Error(err), zap.String("file", *c.file))
+		return err
+	}
+	logger.Info("Environment exported",
+		zap.String("file", *c.file),
+		zap.Int("features", len(s.Features)),
+		zap.Int("segments", len(s.Segments)),
+		zap.Int("goals", len(s.Goals)),
+		zap.Int("autoOpsRules", len(s.AutoOpsRules)),
+	)
+	return nil
+}
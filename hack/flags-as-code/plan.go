@@ -0,0 +1,381 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/bucketeer-io/bucketeer/pkg/cli"
+	featurecommand "github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/metrics"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var errEnvironmentMismatch = fmt.Errorf("flags-as-code: the file doesn't belong to the environment")
+
+type planCommand struct {
+	*kingpin.CmdClause
+	connectionFlags
+	file *string
+}
+
+func registerPlanCommand(r cli.CommandRegistry, p cli.ParentCommand) *planCommand {
+	cmd := p.Command("plan", "Show the changes needed to reconcile the environment with a file")
+	command := &planCommand{
+		CmdClause:       cmd,
+		connectionFlags: registerConnectionFlags(cmd),
+		file:            cmd.Flag("file", "Path to the YAML file.").Required().String(),
+	}
+	r.RegisterCommand(command)
+	return command
+}
+
+func (c *planCommand) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Logger) error {
+	clients, err := newClients(c.connectionFlags, logger)
+	if err != nil {
+		logger.Error("Failed to create clients", zap.Error(err))
+		return err
+	}
+	defer clients.Close()
+	p, err := buildPlan(ctx, clients, *c.file, *c.environmentNamespace)
+	if err != nil {
+		logger.Error("Failed to build the plan", zap.Error(err))
+		return err
+	}
+	return p.write(os.Stdout)
+}
+
+// plan describes how the environment differs from the desired state.
+// Only the feature targeting and variations are reconciled by apply.
+// Everything else is reported as drift, and the entities that only exist in the environment
+// are reported as deletions, so they have to be handled manually.
+type plan struct {
+	desired      *state
+	features     []*featurePlan
+	drifts       []string
+	deletions    []string
+	segmentIDs   map[string]string
+	variationIDs map[string]string
+}
+
+type featurePlan struct {
+	featureID               string
+	variationCommands       []featurecommand.Command
+	targetingCommands       []featurecommand.Command
+	removeVariationCommands []featurecommand.Command
+	drifts                  []string
+	// blocker is set when the commands can't be computed.
+	blocker string
+}
+
+func (p *featurePlan) hasCommands() bool {
+	return len(p.variationCommands) > 0 || len(p.targetingCommands) > 0 || len(p.removeVariationCommands) > 0
+}
+
+func buildPlan(ctx context.Context, clients *clients, path, environmentNamespace string) (*plan, error) {
+	desired, err := loadState(path)
+	if err != nil {
+		return nil, err
+	}
+	if desired.EnvironmentNamespace != environmentNamespace {
+		return nil, errEnvironmentMismatch
+	}
+	current, err := clients.getState(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return newPlan(ctx, desired, current)
+}
+
+func newPlan(ctx context.Context, desired, current *state) (*plan, error) {
+	p := &plan{
+		desired:      desired,
+		segmentIDs:   make(map[string]string, len(current.Segments)),
+		variationIDs: map[string]string{},
+	}
+	// The files use the IDs of the environment, so the references are mapped to themselves
+	// when they still exist.
+	for _, s := range current.Segments {
+		p.segmentIDs[s.Id] = s.Id
+	}
+	currentFeatures := make(map[string]*featureproto.Feature, len(current.Features))
+	for _, f := range current.Features {
+		currentFeatures[f.Id] = f
+		for _, v := range f.Variations {
+			p.variationIDs[v.Id] = v.Id
+		}
+	}
+	desiredIDs := make(map[string]bool, len(desired.Features))
+	for _, f := range desired.Features {
+		desiredIDs[f.Id] = true
+		cur, ok := currentFeatures[f.Id]
+		if !ok {
+			p.drifts = append(p.drifts, fmt.Sprintf("feature %q doesn't exist in the environment", f.Id))
+			continue
+		}
+		fp, err := p.newFeaturePlan(ctx, f, cur, desired.EnvironmentNamespace)
+		if err != nil {
+			return nil, err
+		}
+		p.features = append(p.features, fp)
+	}
+	for _, f := range current.Features {
+		if !desiredIDs[f.Id] {
+			p.deletions = append(p.deletions, fmt.Sprintf("feature %q", f.Id))
+		}
+	}
+	p.diffEntities("segment", segmentMessages(desired.Segments), segmentMessages(current.Segments))
+	p.diffEntities("goal", goalMessages(desired.Goals), goalMessages(current.Goals))
+	p.diffEntities(
+		"auto ops rule",
+		autoOpsRuleMessages(desired.AutoOpsRules),
+		autoOpsRuleMessages(current.AutoOpsRules),
+	)
+	return p, nil
+}
+
+// newFeaturePlan simulates the three promotion steps on a copy of the current feature
+// to compute every command that apply will send.
+func (p *plan) newFeaturePlan(
+	ctx context.Context,
+	desired, current *featureproto.Feature,
+	environmentNamespace string,
+) (*featurePlan, error) {
+	fp := &featurePlan{
+		featureID: desired.Id,
+		drifts:    featureDetailDrifts(desired, current),
+	}
+	target := &domain.Feature{Feature: proto.Clone(current).(*featureproto.Feature)}
+	promotion := domain.NewFeaturePromotion(desired, target, p.segmentIDs, p.variationIDs)
+	if ids := promotion.UnmappedSegmentIDs(); len(ids) > 0 {
+		fp.blocker = fmt.Sprintf("unknown segments %v", ids)
+		return fp, nil
+	}
+	if ids := promotion.UnmappedPrerequisiteIDs(); len(ids) > 0 {
+		fp.blocker = fmt.Sprintf("unknown prerequisite variations of %v", ids)
+		return fp, nil
+	}
+	handler := featurecommand.NewFeatureCommandHandler(&eventproto.Editor{}, target, environmentNamespace, "")
+	fp.variationCommands = featurecommand.PromotionVariationCommands(promotion)
+	if err := handleCommands(ctx, handler, fp.variationCommands); err != nil {
+		return nil, err
+	}
+	targetingCommands, err := featurecommand.PromotionTargetingCommands(promotion)
+	if err != nil {
+		return nil, err
+	}
+	fp.targetingCommands = targetingCommands
+	if err := handleCommands(ctx, handler, fp.targetingCommands); err != nil {
+		return nil, err
+	}
+	fp.removeVariationCommands = featurecommand.PromotionRemoveVariationCommands(promotion)
+	return fp, nil
+}
+
+func handleCommands(
+	ctx context.Context,
+	handler *featurecommand.FeatureCommandHandler,
+	commands []featurecommand.Command,
+) error {
+	for _, cmd := range commands {
+		if err := handler.Handle(ctx, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// featureDetailDrifts reports the differences that can't be reconciled
+// through the targeting and variations commands.
+func featureDetailDrifts(desired, current *featureproto.Feature) []string {
+	drifts := []string{}
+	if desired.Name != current.Name {
+		drifts = append(drifts, fmt.Sprintf("name: %q != %q", current.Name, desired.Name))
+	}
+	if desired.Description != current.Description {
+		drifts = append(drifts, fmt.Sprintf("description: %q != %q", current.Description, desired.Description))
+	}
+	if desired.Enabled != current.Enabled {
+		drifts = append(drifts, fmt.Sprintf("enabled: %t != %t", current.Enabled, desired.Enabled))
+	}
+	if desired.Archived != current.Archived {
+		drifts = append(drifts, fmt.Sprintf("archived: %t != %t", current.Archived, desired.Archived))
+	}
+	if desired.Maintainer != current.Maintainer {
+		drifts = append(drifts, fmt.Sprintf("maintainer: %q != %q", current.Maintainer, desired.Maintainer))
+	}
+	if desired.VariationType != current.VariationType {
+		drifts = append(drifts, fmt.Sprintf("variation type: %s != %s", current.VariationType, desired.VariationType))
+	}
+	if desired.Kind != current.Kind {
+		drifts = append(drifts, fmt.Sprintf("kind: %s != %s", current.Kind, desired.Kind))
+	}
+	if desired.ExpectedRemovalAt != current.ExpectedRemovalAt {
+		drifts = append(drifts, fmt.Sprintf(
+			"expected removal at: %d != %d",
+			current.ExpectedRemovalAt,
+			desired.ExpectedRemovalAt,
+		))
+	}
+	desiredTags := append([]string{}, desired.Tags...)
+	currentTags := append([]string{}, current.Tags...)
+	sort.Strings(desiredTags)
+	sort.Strings(currentTags)
+	if fmt.Sprint(desiredTags) != fmt.Sprint(currentTags) {
+		drifts = append(drifts, fmt.Sprintf("tags: %v != %v", currentTags, desiredTags))
+	}
+	return drifts
+}
+
+type identifiable interface {
+	proto.Message
+	GetId() string
+}
+
+func segmentMessages(segments []*featureproto.Segment) []identifiable {
+	messages := make([]identifiable, 0, len(segments))
+	for _, s := range segments {
+		messages = append(messages, s)
+	}
+	return messages
+}
+
+func goalMessages(goals []*experimentproto.Goal) []identifiable {
+	messages := make([]identifiable, 0, len(goals))
+	for _, g := range goals {
+		messages = append(messages, g)
+	}
+	return messages
+}
+
+func autoOpsRuleMessages(rules []*autoopsproto.AutoOpsRule) []identifiable {
+	messages := make([]identifiable, 0, len(rules))
+	for _, r := range rules {
+		messages = append(messages, r)
+	}
+	return messages
+}
+
+func (p *plan) diffEntities(kind string, desired, current []identifiable) {
+	currentByID := make(map[string]identifiable, len(current))
+	for _, c := range current {
+		currentByID[c.GetId()] = c
+	}
+	desiredIDs := make(map[string]bool, len(desired))
+	for _, d := range desired {
+		desiredIDs[d.GetId()] = true
+		c, ok := currentByID[d.GetId()]
+		if !ok {
+			p.drifts = append(p.drifts, fmt.Sprintf("%s %q doesn't exist in the environment", kind, d.GetId()))
+			continue
+		}
+		if !proto.Equal(d, c) {
+			p.drifts = append(p.drifts, fmt.Sprintf("%s %q differs from the environment", kind, d.GetId()))
+		}
+	}
+	for _, c := range current {
+		if !desiredIDs[c.GetId()] {
+			p.deletions = append(p.deletions, fmt.Sprintf("%s %q", kind, c.GetId()))
+		}
+	}
+}
+
+func (p *plan) hasBlockers() bool {
+	for _, fp := range p.features {
+		if fp.blocker != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *plan) write(w io.Writer) error {
+	changed := 0
+	for _, fp := range p.features {
+		if !fp.hasCommands() && len(fp.drifts) == 0 && fp.blocker == "" {
+			continue
+		}
+		changed++
+		fmt.Fprintf(w, "feature %q:\n", fp.featureID)
+		if fp.blocker != "" {
+			fmt.Fprintf(w, "  ! can't be reconciled: %s\n", fp.blocker)
+		}
+		if err := writeCommands(w, "UpdateFeatureVariations", fp.variationCommands); err != nil {
+			return err
+		}
+		if err := writeCommands(w, "UpdateFeatureTargeting", fp.targetingCommands); err != nil {
+			return err
+		}
+		if err := writeCommands(w, "UpdateFeatureVariations", fp.removeVariationCommands); err != nil {
+			return err
+		}
+		for _, d := range fp.drifts {
+			fmt.Fprintf(w, "  ~ drift (not applied): %s\n", d)
+		}
+	}
+	if len(p.features) > 0 && hasAddedVariations(p.features) {
+		fmt.Fprintln(w, "The IDs of the added variations are generated by the server when applying.")
+	}
+	for _, d := range p.drifts {
+		fmt.Fprintf(w, "~ drift (not applied): %s\n", d)
+	}
+	for _, d := range p.deletions {
+		fmt.Fprintf(w, "- deletion (not applied): %s only exists in the environment\n", d)
+	}
+	if changed == 0 && len(p.drifts) == 0 && len(p.deletions) == 0 {
+		fmt.Fprintln(w, "No changes. The environment matches the file.")
+	}
+	return nil
+}
+
+func hasAddedVariations(features []*featurePlan) bool {
+	for _, fp := range features {
+		for _, cmd := range fp.variationCommands {
+			if _, ok := cmd.(*featureproto.AddVariationCommand); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeCommands(w io.Writer, rpc string, commands []featurecommand.Command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "  %s:\n", rpc)
+	for _, cmd := range commands {
+		m := cmd.(proto.Message)
+		data, err := protojson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "    + %s %s\n", m.ProtoReflect().Descriptor().Name(), data)
+	}
+	return nil
+}
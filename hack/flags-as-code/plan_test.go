@@ -0,0 +1,192 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	featurecommand "github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func newPlanTestFeature(id string) *featureproto.Feature {
+	return &featureproto.Feature{
+		Id:   id,
+		Name: "name",
+		Variations: []*featureproto.Variation{
+			{Id: id + "-a", Value: "a", Name: "a"},
+			{Id: id + "-b", Value: "b", Name: "b"},
+		},
+		Targets: []*featureproto.Target{
+			{Variation: id + "-a", Users: []string{}},
+			{Variation: id + "-b", Users: []string{}},
+		},
+		Rules: []*featureproto.Rule{},
+		DefaultStrategy: &featureproto.Strategy{
+			Type:          featureproto.Strategy_FIXED,
+			FixedStrategy: &featureproto.FixedStrategy{Variation: id + "-a"},
+		},
+		OffVariation: id + "-b",
+		Tags:         []string{"t0"},
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		desc                      string
+		desired                   func() *state
+		current                   func() *state
+		expectedTargetingCommands []featurecommand.Command
+		expectedFeatureDrifts     []string
+		expectedDrifts            []string
+		expectedDeletions         []string
+	}{
+		{
+			desc: "no-op",
+			desired: func() *state {
+				return &state{
+					Features: []*featureproto.Feature{newPlanTestFeature("f0")},
+					Segments: []*featureproto.Segment{{Id: "s0", Name: "segment"}},
+				}
+			},
+			current: func() *state {
+				return &state{
+					Features: []*featureproto.Feature{newPlanTestFeature("f0")},
+					Segments: []*featureproto.Segment{{Id: "s0", Name: "segment"}},
+				}
+			},
+			expectedTargetingCommands: []featurecommand.Command{},
+			expectedFeatureDrifts:     []string{},
+		},
+		{
+			desc: "update: feature targeting and segment",
+			desired: func() *state {
+				f := newPlanTestFeature("f0")
+				f.Targets[1].Users = []string{"user-0"}
+				return &state{
+					Features: []*featureproto.Feature{f},
+					Segments: []*featureproto.Segment{{Id: "s0", Name: "renamed"}},
+				}
+			},
+			current: func() *state {
+				return &state{
+					Features: []*featureproto.Feature{newPlanTestFeature("f0")},
+					Segments: []*featureproto.Segment{{Id: "s0", Name: "segment"}},
+				}
+			},
+			expectedTargetingCommands: []featurecommand.Command{
+				&featureproto.AddUserToVariationCommand{Id: "f0-b", User: "user-0"},
+			},
+			expectedFeatureDrifts: []string{},
+			expectedDrifts:        []string{`segment "s0" differs from the environment`},
+		},
+		{
+			desc: "update: feature details are only reported",
+			desired: func() *state {
+				f := newPlanTestFeature("f0")
+				f.Name = "renamed"
+				f.Tags = []string{"t1"}
+				return &state{Features: []*featureproto.Feature{f}}
+			},
+			current: func() *state {
+				return &state{Features: []*featureproto.Feature{newPlanTestFeature("f0")}}
+			},
+			expectedTargetingCommands: []featurecommand.Command{},
+			expectedFeatureDrifts:     []string{`name: "name" != "renamed"`, "tags: [t0] != [t1]"},
+		},
+		{
+			desc: "create: entities missing in the environment",
+			desired: func() *state {
+				return &state{
+					Features: []*featureproto.Feature{newPlanTestFeature("f0")},
+					Segments: []*featureproto.Segment{{Id: "s0"}},
+				}
+			},
+			current: func() *state {
+				return &state{}
+			},
+			expectedDrifts: []string{
+				`feature "f0" doesn't exist in the environment`,
+				`segment "s0" doesn't exist in the environment`,
+			},
+		},
+		{
+			desc: "delete: entities only in the environment",
+			desired: func() *state {
+				return &state{}
+			},
+			current: func() *state {
+				return &state{
+					Features: []*featureproto.Feature{newPlanTestFeature("f0")},
+					Segments: []*featureproto.Segment{{Id: "s0"}},
+				}
+			},
+			expectedDeletions: []string{`feature "f0"`, `segment "s0"`},
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			current := p.current()
+			snapshot := proto.Clone(&featureproto.ListFeaturesResponse{Features: current.Features})
+			actual, err := newPlan(context.Background(), p.desired(), current)
+			require.NoError(t, err)
+			assert.Equal(t, p.expectedDrifts, actual.drifts)
+			assert.Equal(t, p.expectedDeletions, actual.deletions)
+			if p.expectedTargetingCommands == nil {
+				assert.Empty(t, actual.features)
+			} else {
+				require.Len(t, actual.features, 1)
+				assert.Equal(t, p.expectedTargetingCommands, actual.features[0].targetingCommands)
+				assert.Equal(t, p.expectedFeatureDrifts, actual.features[0].drifts)
+				assert.Empty(t, actual.features[0].blocker)
+			}
+			// The plan is computed on copies, so the environment is left as it is.
+			assert.True(t, proto.Equal(snapshot, &featureproto.ListFeaturesResponse{Features: current.Features}))
+		})
+	}
+}
+
+func TestNewPlanBlocker(t *testing.T) {
+	t.Parallel()
+	desired := newPlanTestFeature("f0")
+	desired.Rules = []*featureproto.Rule{
+		{
+			Id: "rule-0",
+			Strategy: &featureproto.Strategy{
+				Type:          featureproto.Strategy_FIXED,
+				FixedStrategy: &featureproto.FixedStrategy{Variation: "f0-b"},
+			},
+			Clauses: []*featureproto.Clause{
+				{Id: "clause-0", Operator: featureproto.Clause_SEGMENT, Values: []string{"unknown"}},
+			},
+		},
+	}
+	actual, err := newPlan(
+		context.Background(),
+		&state{Features: []*featureproto.Feature{desired}},
+		&state{Features: []*featureproto.Feature{newPlanTestFeature("f0")}},
+	)
+	require.NoError(t, err)
+	require.Len(t, actual.features, 1)
+	assert.Equal(t, "unknown segments [unknown]", actual.features[0].blocker)
+	assert.False(t, actual.features[0].hasCommands())
+	assert.True(t, actual.hasBlockers())
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	yaml "gopkg.in/yaml.v2"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// state is the versionable representation of an environment.
+type state struct {
+	EnvironmentNamespace string
+	Features             []*featureproto.Feature
+	Segments             []*featureproto.Segment
+	Goals                []*experimentproto.Goal
+	AutoOpsRules         []*autoopsproto.AutoOpsRule
+}
+
+// document is the YAML layout of the state.
+// Each entity is stored using the JSON mapping of its proto message.
+type document struct {
+	EnvironmentNamespace string        `yaml:"environmentNamespace"`
+	Features             []interface{} `yaml:"features"`
+	Segments             []interface{} `yaml:"segments"`
+	Goals                []interface{} `yaml:"goals"`
+	AutoOpsRules         []interface{} `yaml:"autoOpsRules"`
+}
+
+// normalize clears the fields managed by the server so the exported files only change
+// when the configuration changes, and sorts the entities by ID.
+func (s *state) normalize() {
+	for _, f := range s.Features {
+		f.Version = 0
+		f.CreatedAt = 0
+		f.UpdatedAt = 0
+		f.LastUsedInfo = nil
+		f.EvaluationUndelayable = false // nolint:staticcheck
+	}
+	for _, sg := range s.Segments {
+		sg.CreatedAt = 0
+		sg.UpdatedAt = 0
+		sg.Version = 0 // nolint:staticcheck
+		sg.IncludedUserCount = 0
+		sg.ExcludedUserCount = 0 // nolint:staticcheck
+		sg.Status = featureproto.Segment_INITIAL
+		sg.IsInUseStatus = false
+	}
+	for _, g := range s.Goals {
+		g.CreatedAt = 0
+		g.UpdatedAt = 0
+		g.IsInUseStatus = false
+	}
+	for _, r := range s.AutoOpsRules {
+		r.TriggeredAt = 0
+		r.CreatedAt = 0
+		r.UpdatedAt = 0
+	}
+	sort.SliceStable(s.Features, func(i, j int) bool { return s.Features[i].Id < s.Features[j].Id })
+	sort.SliceStable(s.Segments, func(i, j int) bool { return s.Segments[i].Id < s.Segments[j].Id })
+	sort.SliceStable(s.Goals, func(i, j int) bool { return s.Goals[i].Id < s.Goals[j].Id })
+	sort.SliceStable(s.AutoOpsRules, func(i, j int) bool { return s.AutoOpsRules[i].Id < s.AutoOpsRules[j].Id })
+}
+
+func (s *state) marshalYAML() ([]byte, error) {
+	doc := &document{EnvironmentNamespace: s.EnvironmentNamespace}
+	for _, f := range s.Features {
+		v, err := marshalMessage(f)
+		if err != nil {
+			return nil, err
+		}
+		doc.Features = append(doc.Features, v)
+	}
+	for _, sg := range s.Segments {
+		v, err := marshalMessage(sg)
+		if err != nil {
+			return nil, err
+		}
+		doc.Segments = append(doc.Segments, v)
+	}
+	for _, g := range s.Goals {
+		v, err := marshalMessage(g)
+		if err != nil {
+			return nil, err
+		}
+		doc.Goals = append(doc.Goals, v)
+	}
+	for _, r := range s.AutoOpsRules {
+		v, err := marshalMessage(r)
+		if err != nil {
+			return nil, err
+		}
+		doc.AutoOpsRules = append(doc.AutoOpsRules, v)
+	}
+	return yaml.Marshal(doc)
+}
+
+func unmarshalYAML(data []byte) (*state, error) {
+	doc := &document{}
+	if err := yaml.UnmarshalStrict(data, doc); err != nil {
+		return nil, err
+	}
+	s := &state{EnvironmentNamespace: doc.EnvironmentNamespace}
+	for _, v := range doc.Features {
+		f := &featureproto.Feature{}
+		if err := unmarshalMessage(v, f); err != nil {
+			return nil, err
+		}
+		s.Features = append(s.Features, f)
+	}
+	for _, v := range doc.Segments {
+		sg := &featureproto.Segment{}
+		if err := unmarshalMessage(v, sg); err != nil {
+			return nil, err
+		}
+		s.Segments = append(s.Segments, sg)
+	}
+	for _, v := range doc.Goals {
+		g := &experimentproto.Goal{}
+		if err := unmarshalMessage(v, g); err != nil {
+			return nil, err
+		}
+		s.Goals = append(s.Goals, g)
+	}
+	for _, v := range doc.AutoOpsRules {
+		r := &autoopsproto.AutoOpsRule{}
+		if err := unmarshalMessage(v, r); err != nil {
+			return nil, err
+		}
+		s.AutoOpsRules = append(s.AutoOpsRules, r)
+	}
+	s.normalize()
+	return s, nil
+}
+
+func loadState(path string) (*state, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalYAML(data)
+}
+
+func saveState(path string, s *state) error {
+	data, err := s.marshalYAML()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func marshalMessage(m proto.Message) (interface{}, error) {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func unmarshalMessage(v interface{}, m proto.Message) error {
+	converted, err := convertYAMLValue(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(converted)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, m)
+}
+
+// convertYAMLValue converts the maps decoded by yaml.v2 into maps that can be encoded to JSON.
+func convertYAMLValue(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid key: %v", key)
+			}
+			converted, err := convertYAMLValue(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		values := make([]interface{}, 0, len(t))
+		for _, value := range t {
+			converted, err := convertYAMLValue(value)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	default:
+		return v, nil
+	}
+}
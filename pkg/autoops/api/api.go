@@ -33,6 +33,7 @@ import (
 	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
 	v2as "github.com/bucketeer-io/bucketeer/pkg/autoops/storage/v2"
 	"github.com/bucketeer-io/bucketeer/pkg/crypto"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
 	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
@@ -48,7 +49,18 @@ import (
 	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
 )
 
-var errAlreadyTriggered = errors.New("auto ops Rule has already triggered")
+const (
+	// maxSustainedEvaluations bounds the history read on each assessment of a rule.
+	maxSustainedEvaluations = int32(100)
+
+	defaultScheduleTransitionsLimit = 10
+	maxScheduleTransitionsLimit     = int32(100)
+)
+
+var (
+	errAlreadyTriggered = errors.New("auto ops Rule has already triggered")
+	errShadowRule       = errors.New("auto ops Rule is in shadow mode")
+)
 
 type options struct {
 	logger *zap.Logger
@@ -63,22 +75,24 @@ func WithLogger(l *zap.Logger) Option {
 }
 
 type AutoOpsService struct {
-	mysqlClient       mysql.Client
-	featureClient     featureclient.Client
-	experimentClient  experimentclient.Client
-	accountClient     accountclient.Client
-	authClient        authclient.Client
-	publisher         publisher.Publisher
-	webhookBaseURL    *url.URL
-	webhookCryptoUtil crypto.EncrypterDecrypter
-	opts              *options
-	logger            *zap.Logger
+	mysqlClient        mysql.Client
+	featureClient      featureclient.Client
+	experimentClient   experimentclient.Client
+	eventCounterClient ecclient.Client
+	accountClient      accountclient.Client
+	authClient         authclient.Client
+	publisher          publisher.Publisher
+	webhookBaseURL     *url.URL
+	webhookCryptoUtil  crypto.EncrypterDecrypter
+	opts               *options
+	logger             *zap.Logger
 }
 
 func NewAutoOpsService(
 	mysqlClient mysql.Client,
 	featureClient featureclient.Client,
 	experimentClient experimentclient.Client,
+	eventCounterClient ecclient.Client,
 	accountClient accountclient.Client,
 	authClient authclient.Client,
 	publisher publisher.Publisher,
@@ -93,16 +107,17 @@ func NewAutoOpsService(
 		opt(dopts)
 	}
 	return &AutoOpsService{
-		mysqlClient:       mysqlClient,
-		featureClient:     featureClient,
-		experimentClient:  experimentClient,
-		accountClient:     accountClient,
-		authClient:        authClient,
-		publisher:         publisher,
-		webhookBaseURL:    webhookBaseURL,
-		opts:              dopts,
-		webhookCryptoUtil: webhookCryptoUtil,
-		logger:            dopts.logger.Named("api"),
+		mysqlClient:        mysqlClient,
+		featureClient:      featureClient,
+		experimentClient:   experimentClient,
+		eventCounterClient: eventCounterClient,
+		accountClient:      accountClient,
+		authClient:         authClient,
+		publisher:          publisher,
+		webhookBaseURL:     webhookBaseURL,
+		opts:               dopts,
+		webhookCryptoUtil:  webhookCryptoUtil,
+		logger:             dopts.logger.Named("api"),
 	}
 }
 
@@ -121,12 +136,25 @@ func (s *AutoOpsService) CreateAutoOpsRule(
 	if err := s.validateCreateAutoOpsRuleRequest(req); err != nil {
 		return nil, err
 	}
+	opsAction, err := s.newOpsAction(
+		ctx,
+		req.EnvironmentNamespace,
+		req.Command.FeatureId,
+		req.Command.OpsType,
+		req.Command.OpsAction,
+	)
+	if err != nil {
+		return nil, err
+	}
 	autoOpsRule, err := domain.NewAutoOpsRule(
 		req.Command.FeatureId,
 		req.Command.OpsType,
+		opsAction,
 		req.Command.OpsEventRateClauses,
 		req.Command.DatetimeClauses,
 		req.Command.WebhookClauses,
+		req.Command.PrometheusClauses,
+		req.Command.ScheduleClauses,
 	)
 	if err != nil {
 		s.logger.Error(
@@ -138,6 +166,12 @@ func (s *AutoOpsService) CreateAutoOpsRule(
 		)
 		return nil, localizedError(statusInternal, locale.JaJP)
 	}
+	autoOpsRule.SetTriggerSettings(
+		req.Command.ClauseOperator,
+		req.Command.CooldownSeconds,
+		req.Command.SustainedEvaluations,
+	)
+	autoOpsRule.SetShadow(req.Command.Shadow)
 	opsEventRateClauses, err := autoOpsRule.ExtractOpsEventRateClauses()
 	if err != nil {
 		s.logger.Error(
@@ -203,7 +237,11 @@ func (s *AutoOpsService) validateCreateAutoOpsRuleRequest(req *autoopsproto.Crea
 	if req.Command.FeatureId == "" {
 		return localizedError(statusFeatureIDRequired, locale.JaJP)
 	}
-	if len(req.Command.OpsEventRateClauses) == 0 && len(req.Command.DatetimeClauses) == 0 && len(req.Command.WebhookClauses) == 0 {
+	if len(req.Command.OpsEventRateClauses) == 0 &&
+		len(req.Command.DatetimeClauses) == 0 &&
+		len(req.Command.WebhookClauses) == 0 &&
+		len(req.Command.PrometheusClauses) == 0 &&
+		len(req.Command.ScheduleClauses) == 0 {
 		return localizedError(statusClauseRequired, locale.JaJP)
 	}
 	if req.Command.OpsType == autoopsproto.OpsType_ENABLE_FEATURE && len(req.Command.OpsEventRateClauses) > 0 {
@@ -218,6 +256,103 @@ func (s *AutoOpsService) validateCreateAutoOpsRuleRequest(req *autoopsproto.Crea
 	if err := s.validateWebhookClauses(req.Command.WebhookClauses); err != nil {
 		return err
 	}
+	if err := s.validatePrometheusClauses(req.Command.PrometheusClauses); err != nil {
+		return err
+	}
+	if err := s.validateScheduleClauses(req.Command.ScheduleClauses); err != nil {
+		return err
+	}
+	if len(req.Command.ScheduleClauses) > 0 {
+		clauses := len(req.Command.OpsEventRateClauses) +
+			len(req.Command.DatetimeClauses) +
+			len(req.Command.WebhookClauses) +
+			len(req.Command.PrometheusClauses) +
+			len(req.Command.ScheduleClauses)
+		if clauses > 1 || req.Command.OpsType != autoopsproto.OpsType_ENABLE_FEATURE {
+			return localizedError(statusIncompatibleScheduleClause, locale.JaJP)
+		}
+	}
+	if err := s.validateTriggerSettings(
+		req.Command.ClauseOperator,
+		req.Command.CooldownSeconds,
+		req.Command.SustainedEvaluations,
+	); err != nil {
+		return err
+	}
+	if !isReversibleOpsType(req.Command.OpsType) {
+		for _, c := range req.Command.WebhookClauses {
+			if c.ReverseOnResolved {
+				return localizedError(statusIrreversibleOpsType, locale.JaJP)
+			}
+		}
+	}
+	if req.Command.ClauseOperator == autoopsproto.AutoOpsRule_AND {
+		if len(req.Command.WebhookClauses) > 0 {
+			return localizedError(statusIncompatibleClauseOperator, locale.JaJP)
+		}
+		if len(req.Command.PrometheusClauses) > 0 && len(req.Command.OpsEventRateClauses) > 0 {
+			return localizedError(statusIncompatiblePrometheusClause, locale.JaJP)
+		}
+	}
+	return nil
+}
+
+func (s *AutoOpsService) validateScheduleClauses(clauses []*autoopsproto.ScheduleClause) error {
+	for _, c := range clauses {
+		if err := s.validateScheduleClause(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AutoOpsService) validateScheduleClause(clause *autoopsproto.ScheduleClause) error {
+	switch domain.ValidateScheduleClause(clause) {
+	case nil:
+		return nil
+	case domain.ErrScheduleTimeZoneInvalid:
+		return localizedError(statusScheduleClauseInvalidTimeZone, locale.JaJP)
+	default:
+		return localizedError(statusScheduleClauseInvalidCron, locale.JaJP)
+	}
+}
+
+func (s *AutoOpsService) validatePrometheusClauses(clauses []*autoopsproto.PrometheusClause) error {
+	for _, c := range clauses {
+		if err := s.validatePrometheusClause(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AutoOpsService) validatePrometheusClause(clause *autoopsproto.PrometheusClause) error {
+	if clause.Query == "" {
+		return localizedError(statusPrometheusClauseQueryRequired, locale.JaJP)
+	}
+	if _, ok := autoopsproto.PrometheusClause_Operator_name[int32(clause.Operator)]; !ok {
+		return localizedError(statusPrometheusClauseInvalidOperator, locale.JaJP)
+	}
+	if clause.LookbackSeconds <= 0 {
+		return localizedError(statusPrometheusClauseInvalidLookback, locale.JaJP)
+	}
+	return nil
+}
+
+func (s *AutoOpsService) validateTriggerSettings(
+	clauseOperator autoopsproto.AutoOpsRule_ClauseOperator,
+	cooldownSeconds int64,
+	sustainedEvaluations int32,
+) error {
+	if _, ok := autoopsproto.AutoOpsRule_ClauseOperator_name[int32(clauseOperator)]; !ok {
+		return localizedError(statusInvalidClauseOperator, locale.JaJP)
+	}
+	if cooldownSeconds < 0 {
+		return localizedError(statusInvalidCooldown, locale.JaJP)
+	}
+	if sustainedEvaluations < 0 || sustainedEvaluations > maxSustainedEvaluations {
+		return localizedError(statusInvalidSustainedEvaluations, locale.JaJP)
+	}
 	return nil
 }
 
@@ -243,6 +378,12 @@ func (s *AutoOpsService) validateOpsEventRateClause(clause *autoopsproto.OpsEven
 	if clause.ThreadsholdRate > 1 || clause.ThreadsholdRate <= 0 {
 		return localizedError(statusOpsEventRateClauseInvalidThredshold, locale.JaJP)
 	}
+	if clause.BaselineVariationId == clause.VariationId {
+		return localizedError(statusOpsEventRateClauseInvalidBaseline, locale.JaJP)
+	}
+	if clause.ConfidenceLevel < 0 || clause.ConfidenceLevel >= 1 {
+		return localizedError(statusOpsEventRateClauseInvalidConfidenceLevel, locale.JaJP)
+	}
 	return nil
 }
 
@@ -405,6 +546,17 @@ func (s *AutoOpsService) UpdateAutoOpsRule(
 				len(req.AddOpsEventRateClauseCommands) > 0 {
 				return localizedError(statusIncompatibleOpsType, locale.JaJP)
 			}
+			opsAction, err := s.newOpsAction(
+				ctx,
+				req.EnvironmentNamespace,
+				autoOpsRule.FeatureId,
+				req.ChangeAutoOpsRuleOpsTypeCommand.OpsType,
+				req.ChangeAutoOpsRuleOpsTypeCommand.OpsAction,
+			)
+			if err != nil {
+				return err
+			}
+			req.ChangeAutoOpsRuleOpsTypeCommand.OpsAction = opsAction
 		} else if autoOpsRule.OpsType == autoopsproto.OpsType_ENABLE_FEATURE && len(req.AddOpsEventRateClauseCommands) > 0 {
 			return localizedError(statusIncompatibleOpsType, locale.JaJP)
 		}
@@ -414,13 +566,23 @@ func (s *AutoOpsService) UpdateAutoOpsRule(
 				return err
 			}
 		}
+		if err := validateClauseOperator(autoOpsRule); err != nil {
+			return err
+		}
+		if err := validateReverseOnResolved(autoOpsRule); err != nil {
+			return err
+		}
+		if err := validateScheduleClauseRule(autoOpsRule); err != nil {
+			return err
+		}
 		return autoOpsRuleStorage.UpdateAutoOpsRule(ctx, autoOpsRule, req.EnvironmentNamespace)
 	})
 	if err != nil {
 		if err == v2as.ErrAutoOpsRuleNotFound || err == v2as.ErrAutoOpsRuleUnexpectedAffectedRows {
 			return nil, localizedError(statusNotFound, locale.JaJP)
 		}
-		if status.Code(err) == codes.InvalidArgument {
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.FailedPrecondition, codes.NotFound:
 			return nil, err
 		}
 		s.logger.Error(
@@ -504,9 +666,114 @@ func (s *AutoOpsService) validateUpdateAutoOpsRuleRequest(req *autoopsproto.Upda
 			return err
 		}
 	}
+	for _, c := range req.AddPrometheusClauseCommands {
+		if c.PrometheusClause == nil {
+			return localizedError(statusPrometheusClauseRequired, locale.JaJP)
+		}
+		if err := s.validatePrometheusClause(c.PrometheusClause); err != nil {
+			return err
+		}
+	}
+	for _, c := range req.ChangePrometheusClauseCommands {
+		if c.Id == "" {
+			return localizedError(statusClauseIDRequired, locale.JaJP)
+		}
+		if c.PrometheusClause == nil {
+			return localizedError(statusPrometheusClauseRequired, locale.JaJP)
+		}
+		if err := s.validatePrometheusClause(c.PrometheusClause); err != nil {
+			return err
+		}
+	}
+	for _, c := range req.AddScheduleClauseCommands {
+		if c.ScheduleClause == nil {
+			return localizedError(statusScheduleClauseRequired, locale.JaJP)
+		}
+		if err := s.validateScheduleClause(c.ScheduleClause); err != nil {
+			return err
+		}
+	}
+	for _, c := range req.ChangeScheduleClauseCommands {
+		if c.Id == "" {
+			return localizedError(statusClauseIDRequired, locale.JaJP)
+		}
+		if c.ScheduleClause == nil {
+			return localizedError(statusScheduleClauseRequired, locale.JaJP)
+		}
+		if err := s.validateScheduleClause(c.ScheduleClause); err != nil {
+			return err
+		}
+	}
+	if c := req.ChangeAutoOpsRuleTriggerSettingsCommand; c != nil {
+		if err := s.validateTriggerSettings(c.ClauseOperator, c.CooldownSeconds, c.SustainedEvaluations); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// validateClauseOperator checks the clauses that cannot be assessed together with the and operator.
+// Webhook clauses are assessed on request and prometheus clauses are assessed by another watcher
+// than ops event rate clauses.
+func validateClauseOperator(a *domain.AutoOpsRule) error {
+	if a.ClauseOperator != autoopsproto.AutoOpsRule_AND {
+		return nil
+	}
+	webhookClauses, err := a.ExtractWebhookClauses()
+	if err != nil {
+		return err
+	}
+	if len(webhookClauses) > 0 {
+		return localizedError(statusIncompatibleClauseOperator, locale.JaJP)
+	}
+	prometheusClauses, err := a.ExtractPrometheusClauses()
+	if err != nil {
+		return err
+	}
+	opsEventRateClauses, err := a.ExtractOpsEventRateClauses()
+	if err != nil {
+		return err
+	}
+	if len(prometheusClauses) > 0 && len(opsEventRateClauses) > 0 {
+		return localizedError(statusIncompatiblePrometheusClause, locale.JaJP)
+	}
+	return nil
+}
+
+// validateReverseOnResolved checks that the ops type can be reversed
+// when any webhook clause reverses it on resolved alerts.
+func validateReverseOnResolved(a *domain.AutoOpsRule) error {
+	if isReversibleOpsType(a.OpsType) {
+		return nil
+	}
+	webhookClauses, err := a.ExtractWebhookClauses()
+	if err != nil {
+		return err
+	}
+	for _, c := range webhookClauses {
+		if c.ReverseOnResolved {
+			return localizedError(statusIrreversibleOpsType, locale.JaJP)
+		}
+	}
+	return nil
+}
+
+// validateScheduleClauseRule checks that a rule with a schedule clause has no other clauses
+// and enables the feature, because the disable transitions reverse the operation.
+func validateScheduleClauseRule(a *domain.AutoOpsRule) error {
+	if !a.HasScheduleClause() {
+		return nil
+	}
+	if len(a.Clauses) > 1 || a.OpsType != autoopsproto.OpsType_ENABLE_FEATURE {
+		return localizedError(statusIncompatibleScheduleClause, locale.JaJP)
+	}
+	return nil
+}
+
+func isReversibleOpsType(opsType autoopsproto.OpsType) bool {
+	return opsType == autoopsproto.OpsType_ENABLE_FEATURE || opsType == autoopsproto.OpsType_DISABLE_FEATURE
+}
+
 func (s *AutoOpsService) isNoUpdateAutoOpsRuleCommand(req *autoopsproto.UpdateAutoOpsRuleRequest) bool {
 	return req.ChangeAutoOpsRuleOpsTypeCommand == nil &&
 		len(req.AddOpsEventRateClauseCommands) == 0 &&
@@ -515,7 +782,14 @@ func (s *AutoOpsService) isNoUpdateAutoOpsRuleCommand(req *autoopsproto.UpdateAu
 		len(req.AddDatetimeClauseCommands) == 0 &&
 		len(req.ChangeDatetimeClauseCommands) == 0 &&
 		len(req.AddWebhookClauseCommands) == 0 &&
-		len(req.ChangeWebhookClauseCommands) == 0
+		len(req.ChangeWebhookClauseCommands) == 0 &&
+		len(req.AddPrometheusClauseCommands) == 0 &&
+		len(req.ChangePrometheusClauseCommands) == 0 &&
+		len(req.AddScheduleClauseCommands) == 0 &&
+		len(req.ChangeScheduleClauseCommands) == 0 &&
+		req.ChangeAutoOpsRuleTriggerSettingsCommand == nil &&
+		req.RearmAutoOpsRuleCommand == nil &&
+		req.ChangeAutoOpsRuleShadowCommand == nil
 }
 
 func (s *AutoOpsService) createUpdateAutoOpsRuleCommands(req *autoopsproto.UpdateAutoOpsRuleRequest) []command.Command {
@@ -541,9 +815,30 @@ func (s *AutoOpsService) createUpdateAutoOpsRuleCommands(req *autoopsproto.Updat
 	for _, c := range req.ChangeWebhookClauseCommands {
 		commands = append(commands, c)
 	}
+	for _, c := range req.AddPrometheusClauseCommands {
+		commands = append(commands, c)
+	}
+	for _, c := range req.ChangePrometheusClauseCommands {
+		commands = append(commands, c)
+	}
+	for _, c := range req.AddScheduleClauseCommands {
+		commands = append(commands, c)
+	}
+	for _, c := range req.ChangeScheduleClauseCommands {
+		commands = append(commands, c)
+	}
 	for _, c := range req.DeleteClauseCommands {
 		commands = append(commands, c)
 	}
+	if req.ChangeAutoOpsRuleTriggerSettingsCommand != nil {
+		commands = append(commands, req.ChangeAutoOpsRuleTriggerSettingsCommand)
+	}
+	if req.RearmAutoOpsRuleCommand != nil {
+		commands = append(commands, req.RearmAutoOpsRuleCommand)
+	}
+	if req.ChangeAutoOpsRuleShadowCommand != nil {
+		commands = append(commands, req.ChangeAutoOpsRuleShadowCommand)
+	}
 	return commands
 }
 
@@ -681,6 +976,23 @@ func (s *AutoOpsService) ExecuteAutoOps(
 		if autoOpsRule.AlreadyTriggered() {
 			return errAlreadyTriggered
 		}
+		trigger, reversed := req.Trigger, false
+		if autoOpsRule.HasScheduleClause() {
+			// The transition is decided here so that it is executed only once.
+			transition, err := autoOpsRule.DueScheduleTransition(time.Now().Unix())
+			if err != nil {
+				return err
+			}
+			if transition == nil {
+				return errAlreadyTriggered
+			}
+			trigger = &autoopsproto.ExecutionTrigger{ClauseId: transition.ClauseId, Time: transition.Time}
+			reversed = transition.Action == autoopsproto.ScheduleTransition_DISABLE
+		}
+		if autoOpsRule.Shadow {
+			LogShadowDecision(ctx, req.EnvironmentNamespace, autoOpsRule, trigger, reversed, s.logger)
+			return errShadowRule
+		}
 		handler := command.NewAutoOpsCommandHandler(editor, autoOpsRule, s.publisher, req.EnvironmentNamespace)
 		if err := handler.Handle(ctx, req.ChangeAutoOpsRuleTriggeredAtCommand); err != nil {
 			return err
@@ -688,12 +1000,35 @@ func (s *AutoOpsService) ExecuteAutoOps(
 		if err = autoOpsRuleStorage.UpdateAutoOpsRule(ctx, autoOpsRule, req.EnvironmentNamespace); err != nil {
 			return err
 		}
-		return ExecuteOperation(ctx, req.EnvironmentNamespace, autoOpsRule, s.featureClient, s.logger)
+		if reversed {
+			err = ExecuteReverseOperation(ctx, req.EnvironmentNamespace, autoOpsRule, s.featureClient, s.logger)
+		} else {
+			err = ExecuteOperation(ctx, req.EnvironmentNamespace, autoOpsRule, trigger, s.featureClient, s.logger)
+		}
+		if err != nil {
+			return err
+		}
+		RecordExecution(
+			ctx,
+			req.EnvironmentNamespace,
+			editor,
+			autoOpsRule,
+			trigger,
+			reversed,
+			v2as.NewAutoOpsExecutionStorage(tx),
+			s.featureClient,
+			s.publisher,
+			s.logger,
+		)
+		return nil
 	})
 	if err != nil {
 		if err == errAlreadyTriggered {
 			return &autoopsproto.ExecuteAutoOpsResponse{AlreadyTriggered: true}, nil
 		}
+		if err == errShadowRule {
+			return &autoopsproto.ExecuteAutoOpsResponse{Shadow: true}, nil
+		}
 		if err == v2as.ErrAutoOpsRuleNotFound || err == v2as.ErrAutoOpsRuleUnexpectedAffectedRows {
 			return nil, localizedError(statusNotFound, locale.JaJP)
 		}
@@ -797,6 +1132,168 @@ func (s *AutoOpsService) listOpsCounts(
 	return opsCounts, strconv.Itoa(nextCursor), nil
 }
 
+func (s *AutoOpsService) ListOpsCountHistory(
+	ctx context.Context,
+	req *autoopsproto.ListOpsCountHistoryRequest,
+) (*autoopsproto.ListOpsCountHistoryResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if req.AutoOpsRuleId == "" {
+		return nil, localizedError(statusAutoOpsRuleIDRequired, locale.JaJP)
+	}
+	whereParts := []mysql.WherePart{
+		mysql.NewFilter("environment_namespace", "=", req.EnvironmentNamespace),
+		mysql.NewFilter("auto_ops_rule_id", "=", req.AutoOpsRuleId),
+	}
+	if req.ClauseId != "" {
+		whereParts = append(whereParts, mysql.NewFilter("clause_id", "=", req.ClauseId))
+	}
+	cursor := req.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	offset, err := strconv.Atoi(cursor)
+	if err != nil {
+		return nil, localizedError(statusInvalidCursor, locale.JaJP)
+	}
+	storage := v2os.NewOpsCountHistoryStorage(s.mysqlClient)
+	opsCounts, nextCursor, err := storage.ListOpsCountHistory(
+		ctx,
+		whereParts,
+		[]*mysql.Order{mysql.NewOrder("updated_at", mysql.OrderDirectionDesc)},
+		int(req.PageSize),
+		offset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list opsCount history",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &autoopsproto.ListOpsCountHistoryResponse{
+		Cursor:    strconv.Itoa(nextCursor),
+		OpsCounts: opsCounts,
+	}, nil
+}
+
+func (s *AutoOpsService) ListAutoOpsExecutions(
+	ctx context.Context,
+	req *autoopsproto.ListAutoOpsExecutionsRequest,
+) (*autoopsproto.ListAutoOpsExecutionsResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	whereParts := []mysql.WherePart{
+		mysql.NewFilter("environment_namespace", "=", req.EnvironmentNamespace),
+	}
+	rIDs := make([]interface{}, 0, len(req.AutoOpsRuleIds))
+	for _, rID := range req.AutoOpsRuleIds {
+		rIDs = append(rIDs, rID)
+	}
+	if len(rIDs) > 0 {
+		whereParts = append(whereParts, mysql.NewInFilter("auto_ops_rule_id", rIDs))
+	}
+	fIDs := make([]interface{}, 0, len(req.FeatureIds))
+	for _, fID := range req.FeatureIds {
+		fIDs = append(fIDs, fID)
+	}
+	if len(fIDs) > 0 {
+		whereParts = append(whereParts, mysql.NewInFilter("feature_id", fIDs))
+	}
+	cursor := req.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	offset, err := strconv.Atoi(cursor)
+	if err != nil {
+		return nil, localizedError(statusInvalidCursor, locale.JaJP)
+	}
+	storage := v2as.NewAutoOpsExecutionStorage(s.mysqlClient)
+	executions, nextCursor, err := storage.ListAutoOpsExecutions(
+		ctx,
+		whereParts,
+		[]*mysql.Order{mysql.NewOrder("executed_at", mysql.OrderDirectionDesc)},
+		int(req.PageSize),
+		offset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list autoOpsExecutions",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &autoopsproto.ListAutoOpsExecutionsResponse{
+		Cursor:            strconv.Itoa(nextCursor),
+		AutoOpsExecutions: executions,
+	}, nil
+}
+
+func (s *AutoOpsService) ListScheduleTransitions(
+	ctx context.Context,
+	req *autoopsproto.ListScheduleTransitionsRequest,
+) (*autoopsproto.ListScheduleTransitionsResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateListScheduleTransitionsRequest(req); err != nil {
+		return nil, err
+	}
+	autoOpsRuleStorage := v2as.NewAutoOpsRuleStorage(s.mysqlClient)
+	autoOpsRule, err := autoOpsRuleStorage.GetAutoOpsRule(ctx, req.AutoOpsRuleId, req.EnvironmentNamespace)
+	if err != nil {
+		if err == v2as.ErrAutoOpsRuleNotFound {
+			return nil, localizedError(statusNotFound, locale.JaJP)
+		}
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if autoOpsRule.Deleted {
+		return nil, localizedError(statusAlreadyDeleted, locale.JaJP)
+	}
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultScheduleTransitionsLimit
+	}
+	transitions, err := autoOpsRule.UpcomingScheduleTransitions(time.Now().Unix(), limit)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list schedule transitions",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("autoOpsRuleId", req.AutoOpsRuleId),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &autoopsproto.ListScheduleTransitionsResponse{
+		ScheduleTransitions: transitions,
+	}, nil
+}
+
+func (s *AutoOpsService) validateListScheduleTransitionsRequest(
+	req *autoopsproto.ListScheduleTransitionsRequest,
+) error {
+	if req.AutoOpsRuleId == "" {
+		return localizedError(statusAutoOpsRuleIDRequired, locale.JaJP)
+	}
+	if req.Limit < 0 || req.Limit > maxScheduleTransitionsLimit {
+		return localizedError(statusScheduleTransitionsInvalidLimit, locale.JaJP)
+	}
+	return nil
+}
+
 func (s *AutoOpsService) existGoal(ctx context.Context, environmentNamespace string, goalID string) (bool, error) {
 	_, err := s.getGoal(ctx, environmentNamespace, goalID)
 	if err != nil {
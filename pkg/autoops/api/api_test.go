@@ -27,6 +27,7 @@ import (
 
 	accountclientmock "github.com/bucketeer-io/bucketeer/pkg/account/client/mock"
 	authclientmock "github.com/bucketeer-io/bucketeer/pkg/auth/client/mock"
+	ecclientmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
 	experimentclientmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
 	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
@@ -66,6 +67,7 @@ func TestNewAutoOpsService(t *testing.T) {
 	mysqlClientMock := mysqlmock.NewMockClient(mockController)
 	featureClientMock := featureclientmock.NewMockClient(mockController)
 	experimentClientMock := experimentclientmock.NewMockClient(mockController)
+	eventCounterClientMock := ecclientmock.NewMockClient(mockController)
 	accountClientMock := accountclientmock.NewMockClient(mockController)
 	authClientMock := authclientmock.NewMockClient(mockController)
 	p := publishermock.NewMockPublisher(mockController)
@@ -74,6 +76,7 @@ func TestNewAutoOpsService(t *testing.T) {
 		mysqlClientMock,
 		featureClientMock,
 		experimentClientMock,
+		eventCounterClientMock,
 		accountClientMock,
 		authClientMock,
 		p,
@@ -221,6 +224,45 @@ func TestCreateAutoOpsRuleMySQL(t *testing.T) {
 			},
 			expectedErr: localizedError(statusOpsEventRateClauseInvalidThredshold, locale.JaJP),
 		},
+		"err: ErrOpsEventRateClauseInvalidBaseline": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					OpsEventRateClauses: []*autoopsproto.OpsEventRateClause{
+						{
+							VariationId:         "vid",
+							GoalId:              "gid",
+							MinCount:            10,
+							ThreadsholdRate:     0.5,
+							Operator:            autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+							BaselineVariationId: "vid",
+						},
+					},
+				},
+			},
+			expectedErr: localizedError(statusOpsEventRateClauseInvalidBaseline, locale.JaJP),
+		},
+		"err: ErrOpsEventRateClauseInvalidConfidenceLevel": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					OpsEventRateClauses: []*autoopsproto.OpsEventRateClause{
+						{
+							VariationId:         "vid",
+							GoalId:              "gid",
+							MinCount:            10,
+							ThreadsholdRate:     0.5,
+							Operator:            autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+							BaselineVariationId: "vid-baseline",
+							ConfidenceLevel:     1,
+						},
+					},
+				},
+			},
+			expectedErr: localizedError(statusOpsEventRateClauseInvalidConfidenceLevel, locale.JaJP),
+		},
 		"err: ErrDatetimeClauseInvalidTime": {
 			req: &autoopsproto.CreateAutoOpsRuleRequest{
 				Command: &autoopsproto.CreateAutoOpsRuleCommand{
@@ -290,6 +332,173 @@ func TestCreateAutoOpsRuleMySQL(t *testing.T) {
 			},
 			expectedErr: localizedError(statusWebhookClauseConditionFilterRequired, locale.JaJP),
 		},
+		"err: ErrPrometheusClauseQueryRequired": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					PrometheusClauses: []*autoopsproto.PrometheusClause{
+						{LookbackSeconds: 300},
+					},
+				},
+			},
+			expectedErr: localizedError(statusPrometheusClauseQueryRequired, locale.JaJP),
+		},
+		"err: ErrPrometheusClauseInvalidLookback": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					PrometheusClauses: []*autoopsproto.PrometheusClause{
+						{Query: "up"},
+					},
+				},
+			},
+			expectedErr: localizedError(statusPrometheusClauseInvalidLookback, locale.JaJP),
+		},
+		"err: ErrIncompatiblePrometheusClause": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					OpsEventRateClauses: []*autoopsproto.OpsEventRateClause{
+						{
+							VariationId:     "vid",
+							GoalId:          "gid",
+							MinCount:        10,
+							ThreadsholdRate: 0.5,
+							Operator:        autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+						},
+					},
+					PrometheusClauses: []*autoopsproto.PrometheusClause{
+						{Query: "up", LookbackSeconds: 300},
+					},
+					ClauseOperator: autoopsproto.AutoOpsRule_AND,
+				},
+			},
+			expectedErr: localizedError(statusIncompatiblePrometheusClause, locale.JaJP),
+		},
+		"err: ErrScheduleClauseInvalidCron": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_ENABLE_FEATURE,
+					ScheduleClauses: []*autoopsproto.ScheduleClause{
+						{EnableCron: "0 18 * * 1-5", DisableCron: "@every 1m"},
+					},
+				},
+			},
+			expectedErr: localizedError(statusScheduleClauseInvalidCron, locale.JaJP),
+		},
+		"err: ErrScheduleClauseInvalidTimeZone": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_ENABLE_FEATURE,
+					ScheduleClauses: []*autoopsproto.ScheduleClause{
+						{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * 1-5", TimeZone: "Asia/Nowhere"},
+					},
+				},
+			},
+			expectedErr: localizedError(statusScheduleClauseInvalidTimeZone, locale.JaJP),
+		},
+		"err: ErrIncompatibleScheduleClause: ops type": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					ScheduleClauses: []*autoopsproto.ScheduleClause{
+						{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * 1-5"},
+					},
+				},
+			},
+			expectedErr: localizedError(statusIncompatibleScheduleClause, locale.JaJP),
+		},
+		"err: ErrIncompatibleScheduleClause: other clauses": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_ENABLE_FEATURE,
+					DatetimeClauses: []*autoopsproto.DatetimeClause{
+						{Time: time.Now().Add(time.Hour).Unix()},
+					},
+					ScheduleClauses: []*autoopsproto.ScheduleClause{
+						{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * 1-5"},
+					},
+				},
+			},
+			expectedErr: localizedError(statusIncompatibleScheduleClause, locale.JaJP),
+		},
+		"err: ErrInvalidCooldown": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_ENABLE_FEATURE,
+					DatetimeClauses: []*autoopsproto.DatetimeClause{
+						{Time: time.Now().AddDate(0, 0, 1).Unix()},
+					},
+					CooldownSeconds: -1,
+				},
+			},
+			expectedErr: localizedError(statusInvalidCooldown, locale.JaJP),
+		},
+		"err: ErrInvalidSustainedEvaluations": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_ENABLE_FEATURE,
+					DatetimeClauses: []*autoopsproto.DatetimeClause{
+						{Time: time.Now().AddDate(0, 0, 1).Unix()},
+					},
+					SustainedEvaluations: maxSustainedEvaluations + 1,
+				},
+			},
+			expectedErr: localizedError(statusInvalidSustainedEvaluations, locale.JaJP),
+		},
+		"err: ErrIncompatibleClauseOperator": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+					WebhookClauses: []*autoopsproto.WebhookClause{
+						{
+							WebhookId: "foo-id",
+							Conditions: []*autoopsproto.WebhookClause_Condition{
+								{
+									Filter:   ".foo.bar",
+									Value:    "foobaz",
+									Operator: autoopsproto.WebhookClause_Condition_EQUAL,
+								},
+							},
+						},
+					},
+					ClauseOperator: autoopsproto.AutoOpsRule_AND,
+				},
+			},
+			expectedErr: localizedError(statusIncompatibleClauseOperator, locale.JaJP),
+		},
+		"err: ErrIrreversibleOpsType": {
+			req: &autoopsproto.CreateAutoOpsRuleRequest{
+				Command: &autoopsproto.CreateAutoOpsRuleCommand{
+					FeatureId: "fid",
+					OpsType:   autoopsproto.OpsType_SERVE_FIXED_VARIATION,
+					WebhookClauses: []*autoopsproto.WebhookClause{
+						{
+							WebhookId: "foo-id",
+							Conditions: []*autoopsproto.WebhookClause_Condition{
+								{
+									Filter:   ".name",
+									Value:    `"HighErrorRate"`,
+									Operator: autoopsproto.WebhookClause_Condition_EQUAL,
+								},
+							},
+							ReverseOnResolved: true,
+						},
+					},
+				},
+			},
+			expectedErr: localizedError(statusIrreversibleOpsType, locale.JaJP),
+		},
 		"success": {
 			setup: func(s *AutoOpsService) {
 				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetGoal(
@@ -368,6 +577,53 @@ func TestUpdateAutoOpsRuleMySQL(t *testing.T) {
 			expected:    nil,
 			expectedErr: localizedError(statusNoCommand, locale.JaJP),
 		},
+		"err: AddScheduleClauseCommand: ErrScheduleClauseRequired": {
+			req: &autoopsproto.UpdateAutoOpsRuleRequest{
+				Id:                        "aid1",
+				AddScheduleClauseCommands: []*autoopsproto.AddScheduleClauseCommand{{}},
+			},
+			expected:    nil,
+			expectedErr: localizedError(statusScheduleClauseRequired, locale.JaJP),
+		},
+		"err: ChangeScheduleClauseCommand: ErrScheduleClauseInvalidCron": {
+			req: &autoopsproto.UpdateAutoOpsRuleRequest{
+				Id: "aid1",
+				ChangeScheduleClauseCommands: []*autoopsproto.ChangeScheduleClauseCommand{
+					{
+						Id:             "cid",
+						ScheduleClause: &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5"},
+					},
+				},
+			},
+			expected:    nil,
+			expectedErr: localizedError(statusScheduleClauseInvalidCron, locale.JaJP),
+		},
+		"err: AddPrometheusClauseCommand: ErrPrometheusClauseRequired": {
+			req: &autoopsproto.UpdateAutoOpsRuleRequest{
+				Id:                          "aid1",
+				AddPrometheusClauseCommands: []*autoopsproto.AddPrometheusClauseCommand{{}},
+			},
+			expected:    nil,
+			expectedErr: localizedError(statusPrometheusClauseRequired, locale.JaJP),
+		},
+		"err: ChangePrometheusClauseCommand: ErrClauseIdRequired": {
+			req: &autoopsproto.UpdateAutoOpsRuleRequest{
+				Id:                             "aid1",
+				ChangePrometheusClauseCommands: []*autoopsproto.ChangePrometheusClauseCommand{{}},
+			},
+			expected:    nil,
+			expectedErr: localizedError(statusClauseIDRequired, locale.JaJP),
+		},
+		"err: ErrInvalidClauseOperator": {
+			req: &autoopsproto.UpdateAutoOpsRuleRequest{
+				Id: "aid1",
+				ChangeAutoOpsRuleTriggerSettingsCommand: &autoopsproto.ChangeAutoOpsRuleTriggerSettingsCommand{
+					ClauseOperator: autoopsproto.AutoOpsRule_ClauseOperator(99),
+				},
+			},
+			expected:    nil,
+			expectedErr: localizedError(statusInvalidClauseOperator, locale.JaJP),
+		},
 		"err: ErrOpsEventRateClauseRequired": {
 			req: &autoopsproto.UpdateAutoOpsRuleRequest{
 				Id:                            "aid1",
@@ -623,6 +879,105 @@ func TestListAutoOpsRulesMySQL(t *testing.T) {
 	}
 }
 
+func TestListOpsCountHistoryMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup       func(*AutoOpsService)
+		req         *autoopsproto.ListOpsCountHistoryRequest
+		expectedErr error
+	}{
+		"err: ErrAutoOpsRuleIDRequired": {
+			req:         &autoopsproto.ListOpsCountHistoryRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: localizedError(statusAutoOpsRuleIDRequired, locale.JaJP),
+		},
+		"err: ErrInvalidCursor": {
+			req: &autoopsproto.ListOpsCountHistoryRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleId:        "aid",
+				Cursor:               "foo",
+			},
+			expectedErr: localizedError(statusInvalidCursor, locale.JaJP),
+		},
+		"success": {
+			setup: func(s *AutoOpsService) {
+				rows := mysqlmock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			req: &autoopsproto.ListOpsCountHistoryRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleId:        "aid",
+				ClauseId:             "cid",
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			service := createAutoOpsService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			_, err := service.ListOpsCountHistory(createContextWithTokenRoleUnassigned(t), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestListAutoOpsExecutionsMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup       func(*AutoOpsService)
+		req         *autoopsproto.ListAutoOpsExecutionsRequest
+		expectedErr error
+	}{
+		"err: ErrInvalidCursor": {
+			req: &autoopsproto.ListAutoOpsExecutionsRequest{
+				EnvironmentNamespace: "ns0",
+				Cursor:               "foo",
+			},
+			expectedErr: localizedError(statusInvalidCursor, locale.JaJP),
+		},
+		"success": {
+			setup: func(s *AutoOpsService) {
+				rows := mysqlmock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			req: &autoopsproto.ListAutoOpsExecutionsRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleIds:       []string{"aid"},
+				FeatureIds:           []string{"fid"},
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			service := createAutoOpsService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			_, err := service.ListAutoOpsExecutions(createContextWithTokenRoleUnassigned(t), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
 func TestExecuteAutoOpsRuleMySQL(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -657,6 +1012,20 @@ func TestExecuteAutoOpsRuleMySQL(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		"success: Shadow": {
+			setup: func(s *AutoOpsService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(errShadowRule)
+			},
+			req: &autoopsproto.ExecuteAutoOpsRequest{
+				Id:                                  "aid4",
+				EnvironmentNamespace:                "ns0",
+				ChangeAutoOpsRuleTriggeredAtCommand: &autoopsproto.ChangeAutoOpsRuleTriggeredAtCommand{},
+			},
+			expectedErr: nil,
+		},
 		"success": {
 			setup: func(s *AutoOpsService) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
@@ -684,6 +1053,69 @@ func TestExecuteAutoOpsRuleMySQL(t *testing.T) {
 	}
 }
 
+func TestListScheduleTransitionsMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup       func(*AutoOpsService)
+		req         *autoopsproto.ListScheduleTransitionsRequest
+		expectedErr error
+	}{
+		"err: ErrAutoOpsRuleIDRequired": {
+			req:         &autoopsproto.ListScheduleTransitionsRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: localizedError(statusAutoOpsRuleIDRequired, locale.JaJP),
+		},
+		"err: ErrInvalidLimit": {
+			req: &autoopsproto.ListScheduleTransitionsRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleId:        "aid1",
+				Limit:                101,
+			},
+			expectedErr: localizedError(statusScheduleTransitionsInvalidLimit, locale.JaJP),
+		},
+		"err: ErrNotFound": {
+			setup: func(s *AutoOpsService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			req: &autoopsproto.ListScheduleTransitionsRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleId:        "wrongid",
+			},
+			expectedErr: localizedError(statusNotFound, locale.JaJP),
+		},
+		"success": {
+			setup: func(s *AutoOpsService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			req: &autoopsproto.ListScheduleTransitionsRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRuleId:        "aid1",
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			s := createAutoOpsService(mockController, nil)
+			if p.setup != nil {
+				p.setup(s)
+			}
+			_, err := s.ListScheduleTransitions(createContextWithTokenRoleUnassigned(t), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
 func TestExistGoal(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -745,6 +1177,7 @@ func createAutoOpsService(c *gomock.Controller, db storage.Client) *AutoOpsServi
 	}
 	accountClientMock.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Return(ar, nil).AnyTimes()
 	experimentClientMock := experimentclientmock.NewMockClient(c)
+	eventCounterClientMock := ecclientmock.NewMockClient(c)
 	authClientMock := authclientmock.NewMockClient(c)
 	p := publishermock.NewMockPublisher(c)
 	logger := zap.NewNop()
@@ -752,6 +1185,7 @@ func createAutoOpsService(c *gomock.Controller, db storage.Client) *AutoOpsServi
 		mysqlClientMock,
 		featureClientMock,
 		experimentClientMock,
+		eventCounterClientMock,
 		accountClientMock,
 		authClientMock,
 		p,
@@ -15,6 +15,8 @@
 package api
 
 import (
+	"fmt"
+
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
@@ -67,6 +69,14 @@ var (
 		codes.InvalidArgument,
 		"autoops: ops event rate clause thredshold must be >0 and <=1",
 	)
+	statusOpsEventRateClauseInvalidBaseline = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: ops event rate clause baseline variation must differ from the variation",
+	)
+	statusOpsEventRateClauseInvalidConfidenceLevel = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: ops event rate clause confidence level must be between 0 and 1",
+	)
 	statusDatetimeClauseRequired    = gstatus.New(codes.InvalidArgument, "autoops: datetime clause must be specified")
 	statusDatetimeClauseInvalidTime = gstatus.New(
 		codes.InvalidArgument,
@@ -103,10 +113,114 @@ var (
 		codes.InvalidArgument,
 		"autoops: webhook clause condition oerator is invalid",
 	)
-	statusAlreadyExists    = gstatus.New(codes.AlreadyExists, "autoops: already exists")
-	statusUnauthenticated  = gstatus.New(codes.Unauthenticated, "autoops: unauthenticated")
-	statusPermissionDenied = gstatus.New(codes.PermissionDenied, "autoops: permission denied")
-	statusInvalidRequest   = gstatus.New(codes.InvalidArgument, "autoops: invalid request")
+	statusWebhookSigningSecretNotFound = gstatus.New(
+		codes.FailedPrecondition,
+		"autoops: webhook signing secret does not exist",
+	)
+	statusFeatureNotFound   = gstatus.New(codes.NotFound, "autoops: feature not found")
+	statusOpsActionRequired = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: ops action must be specified for the ops type",
+	)
+	statusOpsActionRuleNotFound = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: ops action rule does not exist in the feature",
+	)
+	statusOpsActionVariationNotFound = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: ops action variation does not exist in the feature",
+	)
+	statusOpsActionInvalidWeight = gstatus.New(
+		codes.InvalidArgument,
+		fmt.Sprintf("autoops: ops action weights must be positive and sum to %d", totalVariationWeight),
+	)
+	statusOpsActionOffVariationRequired = gstatus.New(
+		codes.FailedPrecondition,
+		"autoops: feature must have an off variation to serve it",
+	)
+	statusOpsActionPreviousVersionNotFound = gstatus.New(
+		codes.FailedPrecondition,
+		"autoops: feature has no previous version to roll back to",
+	)
+	statusOpsActionFeatureChanged = gstatus.New(
+		codes.FailedPrecondition,
+		"autoops: feature has been changed since the rule was assessed",
+	)
+	statusInvalidClauseOperator       = gstatus.New(codes.InvalidArgument, "autoops: clause operator is invalid")
+	statusInvalidCooldown             = gstatus.New(codes.InvalidArgument, "autoops: cooldown must not be negative")
+	statusInvalidSustainedEvaluations = gstatus.New(
+		codes.InvalidArgument,
+		fmt.Sprintf("autoops: sustained evaluations must be between 0 and %d", maxSustainedEvaluations),
+	)
+	statusIncompatibleClauseOperator = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: webhook clauses cannot be combined with the and operator",
+	)
+	statusIncompatiblePrometheusClause = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: prometheus clauses cannot be combined with ops event rate clauses with the and operator",
+	)
+	statusPrometheusClauseRequired = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: prometheus clause must be specified",
+	)
+	statusPrometheusClauseQueryRequired = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: prometheus clause query must be specified",
+	)
+	statusPrometheusClauseInvalidOperator = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: prometheus clause operator is invalid",
+	)
+	statusPrometheusClauseInvalidLookback = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: prometheus clause lookback must be positive",
+	)
+	statusIrreversibleOpsType = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: only enable and disable feature can be reversed on resolved alerts",
+	)
+	statusAutoOpsRuleRequired = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: auto ops rule must be specified",
+	)
+	statusSimulationInvalidTimeRange = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: simulation time range is invalid",
+	)
+	statusSimulationInvalidInterval = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: simulation interval must not be negative",
+	)
+	statusSimulationUnsupportedClause = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: only ops event rate and datetime clauses can be simulated",
+	)
+	statusScheduleClauseRequired = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: schedule clause must be specified",
+	)
+	statusScheduleClauseInvalidCron = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: schedule clause cron expressions must have the five standard fields",
+	)
+	statusScheduleClauseInvalidTimeZone = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: schedule clause time zone must be an IANA time zone name",
+	)
+	statusIncompatibleScheduleClause = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: schedule clause must be the only clause of a rule to enable the feature",
+	)
+	statusScheduleTransitionsInvalidLimit = gstatus.New(
+		codes.InvalidArgument,
+		"autoops: limit of schedule transitions must be between 0 and 100",
+	)
+	statusAutoOpsRuleIDRequired = gstatus.New(codes.InvalidArgument, "autoops: auto ops rule id must be specified")
+	statusAlreadyExists         = gstatus.New(codes.AlreadyExists, "autoops: already exists")
+	statusUnauthenticated       = gstatus.New(codes.Unauthenticated, "autoops: unauthenticated")
+	statusPermissionDenied      = gstatus.New(codes.PermissionDenied, "autoops: permission denied")
+	statusInvalidRequest        = gstatus.New(codes.InvalidArgument, "autoops: invalid request")
 
 	errInternalJaJP = status.MustWithDetails(
 		statusInternal,
@@ -220,6 +334,20 @@ var (
 			Message: "イベントレートルールのしきい値が不正です",
 		},
 	)
+	errOpsEventRateClauseInvalidBaselineJaJP = status.MustWithDetails(
+		statusOpsEventRateClauseInvalidBaseline,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "イベントレートルールのベースラインのvariationは対象のvariationと異なる必要があります",
+		},
+	)
+	errOpsEventRateClauseInvalidConfidenceLevelJaJP = status.MustWithDetails(
+		statusOpsEventRateClauseInvalidConfidenceLevel,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "イベントレートルールの信頼水準が不正です",
+		},
+	)
 	errDatetimeClauseRequiredJaJP = status.MustWithDetails(
 		statusDatetimeClauseRequired,
 		&errdetails.LocalizedMessage{
@@ -283,6 +411,209 @@ var (
 			Message: "ウェブフックルールのconditionのoperatorが不正です",
 		},
 	)
+	errWebhookSigningSecretNotFoundJaJP = status.MustWithDetails(
+		statusWebhookSigningSecretNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "ウェブフックの署名シークレットが存在しません",
+		},
+	)
+	errFeatureNotFoundJaJP = status.MustWithDetails(
+		statusFeatureNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "featureが存在しません",
+		},
+	)
+	errOpsActionRequiredJaJP = status.MustWithDetails(
+		statusOpsActionRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "オペレーションタイプのアクションを指定してください",
+		},
+	)
+	errOpsActionRuleNotFoundJaJP = status.MustWithDetails(
+		statusOpsActionRuleNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "アクションのルールがfeatureに存在しません",
+		},
+	)
+	errOpsActionVariationNotFoundJaJP = status.MustWithDetails(
+		statusOpsActionVariationNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "アクションのvariationがfeatureに存在しません",
+		},
+	)
+	errOpsActionInvalidWeightJaJP = status.MustWithDetails(
+		statusOpsActionInvalidWeight,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: fmt.Sprintf("アクションのweightの合計は%dである必要があります", totalVariationWeight),
+		},
+	)
+	errOpsActionOffVariationRequiredJaJP = status.MustWithDetails(
+		statusOpsActionOffVariationRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "featureにoff variationが設定されていません",
+		},
+	)
+	errOpsActionPreviousVersionNotFoundJaJP = status.MustWithDetails(
+		statusOpsActionPreviousVersionNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "ロールバック先のfeatureの以前のバージョンが存在しません",
+		},
+	)
+	errOpsActionFeatureChangedJaJP = status.MustWithDetails(
+		statusOpsActionFeatureChanged,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "ルールの評価後にfeatureが変更されたため、ロールバックできません",
+		},
+	)
+	errInvalidClauseOperatorJaJP = status.MustWithDetails(
+		statusInvalidClauseOperator,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "ルールの結合条件が不正です",
+		},
+	)
+	errInvalidCooldownJaJP = status.MustWithDetails(
+		statusInvalidCooldown,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "クールダウン時間が不正です",
+		},
+	)
+	errInvalidSustainedEvaluationsJaJP = status.MustWithDetails(
+		statusInvalidSustainedEvaluations,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: fmt.Sprintf("継続評価回数は0から%dの間で指定してください", maxSustainedEvaluations),
+		},
+	)
+	errIncompatibleClauseOperatorJaJP = status.MustWithDetails(
+		statusIncompatibleClauseOperator,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Webhookルールはand条件で結合できません",
+		},
+	)
+	errIncompatiblePrometheusClauseJaJP = status.MustWithDetails(
+		statusIncompatiblePrometheusClause,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールとイベントレートルールはand条件で結合できません",
+		},
+	)
+	errPrometheusClauseRequiredJaJP = status.MustWithDetails(
+		statusPrometheusClauseRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールは必須です",
+		},
+	)
+	errPrometheusClauseQueryRequiredJaJP = status.MustWithDetails(
+		statusPrometheusClauseQueryRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールのクエリは必須です",
+		},
+	)
+	errPrometheusClauseInvalidOperatorJaJP = status.MustWithDetails(
+		statusPrometheusClauseInvalidOperator,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールの比較演算子が不正です",
+		},
+	)
+	errPrometheusClauseInvalidLookbackJaJP = status.MustWithDetails(
+		statusPrometheusClauseInvalidLookback,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールの評価期間が不正です",
+		},
+	)
+	errIrreversibleOpsTypeJaJP = status.MustWithDetails(
+		statusIrreversibleOpsType,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "アラート解消時に元に戻せるのはフラグの有効化と無効化のみです",
+		},
+	)
+	errAutoOpsRuleIDRequiredJaJP = status.MustWithDetails(
+		statusAutoOpsRuleIDRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションのidは必須です",
+		},
+	)
+	errAutoOpsRuleRequiredJaJP = status.MustWithDetails(
+		statusAutoOpsRuleRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションは必須です",
+		},
+	)
+	errSimulationInvalidTimeRangeJaJP = status.MustWithDetails(
+		statusSimulationInvalidTimeRange,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "シミュレーションの期間が不正です",
+		},
+	)
+	errSimulationInvalidIntervalJaJP = status.MustWithDetails(
+		statusSimulationInvalidInterval,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "シミュレーションの間隔が不正です",
+		},
+	)
+	errSimulationUnsupportedClauseJaJP = status.MustWithDetails(
+		statusSimulationUnsupportedClause,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "シミュレーションできるのはイベントレートルールと日時ルールのみです",
+		},
+	)
+	errScheduleClauseRequiredJaJP = status.MustWithDetails(
+		statusScheduleClauseRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールは必須です",
+		},
+	)
+	errScheduleClauseInvalidCronJaJP = status.MustWithDetails(
+		statusScheduleClauseInvalidCron,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールのcron式が不正です",
+		},
+	)
+	errScheduleClauseInvalidTimeZoneJaJP = status.MustWithDetails(
+		statusScheduleClauseInvalidTimeZone,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールのタイムゾーンが不正です",
+		},
+	)
+	errIncompatibleScheduleClauseJaJP = status.MustWithDetails(
+		statusIncompatibleScheduleClause,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールはフラグを有効化するオペレーションの唯一のルールでなければなりません",
+		},
+	)
+	errScheduleTransitionsInvalidLimitJaJP = status.MustWithDetails(
+		statusScheduleTransitionsInvalidLimit,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールの取得件数は0から100の間で指定してください",
+		},
+	)
 	errNotFoundJaJP = status.MustWithDetails(
 		statusNotFound,
 		&errdetails.LocalizedMessage{
@@ -362,6 +693,10 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errOpsEventRateClauseMinCountRequiredJaJP
 	case statusOpsEventRateClauseMinCountRequired:
 		return errOpsEventRateClauseInvalidThredsholdJaJP
+	case statusOpsEventRateClauseInvalidBaseline:
+		return errOpsEventRateClauseInvalidBaselineJaJP
+	case statusOpsEventRateClauseInvalidConfidenceLevel:
+		return errOpsEventRateClauseInvalidConfidenceLevelJaJP
 	case statusDatetimeClauseRequired:
 		return errDatetimeClauseRequiredJaJP
 	case statusDatetimeClauseInvalidTime:
@@ -378,6 +713,64 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errWebhookClauseConditionFilterRequiredJaJP
 	case statusWebhookClauseConditionInvalidOperator:
 		return errWebhookClauseConditionInvalidOperatorJaJP
+	case statusWebhookSigningSecretNotFound:
+		return errWebhookSigningSecretNotFoundJaJP
+	case statusFeatureNotFound:
+		return errFeatureNotFoundJaJP
+	case statusOpsActionRequired:
+		return errOpsActionRequiredJaJP
+	case statusOpsActionRuleNotFound:
+		return errOpsActionRuleNotFoundJaJP
+	case statusOpsActionVariationNotFound:
+		return errOpsActionVariationNotFoundJaJP
+	case statusOpsActionInvalidWeight:
+		return errOpsActionInvalidWeightJaJP
+	case statusOpsActionOffVariationRequired:
+		return errOpsActionOffVariationRequiredJaJP
+	case statusOpsActionPreviousVersionNotFound:
+		return errOpsActionPreviousVersionNotFoundJaJP
+	case statusOpsActionFeatureChanged:
+		return errOpsActionFeatureChangedJaJP
+	case statusInvalidClauseOperator:
+		return errInvalidClauseOperatorJaJP
+	case statusInvalidCooldown:
+		return errInvalidCooldownJaJP
+	case statusInvalidSustainedEvaluations:
+		return errInvalidSustainedEvaluationsJaJP
+	case statusIncompatibleClauseOperator:
+		return errIncompatibleClauseOperatorJaJP
+	case statusIncompatiblePrometheusClause:
+		return errIncompatiblePrometheusClauseJaJP
+	case statusPrometheusClauseRequired:
+		return errPrometheusClauseRequiredJaJP
+	case statusPrometheusClauseQueryRequired:
+		return errPrometheusClauseQueryRequiredJaJP
+	case statusPrometheusClauseInvalidOperator:
+		return errPrometheusClauseInvalidOperatorJaJP
+	case statusPrometheusClauseInvalidLookback:
+		return errPrometheusClauseInvalidLookbackJaJP
+	case statusIrreversibleOpsType:
+		return errIrreversibleOpsTypeJaJP
+	case statusAutoOpsRuleIDRequired:
+		return errAutoOpsRuleIDRequiredJaJP
+	case statusAutoOpsRuleRequired:
+		return errAutoOpsRuleRequiredJaJP
+	case statusSimulationInvalidTimeRange:
+		return errSimulationInvalidTimeRangeJaJP
+	case statusSimulationInvalidInterval:
+		return errSimulationInvalidIntervalJaJP
+	case statusSimulationUnsupportedClause:
+		return errSimulationUnsupportedClauseJaJP
+	case statusScheduleClauseRequired:
+		return errScheduleClauseRequiredJaJP
+	case statusScheduleClauseInvalidCron:
+		return errScheduleClauseInvalidCronJaJP
+	case statusScheduleClauseInvalidTimeZone:
+		return errScheduleClauseInvalidTimeZoneJaJP
+	case statusIncompatibleScheduleClause:
+		return errIncompatibleScheduleClauseJaJP
+	case statusScheduleTransitionsInvalidLimit:
+		return errScheduleTransitionsInvalidLimitJaJP
 	case statusNotFound:
 		return errNotFoundJaJP
 	case statusAlreadyDeleted:
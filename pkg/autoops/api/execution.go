@@ -0,0 +1,148 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	v2as "github.com/bucketeer-io/bucketeer/pkg/autoops/storage/v2"
+	domainevent "github.com/bucketeer-io/bucketeer/pkg/domainevent/domain"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// RecordExecution stores the execution of the rule with the resulting feature version
+// and publishes it so that the trigger is notified.
+// It must be called after the operation of the rule is executed.
+// Failures are only logged, since returning them would roll back the triggered_at of the rule
+// and execute the operation again.
+func RecordExecution(
+	ctx context.Context,
+	environmentNamespace string,
+	editor *eventproto.Editor,
+	autoOpsRule *domain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+	reversed bool,
+	storage v2as.AutoOpsExecutionStorage,
+	featureClient featureclient.Client,
+	publisher publisher.Publisher,
+	logger *zap.Logger,
+) {
+	err := recordExecution(
+		ctx,
+		environmentNamespace,
+		editor,
+		autoOpsRule,
+		trigger,
+		reversed,
+		storage,
+		featureClient,
+		publisher,
+		logger,
+	)
+	if err != nil {
+		logger.Error(
+			"Failed to record the execution",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("autoOpsRuleId", autoOpsRule.Id),
+				zap.Bool("reversed", reversed),
+			)...,
+		)
+	}
+}
+
+func recordExecution(
+	ctx context.Context,
+	environmentNamespace string,
+	editor *eventproto.Editor,
+	autoOpsRule *domain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+	reversed bool,
+	storage v2as.AutoOpsExecutionStorage,
+	featureClient featureclient.Client,
+	publisher publisher.Publisher,
+	logger *zap.Logger,
+) error {
+	execution, err := domain.NewAutoOpsExecution(autoOpsRule, trigger, reversed)
+	if err != nil {
+		return err
+	}
+	resp, err := featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   autoOpsRule.FeatureId,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		// The execution is still recorded because the operation has already been executed.
+		logger.Warn(
+			"Failed to get the feature version of the execution",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("autoOpsRuleId", autoOpsRule.Id),
+			)...,
+		)
+	} else {
+		execution.SetFeatureVersion(resp.Feature.Version)
+	}
+	if err := storage.CreateAutoOpsExecution(ctx, execution, environmentNamespace); err != nil {
+		return err
+	}
+	e, err := domainevent.NewEvent(
+		editor,
+		eventproto.Event_AUTOOPS_RULE,
+		autoOpsRule.Id,
+		eventproto.Event_AUTOOPS_RULE_EXECUTED,
+		&eventproto.AutoOpsRuleExecutedEvent{
+			Execution:   execution.AutoOpsExecution,
+			AutoOpsRule: autoOpsRule.AutoOpsRule,
+		},
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, e)
+}
+
+// LogShadowDecision logs the execution that the rule in shadow mode would have taken.
+func LogShadowDecision(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+	reversed bool,
+	logger *zap.Logger,
+) {
+	logger.Info(
+		"Shadow rule would have been executed",
+		log.FieldsFromImcomingContext(ctx).AddFields(
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("featureId", autoOpsRule.FeatureId),
+			zap.String("autoOpsRuleId", autoOpsRule.Id),
+			zap.String("opsType", autoOpsRule.OpsType.String()),
+			zap.Bool("reversed", reversed),
+			zap.Any("trigger", trigger),
+		)...,
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	storagemock "github.com/bucketeer-io/bucketeer/pkg/autoops/storage/v2/mock"
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestRecordExecution(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	rule := &domain.AutoOpsRule{AutoOpsRule: &autoopsproto.AutoOpsRule{
+		Id:        "rid",
+		FeatureId: "fid",
+		OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+	}}
+	trigger := &autoopsproto.ExecutionTrigger{ClauseId: "cid", OpsEventCount: 5, EvaluationCount: 10}
+	patterns := map[string]struct {
+		getFeatureErr   error
+		createErr       error
+		expectedVersion int32
+	}{
+		"success": {
+			expectedVersion: 3,
+		},
+		"success: feature version is unknown": {
+			getFeatureErr:   errors.New("error"),
+			expectedVersion: 0,
+		},
+		"success: failure to store the execution is not returned": {
+			createErr:       errors.New("error"),
+			expectedVersion: 3,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			featureClient := featureclientmock.NewMockClient(mockController)
+			storage := storagemock.NewMockAutoOpsExecutionStorage(mockController)
+			publisher := publishermock.NewMockPublisher(mockController)
+			var resp *featureproto.GetFeatureResponse
+			if p.getFeatureErr == nil {
+				resp = &featureproto.GetFeatureResponse{Feature: &featureproto.Feature{Id: "fid", Version: 3}}
+			}
+			featureClient.EXPECT().GetFeature(gomock.Any(), &featureproto.GetFeatureRequest{
+				Id:                   "fid",
+				EnvironmentNamespace: "ns0",
+			}).Return(resp, p.getFeatureErr)
+			publishTimes := 1
+			if p.createErr != nil {
+				publishTimes = 0
+			}
+			storage.EXPECT().CreateAutoOpsExecution(gomock.Any(), gomock.Any(), "ns0").DoAndReturn(
+				func(_ context.Context, e *domain.AutoOpsExecution, _ string) error {
+					assert.Equal(t, "rid", e.AutoOpsRuleId)
+					assert.Equal(t, trigger, e.Trigger)
+					assert.Equal(t, p.expectedVersion, e.FeatureVersion)
+					return p.createErr
+				})
+			publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, e *eventproto.Event) error {
+					assert.Equal(t, eventproto.Event_AUTOOPS_RULE_EXECUTED, e.Type)
+					assert.Equal(t, "rid", e.EntityId)
+					executed := &eventproto.AutoOpsRuleExecutedEvent{}
+					require.NoError(t, ptypes.UnmarshalAny(e.Data, executed))
+					assert.Equal(t, "cid", executed.Execution.Trigger.ClauseId)
+					assert.Equal(t, "rid", executed.AutoOpsRule.Id)
+					return nil
+				}).Times(publishTimes)
+			RecordExecution(
+				context.Background(),
+				"ns0",
+				&eventproto.Editor{Email: "email"},
+				rule,
+				trigger,
+				false,
+				storage,
+				featureClient,
+				publisher,
+				zap.NewNop(),
+			)
+		})
+	}
+}
@@ -16,13 +16,18 @@ package api
 
 import (
 	"context"
+	"fmt"
 
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	featurecommand "github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	featuredomain "github.com/bucketeer-io/bucketeer/pkg/feature/domain"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/log"
 	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
@@ -35,6 +40,7 @@ func ExecuteOperation(
 	ctx context.Context,
 	environmentNamespace string,
 	autoOpsRule *domain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
 	featureClient featureclient.Client,
 	logger *zap.Logger,
 ) error {
@@ -43,10 +49,33 @@ func ExecuteOperation(
 		return enableFeature(ctx, environmentNamespace, autoOpsRule, featureClient, logger)
 	case autoopsproto.OpsType_DISABLE_FEATURE:
 		return disableFeature(ctx, environmentNamespace, autoOpsRule, featureClient, logger)
+	case autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS:
+		return updateRolloutWeights(ctx, environmentNamespace, autoOpsRule, featureClient)
+	case autoopsproto.OpsType_SERVE_FIXED_VARIATION:
+		return serveFixedVariation(ctx, environmentNamespace, autoOpsRule, featureClient)
+	case autoopsproto.OpsType_ROLLBACK_FEATURE:
+		return rollbackFeature(ctx, environmentNamespace, autoOpsRule, trigger, featureClient)
 	}
 	return localizedError(statusUnknownOpsType, locale.JaJP)
 }
 
+// ExecuteReverseOperation executes the opposite operation of the rule's ops type.
+func ExecuteReverseOperation(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	featureClient featureclient.Client,
+	logger *zap.Logger,
+) error {
+	switch autoOpsRule.OpsType {
+	case autoopsproto.OpsType_ENABLE_FEATURE:
+		return disableFeature(ctx, environmentNamespace, autoOpsRule, featureClient, logger)
+	case autoopsproto.OpsType_DISABLE_FEATURE:
+		return enableFeature(ctx, environmentNamespace, autoOpsRule, featureClient, logger)
+	}
+	return localizedError(statusIrreversibleOpsType, locale.JaJP)
+}
+
 func enableFeature(
 	ctx context.Context,
 	environmentNamespace string,
@@ -98,3 +127,141 @@ func disableFeature(
 	}
 	return err
 }
+
+func updateRolloutWeights(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	featureClient featureclient.Client,
+) error {
+	action := autoOpsRule.OpsAction
+	if action == nil || action.RolloutStrategy == nil {
+		return localizedError(statusOpsActionRequired, locale.JaJP)
+	}
+	strategy := &featureproto.Strategy{
+		Type:            featureproto.Strategy_ROLLOUT,
+		RolloutStrategy: action.RolloutStrategy,
+	}
+	var cmd pb.Message
+	if action.RuleId == "" {
+		cmd = &featureproto.ChangeDefaultStrategyCommand{Strategy: strategy}
+	} else {
+		cmd = &featureproto.ChangeRuleStrategyCommand{RuleId: action.RuleId, Strategy: strategy}
+	}
+	return updateFeatureTargeting(ctx, environmentNamespace, autoOpsRule, featureClient, cmd)
+}
+
+func serveFixedVariation(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	featureClient featureclient.Client,
+) error {
+	variationID := ""
+	if autoOpsRule.OpsAction != nil {
+		variationID = autoOpsRule.OpsAction.VariationId
+	}
+	if variationID == "" {
+		resp, err := featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+			Id:                   autoOpsRule.FeatureId,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Feature.OffVariation == "" {
+			return localizedError(statusOpsActionOffVariationRequired, locale.JaJP)
+		}
+		variationID = resp.Feature.OffVariation
+	}
+	cmd := &featureproto.ChangeDefaultStrategyCommand{
+		Strategy: &featureproto.Strategy{
+			Type:          featureproto.Strategy_FIXED,
+			FixedStrategy: &featureproto.FixedStrategy{Variation: variationID},
+		},
+	}
+	return updateFeatureTargeting(ctx, environmentNamespace, autoOpsRule, featureClient, cmd)
+}
+
+// rollbackFeature restores the targeting and the enabled state of the feature as of the version
+// before the assessed one, i.e. the change that triggered the rule, in a single update.
+// The assessed version is the current one when the trigger doesn't carry it.
+// The rollback is refused once the feature has been changed since it was assessed,
+// so that the rule doesn't revert a later change.
+// The variations are matched by value, so the version can only be restored
+// while all its variation values still exist.
+func rollbackFeature(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+	featureClient featureclient.Client,
+) error {
+	resp, err := featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   autoOpsRule.FeatureId,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		return err
+	}
+	assessed := trigger.GetFeatureVersion()
+	if assessed == 0 {
+		assessed = resp.Feature.Version
+	}
+	if resp.Feature.Version != assessed {
+		return localizedError(statusOpsActionFeatureChanged, locale.JaJP)
+	}
+	if assessed <= 1 {
+		return localizedError(statusOpsActionPreviousVersionNotFound, locale.JaJP)
+	}
+	previous, err := featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   autoOpsRule.FeatureId,
+		EnvironmentNamespace: environmentNamespace,
+		Version:              assessed - 1,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return localizedError(statusOpsActionPreviousVersionNotFound, locale.JaJP)
+		}
+		return err
+	}
+	commands, err := featurecommand.SnapshotRestoreCommands(
+		previous.Feature,
+		&featuredomain.Feature{Feature: resp.Feature},
+	)
+	if err != nil {
+		return err
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	cmds := make([]pb.Message, 0, len(commands))
+	for _, c := range commands {
+		cmds = append(cmds, c.(pb.Message))
+	}
+	return updateFeatureTargeting(ctx, environmentNamespace, autoOpsRule, featureClient, cmds...)
+}
+
+func updateFeatureTargeting(
+	ctx context.Context,
+	environmentNamespace string,
+	autoOpsRule *domain.AutoOpsRule,
+	featureClient featureclient.Client,
+	cmds ...pb.Message,
+) error {
+	commands := make([]*featureproto.Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		c, err := ptypes.MarshalAny(cmd)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, &featureproto.Command{Command: c})
+	}
+	_, err := featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+		Id:                   autoOpsRule.FeatureId,
+		Commands:             commands,
+		EnvironmentNamespace: environmentNamespace,
+		Comment:              fmt.Sprintf("Executed by the auto operation rule %s", autoOpsRule.Id),
+	})
+	return err
+}
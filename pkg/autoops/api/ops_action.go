@@ -0,0 +1,144 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const totalVariationWeight = int32(100000)
+
+// newOpsAction validates the action against the feature's variations and rules,
+// and returns the action to be saved in the rule.
+// The ops types that don't change the targeting have no action.
+func (s *AutoOpsService) newOpsAction(
+	ctx context.Context,
+	environmentNamespace, featureID string,
+	opsType autoopsproto.OpsType,
+	action *autoopsproto.OpsAction,
+) (*autoopsproto.OpsAction, error) {
+	switch opsType {
+	case autoopsproto.OpsType_ENABLE_FEATURE,
+		autoopsproto.OpsType_DISABLE_FEATURE,
+		autoopsproto.OpsType_ROLLBACK_FEATURE:
+		return nil, nil
+	case autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS:
+		if action == nil || action.RolloutStrategy == nil || len(action.RolloutStrategy.Variations) == 0 {
+			return nil, localizedError(statusOpsActionRequired, locale.JaJP)
+		}
+	case autoopsproto.OpsType_SERVE_FIXED_VARIATION:
+	default:
+		return nil, localizedError(statusUnknownOpsType, locale.JaJP)
+	}
+	feature, err := s.getFeature(ctx, environmentNamespace, featureID)
+	if err != nil {
+		return nil, err
+	}
+	if opsType == autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS {
+		if err := validateRolloutAction(action, feature); err != nil {
+			return nil, err
+		}
+		return &autoopsproto.OpsAction{
+			RuleId:          action.RuleId,
+			RolloutStrategy: action.RolloutStrategy,
+		}, nil
+	}
+	variationID := ""
+	if action != nil {
+		variationID = action.VariationId
+	}
+	if variationID == "" {
+		if feature.OffVariation == "" {
+			return nil, localizedError(statusOpsActionOffVariationRequired, locale.JaJP)
+		}
+	} else if !hasVariation(feature, variationID) {
+		return nil, localizedError(statusOpsActionVariationNotFound, locale.JaJP)
+	}
+	return &autoopsproto.OpsAction{VariationId: variationID}, nil
+}
+
+func validateRolloutAction(action *autoopsproto.OpsAction, feature *featureproto.Feature) error {
+	if action.RuleId != "" && !hasRule(feature, action.RuleId) {
+		return localizedError(statusOpsActionRuleNotFound, locale.JaJP)
+	}
+	var sum int32
+	seen := make(map[string]struct{}, len(action.RolloutStrategy.Variations))
+	for _, v := range action.RolloutStrategy.Variations {
+		if !hasVariation(feature, v.Variation) {
+			return localizedError(statusOpsActionVariationNotFound, locale.JaJP)
+		}
+		if _, ok := seen[v.Variation]; ok || v.Weight < 0 {
+			return localizedError(statusOpsActionInvalidWeight, locale.JaJP)
+		}
+		seen[v.Variation] = struct{}{}
+		sum += v.Weight
+	}
+	if sum != totalVariationWeight {
+		return localizedError(statusOpsActionInvalidWeight, locale.JaJP)
+	}
+	return nil
+}
+
+func hasVariation(feature *featureproto.Feature, id string) bool {
+	for _, v := range feature.Variations {
+		if v.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRule(feature *featureproto.Feature, id string) bool {
+	for _, r := range feature.Rules {
+		if r.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AutoOpsService) getFeature(
+	ctx context.Context,
+	environmentNamespace, featureID string,
+) (*featureproto.Feature, error) {
+	resp, err := s.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   featureID,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		if code := status.Code(err); code == codes.NotFound {
+			return nil, localizedError(statusFeatureNotFound, locale.JaJP)
+		}
+		s.logger.Error(
+			"Failed to get feature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("featureId", featureID),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return resp.Feature, nil
+}
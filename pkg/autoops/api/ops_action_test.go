@@ -0,0 +1,406 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func newOpsActionTestFeature() *featureproto.Feature {
+	return &featureproto.Feature{
+		Id:      "fid",
+		Enabled: true,
+		Variations: []*featureproto.Variation{
+			{Id: "vid-0", Value: "true"},
+			{Id: "vid-1", Value: "false"},
+		},
+		Rules: []*featureproto.Rule{
+			{
+				Id: "rule-0",
+				Strategy: &featureproto.Strategy{
+					Type:          featureproto.Strategy_FIXED,
+					FixedStrategy: &featureproto.FixedStrategy{Variation: "vid-0"},
+				},
+			},
+		},
+		DefaultStrategy: &featureproto.Strategy{
+			Type:          featureproto.Strategy_FIXED,
+			FixedStrategy: &featureproto.FixedStrategy{Variation: "vid-0"},
+		},
+		OffVariation: "vid-1",
+	}
+}
+
+func TestNewOpsAction(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	rollout := func(weights ...int32) *featureproto.RolloutStrategy {
+		s := &featureproto.RolloutStrategy{}
+		for i, w := range weights {
+			s.Variations = append(s.Variations, &featureproto.RolloutStrategy_Variation{
+				Variation: []string{"vid-0", "vid-1", "vid-2"}[i],
+				Weight:    w,
+			})
+		}
+		return s
+	}
+	patterns := map[string]struct {
+		opsType     autoopsproto.OpsType
+		action      *autoopsproto.OpsAction
+		feature     *featureproto.Feature
+		expected    *autoopsproto.OpsAction
+		expectedErr error
+	}{
+		"success: disable feature has no action": {
+			opsType:  autoopsproto.OpsType_DISABLE_FEATURE,
+			action:   &autoopsproto.OpsAction{VariationId: "vid-0"},
+			expected: nil,
+		},
+		"err: rollout strategy required": {
+			opsType:     autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			expectedErr: localizedError(statusOpsActionRequired, locale.JaJP),
+		},
+		"err: rule not found": {
+			opsType:     autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			action:      &autoopsproto.OpsAction{RuleId: "rule-1", RolloutStrategy: rollout(50000, 50000)},
+			feature:     newOpsActionTestFeature(),
+			expectedErr: localizedError(statusOpsActionRuleNotFound, locale.JaJP),
+		},
+		"err: rollout variation not found": {
+			opsType:     autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			action:      &autoopsproto.OpsAction{RolloutStrategy: rollout(50000, 0, 50000)},
+			feature:     newOpsActionTestFeature(),
+			expectedErr: localizedError(statusOpsActionVariationNotFound, locale.JaJP),
+		},
+		"err: invalid weight sum": {
+			opsType:     autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			action:      &autoopsproto.OpsAction{RolloutStrategy: rollout(50000, 40000)},
+			feature:     newOpsActionTestFeature(),
+			expectedErr: localizedError(statusOpsActionInvalidWeight, locale.JaJP),
+		},
+		"success: rollout weights of a rule": {
+			opsType: autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			action: &autoopsproto.OpsAction{
+				RuleId:          "rule-0",
+				RolloutStrategy: rollout(10000, 90000),
+				VariationId:     "vid-0",
+			},
+			feature:  newOpsActionTestFeature(),
+			expected: &autoopsproto.OpsAction{RuleId: "rule-0", RolloutStrategy: rollout(10000, 90000)},
+		},
+		"err: fixed variation not found": {
+			opsType:     autoopsproto.OpsType_SERVE_FIXED_VARIATION,
+			action:      &autoopsproto.OpsAction{VariationId: "vid-2"},
+			feature:     newOpsActionTestFeature(),
+			expectedErr: localizedError(statusOpsActionVariationNotFound, locale.JaJP),
+		},
+		"err: off variation required": {
+			opsType: autoopsproto.OpsType_SERVE_FIXED_VARIATION,
+			feature: func() *featureproto.Feature {
+				f := newOpsActionTestFeature()
+				f.OffVariation = ""
+				return f
+			}(),
+			expectedErr: localizedError(statusOpsActionOffVariationRequired, locale.JaJP),
+		},
+		"success: off variation": {
+			opsType:  autoopsproto.OpsType_SERVE_FIXED_VARIATION,
+			feature:  newOpsActionTestFeature(),
+			expected: &autoopsproto.OpsAction{},
+		},
+		"success: rollback has no action": {
+			opsType:  autoopsproto.OpsType_ROLLBACK_FEATURE,
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			s := createAutoOpsService(mockController, nil)
+			if p.feature != nil {
+				s.featureClient.(*featureclientmock.MockClient).EXPECT().GetFeature(
+					gomock.Any(), gomock.Any(),
+				).Return(&featureproto.GetFeatureResponse{Feature: p.feature}, nil)
+			}
+			action, err := s.newOpsAction(context.Background(), "ns0", "fid", p.opsType, p.action)
+			assert.Equal(t, p.expectedErr, err)
+			assert.Equal(t, p.expected, action)
+		})
+	}
+}
+
+func TestExecuteTargetingOperation(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		opsType  autoopsproto.OpsType
+		action   *autoopsproto.OpsAction
+		current  *featureproto.Feature
+		expected []*featureproto.Command
+	}{
+		"update rollout weights of the default strategy": {
+			opsType: autoopsproto.OpsType_UPDATE_ROLLOUT_WEIGHTS,
+			action: &autoopsproto.OpsAction{
+				RolloutStrategy: &featureproto.RolloutStrategy{
+					Variations: []*featureproto.RolloutStrategy_Variation{
+						{Variation: "vid-0", Weight: 100000},
+					},
+				},
+			},
+			expected: []*featureproto.Command{
+				newFeatureCommand(t, &featureproto.ChangeDefaultStrategyCommand{
+					Strategy: &featureproto.Strategy{
+						Type: featureproto.Strategy_ROLLOUT,
+						RolloutStrategy: &featureproto.RolloutStrategy{
+							Variations: []*featureproto.RolloutStrategy_Variation{
+								{Variation: "vid-0", Weight: 100000},
+							},
+						},
+					},
+				}),
+			},
+		},
+		"serve off variation": {
+			opsType: autoopsproto.OpsType_SERVE_FIXED_VARIATION,
+			action:  &autoopsproto.OpsAction{},
+			current: newOpsActionTestFeature(),
+			expected: []*featureproto.Command{
+				newFeatureCommand(t, &featureproto.ChangeDefaultStrategyCommand{
+					Strategy: &featureproto.Strategy{
+						Type:          featureproto.Strategy_FIXED,
+						FixedStrategy: &featureproto.FixedStrategy{Variation: "vid-1"},
+					},
+				}),
+			},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			featureClient := featureclientmock.NewMockClient(mockController)
+			if p.current != nil {
+				featureClient.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&featureproto.GetFeatureResponse{Feature: p.current}, nil,
+				)
+			}
+			featureClient.EXPECT().UpdateFeatureTargeting(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(
+					ctx context.Context,
+					req *featureproto.UpdateFeatureTargetingRequest,
+					opts ...grpc.CallOption,
+				) (*featureproto.UpdateFeatureTargetingResponse, error) {
+					assert.Equal(t, "fid", req.Id)
+					assert.Equal(t, p.expected, req.Commands)
+					return &featureproto.UpdateFeatureTargetingResponse{}, nil
+				},
+			)
+			rule := &domain.AutoOpsRule{AutoOpsRule: &autoopsproto.AutoOpsRule{
+				Id:        "rid",
+				FeatureId: "fid",
+				OpsType:   p.opsType,
+				OpsAction: p.action,
+			}}
+			err := ExecuteOperation(context.Background(), "ns0", rule, nil, featureClient, zap.NewNop())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRollbackFeature(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	previous := newOpsActionTestFeature()
+	previous.Version = 1
+	current := newOpsActionTestFeature()
+	current.Version = 2
+	current.Enabled = false
+	current.DefaultStrategy.FixedStrategy.Variation = "vid-1"
+	patterns := map[string]struct {
+		setup       func(*featureclientmock.MockClient)
+		trigger     *autoopsproto.ExecutionTrigger
+		expectedErr error
+	}{
+		"err: no previous version": {
+			setup: func(c *featureclientmock.MockClient) {
+				c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&featureproto.GetFeatureResponse{Feature: previous}, nil,
+				)
+			},
+			expectedErr: localizedError(statusOpsActionPreviousVersionNotFound, locale.JaJP),
+		},
+		"err: feature changed since the assessed version": {
+			setup: func(c *featureclientmock.MockClient) {
+				c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&featureproto.GetFeatureResponse{Feature: current}, nil,
+				)
+			},
+			trigger:     &autoopsproto.ExecutionTrigger{ClauseId: "cid", FeatureVersion: 1},
+			expectedErr: localizedError(statusOpsActionFeatureChanged, locale.JaJP),
+		},
+		"err: previous version not in the history": {
+			setup: func(c *featureclientmock.MockClient) {
+				gomock.InOrder(
+					c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						&featureproto.GetFeatureResponse{Feature: current}, nil,
+					),
+					c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						nil, gstatus.Error(codes.NotFound, "not found"),
+					),
+				)
+			},
+			expectedErr: localizedError(statusOpsActionPreviousVersionNotFound, locale.JaJP),
+		},
+		"success: version before the assessed one restored in one update": {
+			setup: func(c *featureclientmock.MockClient) {
+				gomock.InOrder(
+					c.EXPECT().GetFeature(gomock.Any(), &featureproto.GetFeatureRequest{
+						Id:                   "fid",
+						EnvironmentNamespace: "ns0",
+					}).Return(&featureproto.GetFeatureResponse{Feature: current}, nil),
+					c.EXPECT().GetFeature(gomock.Any(), &featureproto.GetFeatureRequest{
+						Id:                   "fid",
+						EnvironmentNamespace: "ns0",
+						Version:              1,
+					}).Return(&featureproto.GetFeatureResponse{Feature: previous}, nil),
+				)
+				c.EXPECT().UpdateFeatureTargeting(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(
+						ctx context.Context,
+						req *featureproto.UpdateFeatureTargetingRequest,
+						opts ...grpc.CallOption,
+					) (*featureproto.UpdateFeatureTargetingResponse, error) {
+						assert.Equal(t, []*featureproto.Command{
+							newFeatureCommand(t, &featureproto.ChangeDefaultStrategyCommand{
+								Strategy: previous.DefaultStrategy,
+							}),
+							newFeatureCommand(t, &featureproto.EnableFeatureCommand{}),
+						}, req.Commands)
+						return &featureproto.UpdateFeatureTargetingResponse{}, nil
+					},
+				)
+			},
+			trigger:     &autoopsproto.ExecutionTrigger{ClauseId: "cid", FeatureVersion: 2},
+			expectedErr: nil,
+		},
+		"success: nothing to restore": {
+			setup: func(c *featureclientmock.MockClient) {
+				unchanged := newOpsActionTestFeature()
+				unchanged.Version = 2
+				gomock.InOrder(
+					c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						&featureproto.GetFeatureResponse{Feature: unchanged}, nil,
+					),
+					c.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						&featureproto.GetFeatureResponse{Feature: previous}, nil,
+					),
+				)
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			featureClient := featureclientmock.NewMockClient(mockController)
+			p.setup(featureClient)
+			rule := &domain.AutoOpsRule{AutoOpsRule: &autoopsproto.AutoOpsRule{
+				Id:        "rid",
+				FeatureId: "fid",
+				OpsType:   autoopsproto.OpsType_ROLLBACK_FEATURE,
+			}}
+			err := ExecuteOperation(context.Background(), "ns0", rule, p.trigger, featureClient, zap.NewNop())
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestExecuteReverseOperation(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		opsType     autoopsproto.OpsType
+		setup       func(*featureclientmock.MockClient)
+		expectedErr error
+	}{
+		"disable the enabled feature": {
+			opsType: autoopsproto.OpsType_ENABLE_FEATURE,
+			setup: func(c *featureclientmock.MockClient) {
+				c.EXPECT().DisableFeature(gomock.Any(), &featureproto.DisableFeatureRequest{
+					Id:                   "fid",
+					Command:              &featureproto.DisableFeatureCommand{},
+					EnvironmentNamespace: "ns0",
+				}).Return(&featureproto.DisableFeatureResponse{}, nil)
+			},
+		},
+		"enable the disabled feature": {
+			opsType: autoopsproto.OpsType_DISABLE_FEATURE,
+			setup: func(c *featureclientmock.MockClient) {
+				c.EXPECT().EnableFeature(gomock.Any(), &featureproto.EnableFeatureRequest{
+					Id:                   "fid",
+					Command:              &featureproto.EnableFeatureCommand{},
+					EnvironmentNamespace: "ns0",
+				}).Return(&featureproto.EnableFeatureResponse{}, nil)
+			},
+		},
+		"err: irreversible ops type": {
+			opsType:     autoopsproto.OpsType_ROLLBACK_FEATURE,
+			expectedErr: localizedError(statusIrreversibleOpsType, locale.JaJP),
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			featureClient := featureclientmock.NewMockClient(mockController)
+			if p.setup != nil {
+				p.setup(featureClient)
+			}
+			rule := &domain.AutoOpsRule{AutoOpsRule: &autoopsproto.AutoOpsRule{
+				Id:        "rid",
+				FeatureId: "fid",
+				OpsType:   p.opsType,
+			}}
+			err := ExecuteReverseOperation(context.Background(), "ns0", rule, featureClient, zap.NewNop())
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func newFeatureCommand(t *testing.T, cmd pb.Message) *featureproto.Command {
+	t.Helper()
+	c, err := ptypes.MarshalAny(cmd)
+	require.NoError(t, err)
+	return &featureproto.Command{Command: c}
+}
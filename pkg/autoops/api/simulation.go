@@ -0,0 +1,409 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const (
+	// The default schedule of the count watcher.
+	defaultSimulationInterval = 10 * time.Second
+	// maxSimulationEvaluations bounds the assessments of a simulation.
+	maxSimulationEvaluations = int64(1440)
+	// maxSimulationCountQueries bounds the event counter queries of a simulation,
+	// since each of them scans the count time range in Druid.
+	// At the default interval, it only covers about 20 minutes of one clause,
+	// so the interval of a longer simulation is coarsened.
+	maxSimulationCountQueries = int64(240)
+	// The count watcher assesses the counts of this time range until the assessment.
+	simulationCountTimeRange = -30 * 24 * time.Hour
+)
+
+func (s *AutoOpsService) SimulateAutoOpsRule(
+	ctx context.Context,
+	req *autoopsproto.SimulateAutoOpsRuleRequest,
+) (*autoopsproto.SimulateAutoOpsRuleResponse, error) {
+	// It is as expensive as many count watcher runs, so it is restricted to the editors.
+	_, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateSimulateAutoOpsRuleRequest(req); err != nil {
+		return nil, err
+	}
+	autoOpsRule := newSimulatedAutoOpsRule(req.AutoOpsRule)
+	featureVersion := req.FeatureVersion
+	if featureVersion == 0 {
+		resp, err := s.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+			Id:                   autoOpsRule.FeatureId,
+			EnvironmentNamespace: req.EnvironmentNamespace,
+		})
+		if err != nil {
+			s.logger.Error(
+				"Failed to get feature",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", req.EnvironmentNamespace),
+					zap.String("featureId", autoOpsRule.FeatureId),
+				)...,
+			)
+			return nil, localizedError(statusInternal, locale.JaJP)
+		}
+		featureVersion = resp.Feature.Version
+	}
+	queries, err := simulationCountQueries(autoOpsRule)
+	if err != nil {
+		return nil, localizedError(statusInvalidRequest, locale.JaJP)
+	}
+	interval := simulationInterval(req.IntervalSeconds, req.EndAt-req.StartAt, queries)
+	executions, err := s.simulate(
+		ctx,
+		req.EnvironmentNamespace,
+		autoOpsRule,
+		featureVersion,
+		time.Unix(req.StartAt, 0),
+		time.Unix(req.EndAt, 0),
+		interval,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to simulate autoOpsRule",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("featureId", autoOpsRule.FeatureId),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &autoopsproto.SimulateAutoOpsRuleResponse{
+		SimulatedExecutions: executions,
+		IntervalSeconds:     int64(interval.Seconds()),
+	}, nil
+}
+
+func (s *AutoOpsService) validateSimulateAutoOpsRuleRequest(req *autoopsproto.SimulateAutoOpsRuleRequest) error {
+	if req.AutoOpsRule == nil {
+		return localizedError(statusAutoOpsRuleRequired, locale.JaJP)
+	}
+	if req.AutoOpsRule.FeatureId == "" {
+		return localizedError(statusFeatureIDRequired, locale.JaJP)
+	}
+	if len(req.AutoOpsRule.Clauses) == 0 {
+		return localizedError(statusClauseRequired, locale.JaJP)
+	}
+	if req.StartAt <= 0 || req.EndAt <= req.StartAt || req.EndAt > time.Now().Unix() {
+		return localizedError(statusSimulationInvalidTimeRange, locale.JaJP)
+	}
+	if req.IntervalSeconds < 0 {
+		return localizedError(statusSimulationInvalidInterval, locale.JaJP)
+	}
+	if err := s.validateTriggerSettings(
+		req.AutoOpsRule.ClauseOperator,
+		req.AutoOpsRule.CooldownSeconds,
+		req.AutoOpsRule.SustainedEvaluations,
+	); err != nil {
+		return err
+	}
+	autoOpsRule := newSimulatedAutoOpsRule(req.AutoOpsRule)
+	webhookClauses, err := autoOpsRule.ExtractWebhookClauses()
+	if err != nil {
+		return localizedError(statusInvalidRequest, locale.JaJP)
+	}
+	prometheusClauses, err := autoOpsRule.ExtractPrometheusClauses()
+	if err != nil {
+		return localizedError(statusInvalidRequest, locale.JaJP)
+	}
+	if len(webhookClauses) > 0 || len(prometheusClauses) > 0 || autoOpsRule.HasScheduleClause() {
+		return localizedError(statusSimulationUnsupportedClause, locale.JaJP)
+	}
+	opsEventRateClauses, err := autoOpsRule.ExtractOpsEventRateClauses()
+	if err != nil {
+		return localizedError(statusInvalidRequest, locale.JaJP)
+	}
+	for _, c := range opsEventRateClauses {
+		if err := s.validateOpsEventRateClause(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simulationInterval returns the interval the rule is replayed at.
+// The requested interval is coarsened so that the simulation doesn't exceed
+// the max evaluations nor the max count queries, so the longer the time range is,
+// the fewer of the assessments of the count watcher are replayed.
+func simulationInterval(intervalSeconds, timeRangeSeconds, queriesPerEvaluation int64) time.Duration {
+	interval := defaultSimulationInterval
+	if intervalSeconds != 0 {
+		interval = time.Duration(intervalSeconds) * time.Second
+	}
+	maxEvaluations := maxSimulationEvaluations
+	if queriesPerEvaluation > 0 && maxSimulationCountQueries/queriesPerEvaluation < maxEvaluations {
+		maxEvaluations = maxSimulationCountQueries / queriesPerEvaluation
+	}
+	if maxEvaluations < 1 {
+		maxEvaluations = 1
+	}
+	minSeconds := (timeRangeSeconds + maxEvaluations - 1) / maxEvaluations
+	if int64(interval.Seconds()) < minSeconds {
+		return time.Duration(minSeconds) * time.Second
+	}
+	return interval
+}
+
+// simulationCountQueries returns the event counter queries of an assessment of the rule.
+func simulationCountQueries(a *domain.AutoOpsRule) (int64, error) {
+	opsEventRateClauses, err := a.ExtractOpsEventRateClauses()
+	if err != nil {
+		return 0, err
+	}
+	queries := int64(0)
+	for _, c := range opsEventRateClauses {
+		// The evaluation and goal counts of the variation, and of the baseline if any.
+		queries += 2
+		if c.BaselineVariationId != "" {
+			queries += 2
+		}
+	}
+	return queries, nil
+}
+
+// newSimulatedAutoOpsRule copies the rule definition so that every clause has an id,
+// because the clauses of a rule that is not created yet have no ids.
+func newSimulatedAutoOpsRule(rule *autoopsproto.AutoOpsRule) *domain.AutoOpsRule {
+	r := pb.Clone(rule).(*autoopsproto.AutoOpsRule)
+	for i, c := range r.Clauses {
+		if c.Id == "" {
+			c.Id = strconv.Itoa(i)
+		}
+	}
+	return &domain.AutoOpsRule{AutoOpsRule: r}
+}
+
+// simulate replays the rule at every interval from the start to the end
+// and returns the executions it would have taken.
+// The sustained evaluations, the cooldown and the clause operator are applied as the watchers do.
+func (s *AutoOpsService) simulate(
+	ctx context.Context,
+	environmentNamespace string,
+	a *domain.AutoOpsRule,
+	featureVersion int32,
+	startAt, endAt time.Time,
+	interval time.Duration,
+) ([]*autoopsproto.SimulatedExecution, error) {
+	opsEventRateClauses, err := a.ExtractOpsEventRateClauses()
+	if err != nil {
+		return nil, err
+	}
+	sustainedEvaluations := int(a.SustainedEvaluations)
+	if sustainedEvaluations < 1 {
+		sustainedEvaluations = 1
+	}
+	executions := []*autoopsproto.SimulatedExecution{}
+	streaks := make(map[string]int, len(opsEventRateClauses))
+	var triggeredAt int64
+	for at := startAt.Add(interval); !at.After(endAt); at = at.Add(interval) {
+		now := at.Unix()
+		if triggeredAt > 0 {
+			if a.CooldownSeconds == 0 {
+				break
+			}
+			if now < triggeredAt+a.CooldownSeconds {
+				continue
+			}
+			// The rule is re-armed, so the assessments before it are not taken into account.
+			triggeredAt = 0
+			streaks = make(map[string]int, len(opsEventRateClauses))
+		}
+		var trigger *autoopsproto.ExecutionTrigger
+		results := make(map[string]bool, len(a.Clauses))
+		for id, c := range opsEventRateClauses {
+			satisfied, opsEventCount, evaluationCount, err := s.assessOpsEventRateClauseAt(
+				ctx,
+				environmentNamespace,
+				a.FeatureId,
+				featureVersion,
+				c,
+				at,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if !satisfied {
+				streaks[id] = 0
+				continue
+			}
+			streaks[id]++
+			if streaks[id] < sustainedEvaluations {
+				continue
+			}
+			results[id] = true
+			trigger = &autoopsproto.ExecutionTrigger{
+				ClauseId:        id,
+				OpsEventCount:   opsEventCount,
+				EvaluationCount: evaluationCount,
+			}
+		}
+		datetimeResults, err := a.AssessDatetimeClauses(now)
+		if err != nil {
+			return nil, err
+		}
+		for id, r := range datetimeResults {
+			results[id] = r
+		}
+		if !a.Assess(results) {
+			continue
+		}
+		if trigger == nil {
+			// The rule is satisfied only by its datetime clauses.
+			if trigger, err = a.LatestDatetimeTrigger(now); err != nil {
+				return nil, err
+			}
+		}
+		executions = append(executions, &autoopsproto.SimulatedExecution{
+			ExecutedAt: now,
+			Trigger:    trigger,
+		})
+		triggeredAt = now
+	}
+	return executions, nil
+}
+
+// assessOpsEventRateClauseAt assesses the clause with the counts the count watcher would have got at the time.
+// It also returns the ops event count and the evaluation count of the variation.
+func (s *AutoOpsService) assessOpsEventRateClauseAt(
+	ctx context.Context,
+	environmentNamespace, featureID string,
+	featureVersion int32,
+	clause *autoopsproto.OpsEventRateClause,
+	at time.Time,
+) (bool, int64, int64, error) {
+	evaluationCount, opsEventCount, err := s.getVariationCountsAt(
+		ctx,
+		environmentNamespace,
+		featureID,
+		featureVersion,
+		clause.VariationId,
+		clause.GoalId,
+		at,
+	)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if evaluationCount == 0 {
+		return false, 0, 0, nil
+	}
+	if !domain.AssessOpsEventRate(clause, opsEventCount, evaluationCount) {
+		return false, opsEventCount, evaluationCount, nil
+	}
+	if clause.BaselineVariationId == "" {
+		return true, opsEventCount, evaluationCount, nil
+	}
+	baselineEvaluationCount, baselineOpsEventCount, err := s.getVariationCountsAt(
+		ctx,
+		environmentNamespace,
+		featureID,
+		featureVersion,
+		clause.BaselineVariationId,
+		clause.GoalId,
+		at,
+	)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if baselineEvaluationCount == 0 {
+		return false, opsEventCount, evaluationCount, nil
+	}
+	significant, _, _, err := domain.AssessOpsEventRateSignificance(
+		clause,
+		baselineOpsEventCount,
+		baselineEvaluationCount,
+		opsEventCount,
+		evaluationCount,
+	)
+	if err != nil {
+		if err == stats.ErrZeroVariance {
+			return false, opsEventCount, evaluationCount, nil
+		}
+		return false, 0, 0, err
+	}
+	return significant, opsEventCount, evaluationCount, nil
+}
+
+// getVariationCountsAt returns the evaluation user count and the goal user count of the variation
+// over the count time range until the time.
+func (s *AutoOpsService) getVariationCountsAt(
+	ctx context.Context,
+	environmentNamespace, featureID string,
+	featureVersion int32,
+	variationID, goalID string,
+	at time.Time,
+) (int64, int64, error) {
+	startAt := at.Add(simulationCountTimeRange).Unix()
+	endAt := at.Unix()
+	evaluationResp, err := s.eventCounterClient.GetEvaluationCountV2(ctx, &ecproto.GetEvaluationCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              startAt,
+		EndAt:                endAt,
+		FeatureId:            featureID,
+		FeatureVersion:       featureVersion,
+		VariationIds:         []string{variationID},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	evaluationCount := variationUserCount(evaluationResp.Count.GetRealtimeCounts(), variationID)
+	if evaluationCount == 0 {
+		return 0, 0, nil
+	}
+	goalResp, err := s.eventCounterClient.GetGoalCountV2(ctx, &ecproto.GetGoalCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              startAt,
+		EndAt:                endAt,
+		FeatureId:            featureID,
+		FeatureVersion:       featureVersion,
+		VariationIds:         []string{variationID},
+		GoalId:               goalID,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return evaluationCount, variationUserCount(goalResp.GoalCounts.GetRealtimeCounts(), variationID), nil
+}
+
+func variationUserCount(counts []*ecproto.VariationCount, variationID string) int64 {
+	for _, vc := range counts {
+		if vc.VariationId == variationID {
+			return vc.UserCount
+		}
+	}
+	return 0
+}
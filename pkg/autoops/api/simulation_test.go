@@ -0,0 +1,238 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ecclientmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+)
+
+func TestSimulateAutoOpsRule(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	endAt := time.Now().Add(-time.Hour).Unix()
+	startAt := endAt - 60
+	opsEventRateClause := &autoopsproto.OpsEventRateClause{
+		VariationId:     "vid",
+		GoalId:          "gid",
+		MinCount:        10,
+		ThreadsholdRate: 0.5,
+		Operator:        autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+	}
+	newRule := func(t *testing.T, cooldownSeconds int64, clauses ...*autoopsproto.Clause) *autoopsproto.AutoOpsRule {
+		t.Helper()
+		c, err := ptypes.MarshalAny(opsEventRateClause)
+		require.NoError(t, err)
+		return &autoopsproto.AutoOpsRule{
+			FeatureId:       "fid",
+			OpsType:         autoopsproto.OpsType_DISABLE_FEATURE,
+			Clauses:         append([]*autoopsproto.Clause{{Clause: c}}, clauses...),
+			CooldownSeconds: cooldownSeconds,
+		}
+	}
+	webhookClause, err := ptypes.MarshalAny(&autoopsproto.WebhookClause{WebhookId: "wid"})
+	require.NoError(t, err)
+	expectCounts := func(s *AutoOpsService, userCounts ...int64) {
+		ec := s.eventCounterClient.(*ecclientmock.MockClient)
+		for _, uc := range userCounts {
+			ec.EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+				&ecproto.GetEvaluationCountV2Response{
+					Count: &ecproto.EvaluationCount{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid", UserCount: 20}},
+					},
+				}, nil,
+			)
+			ec.EXPECT().GetGoalCountV2(gomock.Any(), gomock.Any()).Return(
+				&ecproto.GetGoalCountV2Response{
+					GoalCounts: &ecproto.GoalCounts{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid", UserCount: uc}},
+					},
+				}, nil,
+			)
+		}
+	}
+	patterns := map[string]struct {
+		setup              func(*AutoOpsService)
+		req                *autoopsproto.SimulateAutoOpsRuleRequest
+		expectedExecutedAt []int64
+		expectedErr        error
+	}{
+		"err: ErrAutoOpsRuleRequired": {
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				StartAt:              startAt,
+				EndAt:                endAt,
+			},
+			expectedErr: localizedError(statusAutoOpsRuleRequired, locale.JaJP),
+		},
+		"err: ErrInvalidTimeRange": {
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0),
+				StartAt:              endAt,
+				EndAt:                startAt,
+			},
+			expectedErr: localizedError(statusSimulationInvalidTimeRange, locale.JaJP),
+		},
+		"err: ErrInvalidTimeRange: future": {
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0),
+				StartAt:              startAt,
+				EndAt:                time.Now().Add(time.Hour).Unix(),
+			},
+			expectedErr: localizedError(statusSimulationInvalidTimeRange, locale.JaJP),
+		},
+		"err: ErrInvalidInterval": {
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0),
+				StartAt:              startAt,
+				EndAt:                endAt,
+				IntervalSeconds:      -1,
+			},
+			expectedErr: localizedError(statusSimulationInvalidInterval, locale.JaJP),
+		},
+		"err: ErrUnsupportedClause": {
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0, &autoopsproto.Clause{Clause: webhookClause}),
+				StartAt:              startAt,
+				EndAt:                endAt,
+			},
+			expectedErr: localizedError(statusSimulationUnsupportedClause, locale.JaJP),
+		},
+		"err: ErrInternal": {
+			setup: func(s *AutoOpsService) {
+				s.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetEvaluationCountV2(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0),
+				StartAt:              startAt,
+				EndAt:                endAt,
+				FeatureVersion:       1,
+			},
+			expectedErr: localizedError(statusInternal, locale.JaJP),
+		},
+		"success: without cooldown": {
+			setup: func(s *AutoOpsService) {
+				expectCounts(s, 5, 10)
+			},
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 0),
+				StartAt:              startAt,
+				EndAt:                endAt,
+				IntervalSeconds:      20,
+				FeatureVersion:       1,
+			},
+			expectedExecutedAt: []int64{startAt + 40},
+		},
+		"success: with cooldown": {
+			setup: func(s *AutoOpsService) {
+				expectCounts(s, 10, 10)
+			},
+			req: &autoopsproto.SimulateAutoOpsRuleRequest{
+				EnvironmentNamespace: "ns0",
+				AutoOpsRule:          newRule(t, 30),
+				StartAt:              startAt,
+				EndAt:                endAt,
+				IntervalSeconds:      20,
+				FeatureVersion:       1,
+			},
+			expectedExecutedAt: []int64{startAt + 20, startAt + 60},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			s := createAutoOpsService(mockController, nil)
+			if p.setup != nil {
+				p.setup(s)
+			}
+			ctx := createContextWithTokenRoleOwner(t)
+			resp, err := s.SimulateAutoOpsRule(ctx, p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, p.req.IntervalSeconds, resp.IntervalSeconds)
+			executedAt := make([]int64, 0, len(resp.SimulatedExecutions))
+			for _, e := range resp.SimulatedExecutions {
+				executedAt = append(executedAt, e.ExecutedAt)
+				assert.Equal(t, "0", e.Trigger.ClauseId)
+			}
+			assert.Equal(t, p.expectedExecutedAt, executedAt)
+		})
+	}
+}
+
+func TestSimulationInterval(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		intervalSeconds      int64
+		timeRangeSeconds     int64
+		queriesPerEvaluation int64
+		expected             time.Duration
+	}{
+		"default interval": {
+			timeRangeSeconds:     60,
+			queriesPerEvaluation: 2,
+			expected:             defaultSimulationInterval,
+		},
+		"requested interval": {
+			intervalSeconds:      20,
+			timeRangeSeconds:     60,
+			queriesPerEvaluation: 2,
+			expected:             20 * time.Second,
+		},
+		"coarsened by the max count queries": {
+			timeRangeSeconds:     24 * 60 * 60,
+			queriesPerEvaluation: 4,
+			expected:             24 * 60 * 60 / 60 * time.Second,
+		},
+		"coarsened by the max evaluations": {
+			timeRangeSeconds:     30 * 24 * 60 * 60,
+			queriesPerEvaluation: 0,
+			expected:             30 * 24 * 60 * 60 / 1440 * time.Second,
+		},
+		"one evaluation of too many clauses": {
+			timeRangeSeconds:     60,
+			queriesPerEvaluation: 480,
+			expected:             60 * time.Second,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			actual := simulationInterval(p.intervalSeconds, p.timeRangeSeconds, p.queriesPerEvaluation)
+			assert.Equal(t, p.expected, actual)
+		})
+	}
+}
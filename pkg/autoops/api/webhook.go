@@ -16,7 +16,9 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"strconv"
 
 	"go.uber.org/zap"
@@ -37,8 +39,13 @@ import (
 
 const (
 	webhookAuthKey = "auth"
+
+	webhookSigningSecretBytes          = 32
+	maxWebhookSecretGracePeriodSeconds = int64(7 * 24 * 60 * 60)
 )
 
+var errWebhookSigningSecretNotFound = errors.New("autoops: webhook signing secret not found")
+
 func (s *AutoOpsService) CreateWebhook(
 	ctx context.Context,
 	req *autoopspb.CreateWebhookRequest,
@@ -78,7 +85,11 @@ func (s *AutoOpsService) createWebhook(
 	if err != nil {
 		return nil, err
 	}
-	secret, err := s.generateWebhookSecret(ctx, id.String(), req.EnvironmentNamespace)
+	secret, err := s.generateWebhookSecret(ctx, id.String(), req.EnvironmentNamespace, 0)
+	if err != nil {
+		return nil, err
+	}
+	signingSecret, encryptedSigningSecret, err := s.generateWebhookSigningSecret(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +98,14 @@ func (s *AutoOpsService) createWebhook(
 		return nil, err
 	}
 
-	webhook := domain.NewWebhook(id.String(), req.Command.Name, req.Command.Description)
+	webhook := domain.NewWebhook(
+		id.String(),
+		req.Command.Name,
+		req.Command.Description,
+		req.Command.PayloadFormat,
+		req.Command.SignatureRequired,
+		encryptedSigningSecret,
+	)
 	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
 		webhookStorage := v2as.NewWebhookStorage(tx)
 		err := webhookStorage.CreateWebhook(ctx, webhook, req.EnvironmentNamespace)
@@ -109,16 +127,18 @@ func (s *AutoOpsService) createWebhook(
 		return nil, err
 	}
 	return &autoopspb.CreateWebhookResponse{
-		Webhook: webhook.Webhook,
-		Url:     s.createWebhookURL(secret),
+		Webhook:       webhook.Webhook,
+		Url:           s.createWebhookURL(secret),
+		SigningSecret: signingSecret,
 	}, nil
 }
 
 func (s *AutoOpsService) generateWebhookSecret(
 	ctx context.Context,
 	id, environmentNamespace string,
+	version int32,
 ) (string, error) {
-	ws := domain.NewWebhookSecret(id, environmentNamespace)
+	ws := domain.NewWebhookSecret(id, environmentNamespace, version)
 	encoded, err := ws.Marshal()
 	if err != nil {
 		s.logger.Error(
@@ -138,6 +158,25 @@ func (s *AutoOpsService) generateWebhookSecret(
 	return base64.RawURLEncoding.EncodeToString(encrypted), nil
 }
 
+// generateWebhookSigningSecret returns a new signing secret and its encrypted bytes to be stored.
+// The signing secret is only shown to the user when it is generated.
+func (s *AutoOpsService) generateWebhookSigningSecret(ctx context.Context) (string, []byte, error) {
+	b := make([]byte, webhookSigningSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	encrypted, err := s.webhookCryptoUtil.Encrypt(ctx, []byte(secret))
+	if err != nil {
+		s.logger.Error(
+			"Failed to encrypt webhook signing secret",
+			log.FieldsFromImcomingContext(ctx).AddFields(zap.Error(err))...,
+		)
+		return "", nil, err
+	}
+	return secret, encrypted, nil
+}
+
 func validateCreateWebhook(
 	req *autoopspb.CreateWebhookRequest,
 	localizer locale.Localizer,
@@ -154,9 +193,20 @@ func validateCreateWebhook(
 			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "webhook name"),
 		})
 	}
+	if !isValidWebhookPayloadFormat(req.Command.PayloadFormat) {
+		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "payload format"),
+		})
+	}
 	return nil, nil
 }
 
+func isValidWebhookPayloadFormat(payloadFormat autoopspb.Webhook_PayloadFormat) bool {
+	_, ok := autoopspb.Webhook_PayloadFormat_name[int32(payloadFormat)]
+	return ok
+}
+
 func (s *AutoOpsService) GetWebhook(
 	ctx context.Context,
 	req *autoopspb.GetWebhookRequest,
@@ -180,10 +230,6 @@ func (s *AutoOpsService) GetWebhook(
 		)
 		return nil, status.Err()
 	}
-	secret, err := s.generateWebhookSecret(ctx, req.Id, req.EnvironmentNamespace)
-	if err != nil {
-		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
-	}
 	webhookStorage := v2as.NewWebhookStorage(s.mysqlClient)
 	webhook, err := webhookStorage.GetWebhook(ctx, req.Id, req.EnvironmentNamespace)
 	if err != nil {
@@ -207,6 +253,10 @@ func (s *AutoOpsService) GetWebhook(
 		}
 		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
 	}
+	secret, err := s.generateWebhookSecret(ctx, req.Id, req.EnvironmentNamespace, webhook.SecretVersion)
+	if err != nil {
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
 	return &autoopspb.GetWebhookResponse{
 		Webhook: webhook.Webhook,
 		Url:     s.createWebhookURL(secret),
@@ -364,6 +414,12 @@ func (s *AutoOpsService) UpdateWebhook(
 	if req.ChangeWebhookNameCommand != nil {
 		commands = append(commands, req.ChangeWebhookNameCommand)
 	}
+	if req.ChangeWebhookPayloadFormatCommand != nil {
+		commands = append(commands, req.ChangeWebhookPayloadFormatCommand)
+	}
+	if req.ChangeWebhookSignatureRequiredCommand != nil {
+		commands = append(commands, req.ChangeWebhookSignatureRequiredCommand)
+	}
 	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
 		webhookStorage := v2as.NewWebhookStorage(tx)
 		webhook, err := webhookStorage.GetWebhook(ctx, req.Id, req.EnvironmentNamespace)
@@ -376,9 +432,22 @@ func (s *AutoOpsService) UpdateWebhook(
 				return err
 			}
 		}
+		if webhook.SignatureRequired && len(webhook.SigningSecret) == 0 {
+			return errWebhookSigningSecretNotFound
+		}
 		return webhookStorage.UpdateWebhook(ctx, webhook, req.EnvironmentNamespace)
 	})
 	if err != nil {
+		if err == errWebhookSigningSecretNotFound {
+			dt, err := statusWebhookSigningSecretNotFound.WithDetails(&errdetails.LocalizedMessage{
+				Locale:  localizer.GetLocale(),
+				Message: localizer.MustLocalizeWithTemplate(locale.NotFoundError, "signing secret"),
+			})
+			if err != nil {
+				return nil, statusInternal.Err()
+			}
+			return nil, dt.Err()
+		}
 		if err == v2as.ErrWebhookNotFound || err == v2as.ErrAutoOpsRuleUnexpectedAffectedRows {
 			dt, err := statusWebhookNotFound.WithDetails(&errdetails.LocalizedMessage{
 				Locale:  localizer.GetLocale(),
@@ -411,7 +480,10 @@ func validateUpdateWebhookRequest(
 			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "id"),
 		})
 	}
-	if req.ChangeWebhookNameCommand == nil && req.ChangeWebhookDescriptionCommand == nil {
+	if req.ChangeWebhookNameCommand == nil &&
+		req.ChangeWebhookDescriptionCommand == nil &&
+		req.ChangeWebhookPayloadFormatCommand == nil &&
+		req.ChangeWebhookSignatureRequiredCommand == nil {
 		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
 			Locale:  localizer.GetLocale(),
 			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "command"),
@@ -423,6 +495,122 @@ func validateUpdateWebhookRequest(
 			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "webhook name"),
 		})
 	}
+	if req.ChangeWebhookPayloadFormatCommand != nil &&
+		!isValidWebhookPayloadFormat(req.ChangeWebhookPayloadFormatCommand.PayloadFormat) {
+		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "payload format"),
+		})
+	}
+	return nil, nil
+}
+
+// RotateWebhookSecret issues a new signing secret and webhook URL.
+// The previous ones remain valid until the grace period in the command elapses.
+func (s *AutoOpsService) RotateWebhookSecret(
+	ctx context.Context,
+	req *autoopspb.RotateWebhookSecretRequest,
+) (*autoopspb.RotateWebhookSecretResponse, error) {
+	editor, err := s.checkRole(ctx, accountpb.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	localizer := locale.NewLocalizer(locale.NewLocale(locale.JaJP))
+	status, err := validateRotateWebhookSecretRequest(req, localizer)
+	if err != nil {
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
+	if status != nil {
+		s.logger.Error(
+			"Failed to validate webhook secret rotate request",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(status.Err()),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, status.Err()
+	}
+	signingSecret, encryptedSigningSecret, err := s.generateWebhookSigningSecret(ctx)
+	if err != nil {
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
+	var webhook *domain.Webhook
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		webhookStorage := v2as.NewWebhookStorage(tx)
+		webhook, err = webhookStorage.GetWebhook(ctx, req.Id, req.EnvironmentNamespace)
+		if err != nil {
+			return err
+		}
+		webhook.RotateSecret(encryptedSigningSecret, req.Command.GracePeriodSeconds)
+		handler := command.NewWebhookCommandHandler(editor, s.publisher, webhook, req.EnvironmentNamespace)
+		if err := handler.Handle(ctx, req.Command); err != nil {
+			return err
+		}
+		return webhookStorage.UpdateWebhook(ctx, webhook, req.EnvironmentNamespace)
+	})
+	if err != nil {
+		if err == v2as.ErrWebhookNotFound || err == v2as.ErrWebhookUnexpectedAffectedRows {
+			dt, err := statusWebhookNotFound.WithDetails(&errdetails.LocalizedMessage{
+				Locale:  localizer.GetLocale(),
+				Message: localizer.MustLocalizeWithTemplate(locale.NotFoundError, "webhook"),
+			})
+			if err != nil {
+				return nil, statusInternal.Err()
+			}
+			return nil, dt.Err()
+		}
+		s.logger.Error(
+			"Failed to rotate webhook secret",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
+	secret, err := s.generateWebhookSecret(ctx, req.Id, req.EnvironmentNamespace, webhook.SecretVersion)
+	if err != nil {
+		return nil, s.reportInternalServerError(ctx, err, req.EnvironmentNamespace, localizer)
+	}
+	return &autoopspb.RotateWebhookSecretResponse{
+		Webhook:       webhook.Webhook,
+		Url:           s.createWebhookURL(secret),
+		SigningSecret: signingSecret,
+	}, nil
+}
+
+func validateRotateWebhookSecretRequest(
+	req *autoopspb.RotateWebhookSecretRequest,
+	localizer locale.Localizer,
+) (*status.Status, error) {
+	if req.Id == "" {
+		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "id"),
+		})
+	}
+	if req.Command == nil {
+		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "command"),
+		})
+	}
+	if req.Command.GracePeriodSeconds < 0 || req.Command.GracePeriodSeconds > maxWebhookSecretGracePeriodSeconds {
+		return statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "grace period"),
+		})
+	}
 	return nil, nil
 }
 
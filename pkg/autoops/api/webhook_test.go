@@ -28,6 +28,8 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
 	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
 	autoopspb "github.com/bucketeer-io/bucketeer/proto/autoops"
 )
@@ -68,18 +70,31 @@ func TestCreateWebhook(t *testing.T) {
 			},
 			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "webhook name")),
 		},
+		"err: ErrInvalidPayloadFormat": {
+			req: &autoopspb.CreateWebhookRequest{
+				Command: &autoopspb.CreateWebhookCommand{
+					Name:          "name",
+					PayloadFormat: autoopspb.Webhook_PayloadFormat(-1),
+				},
+			},
+			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "payload format")),
+		},
 		"success": {
 			setup: baseSetup,
 			req: &autoopspb.CreateWebhookRequest{
 				Command: &autoopspb.CreateWebhookCommand{
-					Name:        "name",
-					Description: "description",
+					Name:              "name",
+					Description:       "description",
+					PayloadFormat:     autoopspb.Webhook_ALERTMANAGER,
+					SignatureRequired: true,
 				},
 			},
 			resp: &autoopspb.CreateWebhookResponse{
 				Webhook: &autoopspb.Webhook{
-					Name:        "name",
-					Description: "description",
+					Name:              "name",
+					Description:       "description",
+					PayloadFormat:     autoopspb.Webhook_ALERTMANAGER,
+					SignatureRequired: true,
 				},
 				Url: "https://bucketeer.io/hook?auth=secret",
 			},
@@ -95,6 +110,9 @@ func TestCreateWebhook(t *testing.T) {
 			if p.resp != nil {
 				assert.Equal(t, p.resp.Webhook.Name, resp.Webhook.Name)
 				assert.Equal(t, p.resp.Webhook.Description, resp.Webhook.Description)
+				assert.Equal(t, p.resp.Webhook.PayloadFormat, resp.Webhook.PayloadFormat)
+				assert.Equal(t, p.resp.Webhook.SignatureRequired, resp.Webhook.SignatureRequired)
+				assert.NotEmpty(t, resp.SigningSecret)
 			}
 			assert.Equal(t, p.expectedErr, err)
 		})
@@ -286,6 +304,16 @@ func TestUpdateWebhook(t *testing.T) {
 			},
 			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "webhook name")),
 		},
+		"err: ErrInvalidPayloadFormat": {
+			req: &autoopspb.UpdateWebhookRequest{
+				Id:                   "id-0",
+				EnvironmentNamespace: "ns0",
+				ChangeWebhookPayloadFormatCommand: &autoopspb.ChangeWebhookPayloadFormatCommand{
+					PayloadFormat: autoopspb.Webhook_PayloadFormat(-1),
+				},
+			},
+			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "payload format")),
+		},
 		"success": {
 			setup: func(s *AutoOpsService) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
@@ -317,6 +345,97 @@ func TestUpdateWebhook(t *testing.T) {
 	}
 }
 
+func TestRotateWebhookSecret(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	localizer := locale.NewLocalizer(locale.NewLocale(locale.JaJP))
+	createError := func(msg string) error {
+		status, err := statusInvalidRequest.WithDetails(&errdetails.LocalizedMessage{
+			Locale:  localizer.GetLocale(),
+			Message: msg,
+		})
+		require.NoError(t, err)
+		return status.Err()
+	}
+	ctx := createContextWithTokenRoleOwner(t)
+
+	patterns := map[string]struct {
+		setup           func(*AutoOpsService)
+		req             *autoopspb.RotateWebhookSecretRequest
+		expectedVersion int32
+		expectedErr     error
+	}{
+		"err: ErrNoId": {
+			req: &autoopspb.RotateWebhookSecretRequest{
+				EnvironmentNamespace: "ns0",
+				Command:              &autoopspb.RotateWebhookSecretCommand{},
+			},
+			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "id")),
+		},
+		"err: ErrNoCommand": {
+			req: &autoopspb.RotateWebhookSecretRequest{
+				Id:                   "id-0",
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.RequiredFieldTemplate, "command")),
+		},
+		"err: ErrInvalidGracePeriod": {
+			req: &autoopspb.RotateWebhookSecretRequest{
+				Id:                   "id-0",
+				EnvironmentNamespace: "ns0",
+				Command: &autoopspb.RotateWebhookSecretCommand{
+					GracePeriodSeconds: maxWebhookSecretGracePeriodSeconds + 1,
+				},
+			},
+			expectedErr: createError(localizer.MustLocalizeWithTemplate(locale.InvalidArgumentError, "grace period")),
+		},
+		"success": {
+			setup: func(s *AutoOpsService) {
+				tx := mysqlmock.NewMockTransaction(mockController)
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				tx.EXPECT().QueryRowContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
+				result := mysqlmock.NewMockResult(mockController)
+				result.EXPECT().RowsAffected().Return(int64(1), nil)
+				tx.EXPECT().ExecContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(result, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(tx, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(ctx context.Context, tx mysql.Transaction, f func() error) error {
+					return f()
+				})
+				s.publisher.(*publishermock.MockPublisher).EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			req: &autoopspb.RotateWebhookSecretRequest{
+				Id:                   "id-0",
+				EnvironmentNamespace: "ns0",
+				Command: &autoopspb.RotateWebhookSecretCommand{
+					GracePeriodSeconds: 3600,
+				},
+			},
+			expectedVersion: 1,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			service := createAutoOpsService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.RotateWebhookSecret(ctx, p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, p.expectedVersion, resp.Webhook.SecretVersion)
+			assert.NotZero(t, resp.Webhook.PreviousSecretExpiresAt)
+			assert.NotEmpty(t, resp.SigningSecret)
+			assert.NotEmpty(t, resp.Url)
+		})
+	}
+}
+
 func TestDeleteWebhook(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -427,7 +546,7 @@ func TestGenerateWebhookSecret(t *testing.T) {
 	}
 	for msg, p := range testcases {
 		t.Run(msg, func(t *testing.T) {
-			secret, err := service.generateWebhookSecret(ctx, p.id, p.environmentNamespace)
+			secret, err := service.generateWebhookSecret(ctx, p.id, p.environmentNamespace, 0)
 			require.NoError(t, err)
 			ws := dummyWebhookSecret{}
 			decoded, err := base64.RawURLEncoding.DecodeString(secret)
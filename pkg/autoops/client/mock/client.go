@@ -189,6 +189,26 @@ func (mr *MockClientMockRecorder) GetWebhook(ctx, in interface{}, opts ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockClient)(nil).GetWebhook), varargs...)
 }
 
+// ListAutoOpsExecutions mocks base method.
+func (m *MockClient) ListAutoOpsExecutions(ctx context.Context, in *autoops.ListAutoOpsExecutionsRequest, opts ...grpc.CallOption) (*autoops.ListAutoOpsExecutionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListAutoOpsExecutions", varargs...)
+	ret0, _ := ret[0].(*autoops.ListAutoOpsExecutionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAutoOpsExecutions indicates an expected call of ListAutoOpsExecutions.
+func (mr *MockClientMockRecorder) ListAutoOpsExecutions(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAutoOpsExecutions", reflect.TypeOf((*MockClient)(nil).ListAutoOpsExecutions), varargs...)
+}
+
 // ListAutoOpsRules mocks base method.
 func (m *MockClient) ListAutoOpsRules(ctx context.Context, in *autoops.ListAutoOpsRulesRequest, opts ...grpc.CallOption) (*autoops.ListAutoOpsRulesResponse, error) {
 	m.ctrl.T.Helper()
@@ -209,6 +229,26 @@ func (mr *MockClientMockRecorder) ListAutoOpsRules(ctx, in interface{}, opts ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAutoOpsRules", reflect.TypeOf((*MockClient)(nil).ListAutoOpsRules), varargs...)
 }
 
+// ListOpsCountHistory mocks base method.
+func (m *MockClient) ListOpsCountHistory(ctx context.Context, in *autoops.ListOpsCountHistoryRequest, opts ...grpc.CallOption) (*autoops.ListOpsCountHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListOpsCountHistory", varargs...)
+	ret0, _ := ret[0].(*autoops.ListOpsCountHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpsCountHistory indicates an expected call of ListOpsCountHistory.
+func (mr *MockClientMockRecorder) ListOpsCountHistory(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpsCountHistory", reflect.TypeOf((*MockClient)(nil).ListOpsCountHistory), varargs...)
+}
+
 // ListOpsCounts mocks base method.
 func (m *MockClient) ListOpsCounts(ctx context.Context, in *autoops.ListOpsCountsRequest, opts ...grpc.CallOption) (*autoops.ListOpsCountsResponse, error) {
 	m.ctrl.T.Helper()
@@ -229,6 +269,26 @@ func (mr *MockClientMockRecorder) ListOpsCounts(ctx, in interface{}, opts ...int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpsCounts", reflect.TypeOf((*MockClient)(nil).ListOpsCounts), varargs...)
 }
 
+// ListScheduleTransitions mocks base method.
+func (m *MockClient) ListScheduleTransitions(ctx context.Context, in *autoops.ListScheduleTransitionsRequest, opts ...grpc.CallOption) (*autoops.ListScheduleTransitionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListScheduleTransitions", varargs...)
+	ret0, _ := ret[0].(*autoops.ListScheduleTransitionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduleTransitions indicates an expected call of ListScheduleTransitions.
+func (mr *MockClientMockRecorder) ListScheduleTransitions(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduleTransitions", reflect.TypeOf((*MockClient)(nil).ListScheduleTransitions), varargs...)
+}
+
 // ListWebhooks mocks base method.
 func (m *MockClient) ListWebhooks(ctx context.Context, in *autoops.ListWebhooksRequest, opts ...grpc.CallOption) (*autoops.ListWebhooksResponse, error) {
 	m.ctrl.T.Helper()
@@ -249,6 +309,46 @@ func (mr *MockClientMockRecorder) ListWebhooks(ctx, in interface{}, opts ...inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockClient)(nil).ListWebhooks), varargs...)
 }
 
+// RotateWebhookSecret mocks base method.
+func (m *MockClient) RotateWebhookSecret(ctx context.Context, in *autoops.RotateWebhookSecretRequest, opts ...grpc.CallOption) (*autoops.RotateWebhookSecretResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RotateWebhookSecret", varargs...)
+	ret0, _ := ret[0].(*autoops.RotateWebhookSecretResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateWebhookSecret indicates an expected call of RotateWebhookSecret.
+func (mr *MockClientMockRecorder) RotateWebhookSecret(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateWebhookSecret", reflect.TypeOf((*MockClient)(nil).RotateWebhookSecret), varargs...)
+}
+
+// SimulateAutoOpsRule mocks base method.
+func (m *MockClient) SimulateAutoOpsRule(ctx context.Context, in *autoops.SimulateAutoOpsRuleRequest, opts ...grpc.CallOption) (*autoops.SimulateAutoOpsRuleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SimulateAutoOpsRule", varargs...)
+	ret0, _ := ret[0].(*autoops.SimulateAutoOpsRuleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateAutoOpsRule indicates an expected call of SimulateAutoOpsRule.
+func (mr *MockClientMockRecorder) SimulateAutoOpsRule(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateAutoOpsRule", reflect.TypeOf((*MockClient)(nil).SimulateAutoOpsRule), varargs...)
+}
+
 // UpdateAutoOpsRule mocks base method.
 func (m *MockClient) UpdateAutoOpsRule(ctx context.Context, in *autoops.UpdateAutoOpsRuleRequest, opts ...grpc.CallOption) (*autoops.UpdateAutoOpsRuleResponse, error) {
 	m.ctrl.T.Helper()
@@ -31,6 +31,7 @@ import (
 	"github.com/bucketeer-io/bucketeer/pkg/autoops/webhookhandler"
 	"github.com/bucketeer-io/bucketeer/pkg/cli"
 	"github.com/bucketeer-io/bucketeer/pkg/crypto"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
 	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/health"
@@ -50,28 +51,30 @@ const (
 
 type server struct {
 	*kingpin.CmdClause
-	port              *int
-	project           *string
-	mysqlUser         *string
-	mysqlPass         *string
-	mysqlHost         *string
-	mysqlPort         *int
-	mysqlDBName       *string
-	domainEventTopic  *string
-	accountService    *string
-	authService       *string
-	featureService    *string
-	experimentService *string
-	certPath          *string
-	keyPath           *string
-	serviceTokenPath  *string
+	port                *int
+	project             *string
+	mysqlUser           *string
+	mysqlPass           *string
+	mysqlHost           *string
+	mysqlPort           *int
+	mysqlDBName         *string
+	domainEventTopic    *string
+	accountService      *string
+	authService         *string
+	featureService      *string
+	experimentService   *string
+	eventCounterService *string
+	certPath            *string
+	keyPath             *string
+	serviceTokenPath    *string
 
 	oauthKeyPath  *string
 	oauthClientID *string
 	oauthIssuer   *string
 
-	webhookBaseURL         *string
-	webhookKMSResourceName *string
+	webhookBaseURL            *string
+	webhookKMSResourceName    *string
+	webhookSignatureTolerance *time.Duration
 }
 
 func RegisterServerCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
@@ -93,6 +96,10 @@ func RegisterServerCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Comma
 			"experiment-service",
 			"bucketeer-experiment-service address.",
 		).Default("experiment:9090").String(),
+		eventCounterService: cmd.Flag(
+			"event-counter-service",
+			"bucketeer-event-counter-service address.",
+		).Default("event-counter-server:9090").String(),
 		certPath:         cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
 		keyPath:          cmd.Flag("key", "Path to TLS key.").Required().String(),
 		serviceTokenPath: cmd.Flag("service-token", "Path to service token.").Required().String(),
@@ -104,6 +111,10 @@ func RegisterServerCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Comma
 			"webhook-kms-resource-name",
 			"Cloud KMS resource name to encrypt and decrypt webhook credentials.",
 		).Required().String(),
+		webhookSignatureTolerance: cmd.Flag(
+			"webhook-signature-tolerance",
+			"Maximum allowed difference between the signed timestamp of a webhook request and the current time.",
+		).Default("5m").Duration(),
 	}
 	r.RegisterCommand(server)
 	return server
@@ -158,6 +169,18 @@ func (s *server) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.L
 	}
 	defer experimentClient.Close()
 
+	eventCounterClient, err := ecclient.NewClient(*s.eventCounterService, *s.certPath,
+		client.WithPerRPCCredentials(creds),
+		client.WithDialTimeout(30*time.Second),
+		client.WithBlock(),
+		client.WithMetrics(registerer),
+		client.WithLogger(logger),
+	)
+	if err != nil {
+		return err
+	}
+	defer eventCounterClient.Close()
+
 	accountClient, err := accountclient.NewClient(*s.accountService, *s.certPath,
 		client.WithPerRPCCredentials(creds),
 		client.WithDialTimeout(30*time.Second),
@@ -200,6 +223,7 @@ func (s *server) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.L
 		mysqlClient,
 		featureClient,
 		experimentClient,
+		eventCounterClient,
 		accountClient,
 		authClient,
 		publisher,
@@ -227,6 +251,7 @@ func (s *server) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.L
 		verifier,
 		*s.serviceTokenPath,
 		webhookCryptoUtil,
+		webhookhandler.WithSignatureTolerance(*s.webhookSignatureTolerance),
 		webhookhandler.WithLogger(logger),
 	)
 	if err != nil {
@@ -57,6 +57,12 @@ func (h *autoOpsRuleCommandHandler) Handle(ctx context.Context, cmd Command) err
 		return h.delete(ctx, c)
 	case *proto.ChangeAutoOpsRuleTriggeredAtCommand:
 		return h.changeTriggeredAt(ctx, c)
+	case *proto.ChangeAutoOpsRuleTriggerSettingsCommand:
+		return h.changeTriggerSettings(ctx, c)
+	case *proto.RearmAutoOpsRuleCommand:
+		return h.rearm(ctx, c)
+	case *proto.ChangeAutoOpsRuleShadowCommand:
+		return h.changeShadow(ctx, c)
 	case *proto.AddOpsEventRateClauseCommand:
 		return h.addOpsEventRateClause(ctx, c)
 	case *proto.ChangeOpsEventRateClauseCommand:
@@ -67,6 +73,14 @@ func (h *autoOpsRuleCommandHandler) Handle(ctx context.Context, cmd Command) err
 		return h.addDatetimeClause(ctx, c)
 	case *proto.ChangeDatetimeClauseCommand:
 		return h.changeDatetimeClause(ctx, c)
+	case *proto.AddPrometheusClauseCommand:
+		return h.addPrometheusClause(ctx, c)
+	case *proto.ChangePrometheusClauseCommand:
+		return h.changePrometheusClause(ctx, c)
+	case *proto.AddScheduleClauseCommand:
+		return h.addScheduleClause(ctx, c)
+	case *proto.ChangeScheduleClauseCommand:
+		return h.changeScheduleClause(ctx, c)
 	case *proto.AddWebhookClauseCommand:
 		return h.addWebhookClause(ctx, c)
 	case *proto.ChangeWebhookClauseCommand:
@@ -77,12 +91,17 @@ func (h *autoOpsRuleCommandHandler) Handle(ctx context.Context, cmd Command) err
 
 func (h *autoOpsRuleCommandHandler) create(ctx context.Context, cmd *proto.CreateAutoOpsRuleCommand) error {
 	return h.send(ctx, eventproto.Event_AUTOOPS_RULE_CREATED, &eventproto.AutoOpsRuleCreatedEvent{
-		FeatureId:   h.autoOpsRule.FeatureId,
-		OpsType:     h.autoOpsRule.OpsType,
-		Clauses:     h.autoOpsRule.Clauses,
-		TriggeredAt: h.autoOpsRule.TriggeredAt,
-		CreatedAt:   h.autoOpsRule.CreatedAt,
-		UpdatedAt:   h.autoOpsRule.UpdatedAt,
+		FeatureId:            h.autoOpsRule.FeatureId,
+		OpsType:              h.autoOpsRule.OpsType,
+		OpsAction:            h.autoOpsRule.OpsAction,
+		Clauses:              h.autoOpsRule.Clauses,
+		TriggeredAt:          h.autoOpsRule.TriggeredAt,
+		CreatedAt:            h.autoOpsRule.CreatedAt,
+		UpdatedAt:            h.autoOpsRule.UpdatedAt,
+		ClauseOperator:       h.autoOpsRule.ClauseOperator,
+		CooldownSeconds:      h.autoOpsRule.CooldownSeconds,
+		SustainedEvaluations: h.autoOpsRule.SustainedEvaluations,
+		Shadow:               h.autoOpsRule.Shadow,
 	})
 }
 
@@ -90,9 +109,10 @@ func (h *autoOpsRuleCommandHandler) changeOpsType(
 	ctx context.Context,
 	cmd *proto.ChangeAutoOpsRuleOpsTypeCommand,
 ) error {
-	h.autoOpsRule.SetOpsType(cmd.OpsType)
+	h.autoOpsRule.SetOpsType(cmd.OpsType, cmd.OpsAction)
 	return h.send(ctx, eventproto.Event_AUTOOPS_RULE_OPS_TYPE_CHANGED, &eventproto.AutoOpsRuleOpsTypeChangedEvent{
-		OpsType: h.autoOpsRule.OpsType,
+		OpsType:   h.autoOpsRule.OpsType,
+		OpsAction: h.autoOpsRule.OpsAction,
 	})
 }
 
@@ -113,6 +133,34 @@ func (h *autoOpsRuleCommandHandler) changeTriggeredAt(
 	)
 }
 
+func (h *autoOpsRuleCommandHandler) changeTriggerSettings(
+	ctx context.Context,
+	cmd *proto.ChangeAutoOpsRuleTriggerSettingsCommand,
+) error {
+	h.autoOpsRule.SetTriggerSettings(cmd.ClauseOperator, cmd.CooldownSeconds, cmd.SustainedEvaluations)
+	return h.send(
+		ctx,
+		eventproto.Event_AUTOOPS_RULE_TRIGGER_SETTINGS_CHANGED,
+		&eventproto.AutoOpsRuleTriggerSettingsChangedEvent{
+			ClauseOperator:       h.autoOpsRule.ClauseOperator,
+			CooldownSeconds:      h.autoOpsRule.CooldownSeconds,
+			SustainedEvaluations: h.autoOpsRule.SustainedEvaluations,
+		},
+	)
+}
+
+func (h *autoOpsRuleCommandHandler) rearm(ctx context.Context, cmd *proto.RearmAutoOpsRuleCommand) error {
+	h.autoOpsRule.Rearm()
+	return h.send(ctx, eventproto.Event_AUTOOPS_RULE_REARMED, &eventproto.AutoOpsRuleRearmedEvent{})
+}
+
+func (h *autoOpsRuleCommandHandler) changeShadow(ctx context.Context, cmd *proto.ChangeAutoOpsRuleShadowCommand) error {
+	h.autoOpsRule.SetShadow(cmd.Shadow)
+	return h.send(ctx, eventproto.Event_AUTOOPS_RULE_SHADOW_CHANGED, &eventproto.AutoOpsRuleShadowChangedEvent{
+		Shadow: h.autoOpsRule.Shadow,
+	})
+}
+
 func (h *autoOpsRuleCommandHandler) addOpsEventRateClause(
 	ctx context.Context,
 	cmd *proto.AddOpsEventRateClauseCommand,
@@ -173,6 +221,60 @@ func (h *autoOpsRuleCommandHandler) changeDatetimeClause(
 	})
 }
 
+func (h *autoOpsRuleCommandHandler) addPrometheusClause(
+	ctx context.Context,
+	cmd *proto.AddPrometheusClauseCommand,
+) error {
+	clause, err := h.autoOpsRule.AddPrometheusClause(cmd.PrometheusClause)
+	if err != nil {
+		return err
+	}
+	return h.send(ctx, eventproto.Event_PROMETHEUS_CLAUSE_ADDED, &eventproto.PrometheusClauseAddedEvent{
+		ClauseId:         clause.Id,
+		PrometheusClause: cmd.PrometheusClause,
+	})
+}
+
+func (h *autoOpsRuleCommandHandler) changePrometheusClause(
+	ctx context.Context,
+	cmd *proto.ChangePrometheusClauseCommand,
+) error {
+	if err := h.autoOpsRule.ChangePrometheusClause(cmd.Id, cmd.PrometheusClause); err != nil {
+		return err
+	}
+	return h.send(ctx, eventproto.Event_PROMETHEUS_CLAUSE_CHANGED, &eventproto.PrometheusClauseChangedEvent{
+		ClauseId:         cmd.Id,
+		PrometheusClause: cmd.PrometheusClause,
+	})
+}
+
+func (h *autoOpsRuleCommandHandler) addScheduleClause(
+	ctx context.Context,
+	cmd *proto.AddScheduleClauseCommand,
+) error {
+	clause, err := h.autoOpsRule.AddScheduleClause(cmd.ScheduleClause)
+	if err != nil {
+		return err
+	}
+	return h.send(ctx, eventproto.Event_SCHEDULE_CLAUSE_ADDED, &eventproto.ScheduleClauseAddedEvent{
+		ClauseId:       clause.Id,
+		ScheduleClause: cmd.ScheduleClause,
+	})
+}
+
+func (h *autoOpsRuleCommandHandler) changeScheduleClause(
+	ctx context.Context,
+	cmd *proto.ChangeScheduleClauseCommand,
+) error {
+	if err := h.autoOpsRule.ChangeScheduleClause(cmd.Id, cmd.ScheduleClause); err != nil {
+		return err
+	}
+	return h.send(ctx, eventproto.Event_SCHEDULE_CLAUSE_CHANGED, &eventproto.ScheduleClauseChangedEvent{
+		ClauseId:       cmd.Id,
+		ScheduleClause: cmd.ScheduleClause,
+	})
+}
+
 func (h *autoOpsRuleCommandHandler) addWebhookClause(
 	ctx context.Context,
 	cmd *proto.AddWebhookClauseCommand,
@@ -102,6 +102,50 @@ func TestChangeTriggeredAt(t *testing.T) {
 	}
 }
 
+func TestChangeTriggerSettings(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	m := publishermock.NewMockPublisher(mockController)
+	a := newAutoOpsRule(t)
+	h := newAutoOpsRuleCommandHandler(m, a)
+	m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	cmd := &proto.ChangeAutoOpsRuleTriggerSettingsCommand{
+		ClauseOperator:       proto.AutoOpsRule_AND,
+		CooldownSeconds:      600,
+		SustainedEvaluations: 3,
+	}
+	err := h.Handle(context.Background(), cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, proto.AutoOpsRule_AND, a.ClauseOperator)
+	assert.Equal(t, int64(600), a.CooldownSeconds)
+	assert.Equal(t, int32(3), a.SustainedEvaluations)
+}
+
+func TestRearm(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	m := publishermock.NewMockPublisher(mockController)
+	a := newAutoOpsRule(t)
+	a.SetTriggeredAt()
+	h := newAutoOpsRuleCommandHandler(m, a)
+	m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	err := h.Handle(context.Background(), &proto.RearmAutoOpsRuleCommand{})
+	assert.NoError(t, err)
+	assert.False(t, a.AlreadyTriggered())
+}
+
+func TestChangeShadow(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	m := publishermock.NewMockPublisher(mockController)
+	a := newAutoOpsRule(t)
+	h := newAutoOpsRuleCommandHandler(m, a)
+	m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	err := h.Handle(context.Background(), &proto.ChangeAutoOpsRuleShadowCommand{Shadow: true})
+	assert.NoError(t, err)
+	assert.True(t, a.Shadow)
+}
+
 func TestAddOpsEventRateClause(t *testing.T) {
 	mockController := gomock.NewController(t)
 	defer mockController.Finish()
@@ -206,6 +250,48 @@ func TestAddDatetimeClause(t *testing.T) {
 	}
 }
 
+func TestAddPrometheusClause(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	m := publishermock.NewMockPublisher(mockController)
+	a := newAutoOpsRule(t)
+	l := len(a.Clauses)
+	h := newAutoOpsRuleCommandHandler(m, a)
+	m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	pc := &proto.PrometheusClause{Query: "up", LookbackSeconds: 60}
+	err := h.Handle(context.Background(), &proto.AddPrometheusClauseCommand{PrometheusClause: pc})
+	assert.NoError(t, err)
+	assert.Equal(t, l+1, len(a.Clauses))
+	id := a.Clauses[l].Id
+	pc = &proto.PrometheusClause{Query: "up", LookbackSeconds: 120}
+	err = h.Handle(context.Background(), &proto.ChangePrometheusClauseCommand{Id: id, PrometheusClause: pc})
+	assert.NoError(t, err)
+	clauses, err := a.ExtractPrometheusClauses()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(120), clauses[id].LookbackSeconds)
+}
+
+func TestAddScheduleClause(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	m := publishermock.NewMockPublisher(mockController)
+	a := newAutoOpsRule(t)
+	l := len(a.Clauses)
+	h := newAutoOpsRuleCommandHandler(m, a)
+	m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	sc := &proto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * 1-5"}
+	err := h.Handle(context.Background(), &proto.AddScheduleClauseCommand{ScheduleClause: sc})
+	assert.NoError(t, err)
+	assert.Equal(t, l+1, len(a.Clauses))
+	id := a.Clauses[l].Id
+	sc = &proto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * 1-5", TimeZone: "Asia/Tokyo"}
+	err = h.Handle(context.Background(), &proto.ChangeScheduleClauseCommand{Id: id, ScheduleClause: sc})
+	assert.NoError(t, err)
+	clauses, err := a.ExtractScheduleClauses()
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", clauses[id].TimeZone)
+}
+
 func TestChangeDatetimeClause(t *testing.T) {
 	mockController := gomock.NewController(t)
 	defer mockController.Finish()
@@ -300,7 +386,16 @@ func newAutoOpsRule(t *testing.T) *domain.AutoOpsRule {
 	dc2 := &proto.DatetimeClause{
 		Time: 1000000002,
 	}
-	aor, err := domain.NewAutoOpsRule("fid", proto.OpsType_ENABLE_FEATURE, []*proto.OpsEventRateClause{oerc1, oerc2}, []*proto.DatetimeClause{dc1, dc2}, []*proto.WebhookClause{})
+	aor, err := domain.NewAutoOpsRule(
+		"fid",
+		proto.OpsType_ENABLE_FEATURE,
+		nil,
+		[]*proto.OpsEventRateClause{oerc1, oerc2},
+		[]*proto.DatetimeClause{dc1, dc2},
+		[]*proto.WebhookClause{},
+		[]*proto.PrometheusClause{},
+		[]*proto.ScheduleClause{},
+	)
 	require.NoError(t, err)
 	return aor
 }
@@ -55,6 +55,12 @@ func (h *webhookCommandHandler) Handle(ctx context.Context, cmd Command) error {
 		return h.ChangeWebhookName(ctx, c)
 	case *autoopspb.ChangeWebhookDescriptionCommand:
 		return h.ChangeWebhookDescription(ctx, c)
+	case *autoopspb.ChangeWebhookPayloadFormatCommand:
+		return h.ChangeWebhookPayloadFormat(ctx, c)
+	case *autoopspb.ChangeWebhookSignatureRequiredCommand:
+		return h.ChangeWebhookSignatureRequired(ctx, c)
+	case *autoopspb.RotateWebhookSecretCommand:
+		return h.RotateWebhookSecret(ctx, c)
 	default:
 		return errUnknownCommand
 	}
@@ -67,11 +73,13 @@ func (h *webhookCommandHandler) CreateWebhook(ctx context.Context, cmd *autoopsp
 		h.webhook.Id,
 		eventpb.Event_WEBHOOK_CREATED,
 		&eventpb.WebhookCreatedEvent{
-			Id:          h.webhook.Id,
-			Name:        h.webhook.Name,
-			Description: h.webhook.Description,
-			CreatedAt:   h.webhook.CreatedAt,
-			UpdatedAt:   h.webhook.UpdatedAt,
+			Id:                h.webhook.Id,
+			Name:              h.webhook.Name,
+			Description:       h.webhook.Description,
+			CreatedAt:         h.webhook.CreatedAt,
+			UpdatedAt:         h.webhook.UpdatedAt,
+			PayloadFormat:     h.webhook.PayloadFormat,
+			SignatureRequired: h.webhook.SignatureRequired,
 		},
 		h.environmentNamespace,
 	)
@@ -139,3 +147,75 @@ func (h *webhookCommandHandler) ChangeWebhookDescription(ctx context.Context, cm
 	}
 	return h.publisher.Publish(ctx, event)
 }
+
+func (h *webhookCommandHandler) ChangeWebhookPayloadFormat(
+	ctx context.Context,
+	cmd *autoopspb.ChangeWebhookPayloadFormatCommand,
+) error {
+	if err := h.webhook.ChangePayloadFormat(cmd.PayloadFormat); err != nil {
+		return err
+	}
+	event, err := domainevent.NewEvent(
+		h.editor,
+		eventpb.Event_WEBHOOK,
+		h.webhook.Id,
+		eventpb.Event_WEBHOOK_PAYLOAD_FORMAT_CHANGED,
+		&eventpb.WebhookPayloadFormatChangedEvent{
+			Id:            h.webhook.Id,
+			PayloadFormat: cmd.PayloadFormat,
+		},
+		h.environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return h.publisher.Publish(ctx, event)
+}
+
+func (h *webhookCommandHandler) ChangeWebhookSignatureRequired(
+	ctx context.Context,
+	cmd *autoopspb.ChangeWebhookSignatureRequiredCommand,
+) error {
+	if err := h.webhook.ChangeSignatureRequired(cmd.SignatureRequired); err != nil {
+		return err
+	}
+	event, err := domainevent.NewEvent(
+		h.editor,
+		eventpb.Event_WEBHOOK,
+		h.webhook.Id,
+		eventpb.Event_WEBHOOK_SIGNATURE_REQUIRED_CHANGED,
+		&eventpb.WebhookSignatureRequiredChangedEvent{
+			Id:                h.webhook.Id,
+			SignatureRequired: cmd.SignatureRequired,
+		},
+		h.environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return h.publisher.Publish(ctx, event)
+}
+
+// RotateWebhookSecret publishes the event of the secret rotated by the caller,
+// since the new signing secret has to be generated and encrypted beforehand.
+func (h *webhookCommandHandler) RotateWebhookSecret(
+	ctx context.Context,
+	cmd *autoopspb.RotateWebhookSecretCommand,
+) error {
+	event, err := domainevent.NewEvent(
+		h.editor,
+		eventpb.Event_WEBHOOK,
+		h.webhook.Id,
+		eventpb.Event_WEBHOOK_SECRET_ROTATED,
+		&eventpb.WebhookSecretRotatedEvent{
+			Id:                      h.webhook.Id,
+			SecretVersion:           h.webhook.SecretVersion,
+			PreviousSecretExpiresAt: h.webhook.PreviousSecretExpiresAt,
+		},
+		h.environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return h.publisher.Publish(ctx, event)
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
+	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+type AutoOpsExecution struct {
+	*proto.AutoOpsExecution
+}
+
+// NewAutoOpsExecution records the action of the rule taken by the trigger.
+// The feature version must be set after the action is taken.
+func NewAutoOpsExecution(
+	rule *AutoOpsRule,
+	trigger *proto.ExecutionTrigger,
+	reversed bool,
+) (*AutoOpsExecution, error) {
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	return &AutoOpsExecution{&proto.AutoOpsExecution{
+		Id:            id.String(),
+		AutoOpsRuleId: rule.Id,
+		FeatureId:     rule.FeatureId,
+		Trigger:       trigger,
+		OpsType:       rule.OpsType,
+		OpsAction:     rule.OpsAction,
+		Reversed:      reversed,
+		ExecutedAt:    time.Now().Unix(),
+	}}, nil
+}
+
+func (e *AutoOpsExecution) SetFeatureVersion(version int32) {
+	e.FeatureVersion = version
+}
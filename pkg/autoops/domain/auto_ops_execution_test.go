@@ -0,0 +1,46 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+func TestNewAutoOpsExecution(t *testing.T) {
+	t.Parallel()
+	rule := &AutoOpsRule{&autoopsproto.AutoOpsRule{
+		Id:        "rid",
+		FeatureId: "fid",
+		OpsType:   autoopsproto.OpsType_DISABLE_FEATURE,
+	}}
+	trigger := &autoopsproto.ExecutionTrigger{ClauseId: "cid", OpsEventCount: 10, EvaluationCount: 100}
+	e, err := NewAutoOpsExecution(rule, trigger, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, e.Id)
+	assert.Equal(t, "rid", e.AutoOpsRuleId)
+	assert.Equal(t, "fid", e.FeatureId)
+	assert.Equal(t, autoopsproto.OpsType_DISABLE_FEATURE, e.OpsType)
+	assert.Equal(t, trigger, e.Trigger)
+	assert.True(t, e.Reversed)
+	assert.NotZero(t, e.ExecutedAt)
+	assert.Zero(t, e.FeatureVersion)
+	e.SetFeatureVersion(3)
+	assert.Equal(t, int32(3), e.FeatureVersion)
+}
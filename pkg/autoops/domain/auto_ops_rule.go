@@ -22,6 +22,7 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
 	"github.com/bucketeer-io/bucketeer/pkg/uuid"
 	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
 )
@@ -33,6 +34,8 @@ var (
 	opsEventRateClause = &proto.OpsEventRateClause{}
 	datetimeClause     = &proto.DatetimeClause{}
 	webhookClause      = &proto.WebhookClause{}
+	prometheusClause   = &proto.PrometheusClause{}
+	scheduleClause     = &proto.ScheduleClause{}
 )
 
 type AutoOpsRule struct {
@@ -42,9 +45,12 @@ type AutoOpsRule struct {
 func NewAutoOpsRule(
 	featureID string,
 	opsType proto.OpsType,
+	opsAction *proto.OpsAction,
 	opsEventRateClauses []*proto.OpsEventRateClause,
 	datetimeClauses []*proto.DatetimeClause,
 	webhookClauses []*proto.WebhookClause,
+	prometheusClauses []*proto.PrometheusClause,
+	scheduleClauses []*proto.ScheduleClause,
 ) (*AutoOpsRule, error) {
 	now := time.Now().Unix()
 	id, err := uuid.NewUUID()
@@ -55,9 +61,11 @@ func NewAutoOpsRule(
 		Id:        id.String(),
 		FeatureId: featureID,
 		OpsType:   opsType,
+		OpsAction: opsAction,
 		Clauses:   []*proto.Clause{},
 		CreatedAt: now,
 		UpdatedAt: now,
+		ArmedAt:   now,
 	}}
 	for _, c := range opsEventRateClauses {
 		if _, err := autoOpsRule.AddOpsEventRateClause(c); err != nil {
@@ -74,6 +82,16 @@ func NewAutoOpsRule(
 			return nil, err
 		}
 	}
+	for _, c := range prometheusClauses {
+		if _, err := autoOpsRule.AddPrometheusClause(c); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range scheduleClauses {
+		if _, err := autoOpsRule.AddScheduleClause(c); err != nil {
+			return nil, err
+		}
+	}
 	if len(autoOpsRule.Clauses) == 0 {
 		return nil, errClauseEmpty
 	}
@@ -92,14 +110,78 @@ func (a *AutoOpsRule) SetTriggeredAt() {
 	a.AutoOpsRule.UpdatedAt = now
 }
 
+// AlreadyTriggered reports whether the rule has been triggered and is not armed yet.
+// A rule with a cooldown is re-armed automatically once the cooldown has passed.
+// A rule with a schedule clause is never disarmed because it is triggered at every transition.
 func (a *AutoOpsRule) AlreadyTriggered() bool {
-	return a.TriggeredAt > 0
+	if a.TriggeredAt == 0 || a.HasScheduleClause() {
+		return false
+	}
+	if a.CooldownSeconds == 0 {
+		return true
+	}
+	return time.Now().Unix() < a.TriggeredAt+a.CooldownSeconds
 }
 
-func (a *AutoOpsRule) SetOpsType(opsType proto.OpsType) {
-	a.AutoOpsRule.OpsType = opsType
-	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
+// ArmedSince returns the time since when the rule has been armed.
+// Assessments made before it must not be taken into account.
+func (a *AutoOpsRule) ArmedSince() int64 {
+	if a.TriggeredAt > 0 && a.CooldownSeconds > 0 {
+		return a.TriggeredAt + a.CooldownSeconds
+	}
+	return a.ArmedAt
+}
+
+func (a *AutoOpsRule) Rearm() {
+	a.rearm(time.Now().Unix())
+}
+
+func (a *AutoOpsRule) rearm(now int64) {
 	a.AutoOpsRule.TriggeredAt = 0
+	a.AutoOpsRule.ArmedAt = now
+	a.AutoOpsRule.UpdatedAt = now
+}
+
+func (a *AutoOpsRule) SetTriggerSettings(
+	clauseOperator proto.AutoOpsRule_ClauseOperator,
+	cooldownSeconds int64,
+	sustainedEvaluations int32,
+) {
+	a.AutoOpsRule.ClauseOperator = clauseOperator
+	a.AutoOpsRule.CooldownSeconds = cooldownSeconds
+	a.AutoOpsRule.SustainedEvaluations = sustainedEvaluations
+	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
+}
+
+func (a *AutoOpsRule) SetShadow(shadow bool) {
+	a.AutoOpsRule.Shadow = shadow
+	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
+}
+
+// Assess combines the results of the clauses keyed by the clause id
+// using the clause operator of the rule.
+// Clauses that have no result are regarded as not satisfied.
+func (a *AutoOpsRule) Assess(results map[string]bool) bool {
+	if a.ClauseOperator == proto.AutoOpsRule_AND {
+		for _, c := range a.Clauses {
+			if !results[c.Id] {
+				return false
+			}
+		}
+		return len(a.Clauses) > 0
+	}
+	for _, c := range a.Clauses {
+		if results[c.Id] {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AutoOpsRule) SetOpsType(opsType proto.OpsType, opsAction *proto.OpsAction) {
+	a.AutoOpsRule.OpsType = opsType
+	a.AutoOpsRule.OpsAction = opsAction
+	a.rearm(time.Now().Unix())
 }
 
 func (a *AutoOpsRule) AddOpsEventRateClause(oerc *proto.OpsEventRateClause) (*proto.Clause, error) {
@@ -130,6 +212,30 @@ func (a *AutoOpsRule) ChangeWebhookClause(id string, wc *proto.WebhookClause) er
 	return a.changeClause(id, wc)
 }
 
+func (a *AutoOpsRule) AddPrometheusClause(pc *proto.PrometheusClause) (*proto.Clause, error) {
+	ac, err := ptypes.MarshalAny(pc)
+	if err != nil {
+		return nil, err
+	}
+	return a.addClause(ac)
+}
+
+func (a *AutoOpsRule) ChangePrometheusClause(id string, pc *proto.PrometheusClause) error {
+	return a.changeClause(id, pc)
+}
+
+func (a *AutoOpsRule) AddScheduleClause(sc *proto.ScheduleClause) (*proto.Clause, error) {
+	ac, err := ptypes.MarshalAny(sc)
+	if err != nil {
+		return nil, err
+	}
+	return a.addClause(ac)
+}
+
+func (a *AutoOpsRule) ChangeScheduleClause(id string, sc *proto.ScheduleClause) error {
+	return a.changeClause(id, sc)
+}
+
 func (a *AutoOpsRule) addClause(ac *any.Any) (*proto.Clause, error) {
 	id, err := uuid.NewUUID()
 	if err != nil {
@@ -140,8 +246,7 @@ func (a *AutoOpsRule) addClause(ac *any.Any) (*proto.Clause, error) {
 		Clause: ac,
 	}
 	a.AutoOpsRule.Clauses = append(a.AutoOpsRule.Clauses, clause)
-	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
-	a.AutoOpsRule.TriggeredAt = 0
+	a.rearm(time.Now().Unix())
 	return clause, nil
 }
 
@@ -154,8 +259,7 @@ func (a *AutoOpsRule) ChangeDatetimeClause(id string, dc *proto.DatetimeClause)
 }
 
 func (a *AutoOpsRule) changeClause(id string, mc pb.Message) error {
-	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
-	a.AutoOpsRule.TriggeredAt = 0
+	a.rearm(time.Now().Unix())
 	for _, c := range a.Clauses {
 		if c.Id == id {
 			clause, err := ptypes.MarshalAny(mc)
@@ -173,8 +277,7 @@ func (a *AutoOpsRule) DeleteClause(id string) error {
 	if len(a.Clauses) <= 1 {
 		return errClauseEmpty
 	}
-	a.AutoOpsRule.UpdatedAt = time.Now().Unix()
-	a.AutoOpsRule.TriggeredAt = 0
+	a.rearm(time.Now().Unix())
 	var clauses []*proto.Clause
 	for i, c := range a.Clauses {
 		if c.Id == id {
@@ -215,6 +318,46 @@ func (a *AutoOpsRule) unmarshalOpsEventRateClause(clause *proto.Clause) (*proto.
 	return nil, nil
 }
 
+// AssessOpsEventRate reports whether the rate of the ops event count to the evaluation count
+// satisfies the clause.
+func AssessOpsEventRate(clause *proto.OpsEventRateClause, opsEventCount, evaluationCount int64) bool {
+	if opsEventCount < clause.MinCount {
+		return false
+	}
+	rate := float64(opsEventCount) / float64(evaluationCount)
+	switch clause.Operator {
+	case proto.OpsEventRateClause_GREATER_OR_EQUAL:
+		return rate >= clause.ThreadsholdRate
+	case proto.OpsEventRateClause_LESS_OR_EQUAL:
+		return rate <= clause.ThreadsholdRate
+	}
+	return false
+}
+
+// AssessOpsEventRateSignificance reports whether the ops event rate differs from the rate of the baseline variation
+// in the direction of the clause operator with statistical significance.
+// It also returns the z-score and the p-value of the one-sided test.
+func AssessOpsEventRateSignificance(
+	clause *proto.OpsEventRateClause,
+	baselineOpsEventCount, baselineEvaluationCount, opsEventCount, evaluationCount int64,
+) (bool, float64, float64, error) {
+	z, pValue, err := stats.OneSidedTwoProportionZTest(
+		baselineOpsEventCount,
+		baselineEvaluationCount,
+		opsEventCount,
+		evaluationCount,
+		clause.Operator == proto.OpsEventRateClause_GREATER_OR_EQUAL,
+	)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	confidenceLevel := clause.ConfidenceLevel
+	if confidenceLevel == 0 {
+		confidenceLevel = stats.DefaultConfidenceLevel
+	}
+	return pValue <= 1-confidenceLevel, z, pValue, nil
+}
+
 func (a *AutoOpsRule) ExtractDatetimeClauses() ([]*proto.DatetimeClause, error) {
 	datetimeClauses := []*proto.DatetimeClause{}
 	for _, c := range a.Clauses {
@@ -230,6 +373,48 @@ func (a *AutoOpsRule) ExtractDatetimeClauses() ([]*proto.DatetimeClause, error)
 	return datetimeClauses, nil
 }
 
+// DatetimeClauseArmed reports whether the datetime is not before the time since when the rule has been armed.
+// A datetime before then has already been taken into account,
+// so it doesn't trigger the rule again once the cooldown has passed.
+func (a *AutoOpsRule) DatetimeClauseArmed(datetimeClause *proto.DatetimeClause) bool {
+	return datetimeClause.Time >= a.ArmedSince()
+}
+
+// AssessDatetimeClauses returns whether each datetime clause keyed by the clause id has passed since the rule was armed.
+func (a *AutoOpsRule) AssessDatetimeClauses(now int64) (map[string]bool, error) {
+	results := map[string]bool{}
+	for _, c := range a.Clauses {
+		datetimeClause, err := a.unmarshalDatetimeClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if datetimeClause == nil {
+			continue
+		}
+		results[c.Id] = datetimeClause.Time <= now && a.DatetimeClauseArmed(datetimeClause)
+	}
+	return results, nil
+}
+
+// LatestDatetimeTrigger returns the trigger of the datetime clause satisfied last at now,
+// or nil if no datetime clause is satisfied.
+func (a *AutoOpsRule) LatestDatetimeTrigger(now int64) (*proto.ExecutionTrigger, error) {
+	var trigger *proto.ExecutionTrigger
+	for _, c := range a.Clauses {
+		datetimeClause, err := a.unmarshalDatetimeClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if datetimeClause == nil || datetimeClause.Time > now || !a.DatetimeClauseArmed(datetimeClause) {
+			continue
+		}
+		if trigger == nil || datetimeClause.Time > trigger.Time {
+			trigger = &proto.ExecutionTrigger{ClauseId: c.Id, Time: datetimeClause.Time}
+		}
+	}
+	return trigger, nil
+}
+
 func (a *AutoOpsRule) unmarshalDatetimeClause(clause *proto.Clause) (*proto.DatetimeClause, error) {
 	if ptypes.Is(clause.Clause, datetimeClause) {
 		c := &proto.DatetimeClause{}
@@ -241,8 +426,8 @@ func (a *AutoOpsRule) unmarshalDatetimeClause(clause *proto.Clause) (*proto.Date
 	return nil, nil
 }
 
-func (a *AutoOpsRule) ExtractWebhookClauses() ([]*proto.WebhookClause, error) {
-	webhookClauses := []*proto.WebhookClause{}
+func (a *AutoOpsRule) ExtractWebhookClauses() (map[string]*proto.WebhookClause, error) {
+	webhookClauses := map[string]*proto.WebhookClause{}
 	for _, c := range a.Clauses {
 		webhookClause, err := a.unmarshalWebhookClause(c)
 		if err != nil {
@@ -251,7 +436,7 @@ func (a *AutoOpsRule) ExtractWebhookClauses() ([]*proto.WebhookClause, error) {
 		if webhookClause == nil {
 			continue
 		}
-		webhookClauses = append(webhookClauses, webhookClause)
+		webhookClauses[c.Id] = webhookClause
 	}
 	return webhookClauses, nil
 }
@@ -266,3 +451,64 @@ func (a *AutoOpsRule) unmarshalWebhookClause(clause *proto.Clause) (*proto.Webho
 	}
 	return nil, nil
 }
+
+func (a *AutoOpsRule) ExtractPrometheusClauses() (map[string]*proto.PrometheusClause, error) {
+	prometheusClauses := map[string]*proto.PrometheusClause{}
+	for _, c := range a.Clauses {
+		prometheusClause, err := a.unmarshalPrometheusClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if prometheusClause == nil {
+			continue
+		}
+		prometheusClauses[c.Id] = prometheusClause
+	}
+	return prometheusClauses, nil
+}
+
+func (a *AutoOpsRule) unmarshalPrometheusClause(clause *proto.Clause) (*proto.PrometheusClause, error) {
+	if ptypes.Is(clause.Clause, prometheusClause) {
+		c := &proto.PrometheusClause{}
+		if err := ptypes.UnmarshalAny(clause.Clause, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+func (a *AutoOpsRule) ExtractScheduleClauses() (map[string]*proto.ScheduleClause, error) {
+	scheduleClauses := map[string]*proto.ScheduleClause{}
+	for _, c := range a.Clauses {
+		scheduleClause, err := a.unmarshalScheduleClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if scheduleClause == nil {
+			continue
+		}
+		scheduleClauses[c.Id] = scheduleClause
+	}
+	return scheduleClauses, nil
+}
+
+func (a *AutoOpsRule) HasScheduleClause() bool {
+	for _, c := range a.Clauses {
+		if ptypes.Is(c.Clause, scheduleClause) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AutoOpsRule) unmarshalScheduleClause(clause *proto.Clause) (*proto.ScheduleClause, error) {
+	if ptypes.Is(clause.Clause, scheduleClause) {
+		c := &proto.ScheduleClause{}
+		if err := ptypes.UnmarshalAny(clause.Clause, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, nil
+}
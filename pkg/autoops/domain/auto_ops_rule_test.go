@@ -16,6 +16,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
@@ -35,6 +36,7 @@ func TestNewAutoOpsRule(t *testing.T) {
 	assert.Zero(t, aor.TriggeredAt)
 	assert.NotZero(t, aor.CreatedAt)
 	assert.NotZero(t, aor.UpdatedAt)
+	assert.NotZero(t, aor.ArmedAt)
 }
 
 func TestSetDeleted(t *testing.T) {
@@ -59,13 +61,132 @@ func TestAlreadyTriggeredAt(t *testing.T) {
 	assert.True(t, aor.AlreadyTriggered())
 }
 
+func TestAlreadyTriggeredWithCooldown(t *testing.T) {
+	t.Parallel()
+	aor := createAutoOpsRule(t)
+	aor.CooldownSeconds = 60
+	aor.SetTriggeredAt()
+	assert.True(t, aor.AlreadyTriggered())
+	assert.Equal(t, aor.TriggeredAt+60, aor.ArmedSince())
+	aor.TriggeredAt = time.Now().Unix() - 61
+	assert.False(t, aor.AlreadyTriggered())
+	assert.Equal(t, aor.TriggeredAt+60, aor.ArmedSince())
+}
+
+func TestRearm(t *testing.T) {
+	t.Parallel()
+	aor := createAutoOpsRule(t)
+	aor.ArmedAt = 1
+	aor.SetTriggeredAt()
+	aor.Rearm()
+	assert.False(t, aor.AlreadyTriggered())
+	assert.Zero(t, aor.TriggeredAt)
+	assert.NotEqual(t, int64(1), aor.ArmedAt)
+	assert.Equal(t, aor.ArmedAt, aor.ArmedSince())
+}
+
+func TestSetTriggerSettings(t *testing.T) {
+	t.Parallel()
+	aor := createAutoOpsRule(t)
+	aor.SetTriggerSettings(autoopsproto.AutoOpsRule_AND, 300, 3)
+	assert.Equal(t, autoopsproto.AutoOpsRule_AND, aor.ClauseOperator)
+	assert.Equal(t, int64(300), aor.CooldownSeconds)
+	assert.Equal(t, int32(3), aor.SustainedEvaluations)
+}
+
+func TestSetShadow(t *testing.T) {
+	t.Parallel()
+	aor := createAutoOpsRule(t)
+	aor.SetShadow(true)
+	assert.True(t, aor.Shadow)
+	aor.SetShadow(false)
+	assert.False(t, aor.Shadow)
+}
+
+func TestAssessOpsEventRate(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		operator        autoopsproto.OpsEventRateClause_Operator
+		opsEventCount   int64
+		evaluationCount int64
+		expected        bool
+	}{
+		{autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL, 5, 20, false},
+		{autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL, 10, 40, false},
+		{autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL, 10, 20, true},
+		{autoopsproto.OpsEventRateClause_LESS_OR_EQUAL, 10, 20, true},
+		{autoopsproto.OpsEventRateClause_LESS_OR_EQUAL, 15, 20, false},
+	}
+	for _, p := range patterns {
+		clause := &autoopsproto.OpsEventRateClause{
+			MinCount:        10,
+			ThreadsholdRate: 0.5,
+			Operator:        p.operator,
+		}
+		assert.Equal(t, p.expected, AssessOpsEventRate(clause, p.opsEventCount, p.evaluationCount))
+	}
+}
+
+func TestAssess(t *testing.T) {
+	t.Parallel()
+	clauses := []*autoopsproto.Clause{{Id: "c1"}, {Id: "c2"}}
+	patterns := []struct {
+		desc     string
+		operator autoopsproto.AutoOpsRule_ClauseOperator
+		results  map[string]bool
+		expected bool
+	}{
+		{
+			desc:     "or: none satisfied",
+			operator: autoopsproto.AutoOpsRule_OR,
+			results:  map[string]bool{"c1": false, "c2": false},
+			expected: false,
+		},
+		{
+			desc:     "or: one satisfied",
+			operator: autoopsproto.AutoOpsRule_OR,
+			results:  map[string]bool{"c2": true},
+			expected: true,
+		},
+		{
+			desc:     "and: one missing",
+			operator: autoopsproto.AutoOpsRule_AND,
+			results:  map[string]bool{"c1": true},
+			expected: false,
+		},
+		{
+			desc:     "and: one not satisfied",
+			operator: autoopsproto.AutoOpsRule_AND,
+			results:  map[string]bool{"c1": true, "c2": false},
+			expected: false,
+		},
+		{
+			desc:     "and: all satisfied",
+			operator: autoopsproto.AutoOpsRule_AND,
+			results:  map[string]bool{"c1": true, "c2": true},
+			expected: true,
+		},
+	}
+	for _, p := range patterns {
+		aor := &AutoOpsRule{&autoopsproto.AutoOpsRule{
+			Clauses:        clauses,
+			ClauseOperator: p.operator,
+		}}
+		assert.Equal(t, p.expected, aor.Assess(p.results), p.desc)
+	}
+}
+
 func TestSetOpsType(t *testing.T) {
 	t.Parallel()
 	aor := createAutoOpsRule(t)
 	aor.TriggeredAt = 1
-	aor.SetOpsType(autoopsproto.OpsType_DISABLE_FEATURE)
+	aor.SetOpsType(autoopsproto.OpsType_DISABLE_FEATURE, nil)
 	assert.Equal(t, autoopsproto.OpsType_DISABLE_FEATURE, aor.OpsType)
 	assert.Zero(t, aor.TriggeredAt)
+	action := &autoopsproto.OpsAction{VariationId: "vid"}
+	aor.SetOpsType(autoopsproto.OpsType_SERVE_FIXED_VARIATION, action)
+	assert.Equal(t, autoopsproto.OpsType_SERVE_FIXED_VARIATION, aor.OpsType)
+	assert.Equal(t, action, aor.OpsAction)
 }
 
 func TestAddOpsEventRateClause(t *testing.T) {
@@ -165,11 +286,14 @@ func createAutoOpsRule(t *testing.T) *AutoOpsRule {
 	aor, err := NewAutoOpsRule(
 		"feature-id",
 		autoopsproto.OpsType_ENABLE_FEATURE,
+		nil,
 		[]*autoopsproto.OpsEventRateClause{},
 		[]*autoopsproto.DatetimeClause{
 			{Time: 0},
 		},
 		[]*autoopsproto.WebhookClause{},
+		[]*autoopsproto.PrometheusClause{},
+		[]*autoopsproto.ScheduleClause{},
 	)
 	require.NoError(t, err)
 	return aor
@@ -247,6 +371,53 @@ func TestExtractDatetimeClauses(t *testing.T) {
 	}
 }
 
+func TestAssessDatetimeClauses(t *testing.T) {
+	dc1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1000000001})
+	require.NoError(t, err)
+	dc2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1000000003})
+	require.NoError(t, err)
+	oerc, err := ptypes.MarshalAny(&autoopsproto.OpsEventRateClause{VariationId: "vid1"})
+	require.NoError(t, err)
+	autoOpsRule := &AutoOpsRule{&autoopsproto.AutoOpsRule{
+		Clauses: []*autoopsproto.Clause{
+			{Id: "c1", Clause: dc1},
+			{Id: "c2", Clause: dc2},
+			{Id: "c3", Clause: oerc},
+		},
+	}}
+	actual, err := autoOpsRule.AssessDatetimeClauses(1000000002)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"c1": true, "c2": false}, actual)
+	// The datetime that passed before the cooldown ended doesn't trigger the rule again.
+	autoOpsRule.TriggeredAt = 1000000001
+	autoOpsRule.CooldownSeconds = 1
+	actual, err = autoOpsRule.AssessDatetimeClauses(1000000003)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"c1": false, "c2": true}, actual)
+}
+
+func TestLatestDatetimeTrigger(t *testing.T) {
+	dc1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1000000001})
+	require.NoError(t, err)
+	dc2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1000000002})
+	require.NoError(t, err)
+	dc3, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1000000004})
+	require.NoError(t, err)
+	autoOpsRule := &AutoOpsRule{&autoopsproto.AutoOpsRule{
+		Clauses: []*autoopsproto.Clause{
+			{Id: "c1", Clause: dc1},
+			{Id: "c2", Clause: dc2},
+			{Id: "c3", Clause: dc3},
+		},
+	}}
+	actual, err := autoOpsRule.LatestDatetimeTrigger(1000000003)
+	assert.NoError(t, err)
+	assert.Equal(t, &autoopsproto.ExecutionTrigger{ClauseId: "c2", Time: 1000000002}, actual)
+	actual, err = autoOpsRule.LatestDatetimeTrigger(1000000000)
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
 func TestExtractWebhookClauses(t *testing.T) {
 	wc1 := &autoopsproto.WebhookClause{
 		WebhookId: "foo-id",
@@ -284,13 +455,61 @@ func TestExtractWebhookClauses(t *testing.T) {
 	autoOpsRule := &AutoOpsRule{&autoopsproto.AutoOpsRule{
 		Id:        "id-0",
 		FeatureId: "fid-0",
-		Clauses:   []*autoopsproto.Clause{{Clause: c1}, {Clause: c2}, {Clause: c3}},
+		Clauses:   []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}, {Id: "c3", Clause: c3}},
 	}}
-	expected := []*autoopsproto.WebhookClause{wc1, wc2}
+	expected := map[string]*autoopsproto.WebhookClause{"c1": wc1, "c2": wc2}
 	actual, err := autoOpsRule.ExtractWebhookClauses()
 	assert.NoError(t, err)
 	assert.Equal(t, len(expected), len(actual))
-	for i, a := range actual {
-		assert.True(t, proto.Equal(expected[i], a))
+	for id, a := range actual {
+		assert.True(t, proto.Equal(expected[id], a))
+	}
+}
+
+func TestAddPrometheusClause(t *testing.T) {
+	t.Parallel()
+	aor := createAutoOpsRule(t)
+	aor.TriggeredAt = 1
+	pc := &autoopsproto.PrometheusClause{
+		Query:           "sum(rate(http_requests_total{code=~\"5..\"}[5m]))",
+		Operator:        autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+		Threshold:       0.1,
+		LookbackSeconds: 300,
+	}
+	clause, err := aor.AddPrometheusClause(pc)
+	require.NoError(t, err)
+	assert.NotEmpty(t, clause.Id)
+	assert.Zero(t, aor.TriggeredAt)
+	pc.Threshold = 0.2
+	err = aor.ChangePrometheusClause(clause.Id, pc)
+	require.NoError(t, err)
+	actual, err := aor.ExtractPrometheusClauses()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(actual))
+	assert.True(t, proto.Equal(pc, actual[clause.Id]))
+}
+
+func TestExtractPrometheusClauses(t *testing.T) {
+	pc1 := &autoopsproto.PrometheusClause{
+		Query:           "up",
+		Operator:        autoopsproto.PrometheusClause_LESS_OR_EQUAL,
+		Threshold:       0,
+		LookbackSeconds: 60,
 	}
+	c1, err := ptypes.MarshalAny(pc1)
+	require.NoError(t, err)
+	dc1 := &autoopsproto.DatetimeClause{
+		Time: 1000000001,
+	}
+	c2, err := ptypes.MarshalAny(dc1)
+	require.NoError(t, err)
+	autoOpsRule := &AutoOpsRule{&autoopsproto.AutoOpsRule{
+		Id:        "id-0",
+		FeatureId: "fid-0",
+		Clauses:   []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+	}}
+	actual, err := autoOpsRule.ExtractPrometheusClauses()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(actual))
+	assert.True(t, proto.Equal(pc1, actual["c1"]))
 }
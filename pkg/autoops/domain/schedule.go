@@ -0,0 +1,209 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron"
+
+	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+// scheduleLookback is how far back the transitions of a rule that has not been triggered yet are searched,
+// so that the feature is put into the scheduled state soon after the rule is created or re-armed.
+const scheduleLookback = 31 * 24 * time.Hour
+
+var (
+	ErrScheduleCronInvalid     = errors.New("autoOpsRule: schedule cron expression is invalid")
+	ErrScheduleTimeZoneInvalid = errors.New("autoOpsRule: schedule time zone is invalid")
+)
+
+type schedule struct {
+	enable   cron.Schedule
+	disable  cron.Schedule
+	location *time.Location
+}
+
+// ValidateScheduleClause returns ErrScheduleCronInvalid or ErrScheduleTimeZoneInvalid
+// if the clause cannot be parsed.
+func ValidateScheduleClause(c *proto.ScheduleClause) error {
+	_, err := parseScheduleClause(c)
+	return err
+}
+
+func parseScheduleClause(c *proto.ScheduleClause) (*schedule, error) {
+	// Local depends on the server, so it is not accepted.
+	if c.TimeZone == "Local" {
+		return nil, ErrScheduleTimeZoneInvalid
+	}
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, ErrScheduleTimeZoneInvalid
+	}
+	enable, err := parseCron(c.EnableCron)
+	if err != nil {
+		return nil, err
+	}
+	disable, err := parseCron(c.DisableCron)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule{enable: enable, disable: disable, location: location}, nil
+}
+
+// parseCron accepts the standard five fields and the descriptors except @every,
+// so that a schedule is activated at most once a minute.
+func parseCron(expr string) (cron.Schedule, error) {
+	s, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, ErrScheduleCronInvalid
+	}
+	if _, ok := s.(*cron.SpecSchedule); !ok {
+		return nil, ErrScheduleCronInvalid
+	}
+	return s, nil
+}
+
+// latest returns the latest transition in (since, now], or nil if there is none.
+// When both actions are scheduled at the same time, disabling wins.
+func (s *schedule) latest(clauseID string, since, now int64) *proto.ScheduleTransition {
+	var transition *proto.ScheduleTransition
+	if t := latestActivation(s.enable, s.location, since, now); t > 0 {
+		transition = &proto.ScheduleTransition{
+			ClauseId: clauseID,
+			Time:     t,
+			Action:   proto.ScheduleTransition_ENABLE,
+		}
+	}
+	if t := latestActivation(s.disable, s.location, since, now); t > 0 {
+		transition = laterTransition(transition, &proto.ScheduleTransition{
+			ClauseId: clauseID,
+			Time:     t,
+			Action:   proto.ScheduleTransition_DISABLE,
+		})
+	}
+	return transition
+}
+
+// upcoming returns the transitions after now in time order, up to the limit.
+func (s *schedule) upcoming(clauseID string, now int64, limit int) []*proto.ScheduleTransition {
+	transitions := []*proto.ScheduleTransition{}
+	for _, a := range []struct {
+		schedule cron.Schedule
+		action   proto.ScheduleTransition_Action
+	}{
+		{s.enable, proto.ScheduleTransition_ENABLE},
+		{s.disable, proto.ScheduleTransition_DISABLE},
+	} {
+		t := time.Unix(now, 0).In(s.location)
+		for i := 0; i < limit; i++ {
+			if t = a.schedule.Next(t); t.IsZero() {
+				break
+			}
+			transitions = append(transitions, &proto.ScheduleTransition{
+				ClauseId: clauseID,
+				Time:     t.Unix(),
+				Action:   a.action,
+			})
+		}
+	}
+	sortTransitions(transitions)
+	if len(transitions) > limit {
+		transitions = transitions[:limit]
+	}
+	return transitions
+}
+
+func latestActivation(s cron.Schedule, location *time.Location, since, now int64) int64 {
+	var latest int64
+	for t := s.Next(time.Unix(since, 0).In(location)); !t.IsZero() && t.Unix() <= now; t = s.Next(t) {
+		latest = t.Unix()
+	}
+	return latest
+}
+
+func laterTransition(a, b *proto.ScheduleTransition) *proto.ScheduleTransition {
+	if a == nil {
+		return b
+	}
+	if b.Time > a.Time || (b.Time == a.Time && b.Action == proto.ScheduleTransition_DISABLE) {
+		return b
+	}
+	return a
+}
+
+func sortTransitions(transitions []*proto.ScheduleTransition) {
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if transitions[i].Time != transitions[j].Time {
+			return transitions[i].Time < transitions[j].Time
+		}
+		return transitions[i].Action < transitions[j].Action
+	})
+}
+
+// DueScheduleTransition returns the latest transition of the schedule clauses
+// that has come since the rule was triggered, or nil if there is none.
+func (a *AutoOpsRule) DueScheduleTransition(now int64) (*proto.ScheduleTransition, error) {
+	since := a.TriggeredAt
+	if since == 0 {
+		since = now - int64(scheduleLookback.Seconds())
+	}
+	var due *proto.ScheduleTransition
+	for _, c := range a.Clauses {
+		scheduleClause, err := a.unmarshalScheduleClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if scheduleClause == nil {
+			continue
+		}
+		s, err := parseScheduleClause(scheduleClause)
+		if err != nil {
+			return nil, err
+		}
+		if t := s.latest(c.Id, since, now); t != nil {
+			due = laterTransition(due, t)
+		}
+	}
+	return due, nil
+}
+
+// UpcomingScheduleTransitions returns the transitions of the schedule clauses after now
+// in time order, up to the limit.
+func (a *AutoOpsRule) UpcomingScheduleTransitions(now int64, limit int) ([]*proto.ScheduleTransition, error) {
+	transitions := []*proto.ScheduleTransition{}
+	for _, c := range a.Clauses {
+		scheduleClause, err := a.unmarshalScheduleClause(c)
+		if err != nil {
+			return nil, err
+		}
+		if scheduleClause == nil {
+			continue
+		}
+		s, err := parseScheduleClause(scheduleClause)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, s.upcoming(c.Id, now, limit)...)
+	}
+	sortTransitions(transitions)
+	if len(transitions) > limit {
+		transitions = transitions[:limit]
+	}
+	return transitions, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+var tokyo = time.FixedZone("JST", 9*60*60)
+
+func createScheduleAutoOpsRule(t *testing.T) *AutoOpsRule {
+	t.Helper()
+	aor, err := NewAutoOpsRule(
+		"feature-id",
+		autoopsproto.OpsType_ENABLE_FEATURE,
+		nil,
+		[]*autoopsproto.OpsEventRateClause{},
+		[]*autoopsproto.DatetimeClause{},
+		[]*autoopsproto.WebhookClause{},
+		[]*autoopsproto.PrometheusClause{},
+		[]*autoopsproto.ScheduleClause{
+			{
+				EnableCron:  "0 18 * * 1-5",
+				DisableCron: "0 22 * * 1-5",
+				TimeZone:    "Asia/Tokyo",
+			},
+		},
+	)
+	require.NoError(t, err)
+	return aor
+}
+
+func TestValidateScheduleClause(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		clause   *autoopsproto.ScheduleClause
+		expected error
+	}{
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "@midnight"},
+			expected: nil,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * *", TimeZone: "Asia/Tokyo"},
+			expected: nil,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "", DisableCron: "0 22 * * *"},
+			expected: ErrScheduleCronInvalid,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 0 18 * * 1-5", DisableCron: "0 22 * * *"},
+			expected: ErrScheduleCronInvalid,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "@every 1s"},
+			expected: ErrScheduleCronInvalid,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * *", TimeZone: "Asia/Nowhere"},
+			expected: ErrScheduleTimeZoneInvalid,
+		},
+		{
+			clause:   &autoopsproto.ScheduleClause{EnableCron: "0 18 * * 1-5", DisableCron: "0 22 * * *", TimeZone: "Local"},
+			expected: ErrScheduleTimeZoneInvalid,
+		},
+	}
+	for i, p := range patterns {
+		assert.Equal(t, p.expected, ValidateScheduleClause(p.clause), "pattern %d", i)
+	}
+}
+
+func TestHasScheduleClause(t *testing.T) {
+	t.Parallel()
+	assert.False(t, createAutoOpsRule(t).HasScheduleClause())
+	aor := createScheduleAutoOpsRule(t)
+	assert.True(t, aor.HasScheduleClause())
+	scheduleClauses, err := aor.ExtractScheduleClauses()
+	require.NoError(t, err)
+	assert.Len(t, scheduleClauses, 1)
+	assert.Equal(t, "Asia/Tokyo", scheduleClauses[aor.Clauses[0].Id].TimeZone)
+}
+
+func TestAlreadyTriggeredWithScheduleClause(t *testing.T) {
+	t.Parallel()
+	aor := createScheduleAutoOpsRule(t)
+	aor.SetTriggeredAt()
+	assert.False(t, aor.AlreadyTriggered())
+}
+
+func TestDueScheduleTransition(t *testing.T) {
+	t.Parallel()
+	// 2022-06-01 is a Wednesday.
+	wednesday := func(hour, min int) int64 {
+		return time.Date(2022, 6, 1, hour, min, 0, 0, tokyo).Unix()
+	}
+	patterns := map[string]struct {
+		triggeredAt int64
+		now         int64
+		expected    *autoopsproto.ScheduleTransition
+	}{
+		"not triggered yet: enabled in the window": {
+			triggeredAt: 0,
+			now:         wednesday(20, 0),
+			expected: &autoopsproto.ScheduleTransition{
+				Time:   wednesday(18, 0),
+				Action: autoopsproto.ScheduleTransition_ENABLE,
+			},
+		},
+		"not triggered yet: disabled out of the window": {
+			triggeredAt: 0,
+			now:         wednesday(10, 0),
+			expected: &autoopsproto.ScheduleTransition{
+				Time:   time.Date(2022, 5, 31, 22, 0, 0, 0, tokyo).Unix(),
+				Action: autoopsproto.ScheduleTransition_DISABLE,
+			},
+		},
+		"triggered in the window": {
+			triggeredAt: wednesday(18, 0),
+			now:         wednesday(20, 0),
+			expected:    nil,
+		},
+		"disabled at the end of the window": {
+			triggeredAt: wednesday(18, 0),
+			now:         wednesday(22, 0),
+			expected: &autoopsproto.ScheduleTransition{
+				Time:   wednesday(22, 0),
+				Action: autoopsproto.ScheduleTransition_DISABLE,
+			},
+		},
+		"missed transitions: the latest": {
+			triggeredAt: time.Date(2022, 5, 30, 19, 0, 0, 0, tokyo).Unix(),
+			now:         wednesday(19, 0),
+			expected: &autoopsproto.ScheduleTransition{
+				Time:   wednesday(18, 0),
+				Action: autoopsproto.ScheduleTransition_ENABLE,
+			},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			aor := createScheduleAutoOpsRule(t)
+			aor.TriggeredAt = p.triggeredAt
+			if p.expected != nil {
+				p.expected.ClauseId = aor.Clauses[0].Id
+			}
+			actual, err := aor.DueScheduleTransition(p.now)
+			require.NoError(t, err)
+			assert.Equal(t, p.expected, actual)
+		})
+	}
+}
+
+func TestDueScheduleTransitionWithoutScheduleClause(t *testing.T) {
+	t.Parallel()
+	actual, err := createAutoOpsRule(t).DueScheduleTransition(time.Now().Unix())
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestUpcomingScheduleTransitions(t *testing.T) {
+	t.Parallel()
+	aor := createScheduleAutoOpsRule(t)
+	id := aor.Clauses[0].Id
+	// 2022-06-03 is a Friday.
+	now := time.Date(2022, 6, 3, 20, 0, 0, 0, tokyo).Unix()
+	actual, err := aor.UpcomingScheduleTransitions(now, 3)
+	require.NoError(t, err)
+	expected := []*autoopsproto.ScheduleTransition{
+		{
+			ClauseId: id,
+			Time:     time.Date(2022, 6, 3, 22, 0, 0, 0, tokyo).Unix(),
+			Action:   autoopsproto.ScheduleTransition_DISABLE,
+		},
+		{
+			ClauseId: id,
+			Time:     time.Date(2022, 6, 6, 18, 0, 0, 0, tokyo).Unix(),
+			Action:   autoopsproto.ScheduleTransition_ENABLE,
+		},
+		{
+			ClauseId: id,
+			Time:     time.Date(2022, 6, 6, 22, 0, 0, 0, tokyo).Unix(),
+			Action:   autoopsproto.ScheduleTransition_DISABLE,
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
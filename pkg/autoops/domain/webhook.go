@@ -21,19 +21,32 @@ import (
 )
 
 // Webhook holds the settings for accepting webhooks from alert systems, etc.
+// The signing secrets are encrypted and never exposed through the proto.
 type Webhook struct {
 	*proto.Webhook
+	SigningSecret         []byte
+	PreviousSigningSecret []byte
 }
 
-func NewWebhook(id, name, description string) *Webhook {
+func NewWebhook(
+	id, name, description string,
+	payloadFormat proto.Webhook_PayloadFormat,
+	signatureRequired bool,
+	signingSecret []byte,
+) *Webhook {
 	now := time.Now().Unix()
-	return &Webhook{&proto.Webhook{
-		Id:          id,
-		Name:        name,
-		Description: description,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}}
+	return &Webhook{
+		Webhook: &proto.Webhook{
+			Id:                id,
+			Name:              name,
+			Description:       description,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+			PayloadFormat:     payloadFormat,
+			SignatureRequired: signatureRequired,
+		},
+		SigningSecret: signingSecret,
+	}
 }
 
 func (w *Webhook) ChangeName(name string) error {
@@ -47,3 +60,46 @@ func (w *Webhook) ChangeDescription(description string) error {
 	w.UpdatedAt = time.Now().Unix()
 	return nil
 }
+
+func (w *Webhook) ChangePayloadFormat(payloadFormat proto.Webhook_PayloadFormat) error {
+	w.PayloadFormat = payloadFormat
+	w.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func (w *Webhook) ChangeSignatureRequired(signatureRequired bool) error {
+	w.SignatureRequired = signatureRequired
+	w.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// RotateSecret advances the version of the URL secret and replaces the signing secret.
+// The previous ones remain valid for the grace period.
+func (w *Webhook) RotateSecret(signingSecret []byte, gracePeriodSeconds int64) {
+	now := time.Now().Unix()
+	w.PreviousSigningSecret = w.SigningSecret
+	w.SigningSecret = signingSecret
+	w.SecretVersion++
+	w.PreviousSecretExpiresAt = now + gracePeriodSeconds
+	w.UpdatedAt = now
+}
+
+// IsValidSecretVersion reports whether the URL secret of the version is accepted at the time.
+func (w *Webhook) IsValidSecretVersion(version int32, now int64) bool {
+	if version == w.SecretVersion {
+		return true
+	}
+	return version == w.SecretVersion-1 && now < w.PreviousSecretExpiresAt
+}
+
+// ValidSigningSecrets returns the encrypted signing secrets accepted at the time.
+func (w *Webhook) ValidSigningSecrets(now int64) [][]byte {
+	var secrets [][]byte
+	if len(w.SigningSecret) > 0 {
+		secrets = append(secrets, w.SigningSecret)
+	}
+	if len(w.PreviousSigningSecret) > 0 && now < w.PreviousSecretExpiresAt {
+		secrets = append(secrets, w.PreviousSigningSecret)
+	}
+	return secrets
+}
@@ -21,18 +21,21 @@ import (
 type webhookSecret struct {
 	WebhookID            string `json:"webhook_id"`
 	EnvironmentNamespace string `json:"environment_namespace"`
+	Version              int32  `json:"version,omitempty"`
 }
 
 type WebhookSecret interface {
 	Marshal() ([]byte, error)
 	GetWebhookID() string
 	GetEnvironmentNamespace() string
+	GetVersion() int32
 }
 
-func NewWebhookSecret(webhookID, environmentNamespace string) WebhookSecret {
+func NewWebhookSecret(webhookID, environmentNamespace string, version int32) WebhookSecret {
 	return &webhookSecret{
 		EnvironmentNamespace: environmentNamespace,
 		WebhookID:            webhookID,
+		Version:              version,
 	}
 }
 
@@ -55,3 +58,7 @@ func (ws *webhookSecret) GetWebhookID() string {
 func (ws *webhookSecret) GetEnvironmentNamespace() string {
 	return ws.EnvironmentNamespace
 }
+
+func (ws *webhookSecret) GetVersion() int32 {
+	return ws.Version
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+func TestNewWebhook(t *testing.T) {
+	t.Parallel()
+	w := NewWebhook("id", "name", "description", autoopsproto.Webhook_GRAFANA, true, []byte("secret"))
+	assert.Equal(t, "id", w.Id)
+	assert.Equal(t, autoopsproto.Webhook_GRAFANA, w.PayloadFormat)
+	assert.True(t, w.SignatureRequired)
+	assert.Equal(t, []byte("secret"), w.SigningSecret)
+	assert.Zero(t, w.SecretVersion)
+	assert.Equal(t, [][]byte{[]byte("secret")}, w.ValidSigningSecrets(time.Now().Unix()))
+}
+
+func TestRotateSecret(t *testing.T) {
+	t.Parallel()
+	w := NewWebhook("id", "name", "description", autoopsproto.Webhook_RAW, true, []byte("secret-0"))
+	w.RotateSecret([]byte("secret-1"), 3600)
+	assert.Equal(t, int32(1), w.SecretVersion)
+	assert.Equal(t, []byte("secret-1"), w.SigningSecret)
+	assert.Equal(t, []byte("secret-0"), w.PreviousSigningSecret)
+
+	now := time.Now().Unix()
+	assert.True(t, w.IsValidSecretVersion(1, now))
+	assert.True(t, w.IsValidSecretVersion(0, now))
+	assert.Equal(t, [][]byte{[]byte("secret-1"), []byte("secret-0")}, w.ValidSigningSecrets(now))
+
+	expired := now + 3600
+	assert.True(t, w.IsValidSecretVersion(1, expired))
+	assert.False(t, w.IsValidSecretVersion(0, expired))
+	assert.Equal(t, [][]byte{[]byte("secret-1")}, w.ValidSigningSecrets(expired))
+
+	w.RotateSecret([]byte("secret-2"), 0)
+	assert.Equal(t, int32(2), w.SecretVersion)
+	assert.False(t, w.IsValidSecretVersion(1, now))
+	assert.False(t, w.IsValidSecretVersion(0, now))
+	assert.True(t, w.IsValidSecretVersion(2, now))
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+var (
+	ErrAutoOpsExecutionAlreadyExists = errors.New("autoOpsExecution: already exists")
+)
+
+type AutoOpsExecutionStorage interface {
+	CreateAutoOpsExecution(ctx context.Context, e *domain.AutoOpsExecution, environmentNamespace string) error
+	ListAutoOpsExecutions(
+		ctx context.Context,
+		whereParts []mysql.WherePart,
+		orders []*mysql.Order,
+		limit, offset int,
+	) ([]*proto.AutoOpsExecution, int, error)
+}
+
+type autoOpsExecutionStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewAutoOpsExecutionStorage(qe mysql.QueryExecer) AutoOpsExecutionStorage {
+	return &autoOpsExecutionStorage{qe: qe}
+}
+
+func (s *autoOpsExecutionStorage) CreateAutoOpsExecution(
+	ctx context.Context,
+	e *domain.AutoOpsExecution,
+	environmentNamespace string,
+) error {
+	query := `
+		INSERT INTO auto_ops_execution (
+			id,
+			auto_ops_rule_id,
+			feature_id,
+			execution_trigger,
+			ops_type,
+			ops_action,
+			reversed,
+			feature_version,
+			executed_at,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		e.Id,
+		e.AutoOpsRuleId,
+		e.FeatureId,
+		mysql.JSONObject{Val: e.Trigger},
+		int32(e.OpsType),
+		mysql.JSONObject{Val: e.OpsAction},
+		e.Reversed,
+		e.FeatureVersion,
+		e.ExecutedAt,
+		environmentNamespace,
+	)
+	if err != nil {
+		if err == mysql.ErrDuplicateEntry {
+			return ErrAutoOpsExecutionAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *autoOpsExecutionStorage) ListAutoOpsExecutions(
+	ctx context.Context,
+	whereParts []mysql.WherePart,
+	orders []*mysql.Order,
+	limit, offset int,
+) ([]*proto.AutoOpsExecution, int, error) {
+	whereSQL, whereArgs := mysql.ConstructWhereSQLString(whereParts)
+	orderBySQL := mysql.ConstructOrderBySQLString(orders)
+	limitOffsetSQL := mysql.ConstructLimitOffsetSQLString(limit, offset)
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			auto_ops_rule_id,
+			feature_id,
+			execution_trigger,
+			ops_type,
+			ops_action,
+			reversed,
+			feature_version,
+			executed_at
+		FROM
+			auto_ops_execution
+		%s %s %s
+		`, whereSQL, orderBySQL, limitOffsetSQL,
+	)
+	rows, err := s.qe.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	executions := make([]*proto.AutoOpsExecution, 0, limit)
+	for rows.Next() {
+		execution := proto.AutoOpsExecution{}
+		var opsType int32
+		err := rows.Scan(
+			&execution.Id,
+			&execution.AutoOpsRuleId,
+			&execution.FeatureId,
+			&mysql.JSONObject{Val: &execution.Trigger},
+			&opsType,
+			&mysql.JSONObject{Val: &execution.OpsAction},
+			&execution.Reversed,
+			&execution.FeatureVersion,
+			&execution.ExecutedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		execution.OpsType = proto.OpsType(opsType)
+		executions = append(executions, &execution)
+	}
+	if rows.Err() != nil {
+		return nil, 0, err
+	}
+	nextOffset := offset + len(executions)
+	return executions, nextOffset, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+func TestNewAutoOpsExecutionStorage(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	db := NewAutoOpsExecutionStorage(mock.NewMockQueryExecer(mockController))
+	assert.IsType(t, &autoOpsExecutionStorage{}, db)
+}
+
+func TestCreateAutoOpsExecution(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup                func(*autoOpsExecutionStorage)
+		input                *domain.AutoOpsExecution
+		environmentNamespace string
+		expectedErr          error
+	}{
+		{
+			setup: func(s *autoOpsExecutionStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, mysql.ErrDuplicateEntry)
+			},
+			input: &domain.AutoOpsExecution{
+				AutoOpsExecution: &proto.AutoOpsExecution{Id: "id-0"},
+			},
+			environmentNamespace: "ns0",
+			expectedErr:          ErrAutoOpsExecutionAlreadyExists,
+		},
+		{
+			setup: func(s *autoOpsExecutionStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			input: &domain.AutoOpsExecution{
+				AutoOpsExecution: &proto.AutoOpsExecution{
+					Id:      "id-1",
+					Trigger: &proto.ExecutionTrigger{ClauseId: "cid"},
+				},
+			},
+			environmentNamespace: "ns0",
+			expectedErr:          nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newAutoOpsExecutionStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		err := storage.CreateAutoOpsExecution(context.Background(), p.input, p.environmentNamespace)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestListAutoOpsExecutions(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	patterns := []struct {
+		setup          func(*autoOpsExecutionStorage)
+		whereParts     []mysql.WherePart
+		orders         []*mysql.Order
+		limit          int
+		offset         int
+		expected       []*proto.AutoOpsExecution
+		expectedCursor int
+		expectedErr    error
+	}{
+		{
+			setup: func(s *autoOpsExecutionStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			whereParts:     nil,
+			orders:         nil,
+			limit:          0,
+			offset:         0,
+			expected:       nil,
+			expectedCursor: 0,
+			expectedErr:    errors.New("error"),
+		},
+		{
+			setup: func(s *autoOpsExecutionStorage) {
+				rows := mock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			whereParts: []mysql.WherePart{
+				mysql.NewFilter("auto_ops_rule_id", "=", "rid"),
+			},
+			orders: []*mysql.Order{
+				mysql.NewOrder("executed_at", mysql.OrderDirectionDesc),
+			},
+			limit:          10,
+			offset:         5,
+			expected:       []*proto.AutoOpsExecution{},
+			expectedCursor: 5,
+			expectedErr:    nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newAutoOpsExecutionStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		executions, cursor, err := storage.ListAutoOpsExecutions(
+			context.Background(),
+			p.whereParts,
+			p.orders,
+			p.limit,
+			p.offset,
+		)
+		assert.Equal(t, p.expected, executions)
+		assert.Equal(t, p.expectedCursor, cursor)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func newAutoOpsExecutionStorageWithMock(
+	t *testing.T,
+	mockController *gomock.Controller,
+) *autoOpsExecutionStorage {
+	t.Helper()
+	return &autoOpsExecutionStorage{mock.NewMockQueryExecer(mockController)}
+}
@@ -61,14 +61,20 @@ func (s *autoOpsRuleStorage) CreateAutoOpsRule(
 			id,
 			feature_id,
 			ops_type,
+			ops_action,
 			clauses,
 			triggered_at,
 			created_at,
 			updated_at,
 			deleted,
+			clause_operator,
+			cooldown_seconds,
+			sustained_evaluations,
+			armed_at,
+			shadow,
 			environment_namespace
 		) VALUES (
-			?, ?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		)
 	`
 	_, err := s.qe.ExecContext(
@@ -77,11 +83,17 @@ func (s *autoOpsRuleStorage) CreateAutoOpsRule(
 		e.Id,
 		e.FeatureId,
 		int32(e.OpsType),
+		mysql.JSONObject{Val: e.OpsAction},
 		mysql.JSONObject{Val: e.Clauses},
 		e.TriggeredAt,
 		e.CreatedAt,
 		e.UpdatedAt,
 		e.Deleted,
+		int32(e.ClauseOperator),
+		e.CooldownSeconds,
+		e.SustainedEvaluations,
+		e.ArmedAt,
+		e.Shadow,
 		environmentNamespace,
 	)
 	if err != nil {
@@ -104,11 +116,17 @@ func (s *autoOpsRuleStorage) UpdateAutoOpsRule(
 		SET
 			feature_id = ?,
 			ops_type = ?,
+			ops_action = ?,
 			clauses = ?,
 			triggered_at = ?,
 			created_at = ?,
 			updated_at = ?,
-			deleted = ?
+			deleted = ?,
+			clause_operator = ?,
+			cooldown_seconds = ?,
+			sustained_evaluations = ?,
+			armed_at = ?,
+			shadow = ?
 		WHERE
 			id = ? AND
 			environment_namespace = ?
@@ -118,11 +136,17 @@ func (s *autoOpsRuleStorage) UpdateAutoOpsRule(
 		query,
 		e.FeatureId,
 		int32(e.OpsType),
+		mysql.JSONObject{Val: e.OpsAction},
 		mysql.JSONObject{Val: e.Clauses},
 		e.TriggeredAt,
 		e.CreatedAt,
 		e.UpdatedAt,
 		e.Deleted,
+		int32(e.ClauseOperator),
+		e.CooldownSeconds,
+		e.SustainedEvaluations,
+		e.ArmedAt,
+		e.Shadow,
 		e.Id,
 		environmentNamespace,
 	)
@@ -145,16 +169,23 @@ func (s *autoOpsRuleStorage) GetAutoOpsRule(
 ) (*domain.AutoOpsRule, error) {
 	autoOpsRule := proto.AutoOpsRule{}
 	var opsType int32
+	var clauseOperator int32
 	query := `
 		SELECT
 			id,
 			feature_id,
 			ops_type,
+			ops_action,
 			clauses,
 			triggered_at,
 			created_at,
 			updated_at,
-			deleted
+			deleted,
+			clause_operator,
+			cooldown_seconds,
+			sustained_evaluations,
+			armed_at,
+			shadow
 		FROM
 			auto_ops_rule
 		WHERE
@@ -170,11 +201,17 @@ func (s *autoOpsRuleStorage) GetAutoOpsRule(
 		&autoOpsRule.Id,
 		&autoOpsRule.FeatureId,
 		&opsType,
+		&mysql.JSONObject{Val: &autoOpsRule.OpsAction},
 		&mysql.JSONObject{Val: &autoOpsRule.Clauses},
 		&autoOpsRule.TriggeredAt,
 		&autoOpsRule.CreatedAt,
 		&autoOpsRule.UpdatedAt,
 		&autoOpsRule.Deleted,
+		&clauseOperator,
+		&autoOpsRule.CooldownSeconds,
+		&autoOpsRule.SustainedEvaluations,
+		&autoOpsRule.ArmedAt,
+		&autoOpsRule.Shadow,
 	)
 	if err != nil {
 		if err == mysql.ErrNoRows {
@@ -183,6 +220,7 @@ func (s *autoOpsRuleStorage) GetAutoOpsRule(
 		return nil, err
 	}
 	autoOpsRule.OpsType = proto.OpsType(opsType)
+	autoOpsRule.ClauseOperator = proto.AutoOpsRule_ClauseOperator(clauseOperator)
 	return &domain.AutoOpsRule{AutoOpsRule: &autoOpsRule}, nil
 }
 
@@ -200,11 +238,17 @@ func (s *autoOpsRuleStorage) ListAutoOpsRules(
 			id,
 			feature_id,
 			ops_type,
+			ops_action,
 			clauses,
 			triggered_at,
 			created_at,
 			updated_at,
-			deleted
+			deleted,
+			clause_operator,
+			cooldown_seconds,
+			sustained_evaluations,
+			armed_at,
+			shadow
 		FROM
 			auto_ops_rule
 		%s %s %s
@@ -219,20 +263,28 @@ func (s *autoOpsRuleStorage) ListAutoOpsRules(
 	for rows.Next() {
 		autoOpsRule := proto.AutoOpsRule{}
 		var opsType int32
+		var clauseOperator int32
 		err := rows.Scan(
 			&autoOpsRule.Id,
 			&autoOpsRule.FeatureId,
 			&opsType,
+			&mysql.JSONObject{Val: &autoOpsRule.OpsAction},
 			&mysql.JSONObject{Val: &autoOpsRule.Clauses},
 			&autoOpsRule.TriggeredAt,
 			&autoOpsRule.CreatedAt,
 			&autoOpsRule.UpdatedAt,
 			&autoOpsRule.Deleted,
+			&clauseOperator,
+			&autoOpsRule.CooldownSeconds,
+			&autoOpsRule.SustainedEvaluations,
+			&autoOpsRule.ArmedAt,
+			&autoOpsRule.Shadow,
 		)
 		if err != nil {
 			return nil, 0, err
 		}
 		autoOpsRule.OpsType = proto.OpsType(opsType)
+		autoOpsRule.ClauseOperator = proto.AutoOpsRule_ClauseOperator(clauseOperator)
 		autoOpsRules = append(autoOpsRules, &autoOpsRule)
 	}
 	if rows.Err() != nil {
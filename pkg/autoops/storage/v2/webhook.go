@@ -66,8 +66,14 @@ func (s *webhookStorage) CreateWebhook(
 			description,
 			environment_namespace,
 			created_at,
-			updated_at
-		) VALUES (?, ?, ?, ?, ?, ?)
+			updated_at,
+			payload_format,
+			signature_required,
+			secret_version,
+			previous_secret_expires_at,
+			signing_secret,
+			previous_signing_secret
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := s.qe.ExecContext(
 		ctx,
@@ -78,6 +84,12 @@ func (s *webhookStorage) CreateWebhook(
 		environmentNamespace,
 		webhook.CreatedAt,
 		webhook.UpdatedAt,
+		int32(webhook.PayloadFormat),
+		webhook.SignatureRequired,
+		webhook.SecretVersion,
+		webhook.PreviousSecretExpiresAt,
+		webhook.SigningSecret,
+		webhook.PreviousSigningSecret,
 	)
 	if err != nil {
 		if err == mysql.ErrDuplicateEntry {
@@ -99,7 +111,13 @@ func (s *webhookStorage) UpdateWebhook(
 		SET
 			name = ?,
 			description = ?,
-			updated_at = ?
+			updated_at = ?,
+			payload_format = ?,
+			signature_required = ?,
+			secret_version = ?,
+			previous_secret_expires_at = ?,
+			signing_secret = ?,
+			previous_signing_secret = ?
 		WHERE
 			id = ? AND
 			environment_namespace = ?
@@ -110,6 +128,12 @@ func (s *webhookStorage) UpdateWebhook(
 		webhook.Name,
 		webhook.Description,
 		webhook.UpdatedAt,
+		int32(webhook.PayloadFormat),
+		webhook.SignatureRequired,
+		webhook.SecretVersion,
+		webhook.PreviousSecretExpiresAt,
+		webhook.SigningSecret,
+		webhook.PreviousSigningSecret,
 		webhook.Id,
 		environmentNamespace,
 	)
@@ -166,7 +190,13 @@ func (s *webhookStorage) GetWebhook(
 			name,
 			description,
 			created_at,
-			updated_at
+			updated_at,
+			payload_format,
+			signature_required,
+			secret_version,
+			previous_secret_expires_at,
+			signing_secret,
+			previous_signing_secret
 		FROM
 			webhook
 		WHERE
@@ -174,6 +204,11 @@ func (s *webhookStorage) GetWebhook(
 			environment_namespace = ?
 	`
 	webhook := proto.Webhook{}
+	var (
+		payloadFormat         int32
+		signingSecret         []byte
+		previousSigningSecret []byte
+	)
 	err := s.qe.QueryRowContext(
 		ctx,
 		query,
@@ -185,6 +220,12 @@ func (s *webhookStorage) GetWebhook(
 		&webhook.Description,
 		&webhook.CreatedAt,
 		&webhook.UpdatedAt,
+		&payloadFormat,
+		&webhook.SignatureRequired,
+		&webhook.SecretVersion,
+		&webhook.PreviousSecretExpiresAt,
+		&signingSecret,
+		&previousSigningSecret,
 	)
 	if err != nil {
 		if err == mysql.ErrNoRows {
@@ -192,7 +233,12 @@ func (s *webhookStorage) GetWebhook(
 		}
 		return nil, err
 	}
-	return &domain.Webhook{Webhook: &webhook}, nil
+	webhook.PayloadFormat = proto.Webhook_PayloadFormat(payloadFormat)
+	return &domain.Webhook{
+		Webhook:               &webhook,
+		SigningSecret:         signingSecret,
+		PreviousSigningSecret: previousSigningSecret,
+	}, nil
 }
 
 func (s *webhookStorage) ListWebhooks(
@@ -210,7 +256,11 @@ func (s *webhookStorage) ListWebhooks(
 			name,
 			description,
 			created_at,
-			updated_at
+			updated_at,
+			payload_format,
+			signature_required,
+			secret_version,
+			previous_secret_expires_at
 		FROM
 			webhook
 		%s %s %s
@@ -224,16 +274,22 @@ func (s *webhookStorage) ListWebhooks(
 	webhooks := make([]*proto.Webhook, 0, limit)
 	for rows.Next() {
 		webhook := proto.Webhook{}
+		var payloadFormat int32
 		err := rows.Scan(
 			&webhook.Id,
 			&webhook.Name,
 			&webhook.Description,
 			&webhook.CreatedAt,
 			&webhook.UpdatedAt,
+			&payloadFormat,
+			&webhook.SignatureRequired,
+			&webhook.SecretVersion,
+			&webhook.PreviousSecretExpiresAt,
 		)
 		if err != nil {
 			return nil, 0, 0, err
 		}
+		webhook.PayloadFormat = proto.Webhook_PayloadFormat(payloadFormat)
 		webhooks = append(webhooks, &webhook)
 	}
 	if rows.Err() != nil {
@@ -0,0 +1,102 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+)
+
+var (
+	ErrWebhookSignatureAlreadyExists = errors.New("webhookSignature: already exists")
+)
+
+// WebhookSignatureStorage keeps the signatures accepted by the webhooks,
+// so a replayed request is rejected by every instance of the webhook handler.
+type WebhookSignatureStorage interface {
+	CreateWebhookSignature(
+		ctx context.Context,
+		webhookID, signature string,
+		createdAt int64,
+		environmentNamespace string,
+	) error
+	DeleteWebhookSignatures(ctx context.Context, webhookID string, createdBefore int64, environmentNamespace string) error
+}
+
+type webhookSignatureStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewWebhookSignatureStorage(qe mysql.QueryExecer) WebhookSignatureStorage {
+	return &webhookSignatureStorage{qe: qe}
+}
+
+func (s *webhookSignatureStorage) CreateWebhookSignature(
+	ctx context.Context,
+	webhookID, signature string,
+	createdAt int64,
+	environmentNamespace string,
+) error {
+	query := `
+		INSERT INTO webhook_signature (
+			webhook_id,
+			signature,
+			created_at,
+			environment_namespace
+		) VALUES (?, ?, ?, ?)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		webhookID,
+		signature,
+		createdAt,
+		environmentNamespace,
+	)
+	if err != nil {
+		if err == mysql.ErrDuplicateEntry {
+			return ErrWebhookSignatureAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *webhookSignatureStorage) DeleteWebhookSignatures(
+	ctx context.Context,
+	webhookID string,
+	createdBefore int64,
+	environmentNamespace string,
+) error {
+	query := `
+		DELETE FROM
+			webhook_signature
+		WHERE
+			webhook_id = ? AND
+			created_at < ? AND
+			environment_namespace = ?
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		webhookID,
+		createdBefore,
+		environmentNamespace,
+	)
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+)
+
+func TestNewWebhookSignatureStorage(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	db := NewWebhookSignatureStorage(mock.NewMockQueryExecer(mockController))
+	assert.IsType(t, &webhookSignatureStorage{}, db)
+}
+
+func TestCreateWebhookSignature(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*webhookSignatureStorage)
+		expectedErr error
+	}{
+		{
+			desc: "err: already exists",
+			setup: func(s *webhookSignatureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, mysql.ErrDuplicateEntry)
+			},
+			expectedErr: ErrWebhookSignatureAlreadyExists,
+		},
+		{
+			desc: "err: unexpected error",
+			setup: func(s *webhookSignatureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			expectedErr: errors.New("error"),
+		},
+		{
+			desc: "success",
+			setup: func(s *webhookSignatureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			storage := newWebhookSignatureStorageWithMock(t, mockController)
+			p.setup(storage)
+			err := storage.CreateWebhookSignature(context.Background(), "wid", "v1=sig", 1, "ns0")
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestDeleteWebhookSignatures(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	storage := newWebhookSignatureStorageWithMock(t, mockController)
+	storage.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+		gomock.Any(), gomock.Any(), "wid", int64(1), "ns0",
+	).Return(nil, nil)
+	err := storage.DeleteWebhookSignatures(context.Background(), "wid", 1, "ns0")
+	assert.NoError(t, err)
+}
+
+func newWebhookSignatureStorageWithMock(
+	t *testing.T,
+	mockController *gomock.Controller,
+) *webhookSignatureStorage {
+	t.Helper()
+	return &webhookSignatureStorage{mock.NewMockQueryExecer(mockController)}
+}
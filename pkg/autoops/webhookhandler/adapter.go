@@ -0,0 +1,232 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookhandler
+
+import (
+	"errors"
+	"strings"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+const (
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+
+	datadogResolvedTransition = "Recovered"
+)
+
+var (
+	errUnexpectedPayload    = errors.New("autoops: unexpected webhook payload")
+	errUnknownPayloadFormat = errors.New("autoops: unknown webhook payload format")
+
+	datadogFiringTransitions = map[string]bool{"Triggered": true, "Re-Triggered": true}
+)
+
+// alertPayloads holds the payloads to be assessed against the webhook clauses.
+// A raw payload is always regarded as firing.
+type alertPayloads struct {
+	firing   []interface{}
+	resolved []interface{}
+}
+
+func (p *alertPayloads) add(alert map[string]interface{}) {
+	switch alert["status"] {
+	case alertStatusFiring:
+		p.firing = append(p.firing, alert)
+	case alertStatusResolved:
+		p.resolved = append(p.resolved, alert)
+	}
+}
+
+// normalizePayload converts the payload of the alert system into alerts of the common model
+// so that the same conditions can target them regardless of the alert system.
+func normalizePayload(
+	format autoopsproto.Webhook_PayloadFormat,
+	payload interface{},
+) (*alertPayloads, error) {
+	switch format {
+	case autoopsproto.Webhook_RAW:
+		return &alertPayloads{firing: []interface{}{payload}}, nil
+	case autoopsproto.Webhook_ALERTMANAGER:
+		return normalizeAlertmanagerPayload(payload)
+	case autoopsproto.Webhook_GRAFANA:
+		return normalizeGrafanaPayload(payload)
+	case autoopsproto.Webhook_DATADOG:
+		return normalizeDatadogPayload(payload)
+	}
+	return nil, errUnknownPayloadFormat
+}
+
+// normalizeAlertmanagerPayload converts the grouped alerts,
+// which can contain both firing and resolved alerts.
+func normalizeAlertmanagerPayload(payload interface{}) (*alertPayloads, error) {
+	m, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil, errUnexpectedPayload
+	}
+	alerts, ok := m["alerts"].([]interface{})
+	if !ok {
+		return nil, errUnexpectedPayload
+	}
+	p := &alertPayloads{}
+	for _, a := range alerts {
+		alert, ok := a.(map[string]interface{})
+		if !ok {
+			return nil, errUnexpectedPayload
+		}
+		labels := toLabels(alert["labels"])
+		annotations := toLabels(alert["annotations"])
+		p.add(newAlert(
+			stringValue(alert["status"]),
+			stringValue(labels["alertname"]),
+			stringValue(labels["severity"]),
+			stringValue(annotations["summary"]),
+			labels,
+			annotations,
+		))
+	}
+	return p, nil
+}
+
+// normalizeGrafanaPayload converts the payload of the Grafana alerting,
+// which is compatible with Alertmanager, and that of the legacy dashboard alerts.
+func normalizeGrafanaPayload(payload interface{}) (*alertPayloads, error) {
+	m, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil, errUnexpectedPayload
+	}
+	if _, ok := m["alerts"]; ok {
+		return normalizeAlertmanagerPayload(payload)
+	}
+	var status string
+	switch m["state"] {
+	case "alerting":
+		status = alertStatusFiring
+	case "ok":
+		status = alertStatusResolved
+	}
+	summary := stringValue(m["message"])
+	if summary == "" {
+		summary = stringValue(m["title"])
+	}
+	p := &alertPayloads{}
+	p.add(newAlert(
+		status,
+		stringValue(m["ruleName"]),
+		"",
+		summary,
+		toLabels(m["tags"]),
+		map[string]interface{}{},
+	))
+	return p, nil
+}
+
+// normalizeDatadogPayload converts the payload of the Datadog monitor.
+// The payload is expected to be templated with the following keys.
+//
+//	{
+//	  "alert_transition": "$ALERT_TRANSITION",
+//	  "alert_title": "$ALERT_TITLE",
+//	  "priority": "$ALERT_PRIORITY",
+//	  "body": "$EVENT_MSG",
+//	  "tags": "$TAGS"
+//	}
+//
+// The other keys in the payload are kept as the annotations.
+func normalizeDatadogPayload(payload interface{}) (*alertPayloads, error) {
+	m, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil, errUnexpectedPayload
+	}
+	var status string
+	transition := stringValue(m["alert_transition"])
+	if datadogFiringTransitions[transition] {
+		status = alertStatusFiring
+	} else if transition == datadogResolvedTransition {
+		status = alertStatusResolved
+	}
+	annotations := map[string]interface{}{}
+	for k, v := range m {
+		switch k {
+		case "alert_transition", "alert_title", "priority", "body", "tags":
+			continue
+		}
+		annotations[k] = v
+	}
+	p := &alertPayloads{}
+	p.add(newAlert(
+		status,
+		stringValue(m["alert_title"]),
+		stringValue(m["priority"]),
+		stringValue(m["body"]),
+		datadogTags(m["tags"]),
+		annotations,
+	))
+	return p, nil
+}
+
+// datadogTags converts the tags such as "env:prod,service:api" into labels.
+func datadogTags(tags interface{}) map[string]interface{} {
+	var list []string
+	switch t := tags.(type) {
+	case string:
+		list = strings.Split(t, ",")
+	case []interface{}:
+		for _, v := range t {
+			list = append(list, stringValue(v))
+		}
+	}
+	labels := make(map[string]interface{}, len(list))
+	for _, tag := range list {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) == 1 {
+			labels[kv[0]] = ""
+			continue
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels
+}
+
+func newAlert(
+	status, name, severity, summary string,
+	labels, annotations map[string]interface{},
+) map[string]interface{} {
+	return map[string]interface{}{
+		"status":      status,
+		"name":        name,
+		"severity":    severity,
+		"summary":     summary,
+		"labels":      labels,
+		"annotations": annotations,
+	}
+}
+
+func toLabels(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
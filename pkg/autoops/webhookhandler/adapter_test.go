@@ -0,0 +1,198 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookhandler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+func TestNormalizePayload(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		format           autoopsproto.Webhook_PayloadFormat
+		payload          string
+		expectedFiring   []interface{}
+		expectedResolved []interface{}
+		expectedErr      error
+	}{
+		"raw": {
+			format:         autoopsproto.Webhook_RAW,
+			payload:        `{"body":{"Alert id":123}}`,
+			expectedFiring: []interface{}{map[string]interface{}{"body": map[string]interface{}{"Alert id": float64(123)}}},
+		},
+		"err: unknown format": {
+			format:      autoopsproto.Webhook_PayloadFormat(-1),
+			payload:     `{}`,
+			expectedErr: errUnknownPayloadFormat,
+		},
+		"err: alertmanager: no alerts": {
+			format:      autoopsproto.Webhook_ALERTMANAGER,
+			payload:     `{"status":"firing"}`,
+			expectedErr: errUnexpectedPayload,
+		},
+		"alertmanager": {
+			format: autoopsproto.Webhook_ALERTMANAGER,
+			payload: `{
+				"version": "4",
+				"status": "firing",
+				"alerts": [
+					{
+						"status": "firing",
+						"labels": {"alertname": "HighErrorRate", "severity": "critical", "service": "api"},
+						"annotations": {"summary": "5xx rate is high"}
+					},
+					{
+						"status": "resolved",
+						"labels": {"alertname": "HighLatency", "severity": "warning"},
+						"annotations": {}
+					}
+				]
+			}`,
+			expectedFiring: []interface{}{
+				newAlert(
+					"firing",
+					"HighErrorRate",
+					"critical",
+					"5xx rate is high",
+					map[string]interface{}{"alertname": "HighErrorRate", "severity": "critical", "service": "api"},
+					map[string]interface{}{"summary": "5xx rate is high"},
+				),
+			},
+			expectedResolved: []interface{}{
+				newAlert(
+					"resolved",
+					"HighLatency",
+					"warning",
+					"",
+					map[string]interface{}{"alertname": "HighLatency", "severity": "warning"},
+					map[string]interface{}{},
+				),
+			},
+		},
+		"grafana: alerting": {
+			format: autoopsproto.Webhook_GRAFANA,
+			payload: `{
+				"receiver": "bucketeer",
+				"status": "resolved",
+				"alerts": [
+					{
+						"status": "resolved",
+						"labels": {"alertname": "HighErrorRate"},
+						"annotations": {"summary": "5xx rate is high"},
+						"dashboardURL": "https://grafana.example.com/d/abc"
+					}
+				]
+			}`,
+			expectedResolved: []interface{}{
+				newAlert(
+					"resolved",
+					"HighErrorRate",
+					"",
+					"5xx rate is high",
+					map[string]interface{}{"alertname": "HighErrorRate"},
+					map[string]interface{}{"summary": "5xx rate is high"},
+				),
+			},
+		},
+		"grafana: legacy": {
+			format: autoopsproto.Webhook_GRAFANA,
+			payload: `{
+				"title": "[Alerting] HighErrorRate",
+				"ruleName": "HighErrorRate",
+				"state": "alerting",
+				"message": "5xx rate is high",
+				"tags": {"service": "api"}
+			}`,
+			expectedFiring: []interface{}{
+				newAlert(
+					"firing",
+					"HighErrorRate",
+					"",
+					"5xx rate is high",
+					map[string]interface{}{"service": "api"},
+					map[string]interface{}{},
+				),
+			},
+		},
+		"grafana: legacy: no data": {
+			format:  autoopsproto.Webhook_GRAFANA,
+			payload: `{"ruleName": "HighErrorRate", "state": "no_data"}`,
+		},
+		"datadog": {
+			format: autoopsproto.Webhook_DATADOG,
+			payload: `{
+				"alert_id": "123",
+				"alert_transition": "Triggered",
+				"alert_title": "High error rate on api",
+				"priority": "P1",
+				"body": "5xx rate is high",
+				"tags": "env:prod,service:api,critical"
+			}`,
+			expectedFiring: []interface{}{
+				newAlert(
+					"firing",
+					"High error rate on api",
+					"P1",
+					"5xx rate is high",
+					map[string]interface{}{"env": "prod", "service": "api", "critical": ""},
+					map[string]interface{}{"alert_id": "123"},
+				),
+			},
+		},
+		"datadog: recovered": {
+			format: autoopsproto.Webhook_DATADOG,
+			payload: `{
+				"alert_transition": "Recovered",
+				"alert_title": "High error rate on api",
+				"tags": ["env:prod"]
+			}`,
+			expectedResolved: []interface{}{
+				newAlert(
+					"resolved",
+					"High error rate on api",
+					"",
+					"",
+					map[string]interface{}{"env": "prod"},
+					map[string]interface{}{},
+				),
+			},
+		},
+		"datadog: warn": {
+			format:  autoopsproto.Webhook_DATADOG,
+			payload: `{"alert_transition": "Warn", "alert_title": "High error rate on api"}`,
+		},
+	}
+	for msg, p := range patterns {
+		p := p
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+			var payload interface{}
+			require.NoError(t, json.Unmarshal([]byte(p.payload), &payload))
+			actual, err := normalizePayload(p.format, payload)
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, p.expectedFiring, actual.firing)
+			assert.Equal(t, p.expectedResolved, actual.resolved)
+		})
+	}
+}
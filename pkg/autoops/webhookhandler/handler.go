@@ -16,12 +16,17 @@ package webhookhandler
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -45,14 +50,39 @@ import (
 
 const (
 	urlParamKeyAuth = "auth"
+
+	// The webhooks requiring a signature must be called with the unix timestamp in seconds
+	// and the hex encoded HMAC-SHA256 of "<timestamp>.<body>" prefixed with the signature version,
+	// e.g. "X-Bucketeer-Signature: v1=<hex encoded HMAC>".
+	headerKeyTimestamp = "X-Bucketeer-Timestamp"
+	headerKeySignature = "X-Bucketeer-Signature"
+	signatureVersion   = "v1"
+
+	defaultSignatureTolerance = 5 * time.Minute
+
+	// The payload of the execution is truncated not to store and notify a huge alert.
+	maxWebhookPayloadExcerptSize = 1024
 )
 
 var (
 	errAuthKeyEmpty     = errors.New("autoops: auth key is empty")
 	errAlreadyTriggered = errors.New("autoops: rule has already triggered")
 	errPermissionDenied = errors.New("autoops: permission denied")
+
+	errSecretExpired           = errors.New("autoops: webhook secret has expired")
+	errSignatureEmpty          = errors.New("autoops: signature is empty")
+	errInvalidTimestamp        = errors.New("autoops: timestamp is invalid")
+	errTimestampOutOfTolerance = errors.New("autoops: timestamp is out of the tolerance")
+	errSignatureMismatch       = errors.New("autoops: signature does not match")
+	errSignatureReplayed       = errors.New("autoops: signature has already been used")
 )
 
+func WithSignatureTolerance(tolerance time.Duration) Option {
+	return func(o *options) {
+		o.signatureTolerance = tolerance
+	}
+}
+
 func WithLogger(logger *zap.Logger) Option {
 	return func(o *options) {
 		o.logger = logger
@@ -60,19 +90,21 @@ func WithLogger(logger *zap.Logger) Option {
 }
 
 type handler struct {
-	mysqlClient       mysql.Client
-	authClient        authclient.Client
-	featureClient     featureclient.Client
-	publisher         publisher.Publisher
-	editor            *event.Editor
-	webhookCryptoUtil crypto.EncrypterDecrypter
-	logger            *zap.Logger
+	mysqlClient        mysql.Client
+	authClient         authclient.Client
+	featureClient      featureclient.Client
+	publisher          publisher.Publisher
+	editor             *event.Editor
+	webhookCryptoUtil  crypto.EncrypterDecrypter
+	signatureTolerance time.Duration
+	logger             *zap.Logger
 }
 
 type Option func(*options)
 
 type options struct {
-	logger *zap.Logger
+	signatureTolerance time.Duration
+	logger             *zap.Logger
 }
 
 func NewHandler(
@@ -86,7 +118,8 @@ func NewHandler(
 	opts ...Option,
 ) (*handler, error) {
 	options := &options{
-		logger: zap.NewNop(),
+		signatureTolerance: defaultSignatureTolerance,
+		logger:             zap.NewNop(),
 	}
 	for _, opt := range opts {
 		opt(options)
@@ -108,13 +141,14 @@ func NewHandler(
 		IsAdmin: true,
 	}
 	return &handler{
-		mysqlClient:       mysqlClient,
-		authClient:        authClient,
-		featureClient:     featureClient,
-		publisher:         publisher,
-		editor:            editor,
-		webhookCryptoUtil: webhookCryptoUtil,
-		logger:            options.logger.Named("webhookhandler"),
+		mysqlClient:        mysqlClient,
+		authClient:         authClient,
+		featureClient:      featureClient,
+		publisher:          publisher,
+		editor:             editor,
+		webhookCryptoUtil:  webhookCryptoUtil,
+		signatureTolerance: options.signatureTolerance,
+		logger:             options.logger.Named("webhookhandler"),
 	}, nil
 }
 
@@ -150,6 +184,47 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		resp.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Warn(
+			"Failed to read request body",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	webhook, err := v2as.NewWebhookStorage(h.mysqlClient).GetWebhook(
+		ctx,
+		ws.GetWebhookID(),
+		ws.GetEnvironmentNamespace(),
+	)
+	if err != nil {
+		h.logger.Error(
+			"Failed to get webhook",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", ws.GetEnvironmentNamespace()),
+			)...,
+		)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	signature, err := h.verifyRequest(ctx, webhook, ws, req.Header, body, now)
+	if err != nil {
+		h.logger.Warn(
+			"Failed to verify webhook request",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("webhookId", webhook.Id),
+				zap.String("environmentNamespace", ws.GetEnvironmentNamespace()),
+			)...,
+		)
+		resp.WriteHeader(http.StatusUnauthorized)
+		return
+	}
 	tx, err := h.mysqlClient.BeginTx(ctx)
 	if err != nil {
 		h.logger.Error(
@@ -162,12 +237,16 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 	err = h.mysqlClient.RunInTransaction(ctx, tx, func() error {
-		webhookStorage := v2as.NewWebhookStorage(tx)
-		webhook, err := webhookStorage.GetWebhook(ctx, ws.GetWebhookID(), ws.GetEnvironmentNamespace())
-		if err != nil {
-			return err
+		// The signature is consumed in the same transaction as the rules,
+		// so that the sender can retry with it when the execution fails.
+		if signature != "" {
+			storage := v2as.NewWebhookSignatureStorage(tx)
+			if err := h.useSignature(ctx, storage, webhook.Id, signature, ws.GetEnvironmentNamespace(), now); err != nil {
+				return err
+			}
 		}
 		autoOpsRuleStorage := v2as.NewAutoOpsRuleStorage(tx)
+		autoOpsExecutionStorage := v2as.NewAutoOpsExecutionStorage(tx)
 		whereParts := []mysql.WherePart{
 			mysql.NewFilter("deleted", "=", false),
 			mysql.NewFilter("environment_namespace", "=", ws.GetEnvironmentNamespace()),
@@ -183,7 +262,11 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 			return err
 		}
 		var payload interface{}
-		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return err
+		}
+		alerts, err := normalizePayload(webhook.PayloadFormat, payload)
+		if err != nil {
 			return err
 		}
 		// Handle webhook and assesses all rules
@@ -191,18 +274,57 @@ func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		var lastErr error
 		for _, r := range autoOpsRules {
 			rule := &autoopsdomain.AutoOpsRule{AutoOpsRule: r}
-			asmt, err := h.assessAutoOpsRule(ctx, rule, webhook.Id, payload)
+			trigger, err := h.assessAutoOpsRule(ctx, rule, webhook.Id, alerts.firing, false)
 			if err != nil {
 				lastErr = err
 			}
-			if asmt {
-				if err = h.executeAutoOps(ctx, rule, ws.GetEnvironmentNamespace(), autoOpsRuleStorage); err != nil {
+			if trigger != nil {
+				if err = h.executeAutoOps(
+					ctx,
+					rule,
+					trigger,
+					ws.GetEnvironmentNamespace(),
+					autoOpsRuleStorage,
+					autoOpsExecutionStorage,
+				); err != nil {
+					lastErr = err
+				}
+				continue
+			}
+			if rule.TriggeredAt == 0 || len(alerts.resolved) == 0 {
+				continue
+			}
+			trigger, err = h.assessAutoOpsRule(ctx, rule, webhook.Id, alerts.resolved, true)
+			if err != nil {
+				lastErr = err
+			}
+			if trigger != nil {
+				if err = h.reverseAutoOps(
+					ctx,
+					rule,
+					trigger,
+					ws.GetEnvironmentNamespace(),
+					autoOpsRuleStorage,
+					autoOpsExecutionStorage,
+				); err != nil {
 					lastErr = err
 				}
 			}
 		}
 		return lastErr
 	})
+	if err == errSignatureReplayed {
+		h.logger.Warn(
+			"Failed to verify webhook request",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("webhookId", webhook.Id),
+				zap.String("environmentNamespace", ws.GetEnvironmentNamespace()),
+			)...,
+		)
+		resp.WriteHeader(http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
 		h.logger.Error(
 			"Failed to execute autoOpsRule",
@@ -256,12 +378,132 @@ func (h *handler) authWebhook(
 	return ws, nil
 }
 
+// verifyRequest checks that the secret in the url has not expired by a rotation,
+// and verifies the signature of the request when the webhook requires it.
+// It returns the verified signature, which must then be consumed with useSignature,
+// or an empty string when the webhook doesn't require signatures.
+func (h *handler) verifyRequest(
+	ctx context.Context,
+	webhook *autoopsdomain.Webhook,
+	ws domain.WebhookSecret,
+	header http.Header,
+	body []byte,
+	now time.Time,
+) (string, error) {
+	if !webhook.IsValidSecretVersion(ws.GetVersion(), now.Unix()) {
+		return "", errSecretExpired
+	}
+	if !webhook.SignatureRequired {
+		return "", nil
+	}
+	timestamp := header.Get(headerKeyTimestamp)
+	signature := header.Get(headerKeySignature)
+	if timestamp == "" || signature == "" {
+		return "", errSignatureEmpty
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", errInvalidTimestamp
+	}
+	if diff := now.Sub(time.Unix(ts, 0)); diff > h.signatureTolerance || diff < -h.signatureTolerance {
+		return "", errTimestampOutOfTolerance
+	}
+	mac, err := decodeSignature(signature)
+	if err != nil {
+		return "", err
+	}
+	matched := false
+	// The previous signing secret is also accepted during the grace period of a rotation.
+	for _, encrypted := range webhook.ValidSigningSecrets(now.Unix()) {
+		secret, err := h.webhookCryptoUtil.Decrypt(ctx, encrypted)
+		if err != nil {
+			h.logger.Error(
+				"Failed to decrypt signing secret",
+				log.FieldsFromImcomingContext(ctx).AddFields(zap.Error(err))...,
+			)
+			return "", err
+		}
+		if hmac.Equal(mac, computeSignature(secret, timestamp, body)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return "", errSignatureMismatch
+	}
+	// The MAC is returned instead of the header, since the same MAC can be sent in upper or lower case.
+	return hex.EncodeToString(mac), nil
+}
+
+func decodeSignature(signature string) ([]byte, error) {
+	prefix := signatureVersion + "="
+	if !strings.HasPrefix(signature, prefix) {
+		return nil, errSignatureMismatch
+	}
+	mac, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return nil, errSignatureMismatch
+	}
+	return mac, nil
+}
+
+func computeSignature(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// useSignature rejects a signature that has already been accepted to prevent replay attacks.
+// The signatures are stored in MySQL so that a request replayed to another instance is also rejected.
+// They are kept for twice the tolerance, after which the timestamp check alone rejects them.
+func (h *handler) useSignature(
+	ctx context.Context,
+	storage v2as.WebhookSignatureStorage,
+	webhookID, signature, environmentNamespace string,
+	now time.Time,
+) error {
+	expiredAt := now.Add(-2 * h.signatureTolerance).Unix()
+	if err := storage.DeleteWebhookSignatures(ctx, webhookID, expiredAt, environmentNamespace); err != nil {
+		h.logger.Error(
+			"Failed to delete expired webhook signatures",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("webhookId", webhookID),
+			)...,
+		)
+		return err
+	}
+	err := storage.CreateWebhookSignature(ctx, webhookID, signature, now.Unix(), environmentNamespace)
+	if err != nil {
+		if err == v2as.ErrWebhookSignatureAlreadyExists {
+			return errSignatureReplayed
+		}
+		h.logger.Error(
+			"Failed to create webhook signature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("webhookId", webhookID),
+			)...,
+		)
+		return err
+	}
+	return nil
+}
+
+// assessAutoOpsRule returns the trigger of the first payload satisfying the webhook clauses of the rule,
+// or nil if no payload satisfies them.
+// The resolved payloads are only assessed against the clauses that reverse the action on them.
 func (h *handler) assessAutoOpsRule(
 	ctx context.Context,
 	a *autoopsdomain.AutoOpsRule,
 	tarId string,
-	payload interface{},
-) (bool, error) {
+	payloads []interface{},
+	resolved bool,
+) (*autoopsproto.ExecutionTrigger, error) {
 	webhookClauses, err := a.ExtractWebhookClauses()
 	if err != nil {
 		h.logger.Error("Failed to extract webhook clauses",
@@ -269,45 +511,72 @@ func (h *handler) assessAutoOpsRule(
 			zap.String("featureId", a.FeatureId),
 			zap.String("autoOpsRuleId", a.Id),
 		)
-		return false, err
+		return nil, err
 	}
 	var lastErr error
 	// All clauses are combined with implicit OR
-	for _, w := range webhookClauses {
+	for id, w := range webhookClauses {
 		if w.WebhookId != tarId {
 			continue
 		}
-		asmt, err := evaluateClause(ctx, w, payload)
-		if err != nil {
-			h.logger.Error("Skipping evaluation because an error has occurred",
-				zap.Error(err),
-				zap.String("featureId", a.FeatureId),
-				zap.String("autoOpsRuleId", a.Id),
-			)
-			lastErr = err
+		if resolved && !w.ReverseOnResolved {
 			continue
 		}
-		if asmt {
-			h.logger.Info("Clause satisfies condition",
-				zap.String("featureId", a.FeatureId),
-				zap.String("autoOpsRuleId", a.Id),
-				zap.Any("webhookClause", w),
-			)
-			return true, lastErr
+		for _, payload := range payloads {
+			asmt, err := evaluateClause(ctx, w, payload)
+			if err != nil {
+				h.logger.Error("Skipping evaluation because an error has occurred",
+					zap.Error(err),
+					zap.String("featureId", a.FeatureId),
+					zap.String("autoOpsRuleId", a.Id),
+				)
+				lastErr = err
+				continue
+			}
+			if asmt {
+				h.logger.Info("Clause satisfies condition",
+					zap.String("featureId", a.FeatureId),
+					zap.String("autoOpsRuleId", a.Id),
+					zap.Any("webhookClause", w),
+					zap.Bool("resolved", resolved),
+				)
+				return &autoopsproto.ExecutionTrigger{
+					ClauseId:       id,
+					WebhookPayload: payloadExcerpt(payload),
+				}, lastErr
+			}
 		}
 	}
-	return false, lastErr
+	return nil, lastErr
+}
+
+// payloadExcerpt returns the JSON of the payload truncated to the max excerpt size.
+func payloadExcerpt(payload interface{}) string {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	if len(b) > maxWebhookPayloadExcerptSize {
+		return string(b[:maxWebhookPayloadExcerptSize]) + "..."
+	}
+	return string(b)
 }
 
 func (h *handler) executeAutoOps(
 	ctx context.Context,
 	rule *autoopsdomain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
 	environmentNamespace string,
 	storage v2as.AutoOpsRuleStorage,
+	executionStorage v2as.AutoOpsExecutionStorage,
 ) error {
 	if rule.AlreadyTriggered() {
 		return errAlreadyTriggered
 	}
+	if rule.Shadow {
+		autoopsapi.LogShadowDecision(ctx, environmentNamespace, rule, trigger, false, h.logger)
+		return nil
+	}
 	handler := command.NewAutoOpsCommandHandler(h.editor, rule, h.publisher, environmentNamespace)
 	if err := handler.Handle(ctx, &autoopsproto.ChangeAutoOpsRuleTriggeredAtCommand{}); err != nil {
 		return err
@@ -315,5 +584,60 @@ func (h *handler) executeAutoOps(
 	if err := storage.UpdateAutoOpsRule(ctx, rule, environmentNamespace); err != nil {
 		return err
 	}
-	return autoopsapi.ExecuteOperation(ctx, environmentNamespace, rule, h.featureClient, h.logger)
+	err := autoopsapi.ExecuteOperation(ctx, environmentNamespace, rule, trigger, h.featureClient, h.logger)
+	if err != nil {
+		return err
+	}
+	autoopsapi.RecordExecution(
+		ctx,
+		environmentNamespace,
+		h.editor,
+		rule,
+		trigger,
+		false,
+		executionStorage,
+		h.featureClient,
+		h.publisher,
+		h.logger,
+	)
+	return nil
+}
+
+// reverseAutoOps reverses the action of the triggered rule and re-arms it
+// so that the rule can be triggered again by the next firing alert.
+func (h *handler) reverseAutoOps(
+	ctx context.Context,
+	rule *autoopsdomain.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+	environmentNamespace string,
+	storage v2as.AutoOpsRuleStorage,
+	executionStorage v2as.AutoOpsExecutionStorage,
+) error {
+	if rule.Shadow {
+		autoopsapi.LogShadowDecision(ctx, environmentNamespace, rule, trigger, true, h.logger)
+		return nil
+	}
+	handler := command.NewAutoOpsCommandHandler(h.editor, rule, h.publisher, environmentNamespace)
+	if err := handler.Handle(ctx, &autoopsproto.RearmAutoOpsRuleCommand{}); err != nil {
+		return err
+	}
+	if err := storage.UpdateAutoOpsRule(ctx, rule, environmentNamespace); err != nil {
+		return err
+	}
+	if err := autoopsapi.ExecuteReverseOperation(ctx, environmentNamespace, rule, h.featureClient, h.logger); err != nil {
+		return err
+	}
+	autoopsapi.RecordExecution(
+		ctx,
+		environmentNamespace,
+		h.editor,
+		rule,
+		trigger,
+		true,
+		executionStorage,
+		h.featureClient,
+		h.publisher,
+		h.logger,
+	)
+	return nil
 }
@@ -17,10 +17,15 @@ package webhookhandler
 import (
 	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/ptypes"
@@ -29,9 +34,11 @@ import (
 
 	authclientmock "github.com/bucketeer-io/bucketeer/pkg/auth/client/mock"
 	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	v2as "github.com/bucketeer-io/bucketeer/pkg/autoops/storage/v2"
 	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
 	"github.com/bucketeer-io/bucketeer/pkg/log"
 	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
 	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
 	"github.com/bucketeer-io/bucketeer/pkg/token"
 	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
@@ -126,6 +133,11 @@ func TestServeHTTP(t *testing.T) {
 		},
 		"success": {
 			setup: func(t *testing.T, h *handler) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				h.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
 				h.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
 				h.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
@@ -157,10 +169,10 @@ func TestHandleWebhook(t *testing.T) {
 	t.Parallel()
 	convert := func(wcs []autoopsproto.WebhookClause) []*autoopsproto.Clause {
 		var clauses []*autoopsproto.Clause
-		for _, w := range wcs {
-			c, err := ptypes.MarshalAny(&w)
+		for i := range wcs {
+			c, err := ptypes.MarshalAny(&wcs[i])
 			require.NoError(t, err)
-			clauses = append(clauses, &autoopsproto.Clause{Clause: c})
+			clauses = append(clauses, &autoopsproto.Clause{Id: fmt.Sprintf("clause-%d", i), Clause: c})
 		}
 		return clauses
 	}
@@ -174,10 +186,60 @@ func TestHandleWebhook(t *testing.T) {
 		name        string
 		webhookId   string
 		payload     string
+		resolved    bool
 		autoOpsRule *autoopsdomain.AutoOpsRule
 		wantErr     bool
 		expected    bool
 	}{
+		{
+			name:      "reverse rule-1 on resolved alert",
+			webhookId: "webhook-1",
+			payload:   `{"status":"resolved","name":"HighErrorRate"}`,
+			resolved:  true,
+			expected:  true,
+			autoOpsRule: &autoopsdomain.AutoOpsRule{
+				AutoOpsRule: &autoopsproto.AutoOpsRule{
+					Id: "rule-1",
+					Clauses: convert([]autoopsproto.WebhookClause{
+						{
+							WebhookId: "webhook-1",
+							Conditions: []*autoopsproto.WebhookClause_Condition{
+								{
+									Filter:   `.name`,
+									Value:    `"HighErrorRate"`,
+									Operator: autoopsproto.WebhookClause_Condition_EQUAL,
+								},
+							},
+							ReverseOnResolved: true,
+						},
+					}),
+				},
+			},
+		},
+		{
+			name:      "reverse nothing because the clause does not reverse on resolved alerts",
+			webhookId: "webhook-1",
+			payload:   `{"status":"resolved","name":"HighErrorRate"}`,
+			resolved:  true,
+			expected:  false,
+			autoOpsRule: &autoopsdomain.AutoOpsRule{
+				AutoOpsRule: &autoopsproto.AutoOpsRule{
+					Id: "rule-1",
+					Clauses: convert([]autoopsproto.WebhookClause{
+						{
+							WebhookId: "webhook-1",
+							Conditions: []*autoopsproto.WebhookClause_Condition{
+								{
+									Filter:   `.name`,
+									Value:    `"HighErrorRate"`,
+									Operator: autoopsproto.WebhookClause_Condition_EQUAL,
+								},
+							},
+						},
+					}),
+				},
+			},
+		},
 		{
 			name:      "1. execute rule-1",
 			webhookId: "webhook-1",
@@ -523,9 +585,14 @@ func TestHandleWebhook(t *testing.T) {
 				ctx,
 				c.autoOpsRule,
 				c.webhookId,
-				payload,
+				[]interface{}{payload},
+				c.resolved,
 			)
-			assert.Equal(t, c.expected, result)
+			assert.Equal(t, c.expected, result != nil)
+			if result != nil {
+				assert.NotEmpty(t, result.ClauseId)
+				assert.JSONEq(t, c.payload, result.WebhookPayload)
+			}
 			assert.Equal(t, c.wantErr, err != nil)
 			if err != nil {
 				t.Log(err)
@@ -560,7 +627,7 @@ func TestAuthWebhook(t *testing.T) {
 	}
 	for msg, p := range testcases {
 		t.Run(msg, func(t *testing.T) {
-			ws := autoopsdomain.NewWebhookSecret(p.id, p.environmentNamespace)
+			ws := autoopsdomain.NewWebhookSecret(p.id, p.environmentNamespace, 1)
 			encoded, err := json.Marshal(ws)
 			require.NoError(t, err)
 			actual, err := h.authWebhook(ctx, base64.RawURLEncoding.EncodeToString(encoded))
@@ -569,3 +636,188 @@ func TestAuthWebhook(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyRequest(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	logger, err := log.NewLogger()
+	require.NoError(t, err)
+	now := time.Now()
+	body := []byte(`{"status":"firing"}`)
+	sign := func(secret string, ts time.Time) (string, string) {
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		mac := computeSignature([]byte(secret), timestamp, body)
+		return timestamp, signatureVersion + "=" + hex.EncodeToString(mac)
+	}
+	newWebhook := func(signatureRequired bool, previousSecretExpiresAt int64) *autoopsdomain.Webhook {
+		return &autoopsdomain.Webhook{
+			Webhook: &autoopsproto.Webhook{
+				Id:                      "webhook-1",
+				SignatureRequired:       signatureRequired,
+				SecretVersion:           1,
+				PreviousSecretExpiresAt: previousSecretExpiresAt,
+			},
+			SigningSecret:         []byte("current"),
+			PreviousSigningSecret: []byte("previous"),
+		}
+	}
+	patterns := []struct {
+		desc          string
+		webhook       *autoopsdomain.Webhook
+		secretVersion int32
+		timestamp     string
+		signature     string
+		signingSecret string
+		expected      error
+	}{
+		{
+			desc:          "success: signature not required",
+			webhook:       newWebhook(false, 0),
+			secretVersion: 1,
+		},
+		{
+			desc:          "success: previous secret version in grace period",
+			webhook:       newWebhook(false, now.Add(time.Hour).Unix()),
+			secretVersion: 0,
+		},
+		{
+			desc:          "err: previous secret version expired",
+			webhook:       newWebhook(false, now.Add(-time.Hour).Unix()),
+			secretVersion: 0,
+			expected:      errSecretExpired,
+		},
+		{
+			desc:          "err: signature empty",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+			expected:      errSignatureEmpty,
+		},
+		{
+			desc:          "err: invalid timestamp",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+			timestamp:     "now",
+			signature:     "v1=00",
+			expected:      errInvalidTimestamp,
+		},
+		{
+			desc:          "err: timestamp out of tolerance",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+			timestamp:     strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10),
+			signature:     "v1=00",
+			expected:      errTimestampOutOfTolerance,
+		},
+		{
+			desc:          "err: unknown signature version",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+			timestamp:     strconv.FormatInt(now.Unix(), 10),
+			signature:     "v0=00",
+			expected:      errSignatureMismatch,
+		},
+		{
+			desc:          "err: signed with another secret",
+			signingSecret: "another",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+			expected:      errSignatureMismatch,
+		},
+		{
+			desc:          "err: signed with the expired previous secret",
+			signingSecret: "previous",
+			webhook:       newWebhook(true, now.Add(-time.Hour).Unix()),
+			secretVersion: 1,
+			expected:      errSignatureMismatch,
+		},
+		{
+			desc:          "success: signed with the current secret",
+			signingSecret: "current",
+			webhook:       newWebhook(true, 0),
+			secretVersion: 1,
+		},
+		{
+			desc:          "success: signed with the previous secret in grace period",
+			signingSecret: "previous",
+			webhook:       newWebhook(true, now.Add(time.Hour).Unix()),
+			secretVersion: 1,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			h := &handler{
+				webhookCryptoUtil:  &dummyWebhookCryptoUtil{},
+				signatureTolerance: defaultSignatureTolerance,
+				logger:             logger,
+			}
+			header := http.Header{}
+			timestamp, signature := p.timestamp, p.signature
+			if p.signingSecret != "" {
+				timestamp, signature = sign(p.signingSecret, now)
+			}
+			if timestamp != "" {
+				header.Set(headerKeyTimestamp, timestamp)
+				header.Set(headerKeySignature, signature)
+			}
+			ws := autoopsdomain.NewWebhookSecret(p.webhook.Id, "ns-1", p.secretVersion)
+			signature, err := h.verifyRequest(ctx, p.webhook, ws, header, body, now)
+			assert.Equal(t, p.expected, err)
+			assert.Equal(t, p.expected == nil && p.webhook.SignatureRequired, signature != "")
+		})
+	}
+}
+
+func TestUseSignatureReplayed(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	logger, err := log.NewLogger()
+	require.NoError(t, err)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mysqlClient := mysqlmock.NewMockClient(mockController)
+	now := time.Now()
+	webhook := &autoopsdomain.Webhook{
+		Webhook: &autoopsproto.Webhook{
+			Id:                "webhook-1",
+			SignatureRequired: true,
+		},
+		SigningSecret: []byte("current"),
+	}
+	body := []byte(`{"status":"firing"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hex.EncodeToString(computeSignature(webhook.SigningSecret, timestamp, body))
+	// The signature is stored in MySQL, so the replay is rejected by any instance.
+	// The same MAC in upper case is stored as the same signature.
+	gomock.InOrder(
+		mysqlClient.EXPECT().ExecContext(
+			gomock.Any(), gomock.Any(), "webhook-1", now.Add(-2*defaultSignatureTolerance).Unix(), "ns-1",
+		).Return(nil, nil),
+		mysqlClient.EXPECT().ExecContext(
+			gomock.Any(), gomock.Any(), "webhook-1", mac, now.Unix(), "ns-1",
+		).Return(nil, nil),
+		mysqlClient.EXPECT().ExecContext(
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(nil, nil),
+		mysqlClient.EXPECT().ExecContext(
+			gomock.Any(), gomock.Any(), "webhook-1", mac, now.Unix(), "ns-1",
+		).Return(nil, mysql.ErrDuplicateEntry),
+	)
+	h := &handler{
+		mysqlClient:        mysqlClient,
+		webhookCryptoUtil:  &dummyWebhookCryptoUtil{},
+		signatureTolerance: defaultSignatureTolerance,
+		logger:             logger,
+	}
+	header := http.Header{}
+	header.Set(headerKeyTimestamp, timestamp)
+	header.Set(headerKeySignature, signatureVersion+"="+mac)
+	ws := autoopsdomain.NewWebhookSecret(webhook.Id, "ns-1", 0)
+	storage := v2as.NewWebhookSignatureStorage(mysqlClient)
+	signature, err := h.verifyRequest(ctx, webhook, ws, header, body, now)
+	require.NoError(t, err)
+	assert.NoError(t, h.useSignature(ctx, storage, webhook.Id, signature, "ns-1", now))
+	header.Set(headerKeySignature, signatureVersion+"="+strings.ToUpper(mac))
+	signature, err = h.verifyRequest(ctx, webhook, ws, header, body, now)
+	require.NoError(t, err)
+	assert.Equal(t, errSignatureReplayed, h.useSignature(ctx, storage, webhook.Id, signature, "ns-1", now))
+}
@@ -77,6 +77,41 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "feature flagの説明文を変更しました",
 		}
+	case proto.Event_FEATURE_KIND_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagの種類を変更しました",
+		}
+	case proto.Event_FEATURE_EXPECTED_REMOVAL_DATE_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagの削除予定日を変更しました",
+		}
+	case proto.Event_FEATURE_LAYER_ASSIGNMENT_SET:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagをlayerに割り当てました",
+		}
+	case proto.Event_FEATURE_LAYER_ASSIGNMENT_REMOVED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagのlayerの割り当てを解除しました",
+		}
+	case proto.Event_FEATURE_EXPERIMENT_ALLOCATION_SET:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagにexperimentの対象ユーザーを設定しました",
+		}
+	case proto.Event_FEATURE_EXPERIMENT_ALLOCATION_REMOVED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagのexperimentの対象ユーザーの設定を解除しました",
+		}
+	case proto.Event_FEATURE_BANDIT_WEIGHTS_UPDATED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "feature flagのbanditのweightを更新しました",
+		}
 	case proto.Event_FEATURE_VARIATION_ADDED:
 		return &proto.LocalizedMessage{
 			Locale:  locale.JaJP,
@@ -277,6 +312,21 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "experimentをアーカイブしました",
 		}
+	case proto.Event_EXPERIMENT_GUARDRAIL_BREACHED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "guardrailの閾値を超えたためexperimentを停止しました",
+		}
+	case proto.Event_EXPERIMENT_SAMPLE_SIZE_ESTIMATED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "experimentのサンプルサイズを見積もりました",
+		}
+	case proto.Event_EXPERIMENT_DEFAULT_VARIATION_APPLIED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "experimentの結果をfeatureのデフォルトvariationに反映しました",
+		}
 	case proto.Event_EXPERIMENT_DELETED:
 		return &proto.LocalizedMessage{
 			Locale:  locale.JaJP,
@@ -482,6 +532,26 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "自動オペレーションの実行時間が変更されました",
 		}
+	case proto.Event_AUTOOPS_RULE_TRIGGER_SETTINGS_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションの実行条件の設定が変更されました",
+		}
+	case proto.Event_AUTOOPS_RULE_REARMED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションが再度有効化されました",
+		}
+	case proto.Event_AUTOOPS_RULE_SHADOW_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションのシャドーモードが変更されました",
+		}
+	case proto.Event_AUTOOPS_RULE_EXECUTED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "自動オペレーションが実行されました",
+		}
 	case proto.Event_OPS_EVENT_RATE_CLAUSE_ADDED:
 		return &proto.LocalizedMessage{
 			Locale:  locale.JaJP,
@@ -502,6 +572,26 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "日時ルールが変更されました",
 		}
+	case proto.Event_PROMETHEUS_CLAUSE_ADDED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールが追加されました",
+		}
+	case proto.Event_PROMETHEUS_CLAUSE_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "Prometheusルールが変更されました",
+		}
+	case proto.Event_SCHEDULE_CLAUSE_ADDED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールが追加されました",
+		}
+	case proto.Event_SCHEDULE_CLAUSE_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "スケジュールルールが変更されました",
+		}
 	case proto.Event_PUSH_CREATED:
 		return &proto.LocalizedMessage{
 			Locale:  locale.JaJP,
@@ -647,6 +737,21 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "webhookの説明を変更しました",
 		}
+	case proto.Event_WEBHOOK_PAYLOAD_FORMAT_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "webhookのペイロード形式を変更しました",
+		}
+	case proto.Event_WEBHOOK_SIGNATURE_REQUIRED_CHANGED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "webhookの署名要否を変更しました",
+		}
+	case proto.Event_WEBHOOK_SECRET_ROTATED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "webhookのシークレットをローテーションしました",
+		}
 	case proto.Event_WEBHOOK_CLAUSE_ADDED:
 		return &proto.LocalizedMessage{
 			Locale:  locale.JaJP,
@@ -657,6 +762,11 @@ func LocalizedMessage(eventType proto.Event_Type, loc string) *proto.LocalizedMe
 			Locale:  locale.JaJP,
 			Message: "webhookのルールが変更されました",
 		}
+	case proto.Event_LAYER_CREATED:
+		return &proto.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layerを作成しました",
+		}
 	}
 	return &proto.LocalizedMessage{
 		Locale:  locale.JaJP,
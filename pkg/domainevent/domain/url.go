@@ -31,6 +31,7 @@ const (
 	urlTemplateAutoOpsRule  = "%s/%s/features/%s/settings"
 	urlTemplatePush         = "%s/%s/settings/pushes/%s"
 	urlTemplateSubscription = "%s/%s/settings/notifications/%s"
+	urlTemplateLayer        = "%s/%s/experiments/layers/%s"
 
 	// FIXME: url templates for admin will not require defaultEnvironmentID after environmentID is removed from admin page.
 	urlTemplateAdminSubscription = "%s/%s/admin/notifications/%s"
@@ -75,6 +76,8 @@ func URL(entityType proto.Event_EntityType, url, environmentID, id string) (stri
 		return fmt.Sprintf(urlTemplateProject, url, defaultEnvironmentID, id), nil
 	case proto.Event_WEBHOOK:
 		return fmt.Sprintf(urlTemplateWebhook, url, defaultEnvironmentID, id), nil
+	case proto.Event_LAYER:
+		return fmt.Sprintf(urlTemplateLayer, url, environmentID, id), nil
 	}
 	return "", ErrUnknownEntityType
 }
@@ -27,10 +27,13 @@ import (
 	"google.golang.org/grpc/status"
 
 	accountclient "github.com/bucketeer-io/bucketeer/pkg/account/client"
+	ecdomain "github.com/bucketeer-io/bucketeer/pkg/eventcounter/domain"
 	ecdruid "github.com/bucketeer-io/bucketeer/pkg/eventcounter/druid"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
 	v2ecstorage "github.com/bucketeer-io/bucketeer/pkg/eventcounter/storage/v2"
 	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	featuredomain "github.com/bucketeer-io/bucketeer/pkg/feature/domain"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/log"
 	"github.com/bucketeer-io/bucketeer/pkg/metrics"
@@ -45,7 +48,13 @@ import (
 	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
 )
 
-const listRequestPageSize = 500
+const (
+	listRequestPageSize = 500
+	// The breakdown compares every variation once per segment,
+	// so the number of segments is limited to keep the results meaningful after correction.
+	defaultBreakdownSegments = 10
+	maxBreakdownSegments     = 50
+)
 
 var (
 	jpLocation = time.FixedZone("Asia/Tokyo", 9*60*60)
@@ -99,6 +108,13 @@ func (s *eventCounterService) GetEvaluationCountV2(
 	}
 	startAt := time.Unix(req.StartAt, 0)
 	endAt := time.Unix(req.EndAt, 0)
+	filters := []*ecproto.Filter{}
+	if req.ExperimentExposuresOnly {
+		filters = experimentExposureFilters()
+	}
+	if req.RuleId != "" {
+		filters = append(filters, ruleFilter(req.RuleId))
+	}
 	headers, rows, err := s.druidQuerier.QueryEvaluationCount(
 		ctx,
 		req.EnvironmentNamespace,
@@ -106,8 +122,8 @@ func (s *eventCounterService) GetEvaluationCountV2(
 		endAt,
 		req.FeatureId,
 		req.FeatureVersion,
-		"",
-		[]string{}, []*ecproto.Filter{},
+		req.Reason,
+		[]string{}, filters,
 	)
 	if err != nil {
 		s.logger.Error(
@@ -290,8 +306,8 @@ func (s *eventCounterService) GetExperimentResult(
 	if err != nil {
 		return nil, err
 	}
-	if req.ExperimentId == "" {
-		return nil, localizedError(statusExperimentIDRequired, locale.JaJP)
+	if err := validateGetExperimentResultRequest(req); err != nil {
+		return nil, err
 	}
 	result, err := s.mysqlExperimentResultStorage.GetExperimentResult(ctx, req.ExperimentId, req.EnvironmentNamespace)
 	if err != nil {
@@ -308,11 +324,435 @@ func (s *eventCounterService) GetExperimentResult(
 		)
 		return nil, localizedError(statusInternal, locale.JaJP)
 	}
+	resp, err := s.experimentClient.GetExperiment(ctx, &experimentproto.GetExperimentRequest{
+		Id:                   req.ExperimentId,
+		EnvironmentNamespace: req.EnvironmentNamespace,
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to get experiment",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("experimentId", req.ExperimentId),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	confidenceLevel := req.ConfidenceLevel
+	if confidenceLevel == 0 {
+		confidenceLevel = stats.DefaultConfidenceLevel
+	}
+	result.SetFrequentistSummaries(resp.Experiment.BaseVariationId, confidenceLevel)
+	if resp.Experiment.VarianceReduction.GetMethod() == experimentproto.VarianceReduction_CUPED {
+		s.setCupedSummaries(ctx, req.EnvironmentNamespace, result, resp.Experiment, confidenceLevel)
+	}
 	return &ecproto.GetExperimentResultResponse{
 		ExperimentResult: result.ExperimentResult,
 	}, nil
 }
 
+// setCupedSummaries adjusts the goal values of the experiment by the goal values
+// of the same users before the experiment started.
+// The unadjusted results are still returned when the goal values can't be queried.
+func (s *eventCounterService) setCupedSummaries(
+	ctx context.Context,
+	environmentNamespace string,
+	result *ecdomain.ExperimentResult,
+	experiment *experimentproto.Experiment,
+	confidenceLevel float64,
+) {
+	startAt := time.Unix(experiment.StartAt, 0)
+	endAt := time.Unix(experiment.StopAt, 0)
+	if now := time.Now(); endAt.After(now) {
+		endAt = now
+	}
+	preStartAt := startAt.AddDate(0, 0, -int(experiment.VarianceReduction.PrePeriodDays))
+	variationIDs := make([]string, 0, len(experiment.Variations))
+	for _, v := range experiment.Variations {
+		variationIDs = append(variationIDs, v.Id)
+	}
+	for _, gr := range result.GoalResults {
+		headers, rows, err := s.druidQuerier.QueryGoalValueCovariates(
+			ctx,
+			environmentNamespace,
+			preStartAt,
+			startAt,
+			endAt,
+			gr.GoalId,
+			experiment.FeatureId,
+			experiment.FeatureVersion,
+			variationIDs,
+			experimentExposureFilters(),
+		)
+		if err != nil {
+			s.logger.Warn(
+				"Failed to query goal value covariates",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", environmentNamespace),
+					zap.String("experimentId", experiment.Id),
+					zap.String("goalId", gr.GoalId),
+				)...,
+			)
+			continue
+		}
+		samples, err := convToCovariateSamples(headers, rows)
+		if err != nil {
+			continue
+		}
+		result.SetCupedSummaries(gr.GoalId, experiment.BaseVariationId, samples, confidenceLevel)
+	}
+}
+
+// convToCovariateSamples summarizes the goal values of the users in each variation
+// and their goal values before the experiment by the sums of them.
+func convToCovariateSamples(headers *ecproto.Row, rows []*ecproto.Row) (map[string]*stats.CovariateSample, error) {
+	idx := map[string]int{}
+	for i, cell := range headers.Cells {
+		idx[cell.Value] = i
+	}
+	columns := []string{
+		ecdruid.ColumnVariation,
+		ecdruid.ColumnGoalUser,
+		ecdruid.ColumnGoalValueTotal,
+		ecdruid.ColumnGoalValueSquareTotal,
+		ecdruid.ColumnCovariateTotal,
+		ecdruid.ColumnCovariateSquareTotal,
+		ecdruid.ColumnGoalValueCovariateTotal,
+	}
+	for _, c := range columns {
+		if _, ok := idx[c]; !ok {
+			return nil, errors.New("eventcounter: goal value covariate header not found")
+		}
+	}
+	samples := make(map[string]*stats.CovariateSample, len(rows))
+	for _, row := range rows {
+		value := func(column string) float64 {
+			return row.Cells[idx[column]].ValueDouble
+		}
+		sample, err := stats.NewCovariateSample(&stats.CovariateSums{
+			Size:               int64(value(ecdruid.ColumnGoalUser)),
+			Sum:                value(ecdruid.ColumnGoalValueTotal),
+			SquareSum:          value(ecdruid.ColumnGoalValueSquareTotal),
+			CovariateSum:       value(ecdruid.ColumnCovariateTotal),
+			CovariateSquareSum: value(ecdruid.ColumnCovariateSquareTotal),
+			ProductSum:         value(ecdruid.ColumnGoalValueCovariateTotal),
+		})
+		if err != nil {
+			continue
+		}
+		samples[row.Cells[idx[ecdruid.ColumnVariation]].Value] = sample
+	}
+	return samples, nil
+}
+
+func validateGetExperimentResultRequest(req *ecproto.GetExperimentResultRequest) error {
+	if req.ExperimentId == "" {
+		return localizedError(statusExperimentIDRequired, locale.JaJP)
+	}
+	if req.ConfidenceLevel < 0 || req.ConfidenceLevel >= 1 {
+		return localizedError(statusInvalidConfidenceLevel, locale.JaJP)
+	}
+	return nil
+}
+
+func (s *eventCounterService) GetExperimentResultBreakdown(
+	ctx context.Context,
+	req *ecproto.GetExperimentResultBreakdownRequest,
+) (*ecproto.GetExperimentResultBreakdownResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGetExperimentResultBreakdownRequest(req); err != nil {
+		return nil, err
+	}
+	resp, err := s.experimentClient.GetExperiment(ctx, &experimentproto.GetExperimentRequest{
+		Id:                   req.ExperimentId,
+		EnvironmentNamespace: req.EnvironmentNamespace,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, localizedError(statusNotFound, locale.JaJP)
+		}
+		s.logger.Error(
+			"Failed to get experiment",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("experimentId", req.ExperimentId),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	experiment := resp.Experiment
+	// Only the user data written by the event persister can be grouped by.
+	attributes, err := s.druidQuerier.QuerySegmentMetadata(
+		ctx,
+		req.EnvironmentNamespace,
+		ecdruid.DataTypeEvaluationEvents,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to query segment metadata",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if !containsString(attributes, req.Attribute) {
+		return nil, localizedError(statusAttributeNotFound, locale.JaJP)
+	}
+	startAt := time.Unix(experiment.StartAt, 0)
+	endAt := time.Unix(experiment.StopAt, 0)
+	if now := time.Now(); endAt.After(now) {
+		endAt = now
+	}
+	maxSegments := int(req.MaxSegments)
+	if maxSegments == 0 {
+		maxSegments = defaultBreakdownSegments
+	}
+	// One more value is queried to know whether the segments are truncated.
+	values, err := s.druidQuerier.QueryEvaluationSegmentValues(
+		ctx,
+		req.EnvironmentNamespace,
+		startAt,
+		endAt,
+		experiment.FeatureId,
+		experiment.FeatureVersion,
+		req.Attribute,
+		experimentExposureFilters(),
+		maxSegments+1,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to query evaluation segment values",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("experimentId", req.ExperimentId),
+				zap.String("attribute", req.Attribute),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	breakdown := &ecdomain.ExperimentResultBreakdown{
+		ExperimentResultBreakdown: &ecproto.ExperimentResultBreakdown{
+			ExperimentId: req.ExperimentId,
+			GoalId:       req.GoalId,
+			Attribute:    req.Attribute,
+			Segments:     []*ecproto.SegmentResult{},
+		},
+	}
+	if len(values) > maxSegments {
+		values = values[:maxSegments]
+		breakdown.Truncated = true
+	}
+	if len(values) == 0 {
+		return &ecproto.GetExperimentResultBreakdownResponse{
+			Breakdown: breakdown.ExperimentResultBreakdown,
+		}, nil
+	}
+	// The counts are only grouped by the values with the most evaluated users,
+	// so that an attribute with a high cardinality doesn't make the queries explode.
+	segments := []string{req.Attribute}
+	filters := append(experimentExposureFilters(), &ecproto.Filter{
+		Key:      req.Attribute,
+		Operator: ecproto.Filter_IN,
+		Values:   values,
+	})
+	evalHeaders, evalRows, err := s.druidQuerier.QueryEvaluationCount(
+		ctx,
+		req.EnvironmentNamespace,
+		startAt,
+		endAt,
+		experiment.FeatureId,
+		experiment.FeatureVersion,
+		"",
+		segments,
+		filters,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to query evaluation counts",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("experimentId", req.ExperimentId),
+				zap.String("attribute", req.Attribute),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	goalHeaders, goalRows, err := s.druidQuerier.QueryGoalCount(
+		ctx,
+		req.EnvironmentNamespace,
+		startAt,
+		endAt,
+		req.GoalId,
+		experiment.FeatureId,
+		experiment.FeatureVersion,
+		"",
+		segments,
+		filters,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to query goal counts",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("experimentId", req.ExperimentId),
+				zap.String("goalId", req.GoalId),
+				zap.String("attribute", req.Attribute),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	variationIDs := make([]string, 0, len(experiment.Variations))
+	for _, v := range experiment.Variations {
+		variationIDs = append(variationIDs, v.Id)
+	}
+	segmentResults, err := convToSegmentResults(
+		req.Attribute,
+		evalHeaders, evalRows,
+		goalHeaders, goalRows,
+		variationIDs,
+	)
+	if err != nil {
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	breakdown.Segments = segmentResults
+	confidenceLevel := req.ConfidenceLevel
+	if confidenceLevel == 0 {
+		confidenceLevel = stats.DefaultConfidenceLevel
+	}
+	breakdown.SetFrequentistSummaries(experiment.BaseVariationId, confidenceLevel)
+	return &ecproto.GetExperimentResultBreakdownResponse{
+		Breakdown: breakdown.ExperimentResultBreakdown,
+	}, nil
+}
+
+// experimentExposureFilters leave out the evaluations and the goals of users who are not in the experiment.
+// They are only used for the experiment results, since the other counts of the feature include every user.
+func experimentExposureFilters() []*ecproto.Filter {
+	values := make([]string, 0, len(featuredomain.NonExposureReasons))
+	for _, r := range featuredomain.NonExposureReasons {
+		values = append(values, r.String())
+	}
+	return []*ecproto.Filter{{
+		Key:      "reason",
+		Operator: ecproto.Filter_NOT_EQUALS,
+		Values:   values,
+	}}
+}
+
+// ruleFilter leaves out the evaluations and the goals of users who are not served by the rule.
+func ruleFilter(ruleID string) *ecproto.Filter {
+	return &ecproto.Filter{
+		Key:      "ruleId",
+		Operator: ecproto.Filter_EQUALS,
+		Values:   []string{ruleID},
+	}
+}
+
+func validateGetExperimentResultBreakdownRequest(req *ecproto.GetExperimentResultBreakdownRequest) error {
+	if req.ExperimentId == "" {
+		return localizedError(statusExperimentIDRequired, locale.JaJP)
+	}
+	if req.GoalId == "" {
+		return localizedError(statusGoalIDRequired, locale.JaJP)
+	}
+	if req.Attribute == "" {
+		return localizedError(statusAttributeRequired, locale.JaJP)
+	}
+	if req.ConfidenceLevel < 0 || req.ConfidenceLevel >= 1 {
+		return localizedError(statusInvalidConfidenceLevel, locale.JaJP)
+	}
+	if req.MaxSegments < 0 || req.MaxSegments > maxBreakdownSegments {
+		return localizedError(statusInvalidMaxSegments, locale.JaJP)
+	}
+	return nil
+}
+
+// convToSegmentResults joins the evaluation counts and the goal counts of each attribute value.
+// Values without evaluations are dropped since they can't be compared.
+func convToSegmentResults(
+	attribute string,
+	evalHeaders *ecproto.Row,
+	evalRows []*ecproto.Row,
+	goalHeaders *ecproto.Row,
+	goalRows []*ecproto.Row,
+	variationIDs []string,
+) ([]*ecproto.SegmentResult, error) {
+	evalRowsByValue, err := groupRowsBySegment(evalHeaders, evalRows, attribute)
+	if err != nil {
+		return nil, err
+	}
+	goalRowsByValue, err := groupRowsBySegment(goalHeaders, goalRows, attribute)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(evalRowsByValue))
+	for v := range evalRowsByValue {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	results := make([]*ecproto.SegmentResult, 0, len(values))
+	for _, v := range values {
+		evalCounts, err := convToVariationCounts(evalHeaders, evalRowsByValue[v], variationIDs)
+		if err != nil {
+			return nil, err
+		}
+		goalCounts, err := convToVariationCounts(goalHeaders, goalRowsByValue[v], variationIDs)
+		if err != nil {
+			return nil, err
+		}
+		// Both counts are sorted by variation ID.
+		vrs := make([]*ecproto.VariationResult, 0, len(evalCounts))
+		for i := range evalCounts {
+			vrs = append(vrs, &ecproto.VariationResult{
+				VariationId:     evalCounts[i].VariationId,
+				EvaluationCount: evalCounts[i],
+				ExperimentCount: goalCounts[i],
+			})
+		}
+		results = append(results, &ecproto.SegmentResult{Value: v, VariationResults: vrs})
+	}
+	return results, nil
+}
+
+func groupRowsBySegment(headers *ecproto.Row, rows []*ecproto.Row, segment string) (map[string][]*ecproto.Row, error) {
+	idx := -1
+	for i, cell := range headers.Cells {
+		if cell.Value == segment {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, errors.New("eventcounter: segment header not found")
+	}
+	grouped := map[string][]*ecproto.Row{}
+	for _, row := range rows {
+		value := row.Cells[idx].Value
+		grouped[value] = append(grouped[value], row)
+	}
+	return grouped, nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, h := range haystack {
+		if h == needle {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *eventCounterService) ListExperimentResults(
 	ctx context.Context,
 	req *ecproto.ListExperimentResultsRequest,
@@ -471,6 +911,13 @@ func (s *eventCounterService) GetGoalCountV2(
 	}
 	startAt := time.Unix(req.StartAt, 0)
 	endAt := time.Unix(req.EndAt, 0)
+	filters := []*ecproto.Filter{}
+	if req.ExperimentExposuresOnly {
+		filters = experimentExposureFilters()
+	}
+	if req.RuleId != "" {
+		filters = append(filters, ruleFilter(req.RuleId))
+	}
 	headers, rows, err := s.druidQuerier.QueryGoalCount(
 		ctx,
 		req.EnvironmentNamespace,
@@ -479,8 +926,8 @@ func (s *eventCounterService) GetGoalCountV2(
 		req.GoalId,
 		req.FeatureId,
 		req.FeatureVersion,
-		"",
-		[]string{}, []*ecproto.Filter{},
+		req.Reason,
+		[]string{}, filters,
 	)
 	if err != nil {
 		s.logger.Error(
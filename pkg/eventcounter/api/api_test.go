@@ -46,6 +46,12 @@ import (
 	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
 )
 
+var exposureFilters = []*ecproto.Filter{{
+	Key:      "reason",
+	Operator: ecproto.Filter_NOT_EQUALS,
+	Values:   []string{"LAYER_HOLDOUT", "LAYER_EXCLUDED", "EXPERIMENT_NOT_ALLOCATED"},
+}}
+
 func TestNewEventCounterService(t *testing.T) {
 	metrics := metrics.NewMetrics(
 		9999,
@@ -101,7 +107,7 @@ func TestGetEvaluationCountV2(t *testing.T) {
 		},
 		"success: one variation": {
 			setup: func(s *eventCounterService) {
-				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "DEFAULT", gomock.Any(), []*ecproto.Filter{}).Return(
 					&ecproto.Row{Cells: []*ecproto.Cell{
 						{Value: ecdruid.ColumnVariation},
 						{Value: ecdruid.ColumnEvaluationUser},
@@ -128,6 +134,7 @@ func TestGetEvaluationCountV2(t *testing.T) {
 				FeatureId:            "fid",
 				FeatureVersion:       int32(1),
 				VariationIds:         []string{"vid1"},
+				Reason:               "DEFAULT",
 			},
 			expected: &ecproto.GetEvaluationCountV2Response{
 				Count: &ecproto.EvaluationCount{
@@ -144,9 +151,9 @@ func TestGetEvaluationCountV2(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
-		"success: all variations": {
+		"success: all variations of experiment exposures": {
 			setup: func(s *eventCounterService) {
-				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), exposureFilters).Return(
 					&ecproto.Row{Cells: []*ecproto.Cell{
 						{Value: ecdruid.ColumnVariation},
 						{Value: ecdruid.ColumnEvaluationUser},
@@ -167,12 +174,13 @@ func TestGetEvaluationCountV2(t *testing.T) {
 					nil)
 			},
 			input: &ecproto.GetEvaluationCountV2Request{
-				EnvironmentNamespace: "ns0",
-				StartAt:              now.Add(-30 * 24 * time.Hour).Unix(),
-				EndAt:                now.Unix(),
-				FeatureId:            "fid",
-				FeatureVersion:       int32(1),
-				VariationIds:         []string{"vid0", "vid1"},
+				EnvironmentNamespace:    "ns0",
+				StartAt:                 now.Add(-30 * 24 * time.Hour).Unix(),
+				EndAt:                   now.Unix(),
+				FeatureId:               "fid",
+				FeatureVersion:          int32(1),
+				VariationIds:            []string{"vid0", "vid1"},
+				ExperimentExposuresOnly: true,
 			},
 			expected: &ecproto.GetEvaluationCountV2Response{
 				Count: &ecproto.EvaluationCount{
@@ -289,11 +297,68 @@ func TestGetExperimentResultMySQL(t *testing.T) {
 			},
 			expectedErr: localizedError(statusNotFound, locale.JaJP),
 		},
+		"error: ErrInvalidConfidenceLevel": {
+			input: &ecproto.GetExperimentResultRequest{
+				ExperimentId:         "eid",
+				EnvironmentNamespace: "ns0",
+				ConfidenceLevel:      1,
+			},
+			expectedErr: localizedError(statusInvalidConfidenceLevel, locale.JaJP),
+		},
+		"err: failed to get experiment": {
+			setup: func(s *eventCounterService) {
+				s.mysqlExperimentResultStorage.(*v2ecsmock.MockExperimentResultStorage).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(&domain.ExperimentResult{ExperimentResult: &ecproto.ExperimentResult{}}, nil)
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			input: &ecproto.GetExperimentResultRequest{
+				ExperimentId:         "eid",
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: localizedError(statusInternal, locale.JaJP),
+		},
 		"success: get the result from storage": {
 			setup: func(s *eventCounterService) {
 				s.mysqlExperimentResultStorage.(*v2ecsmock.MockExperimentResultStorage).EXPECT().GetExperimentResult(
 					gomock.Any(), gomock.Any(), gomock.Any(),
-				).Return(&domain.ExperimentResult{}, nil)
+				).Return(&domain.ExperimentResult{ExperimentResult: &ecproto.ExperimentResult{}}, nil)
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(&experimentproto.GetExperimentResponse{
+					Experiment: &experimentproto.Experiment{BaseVariationId: "vid"},
+				}, nil)
+			},
+			input: &ecproto.GetExperimentResultRequest{
+				ExperimentId:         "eid",
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: nil,
+		},
+		"success: the result is returned without CUPED when the goal values can't be queried": {
+			setup: func(s *eventCounterService) {
+				s.mysqlExperimentResultStorage.(*v2ecsmock.MockExperimentResultStorage).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(&domain.ExperimentResult{ExperimentResult: &ecproto.ExperimentResult{
+					GoalResults: []*ecproto.GoalResult{{GoalId: "gid"}},
+				}}, nil)
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(&experimentproto.GetExperimentResponse{
+					Experiment: &experimentproto.Experiment{
+						BaseVariationId: "vid",
+						VarianceReduction: &experimentproto.VarianceReduction{
+							Method:        experimentproto.VarianceReduction_CUPED,
+							PrePeriodDays: 14,
+						},
+					},
+				}, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalValueCovariates(
+					gomock.Any(), "ns0", gomock.Any(), gomock.Any(), gomock.Any(), "gid", gomock.Any(), gomock.Any(),
+					gomock.Any(), exposureFilters,
+				).Return(nil, nil, errors.New("error"))
 			},
 			input: &ecproto.GetExperimentResultRequest{
 				ExperimentId:         "eid",
@@ -315,6 +380,242 @@ func TestGetExperimentResultMySQL(t *testing.T) {
 	}
 }
 
+func TestConvToCovariateSamples(t *testing.T) {
+	t.Parallel()
+	headers := &ecproto.Row{Cells: []*ecproto.Cell{
+		{Value: ecdruid.ColumnVariation},
+		{Value: ecdruid.ColumnGoalUser},
+		{Value: ecdruid.ColumnGoalValueTotal},
+		{Value: ecdruid.ColumnGoalValueSquareTotal},
+		{Value: ecdruid.ColumnCovariateTotal},
+		{Value: ecdruid.ColumnCovariateSquareTotal},
+		{Value: ecdruid.ColumnGoalValueCovariateTotal},
+	}}
+	row := func(variation string, values ...float64) *ecproto.Row {
+		cells := []*ecproto.Cell{{Value: variation}}
+		for _, v := range values {
+			cells = append(cells, &ecproto.Cell{Type: ecproto.Cell_DOUBLE, ValueDouble: v})
+		}
+		return &ecproto.Row{Cells: cells}
+	}
+
+	_, err := convToCovariateSamples(&ecproto.Row{Cells: headers.Cells[:2]}, nil)
+	assert.Error(t, err)
+
+	samples, err := convToCovariateSamples(
+		headers,
+		[]*ecproto.Row{
+			// The values are 2, 4 and the covariates are 1, 0.
+			row("vid-0", 2, 6, 20, 1, 1, 2),
+			// The values are 6, 8 and the covariates are 3, 5.
+			row("vid-1", 2, 14, 100, 8, 34, 58),
+			// The sample is too small to summarize.
+			row("vid-2", 1, 10, 100, 0, 0, 0),
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, int64(2), samples["vid-0"].Size)
+	assert.InDelta(t, 3, samples["vid-0"].Mean, 1e-9)
+	assert.InDelta(t, 0.5, samples["vid-0"].CovariateMean, 1e-9)
+	assert.InDelta(t, 7, samples["vid-1"].Mean, 1e-9)
+	assert.InDelta(t, 4, samples["vid-1"].CovariateMean, 1e-9)
+	assert.InDelta(t, 2, samples["vid-1"].Covariance, 1e-9)
+}
+
+func TestGetExperimentResultBreakdown(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	attribute := "user.data.platform"
+	experiment := &experimentproto.GetExperimentResponse{
+		Experiment: &experimentproto.Experiment{
+			Id:              "eid",
+			FeatureId:       "fid",
+			FeatureVersion:  1,
+			BaseVariationId: "vid0",
+			Variations:      []*featureproto.Variation{{Id: "vid0"}, {Id: "vid1"}},
+			StartAt:         time.Now().Add(-24 * time.Hour).Unix(),
+			StopAt:          time.Now().Add(24 * time.Hour).Unix(),
+		},
+	}
+	evalHeaders := &ecproto.Row{Cells: []*ecproto.Cell{
+		{Value: attribute},
+		{Value: ecdruid.ColumnVariation},
+		{Value: ecdruid.ColumnEvaluationUser},
+		{Value: ecdruid.ColumnEvaluationTotal},
+	}}
+	goalHeaders := &ecproto.Row{Cells: []*ecproto.Cell{
+		{Value: attribute},
+		{Value: ecdruid.ColumnVariation},
+		{Value: ecdruid.ColumnGoalUser},
+		{Value: ecdruid.ColumnGoalTotal},
+	}}
+	newRow := func(value, vid string, users, total float64) *ecproto.Row {
+		return &ecproto.Row{Cells: []*ecproto.Cell{
+			{Value: value, Type: ecproto.Cell_STRING},
+			{Value: vid, Type: ecproto.Cell_STRING},
+			{ValueDouble: users, Type: ecproto.Cell_DOUBLE},
+			{ValueDouble: total, Type: ecproto.Cell_DOUBLE},
+		}}
+	}
+	patterns := map[string]struct {
+		setup       func(*eventCounterService)
+		input       *ecproto.GetExperimentResultBreakdownRequest
+		expected    func(*testing.T, *ecproto.GetExperimentResultBreakdownResponse)
+		expectedErr error
+	}{
+		"error: ErrExperimentIDRequired": {
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				GoalId:               "gid",
+				Attribute:            attribute,
+			},
+			expectedErr: localizedError(statusExperimentIDRequired, locale.JaJP),
+		},
+		"error: ErrGoalIDRequired": {
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				Attribute:            attribute,
+			},
+			expectedErr: localizedError(statusGoalIDRequired, locale.JaJP),
+		},
+		"error: ErrAttributeRequired": {
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				GoalId:               "gid",
+			},
+			expectedErr: localizedError(statusAttributeRequired, locale.JaJP),
+		},
+		"error: ErrInvalidMaxSegments": {
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				GoalId:               "gid",
+				Attribute:            attribute,
+				MaxSegments:          maxBreakdownSegments + 1,
+			},
+			expectedErr: localizedError(statusInvalidMaxSegments, locale.JaJP),
+		},
+		"error: ErrAttributeNotFound": {
+			setup: func(s *eventCounterService) {
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(experiment, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QuerySegmentMetadata(
+					gomock.Any(), "ns0", ecdruid.DataTypeEvaluationEvents,
+				).Return([]string{"user.data.country"}, nil)
+			},
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				GoalId:               "gid",
+				Attribute:            attribute,
+			},
+			expectedErr: localizedError(statusAttributeNotFound, locale.JaJP),
+		},
+		"success": {
+			setup: func(s *eventCounterService) {
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(experiment, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QuerySegmentMetadata(
+					gomock.Any(), "ns0", ecdruid.DataTypeEvaluationEvents,
+				).Return([]string{"user.data.country", attribute}, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationSegmentValues(
+					gomock.Any(), "ns0", gomock.Any(), gomock.Any(), "fid", int32(1), attribute, exposureFilters, 3,
+				).Return([]string{"android", "ios", "web"}, nil)
+				filters := append(exposureFilters, &ecproto.Filter{
+					Key:      attribute,
+					Operator: ecproto.Filter_IN,
+					Values:   []string{"android", "ios"},
+				})
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationCount(
+					gomock.Any(), "ns0", gomock.Any(), gomock.Any(), "fid", int32(1), "",
+					[]string{attribute}, filters,
+				).Return(evalHeaders, []*ecproto.Row{
+					newRow("android", "vid0", 2000, 3000),
+					newRow("android", "vid1", 2000, 3000),
+					newRow("ios", "vid0", 1000, 1500),
+					newRow("ios", "vid1", 1000, 1500),
+				}, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalCount(
+					gomock.Any(), "ns0", gomock.Any(), gomock.Any(), "gid", "fid", int32(1), "",
+					[]string{attribute}, filters,
+				).Return(goalHeaders, []*ecproto.Row{
+					newRow("android", "vid0", 200, 300),
+					newRow("android", "vid1", 210, 300),
+					newRow("ios", "vid0", 100, 150),
+					newRow("ios", "vid1", 130, 150),
+				}, nil)
+			},
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				GoalId:               "gid",
+				Attribute:            attribute,
+				MaxSegments:          2,
+			},
+			expected: func(t *testing.T, resp *ecproto.GetExperimentResultBreakdownResponse) {
+				breakdown := resp.Breakdown
+				assert.Equal(t, attribute, breakdown.Attribute)
+				assert.True(t, breakdown.Truncated)
+				assert.Len(t, breakdown.Segments, 2)
+				assert.Equal(t, "android", breakdown.Segments[0].Value)
+				assert.Equal(t, "ios", breakdown.Segments[1].Value)
+				ios := breakdown.Segments[1].VariationResults[1]
+				assert.Equal(t, "vid1", ios.VariationId)
+				assert.Equal(t, int64(1000), ios.EvaluationCount.UserCount)
+				assert.Equal(t, int64(130), ios.ExperimentCount.UserCount)
+				assert.Greater(t, ios.CvrFrequentist.AdjustedPValue, ios.CvrFrequentist.PValue)
+			},
+			expectedErr: nil,
+		},
+		"success: no evaluations": {
+			setup: func(s *eventCounterService) {
+				s.experimentClient.(*experimentclientmock.MockClient).EXPECT().GetExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(experiment, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QuerySegmentMetadata(
+					gomock.Any(), "ns0", ecdruid.DataTypeEvaluationEvents,
+				).Return([]string{attribute}, nil)
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryEvaluationSegmentValues(
+					gomock.Any(), "ns0", gomock.Any(), gomock.Any(), "fid", int32(1), attribute, exposureFilters,
+					defaultBreakdownSegments+1,
+				).Return([]string{}, nil)
+			},
+			input: &ecproto.GetExperimentResultBreakdownRequest{
+				EnvironmentNamespace: "ns0",
+				ExperimentId:         "eid",
+				GoalId:               "gid",
+				Attribute:            attribute,
+			},
+			expected: func(t *testing.T, resp *ecproto.GetExperimentResultBreakdownResponse) {
+				assert.False(t, resp.Breakdown.Truncated)
+				assert.Empty(t, resp.Breakdown.Segments)
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			s := newEventCounterService(t, mockController)
+			if p.setup != nil {
+				p.setup(s)
+			}
+			ctx := createContextWithToken(t, accountproto.Account_UNASSIGNED)
+			actual, err := s.GetExperimentResultBreakdown(ctx, p.input)
+			assert.Equal(t, p.expectedErr, err)
+			if p.expected != nil {
+				p.expected(t, actual)
+			}
+		})
+	}
+}
+
 func TestListExperimentResultsMySQL(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -576,7 +877,9 @@ func TestGetGoalCountV2(t *testing.T) {
 		},
 		"success: one variation": {
 			setup: func(s *eventCounterService) {
-				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "RULE", gomock.Any(), []*ecproto.Filter{
+					{Key: "ruleId", Operator: ecproto.Filter_EQUALS, Values: []string{"rid"}},
+				}).Return(
 					&ecproto.Row{Cells: []*ecproto.Cell{
 						{Value: ecdruid.ColumnVariation},
 						{Value: ecdruid.ColumnGoalUser},
@@ -613,6 +916,8 @@ func TestGetGoalCountV2(t *testing.T) {
 				VariationIds:         []string{"vid1"},
 				StartAt:              now.Add(-30 * 24 * time.Hour).Unix(),
 				EndAt:                now.Unix(),
+				Reason:               "RULE",
+				RuleId:               "rid",
 			},
 			expected: &ecproto.GetGoalCountV2Response{
 				GoalCounts: &ecproto.GoalCounts{
@@ -631,9 +936,9 @@ func TestGetGoalCountV2(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
-		"success: all variations": {
+		"success: all variations of experiment exposures": {
 			setup: func(s *eventCounterService) {
-				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				s.druidQuerier.(*dmock.MockQuerier).EXPECT().QueryGoalCount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), exposureFilters).Return(
 					&ecproto.Row{Cells: []*ecproto.Cell{
 						{Value: ecdruid.ColumnVariation},
 						{Value: ecdruid.ColumnGoalUser},
@@ -663,13 +968,14 @@ func TestGetGoalCountV2(t *testing.T) {
 					nil)
 			},
 			input: &ecproto.GetGoalCountV2Request{
-				EnvironmentNamespace: "ns0",
-				GoalId:               "gid",
-				FeatureId:            "fid",
-				FeatureVersion:       int32(1),
-				VariationIds:         []string{"vid0", "vid1"},
-				StartAt:              now.Add(-30 * 24 * time.Hour).Unix(),
-				EndAt:                now.Unix(),
+				EnvironmentNamespace:    "ns0",
+				GoalId:                  "gid",
+				FeatureId:               "fid",
+				FeatureVersion:          int32(1),
+				VariationIds:            []string{"vid0", "vid1"},
+				StartAt:                 now.Add(-30 * 24 * time.Hour).Unix(),
+				EndAt:                   now.Unix(),
+				ExperimentExposuresOnly: true,
 			},
 			expected: &ecproto.GetGoalCountV2Response{
 				GoalCounts: &ecproto.GoalCounts{
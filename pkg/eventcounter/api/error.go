@@ -15,6 +15,8 @@
 package api
 
 import (
+	"fmt"
+
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
@@ -24,17 +26,24 @@ import (
 )
 
 var (
-	statusInternal             = gstatus.New(codes.Internal, "eventcounter: internal")
-	statusFeatureIDRequired    = gstatus.New(codes.InvalidArgument, "eventcounter: feature id is required")
-	statusExperimentIDRequired = gstatus.New(codes.InvalidArgument, "eventcounter: experiment id is required")
-	statusGoalIDRequired       = gstatus.New(codes.InvalidArgument, "eventcounter: goal id is required")
-	statusStartAtRequired      = gstatus.New(codes.InvalidArgument, "eventcounter: start at is required")
-	statusEndAtRequired        = gstatus.New(codes.InvalidArgument, "eventcounter: end at is required")
-	statusPeriodOutOfRange     = gstatus.New(codes.InvalidArgument, "eventcounter: period out of range")
-	statusStartAtIsAfterEndAt  = gstatus.New(codes.InvalidArgument, "eventcounter: start at is after end at")
-	statusNotFound             = gstatus.New(codes.NotFound, "eventcounter: not found")
-	statusUnauthenticated      = gstatus.New(codes.Unauthenticated, "feature: unauthenticated")
-	statusPermissionDenied     = gstatus.New(codes.PermissionDenied, "feature: permission denied")
+	statusInternal               = gstatus.New(codes.Internal, "eventcounter: internal")
+	statusFeatureIDRequired      = gstatus.New(codes.InvalidArgument, "eventcounter: feature id is required")
+	statusExperimentIDRequired   = gstatus.New(codes.InvalidArgument, "eventcounter: experiment id is required")
+	statusGoalIDRequired         = gstatus.New(codes.InvalidArgument, "eventcounter: goal id is required")
+	statusStartAtRequired        = gstatus.New(codes.InvalidArgument, "eventcounter: start at is required")
+	statusEndAtRequired          = gstatus.New(codes.InvalidArgument, "eventcounter: end at is required")
+	statusPeriodOutOfRange       = gstatus.New(codes.InvalidArgument, "eventcounter: period out of range")
+	statusStartAtIsAfterEndAt    = gstatus.New(codes.InvalidArgument, "eventcounter: start at is after end at")
+	statusInvalidConfidenceLevel = gstatus.New(
+		codes.InvalidArgument,
+		"eventcounter: confidence level must be between 0 and 1",
+	)
+	statusAttributeRequired  = gstatus.New(codes.InvalidArgument, "eventcounter: attribute is required")
+	statusAttributeNotFound  = gstatus.New(codes.InvalidArgument, "eventcounter: attribute is not found in user data")
+	statusInvalidMaxSegments = gstatus.New(codes.InvalidArgument, "eventcounter: max segments is out of range")
+	statusNotFound           = gstatus.New(codes.NotFound, "eventcounter: not found")
+	statusUnauthenticated    = gstatus.New(codes.Unauthenticated, "feature: unauthenticated")
+	statusPermissionDenied   = gstatus.New(codes.PermissionDenied, "feature: permission denied")
 
 	errInternalJaJP = status.MustWithDetails(
 		statusInternal,
@@ -92,6 +101,34 @@ var (
 			Message: "期間は過去30日以内を選択してください。",
 		},
 	)
+	errInvalidConfidenceLevelJaJP = status.MustWithDetails(
+		statusInvalidConfidenceLevel,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "confidence levelは0より大きく1より小さい値を指定してください",
+		},
+	)
+	errAttributeRequiredJaJP = status.MustWithDetails(
+		statusAttributeRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "attributeは必須です",
+		},
+	)
+	errAttributeNotFoundJaJP = status.MustWithDetails(
+		statusAttributeNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "attributeがuser dataに存在しません",
+		},
+	)
+	errInvalidMaxSegmentsJaJP = status.MustWithDetails(
+		statusInvalidMaxSegments,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: fmt.Sprintf("max segmentsは%d以下で指定してください", maxBreakdownSegments),
+		},
+	)
 	errNotFoundJaJP = status.MustWithDetails(
 		statusNotFound,
 		&errdetails.LocalizedMessage{
@@ -134,6 +171,14 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errPeroidOutOfRangeJaJP
 	case statusStartAtIsAfterEndAt:
 		return errStartAtIsAfterEndAtJaJP
+	case statusInvalidConfidenceLevel:
+		return errInvalidConfidenceLevelJaJP
+	case statusAttributeRequired:
+		return errAttributeRequiredJaJP
+	case statusAttributeNotFound:
+		return errAttributeNotFoundJaJP
+	case statusInvalidMaxSegments:
+		return errInvalidMaxSegmentsJaJP
 	case statusNotFound:
 		return errNotFoundJaJP
 	case statusUnauthenticated:
@@ -109,6 +109,26 @@ func (mr *MockClientMockRecorder) GetExperimentResult(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperimentResult", reflect.TypeOf((*MockClient)(nil).GetExperimentResult), varargs...)
 }
 
+// GetExperimentResultBreakdown mocks base method.
+func (m *MockClient) GetExperimentResultBreakdown(ctx context.Context, in *eventcounter.GetExperimentResultBreakdownRequest, opts ...grpc.CallOption) (*eventcounter.GetExperimentResultBreakdownResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetExperimentResultBreakdown", varargs...)
+	ret0, _ := ret[0].(*eventcounter.GetExperimentResultBreakdownResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExperimentResultBreakdown indicates an expected call of GetExperimentResultBreakdown.
+func (mr *MockClientMockRecorder) GetExperimentResultBreakdown(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperimentResultBreakdown", reflect.TypeOf((*MockClient)(nil).GetExperimentResultBreakdown), varargs...)
+}
+
 // GetGoalCount mocks base method.
 func (m *MockClient) GetGoalCount(ctx context.Context, in *eventcounter.GetGoalCountRequest, opts ...grpc.CallOption) (*eventcounter.GetGoalCountResponse, error) {
 	m.ctrl.T.Helper()
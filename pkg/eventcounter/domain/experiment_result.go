@@ -15,9 +15,176 @@
 package domain
 
 import (
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
 	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
 )
 
 type ExperimentResult struct {
 	*eventcounterproto.ExperimentResult
 }
+
+// SetFrequentistSummaries compares every variation in each goal against the base variation.
+// The conversion rate is compared by the two-proportion z-test and the value sum per user by Welch's t-test.
+// A summary is left empty when the sample is not large enough to run the test.
+func (e *ExperimentResult) SetFrequentistSummaries(baseVariationID string, confidenceLevel float64) {
+	for _, gr := range e.GoalResults {
+		setFrequentistSummaries(gr.VariationResults, baseVariationID, confidenceLevel)
+	}
+}
+
+// setFrequentistSummaries returns false when the base variation is not in the results.
+func setFrequentistSummaries(
+	variationResults []*eventcounterproto.VariationResult,
+	baseVariationID string,
+	confidenceLevel float64,
+) bool {
+	var base *eventcounterproto.VariationResult
+	for _, vr := range variationResults {
+		if vr.VariationId == baseVariationID {
+			base = vr
+			break
+		}
+	}
+	if base == nil {
+		return false
+	}
+	for _, vr := range variationResults {
+		if vr == base {
+			vr.CvrFrequentist = &eventcounterproto.FrequentistSummary{
+				Mean:            conversionRate(vr),
+				ConfidenceLevel: confidenceLevel,
+			}
+			vr.GoalValueSumPerUserFrequentist = &eventcounterproto.FrequentistSummary{
+				Mean:            vr.GetExperimentCount().GetValueSumPerUserMean(),
+				ConfidenceLevel: confidenceLevel,
+			}
+			continue
+		}
+		vr.CvrFrequentist = cvrFrequentistSummary(base, vr, confidenceLevel)
+		vr.GoalValueSumPerUserFrequentist = valueSumPerUserFrequentistSummary(base, vr, confidenceLevel)
+	}
+	return true
+}
+
+// SetCupedSummaries adjusts the goal value sum per user of every variation in the goal
+// by the pre-experiment covariate samples keyed by the variation ID, and compares them
+// against the base variation by Welch's t-test. The summaries are left empty when
+// the covariate doesn't vary, since there is nothing to adjust by.
+func (e *ExperimentResult) SetCupedSummaries(
+	goalID, baseVariationID string,
+	samples map[string]*stats.CovariateSample,
+	confidenceLevel float64,
+) {
+	var goalResult *eventcounterproto.GoalResult
+	for _, gr := range e.GoalResults {
+		if gr.GoalId == goalID {
+			goalResult = gr
+			break
+		}
+	}
+	base, ok := samples[baseVariationID]
+	if goalResult == nil || !ok {
+		return
+	}
+	all := make([]*stats.CovariateSample, 0, len(samples))
+	size, covariateSum := int64(0), 0.0
+	for _, s := range samples {
+		all = append(all, s)
+		size += s.Size
+		covariateSum += float64(s.Size) * s.CovariateMean
+	}
+	theta, err := stats.CUPEDTheta(all)
+	if err != nil {
+		return
+	}
+	covariateMean := covariateSum / float64(size)
+	baseMean, baseVariance := stats.CUPEDAdjust(base, theta, covariateMean)
+	for _, vr := range goalResult.VariationResults {
+		s, ok := samples[vr.VariationId]
+		if !ok {
+			continue
+		}
+		mean, variance := stats.CUPEDAdjust(s, theta, covariateMean)
+		summary := &eventcounterproto.CupedSummary{
+			Theta:            theta,
+			CovariateMean:    s.CovariateMean,
+			AdjustedMean:     mean,
+			AdjustedVariance: variance,
+		}
+		if s.Variance > 0 {
+			summary.VarianceReduction = 1 - variance/s.Variance
+		}
+		if vr.VariationId == baseVariationID {
+			summary.Frequentist = &eventcounterproto.FrequentistSummary{
+				Mean:            mean,
+				ConfidenceLevel: confidenceLevel,
+			}
+		} else {
+			result, err := stats.WelchTTest(baseMean, baseVariance, base.Size, mean, variance, s.Size, confidenceLevel)
+			if err == nil {
+				summary.Frequentist = newFrequentistSummary(result)
+			}
+		}
+		vr.GoalValueSumPerUserCuped = summary
+	}
+}
+
+func conversionRate(vr *eventcounterproto.VariationResult) float64 {
+	evaluationUsers := vr.GetEvaluationCount().GetUserCount()
+	if evaluationUsers == 0 {
+		return 0
+	}
+	return float64(vr.GetExperimentCount().GetUserCount()) / float64(evaluationUsers)
+}
+
+func cvrFrequentistSummary(
+	base, vr *eventcounterproto.VariationResult,
+	confidenceLevel float64,
+) *eventcounterproto.FrequentistSummary {
+	result, err := stats.TwoProportionZTest(
+		base.GetExperimentCount().GetUserCount(),
+		base.GetEvaluationCount().GetUserCount(),
+		vr.GetExperimentCount().GetUserCount(),
+		vr.GetEvaluationCount().GetUserCount(),
+		confidenceLevel,
+	)
+	if err != nil {
+		return nil
+	}
+	return newFrequentistSummary(result)
+}
+
+func valueSumPerUserFrequentistSummary(
+	base, vr *eventcounterproto.VariationResult,
+	confidenceLevel float64,
+) *eventcounterproto.FrequentistSummary {
+	result, err := stats.WelchTTest(
+		base.GetExperimentCount().GetValueSumPerUserMean(),
+		base.GetExperimentCount().GetValueSumPerUserVariance(),
+		base.GetExperimentCount().GetUserCount(),
+		vr.GetExperimentCount().GetValueSumPerUserMean(),
+		vr.GetExperimentCount().GetValueSumPerUserVariance(),
+		vr.GetExperimentCount().GetUserCount(),
+		confidenceLevel,
+	)
+	if err != nil {
+		return nil
+	}
+	return newFrequentistSummary(result)
+}
+
+func newFrequentistSummary(result *stats.Result) *eventcounterproto.FrequentistSummary {
+	return &eventcounterproto.FrequentistSummary{
+		Mean:                result.Mean,
+		Difference:          result.Difference,
+		DifferenceCiLower:   result.DifferenceLower,
+		DifferenceCiUpper:   result.DifferenceUpper,
+		RelativeLift:        result.RelativeLift,
+		RelativeLiftCiLower: result.RelativeLiftLower,
+		RelativeLiftCiUpper: result.RelativeLiftUpper,
+		Statistic:           result.Statistic,
+		DegreesOfFreedom:    result.DegreesOfFreedom,
+		PValue:              result.PValue,
+		ConfidenceLevel:     result.ConfidenceLevel,
+	}
+}
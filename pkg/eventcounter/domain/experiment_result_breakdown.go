@@ -0,0 +1,60 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+)
+
+type ExperimentResultBreakdown struct {
+	*eventcounterproto.ExperimentResultBreakdown
+}
+
+// SetFrequentistSummaries compares every variation against the base variation in each segment.
+// Since a variation is compared once per segment, the p-values are adjusted
+// by the Holm-Bonferroni method across all the segments.
+func (b *ExperimentResultBreakdown) SetFrequentistSummaries(baseVariationID string, confidenceLevel float64) {
+	cvrs := []*eventcounterproto.FrequentistSummary{}
+	valueSums := []*eventcounterproto.FrequentistSummary{}
+	for _, s := range b.Segments {
+		if !setFrequentistSummaries(s.VariationResults, baseVariationID, confidenceLevel) {
+			continue
+		}
+		for _, vr := range s.VariationResults {
+			if vr.VariationId == baseVariationID {
+				continue
+			}
+			if vr.CvrFrequentist != nil {
+				cvrs = append(cvrs, vr.CvrFrequentist)
+			}
+			if vr.GoalValueSumPerUserFrequentist != nil {
+				valueSums = append(valueSums, vr.GoalValueSumPerUserFrequentist)
+			}
+		}
+	}
+	adjustPValues(cvrs)
+	adjustPValues(valueSums)
+}
+
+func adjustPValues(summaries []*eventcounterproto.FrequentistSummary) {
+	pValues := make([]float64, 0, len(summaries))
+	for _, s := range summaries {
+		pValues = append(pValues, s.PValue)
+	}
+	for i, adjusted := range stats.HolmBonferroni(pValues) {
+		summaries[i].AdjustedPValue = adjusted
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+)
+
+func TestExperimentResultBreakdown(t *testing.T) {
+	t.Parallel()
+	newVariationResult := func(id string, evaluationUsers, goalUsers int64) *eventcounterproto.VariationResult {
+		return &eventcounterproto.VariationResult{
+			VariationId:     id,
+			EvaluationCount: &eventcounterproto.VariationCount{VariationId: id, UserCount: evaluationUsers},
+			ExperimentCount: &eventcounterproto.VariationCount{VariationId: id, UserCount: goalUsers},
+		}
+	}
+	b := &ExperimentResultBreakdown{&eventcounterproto.ExperimentResultBreakdown{
+		Attribute: "platform",
+		Segments: []*eventcounterproto.SegmentResult{
+			{
+				Value: "android",
+				VariationResults: []*eventcounterproto.VariationResult{
+					newVariationResult("vid-0", 2000, 200),
+					newVariationResult("vid-1", 2000, 210),
+				},
+			},
+			{
+				Value: "ios",
+				VariationResults: []*eventcounterproto.VariationResult{
+					newVariationResult("vid-0", 1000, 100),
+					newVariationResult("vid-1", 1000, 130),
+				},
+			},
+		},
+	}}
+	b.SetFrequentistSummaries("vid-0", stats.DefaultConfidenceLevel)
+	android, err := stats.TwoProportionZTest(200, 2000, 210, 2000, stats.DefaultConfidenceLevel)
+	assert.NoError(t, err)
+	ios, err := stats.TwoProportionZTest(100, 1000, 130, 1000, stats.DefaultConfidenceLevel)
+	assert.NoError(t, err)
+	adjusted := stats.HolmBonferroni([]float64{android.PValue, ios.PValue})
+
+	assert.Equal(t, float64(0), b.Segments[0].VariationResults[0].CvrFrequentist.AdjustedPValue)
+	assert.InDelta(t, android.PValue, b.Segments[0].VariationResults[1].CvrFrequentist.PValue, 1e-9)
+	assert.InDelta(t, adjusted[0], b.Segments[0].VariationResults[1].CvrFrequentist.AdjustedPValue, 1e-9)
+	assert.InDelta(t, ios.PValue, b.Segments[1].VariationResults[1].CvrFrequentist.PValue, 1e-9)
+	assert.InDelta(t, adjusted[1], b.Segments[1].VariationResults[1].CvrFrequentist.AdjustedPValue, 1e-9)
+	assert.Greater(t, b.Segments[1].VariationResults[1].CvrFrequentist.AdjustedPValue, ios.PValue)
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+)
+
+func TestSetFrequentistSummaries(t *testing.T) {
+	t.Parallel()
+	newVariationResult := func(
+		id string,
+		evaluationUsers, goalUsers int64,
+		mean, variance float64,
+	) *eventcounterproto.VariationResult {
+		return &eventcounterproto.VariationResult{
+			VariationId: id,
+			EvaluationCount: &eventcounterproto.VariationCount{
+				VariationId: id,
+				UserCount:   evaluationUsers,
+			},
+			ExperimentCount: &eventcounterproto.VariationCount{
+				VariationId:             id,
+				UserCount:               goalUsers,
+				ValueSumPerUserMean:     mean,
+				ValueSumPerUserVariance: variance,
+			},
+		}
+	}
+	e := &ExperimentResult{&eventcounterproto.ExperimentResult{
+		GoalResults: []*eventcounterproto.GoalResult{
+			{
+				GoalId: "gid-0",
+				VariationResults: []*eventcounterproto.VariationResult{
+					newVariationResult("vid-0", 1000, 100, 10, 4),
+					newVariationResult("vid-1", 1000, 130, 11, 9),
+					newVariationResult("vid-2", 0, 0, 0, 0),
+				},
+			},
+			{
+				GoalId: "gid-1",
+				VariationResults: []*eventcounterproto.VariationResult{
+					newVariationResult("vid-1", 1000, 130, 11, 9),
+				},
+			},
+		},
+	}}
+	e.SetFrequentistSummaries("vid-0", stats.DefaultConfidenceLevel)
+
+	base := e.GoalResults[0].VariationResults[0]
+	assert.Equal(t, &eventcounterproto.FrequentistSummary{
+		Mean:            0.1,
+		ConfidenceLevel: stats.DefaultConfidenceLevel,
+	}, base.CvrFrequentist)
+	assert.Equal(t, &eventcounterproto.FrequentistSummary{
+		Mean:            10,
+		ConfidenceLevel: stats.DefaultConfidenceLevel,
+	}, base.GoalValueSumPerUserFrequentist)
+
+	vr := e.GoalResults[0].VariationResults[1]
+	assert.InDelta(t, 0.13, vr.CvrFrequentist.Mean, 1e-9)
+	assert.InDelta(t, 0.3, vr.CvrFrequentist.RelativeLift, 1e-9)
+	assert.InDelta(t, 0.035488, vr.CvrFrequentist.PValue, 1e-6)
+	assert.InDelta(t, 1.0, vr.GoalValueSumPerUserFrequentist.Difference, 1e-9)
+	assert.True(t, vr.GoalValueSumPerUserFrequentist.DegreesOfFreedom > 0)
+
+	empty := e.GoalResults[0].VariationResults[2]
+	assert.Nil(t, empty.CvrFrequentist)
+	assert.Nil(t, empty.GoalValueSumPerUserFrequentist)
+
+	missingBase := e.GoalResults[1].VariationResults[0]
+	assert.Nil(t, missingBase.CvrFrequentist)
+	assert.Nil(t, missingBase.GoalValueSumPerUserFrequentist)
+}
+
+func TestSetCupedSummaries(t *testing.T) {
+	t.Parallel()
+	e := &ExperimentResult{&eventcounterproto.ExperimentResult{
+		GoalResults: []*eventcounterproto.GoalResult{
+			{
+				GoalId: "gid",
+				VariationResults: []*eventcounterproto.VariationResult{
+					{VariationId: "vid-0"},
+					{VariationId: "vid-1"},
+					{VariationId: "vid-2"},
+				},
+			},
+		},
+	}}
+	samples := map[string]*stats.CovariateSample{
+		"vid-0": {Size: 101, Mean: 10, Variance: 4, CovariateMean: 5, CovariateVariance: 4, Covariance: 3},
+		"vid-1": {Size: 101, Mean: 11, Variance: 4, CovariateMean: 6, CovariateVariance: 4, Covariance: 3},
+	}
+	e.SetCupedSummaries("unknown", "vid-0", samples, stats.DefaultConfidenceLevel)
+	assert.Nil(t, e.GoalResults[0].VariationResults[0].GoalValueSumPerUserCuped)
+
+	e.SetCupedSummaries("gid", "vid-0", samples, stats.DefaultConfidenceLevel)
+	base := e.GoalResults[0].VariationResults[0].GoalValueSumPerUserCuped
+	assert.InDelta(t, 0.75, base.Theta, 1e-9)
+	assert.InDelta(t, 10.375, base.AdjustedMean, 1e-9)
+	assert.InDelta(t, 1.75, base.AdjustedVariance, 1e-9)
+	assert.InDelta(t, 0.5625, base.VarianceReduction, 1e-9)
+	assert.Equal(t, &eventcounterproto.FrequentistSummary{
+		Mean:            10.375,
+		ConfidenceLevel: stats.DefaultConfidenceLevel,
+	}, base.Frequentist)
+
+	// The variation had a higher goal value before the experiment, so most of its lift is explained away.
+	vr := e.GoalResults[0].VariationResults[1].GoalValueSumPerUserCuped
+	assert.InDelta(t, 10.625, vr.AdjustedMean, 1e-9)
+	assert.InDelta(t, 0.25, vr.Frequentist.Difference, 1e-9)
+	assert.Greater(t, vr.Frequentist.PValue, 0.05)
+	assert.Nil(t, e.GoalResults[0].VariationResults[2].GoalValueSumPerUserCuped)
+}
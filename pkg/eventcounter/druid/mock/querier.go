@@ -69,6 +69,21 @@ func (mr *MockQuerierMockRecorder) QueryEvaluationCount(ctx, environmentNamespac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEvaluationCount", reflect.TypeOf((*MockQuerier)(nil).QueryEvaluationCount), ctx, environmentNamespace, startAt, endAt, featureID, featureVersion, reason, segmnets, filters)
 }
 
+// QueryEvaluationSegmentValues mocks base method.
+func (m *MockQuerier) QueryEvaluationSegmentValues(ctx context.Context, environmentNamespace string, startAt, endAt time.Time, featureID string, featureVersion int32, segment string, filters []*eventcounter.Filter, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryEvaluationSegmentValues", ctx, environmentNamespace, startAt, endAt, featureID, featureVersion, segment, filters, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryEvaluationSegmentValues indicates an expected call of QueryEvaluationSegmentValues.
+func (mr *MockQuerierMockRecorder) QueryEvaluationSegmentValues(ctx, environmentNamespace, startAt, endAt, featureID, featureVersion, segment, filters, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEvaluationSegmentValues", reflect.TypeOf((*MockQuerier)(nil).QueryEvaluationSegmentValues), ctx, environmentNamespace, startAt, endAt, featureID, featureVersion, segment, filters, limit)
+}
+
 // QueryEvaluationTimeseriesCount mocks base method.
 func (m *MockQuerier) QueryEvaluationTimeseriesCount(ctx context.Context, environmentNamespace string, startAt, endAt time.Time, featureID string, featureVersion int32, variationID string) (map[string]*eventcounter.VariationTimeseries, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +115,22 @@ func (mr *MockQuerierMockRecorder) QueryGoalCount(ctx, environmentNamespace, sta
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryGoalCount", reflect.TypeOf((*MockQuerier)(nil).QueryGoalCount), ctx, environmentNamespace, startAt, endAt, goalID, featureID, featureVersion, reason, segmnets, filters)
 }
 
+// QueryGoalValueCovariates mocks base method.
+func (m *MockQuerier) QueryGoalValueCovariates(ctx context.Context, environmentNamespace string, preStartAt, startAt, endAt time.Time, goalID, featureID string, featureVersion int32, variationIDs []string, filters []*eventcounter.Filter) (*eventcounter.Row, []*eventcounter.Row, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryGoalValueCovariates", ctx, environmentNamespace, preStartAt, startAt, endAt, goalID, featureID, featureVersion, variationIDs, filters)
+	ret0, _ := ret[0].(*eventcounter.Row)
+	ret1, _ := ret[1].([]*eventcounter.Row)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// QueryGoalValueCovariates indicates an expected call of QueryGoalValueCovariates.
+func (mr *MockQuerierMockRecorder) QueryGoalValueCovariates(ctx, environmentNamespace, preStartAt, startAt, endAt, goalID, featureID, featureVersion, variationIDs, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryGoalValueCovariates", reflect.TypeOf((*MockQuerier)(nil).QueryGoalValueCovariates), ctx, environmentNamespace, preStartAt, startAt, endAt, goalID, featureID, featureVersion, variationIDs, filters)
+}
+
 // QuerySegmentMetadata mocks base method.
 func (m *MockQuerier) QuerySegmentMetadata(ctx context.Context, environmentNamespace, dataType string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -31,22 +31,26 @@ import (
 )
 
 const (
-	DataTypeEvaluationEvents = "evaluation_events"
-	DataTypeGoalEvents       = "goal_events"
-	DataTypeUserEvents       = "user_events"
-	ColumnVariation          = "Variation"
-	ColumnFeatureVersion     = "Feature version"
-	ColumnCVR                = "Conversion rate"
-	ColumnGoalUser           = "Goal user"
-	ColumnGoalTotal          = "Goal total"
-	ColumnGoalValueMean      = "Goal value mean"
-	ColumnGoalValueTotal     = "Goal value total"
-	ColumnGoalValueVariance  = "Goal value variance"
-	ColumnEvaluationUser     = "Evaluation user"
-	ColumnEvaluationTotal    = "Evaluation total"
-	ColumnUser               = "User"
-	ColumnUserCount          = "User count"
-	ColumnUserTotal          = "User total"
+	DataTypeEvaluationEvents      = "evaluation_events"
+	DataTypeGoalEvents            = "goal_events"
+	DataTypeUserEvents            = "user_events"
+	ColumnVariation               = "Variation"
+	ColumnFeatureVersion          = "Feature version"
+	ColumnCVR                     = "Conversion rate"
+	ColumnGoalUser                = "Goal user"
+	ColumnGoalTotal               = "Goal total"
+	ColumnGoalValueMean           = "Goal value mean"
+	ColumnGoalValueTotal          = "Goal value total"
+	ColumnGoalValueVariance       = "Goal value variance"
+	ColumnGoalValueSquareTotal    = "Goal value square total"
+	ColumnCovariateTotal          = "Covariate total"
+	ColumnCovariateSquareTotal    = "Covariate square total"
+	ColumnGoalValueCovariateTotal = "Goal value covariate product total"
+	ColumnEvaluationUser          = "Evaluation user"
+	ColumnEvaluationTotal         = "Evaluation total"
+	ColumnUser                    = "User"
+	ColumnUserCount               = "User count"
+	ColumnUserTotal               = "User total"
 )
 
 var (
@@ -65,6 +69,15 @@ type Querier interface {
 		segmnets []string,
 		filters []*ecproto.Filter,
 	) (*ecproto.Row, []*ecproto.Row, error)
+	QueryGoalValueCovariates(
+		ctx context.Context,
+		environmentNamespace string,
+		preStartAt, startAt, endAt time.Time,
+		goalID, featureID string,
+		featureVersion int32,
+		variationIDs []string,
+		filters []*ecproto.Filter,
+	) (*ecproto.Row, []*ecproto.Row, error)
 	QueryEvaluationCount(
 		ctx context.Context,
 		environmentNamespace string,
@@ -75,6 +88,16 @@ type Querier interface {
 		segmnets []string,
 		filters []*ecproto.Filter,
 	) (*ecproto.Row, []*ecproto.Row, error)
+	QueryEvaluationSegmentValues(
+		ctx context.Context,
+		environmentNamespace string,
+		startAt, endAt time.Time,
+		featureID string,
+		featureVersion int32,
+		segment string,
+		filters []*ecproto.Filter,
+		limit int,
+	) ([]string, error)
 	QueryEvaluationTimeseriesCount(
 		ctx context.Context,
 		environmentNamespace string,
@@ -227,6 +250,120 @@ func (q *druidQuerier) QueryGoalCount(
 	return headers, rows, nil
 }
 
+// QueryGoalValueCovariates returns the sums of the goal values of the users in each variation
+// between startAt and endAt, and the ones of the goal values of the same users between preStartAt and startAt.
+func (q *druidQuerier) QueryGoalValueCovariates(
+	ctx context.Context,
+	environmentNamespace string,
+	preStartAt, startAt, endAt time.Time,
+	goalID, featureID string,
+	featureVersion int32,
+	variationIDs []string,
+	filters []*ecproto.Filter,
+) (*ecproto.Row, []*ecproto.Row, error) {
+	datasource := storagedruid.Datasource(q.datasourcePrefix, DataTypeGoalEvents)
+	envFilters := convToEnvFilters(environmentNamespace, filters)
+	query := queryGoalValueCovariateGroupBy(
+		datasource,
+		preStartAt,
+		startAt,
+		endAt,
+		environmentNamespace,
+		goalID,
+		featureID,
+		featureVersion,
+		variationIDs,
+		envFilters,
+	)
+	if err := q.brokerClient.Query(query, ""); err != nil {
+		b, _ := json.Marshal(query)
+		q.logger.Error("Failed to query goal value covariates", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("datastore", datasource),
+			zap.String("query", string(b)))
+		return nil, nil, err
+	}
+	headers, rows := convToCovariateTable(query.QueryResult, variationIDs)
+	return headers, rows, nil
+}
+
+// convToCovariateTable turns the columns of each variation into a row.
+func convToCovariateTable(queryResult []godruid.GroupbyItem, variationIDs []string) (*ecproto.Row, []*ecproto.Row) {
+	columns := []string{
+		ColumnGoalUser,
+		ColumnGoalValueTotal,
+		ColumnGoalValueSquareTotal,
+		ColumnCovariateTotal,
+		ColumnCovariateSquareTotal,
+		ColumnGoalValueCovariateTotal,
+	}
+	headers := &ecproto.Row{Cells: []*ecproto.Cell{{Type: ecproto.Cell_STRING, Value: ColumnVariation}}}
+	for _, column := range columns {
+		headers.Cells = append(headers.Cells, &ecproto.Cell{Type: ecproto.Cell_STRING, Value: column})
+	}
+	rows := []*ecproto.Row{}
+	for _, item := range queryResult {
+		for i, vid := range variationIDs {
+			cells := []*ecproto.Cell{{Type: ecproto.Cell_STRING, Value: vid}}
+			for _, column := range columns {
+				value, _ := item.Event[covariateColumn(column, i)].(float64)
+				cells = append(cells, &ecproto.Cell{Type: ecproto.Cell_DOUBLE, ValueDouble: value})
+			}
+			rows = append(rows, &ecproto.Row{Cells: cells})
+		}
+	}
+	return headers, rows
+}
+
+// QueryEvaluationSegmentValues returns up to limit values of the segment,
+// in descending order of the number of evaluated users.
+func (q *druidQuerier) QueryEvaluationSegmentValues(
+	ctx context.Context,
+	environmentNamespace string,
+	startAt, endAt time.Time,
+	featureID string,
+	featureVersion int32,
+	segment string,
+	filters []*ecproto.Filter,
+	limit int,
+) ([]string, error) {
+	datasource := storagedruid.Datasource(q.datasourcePrefix, DataTypeEvaluationEvents)
+	envSegment := convToEnvSegments(environmentNamespace, []string{segment})[0]
+	envFilters := convToEnvFilters(environmentNamespace, filters)
+	query := queryEvaluationSegmentTopN(
+		datasource,
+		startAt,
+		endAt,
+		environmentNamespace,
+		featureID,
+		featureVersion,
+		envSegment,
+		envFilters,
+		limit,
+	)
+	if err := q.brokerClient.Query(query, ""); err != nil {
+		b, _ := json.Marshal(query)
+		q.logger.Error("Failed to query evaluation segment values", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("datastore", datasource),
+			zap.String("query", string(b)))
+		return nil, err
+	}
+	return convToSegmentValues(query.QueryResult, envSegment), nil
+}
+
+func convToSegmentValues(items []godruid.TopNItem, segment string) []string {
+	values := []string{}
+	for _, item := range items {
+		for _, r := range item.Result {
+			// The events without the segment are grouped by null.
+			v, _ := r[segment].(string)
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func (q *druidQuerier) QueryEvaluationCount(
 	ctx context.Context,
 	environmentNamespace string,
@@ -594,9 +731,9 @@ func convToEnvFilters(environmentNamespace string, filters []*ecproto.Filter) []
 			key = fmt.Sprintf("%s.%s", environmentNamespace, key)
 		}
 		switch f.Operator {
-		case ecproto.Filter_EQUALS:
+		case ecproto.Filter_EQUALS, ecproto.Filter_NOT_EQUALS, ecproto.Filter_IN:
 			fls = append(fls, &ecproto.Filter{
-				Operator: ecproto.Filter_EQUALS,
+				Operator: f.Operator,
 				Key:      key,
 				Values:   f.Values,
 			})
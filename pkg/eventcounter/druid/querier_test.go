@@ -18,7 +18,9 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/ca-dp/godruid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
 )
@@ -92,6 +94,17 @@ func TestConvToEnvFilters(t *testing.T) {
 				{Key: "ns.user.data.sgmt", Operator: ecproto.Filter_EQUALS, Values: []string{"d0"}},
 			},
 		},
+		"not equals": {
+			inputNamespace: "ns",
+			inputFilters: []*ecproto.Filter{
+				{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"TARGET", "RULE"}},
+				{Key: "user.data.sgmt", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"d0"}},
+			},
+			expected: []*ecproto.Filter{
+				{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"TARGET", "RULE"}},
+				{Key: "ns.user.data.sgmt", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"d0"}},
+			},
+		},
 	}
 	for msg, p := range patterns {
 		t.Run(msg, func(t *testing.T) {
@@ -101,6 +114,43 @@ func TestConvToEnvFilters(t *testing.T) {
 	}
 }
 
+func TestConvToSegmentValues(t *testing.T) {
+	t.Parallel()
+	items := []godruid.TopNItem{{
+		Result: []map[string]interface{}{
+			{"ns.user.data.platform": "android", ColumnEvaluationUser: float64(20)},
+			{"ns.user.data.platform": nil, ColumnEvaluationUser: float64(10)},
+			{"ns.user.data.platform": "ios", ColumnEvaluationUser: float64(5)},
+		},
+	}}
+	assert.Equal(t, []string{"android", "", "ios"}, convToSegmentValues(items, "ns.user.data.platform"))
+}
+
+func TestConvToCovariateTable(t *testing.T) {
+	t.Parallel()
+	items := []godruid.GroupbyItem{{
+		Event: map[string]interface{}{
+			"Goal user 0":                          float64(2),
+			"Goal value total 0":                   float64(6),
+			"Goal value square total 0":            float64(20),
+			"Covariate total 0":                    float64(1),
+			"Covariate square total 0":             float64(1),
+			"Goal value covariate product total 0": float64(2),
+		},
+	}}
+	headers, rows := convToCovariateTable(items, []string{"vid-0", "vid-1"})
+	require.Len(t, headers.Cells, 7)
+	assert.Equal(t, ColumnVariation, headers.Cells[0].Value)
+	assert.Equal(t, ColumnGoalValueCovariateTotal, headers.Cells[6].Value)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "vid-0", rows[0].Cells[0].Value)
+	assert.Equal(t, float64(2), rows[0].Cells[1].ValueDouble)
+	assert.Equal(t, float64(2), rows[0].Cells[6].ValueDouble)
+	// The variation nobody was exposed to has no columns.
+	assert.Equal(t, "vid-1", rows[1].Cells[0].Value)
+	assert.Equal(t, float64(0), rows[1].Cells[1].ValueDouble)
+}
+
 func TestUserDataPattern(t *testing.T) {
 	t.Parallel()
 
@@ -25,6 +25,8 @@ import (
 
 const (
 	intervalStr = "2006-01-02T15:04"
+	reasonKey   = "reason"
+	ruleKey     = "ruleId"
 )
 
 func querySegmentMetadata(datasource string, startAt, endAt time.Time) *godruid.QuerySegmentMetadata {
@@ -58,6 +60,9 @@ func queryGoalGroupBy(
 	limitColumns := []godruid.Column{}
 	filters = append(filters, godruid.FilterSelector("environmentNamespace", environmentNamespace))
 	filters = append(filters, godruid.FilterSelector("goalId", goalID))
+	// Goal events have no reason or rule dimension, they are in their evaluations instead.
+	reasonFls, fls := splitFilters(fls, reasonKey)
+	ruleFls, fls := splitFilters(fls, ruleKey)
 	filters = append(filters, convToDruidFilters(fls)...)
 	innerDimensions = append(innerDimensions, godruid.DimDefault("userId", ColumnUser))
 	for _, segment := range segments {
@@ -74,6 +79,8 @@ func queryGoalGroupBy(
 		}
 		innerEvaluationPattern := fmt.Sprintf("^%s:%d:.*$", featureID, featureVersion)
 		filters = append(filters, godruid.FilterRegex("evaluations", filterEvaluationPattern))
+		filters = append(filters, convToEvaluationReasonFilters(featureID, featureVersion, reasonFls)...)
+		filters = append(filters, convToEvaluationRuleFilters(featureID, featureVersion, ruleFls)...)
 		innerDimensions = append(innerDimensions, evaluationsDim(innerEvaluationPattern))
 		outerDimensions = append(outerDimensions, godruid.DimDefault(ColumnVariation, ColumnVariation))
 		limitColumns = append(limitColumns, godruid.Column{Dimension: ColumnVariation, Direction: godruid.DirectionASC})
@@ -110,6 +117,101 @@ func queryGoalGroupBy(
 	return query
 }
 
+// queryGoalValueCovariateGroupBy sums up the goal values of the users in each variation of the experiment,
+// and the goal values of the same users before the experiment started as the covariate.
+// The inner query sums up the values of each user, and the outer one sums them up over the users,
+// so that no value of each user is returned.
+func queryGoalValueCovariateGroupBy(
+	datasource string,
+	preStartAt, startAt, endAt time.Time,
+	environmentNamespace string,
+	goalID string,
+	featureID string,
+	featureVersion int32,
+	variationIDs []string,
+	fls []*ecproto.Filter,
+) *godruid.QueryGroupBy {
+	filters := []*godruid.Filter{}
+	filters = append(filters, godruid.FilterSelector("environmentNamespace", environmentNamespace))
+	filters = append(filters, godruid.FilterSelector("goalId", goalID))
+	reasonFls, fls := splitFilters(fls, reasonKey)
+	filters = append(filters, convToDruidFilters(fls)...)
+	// The reasons are only filtered in the experiment, since the users are in no experiment before it.
+	inExperiment := godruid.FilterSelector("inExperiment", 1)
+	innerAggregations := []godruid.Aggregation{
+		godruid.AggFiltered(
+			godruid.FilterSelector("inExperiment", 0),
+			godruid.AggDoubleSum("covariate", "valueSum"),
+		),
+	}
+	outerVirtualColumns := []godruid.VirtualColumn{
+		godruid.NewVirtualColumn("covariateSquare", "covariate * covariate", godruid.VirtualColumnDouble),
+	}
+	outerAggregations := []godruid.Aggregation{}
+	for i, vid := range variationIDs {
+		variationFilters := []*godruid.Filter{
+			inExperiment,
+			godruid.FilterRegex("evaluations", fmt.Sprintf("^%s:%d:%s:.*$", featureID, featureVersion, vid)),
+		}
+		variationFilters = append(variationFilters, convToEvaluationReasonFilters(featureID, featureVersion, reasonFls)...)
+		exposures, value := fmt.Sprintf("exposures%d", i), fmt.Sprintf("value%d", i)
+		innerAggregations = append(
+			innerAggregations,
+			godruid.AggFiltered(godruid.FilterAnd(variationFilters...), godruid.AggLongSum(exposures, "count")),
+			godruid.AggFiltered(godruid.FilterAnd(variationFilters...), godruid.AggDoubleSum(value, "valueSum")),
+		)
+		valueSquare, product := fmt.Sprintf("valueSquare%d", i), fmt.Sprintf("product%d", i)
+		outerVirtualColumns = append(
+			outerVirtualColumns,
+			godruid.NewVirtualColumn(valueSquare, fmt.Sprintf("%s * %s", value, value), godruid.VirtualColumnDouble),
+			godruid.NewVirtualColumn(product, fmt.Sprintf("covariate * %s", value), godruid.VirtualColumnDouble),
+		)
+		exposed := godruid.FilterNot(godruid.FilterSelector(exposures, 0))
+		outerAggregations = append(
+			outerAggregations,
+			godruid.AggFiltered(exposed, godruid.AggCount(covariateColumn(ColumnGoalUser, i))),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum(covariateColumn(ColumnGoalValueTotal, i), value)),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum(covariateColumn(ColumnGoalValueSquareTotal, i), valueSquare)),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum(covariateColumn(ColumnCovariateTotal, i), "covariate")),
+			godruid.AggFiltered(
+				exposed,
+				godruid.AggDoubleSum(covariateColumn(ColumnCovariateSquareTotal, i), "covariateSquare"),
+			),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum(covariateColumn(ColumnGoalValueCovariateTotal, i), product)),
+		)
+	}
+	innerQuery := &godruid.QueryGroupBy{
+		QueryType:   godruid.GROUPBY,
+		DataSource:  godruid.DataSourceTable(datasource),
+		Intervals:   toConvInterval(preStartAt, endAt),
+		Granularity: godruid.GranAll,
+		Filter:      godruid.FilterAnd(filters...),
+		VirtualColumns: []godruid.VirtualColumn{
+			godruid.NewVirtualColumn(
+				"inExperiment",
+				fmt.Sprintf("__time >= %d", startAt.UnixMilli()),
+				godruid.VirtualColumnLong,
+			),
+		},
+		Dimensions:   []godruid.DimSpec{godruid.DimDefault("userId", ColumnUser)},
+		Aggregations: innerAggregations,
+	}
+	return &godruid.QueryGroupBy{
+		QueryType:      godruid.GROUPBY,
+		DataSource:     godruid.DataSourceQuery(innerQuery),
+		Intervals:      toConvInterval(preStartAt, endAt),
+		Granularity:    godruid.GranAll,
+		Dimensions:     []godruid.DimSpec{},
+		VirtualColumns: outerVirtualColumns,
+		Aggregations:   outerAggregations,
+	}
+}
+
+// covariateColumn is the column of the ith variation in the goal value covariate query.
+func covariateColumn(column string, i int) string {
+	return fmt.Sprintf("%s %d", column, i)
+}
+
 func queryEvaluationGroupBy(
 	datasource string,
 	startAt, endAt time.Time,
@@ -136,7 +238,7 @@ func queryEvaluationGroupBy(
 		filters = append(filters, godruid.FilterSelector("featureVersion", featureVersion))
 	}
 	if reason != "" {
-		filters = append(filters, godruid.FilterSelector("reason", reason))
+		filters = append(filters, godruid.FilterSelector(reasonKey, reason))
 	}
 	filters = append(filters, convToDruidFilters(fls)...)
 	dimensions = append(dimensions, godruid.DimDefault("variationId", ColumnVariation))
@@ -191,6 +293,41 @@ func evaluationsDim(evaluationPattern string) *godruid.DimFiltered {
 	return godruid.DimFilteredRegex(variationDelegate, evaluationPattern)
 }
 
+// queryEvaluationSegmentTopN ranks the values of the segment by the number of evaluated users.
+func queryEvaluationSegmentTopN(
+	datasource string,
+	startAt, endAt time.Time,
+	environmentNamespace string,
+	featureID string,
+	featureVersion int32,
+	segment string,
+	fls []*ecproto.Filter,
+	threshold int,
+) *godruid.QueryTopN {
+	filters := []*godruid.Filter{}
+	filters = append(filters, godruid.FilterSelector("environmentNamespace", environmentNamespace))
+	filters = append(filters, godruid.FilterSelector("featureId", featureID))
+	if featureVersion != 0 {
+		filters = append(filters, godruid.FilterSelector("featureVersion", featureVersion))
+	}
+	filters = append(filters, convToDruidFilters(fls)...)
+	return &godruid.QueryTopN{
+		QueryType:   godruid.TOPN,
+		DataSource:  godruid.DataSourceTable(datasource),
+		Intervals:   toConvInterval(startAt, endAt),
+		Granularity: godruid.GranAll,
+		Filter:      godruid.FilterAnd(filters...),
+		Dimension:   godruid.DimDefault(segment, segment),
+		Threshold:   threshold,
+		Metric:      ColumnEvaluationUser,
+		Aggregations: []godruid.Aggregation{
+			*godruid.AggRawJson(
+				fmt.Sprintf(`{ "type": "thetaSketch", "name": "%s", "fieldName": "userIdThetaSketch" }`, ColumnEvaluationUser),
+			),
+		},
+	}
+}
+
 func queryEvaluationTimeseries(
 	datasource string,
 	startAt, endAt time.Time,
@@ -230,6 +367,81 @@ func convToDruidFilters(filters []*ecproto.Filter) []*godruid.Filter {
 			for _, v := range f.Values {
 				fls = append(fls, godruid.FilterSelector(f.Key, v))
 			}
+		case ecproto.Filter_IN:
+			selectors := make([]*godruid.Filter, 0, len(f.Values))
+			for _, v := range f.Values {
+				selectors = append(selectors, godruid.FilterSelector(f.Key, v))
+			}
+			fls = append(fls, godruid.FilterOr(selectors...))
+		case ecproto.Filter_NOT_EQUALS:
+			for _, v := range f.Values {
+				fls = append(fls, godruid.FilterNot(godruid.FilterSelector(f.Key, v)))
+			}
+		}
+	}
+	return fls
+}
+
+func splitFilters(filters []*ecproto.Filter, key string) (keyFilters, others []*ecproto.Filter) {
+	for _, f := range filters {
+		if f.Key == key {
+			keyFilters = append(keyFilters, f)
+			continue
+		}
+		others = append(others, f)
+	}
+	return keyFilters, others
+}
+
+// convToEvaluationReasonFilters filters goal events by the reason of the feature's evaluation they are attributed to.
+func convToEvaluationReasonFilters(
+	featureID string,
+	featureVersion int32,
+	filters []*ecproto.Filter,
+) []*godruid.Filter {
+	fls := []*godruid.Filter{}
+	for _, f := range filters {
+		regexes := make([]*godruid.Filter, 0, len(f.Values))
+		for _, v := range f.Values {
+			pattern := fmt.Sprintf("^%s:%d:.*:%s$", featureID, featureVersion, v)
+			regexes = append(regexes, godruid.FilterRegex("evaluations", pattern))
+		}
+		switch f.Operator {
+		case ecproto.Filter_EQUALS:
+			fls = append(fls, regexes...)
+		case ecproto.Filter_IN:
+			fls = append(fls, godruid.FilterOr(regexes...))
+		case ecproto.Filter_NOT_EQUALS:
+			for _, r := range regexes {
+				fls = append(fls, godruid.FilterNot(r))
+			}
+		}
+	}
+	return fls
+}
+
+// convToEvaluationRuleFilters filters goal events by the rule of the feature's evaluation they are attributed to.
+func convToEvaluationRuleFilters(
+	featureID string,
+	featureVersion int32,
+	filters []*ecproto.Filter,
+) []*godruid.Filter {
+	fls := []*godruid.Filter{}
+	for _, f := range filters {
+		selectors := make([]*godruid.Filter, 0, len(f.Values))
+		for _, v := range f.Values {
+			value := fmt.Sprintf("%s:%d:%s", featureID, featureVersion, v)
+			selectors = append(selectors, godruid.FilterSelector("ruleEvaluations", value))
+		}
+		switch f.Operator {
+		case ecproto.Filter_EQUALS:
+			fls = append(fls, selectors...)
+		case ecproto.Filter_IN:
+			fls = append(fls, godruid.FilterOr(selectors...))
+		case ecproto.Filter_NOT_EQUALS:
+			for _, s := range selectors {
+				fls = append(fls, godruid.FilterNot(s))
+			}
 		}
 	}
 	return fls
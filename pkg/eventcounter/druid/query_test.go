@@ -630,6 +630,66 @@ func TestQueryUserGroupBy(t *testing.T) {
 	}
 }
 
+func TestQueryGoalValueCovariateGroupBy(t *testing.T) {
+	t.Parallel()
+	layout := "2006-01-02 15:04:05 -0700 MST"
+	t0, err := time.Parse(layout, "2014-01-10 23:02:03 +0000 UTC")
+	require.NoError(t, err)
+	t1, err := time.Parse(layout, "2014-01-17 23:02:03 +0000 UTC")
+	require.NoError(t, err)
+	t2, err := time.Parse(layout, "2014-01-18 23:02:03 +0000 UTC")
+	require.NoError(t, err)
+	filters := []*ecproto.Filter{
+		{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"LAYER_HOLDOUT"}},
+	}
+	variationFilter := godruid.FilterAnd(
+		godruid.FilterSelector("inExperiment", 1),
+		godruid.FilterRegex("evaluations", "^fid:2:vid:.*$"),
+		godruid.FilterNot(godruid.FilterRegex("evaluations", "^fid:2:.*:LAYER_HOLDOUT$")),
+	)
+	exposed := godruid.FilterNot(godruid.FilterSelector("exposures0", 0))
+	expected := &godruid.QueryGroupBy{
+		QueryType: godruid.GROUPBY,
+		DataSource: godruid.DataSourceQuery(&godruid.QueryGroupBy{
+			QueryType:   godruid.GROUPBY,
+			DataSource:  godruid.DataSourceTable("ds"),
+			Intervals:   "2014-01-10T23:02/2014-01-18T23:02",
+			Granularity: godruid.GranAll,
+			Filter: godruid.FilterAnd(
+				godruid.FilterSelector("environmentNamespace", "ns"),
+				godruid.FilterSelector("goalId", "gid"),
+			),
+			VirtualColumns: []godruid.VirtualColumn{
+				godruid.NewVirtualColumn("inExperiment", "__time >= 1389999723000", godruid.VirtualColumnLong),
+			},
+			Dimensions: []godruid.DimSpec{godruid.DimDefault("userId", ColumnUser)},
+			Aggregations: []godruid.Aggregation{
+				godruid.AggFiltered(godruid.FilterSelector("inExperiment", 0), godruid.AggDoubleSum("covariate", "valueSum")),
+				godruid.AggFiltered(variationFilter, godruid.AggLongSum("exposures0", "count")),
+				godruid.AggFiltered(variationFilter, godruid.AggDoubleSum("value0", "valueSum")),
+			},
+		}),
+		Intervals:   "2014-01-10T23:02/2014-01-18T23:02",
+		Granularity: godruid.GranAll,
+		Dimensions:  []godruid.DimSpec{},
+		VirtualColumns: []godruid.VirtualColumn{
+			godruid.NewVirtualColumn("covariateSquare", "covariate * covariate", godruid.VirtualColumnDouble),
+			godruid.NewVirtualColumn("valueSquare0", "value0 * value0", godruid.VirtualColumnDouble),
+			godruid.NewVirtualColumn("product0", "covariate * value0", godruid.VirtualColumnDouble),
+		},
+		Aggregations: []godruid.Aggregation{
+			godruid.AggFiltered(exposed, godruid.AggCount("Goal user 0")),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum("Goal value total 0", "value0")),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum("Goal value square total 0", "valueSquare0")),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum("Covariate total 0", "covariate")),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum("Covariate square total 0", "covariateSquare")),
+			godruid.AggFiltered(exposed, godruid.AggDoubleSum("Goal value covariate product total 0", "product0")),
+		},
+	}
+	actual := queryGoalValueCovariateGroupBy("ds", t0, t1, t2, "ns", "gid", "fid", 2, []string{"vid"}, filters)
+	assert.Equal(t, expected, actual)
+}
+
 func TestQueryEvaluationTimeseries(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -691,3 +751,91 @@ func TestQueryEvaluationTimeseries(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryEvaluationSegmentTopN(t *testing.T) {
+	t.Parallel()
+	layout := "2006-01-02 15:04:05 -0700 MST"
+	t1, err := time.Parse(layout, "2014-01-17 23:02:03 +0000 UTC")
+	require.NoError(t, err)
+	t2, err := time.Parse(layout, "2014-01-18 23:02:03 +0000 UTC")
+	require.NoError(t, err)
+	filters := []*ecproto.Filter{
+		{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"LAYER_HOLDOUT"}},
+	}
+	expected := &godruid.QueryTopN{
+		QueryType:   godruid.TOPN,
+		DataSource:  godruid.DataSourceTable("ds"),
+		Intervals:   "2014-01-17T23:02/2014-01-18T23:02",
+		Granularity: godruid.GranAll,
+		Filter: godruid.FilterAnd(
+			godruid.FilterSelector("environmentNamespace", "ns"),
+			godruid.FilterSelector("featureId", "fid"),
+			godruid.FilterSelector("featureVersion", int32(2)),
+			godruid.FilterNot(godruid.FilterSelector("reason", "LAYER_HOLDOUT")),
+		),
+		Dimension: godruid.DimDefault("ns.user.data.platform", "ns.user.data.platform"),
+		Threshold: 11,
+		Metric:    ColumnEvaluationUser,
+		Aggregations: []godruid.Aggregation{
+			*godruid.AggRawJson(
+				fmt.Sprintf(`{ "type": "thetaSketch", "name": "%s", "fieldName": "userIdThetaSketch" }`, ColumnEvaluationUser),
+			),
+		},
+	}
+	actual := queryEvaluationSegmentTopN("ds", t1, t2, "ns", "fid", 2, "ns.user.data.platform", filters, 11)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConvToDruidFilters(t *testing.T) {
+	t.Parallel()
+	filters := []*ecproto.Filter{
+		{Key: "f0", Operator: ecproto.Filter_EQUALS, Values: []string{"v0"}},
+		{Key: "f1", Operator: ecproto.Filter_EQUALS, Values: []string{"v0", "v1"}},
+		{Key: "f2", Operator: ecproto.Filter_IN, Values: []string{"v0", "v1"}},
+		{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"LAYER_HOLDOUT", "LAYER_EXCLUDED"}},
+	}
+	expected := []*godruid.Filter{
+		godruid.FilterSelector("f0", "v0"),
+		godruid.FilterSelector("f1", "v0"),
+		godruid.FilterSelector("f1", "v1"),
+		godruid.FilterOr(godruid.FilterSelector("f2", "v0"), godruid.FilterSelector("f2", "v1")),
+		godruid.FilterNot(godruid.FilterSelector("reason", "LAYER_HOLDOUT")),
+		godruid.FilterNot(godruid.FilterSelector("reason", "LAYER_EXCLUDED")),
+	}
+	assert.Equal(t, expected, convToDruidFilters(filters))
+}
+
+func TestQueryGoalGroupByReasonFilters(t *testing.T) {
+	t.Parallel()
+	filters := []*ecproto.Filter{
+		{Key: "f0", Operator: ecproto.Filter_EQUALS, Values: []string{"v0"}},
+		{Key: "reason", Operator: ecproto.Filter_NOT_EQUALS, Values: []string{"LAYER_HOLDOUT", "LAYER_EXCLUDED"}},
+	}
+	query := queryGoalGroupBy("ds", time.Now(), time.Now(), "ns0", "gid", "fid", 1, "", nil, filters)
+	inner := query.DataSource.Query.(*godruid.QueryGroupBy)
+	expected := godruid.FilterAnd(
+		godruid.FilterSelector("environmentNamespace", "ns0"),
+		godruid.FilterSelector("goalId", "gid"),
+		godruid.FilterSelector("f0", "v0"),
+		godruid.FilterRegex("evaluations", "^fid:1:.*$"),
+		godruid.FilterNot(godruid.FilterRegex("evaluations", "^fid:1:.*:LAYER_HOLDOUT$")),
+		godruid.FilterNot(godruid.FilterRegex("evaluations", "^fid:1:.*:LAYER_EXCLUDED$")),
+	)
+	assert.Equal(t, expected, inner.Filter)
+}
+
+func TestQueryGoalGroupByRuleFilters(t *testing.T) {
+	t.Parallel()
+	filters := []*ecproto.Filter{
+		{Key: "ruleId", Operator: ecproto.Filter_EQUALS, Values: []string{"rid"}},
+	}
+	query := queryGoalGroupBy("ds", time.Now(), time.Now(), "ns0", "gid", "fid", 1, "RULE", nil, filters)
+	inner := query.DataSource.Query.(*godruid.QueryGroupBy)
+	expected := godruid.FilterAnd(
+		godruid.FilterSelector("environmentNamespace", "ns0"),
+		godruid.FilterSelector("goalId", "gid"),
+		godruid.FilterRegex("evaluations", "^fid:1:.*:RULE$"),
+		godruid.FilterSelector("ruleEvaluations", "fid:1:rid"),
+	)
+	assert.Equal(t, expected, inner.Filter)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+var (
+	ErrNoArms            = errors.New("stats: no arms")
+	ErrInvalidArm        = errors.New("stats: conversions must be between 0 and trials")
+	ErrInvalidSampleSize = errors.New("stats: sample size must be positive")
+)
+
+// BanditArm is the number of trials and conversions observed for an arm of a bandit.
+type BanditArm struct {
+	Trials      int64
+	Conversions int64
+}
+
+// ThompsonSampling returns the probability of each arm having the highest conversion rate.
+// The conversion rate of each arm follows a Beta(1+conversions, 1+trials-conversions) posterior,
+// and the probabilities are estimated by drawing sampleSize samples from every posterior.
+func ThompsonSampling(arms []BanditArm, sampleSize int, r *rand.Rand) ([]float64, error) {
+	if len(arms) == 0 {
+		return nil, ErrNoArms
+	}
+	if sampleSize <= 0 {
+		return nil, ErrInvalidSampleSize
+	}
+	for _, a := range arms {
+		if a.Conversions < 0 || a.Conversions > a.Trials {
+			return nil, ErrInvalidArm
+		}
+	}
+	wins := make([]int, len(arms))
+	for i := 0; i < sampleSize; i++ {
+		best := 0
+		bestDraw := -1.0
+		for j, a := range arms {
+			draw := betaSample(r, float64(1+a.Conversions), float64(1+a.Trials-a.Conversions))
+			if draw > bestDraw {
+				best = j
+				bestDraw = draw
+			}
+		}
+		wins[best]++
+	}
+	probabilities := make([]float64, len(arms))
+	for i, w := range wins {
+		probabilities[i] = float64(w) / float64(sampleSize)
+	}
+	return probabilities, nil
+}
+
+func betaSample(r *rand.Rand, alpha, beta float64) float64 {
+	x := gammaSample(r, alpha)
+	y := gammaSample(r, beta)
+	return x / (x + y)
+}
+
+// gammaSample draws from Gamma(shape, 1) using the Marsaglia and Tsang method.
+// Shape must be at least 1, which always holds for the Beta posteriors above.
+func gammaSample(r *rand.Rand, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
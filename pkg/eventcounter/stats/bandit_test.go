@@ -0,0 +1,90 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThompsonSampling(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		arms        []BanditArm
+		sampleSize  int
+		expectedErr error
+	}{
+		"err: no arms": {
+			arms:        nil,
+			sampleSize:  100,
+			expectedErr: ErrNoArms,
+		},
+		"err: conversions exceed trials": {
+			arms:        []BanditArm{{Trials: 10, Conversions: 11}},
+			sampleSize:  100,
+			expectedErr: ErrInvalidArm,
+		},
+		"err: invalid sample size": {
+			arms:        []BanditArm{{Trials: 10, Conversions: 1}},
+			sampleSize:  0,
+			expectedErr: ErrInvalidSampleSize,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			_, err := ThompsonSampling(p.arms, p.sampleSize, rand.New(rand.NewSource(1)))
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestThompsonSamplingProbabilities(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	probabilities, err := ThompsonSampling([]BanditArm{
+		{Trials: 1000, Conversions: 100},
+		{Trials: 1000, Conversions: 150},
+		{Trials: 1000, Conversions: 140},
+	}, 10000, r)
+	require.NoError(t, err)
+	require.Len(t, probabilities, 3)
+	assert.InDelta(t, 1, probabilities[0]+probabilities[1]+probabilities[2], 1e-9)
+	assert.Less(t, probabilities[0], 0.01)
+	assert.Greater(t, probabilities[1], probabilities[2])
+
+	probabilities, err = ThompsonSampling([]BanditArm{
+		{Trials: 0, Conversions: 0},
+		{Trials: 0, Conversions: 0},
+	}, 10000, r)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, probabilities[0], 0.03)
+	assert.InDelta(t, 0.5, probabilities[1], 0.03)
+}
+
+func TestGammaSampleMean(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	for _, shape := range []float64{1, 2.5, 50} {
+		sum := 0.0
+		n := 20000
+		for i := 0; i < n; i++ {
+			sum += gammaSample(r, shape)
+		}
+		assert.InDelta(t, shape, sum/float64(n), shape*0.05)
+	}
+}
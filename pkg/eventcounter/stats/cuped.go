@@ -0,0 +1,88 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+// CovariateSums are the sums of a metric and its covariate over the users of a group,
+// so that the group can be summarized without the values of each user.
+type CovariateSums struct {
+	Size               int64
+	Sum                float64
+	SquareSum          float64
+	CovariateSum       float64
+	CovariateSquareSum float64
+	ProductSum         float64
+}
+
+// CovariateSample summarizes a metric and its covariate over the users of a group.
+// The variances and the covariance are unbiased sample estimates.
+type CovariateSample struct {
+	Size              int64
+	Mean              float64
+	Variance          float64
+	CovariateMean     float64
+	CovariateVariance float64
+	Covariance        float64
+}
+
+// NewCovariateSample summarizes the metric and the covariate of the same users by their sums.
+func NewCovariateSample(sums *CovariateSums) (*CovariateSample, error) {
+	if sums.Size < 2 {
+		return nil, ErrInsufficientSample
+	}
+	n := float64(sums.Size)
+	s := &CovariateSample{
+		Size:          sums.Size,
+		Mean:          sums.Sum / n,
+		CovariateMean: sums.CovariateSum / n,
+	}
+	s.Variance = nonNegative(sums.SquareSum-n*s.Mean*s.Mean) / (n - 1)
+	s.CovariateVariance = nonNegative(sums.CovariateSquareSum-n*s.CovariateMean*s.CovariateMean) / (n - 1)
+	s.Covariance = (sums.ProductSum - n*s.Mean*s.CovariateMean) / (n - 1)
+	return s, nil
+}
+
+// nonNegative drops the rounding errors that make a sum of squared deviations negative.
+func nonNegative(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// CUPEDTheta returns the coefficient that minimizes the variance of the adjusted metric.
+// It is pooled over the groups so that every group is adjusted by the same coefficient,
+// which keeps the difference between the adjusted means unbiased.
+func CUPEDTheta(samples []*CovariateSample) (float64, error) {
+	covariance, covariateVariance := 0.0, 0.0
+	for _, s := range samples {
+		covariance += float64(s.Size-1) * s.Covariance
+		covariateVariance += float64(s.Size-1) * s.CovariateVariance
+	}
+	if covariateVariance == 0 {
+		return 0, ErrZeroVariance
+	}
+	return covariance / covariateVariance, nil
+}
+
+// CUPEDAdjust returns the mean and the variance of the metric adjusted by the covariate,
+// where covariateMean is the mean of the covariate over all the groups.
+func CUPEDAdjust(s *CovariateSample, theta, covariateMean float64) (float64, float64) {
+	mean := s.Mean - theta*(s.CovariateMean-covariateMean)
+	variance := s.Variance - 2*theta*s.Covariance + theta*theta*s.CovariateVariance
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, variance
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCovariateSample(t *testing.T) {
+	t.Parallel()
+	_, err := NewCovariateSample(&CovariateSums{Size: 1, Sum: 1, CovariateSum: 1})
+	assert.Equal(t, ErrInsufficientSample, err)
+
+	// The values are 2, 4, 6, 8 and the covariates are 1, 2, 3, 5.
+	s, err := NewCovariateSample(&CovariateSums{
+		Size:               4,
+		Sum:                20,
+		SquareSum:          120,
+		CovariateSum:       11,
+		CovariateSquareSum: 39,
+		ProductSum:         68,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), s.Size)
+	assert.InDelta(t, 5, s.Mean, 1e-9)
+	assert.InDelta(t, 20.0/3, s.Variance, 1e-9)
+	assert.InDelta(t, 2.75, s.CovariateMean, 1e-9)
+	assert.InDelta(t, 8.75/3, s.CovariateVariance, 1e-9)
+	assert.InDelta(t, 13.0/3, s.Covariance, 1e-9)
+}
+
+func TestCUPEDTheta(t *testing.T) {
+	t.Parallel()
+	_, err := CUPEDTheta([]*CovariateSample{{Size: 10, Covariance: 1}})
+	assert.Equal(t, ErrZeroVariance, err)
+
+	theta, err := CUPEDTheta([]*CovariateSample{
+		{Size: 11, Covariance: 2, CovariateVariance: 4},
+		{Size: 31, Covariance: 1, CovariateVariance: 1},
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, (10*2.0+30*1.0)/(10*4.0+30*1.0), theta, 1e-9)
+}
+
+func TestCUPEDAdjust(t *testing.T) {
+	t.Parallel()
+	s := &CovariateSample{
+		Size:              100,
+		Mean:              10,
+		Variance:          4,
+		CovariateMean:     6,
+		CovariateVariance: 4,
+		Covariance:        3,
+	}
+	mean, variance := CUPEDAdjust(s, 0.75, 5)
+	assert.InDelta(t, 9.25, mean, 1e-9)
+	// The correlation is 0.75, so the variance is reduced by 0.75^2.
+	assert.InDelta(t, 4*(1-0.75*0.75), variance, 1e-9)
+
+	// Without a covariate the metric is left as it is.
+	mean, variance = CUPEDAdjust(s, 0, 5)
+	assert.InDelta(t, 10, mean, 1e-9)
+	assert.InDelta(t, 4, variance, 1e-9)
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"math"
+)
+
+const (
+	betaContinuedFractionMaxIterations = 300
+	betaContinuedFractionEpsilon       = 3e-14
+	betaContinuedFractionMin           = 1e-300
+	quantileBisectionIterations        = 200
+	incompleteGammaMaxIterations       = 300
+	incompleteGammaEpsilon             = 3e-14
+)
+
+// NormalCDF returns the cumulative distribution function of the standard normal distribution.
+func NormalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// NormalQuantile returns the inverse of NormalCDF.
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// StudentTCDF returns the cumulative distribution function of Student's t-distribution
+// with df degrees of freedom.
+func StudentTCDF(t, df float64) float64 {
+	if math.IsInf(df, 1) {
+		return NormalCDF(t)
+	}
+	tail := 0.5 * regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// StudentTQuantile returns the inverse of StudentTCDF.
+func StudentTQuantile(p, df float64) float64 {
+	if math.IsInf(df, 1) {
+		return NormalQuantile(p)
+	}
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	if p == 0.5 {
+		return 0
+	}
+	if p < 0.5 {
+		return -StudentTQuantile(1-p, df)
+	}
+	lower, upper := 0.0, 1.0
+	for StudentTCDF(upper, df) < p {
+		lower = upper
+		upper *= 2
+	}
+	for i := 0; i < quantileBisectionIterations; i++ {
+		mid := (lower + upper) / 2
+		if StudentTCDF(mid, df) < p {
+			lower = mid
+		} else {
+			upper = mid
+		}
+	}
+	return (lower + upper) / 2
+}
+
+// ChiSquaredSurvival returns the probability that a chi-squared variable with df degrees of freedom exceeds x.
+func ChiSquaredSurvival(x, df float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return regularizedUpperIncompleteGamma(df/2, x/2)
+}
+
+// regularizedUpperIncompleteGamma returns Q(a, x).
+func regularizedUpperIncompleteGamma(a, x float64) float64 {
+	lga, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lga)
+	// The series converges quickly only for x < a+1, and the continued fraction otherwise.
+	if x < a+1 {
+		ap := a
+		sum := 1 / a
+		del := sum
+		for n := 0; n < incompleteGammaMaxIterations; n++ {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*incompleteGammaEpsilon {
+				break
+			}
+		}
+		return 1 - sum*front
+	}
+	b := x + 1 - a
+	c := 1 / betaContinuedFractionMin
+	d := 1 / b
+	h := d
+	for i := 1; i <= incompleteGammaMaxIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < betaContinuedFractionMin {
+			d = betaContinuedFractionMin
+		}
+		c = b + an/c
+		if math.Abs(c) < betaContinuedFractionMin {
+			c = betaContinuedFractionMin
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < incompleteGammaEpsilon {
+			break
+		}
+	}
+	return front * h
+}
+
+// regularizedIncompleteBeta returns I_x(a, b).
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly only for x < (a+1)/(a+b+2).
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < betaContinuedFractionMin {
+		d = betaContinuedFractionMin
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= betaContinuedFractionMaxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < betaContinuedFractionMin {
+			d = betaContinuedFractionMin
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < betaContinuedFractionMin {
+			c = betaContinuedFractionMin
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < betaContinuedFractionMin {
+			d = betaContinuedFractionMin
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < betaContinuedFractionMin {
+			c = betaContinuedFractionMin
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < betaContinuedFractionEpsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalCDF(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		input    float64
+		expected float64
+	}{
+		"zero":     {input: 0, expected: 0.5},
+		"positive": {input: 1.96, expected: 0.9750021},
+		"negative": {input: -1.0, expected: 0.1586553},
+	}
+	for msg, p := range patterns {
+		assert.InDelta(t, p.expected, NormalCDF(p.input), 1e-6, msg)
+	}
+}
+
+func TestNormalQuantile(t *testing.T) {
+	t.Parallel()
+	assert.InDelta(t, 1.959964, NormalQuantile(0.975), 1e-6)
+	assert.InDelta(t, -1.644854, NormalQuantile(0.05), 1e-6)
+	assert.Equal(t, 0.0, NormalQuantile(0.5))
+}
+
+func TestStudentTCDF(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		inputT   float64
+		inputDF  float64
+		expected float64
+	}{
+		"zero":     {inputT: 0, inputDF: 5, expected: 0.5},
+		"positive": {inputT: 2, inputDF: 10, expected: 0.9633060},
+		"negative": {inputT: -2, inputDF: 10, expected: 0.0366940},
+		"cauchy":   {inputT: 1, inputDF: 1, expected: 0.75},
+		"infinite": {inputT: 1.96, inputDF: math.Inf(1), expected: 0.9750021},
+	}
+	for msg, p := range patterns {
+		assert.InDelta(t, p.expected, StudentTCDF(p.inputT, p.inputDF), 1e-6, msg)
+	}
+}
+
+func TestStudentTQuantile(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		inputP   float64
+		inputDF  float64
+		expected float64
+	}{
+		"df 1":     {inputP: 0.975, inputDF: 1, expected: 12.706205},
+		"df 10":    {inputP: 0.975, inputDF: 10, expected: 2.228139},
+		"df 30":    {inputP: 0.975, inputDF: 30, expected: 2.042272},
+		"lower":    {inputP: 0.025, inputDF: 10, expected: -2.228139},
+		"median":   {inputP: 0.5, inputDF: 10, expected: 0},
+		"infinite": {inputP: 0.975, inputDF: math.Inf(1), expected: 1.959964},
+	}
+	for msg, p := range patterns {
+		assert.InDelta(t, p.expected, StudentTQuantile(p.inputP, p.inputDF), 1e-5, msg)
+	}
+}
+
+func TestChiSquaredSurvival(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		inputX   float64
+		inputDF  float64
+		expected float64
+	}{
+		"zero":            {inputX: 0, inputDF: 1, expected: 1},
+		"df 1":            {inputX: 3.841459, inputDF: 1, expected: 0.05},
+		"df 2":            {inputX: 2, inputDF: 2, expected: math.Exp(-1)},
+		"df 3":            {inputX: 7.814728, inputDF: 3, expected: 0.05},
+		"large statistic": {inputX: 100, inputDF: 2, expected: math.Exp(-50)},
+	}
+	for msg, p := range patterns {
+		assert.InDelta(t, p.expected, ChiSquaredSurvival(p.inputX, p.inputDF), 1e-6, msg)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"errors"
+	"math"
+)
+
+const DefaultConfidenceLevel = 0.95
+
+var (
+	ErrInvalidConfidenceLevel = errors.New("stats: confidence level must be between 0 and 1")
+	ErrInsufficientSample     = errors.New("stats: insufficient sample size")
+	ErrZeroVariance           = errors.New("stats: variance is zero")
+)
+
+// Result is the outcome of comparing a variation against the baseline.
+// DegreesOfFreedom is left at zero for the z-test.
+// Difference is mean - baselineMean, and RelativeLift is Difference / baselineMean.
+type Result struct {
+	BaselineMean      float64
+	Mean              float64
+	Difference        float64
+	DifferenceLower   float64
+	DifferenceUpper   float64
+	RelativeLift      float64
+	RelativeLiftLower float64
+	RelativeLiftUpper float64
+	Statistic         float64
+	DegreesOfFreedom  float64
+	PValue            float64
+	ConfidenceLevel   float64
+}
+
+// TwoProportionZTest compares the conversion rate of a variation against the baseline.
+// The test statistic uses the pooled standard error and the confidence interval uses the unpooled one.
+func TwoProportionZTest(
+	baselineConversions, baselineTotal, conversions, total int64,
+	confidenceLevel float64,
+) (*Result, error) {
+	if err := validateConfidenceLevel(confidenceLevel); err != nil {
+		return nil, err
+	}
+	if baselineTotal <= 0 || total <= 0 {
+		return nil, ErrInsufficientSample
+	}
+	n1, n2 := float64(baselineTotal), float64(total)
+	p1, p2 := float64(baselineConversions)/n1, float64(conversions)/n2
+	pooled := float64(baselineConversions+conversions) / (n1 + n2)
+	pooledSE := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if pooledSE == 0 {
+		return nil, ErrZeroVariance
+	}
+	z := (p2 - p1) / pooledSE
+	critical := NormalQuantile(1 - (1-confidenceLevel)/2)
+	result := newResult(
+		p1, p1*(1-p1)/n1,
+		p2, p2*(1-p2)/n2,
+		critical,
+		confidenceLevel,
+	)
+	result.Statistic = z
+	result.PValue = 2 * NormalCDF(-math.Abs(z))
+	return result, nil
+}
+
+// OneSidedTwoProportionZTest tests whether the conversion rate of a variation is greater
+// or less than the baseline, depending on greater.
+// It returns the z statistic using the pooled standard error and the one-sided p-value.
+func OneSidedTwoProportionZTest(
+	baselineConversions, baselineTotal, conversions, total int64,
+	greater bool,
+) (float64, float64, error) {
+	if baselineTotal <= 0 || total <= 0 {
+		return 0, 0, ErrInsufficientSample
+	}
+	n1, n2 := float64(baselineTotal), float64(total)
+	p1, p2 := float64(baselineConversions)/n1, float64(conversions)/n2
+	pooled := float64(baselineConversions+conversions) / (n1 + n2)
+	pooledSE := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if pooledSE == 0 {
+		return 0, 0, ErrZeroVariance
+	}
+	z := (p2 - p1) / pooledSE
+	if greater {
+		return z, NormalCDF(-z), nil
+	}
+	return z, NormalCDF(z), nil
+}
+
+// WelchTTest compares the mean of a variation against the baseline without assuming equal variances.
+// The variances are the unbiased sample variances of each group.
+func WelchTTest(
+	baselineMean, baselineVariance float64, baselineSize int64,
+	mean, variance float64, size int64,
+	confidenceLevel float64,
+) (*Result, error) {
+	if err := validateConfidenceLevel(confidenceLevel); err != nil {
+		return nil, err
+	}
+	if baselineSize < 2 || size < 2 {
+		return nil, ErrInsufficientSample
+	}
+	n1, n2 := float64(baselineSize), float64(size)
+	v1, v2 := baselineVariance/n1, variance/n2
+	se := math.Sqrt(v1 + v2)
+	if se == 0 {
+		return nil, ErrZeroVariance
+	}
+	// Welch–Satterthwaite equation.
+	df := (v1 + v2) * (v1 + v2) / (v1*v1/(n1-1) + v2*v2/(n2-1))
+	t := (mean - baselineMean) / se
+	critical := StudentTQuantile(1-(1-confidenceLevel)/2, df)
+	result := newResult(baselineMean, v1, mean, v2, critical, confidenceLevel)
+	result.Statistic = t
+	result.DegreesOfFreedom = df
+	result.PValue = 2 * StudentTCDF(-math.Abs(t), df)
+	return result, nil
+}
+
+// newResult fills the difference and the relative lift with their confidence intervals.
+// The relative lift interval is approximated by the delta method and is left at zero
+// when the baseline mean is zero.
+func newResult(
+	baselineMean, baselineMeanVariance, mean, meanVariance, critical, confidenceLevel float64,
+) *Result {
+	diff := mean - baselineMean
+	diffMargin := critical * math.Sqrt(baselineMeanVariance+meanVariance)
+	result := &Result{
+		BaselineMean:    baselineMean,
+		Mean:            mean,
+		Difference:      diff,
+		DifferenceLower: diff - diffMargin,
+		DifferenceUpper: diff + diffMargin,
+		ConfidenceLevel: confidenceLevel,
+	}
+	if baselineMean == 0 {
+		return result
+	}
+	lift := diff / baselineMean
+	liftVariance := meanVariance/(baselineMean*baselineMean) +
+		mean*mean*baselineMeanVariance/math.Pow(baselineMean, 4)
+	liftMargin := critical * math.Sqrt(liftVariance)
+	result.RelativeLift = lift
+	result.RelativeLiftLower = lift - liftMargin
+	result.RelativeLiftUpper = lift + liftMargin
+	return result
+}
+
+func validateConfidenceLevel(confidenceLevel float64) error {
+	if confidenceLevel <= 0 || confidenceLevel >= 1 {
+		return ErrInvalidConfidenceLevel
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoProportionZTest(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		baselineConversions, baselineTotal int64
+		conversions, total                 int64
+		confidenceLevel                    float64
+		expectedErr                        error
+	}{
+		"err: invalid confidence level": {
+			baselineConversions: 100,
+			baselineTotal:       1000,
+			conversions:         130,
+			total:               1000,
+			confidenceLevel:     1,
+			expectedErr:         ErrInvalidConfidenceLevel,
+		},
+		"err: empty baseline": {
+			conversions:     130,
+			total:           1000,
+			confidenceLevel: DefaultConfidenceLevel,
+			expectedErr:     ErrInsufficientSample,
+		},
+		"err: no conversions": {
+			baselineTotal:   1000,
+			total:           1000,
+			confidenceLevel: DefaultConfidenceLevel,
+			expectedErr:     ErrZeroVariance,
+		},
+	}
+	for msg, p := range patterns {
+		_, err := TwoProportionZTest(p.baselineConversions, p.baselineTotal, p.conversions, p.total, p.confidenceLevel)
+		assert.Equal(t, p.expectedErr, err, msg)
+	}
+
+	result, err := TwoProportionZTest(100, 1000, 130, 1000, DefaultConfidenceLevel)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, result.BaselineMean, 1e-9)
+	assert.InDelta(t, 0.13, result.Mean, 1e-9)
+	assert.InDelta(t, 0.03, result.Difference, 1e-9)
+	// SE = sqrt(0.1*0.9/1000 + 0.13*0.87/1000) = 0.0142513
+	assert.InDelta(t, 0.03-1.959964*0.0142513, result.DifferenceLower, 1e-6)
+	assert.InDelta(t, 0.03+1.959964*0.0142513, result.DifferenceUpper, 1e-6)
+	assert.InDelta(t, 0.3, result.RelativeLift, 1e-9)
+	assert.True(t, result.RelativeLiftLower < 0.3 && result.RelativeLiftUpper > 0.3)
+	assert.InDelta(t, 2.102741, result.Statistic, 1e-6)
+	assert.InDelta(t, 0.035488, result.PValue, 1e-6)
+	assert.Equal(t, 0.0, result.DegreesOfFreedom)
+	assert.Equal(t, DefaultConfidenceLevel, result.ConfidenceLevel)
+}
+
+func TestOneSidedTwoProportionZTest(t *testing.T) {
+	t.Parallel()
+	_, _, err := OneSidedTwoProportionZTest(0, 0, 130, 1000, true)
+	assert.Equal(t, ErrInsufficientSample, err)
+	_, _, err = OneSidedTwoProportionZTest(0, 1000, 0, 1000, true)
+	assert.Equal(t, ErrZeroVariance, err)
+
+	z, p, err := OneSidedTwoProportionZTest(100, 1000, 130, 1000, true)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.102741, z, 1e-6)
+	assert.InDelta(t, 0.035488/2, p, 1e-6)
+	z, p, err = OneSidedTwoProportionZTest(100, 1000, 130, 1000, false)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.102741, z, 1e-6)
+	assert.InDelta(t, 1-0.035488/2, p, 1e-6)
+}
+
+func TestWelchTTest(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		baselineMean, baselineVariance float64
+		baselineSize                   int64
+		mean, variance                 float64
+		size                           int64
+		confidenceLevel                float64
+		expectedErr                    error
+	}{
+		"err: invalid confidence level": {
+			baselineMean:     10,
+			baselineVariance: 4,
+			baselineSize:     50,
+			mean:             11,
+			variance:         9,
+			size:             40,
+			confidenceLevel:  0,
+			expectedErr:      ErrInvalidConfidenceLevel,
+		},
+		"err: insufficient sample": {
+			baselineMean:     10,
+			baselineVariance: 4,
+			baselineSize:     1,
+			mean:             11,
+			variance:         9,
+			size:             40,
+			confidenceLevel:  DefaultConfidenceLevel,
+			expectedErr:      ErrInsufficientSample,
+		},
+		"err: zero variance": {
+			baselineMean:    10,
+			baselineSize:    50,
+			mean:            11,
+			size:            40,
+			confidenceLevel: DefaultConfidenceLevel,
+			expectedErr:     ErrZeroVariance,
+		},
+	}
+	for msg, p := range patterns {
+		_, err := WelchTTest(
+			p.baselineMean, p.baselineVariance, p.baselineSize,
+			p.mean, p.variance, p.size,
+			p.confidenceLevel,
+		)
+		assert.Equal(t, p.expectedErr, err, msg)
+	}
+
+	result, err := WelchTTest(10, 4, 50, 11, 9, 40, DefaultConfidenceLevel)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, result.Difference, 1e-9)
+	assert.InDelta(t, 0.1, result.RelativeLift, 1e-9)
+	assert.InDelta(t, 1.810715, result.Statistic, 1e-6)
+	assert.InDelta(t, 65.112134, result.DegreesOfFreedom, 1e-6)
+	assert.InDelta(t, 0.074799, result.PValue, 1e-6)
+	assert.True(t, result.DifferenceLower < 0 && result.DifferenceUpper > 2)
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// HolmBonferroni returns the p-values adjusted by Holm's step-down method
+// in the same order as the given p-values.
+// It controls the family-wise error rate across all the comparisons
+// and is uniformly more powerful than the Bonferroni correction.
+func HolmBonferroni(pValues []float64) []float64 {
+	m := len(pValues)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return pValues[order[i]] < pValues[order[j]] })
+	adjusted := make([]float64, m)
+	var running float64
+	for rank, i := range order {
+		running = math.Max(running, math.Min(1, float64(m-rank)*pValues[i]))
+		adjusted[i] = running
+	}
+	return adjusted
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHolmBonferroni(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		pValues  []float64
+		expected []float64
+	}{
+		"empty": {
+			pValues:  []float64{},
+			expected: []float64{},
+		},
+		"single comparison is not adjusted": {
+			pValues:  []float64{0.03},
+			expected: []float64{0.03},
+		},
+		"adjusted values keep the input order and are monotonic": {
+			pValues:  []float64{0.01, 0.04, 0.03, 0.005},
+			expected: []float64{0.03, 0.06, 0.06, 0.02},
+		},
+		"adjusted values are capped at 1": {
+			pValues:  []float64{0.5, 0.2, 0.9},
+			expected: []float64{1, 0.6, 1},
+		},
+	}
+	for msg, p := range patterns {
+		actual := HolmBonferroni(p.pValues)
+		assert.Len(t, actual, len(p.expected), msg)
+		for i := range p.expected {
+			assert.InDelta(t, p.expected[i], actual[i], 1e-9, msg)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrInvalidBaselineRate     = errors.New("stats: baseline conversion rate must be between 0 and 1")
+	ErrInvalidDetectableEffect = errors.New("stats: minimum detectable effect must be positive and keep the rate below 1")
+	ErrInvalidPower            = errors.New("stats: power must be between 0 and 1")
+	ErrInvalidVariationCount   = errors.New("stats: variation count must be at least 2")
+)
+
+// TwoProportionSampleSize returns the number of users needed per variation to detect
+// a relative lift of minimumDetectableEffect over baselineRate with a two-sided z-test.
+// When there are more than two variations, alpha is split between the comparisons
+// against the baseline with the Bonferroni correction.
+func TwoProportionSampleSize(
+	baselineRate, minimumDetectableEffect, alpha, power float64,
+	variationCount int,
+) (int64, error) {
+	if baselineRate <= 0 || baselineRate >= 1 {
+		return 0, ErrInvalidBaselineRate
+	}
+	p2 := baselineRate * (1 + minimumDetectableEffect)
+	if minimumDetectableEffect <= 0 || p2 >= 1 {
+		return 0, ErrInvalidDetectableEffect
+	}
+	if alpha <= 0 || alpha >= 1 {
+		return 0, ErrInvalidAlpha
+	}
+	if power <= 0 || power >= 1 {
+		return 0, ErrInvalidPower
+	}
+	if variationCount < 2 {
+		return 0, ErrInvalidVariationCount
+	}
+	alpha /= float64(variationCount - 1)
+	p1 := baselineRate
+	pooled := (p1 + p2) / 2
+	zAlpha := NormalQuantile(1 - alpha/2)
+	zBeta := NormalQuantile(power)
+	numerator := zAlpha*math.Sqrt(2*pooled*(1-pooled)) + zBeta*math.Sqrt(p1*(1-p1)+p2*(1-p2))
+	diff := p2 - p1
+	return int64(math.Ceil(numerator * numerator / (diff * diff))), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoProportionSampleSize(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		baselineRate   float64
+		mde            float64
+		alpha          float64
+		power          float64
+		variationCount int
+		expected       int64
+		expectedErr    error
+	}{
+		"err: baseline rate": {
+			baselineRate:   0,
+			mde:            0.1,
+			alpha:          0.05,
+			power:          0.8,
+			variationCount: 2,
+			expectedErr:    ErrInvalidBaselineRate,
+		},
+		"err: detectable effect": {
+			baselineRate:   0.6,
+			mde:            1,
+			alpha:          0.05,
+			power:          0.8,
+			variationCount: 2,
+			expectedErr:    ErrInvalidDetectableEffect,
+		},
+		"err: alpha": {
+			baselineRate:   0.1,
+			mde:            0.1,
+			alpha:          1,
+			power:          0.8,
+			variationCount: 2,
+			expectedErr:    ErrInvalidAlpha,
+		},
+		"err: power": {
+			baselineRate:   0.1,
+			mde:            0.1,
+			alpha:          0.05,
+			power:          0,
+			variationCount: 2,
+			expectedErr:    ErrInvalidPower,
+		},
+		"err: variation count": {
+			baselineRate:   0.1,
+			mde:            0.1,
+			alpha:          0.05,
+			power:          0.8,
+			variationCount: 1,
+			expectedErr:    ErrInvalidVariationCount,
+		},
+		"success: two variations": {
+			baselineRate:   0.1,
+			mde:            0.1,
+			alpha:          0.05,
+			power:          0.8,
+			variationCount: 2,
+			expected:       14751,
+		},
+	}
+	for msg, p := range patterns {
+		p := p
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+			n, err := TwoProportionSampleSize(p.baselineRate, p.mde, p.alpha, p.power, p.variationCount)
+			assert.Equal(t, p.expectedErr, err)
+			assert.Equal(t, p.expected, n)
+		})
+	}
+}
+
+func TestTwoProportionSampleSizeMoreVariations(t *testing.T) {
+	t.Parallel()
+	two, err := TwoProportionSampleSize(0.1, 0.1, 0.05, 0.8, 2)
+	assert.NoError(t, err)
+	three, err := TwoProportionSampleSize(0.1, 0.1, 0.05, 0.8, 3)
+	assert.NoError(t, err)
+	assert.Greater(t, three, two)
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrInvalidAlpha               = errors.New("stats: alpha must be between 0 and 1")
+	ErrInvalidInformationFraction = errors.New("stats: information fraction must be between 0 and 1")
+)
+
+// MSPRTPValue returns the always-valid p-value of the mixture sequential
+// probability ratio test for an observed difference whose estimator has the
+// given variance. The normal mixing distribution over the true difference
+// has mean zero and mixingVariance. The p-value may be checked after every
+// new observation without inflating the false positive rate.
+func MSPRTPValue(difference, variance, mixingVariance float64) (float64, error) {
+	if variance <= 0 || mixingVariance <= 0 {
+		return 0, ErrZeroVariance
+	}
+	total := variance + mixingVariance
+	logLikelihoodRatio := 0.5*math.Log(variance/total) +
+		mixingVariance*difference*difference/(2*variance*total)
+	if logLikelihoodRatio <= 0 {
+		return 1, nil
+	}
+	return math.Exp(-logLikelihoodRatio), nil
+}
+
+// OBrienFlemingBoundary returns the two-sided z-score boundary with the
+// O'Brien-Fleming shape z_{1-alpha/2}/sqrt(t) at the information fraction t.
+// The boundary is very strict early on and converges to the fixed sample
+// boundary when the information fraction reaches 1.
+// This is an approximation, not an alpha spending function: the constant
+// doesn't depend on the number of looks, so the overall false positive rate
+// is slightly above alpha, and the excess grows with the number of looks.
+func OBrienFlemingBoundary(alpha, informationFraction float64) (float64, error) {
+	if alpha <= 0 || alpha >= 1 {
+		return 0, ErrInvalidAlpha
+	}
+	if informationFraction <= 0 || informationFraction > 1 {
+		return 0, ErrInvalidInformationFraction
+	}
+	return NormalQuantile(1-alpha/2) / math.Sqrt(informationFraction), nil
+}
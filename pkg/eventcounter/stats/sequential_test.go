@@ -0,0 +1,114 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMSPRTPValue(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		difference     float64
+		variance       float64
+		mixingVariance float64
+		expected       float64
+		expectedErr    error
+	}{
+		"err: zero variance": {
+			difference:     0.01,
+			variance:       0,
+			mixingVariance: 0.0025,
+			expectedErr:    ErrZeroVariance,
+		},
+		"err: zero mixing variance": {
+			difference:     0.01,
+			variance:       0.0001,
+			mixingVariance: 0,
+			expectedErr:    ErrZeroVariance,
+		},
+		"success: no evidence": {
+			difference:     0.001,
+			variance:       0.0001,
+			mixingVariance: 0.0025,
+			expected:       1,
+		},
+		"success: weak evidence": {
+			difference:     0.02,
+			variance:       0.0001,
+			mixingVariance: 0.0025,
+			expected:       0.745255,
+		},
+		"success: strong evidence": {
+			difference:     0.05,
+			variance:       0.0001,
+			mixingVariance: 0.0025,
+			expected:       0.000031,
+		},
+	}
+	for msg, p := range patterns {
+		pValue, err := MSPRTPValue(p.difference, p.variance, p.mixingVariance)
+		assert.Equal(t, p.expectedErr, err, msg)
+		assert.InDelta(t, p.expected, pValue, 1e-6, msg)
+	}
+}
+
+func TestOBrienFlemingBoundary(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		alpha               float64
+		informationFraction float64
+		expected            float64
+		expectedErr         error
+	}{
+		"err: invalid alpha": {
+			alpha:               1,
+			informationFraction: 0.5,
+			expectedErr:         ErrInvalidAlpha,
+		},
+		"err: zero information fraction": {
+			alpha:               0.05,
+			informationFraction: 0,
+			expectedErr:         ErrInvalidInformationFraction,
+		},
+		"err: information fraction over 1": {
+			alpha:               0.05,
+			informationFraction: 1.1,
+			expectedErr:         ErrInvalidInformationFraction,
+		},
+		"success: full information": {
+			alpha:               0.05,
+			informationFraction: 1,
+			expected:            1.959964,
+		},
+		"success: early look": {
+			alpha:               0.05,
+			informationFraction: 0.25,
+			expected:            3.919928,
+		},
+		"success: halfway with alpha 0.01": {
+			alpha:               0.01,
+			informationFraction: 0.5,
+			expected:            3.642773,
+		},
+	}
+	for msg, p := range patterns {
+		boundary, err := OBrienFlemingBoundary(p.alpha, p.informationFraction)
+		assert.Equal(t, p.expectedErr, err, msg)
+		assert.InDelta(t, p.expected, boundary, 1e-5, msg)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"errors"
+)
+
+var ErrInvalidWeights = errors.New("stats: weights must be positive and match the observed counts")
+
+// SampleRatioMismatchTest runs Pearson's chi-squared goodness of fit test
+// between the observed counts and the split expected from weights.
+// It returns the chi-squared statistic and the p-value.
+func SampleRatioMismatchTest(observed []int64, weights []float64) (float64, float64, error) {
+	if len(observed) < 2 || len(observed) != len(weights) {
+		return 0, 0, ErrInvalidWeights
+	}
+	var total int64
+	var weightSum float64
+	for i := range observed {
+		if weights[i] <= 0 {
+			return 0, 0, ErrInvalidWeights
+		}
+		total += observed[i]
+		weightSum += weights[i]
+	}
+	if total == 0 {
+		return 0, 0, ErrInsufficientSample
+	}
+	var chi2 float64
+	for i := range observed {
+		expected := float64(total) * weights[i] / weightSum
+		diff := float64(observed[i]) - expected
+		chi2 += diff * diff / expected
+	}
+	return chi2, ChiSquaredSurvival(chi2, float64(len(observed)-1)), nil
+}
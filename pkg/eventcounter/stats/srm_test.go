@@ -0,0 +1,71 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleRatioMismatchTest(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		observed       []int64
+		weights        []float64
+		expectedChi2   float64
+		expectedPValue float64
+		expectedErr    error
+	}{
+		"err: single variation": {
+			observed:    []int64{100},
+			weights:     []float64{1},
+			expectedErr: ErrInvalidWeights,
+		},
+		"err: length mismatch": {
+			observed:    []int64{100, 100},
+			weights:     []float64{1},
+			expectedErr: ErrInvalidWeights,
+		},
+		"err: zero weight": {
+			observed:    []int64{100, 100},
+			weights:     []float64{1, 0},
+			expectedErr: ErrInvalidWeights,
+		},
+		"err: no users": {
+			observed:    []int64{0, 0},
+			weights:     []float64{1, 1},
+			expectedErr: ErrInsufficientSample,
+		},
+		"success: even split": {
+			observed:       []int64{5000, 5200},
+			weights:        []float64{50000, 50000},
+			expectedChi2:   3.921569,
+			expectedPValue: 0.047670,
+		},
+		"success: uneven split": {
+			observed:       []int64{1000, 2100, 2900},
+			weights:        []float64{1, 2, 3},
+			expectedChi2:   8.333333,
+			expectedPValue: 0.015504,
+		},
+	}
+	for msg, p := range patterns {
+		chi2, pValue, err := SampleRatioMismatchTest(p.observed, p.weights)
+		assert.Equal(t, p.expectedErr, err, msg)
+		assert.InDelta(t, p.expectedChi2, chi2, 1e-6, msg)
+		assert.InDelta(t, p.expectedPValue, pValue, 1e-6, msg)
+	}
+}
@@ -24,10 +24,19 @@ import (
 	proto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
 )
 
-var ErrExperimentResultNotFound = errors.New("experimentResult: experiment result not found")
+var (
+	ErrExperimentResultNotFound               = errors.New("experimentResult: experiment result not found")
+	ErrExperimentResultUnexpectedAffectedRows = errors.New("experimentResult: unexpected affected rows")
+)
 
 type ExperimentResultStorage interface {
 	GetExperimentResult(ctx context.Context, id, environmentNamespace string) (*domain.ExperimentResult, error)
+	UpdateSampleRatioMismatch(
+		ctx context.Context,
+		id, environmentNamespace string,
+		pValue float64,
+		checkedAt int64,
+	) error
 }
 
 type experimentResultStorage struct {
@@ -49,7 +58,9 @@ func (s *experimentResultStorage) GetExperimentResult(
 			id,
 			experiment_id,
 			updated_at,
-			data
+			data,
+			srm_p_value,
+			srm_checked_at
 		FROM
 			experiment_result
 		WHERE
@@ -66,6 +77,8 @@ func (s *experimentResultStorage) GetExperimentResult(
 		&er.ExperimentId,
 		&er.UpdatedAt,
 		&mysql.JSONPBObject{Val: &er_for_goal_results},
+		&er.SrmPValue,
+		&er.SrmCheckedAt,
 	)
 	if err != nil {
 		if err == mysql.ErrNoRows {
@@ -76,3 +89,40 @@ func (s *experimentResultStorage) GetExperimentResult(
 	er.GoalResults = er_for_goal_results.GoalResults
 	return &domain.ExperimentResult{ExperimentResult: &er}, nil
 }
+
+func (s *experimentResultStorage) UpdateSampleRatioMismatch(
+	ctx context.Context,
+	id, environmentNamespace string,
+	pValue float64,
+	checkedAt int64,
+) error {
+	query := `
+		UPDATE
+			experiment_result
+		SET
+			srm_p_value = ?,
+			srm_checked_at = ?
+		WHERE
+			id = ? AND
+			environment_namespace = ?
+	`
+	result, err := s.qe.ExecContext(
+		ctx,
+		query,
+		pValue,
+		checkedAt,
+		id,
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return ErrExperimentResultUnexpectedAffectedRows
+	}
+	return nil
+}
@@ -94,6 +94,55 @@ func TestGetExperimentResult(t *testing.T) {
 	}
 }
 
+func TestUpdateSampleRatioMismatch(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	patterns := map[string]struct {
+		setup       func(*experimentResultStorage)
+		expectedErr error
+	}{
+		"ErrExperimentResultUnexpectedAffectedRows": {
+			setup: func(s *experimentResultStorage) {
+				result := mock.NewMockResult(mockController)
+				result.EXPECT().RowsAffected().Return(int64(0), nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+			},
+			expectedErr: ErrExperimentResultUnexpectedAffectedRows,
+		},
+		"Error": {
+			setup: func(s *experimentResultStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			expectedErr: errors.New("error"),
+		},
+		"Success": {
+			setup: func(s *experimentResultStorage) {
+				result := mock.NewMockResult(mockController)
+				result.EXPECT().RowsAffected().Return(int64(1), nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			storage := newExperimentResultStorageWithMock(t, mockController)
+			if p.setup != nil {
+				p.setup(storage)
+			}
+			err := storage.UpdateSampleRatioMismatch(context.Background(), "id-0", "ns", 0.01, 1)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
 func newExperimentResultStorageWithMock(t *testing.T, mockController *gomock.Controller) *experimentResultStorage {
 	t.Helper()
 	return &experimentResultStorage{mock.NewMockQueryExecer(mockController)}
@@ -50,3 +50,17 @@ func (mr *MockExperimentResultStorageMockRecorder) GetExperimentResult(ctx, id,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperimentResult", reflect.TypeOf((*MockExperimentResultStorage)(nil).GetExperimentResult), ctx, id, environmentNamespace)
 }
+
+// UpdateSampleRatioMismatch mocks base method.
+func (m *MockExperimentResultStorage) UpdateSampleRatioMismatch(ctx context.Context, id, environmentNamespace string, pValue float64, checkedAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSampleRatioMismatch", ctx, id, environmentNamespace, pValue, checkedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSampleRatioMismatch indicates an expected call of UpdateSampleRatioMismatch.
+func (mr *MockExperimentResultStorageMockRecorder) UpdateSampleRatioMismatch(ctx, id, environmentNamespace, pValue, checkedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSampleRatioMismatch", reflect.TypeOf((*MockExperimentResultStorage)(nil).UpdateSampleRatioMismatch), ctx, id, environmentNamespace, pValue, checkedAt)
+}
@@ -349,6 +349,9 @@ func (p *Persister) marshalEvaluationEvent(
 	m["variationId"] = e.VariationId
 	if e.Reason != nil {
 		m["reason"] = e.Reason.Type.String()
+		if e.Reason.RuleId != "" {
+			m["ruleId"] = e.Reason.RuleId
+		}
 	}
 	if e.User != nil {
 		for k, v := range e.User.Data {
@@ -382,16 +385,27 @@ func (p *Persister) marshalGoalEvent(e *eventproto.GoalEvent, environmentNamespa
 		return "", retriable, err
 	}
 	evaluations := []string{}
+	// The rules are kept apart from the evaluations, so that the format of the evaluations doesn't change.
+	ruleEvaluations := []string{}
 	for _, eval := range ue {
 		reason := ""
 		if eval.Reason != nil {
 			reason = eval.Reason.Type.String()
+			if eval.Reason.RuleId != "" {
+				ruleEvaluations = append(
+					ruleEvaluations,
+					fmt.Sprintf("%s:%d:%s", eval.FeatureId, eval.FeatureVersion, eval.Reason.RuleId),
+				)
+			}
 		}
 		evaluations = append(
 			evaluations,
 			fmt.Sprintf("%s:%d:%s:%s", eval.FeatureId, eval.FeatureVersion, eval.VariationId, reason),
 		)
 	}
+	if len(ruleEvaluations) > 0 {
+		m["ruleEvaluations"] = ruleEvaluations
+	}
 	if len(evaluations) == 0 {
 		p.logger.Warn(
 			"Goal event has no evaluations",
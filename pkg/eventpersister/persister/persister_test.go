@@ -103,6 +103,33 @@ func TestMarshaEvent(t *testing.T) {
 			expectedErr:        nil,
 			expectedRepeatable: false,
 		},
+		"success evaluation event: served by a rule": {
+			setup: nil,
+			input: &eventproto.EvaluationEvent{
+				Tag:            "tag",
+				Timestamp:      t1.Unix(),
+				FeatureId:      "fid",
+				FeatureVersion: int32(1),
+				UserId:         "uid",
+				VariationId:    "vid",
+				Reason:         &featureproto.Reason{Type: featureproto.Reason_RULE, RuleId: "rid"},
+			},
+			expected: `{
+				"environmentNamespace":"ns",
+				"featureId": "fid",
+				"featureVersion": "1",
+				"metric.userId": "uid",
+				"reason":"RULE",
+				"ruleId":"rid",
+				"sourceId":"UNKNOWN",
+				"tag":"tag",
+				"timestamp":"2014-01-17T23:02:03Z",
+				"userId":"uid",
+				"variationId":"vid"
+			}`,
+			expectedErr:        nil,
+			expectedRepeatable: false,
+		},
 		"err goal batch event: internal error from bigtable": {
 			setup: func(ctx context.Context, p *Persister) {
 				p.userEvaluationStorage.(*ftmock.MockUserEvaluationsStorage).EXPECT().GetUserEvaluations(
@@ -179,6 +206,74 @@ func TestMarshaEvent(t *testing.T) {
 			expectedErr:        nil,
 			expectedRepeatable: false,
 		},
+		"success goal batch event: keeping users who are not in the experiment": {
+			setup: func(ctx context.Context, p *Persister) {
+				p.userEvaluationStorage.(*ftmock.MockUserEvaluationsStorage).EXPECT().GetUserEvaluations(
+					ctx,
+					"uid",
+					"ns",
+					"tag",
+				).Return([]*featureproto.Evaluation{
+					{
+						FeatureId:      "fid-0",
+						FeatureVersion: int32(0),
+						VariationId:    "vid-0",
+						Reason:         &featureproto.Reason{Type: featureproto.Reason_EXPERIMENT_NOT_ALLOCATED},
+					},
+					{
+						FeatureId:      "fid-1",
+						FeatureVersion: int32(1),
+						VariationId:    "vid-1",
+						Reason:         &featureproto.Reason{Type: featureproto.Reason_LAYER_HOLDOUT},
+					},
+					{
+						FeatureId:      "fid-2",
+						FeatureVersion: int32(2),
+						VariationId:    "vid-2",
+						Reason:         &featureproto.Reason{Type: featureproto.Reason_DEFAULT},
+					},
+					{
+						FeatureId:      "fid-3",
+						FeatureVersion: int32(3),
+						VariationId:    "vid-3",
+						Reason:         &featureproto.Reason{Type: featureproto.Reason_RULE, RuleId: "rid-3"},
+					},
+				}, nil).Times(1)
+			},
+			input: &eventproto.GoalEvent{
+				SourceId:  eventproto.SourceId_GOAL_BATCH,
+				Timestamp: t1.Unix(),
+				GoalId:    "gid",
+				UserId:    "uid",
+				User: &userproto.User{
+					Id:   "uid",
+					Data: map[string]string{"atr": "av"},
+				},
+				Value:       float64(1.2),
+				Evaluations: nil,
+				Tag:         "tag",
+			},
+			expected: `{
+				"environmentNamespace": "ns",
+				"evaluations": [
+					"fid-0:0:vid-0:EXPERIMENT_NOT_ALLOCATED",
+					"fid-1:1:vid-1:LAYER_HOLDOUT",
+					"fid-2:2:vid-2:DEFAULT",
+					"fid-3:3:vid-3:RULE"
+				],
+				"goalId": "gid",
+				"metric.userId": "uid",
+				"ns.user.data.atr":"av",
+				"ruleEvaluations": ["fid-3:3:rid-3"],
+				"sourceId":"GOAL_BATCH",
+				"tag": "tag",
+				"timestamp": "2014-01-17T23:02:03Z",
+				"userId":"uid",
+				"value": "1.2"
+			}`,
+			expectedErr:        nil,
+			expectedRepeatable: false,
+		},
 		"success goal batch event: getting evaluations from evaluate process with segment users": {
 			setup: func(ctx context.Context, p *Persister) {
 				p.userEvaluationStorage.(*ftmock.MockUserEvaluationsStorage).EXPECT().GetUserEvaluations(
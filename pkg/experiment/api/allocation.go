@@ -0,0 +1,154 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	v2es "github.com/bucketeer-io/bucketeer/pkg/experiment/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// experimentAllocationCommand makes the feature serve the base variation
+// to users outside the experiment's audience or traffic allocation.
+func experimentAllocationCommand(experiment *domain.Experiment) pb.Message {
+	return &featureproto.SetExperimentAllocationCommand{
+		ExperimentAllocation: &featureproto.ExperimentAllocation{
+			ExperimentId:       experiment.Id,
+			TrafficAllocation:  experiment.TrafficAllocation,
+			Audience:           experiment.Audience,
+			ControlVariationId: experiment.BaseVariationId,
+		},
+	}
+}
+
+// releaseFeature removes the feature's layer assignment and traffic allocation
+// once the experiment no longer runs on it.
+func (s *experimentService) releaseFeature(
+	ctx context.Context,
+	experimentID, environmentNamespace string,
+) error {
+	experimentStorage := v2es.NewExperimentStorage(s.mysqlClient)
+	experiment, err := experimentStorage.GetExperiment(ctx, experimentID, environmentNamespace)
+	if err != nil {
+		s.logger.Error(
+			"Failed to get experiment",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	cmds := []pb.Message{}
+	if experiment.LayerId != "" {
+		cmds = append(cmds, &featureproto.RemoveLayerAssignmentCommand{ExperimentId: experiment.Id})
+	}
+	if experiment.IsTrafficLimited() {
+		cmds = append(cmds, &featureproto.RemoveExperimentAllocationCommand{ExperimentId: experiment.Id})
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return s.updateFeatureTargeting(ctx, experiment.FeatureId, environmentNamespace, cmds...)
+}
+
+// revertFeatureTargeting undoes the feature updates made for an experiment that failed to be created,
+// so the feature doesn't keep serving a layer slice or a traffic allocation of a missing experiment.
+func (s *experimentService) revertFeatureTargeting(
+	ctx context.Context,
+	experiment *domain.Experiment,
+	environmentNamespace string,
+	cmds []pb.Message,
+) {
+	if len(cmds) == 0 {
+		return
+	}
+	if err := s.updateFeatureTargeting(ctx, experiment.FeatureId, environmentNamespace, cmds...); err != nil {
+		s.logger.Error(
+			"Failed to revert the feature's targeting of the experiment that failed to be created",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("experimentId", experiment.Id),
+			)...,
+		)
+	}
+}
+
+// refreshFeatureVersion makes the experiment count the evaluations of the feature's current version,
+// since every targeting update increments it.
+func (s *experimentService) refreshFeatureVersion(
+	ctx context.Context,
+	experiment *domain.Experiment,
+	environmentNamespace string,
+) error {
+	resp, err := s.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		Id:                   experiment.FeatureId,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to get feature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("featureId", experiment.FeatureId),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	experiment.SetFeatureVersion(resp.Feature.Version)
+	return nil
+}
+
+func (s *experimentService) updateFeatureTargeting(
+	ctx context.Context,
+	featureID, environmentNamespace string,
+	cmds ...pb.Message,
+) error {
+	commands := make([]*featureproto.Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		c, err := ptypes.MarshalAny(cmd)
+		if err != nil {
+			return localizedError(statusInternal, locale.JaJP)
+		}
+		commands = append(commands, &featureproto.Command{Command: c})
+	}
+	_, err := s.featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+		Id:                   featureID,
+		EnvironmentNamespace: environmentNamespace,
+		Commands:             commands,
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to update the feature's targeting",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("featureId", featureID),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestCreateExperimentWithTrafficAllocationMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	expectGoal := func(s *experimentService) {
+		row := mysqlmock.NewMockRow(mockController)
+		row.EXPECT().Scan(gomock.Any()).Return(nil)
+		s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(row)
+	}
+	country := &featureproto.Clause{
+		Attribute: "country",
+		Operator:  featureproto.Clause_EQUALS,
+		Values:    []string{"jp"},
+	}
+	patterns := []struct {
+		desc              string
+		setup             func(s *experimentService)
+		trafficAllocation int32
+		audience          []*featureproto.Clause
+		baseVariationID   string
+		expectedErr       error
+	}{
+		{
+			desc:              "err: negative traffic allocation",
+			trafficAllocation: -1,
+			baseVariationID:   "vid",
+			expectedErr:       errInvalidTrafficAllocationJaJP,
+		},
+		{
+			desc:              "err: traffic allocation exceeds 100%",
+			trafficAllocation: domain.TotalTrafficAllocation + 1,
+			baseVariationID:   "vid",
+			expectedErr:       errInvalidTrafficAllocationJaJP,
+		},
+		{
+			desc:            "err: audience clause without values",
+			audience:        []*featureproto.Clause{{Attribute: "country"}},
+			baseVariationID: "vid",
+			expectedErr:     errInvalidAudienceJaJP,
+		},
+		{
+			desc:              "err: base variation required",
+			setup:             expectGoal,
+			trafficAllocation: 20000,
+			expectedErr:       errBaseVariationRequiredJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := mysqlmock.NewMockTransaction(mockController)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(tx, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(_ context.Context, _ mysql.Transaction, f func() error) error {
+					return f()
+				})
+				tx.EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+				// The feature version is incremented by the traffic allocation.
+				featureClient := featureclientmock.NewMockClient(mockController)
+				s.featureClient = featureClient
+				gomock.InOrder(
+					featureClient.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						&featureproto.GetFeatureResponse{Feature: &featureproto.Feature{Id: "fid", Version: 1}}, nil,
+					),
+					featureClient.EXPECT().UpdateFeatureTargeting(gomock.Any(), gomock.Any()).DoAndReturn(func(
+						_ context.Context,
+						req *featureproto.UpdateFeatureTargetingRequest,
+						_ ...grpc.CallOption,
+					) (*featureproto.UpdateFeatureTargetingResponse, error) {
+						assert.Equal(t, "fid", req.Id)
+						assert.Equal(t, "ns0", req.EnvironmentNamespace)
+						require.Len(t, req.Commands, 1)
+						cmd := &featureproto.SetExperimentAllocationCommand{}
+						require.NoError(t, ptypes.UnmarshalAny(req.Commands[0].Command, cmd))
+						assert.Equal(t, "vid", cmd.ExperimentAllocation.ControlVariationId)
+						return &featureproto.UpdateFeatureTargetingResponse{}, nil
+					}),
+					featureClient.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+						&featureproto.GetFeatureResponse{Feature: &featureproto.Feature{Id: "fid", Version: 2}}, nil,
+					),
+				)
+			},
+			trafficAllocation: 20000,
+			audience:          []*featureproto.Clause{country},
+			baseVariationID:   "vid",
+			expectedErr:       nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createExperimentService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			req := &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId:         "fid",
+					GoalIds:           []string{"goalId"},
+					StartAt:           1,
+					StopAt:            10,
+					BaseVariationId:   p.baseVariationID,
+					TrafficAllocation: p.trafficAllocation,
+					Audience:          p.audience,
+				},
+				EnvironmentNamespace: "ns0",
+			}
+			resp, err := service.CreateExperiment(createContextWithToken(), req)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.Equal(t, p.trafficAllocation, resp.Experiment.TrafficAllocation)
+				assert.Equal(t, p.audience, resp.Experiment.Audience)
+				assert.Equal(t, int32(2), resp.Experiment.FeatureVersion)
+			}
+		})
+	}
+}
+
+func TestExperimentAllocationCommand(t *testing.T) {
+	t.Parallel()
+	experiment := &domain.Experiment{Experiment: &experimentproto.Experiment{
+		Id:              "eid",
+		FeatureId:       "fid",
+		BaseVariationId: "vid",
+	}}
+	audience := []*featureproto.Clause{
+		{Attribute: "country", Operator: featureproto.Clause_EQUALS, Values: []string{"jp"}},
+	}
+	experiment.LimitTraffic(20000, audience)
+	cmd, ok := experimentAllocationCommand(experiment).(*featureproto.SetExperimentAllocationCommand)
+	require.True(t, ok)
+	assert.Equal(t, "eid", cmd.ExperimentAllocation.ExperimentId)
+	assert.Equal(t, int32(20000), cmd.ExperimentAllocation.TrafficAllocation)
+	assert.Equal(t, "vid", cmd.ExperimentAllocation.ControlVariationId)
+	assert.Len(t, cmd.ExperimentAllocation.Audience, 1)
+}
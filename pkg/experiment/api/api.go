@@ -23,6 +23,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	accountclient "github.com/bucketeer-io/bucketeer/pkg/account/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/log"
@@ -48,17 +49,19 @@ func WithLogger(l *zap.Logger) Option {
 }
 
 type experimentService struct {
-	featureClient featureclient.Client
-	accountClient accountclient.Client
-	mysqlClient   mysql.Client
-	publisher     publisher.Publisher
-	opts          *options
-	logger        *zap.Logger
+	featureClient      featureclient.Client
+	accountClient      accountclient.Client
+	eventCounterClient ecclient.Client
+	mysqlClient        mysql.Client
+	publisher          publisher.Publisher
+	opts               *options
+	logger             *zap.Logger
 }
 
 func NewExperimentService(
 	featureClient featureclient.Client,
 	accountClient accountclient.Client,
+	eventCounterClient ecclient.Client,
 	mysqlClient mysql.Client,
 	publisher publisher.Publisher,
 	opts ...Option,
@@ -70,12 +73,13 @@ func NewExperimentService(
 		opt(dopts)
 	}
 	return &experimentService{
-		featureClient: featureClient,
-		accountClient: accountClient,
-		mysqlClient:   mysqlClient,
-		publisher:     publisher,
-		opts:          dopts,
-		logger:        dopts.logger.Named("api"),
+		featureClient:      featureClient,
+		accountClient:      accountClient,
+		eventCounterClient: eventCounterClient,
+		mysqlClient:        mysqlClient,
+		publisher:          publisher,
+		opts:               dopts,
+		logger:             dopts.logger.Named("api"),
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	"go.uber.org/zap"
 
 	accountclientmock "github.com/bucketeer-io/bucketeer/pkg/account/client/mock"
+	eventcounterclientmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
 	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
 	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc"
@@ -40,12 +41,14 @@ func TestNewExperimentService(t *testing.T) {
 	defer mockController.Finish()
 	featureClientMock := featureclientmock.NewMockClient(mockController)
 	accountClientMock := accountclientmock.NewMockClient(mockController)
+	eventCounterClientMock := eventcounterclientmock.NewMockClient(mockController)
 	mysqlClient := mysqlmock.NewMockClient(mockController)
 	p := publishermock.NewMockPublisher(mockController)
 	logger := zap.NewNop()
 	s := NewExperimentService(
 		featureClientMock,
 		accountClientMock,
+		eventCounterClientMock,
 		mysqlClient,
 		p,
 		WithLogger(logger),
@@ -82,7 +85,8 @@ func createExperimentService(c *gomock.Controller, s storage.Client) *experiment
 	mysqlClient := mysqlmock.NewMockClient(c)
 	p := publishermock.NewMockPublisher(c)
 	p.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	es := NewExperimentService(featureClientMock, accountClientMock, mysqlClient, p)
+	eventCounterClientMock := eventcounterclientmock.NewMockClient(c)
+	es := NewExperimentService(featureClientMock, accountClientMock, eventCounterClientMock, mysqlClient, p)
 	return es.(*experimentService)
 }
 
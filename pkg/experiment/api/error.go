@@ -21,28 +21,89 @@ import (
 	"google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
 
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc/status"
 )
 
 var (
-	statusInternal             = gstatus.New(codes.Internal, "experiment: internal")
-	statusInvalidCursor        = gstatus.New(codes.InvalidArgument, "experiment: cursor is invalid")
-	statusNoCommand            = gstatus.New(codes.InvalidArgument, "experiment: must contain at least one command")
-	statusUnknownCommand       = gstatus.New(codes.InvalidArgument, "experiment: unknown command")
-	statusFeatureIDRequired    = gstatus.New(codes.InvalidArgument, "experiment: feature id must be specified")
-	statusExperimentIDRequired = gstatus.New(codes.InvalidArgument, "experiment: experiment id must be specified")
-	statusGoalIDRequired       = gstatus.New(codes.InvalidArgument, "experiment: goal id must be specified")
-	statusInvalidGoalID        = gstatus.New(codes.InvalidArgument, "experiment: invalid goal id")
-	statusGoalNameRequired     = gstatus.New(codes.InvalidArgument, "experiment: goal name must be specified")
-	statusPeriodTooLong        = gstatus.New(codes.InvalidArgument, "experiment: period too long")
-	statusInvalidOrderBy       = gstatus.New(codes.InvalidArgument, "expriment: order_by is invalid")
-	statusNotFound             = gstatus.New(codes.NotFound, "experiment: not found")
-	statusGoalNotFound         = gstatus.New(codes.NotFound, "experiment: goal not found")
-	statusFeatureNotFound      = gstatus.New(codes.NotFound, "experiment: feature not found")
-	statusAlreadyExists        = gstatus.New(codes.AlreadyExists, "experiment: already exists")
-	statusUnauthenticated      = gstatus.New(codes.Unauthenticated, "experiment: unauthenticated")
-	statusPermissionDenied     = gstatus.New(codes.PermissionDenied, "experiment: permission denied")
+	statusInternal                 = gstatus.New(codes.Internal, "experiment: internal")
+	statusInvalidCursor            = gstatus.New(codes.InvalidArgument, "experiment: cursor is invalid")
+	statusNoCommand                = gstatus.New(codes.InvalidArgument, "experiment: must contain at least one command")
+	statusUnknownCommand           = gstatus.New(codes.InvalidArgument, "experiment: unknown command")
+	statusFeatureIDRequired        = gstatus.New(codes.InvalidArgument, "experiment: feature id must be specified")
+	statusExperimentIDRequired     = gstatus.New(codes.InvalidArgument, "experiment: experiment id must be specified")
+	statusGoalIDRequired           = gstatus.New(codes.InvalidArgument, "experiment: goal id must be specified")
+	statusInvalidGoalID            = gstatus.New(codes.InvalidArgument, "experiment: invalid goal id")
+	statusGoalNameRequired         = gstatus.New(codes.InvalidArgument, "experiment: goal name must be specified")
+	statusPeriodTooLong            = gstatus.New(codes.InvalidArgument, "experiment: period too long")
+	statusInvalidOrderBy           = gstatus.New(codes.InvalidArgument, "expriment: order_by is invalid")
+	statusInvalidSequentialTesting = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: sequential testing alpha must be between 0 and 1 and goal id must be one of the goals",
+	)
+	statusInvalidGoalConfig = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: goal config must be unique per goal and guardrail must have a threshold",
+	)
+	statusWinnerVariationRequired = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: winner variation must be specified when stopped by sequential test",
+	)
+	statusLayerIDRequired      = gstatus.New(codes.InvalidArgument, "experiment: layer id must be specified")
+	statusLayerNameRequired    = gstatus.New(codes.InvalidArgument, "experiment: layer name must be specified")
+	statusInvalidHoldoutWeight = gstatus.New(
+		codes.InvalidArgument,
+		fmt.Sprintf("experiment: holdout weight must be between 0 and %d", domain.LayerHashSpace-1),
+	)
+	statusInvalidLayerWeight = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: layer weight must be positive and fit in the layer outside the holdout",
+	)
+	statusLayerSliceUnavailable = gstatus.New(
+		codes.FailedPrecondition,
+		"experiment: layer has no free slice large enough for the weight",
+	)
+	statusFeatureAlreadyInLayer = gstatus.New(
+		codes.FailedPrecondition,
+		"experiment: feature is already assigned to a layer",
+	)
+	statusInvalidTrafficAllocation = gstatus.New(
+		codes.InvalidArgument,
+		fmt.Sprintf("experiment: traffic allocation must be between 0 and %d", domain.TotalTrafficAllocation),
+	)
+	statusInvalidAudience = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: audience clause must have an attribute and values",
+	)
+	statusInvalidVarianceReduction = gstatus.New(
+		codes.InvalidArgument,
+		fmt.Sprintf("experiment: pre-experiment period must be between 0 and %d days", domain.MaxPrePeriodDays),
+	)
+	statusInvalidSampleSizeParameters = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: invalid sample size parameters",
+	)
+	statusBaseVariationRequired = gstatus.New(
+		codes.InvalidArgument,
+		"experiment: base variation must be specified to limit the experiment's traffic",
+	)
+	statusFeatureAlreadyAllocated = gstatus.New(
+		codes.FailedPrecondition,
+		"experiment: feature already limits the traffic of another experiment",
+	)
+	statusNoPendingDefaultVariation = gstatus.New(
+		codes.FailedPrecondition,
+		"experiment: no default variation is pending",
+	)
+	statusWinnerVariationNotFound = gstatus.New(codes.NotFound, "experiment: winner variation not found")
+	statusLayerNotFound           = gstatus.New(codes.NotFound, "experiment: layer not found")
+	statusNotFound                = gstatus.New(codes.NotFound, "experiment: not found")
+	statusGoalNotFound            = gstatus.New(codes.NotFound, "experiment: goal not found")
+	statusFeatureNotFound         = gstatus.New(codes.NotFound, "experiment: feature not found")
+	statusAlreadyExists           = gstatus.New(codes.AlreadyExists, "experiment: already exists")
+	statusUnauthenticated         = gstatus.New(codes.Unauthenticated, "experiment: unauthenticated")
+	statusPermissionDenied        = gstatus.New(codes.PermissionDenied, "experiment: permission denied")
 
 	errInternalJaJP = status.MustWithDetails(
 		statusInternal,
@@ -114,6 +175,132 @@ var (
 			Message: fmt.Sprintf("experiment期間は%d日以内で設定してください", maxExperimentPeriodDays),
 		},
 	)
+	errInvalidSequentialTestingJaJP = status.MustWithDetails(
+		statusInvalidSequentialTesting,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "逐次検定のalphaは0より大きく1より小さい値を、goal idはexperimentのgoalを指定してください",
+		},
+	)
+	errInvalidGoalConfigJaJP = status.MustWithDetails(
+		statusInvalidGoalConfig,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "goalの設定はexperimentのgoalごとに1つまでとし、guardrailには閾値を指定してください",
+		},
+	)
+	errWinnerVariationRequiredJaJP = status.MustWithDetails(
+		statusWinnerVariationRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "逐次検定による停止には勝者のvariationが必須です",
+		},
+	)
+	errWinnerVariationNotFoundJaJP = status.MustWithDetails(
+		statusWinnerVariationNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "勝者のvariationが存在しません",
+		},
+	)
+	errLayerIDRequiredJaJP = status.MustWithDetails(
+		statusLayerIDRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layer idは必須です",
+		},
+	)
+	errLayerNameRequiredJaJP = status.MustWithDetails(
+		statusLayerNameRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layer nameは必須です",
+		},
+	)
+	errInvalidHoldoutWeightJaJP = status.MustWithDetails(
+		statusInvalidHoldoutWeight,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: fmt.Sprintf("holdoutのweightは0から%dの間で指定してください", domain.LayerHashSpace-1),
+		},
+	)
+	errInvalidLayerWeightJaJP = status.MustWithDetails(
+		statusInvalidLayerWeight,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layerのweightはholdoutを除いたlayerの範囲内で指定してください",
+		},
+	)
+	errLayerSliceUnavailableJaJP = status.MustWithDetails(
+		statusLayerSliceUnavailable,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layerに指定したweightの空きがありません",
+		},
+	)
+	errFeatureAlreadyInLayerJaJP = status.MustWithDetails(
+		statusFeatureAlreadyInLayer,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "featureはすでにlayerに割り当てられています",
+		},
+	)
+	errInvalidTrafficAllocationJaJP = status.MustWithDetails(
+		statusInvalidTrafficAllocation,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なtraffic allocationです",
+		},
+	)
+	errInvalidAudienceJaJP = status.MustWithDetails(
+		statusInvalidAudience,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なaudienceです",
+		},
+	)
+	errInvalidVarianceReductionJaJP = status.MustWithDetails(
+		statusInvalidVarianceReduction,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なvariance reductionです",
+		},
+	)
+	errInvalidSampleSizeParametersJaJP = status.MustWithDetails(
+		statusInvalidSampleSizeParameters,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なサンプルサイズのパラメータです",
+		},
+	)
+	errBaseVariationRequiredJaJP = status.MustWithDetails(
+		statusBaseVariationRequired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "対象ユーザーを制限する場合、base variationは必須です",
+		},
+	)
+	errFeatureAlreadyAllocatedJaJP = status.MustWithDetails(
+		statusFeatureAlreadyAllocated,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "featureはすでに他のexperimentで対象ユーザーが制限されています",
+		},
+	)
+	errNoPendingDefaultVariationJaJP = status.MustWithDetails(
+		statusNoPendingDefaultVariation,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "featureに反映待ちのデフォルトvariationがありません",
+		},
+	)
+	errLayerNotFoundJaJP = status.MustWithDetails(
+		statusLayerNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "layerが存在しません",
+		},
+	)
 	errInvalidOrderByJaJP = status.MustWithDetails(
 		statusInvalidOrderBy,
 		&errdetails.LocalizedMessage{
@@ -188,6 +375,42 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errGoalNameRequiredJaJP
 	case statusPeriodTooLong:
 		return errPeriodTooLongJaJP
+	case statusInvalidSequentialTesting:
+		return errInvalidSequentialTestingJaJP
+	case statusInvalidGoalConfig:
+		return errInvalidGoalConfigJaJP
+	case statusWinnerVariationRequired:
+		return errWinnerVariationRequiredJaJP
+	case statusWinnerVariationNotFound:
+		return errWinnerVariationNotFoundJaJP
+	case statusLayerIDRequired:
+		return errLayerIDRequiredJaJP
+	case statusLayerNameRequired:
+		return errLayerNameRequiredJaJP
+	case statusInvalidHoldoutWeight:
+		return errInvalidHoldoutWeightJaJP
+	case statusInvalidLayerWeight:
+		return errInvalidLayerWeightJaJP
+	case statusLayerSliceUnavailable:
+		return errLayerSliceUnavailableJaJP
+	case statusFeatureAlreadyInLayer:
+		return errFeatureAlreadyInLayerJaJP
+	case statusInvalidTrafficAllocation:
+		return errInvalidTrafficAllocationJaJP
+	case statusInvalidAudience:
+		return errInvalidAudienceJaJP
+	case statusInvalidVarianceReduction:
+		return errInvalidVarianceReductionJaJP
+	case statusInvalidSampleSizeParameters:
+		return errInvalidSampleSizeParametersJaJP
+	case statusBaseVariationRequired:
+		return errBaseVariationRequiredJaJP
+	case statusFeatureAlreadyAllocated:
+		return errFeatureAlreadyAllocatedJaJP
+	case statusNoPendingDefaultVariation:
+		return errNoPendingDefaultVariationJaJP
+	case statusLayerNotFound:
+		return errLayerNotFoundJaJP
 	case statusInvalidOrderBy:
 		return errInvalidOrderByJaJP
 	case statusNotFound:
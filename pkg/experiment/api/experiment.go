@@ -18,6 +18,7 @@ import (
 	"context"
 	"strconv"
 
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -109,6 +110,9 @@ func (s *experimentService) ListExperiments(
 	if req.Maintainer != "" {
 		whereParts = append(whereParts, mysql.NewFilter("maintainer", "=", req.Maintainer))
 	}
+	if req.LayerId != "" {
+		whereParts = append(whereParts, mysql.NewFilter("layer_id", "=", req.LayerId))
+	}
 	if req.SearchKeyword != "" {
 		whereParts = append(whereParts, mysql.NewSearchQuery([]string{"name", "description"}, req.SearchKeyword))
 	}
@@ -214,6 +218,9 @@ func (s *experimentService) CreateExperiment(
 			return nil, localizedError(statusInternal, locale.JaJP)
 		}
 	}
+	if req.Command.LayerId != "" && resp.Feature.LayerAssignment != nil {
+		return nil, localizedError(statusFeatureAlreadyInLayer, locale.JaJP)
+	}
 	experiment, err := domain.NewExperiment(
 		req.Command.FeatureId,
 		resp.Feature.Version,
@@ -225,6 +232,9 @@ func (s *experimentService) CreateExperiment(
 		req.Command.Description,
 		req.Command.BaseVariationId,
 		editor.Email,
+		req.Command.SequentialTesting,
+		req.Command.GoalConfigs,
+		req.Command.RollbackOnGuardrailBreach,
 	)
 	if err != nil {
 		s.logger.Error(
@@ -236,6 +246,20 @@ func (s *experimentService) CreateExperiment(
 		)
 		return nil, localizedError(statusInternal, locale.JaJP)
 	}
+	experiment.LimitTraffic(req.Command.TrafficAllocation, req.Command.Audience)
+	experiment.ReduceVariance(req.Command.VarianceReduction)
+	if req.Command.SampleSizeEstimate != nil {
+		experiment.SetSampleSizeEstimate(req.Command.SampleSizeEstimate)
+	}
+	if experiment.IsTrafficLimited() || req.Command.LayerId != "" {
+		// Users outside the experiment are served the base variation.
+		if experiment.BaseVariationId == "" {
+			return nil, localizedError(statusBaseVariationRequired, locale.JaJP)
+		}
+	}
+	if experiment.IsTrafficLimited() && resp.Feature.ExperimentAllocation != nil {
+		return nil, localizedError(statusFeatureAlreadyAllocated, locale.JaJP)
+	}
 	tx, err := s.mysqlClient.BeginTx(ctx)
 	if err != nil {
 		s.logger.Error(
@@ -246,8 +270,47 @@ func (s *experimentService) CreateExperiment(
 		)
 		return nil, localizedError(statusInternal, locale.JaJP)
 	}
+	// The feature updates are made in the feature service, so they must be reverted
+	// if the experiment fails to be created.
+	var revertCmds []pb.Message
 	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
-		experimentStorage := v2es.NewExperimentStorage(tx)
+		var layer *domain.Layer
+		var cmds []pb.Message
+		if req.Command.LayerId != "" {
+			// Lock the layer until the experiment is stored, so concurrent requests
+			// can't allocate overlapping slices.
+			var err error
+			layer, err = v2es.NewLayerStorage(tx).GetLayerForUpdate(ctx, req.Command.LayerId, req.EnvironmentNamespace)
+			if err != nil {
+				return err
+			}
+			err = s.allocateLayerSlice(ctx, tx, layer, experiment, req.Command.LayerWeight, req.EnvironmentNamespace)
+			if err != nil {
+				return err
+			}
+			cmds = append(cmds, layerAssignmentCommand(layer, experiment))
+		}
+		if experiment.IsTrafficLimited() {
+			cmds = append(cmds, experimentAllocationCommand(experiment))
+		}
+		// Update the feature before committing, because the feature service rejects
+		// targeting changes once a waiting or running experiment exists.
+		// Every targeting update increments the feature version,
+		// so the commands are sent at once and the version is read after it.
+		if len(cmds) > 0 {
+			if err := s.updateFeatureTargeting(ctx, experiment.FeatureId, req.EnvironmentNamespace, cmds...); err != nil {
+				return err
+			}
+			if layer != nil {
+				revertCmds = append(revertCmds, &featureproto.RemoveLayerAssignmentCommand{ExperimentId: experiment.Id})
+			}
+			if experiment.IsTrafficLimited() {
+				revertCmds = append(revertCmds, &featureproto.RemoveExperimentAllocationCommand{ExperimentId: experiment.Id})
+			}
+			if err := s.refreshFeatureVersion(ctx, experiment, req.EnvironmentNamespace); err != nil {
+				return err
+			}
+		}
 		handler := command.NewExperimentCommandHandler(
 			editor,
 			experiment,
@@ -257,11 +320,20 @@ func (s *experimentService) CreateExperiment(
 		if err := handler.Handle(ctx, req.Command); err != nil {
 			return err
 		}
+		experimentStorage := v2es.NewExperimentStorage(tx)
 		return experimentStorage.CreateExperiment(ctx, experiment, req.EnvironmentNamespace)
 	})
 	if err != nil {
-		if err == v2es.ErrExperimentAlreadyExists {
+		s.revertFeatureTargeting(ctx, experiment, req.EnvironmentNamespace, revertCmds)
+		switch err {
+		case v2es.ErrExperimentAlreadyExists:
 			return nil, localizedError(statusAlreadyExists, locale.JaJP)
+		case v2es.ErrLayerNotFound:
+			return nil, localizedError(statusLayerNotFound, locale.JaJP)
+		case domain.ErrInvalidLayerWeight:
+			return nil, localizedError(statusInvalidLayerWeight, locale.JaJP)
+		case domain.ErrLayerSliceUnavailable:
+			return nil, localizedError(statusLayerSliceUnavailable, locale.JaJP)
 		}
 		s.logger.Error(
 			"Failed to create experiment",
@@ -295,10 +367,86 @@ func validateCreateExperimentRequest(req *proto.CreateExperimentRequest) error {
 	if err := validateExperimentPeriod(req.Command.StartAt, req.Command.StopAt); err != nil {
 		return err
 	}
+	if err := validateSequentialTesting(req.Command.SequentialTesting, req.Command.GoalIds); err != nil {
+		return err
+	}
+	if err := validateGoalConfigs(req.Command.GoalConfigs, req.Command.GoalIds); err != nil {
+		return err
+	}
+	if req.Command.LayerId != "" && (req.Command.LayerWeight <= 0 || req.Command.LayerWeight > domain.LayerHashSpace) {
+		return localizedError(statusInvalidLayerWeight, locale.JaJP)
+	}
+	if err := validateTrafficAllocation(req.Command); err != nil {
+		return err
+	}
+	if err := validateVarianceReduction(req.Command.VarianceReduction); err != nil {
+		return err
+	}
 	// TODO: validate name empty check
 	return nil
 }
 
+func validateTrafficAllocation(cmd *proto.CreateExperimentCommand) error {
+	if cmd.TrafficAllocation < 0 || cmd.TrafficAllocation > domain.TotalTrafficAllocation {
+		return localizedError(statusInvalidTrafficAllocation, locale.JaJP)
+	}
+	for _, c := range cmd.Audience {
+		if c.Attribute == "" || len(c.Values) == 0 {
+			return localizedError(statusInvalidAudience, locale.JaJP)
+		}
+	}
+	return nil
+}
+
+func validateVarianceReduction(vr *proto.VarianceReduction) error {
+	if vr == nil || vr.Method == proto.VarianceReduction_NONE {
+		return nil
+	}
+	if vr.PrePeriodDays < 0 || vr.PrePeriodDays > domain.MaxPrePeriodDays {
+		return localizedError(statusInvalidVarianceReduction, locale.JaJP)
+	}
+	return nil
+}
+
+func validateSequentialTesting(st *proto.SequentialTesting, goalIDs []string) error {
+	if st == nil || st.Method == proto.SequentialTesting_NONE {
+		return nil
+	}
+	if st.Alpha < 0 || st.Alpha >= 1 {
+		return localizedError(statusInvalidSequentialTesting, locale.JaJP)
+	}
+	if st.GoalId != "" && !containsGoalID(goalIDs, st.GoalId) {
+		return localizedError(statusInvalidSequentialTesting, locale.JaJP)
+	}
+	return nil
+}
+
+func validateGoalConfigs(goalConfigs []*proto.GoalConfig, goalIDs []string) error {
+	configured := make(map[string]bool, len(goalConfigs))
+	for _, gc := range goalConfigs {
+		if configured[gc.GoalId] || !containsGoalID(goalIDs, gc.GoalId) {
+			return localizedError(statusInvalidGoalConfig, locale.JaJP)
+		}
+		configured[gc.GoalId] = true
+		if gc.Role != proto.GoalConfig_GUARDRAIL {
+			continue
+		}
+		if gc.Guardrail == nil || gc.Guardrail.Threshold < 0 {
+			return localizedError(statusInvalidGoalConfig, locale.JaJP)
+		}
+	}
+	return nil
+}
+
+func containsGoalID(goalIDs []string, id string) bool {
+	for _, gid := range goalIDs {
+		if gid == id {
+			return true
+		}
+	}
+	return false
+}
+
 func validateExperimentPeriod(startAt, stopAt int64) error {
 	period := stopAt - startAt
 	if period <= 0 || period > int64(maxExperimentPeriod) {
@@ -377,12 +525,27 @@ func (s *experimentService) UpdateExperiment(
 				return err
 			}
 		}
+		if req.ApplyDefaultVariationCommand != nil {
+			if err = handler.Handle(ctx, req.ApplyDefaultVariationCommand); err != nil {
+				s.logger.Error(
+					"Failed to apply default variation",
+					log.FieldsFromImcomingContext(ctx).AddFields(
+						zap.Error(err),
+						zap.String("environmentNamespace", req.EnvironmentNamespace),
+					)...,
+				)
+				return err
+			}
+		}
 		return experimentStorage.UpdateExperiment(ctx, experiment, req.EnvironmentNamespace)
 	})
 	if err != nil {
 		if err == v2es.ErrExperimentNotFound || err == v2es.ErrExperimentUnexpectedAffectedRows {
 			return nil, localizedError(statusNotFound, locale.JaJP)
 		}
+		if err == domain.ErrNoPendingDefaultVariation {
+			return nil, localizedError(statusNoPendingDefaultVariation, locale.JaJP)
+		}
 		s.logger.Error(
 			"Failed to update experiment",
 			log.FieldsFromImcomingContext(ctx).AddFields(
@@ -451,6 +614,9 @@ func (s *experimentService) FinishExperiment(
 	if err := s.updateExperiment(ctx, editor, req.Command, req.Id, req.EnvironmentNamespace); err != nil {
 		return nil, err
 	}
+	if err := s.releaseFeature(ctx, req.Id, req.EnvironmentNamespace); err != nil {
+		return nil, err
+	}
 	return &proto.FinishExperimentResponse{}, nil
 }
 
@@ -478,6 +644,9 @@ func (s *experimentService) StopExperiment(
 	if err := s.updateExperiment(ctx, editor, req.Command, req.Id, req.EnvironmentNamespace); err != nil {
 		return nil, err
 	}
+	if err := s.releaseFeature(ctx, req.Id, req.EnvironmentNamespace); err != nil {
+		return nil, err
+	}
 	return &proto.StopExperimentResponse{}, nil
 }
 
@@ -488,6 +657,9 @@ func validateStopExperimentRequest(req *proto.StopExperimentRequest) error {
 	if req.Command == nil {
 		return localizedError(statusNoCommand, locale.JaJP)
 	}
+	if req.Command.Reason == proto.Experiment_SEQUENTIAL_TEST && req.Command.WinnerVariationId == "" {
+		return localizedError(statusWinnerVariationRequired, locale.JaJP)
+	}
 	return nil
 }
 
@@ -539,6 +711,9 @@ func (s *experimentService) DeleteExperiment(
 	if err := s.updateExperiment(ctx, editor, req.Command, req.Id, req.EnvironmentNamespace); err != nil {
 		return nil, err
 	}
+	if err := s.releaseFeature(ctx, req.Id, req.EnvironmentNamespace); err != nil {
+		return nil, err
+	}
 	return &proto.DeleteExperimentResponse{}, nil
 }
 
@@ -598,6 +773,9 @@ func (s *experimentService) updateExperiment(
 		if err == v2es.ErrExperimentNotFound || err == v2es.ErrExperimentUnexpectedAffectedRows {
 			return localizedError(statusNotFound, locale.JaJP)
 		}
+		if err == domain.ErrWinnerVariationNotFound {
+			return localizedError(statusWinnerVariationNotFound, locale.JaJP)
+		}
 		s.logger.Error(
 			"Failed to update experiment",
 			log.FieldsFromImcomingContext(ctx).AddFields(
@@ -22,6 +22,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
 	v2es "github.com/bucketeer-io/bucketeer/pkg/experiment/storage/v2"
 	storagetesting "github.com/bucketeer-io/bucketeer/pkg/storage/testing"
 	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
@@ -248,6 +249,123 @@ func TestValidateCreateExperimentRequest(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0", "gid1"},
+					StartAt:   1,
+					StopAt:    10,
+					SequentialTesting: &experimentproto.SequentialTesting{
+						Method: experimentproto.SequentialTesting_MSPRT,
+						Alpha:  1.5,
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: errInvalidSequentialTestingJaJP,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0", "gid1"},
+					StartAt:   1,
+					StopAt:    10,
+					SequentialTesting: &experimentproto.SequentialTesting{
+						Method: experimentproto.SequentialTesting_ALPHA_SPENDING,
+						Alpha:  0.05,
+						GoalId: "gid2",
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: errInvalidSequentialTestingJaJP,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0", "gid1"},
+					StartAt:   1,
+					StopAt:    10,
+					GoalConfigs: []*experimentproto.GoalConfig{
+						{GoalId: "gid2", Role: experimentproto.GoalConfig_SECONDARY},
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: errInvalidGoalConfigJaJP,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0", "gid1"},
+					StartAt:   1,
+					StopAt:    10,
+					GoalConfigs: []*experimentproto.GoalConfig{
+						{GoalId: "gid1", Role: experimentproto.GoalConfig_GUARDRAIL},
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: errInvalidGoalConfigJaJP,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0"},
+					StartAt:   1,
+					StopAt:    10,
+					VarianceReduction: &experimentproto.VarianceReduction{
+						Method:        experimentproto.VarianceReduction_CUPED,
+						PrePeriodDays: 91,
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: errInvalidVarianceReductionJaJP,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0"},
+					StartAt:   1,
+					StopAt:    10,
+					VarianceReduction: &experimentproto.VarianceReduction{
+						Method: experimentproto.VarianceReduction_CUPED,
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: nil,
+		},
+		{
+			in: &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId: "fid",
+					GoalIds:   []string{"gid0", "gid1"},
+					StartAt:   1,
+					StopAt:    10,
+					GoalConfigs: []*experimentproto.GoalConfig{
+						{GoalId: "gid0", Role: experimentproto.GoalConfig_PRIMARY},
+						{
+							GoalId: "gid1",
+							Role:   experimentproto.GoalConfig_GUARDRAIL,
+							Guardrail: &experimentproto.Guardrail{
+								Direction: experimentproto.Guardrail_MUST_NOT_INCREASE,
+								Threshold: 0.02,
+							},
+						},
+					},
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expected: nil,
+		},
 	}
 	for _, p := range patterns {
 		err := validateCreateExperimentRequest(p.in)
@@ -311,6 +429,20 @@ func TestUpdateExperimentMySQL(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			setup: func(s *experimentService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(domain.ErrNoPendingDefaultVariation)
+			},
+			req: &experimentproto.UpdateExperimentRequest{
+				Id:                           "id-1",
+				ApplyDefaultVariationCommand: &experimentproto.ApplyDefaultVariationCommand{},
+				EnvironmentNamespace:         "ns0",
+			},
+			expectedErr: errNoPendingDefaultVariationJaJP,
+		},
 	}
 	for _, p := range patterns {
 		ctx := createContextWithToken()
@@ -435,6 +567,11 @@ func TestFinishExperimentMySQL(t *testing.T) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
 					gomock.Any(), gomock.Any(), gomock.Any(),
 				).Return(nil)
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
 			},
 			req: &experimentproto.FinishExperimentRequest{
 				Id:                   "eid",
@@ -482,6 +619,17 @@ func TestStopExperimentMySQL(t *testing.T) {
 			},
 			expectedErr: errNoCommandJaJP,
 		},
+		{
+			setup: nil,
+			req: &experimentproto.StopExperimentRequest{
+				Id: "id-0",
+				Command: &experimentproto.StopExperimentCommand{
+					Reason: experimentproto.Experiment_SEQUENTIAL_TEST,
+				},
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: errWinnerVariationRequiredJaJP,
+		},
 		{
 			setup: func(s *experimentService) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
@@ -502,6 +650,11 @@ func TestStopExperimentMySQL(t *testing.T) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
 					gomock.Any(), gomock.Any(), gomock.Any(),
 				).Return(nil)
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
 			},
 			req: &experimentproto.StopExperimentRequest{
 				Id:                   "id-1",
@@ -632,6 +785,11 @@ func TestDeleteExperimentMySQL(t *testing.T) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
 					gomock.Any(), gomock.Any(), gomock.Any(),
 				).Return(nil)
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
 			},
 			req: &experimentproto.DeleteExperimentRequest{
 				Id:                   "id-1",
@@ -0,0 +1,242 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"strconv"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/command"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	v2es "github.com/bucketeer-io/bucketeer/pkg/experiment/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func (s *experimentService) GetLayer(ctx context.Context, req *proto.GetLayerRequest) (*proto.GetLayerResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, localizedError(statusLayerIDRequired, locale.JaJP)
+	}
+	layer, err := s.getLayerMySQL(ctx, req.Id, req.EnvironmentNamespace)
+	if err != nil {
+		if err == v2es.ErrLayerNotFound {
+			return nil, localizedError(statusNotFound, locale.JaJP)
+		}
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &proto.GetLayerResponse{Layer: layer.Layer}, nil
+}
+
+func (s *experimentService) getLayerMySQL(
+	ctx context.Context,
+	layerID, environmentNamespace string,
+) (*domain.Layer, error) {
+	layerStorage := v2es.NewLayerStorage(s.mysqlClient)
+	layer, err := layerStorage.GetLayer(ctx, layerID, environmentNamespace)
+	if err != nil {
+		s.logger.Error(
+			"Failed to get layer",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("layerId", layerID),
+			)...,
+		)
+	}
+	return layer, err
+}
+
+func (s *experimentService) ListLayers(
+	ctx context.Context,
+	req *proto.ListLayersRequest,
+) (*proto.ListLayersResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	whereParts := []mysql.WherePart{
+		mysql.NewFilter("environment_namespace", "=", req.EnvironmentNamespace),
+	}
+	if req.SearchKeyword != "" {
+		whereParts = append(whereParts, mysql.NewSearchQuery([]string{"id", "name", "description"}, req.SearchKeyword))
+	}
+	orders := []*mysql.Order{mysql.NewOrder("name", mysql.OrderDirectionAsc)}
+	limit := int(req.PageSize)
+	cursor := req.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	offset, err := strconv.Atoi(cursor)
+	if err != nil {
+		return nil, localizedError(statusInvalidCursor, locale.JaJP)
+	}
+	layerStorage := v2es.NewLayerStorage(s.mysqlClient)
+	layers, nextCursor, totalCount, err := layerStorage.ListLayers(ctx, whereParts, orders, limit, offset)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list layers",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &proto.ListLayersResponse{
+		Layers:     layers,
+		Cursor:     strconv.Itoa(nextCursor),
+		TotalCount: totalCount,
+	}, nil
+}
+
+func (s *experimentService) CreateLayer(
+	ctx context.Context,
+	req *proto.CreateLayerRequest,
+) (*proto.CreateLayerResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCreateLayerRequest(req); err != nil {
+		return nil, err
+	}
+	layer, err := domain.NewLayer(req.Command.Name, req.Command.Description, req.Command.HoldoutWeight)
+	if err != nil {
+		s.logger.Error(
+			"Failed to create a new layer",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		layerStorage := v2es.NewLayerStorage(tx)
+		handler := command.NewLayerCommandHandler(editor, layer, s.publisher, req.EnvironmentNamespace)
+		if err := handler.Handle(ctx, req.Command); err != nil {
+			return err
+		}
+		return layerStorage.CreateLayer(ctx, layer, req.EnvironmentNamespace)
+	})
+	if err != nil {
+		if err == v2es.ErrLayerAlreadyExists {
+			return nil, localizedError(statusAlreadyExists, locale.JaJP)
+		}
+		s.logger.Error(
+			"Failed to create layer",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &proto.CreateLayerResponse{Layer: layer.Layer}, nil
+}
+
+func validateCreateLayerRequest(req *proto.CreateLayerRequest) error {
+	if req.Command == nil {
+		return localizedError(statusNoCommand, locale.JaJP)
+	}
+	if req.Command.Name == "" {
+		return localizedError(statusLayerNameRequired, locale.JaJP)
+	}
+	if req.Command.HoldoutWeight < 0 || req.Command.HoldoutWeight >= domain.LayerHashSpace {
+		return localizedError(statusInvalidHoldoutWeight, locale.JaJP)
+	}
+	return nil
+}
+
+// allocateLayerSlice gives the experiment the first free slice of the layer.
+// The layer must be locked by the transaction so concurrent requests can't allocate overlapping slices.
+func (s *experimentService) allocateLayerSlice(
+	ctx context.Context,
+	tx mysql.Transaction,
+	layer *domain.Layer,
+	experiment *domain.Experiment,
+	weight int32,
+	environmentNamespace string,
+) error {
+	experimentStorage := v2es.NewExperimentStorage(tx)
+	experiments, _, _, err := experimentStorage.ListExperiments(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+			mysql.NewFilter("layer_id", "=", layer.Id),
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewInFilter("status", []interface{}{
+				int32(proto.Experiment_WAITING),
+				int32(proto.Experiment_RUNNING),
+			}),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list experiments in the layer",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("layerId", layer.Id),
+			)...,
+		)
+		return err
+	}
+	start, end, err := layer.AllocateSlice(experiments, weight)
+	if err != nil {
+		return err
+	}
+	experiment.AssignLayerSlice(layer.Id, start, end)
+	return nil
+}
+
+// layerAssignmentCommand makes the feature serve the base variation
+// to users outside the experiment's slice of the layer.
+func layerAssignmentCommand(layer *domain.Layer, experiment *domain.Experiment) pb.Message {
+	return &featureproto.SetLayerAssignmentCommand{
+		LayerAssignment: &featureproto.LayerAssignment{
+			LayerId:             layer.Id,
+			ExperimentId:        experiment.Id,
+			HoldoutWeight:       layer.HoldoutWeight,
+			SliceStart:          experiment.LayerSliceStart,
+			SliceEnd:            experiment.LayerSliceEnd,
+			BaselineVariationId: experiment.BaseVariationId,
+		},
+	}
+}
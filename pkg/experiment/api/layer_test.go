@@ -0,0 +1,358 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestGetLayerMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup       func(*experimentService)
+		id          string
+		expectedErr error
+	}{
+		{
+			setup:       nil,
+			id:          "",
+			expectedErr: errLayerIDRequiredJaJP,
+		},
+		{
+			setup: func(s *experimentService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			id:          "id-0",
+			expectedErr: errNotFoundJaJP,
+		},
+		{
+			setup: func(s *experimentService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			id:          "id-1",
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		service := createExperimentService(mockController, nil)
+		if p.setup != nil {
+			p.setup(service)
+		}
+		req := &experimentproto.GetLayerRequest{Id: p.id, EnvironmentNamespace: "ns0"}
+		_, err := service.GetLayer(createContextWithTokenRoleUnassigned(), req)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestCreateLayerMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup       func(s *experimentService)
+		req         *experimentproto.CreateLayerRequest
+		expectedErr error
+	}{
+		{
+			setup: nil,
+			req: &experimentproto.CreateLayerRequest{
+				Command:              nil,
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: errNoCommandJaJP,
+		},
+		{
+			setup: nil,
+			req: &experimentproto.CreateLayerRequest{
+				Command:              &experimentproto.CreateLayerCommand{Name: ""},
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: errLayerNameRequiredJaJP,
+		},
+		{
+			setup: nil,
+			req: &experimentproto.CreateLayerRequest{
+				Command:              &experimentproto.CreateLayerCommand{Name: "name", HoldoutWeight: 100000},
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: errInvalidHoldoutWeightJaJP,
+		},
+		{
+			setup: func(s *experimentService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil)
+			},
+			req: &experimentproto.CreateLayerRequest{
+				Command:              &experimentproto.CreateLayerCommand{Name: "name", HoldoutWeight: 10000},
+				EnvironmentNamespace: "ns0",
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		ctx := createContextWithToken()
+		service := createExperimentService(mockController, nil)
+		if p.setup != nil {
+			p.setup(service)
+		}
+		_, err := service.CreateLayer(ctx, p.req)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestCreateExperimentInLayerMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	expectGoal := func(s *experimentService) {
+		row := mysqlmock.NewMockRow(mockController)
+		row.EXPECT().Scan(gomock.Any()).Return(nil)
+		s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(row)
+	}
+	expectTx := func(s *experimentService) *mysqlmock.MockTransaction {
+		tx := mysqlmock.NewMockTransaction(mockController)
+		s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(tx, nil)
+		s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).DoAndReturn(func(_ context.Context, _ mysql.Transaction, f func() error) error {
+			return f()
+		})
+		return tx
+	}
+	expectLayer := func(tx *mysqlmock.MockTransaction) {
+		layerRow := mysqlmock.NewMockRow(mockController)
+		layerRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			*dest[0].(*string) = "layer-id"
+			return nil
+		})
+		tx.EXPECT().QueryRowContext(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(layerRow)
+		rows := mysqlmock.NewMockRows(mockController)
+		rows.EXPECT().Close().Return(nil)
+		rows.EXPECT().Next().Return(false)
+		rows.EXPECT().Err().Return(nil)
+		tx.EXPECT().QueryContext(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(rows, nil)
+		countRow := mysqlmock.NewMockRow(mockController)
+		countRow.EXPECT().Scan(gomock.Any()).Return(nil)
+		tx.EXPECT().QueryRowContext(
+			gomock.Any(), gomock.Any(), gomock.Any(),
+		).Return(countRow)
+	}
+	// The feature version is incremented once by the layer assignment and the traffic allocation.
+	expectFeatureUpdate := func(s *experimentService, trafficLimited bool) {
+		featureClient := featureclientmock.NewMockClient(mockController)
+		s.featureClient = featureClient
+		gomock.InOrder(
+			featureClient.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+				&featureproto.GetFeatureResponse{Feature: &featureproto.Feature{Id: "fid", Version: 1}}, nil,
+			),
+			featureClient.EXPECT().UpdateFeatureTargeting(gomock.Any(), gomock.Any()).DoAndReturn(func(
+				_ context.Context,
+				req *featureproto.UpdateFeatureTargetingRequest,
+				_ ...grpc.CallOption,
+			) (*featureproto.UpdateFeatureTargetingResponse, error) {
+				cmd := &featureproto.SetLayerAssignmentCommand{}
+				require.NoError(t, ptypes.UnmarshalAny(req.Commands[0].Command, cmd))
+				assert.Equal(t, "layer-id", cmd.LayerAssignment.LayerId)
+				if trafficLimited {
+					require.Len(t, req.Commands, 2)
+					allocation := &featureproto.SetExperimentAllocationCommand{}
+					require.NoError(t, ptypes.UnmarshalAny(req.Commands[1].Command, allocation))
+					assert.Equal(t, int32(20000), allocation.ExperimentAllocation.TrafficAllocation)
+				} else {
+					require.Len(t, req.Commands, 1)
+				}
+				return &featureproto.UpdateFeatureTargetingResponse{}, nil
+			}),
+			featureClient.EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+				&featureproto.GetFeatureResponse{Feature: &featureproto.Feature{Id: "fid", Version: 2}}, nil,
+			),
+		)
+	}
+	patterns := []struct {
+		desc              string
+		setup             func(s *experimentService)
+		layerWeight       int32
+		trafficAllocation int32
+		baseVariationID   string
+		expectedErr       error
+	}{
+		{
+			desc:            "err: invalid weight",
+			layerWeight:     0,
+			baseVariationID: "vid",
+			expectedErr:     errInvalidLayerWeightJaJP,
+		},
+		{
+			desc:        "err: base variation required",
+			setup:       expectGoal,
+			layerWeight: 10000,
+			expectedErr: errBaseVariationRequiredJaJP,
+		},
+		{
+			desc: "err: layer not found",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := expectTx(s)
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				tx.EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			layerWeight:     10000,
+			baseVariationID: "vid",
+			expectedErr:     errLayerNotFoundJaJP,
+		},
+		{
+			desc: "err: failed to list experiments in the layer",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := expectTx(s)
+				layerRow := mysqlmock.NewMockRow(mockController)
+				layerRow.EXPECT().Scan(gomock.Any()).Return(nil)
+				tx.EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(layerRow)
+				tx.EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			layerWeight:     10000,
+			baseVariationID: "vid",
+			expectedErr:     errInternalJaJP,
+		},
+		{
+			desc: "err: failed to store the experiment reverts the layer assignment",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := expectTx(s)
+				expectLayer(tx)
+				expectFeatureUpdate(s, false)
+				tx.EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+				s.featureClient.(*featureclientmock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *featureproto.UpdateFeatureTargetingRequest,
+					_ ...grpc.CallOption,
+				) (*featureproto.UpdateFeatureTargetingResponse, error) {
+					require.Len(t, req.Commands, 1)
+					cmd := &featureproto.RemoveLayerAssignmentCommand{}
+					require.NoError(t, ptypes.UnmarshalAny(req.Commands[0].Command, cmd))
+					assert.NotEmpty(t, cmd.ExperimentId)
+					return &featureproto.UpdateFeatureTargetingResponse{}, nil
+				})
+			},
+			layerWeight:     10000,
+			baseVariationID: "vid",
+			expectedErr:     errInternalJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := expectTx(s)
+				expectLayer(tx)
+				expectFeatureUpdate(s, false)
+				tx.EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			layerWeight:     10000,
+			baseVariationID: "vid",
+			expectedErr:     nil,
+		},
+		{
+			desc: "success: traffic limited",
+			setup: func(s *experimentService) {
+				expectGoal(s)
+				tx := expectTx(s)
+				expectLayer(tx)
+				expectFeatureUpdate(s, true)
+				tx.EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			layerWeight:       10000,
+			trafficAllocation: 20000,
+			baseVariationID:   "vid",
+			expectedErr:       nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createExperimentService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			req := &experimentproto.CreateExperimentRequest{
+				Command: &experimentproto.CreateExperimentCommand{
+					FeatureId:         "fid",
+					GoalIds:           []string{"goalId"},
+					StartAt:           1,
+					StopAt:            10,
+					BaseVariationId:   p.baseVariationID,
+					LayerId:           "layer-id",
+					LayerWeight:       p.layerWeight,
+					TrafficAllocation: p.trafficAllocation,
+				},
+				EnvironmentNamespace: "ns0",
+			}
+			resp, err := service.CreateExperiment(createContextWithToken(), req)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.Equal(t, "layer-id", resp.Experiment.LayerId)
+				assert.Equal(t, int32(2), resp.Experiment.FeatureVersion)
+			}
+		})
+	}
+}
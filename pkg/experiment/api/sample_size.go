@@ -0,0 +1,261 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const (
+	defaultSampleSizeAlpha  = 0.05
+	defaultSampleSizePower  = 0.8
+	sampleSizeLookbackDays  = 7
+	defaultVariationCount   = 2
+	secondsPerDay           = 24 * 60 * 60
+	sampleSizeLookbackRange = sampleSizeLookbackDays * secondsPerDay
+	// Shorter periods are too noisy to be extrapolated to a day.
+	sampleSizeMinLookbackRange = 60 * 60
+)
+
+func (s *experimentService) EstimateSampleSize(
+	ctx context.Context,
+	req *proto.EstimateSampleSizeRequest,
+) (*proto.EstimateSampleSizeResponse, error) {
+	var editor *eventproto.Editor
+	var err error
+	if req.ExperimentId != "" {
+		editor, err = s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	} else {
+		_, err = s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEstimateSampleSizeRequest(req); err != nil {
+		return nil, err
+	}
+	var feature *featureproto.Feature
+	if req.FeatureId != "" {
+		resp, err := s.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+			Id:                   req.FeatureId,
+			EnvironmentNamespace: req.EnvironmentNamespace,
+		})
+		if err != nil {
+			if code := status.Code(err); code == codes.NotFound {
+				return nil, localizedError(statusFeatureNotFound, locale.JaJP)
+			}
+			s.logger.Error(
+				"Failed to get feature",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", req.EnvironmentNamespace),
+				)...,
+			)
+			return nil, localizedError(statusInternal, locale.JaJP)
+		}
+		feature = resp.Feature
+	}
+	estimate := newSampleSizeEstimate(req, feature)
+	usersPerVariation, err := stats.TwoProportionSampleSize(
+		estimate.BaselineConversionRate,
+		estimate.MinimumDetectableEffect,
+		estimate.Alpha,
+		estimate.Power,
+		int(estimate.VariationCount),
+	)
+	if err != nil {
+		return nil, localizedError(statusInvalidSampleSizeParameters, locale.JaJP)
+	}
+	estimate.UsersPerVariation = usersPerVariation
+	now := time.Now()
+	dailyUsers, known, err := s.getDailyUsers(ctx, feature, now, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	estimate.DailyUsers = dailyUsers
+	estimate.DailyUsersUnknown = !known
+	estimate.EstimatedDays = estimateDays(estimate)
+	estimate.EstimatedAt = now.Unix()
+	if req.ExperimentId != "" {
+		err := s.updateExperiment(
+			ctx,
+			editor,
+			&proto.ChangeSampleSizeEstimateCommand{SampleSizeEstimate: estimate},
+			req.ExperimentId,
+			req.EnvironmentNamespace,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &proto.EstimateSampleSizeResponse{Estimate: estimate}, nil
+}
+
+func validateEstimateSampleSizeRequest(req *proto.EstimateSampleSizeRequest) error {
+	if req.BaselineConversionRate <= 0 || req.BaselineConversionRate >= 1 {
+		return localizedError(statusInvalidSampleSizeParameters, locale.JaJP)
+	}
+	if req.MinimumDetectableEffect <= 0 {
+		return localizedError(statusInvalidSampleSizeParameters, locale.JaJP)
+	}
+	if req.Alpha < 0 || req.Alpha >= 1 || req.Power < 0 || req.Power >= 1 {
+		return localizedError(statusInvalidSampleSizeParameters, locale.JaJP)
+	}
+	if req.VariationCount != 0 && req.VariationCount < 2 {
+		return localizedError(statusInvalidSampleSizeParameters, locale.JaJP)
+	}
+	if req.TrafficAllocation < 0 || req.TrafficAllocation > domain.TotalTrafficAllocation {
+		return localizedError(statusInvalidTrafficAllocation, locale.JaJP)
+	}
+	return nil
+}
+
+// newSampleSizeEstimate fills in the defaults of the request.
+// The variation count defaults to the feature's variations.
+func newSampleSizeEstimate(
+	req *proto.EstimateSampleSizeRequest,
+	feature *featureproto.Feature,
+) *proto.SampleSizeEstimate {
+	estimate := &proto.SampleSizeEstimate{
+		BaselineConversionRate:  req.BaselineConversionRate,
+		MinimumDetectableEffect: req.MinimumDetectableEffect,
+		Alpha:                   req.Alpha,
+		Power:                   req.Power,
+		VariationCount:          req.VariationCount,
+		TrafficAllocation:       req.TrafficAllocation,
+	}
+	if estimate.Alpha == 0 {
+		estimate.Alpha = defaultSampleSizeAlpha
+	}
+	if estimate.Power == 0 {
+		estimate.Power = defaultSampleSizePower
+	}
+	if estimate.VariationCount == 0 {
+		estimate.VariationCount = defaultVariationCount
+		if feature != nil && len(feature.Variations) > defaultVariationCount {
+			estimate.VariationCount = int32(len(feature.Variations))
+		}
+	}
+	return estimate
+}
+
+// getDailyUsers returns the average daily users over the lookback period,
+// and false when there isn't enough data to know them.
+// If the feature is given, only the users who evaluated its current version are counted,
+// so the period starts when the feature was last updated.
+func (s *experimentService) getDailyUsers(
+	ctx context.Context,
+	feature *featureproto.Feature,
+	now time.Time,
+	environmentNamespace string,
+) (int64, bool, error) {
+	endAt := now.Unix()
+	startAt := endAt - sampleSizeLookbackRange
+	if feature == nil {
+		resp, err := s.eventCounterClient.GetUserCountV2(ctx, &eventcounterproto.GetUserCountV2Request{
+			EnvironmentNamespace: environmentNamespace,
+			StartAt:              startAt,
+			EndAt:                endAt,
+		})
+		if err != nil {
+			s.logger.Error(
+				"Failed to get user count",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", environmentNamespace),
+				)...,
+			)
+			return 0, false, localizedError(statusInternal, locale.JaJP)
+		}
+		users, known := averageDailyUsers(resp.UserCount, startAt, endAt)
+		return users, known, nil
+	}
+	if feature.UpdatedAt > startAt {
+		startAt = feature.UpdatedAt
+	}
+	if endAt-startAt < sampleSizeMinLookbackRange {
+		return 0, false, nil
+	}
+	variationIDs := make([]string, 0, len(feature.Variations))
+	for _, v := range feature.Variations {
+		variationIDs = append(variationIDs, v.Id)
+	}
+	resp, err := s.eventCounterClient.GetEvaluationCountV2(ctx, &eventcounterproto.GetEvaluationCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              startAt,
+		EndAt:                endAt,
+		FeatureId:            feature.Id,
+		FeatureVersion:       feature.Version,
+		VariationIds:         variationIDs,
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to get evaluation count",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("featureId", feature.Id),
+			)...,
+		)
+		return 0, false, localizedError(statusInternal, locale.JaJP)
+	}
+	var users int64
+	if resp.Count != nil {
+		for _, vc := range resp.Count.RealtimeCounts {
+			users += vc.UserCount
+		}
+	}
+	dailyUsers, known := averageDailyUsers(users, startAt, endAt)
+	return dailyUsers, known, nil
+}
+
+// averageDailyUsers scales the users seen between startAt and endAt to a day.
+// It returns false when the period is too short or no users were seen.
+func averageDailyUsers(users, startAt, endAt int64) (int64, bool) {
+	if users == 0 || endAt-startAt < sampleSizeMinLookbackRange {
+		return 0, false
+	}
+	return int64(math.Round(float64(users) * secondsPerDay / float64(endAt-startAt))), true
+}
+
+// estimateDays returns how many days it takes for all the variations to reach the sample size
+// with the daily users who enter the experiment.
+func estimateDays(estimate *proto.SampleSizeEstimate) int32 {
+	dailyUsers := float64(estimate.DailyUsers)
+	if estimate.TrafficAllocation > 0 {
+		dailyUsers = dailyUsers * float64(estimate.TrafficAllocation) / domain.TotalTrafficAllocation
+	}
+	if estimate.DailyUsersUnknown || dailyUsers == 0 {
+		return 0
+	}
+	required := float64(estimate.UsersPerVariation) * float64(estimate.VariationCount)
+	return int32(math.Ceil(required / dailyUsers))
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ecclientmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	eventcounterproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+func TestEstimateSampleSize(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup        func(*experimentService)
+		req          *experimentproto.EstimateSampleSizeRequest
+		expectedDays int32
+		expectedErr  error
+	}{
+		"err: invalid baseline conversion rate": {
+			req: &experimentproto.EstimateSampleSizeRequest{
+				EnvironmentNamespace:    "ns0",
+				BaselineConversionRate:  1,
+				MinimumDetectableEffect: 0.1,
+			},
+			expectedErr: errInvalidSampleSizeParametersJaJP,
+		},
+		"err: invalid variation count": {
+			req: &experimentproto.EstimateSampleSizeRequest{
+				EnvironmentNamespace:    "ns0",
+				BaselineConversionRate:  0.1,
+				MinimumDetectableEffect: 0.1,
+				VariationCount:          1,
+			},
+			expectedErr: errInvalidSampleSizeParametersJaJP,
+		},
+		"err: invalid traffic allocation": {
+			req: &experimentproto.EstimateSampleSizeRequest{
+				EnvironmentNamespace:    "ns0",
+				BaselineConversionRate:  0.1,
+				MinimumDetectableEffect: 0.1,
+				TrafficAllocation:       100001,
+			},
+			expectedErr: errInvalidTrafficAllocationJaJP,
+		},
+		"success: all users": {
+			setup: func(s *experimentService) {
+				s.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetUserCountV2(
+					gomock.Any(), gomock.Any(),
+				).Return(&eventcounterproto.GetUserCountV2Response{UserCount: 7000}, nil)
+			},
+			req: &experimentproto.EstimateSampleSizeRequest{
+				EnvironmentNamespace:    "ns0",
+				BaselineConversionRate:  0.1,
+				MinimumDetectableEffect: 0.1,
+			},
+			expectedDays: 30,
+		},
+		"success: feature users stored on the experiment": {
+			setup: func(s *experimentService) {
+				s.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetEvaluationCountV2(
+					gomock.Any(), gomock.Any(),
+				).Return(&eventcounterproto.GetEvaluationCountV2Response{
+					Count: &eventcounterproto.EvaluationCount{
+						RealtimeCounts: []*eventcounterproto.VariationCount{
+							{VariationId: "vid-0", UserCount: 3500},
+							{VariationId: "vid-1", UserCount: 3500},
+						},
+					},
+				}, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil)
+			},
+			req: &experimentproto.EstimateSampleSizeRequest{
+				EnvironmentNamespace:    "ns0",
+				FeatureId:               "fid",
+				BaselineConversionRate:  0.1,
+				MinimumDetectableEffect: 0.1,
+				TrafficAllocation:       50000,
+				ExperimentId:            "eid",
+			},
+			expectedDays: 60,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			ctx := createContextWithToken()
+			service := createExperimentService(mockController, nil)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.EstimateSampleSize(ctx, p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			require.NotNil(t, resp.Estimate)
+			assert.Equal(t, int64(14751), resp.Estimate.UsersPerVariation)
+			assert.Equal(t, int64(1000), resp.Estimate.DailyUsers)
+			assert.Equal(t, p.expectedDays, resp.Estimate.EstimatedDays)
+		})
+	}
+}
+
+func TestEstimateDays(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		estimate *experimentproto.SampleSizeEstimate
+		expected int32
+	}{
+		"no users": {
+			estimate: &experimentproto.SampleSizeEstimate{
+				UsersPerVariation: 100,
+				VariationCount:    2,
+			},
+			expected: 0,
+		},
+		"unknown daily users": {
+			estimate: &experimentproto.SampleSizeEstimate{
+				UsersPerVariation: 100,
+				VariationCount:    2,
+				DailyUsers:        100,
+				DailyUsersUnknown: true,
+			},
+			expected: 0,
+		},
+		"all traffic": {
+			estimate: &experimentproto.SampleSizeEstimate{
+				UsersPerVariation: 100,
+				VariationCount:    3,
+				DailyUsers:        100,
+			},
+			expected: 3,
+		},
+		"limited traffic": {
+			estimate: &experimentproto.SampleSizeEstimate{
+				UsersPerVariation: 100,
+				VariationCount:    2,
+				DailyUsers:        100,
+				TrafficAllocation: 10000,
+			},
+			expected: 20,
+		},
+	}
+	for msg, p := range patterns {
+		p := p
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, p.expected, estimateDays(p.estimate))
+		})
+	}
+}
+
+func TestAverageDailyUsers(t *testing.T) {
+	t.Parallel()
+	patterns := map[string]struct {
+		users          int64
+		startAt, endAt int64
+		expected       int64
+		expectedKnown  bool
+	}{
+		"no users": {
+			users:   0,
+			startAt: 0,
+			endAt:   sampleSizeLookbackRange,
+		},
+		"period too short": {
+			users:   100,
+			startAt: 0,
+			endAt:   sampleSizeMinLookbackRange - 1,
+		},
+		"whole lookback period": {
+			users:         7000,
+			startAt:       0,
+			endAt:         sampleSizeLookbackRange,
+			expected:      1000,
+			expectedKnown: true,
+		},
+		"feature updated a day and a half ago": {
+			users:         1500,
+			startAt:       0,
+			endAt:         secondsPerDay * 3 / 2,
+			expected:      1000,
+			expectedKnown: true,
+		},
+	}
+	for msg, p := range patterns {
+		p := p
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+			users, known := averageDailyUsers(p.users, p.startAt, p.endAt)
+			assert.Equal(t, p.expected, users)
+			assert.Equal(t, p.expectedKnown, known)
+		})
+	}
+}
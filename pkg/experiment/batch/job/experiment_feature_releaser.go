@@ -0,0 +1,196 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// experimentFeatureReleaser removes the layer assignments and the traffic allocations
+// left on the features by experiments that are finished, stopped or deleted.
+// The experiment API releases the feature right after the status change,
+// but it fails while another experiment is waiting or running on the feature.
+type experimentFeatureReleaser struct {
+	environmentClient environmentclient.Client
+	experimentClient  experimentclient.Client
+	featureClient     featureclient.Client
+	opts              *options
+	logger            *zap.Logger
+}
+
+func NewExperimentFeatureReleaser(
+	environmentClient environmentclient.Client,
+	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &experimentFeatureReleaser{
+		environmentClient: environmentClient,
+		experimentClient:  experimentClient,
+		featureClient:     featureClient,
+		opts:              dopts,
+		logger:            dopts.logger.Named("feature-releaser"),
+	}
+}
+
+func (r *experimentFeatureReleaser) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.timeout)
+	defer cancel()
+	environments, err := listEnvironments(ctx, r.environmentClient)
+	if err != nil {
+		r.logger.Error("Failed to list environments", zap.Error(err))
+		lastErr = err
+		return
+	}
+	for _, env := range environments {
+		features, err := r.listAssignedFeatures(ctx, env.Namespace)
+		if err != nil {
+			r.logger.Error("Failed to list features", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		for _, f := range features {
+			if err := r.release(ctx, env.Namespace, f); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return
+}
+
+// listAssignedFeatures lists the features with a layer assignment or a traffic allocation.
+func (r *experimentFeatureReleaser) listAssignedFeatures(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Feature, error) {
+	features := []*featureproto.Feature{}
+	cursor := ""
+	for {
+		resp, err := r.featureClient.ListFeatures(ctx, &featureproto.ListFeaturesRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Features {
+			if f.LayerAssignment == nil && f.ExperimentAllocation == nil {
+				continue
+			}
+			features = append(features, f)
+		}
+		featureSize := len(resp.Features)
+		if featureSize == 0 || featureSize < listRequestSize {
+			return features, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (r *experimentFeatureReleaser) release(
+	ctx context.Context,
+	environmentNamespace string,
+	feature *featureproto.Feature,
+) error {
+	cmds := []pb.Message{}
+	if id := feature.LayerAssignment.GetExperimentId(); id != "" {
+		released, err := r.isReleased(ctx, environmentNamespace, id)
+		if err != nil {
+			return err
+		}
+		if released {
+			cmds = append(cmds, &featureproto.RemoveLayerAssignmentCommand{ExperimentId: id})
+		}
+	}
+	if id := feature.ExperimentAllocation.GetExperimentId(); id != "" {
+		released, err := r.isReleased(ctx, environmentNamespace, id)
+		if err != nil {
+			return err
+		}
+		if released {
+			cmds = append(cmds, &featureproto.RemoveExperimentAllocationCommand{ExperimentId: id})
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	commands := make([]*featureproto.Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		c, err := ptypes.MarshalAny(cmd)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, &featureproto.Command{Command: c})
+	}
+	_, err := r.featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+		Id:                   feature.Id,
+		Commands:             commands,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		r.logger.Error("Failed to release feature", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("featureId", feature.Id))
+		return err
+	}
+	return nil
+}
+
+// isReleased returns true if the experiment no longer runs on its feature.
+func (r *experimentFeatureReleaser) isReleased(
+	ctx context.Context,
+	environmentNamespace, experimentID string,
+) (bool, error) {
+	resp, err := r.experimentClient.GetExperiment(ctx, &experimentproto.GetExperimentRequest{
+		Id:                   experimentID,
+		EnvironmentNamespace: environmentNamespace,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return true, nil
+		}
+		r.logger.Error("Failed to get experiment", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experimentID))
+		return false, err
+	}
+	de := &domain.Experiment{Experiment: resp.Experiment}
+	return !de.IsNotFinished(time.Now()), nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	ecmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
+	featuremock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestReleaseFeature(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	now := time.Now()
+	feature := &featureproto.Feature{
+		Id:                   "fid",
+		LayerAssignment:      &featureproto.LayerAssignment{LayerId: "lid", ExperimentId: "eid-0"},
+		ExperimentAllocation: &featureproto.ExperimentAllocation{ExperimentId: "eid-1"},
+	}
+	running := func(id string) *experimentproto.GetExperimentResponse {
+		return &experimentproto.GetExperimentResponse{Experiment: &experimentproto.Experiment{
+			Id:        id,
+			Status:    experimentproto.Experiment_RUNNING,
+			StopAt:    now.Add(24 * time.Hour).Unix(),
+			StoppedAt: 1<<63 - 1,
+		}}
+	}
+	finished := func(id string) *experimentproto.GetExperimentResponse {
+		return &experimentproto.GetExperimentResponse{Experiment: &experimentproto.Experiment{
+			Id:     id,
+			Status: experimentproto.Experiment_STOPPED,
+			StopAt: now.Add(-time.Hour).Unix(),
+		}}
+	}
+	getExperiment := func(r *experimentFeatureReleaser, id string) *gomock.Call {
+		return r.experimentClient.(*ecmock.MockClient).EXPECT().GetExperiment(
+			gomock.Any(),
+			&experimentproto.GetExperimentRequest{Id: id, EnvironmentNamespace: "ns"},
+		)
+	}
+	patterns := map[string]struct {
+		setup            func(*experimentFeatureReleaser)
+		expectedCommands []*featureproto.Command
+		expected         error
+	}{
+		"success: running experiments are kept": {
+			setup: func(r *experimentFeatureReleaser) {
+				getExperiment(r, "eid-0").Return(running("eid-0"), nil)
+				getExperiment(r, "eid-1").Return(running("eid-1"), nil)
+			},
+			expected: nil,
+		},
+		"error: get experiment fails": {
+			setup: func(r *experimentFeatureReleaser) {
+				getExperiment(r, "eid-0").Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"error: update feature targeting fails": {
+			setup: func(r *experimentFeatureReleaser) {
+				getExperiment(r, "eid-0").Return(finished("eid-0"), nil)
+				getExperiment(r, "eid-1").Return(running("eid-1"), nil)
+				r.featureClient.(*featuremock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: finished and deleted experiments are released": {
+			setup: func(r *experimentFeatureReleaser) {
+				getExperiment(r, "eid-0").Return(finished("eid-0"), nil)
+				getExperiment(r, "eid-1").Return(nil, gstatus.Error(codes.NotFound, "not found"))
+			},
+			expectedCommands: []*featureproto.Command{
+				newCommand(t, &featureproto.RemoveLayerAssignmentCommand{ExperimentId: "eid-0"}),
+				newCommand(t, &featureproto.RemoveExperimentAllocationCommand{ExperimentId: "eid-1"}),
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			releaser := newMockExperimentFeatureReleaser(t, mockController)
+			p.setup(releaser)
+			if p.expectedCommands != nil {
+				releaser.featureClient.(*featuremock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					ctx context.Context,
+					req *featureproto.UpdateFeatureTargetingRequest,
+					opts ...grpc.CallOption,
+				) (*featureproto.UpdateFeatureTargetingResponse, error) {
+					assert.Equal(t, "fid", req.Id)
+					assert.Equal(t, p.expectedCommands, req.Commands)
+					return &featureproto.UpdateFeatureTargetingResponse{}, nil
+				})
+			}
+			err := releaser.release(context.Background(), "ns", feature)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func newCommand(t *testing.T, cmd pb.Message) *featureproto.Command {
+	t.Helper()
+	c, err := ptypes.MarshalAny(cmd)
+	require.NoError(t, err)
+	return &featureproto.Command{Command: c}
+}
+
+func newMockExperimentFeatureReleaser(t *testing.T, c *gomock.Controller) *experimentFeatureReleaser {
+	return &experimentFeatureReleaser{
+		experimentClient: ecmock.NewMockClient(c),
+		featureClient:    featuremock.NewMockClient(c),
+		opts: &options{
+			timeout: 5 * time.Second,
+		},
+		logger: zap.NewNop().Named("test-experiment-feature-releaser"),
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+// experimentGuardrailWatcher stops running experiments whose guardrail goals are breached.
+// When the experiment is configured to roll back, the feature's default strategy is fixed
+// to the base variation once the experiment is stopped.
+type experimentGuardrailWatcher struct {
+	environmentClient  environmentclient.Client
+	experimentClient   experimentclient.Client
+	featureClient      featureclient.Client
+	eventCounterClient ecclient.Client
+	opts               *options
+	logger             *zap.Logger
+}
+
+func NewExperimentGuardrailWatcher(
+	environmentClient environmentclient.Client,
+	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	eventCounterClient ecclient.Client,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &experimentGuardrailWatcher{
+		environmentClient:  environmentClient,
+		experimentClient:   experimentClient,
+		featureClient:      featureClient,
+		eventCounterClient: eventCounterClient,
+		opts:               dopts,
+		logger:             dopts.logger.Named("guardrail-watcher"),
+	}
+}
+
+func (w *experimentGuardrailWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments, err := listEnvironments(ctx, w.environmentClient)
+	if err != nil {
+		w.logger.Error("Failed to list environments", zap.Error(err))
+		lastErr = err
+		return
+	}
+	for _, env := range environments {
+		experiments, err := listExperiments(ctx, w.experimentClient, env.Namespace, experimentproto.Experiment_RUNNING)
+		if err != nil {
+			w.logger.Error("Failed to list experiments", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		for _, e := range experiments {
+			if err := w.watch(ctx, env.Namespace, e); err != nil {
+				lastErr = err
+			}
+		}
+		err = applyPendingDefaultVariations(
+			ctx,
+			w.experimentClient,
+			w.featureClient,
+			env.Namespace,
+			experimentproto.Experiment_GUARDRAIL_BREACH,
+			func(e *experimentproto.Experiment) string {
+				return fmt.Sprintf("Rolled back to the base variation due to guardrail breach of experiment %s", e.Name)
+			},
+			w.logger,
+		)
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return
+}
+
+func (w *experimentGuardrailWatcher) watch(
+	ctx context.Context,
+	environmentNamespace string,
+	experiment *experimentproto.Experiment,
+) error {
+	de := &domain.Experiment{Experiment: experiment}
+	guardrails := de.Guardrails()
+	if len(guardrails) == 0 {
+		return nil
+	}
+	resultResp, err := w.eventCounterClient.GetExperimentResult(ctx, &ecproto.GetExperimentResultRequest{
+		EnvironmentNamespace: environmentNamespace,
+		ExperimentId:         experiment.Id,
+	})
+	if err != nil {
+		if gstatus.Code(err) == codes.NotFound {
+			return nil
+		}
+		w.logger.Error("Failed to get experiment result", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id))
+		return err
+	}
+	breaches := guardrailBreaches(experiment, guardrails, resultResp.ExperimentResult)
+	if len(breaches) == 0 {
+		return nil
+	}
+	_, err = w.experimentClient.StopExperiment(ctx, &experimentproto.StopExperimentRequest{
+		EnvironmentNamespace: environmentNamespace,
+		Id:                   experiment.Id,
+		Command: &experimentproto.StopExperimentCommand{
+			Reason:            experimentproto.Experiment_GUARDRAIL_BREACH,
+			GuardrailBreaches: breaches,
+		},
+	})
+	if err != nil {
+		w.logger.Error("Failed to stop experiment", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id))
+		return err
+	}
+	return nil
+}
+
+// guardrailBreaches returns the variations whose conversion rate of a guardrail goal changed
+// significantly by more than the threshold relative to the base variation.
+// Guardrails are checked on every run, so they are tested with the always-valid p-value of the mSPRT,
+// and alpha is split across the guardrails and the variations with Bonferroni's correction.
+func guardrailBreaches(
+	experiment *experimentproto.Experiment,
+	guardrails []*experimentproto.GoalConfig,
+	result *ecproto.ExperimentResult,
+) []*experimentproto.GuardrailBreach {
+	breaches := []*experimentproto.GuardrailBreach{}
+	if len(experiment.Variations) < 2 {
+		return breaches
+	}
+	alpha := (1 - stats.DefaultConfidenceLevel) / float64(len(guardrails)*(len(experiment.Variations)-1))
+	goalResults := make(map[string]*ecproto.GoalResult, len(result.GoalResults))
+	for _, gr := range result.GoalResults {
+		goalResults[gr.GoalId] = gr
+	}
+	for _, gc := range guardrails {
+		gr, ok := goalResults[gc.GoalId]
+		if !ok {
+			continue
+		}
+		var base *ecproto.VariationResult
+		for _, vr := range gr.VariationResults {
+			if vr.VariationId == experiment.BaseVariationId {
+				base = vr
+				break
+			}
+		}
+		if base == nil {
+			continue
+		}
+		for _, vr := range gr.VariationResults {
+			if vr.VariationId == experiment.BaseVariationId {
+				continue
+			}
+			if breach := guardrailBreach(gc, alpha, base, vr); breach != nil {
+				breaches = append(breaches, breach)
+			}
+		}
+	}
+	return breaches
+}
+
+func guardrailBreach(
+	goalConfig *experimentproto.GoalConfig,
+	alpha float64,
+	base, variation *ecproto.VariationResult,
+) *experimentproto.GuardrailBreach {
+	difference, significant := sequentialTest(
+		experimentproto.SequentialTesting_MSPRT,
+		alpha,
+		base,
+		variation,
+		0, 0, 0,
+	)
+	if !significant {
+		return nil
+	}
+	baseRate := conversionRate(base)
+	if baseRate == 0 {
+		return nil
+	}
+	relativeChange := difference / baseRate
+	change := relativeChange
+	if goalConfig.Guardrail.Direction == experimentproto.Guardrail_MUST_NOT_DECREASE {
+		change = -change
+	}
+	if change <= goalConfig.Guardrail.Threshold {
+		return nil
+	}
+	return &experimentproto.GuardrailBreach{
+		GoalId:             goalConfig.GoalId,
+		VariationId:        variation.VariationId,
+		BaseConversionRate: baseRate,
+		ConversionRate:     conversionRate(variation),
+		RelativeChange:     relativeChange,
+		Threshold:          goalConfig.Guardrail.Threshold,
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	eventcountermock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	experimentmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
+	featuremock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestWatchGuardrail(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	newExperiment := func(direction experimentproto.Guardrail_Direction, rollback bool) *experimentproto.Experiment {
+		return &experimentproto.Experiment{
+			Id:              "eid",
+			Name:            "name",
+			FeatureId:       "fid",
+			BaseVariationId: "vid-0",
+			GoalIds:         []string{"gid-0", "gid-1"},
+			Variations: []*featureproto.Variation{
+				{Id: "vid-0"},
+				{Id: "vid-1"},
+			},
+			GoalConfigs: []*experimentproto.GoalConfig{
+				{
+					GoalId: "gid-1",
+					Role:   experimentproto.GoalConfig_GUARDRAIL,
+					Guardrail: &experimentproto.Guardrail{
+						Direction: direction,
+						Threshold: 0.02,
+					},
+				},
+			},
+			RollbackOnGuardrailBreach: rollback,
+		}
+	}
+	newResult := func(conversions int64) *ecproto.GetExperimentResultResponse {
+		return &ecproto.GetExperimentResultResponse{
+			ExperimentResult: &ecproto.ExperimentResult{
+				Id:           "eid",
+				ExperimentId: "eid",
+				GoalResults: []*ecproto.GoalResult{
+					{
+						GoalId: "gid-1",
+						VariationResults: []*ecproto.VariationResult{
+							{
+								VariationId:     "vid-0",
+								EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+								ExperimentCount: &ecproto.VariationCount{UserCount: 1000},
+							},
+							{
+								VariationId:     "vid-1",
+								EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+								ExperimentCount: &ecproto.VariationCount{UserCount: conversions},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	patterns := map[string]struct {
+		experiment *experimentproto.Experiment
+		setup      func(*experimentGuardrailWatcher)
+		expected   error
+	}{
+		"success: no guardrails": {
+			experiment: &experimentproto.Experiment{Id: "eid", GoalIds: []string{"gid-0"}},
+			expected:   nil,
+		},
+		"success: change is not significant": {
+			experiment: newExperiment(experimentproto.Guardrail_MUST_NOT_INCREASE, false),
+			setup: func(w *experimentGuardrailWatcher) {
+				w.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1020), nil)
+			},
+			expected: nil,
+		},
+		"success: change is in the allowed direction": {
+			experiment: newExperiment(experimentproto.Guardrail_MUST_NOT_DECREASE, false),
+			setup: func(w *experimentGuardrailWatcher) {
+				w.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1200), nil)
+			},
+			expected: nil,
+		},
+		"error: stop experiment fails": {
+			experiment: newExperiment(experimentproto.Guardrail_MUST_NOT_INCREASE, true),
+			setup: func(w *experimentGuardrailWatcher) {
+				w.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1200), nil)
+				w.experimentClient.(*experimentmock.MockClient).EXPECT().StopExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: breach stops experiment": {
+			experiment: newExperiment(experimentproto.Guardrail_MUST_NOT_INCREASE, false),
+			setup: func(w *experimentGuardrailWatcher) {
+				w.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1200), nil)
+				w.experimentClient.(*experimentmock.MockClient).EXPECT().StopExperiment(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *experimentproto.StopExperimentRequest,
+					_ ...grpc.CallOption,
+				) (*experimentproto.StopExperimentResponse, error) {
+					assert.Equal(t, experimentproto.Experiment_GUARDRAIL_BREACH, req.Command.Reason)
+					assert.Len(t, req.Command.GuardrailBreaches, 1)
+					breach := req.Command.GuardrailBreaches[0]
+					assert.Equal(t, "gid-1", breach.GoalId)
+					assert.Equal(t, "vid-1", breach.VariationId)
+					assert.InDelta(t, 0.2, breach.RelativeChange, 1e-9)
+					return &experimentproto.StopExperimentResponse{}, nil
+				})
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			watcher := newMockExperimentGuardrailWatcher(t, mockController)
+			if p.setup != nil {
+				p.setup(watcher)
+			}
+			err := watcher.watch(context.Background(), "ns", p.experiment)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func TestGuardrailBreaches(t *testing.T) {
+	t.Parallel()
+	guardrail := func(goalID string) *experimentproto.GoalConfig {
+		return &experimentproto.GoalConfig{
+			GoalId: goalID,
+			Role:   experimentproto.GoalConfig_GUARDRAIL,
+			Guardrail: &experimentproto.Guardrail{
+				Direction: experimentproto.Guardrail_MUST_NOT_INCREASE,
+				Threshold: 0.02,
+			},
+		}
+	}
+	goalResult := func(goalID string, conversions int64) *ecproto.GoalResult {
+		return &ecproto.GoalResult{
+			GoalId: goalID,
+			VariationResults: []*ecproto.VariationResult{
+				{
+					VariationId:     "vid-0",
+					EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+					ExperimentCount: &ecproto.VariationCount{UserCount: 1000},
+				},
+				{
+					VariationId:     "vid-1",
+					EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+					ExperimentCount: &ecproto.VariationCount{UserCount: conversions},
+				},
+			},
+		}
+	}
+	experiment := &experimentproto.Experiment{
+		Id:              "eid",
+		BaseVariationId: "vid-0",
+		Variations: []*featureproto.Variation{
+			{Id: "vid-0"},
+			{Id: "vid-1"},
+		},
+	}
+	patterns := map[string]struct {
+		guardrails []*experimentproto.GoalConfig
+		result     *ecproto.ExperimentResult
+		expected   []string
+	}{
+		"significant with a single guardrail": {
+			guardrails: []*experimentproto.GoalConfig{guardrail("gid-0")},
+			result: &ecproto.ExperimentResult{
+				GoalResults: []*ecproto.GoalResult{goalResult("gid-0", 1140)},
+			},
+			expected: []string{"gid-0"},
+		},
+		"not significant once alpha is split across the guardrails": {
+			guardrails: []*experimentproto.GoalConfig{guardrail("gid-0"), guardrail("gid-1")},
+			result: &ecproto.ExperimentResult{
+				GoalResults: []*ecproto.GoalResult{goalResult("gid-0", 1140), goalResult("gid-1", 1000)},
+			},
+			expected: []string{},
+		},
+		"significant after the split": {
+			guardrails: []*experimentproto.GoalConfig{guardrail("gid-0"), guardrail("gid-1")},
+			result: &ecproto.ExperimentResult{
+				GoalResults: []*ecproto.GoalResult{goalResult("gid-0", 1000), goalResult("gid-1", 1200)},
+			},
+			expected: []string{"gid-1"},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			breaches := guardrailBreaches(experiment, p.guardrails, p.result)
+			goalIDs := []string{}
+			for _, b := range breaches {
+				goalIDs = append(goalIDs, b.GoalId)
+			}
+			assert.Equal(t, p.expected, goalIDs)
+		})
+	}
+}
+
+func newMockExperimentGuardrailWatcher(t *testing.T, c *gomock.Controller) *experimentGuardrailWatcher {
+	return &experimentGuardrailWatcher{
+		experimentClient:   experimentmock.NewMockClient(c),
+		featureClient:      featuremock.NewMockClient(c),
+		eventCounterClient: eventcountermock.NewMockClient(c),
+		opts: &options{
+			timeout: 5 * time.Second,
+		},
+		logger: zap.NewNop().Named("test-experiment-guardrail-watcher"),
+	}
+}
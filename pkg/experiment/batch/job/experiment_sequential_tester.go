@@ -0,0 +1,358 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// mixingSDRatio is the standard deviation of the mSPRT mixing distribution
+// relative to the baseline conversion rate, i.e. the size of the effects we expect to detect.
+const mixingSDRatio = 0.1
+
+// pendingDefaultVariationRetryPeriod is how long after an experiment is stopped
+// the jobs keep retrying to apply its pending default variation to the feature.
+const pendingDefaultVariationRetryPeriod = 7 * 24 * time.Hour
+
+// experimentSequentialTester stops running experiments as soon as the sequential test
+// on the configured goal reaches significance, and optionally promotes the winner
+// to the default strategy of the feature once the experiment is stopped.
+type experimentSequentialTester struct {
+	environmentClient  environmentclient.Client
+	experimentClient   experimentclient.Client
+	featureClient      featureclient.Client
+	eventCounterClient ecclient.Client
+	opts               *options
+	logger             *zap.Logger
+}
+
+func NewExperimentSequentialTester(
+	environmentClient environmentclient.Client,
+	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	eventCounterClient ecclient.Client,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &experimentSequentialTester{
+		environmentClient:  environmentClient,
+		experimentClient:   experimentClient,
+		featureClient:      featureClient,
+		eventCounterClient: eventCounterClient,
+		opts:               dopts,
+		logger:             dopts.logger.Named("sequential-tester"),
+	}
+}
+
+func (t *experimentSequentialTester) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, t.opts.timeout)
+	defer cancel()
+	environments, err := listEnvironments(ctx, t.environmentClient)
+	if err != nil {
+		t.logger.Error("Failed to list environments", zap.Error(err))
+		lastErr = err
+		return
+	}
+	for _, env := range environments {
+		experiments, err := listExperiments(ctx, t.experimentClient, env.Namespace, experimentproto.Experiment_RUNNING)
+		if err != nil {
+			t.logger.Error("Failed to list experiments", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		for _, e := range experiments {
+			if err := t.test(ctx, env.Namespace, e); err != nil {
+				lastErr = err
+			}
+		}
+		err = applyPendingDefaultVariations(
+			ctx,
+			t.experimentClient,
+			t.featureClient,
+			env.Namespace,
+			experimentproto.Experiment_SEQUENTIAL_TEST,
+			func(e *experimentproto.Experiment) string {
+				return fmt.Sprintf("Promoted the winner of experiment %s by sequential testing", e.Name)
+			},
+			t.logger,
+		)
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return
+}
+
+func (t *experimentSequentialTester) test(
+	ctx context.Context,
+	environmentNamespace string,
+	experiment *experimentproto.Experiment,
+) error {
+	de := &domain.Experiment{Experiment: experiment}
+	if !de.UsesSequentialTesting() {
+		return nil
+	}
+	resultResp, err := t.eventCounterClient.GetExperimentResult(ctx, &ecproto.GetExperimentResultRequest{
+		EnvironmentNamespace: environmentNamespace,
+		ExperimentId:         experiment.Id,
+	})
+	if err != nil {
+		if gstatus.Code(err) == codes.NotFound {
+			return nil
+		}
+		t.logger.Error("Failed to get experiment result", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id))
+		return err
+	}
+	winner := sequentialTestWinner(experiment, resultResp.ExperimentResult, time.Now().Unix())
+	if winner == "" {
+		return nil
+	}
+	_, err = t.experimentClient.StopExperiment(ctx, &experimentproto.StopExperimentRequest{
+		EnvironmentNamespace: environmentNamespace,
+		Id:                   experiment.Id,
+		Command: &experimentproto.StopExperimentCommand{
+			Reason:            experimentproto.Experiment_SEQUENTIAL_TEST,
+			WinnerVariationId: winner,
+		},
+	})
+	if err != nil {
+		t.logger.Error("Failed to stop experiment", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id),
+			zap.String("winnerVariationId", winner))
+		return err
+	}
+	return nil
+}
+
+// applyPendingDefaultVariations fixes the default strategy of the features to the pending default
+// variation of the experiments recently stopped for the reason, then clears it from the experiment.
+// The feature can be updated only once the experiment is stopped, so the variation stays pending
+// until both succeed and a failure is retried on the next run.
+func applyPendingDefaultVariations(
+	ctx context.Context,
+	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	environmentNamespace string,
+	reason experimentproto.Experiment_StopReason,
+	comment func(*experimentproto.Experiment) string,
+	logger *zap.Logger,
+) (lastErr error) {
+	experiments, err := listExperimentsStoppedSince(
+		ctx,
+		experimentClient,
+		environmentNamespace,
+		experimentproto.Experiment_FORCE_STOPPED,
+		time.Now().Add(-pendingDefaultVariationRetryPeriod).Unix(),
+	)
+	if err != nil {
+		logger.Error("Failed to list stopped experiments", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace))
+		return err
+	}
+	for _, e := range experiments {
+		if e.StopReason != reason || e.PendingDefaultVariationId == "" {
+			continue
+		}
+		err := fixDefaultStrategy(
+			ctx,
+			featureClient,
+			environmentNamespace,
+			e.FeatureId,
+			e.PendingDefaultVariationId,
+			comment(e),
+		)
+		if err != nil {
+			logger.Error("Failed to fix default strategy", zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("id", e.Id),
+				zap.String("featureId", e.FeatureId),
+				zap.String("variationId", e.PendingDefaultVariationId))
+			lastErr = err
+			continue
+		}
+		_, err = experimentClient.UpdateExperiment(ctx, &experimentproto.UpdateExperimentRequest{
+			Id:                           e.Id,
+			ApplyDefaultVariationCommand: &experimentproto.ApplyDefaultVariationCommand{},
+			EnvironmentNamespace:         environmentNamespace,
+		})
+		if err != nil {
+			logger.Error("Failed to apply default variation", zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("id", e.Id))
+			lastErr = err
+		}
+	}
+	return
+}
+
+// fixDefaultStrategy sets the default strategy of the feature to serve the variation to everyone.
+func fixDefaultStrategy(
+	ctx context.Context,
+	featureClient featureclient.Client,
+	environmentNamespace, featureID, variationID, comment string,
+) error {
+	cmd, err := ptypes.MarshalAny(&featureproto.ChangeDefaultStrategyCommand{
+		Strategy: &featureproto.Strategy{
+			Type:          featureproto.Strategy_FIXED,
+			FixedStrategy: &featureproto.FixedStrategy{Variation: variationID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+		Id:                   featureID,
+		Commands:             []*featureproto.Command{{Command: cmd}},
+		EnvironmentNamespace: environmentNamespace,
+		Comment:              comment,
+	})
+	return err
+}
+
+// sequentialTestWinner compares the conversion rate of each variation against the base variation
+// and returns the winner once the test is conclusive, or an empty string if it should keep running.
+// The variation with the biggest significant lift wins. The base variation wins when every other
+// variation is significantly worse. Alpha is split across the comparisons with Bonferroni's correction.
+func sequentialTestWinner(
+	experiment *experimentproto.Experiment,
+	result *ecproto.ExperimentResult,
+	now int64,
+) string {
+	st := experiment.SequentialTesting
+	var goalResult *ecproto.GoalResult
+	for _, gr := range result.GoalResults {
+		if gr.GoalId == st.GoalId {
+			goalResult = gr
+			break
+		}
+	}
+	if goalResult == nil {
+		return ""
+	}
+	variationResults := make(map[string]*ecproto.VariationResult, len(goalResult.VariationResults))
+	for _, vr := range goalResult.VariationResults {
+		variationResults[vr.VariationId] = vr
+	}
+	base, ok := variationResults[experiment.BaseVariationId]
+	if !ok || len(experiment.Variations) < 2 {
+		return ""
+	}
+	alpha := st.Alpha / float64(len(experiment.Variations)-1)
+	var winner string
+	var bestDifference float64
+	worse := 0
+	for _, v := range experiment.Variations {
+		if v.Id == experiment.BaseVariationId {
+			continue
+		}
+		vr, ok := variationResults[v.Id]
+		if !ok {
+			return ""
+		}
+		difference, significant := sequentialTest(st.Method, alpha, base, vr, experiment.StartAt, experiment.StopAt, now)
+		if !significant {
+			continue
+		}
+		if difference < 0 {
+			worse++
+			continue
+		}
+		if difference > bestDifference {
+			winner = v.Id
+			bestDifference = difference
+		}
+	}
+	if winner != "" {
+		return winner
+	}
+	if worse == len(experiment.Variations)-1 {
+		return experiment.BaseVariationId
+	}
+	return ""
+}
+
+// sequentialTest returns the difference of the conversion rates and whether it is significant.
+func sequentialTest(
+	method experimentproto.SequentialTesting_Method,
+	alpha float64,
+	base, variation *ecproto.VariationResult,
+	startAt, stopAt, now int64,
+) (float64, bool) {
+	baseTotal := base.GetEvaluationCount().GetUserCount()
+	total := variation.GetEvaluationCount().GetUserCount()
+	if baseTotal == 0 || total == 0 {
+		return 0, false
+	}
+	baseRate := conversionRate(base)
+	rate := conversionRate(variation)
+	difference := rate - baseRate
+	variance := baseRate*(1-baseRate)/float64(baseTotal) + rate*(1-rate)/float64(total)
+	switch method {
+	case experimentproto.SequentialTesting_MSPRT:
+		mixingSD := mixingSDRatio * baseRate
+		pValue, err := stats.MSPRTPValue(difference, variance, mixingSD*mixingSD)
+		if err != nil {
+			return 0, false
+		}
+		return difference, pValue < alpha
+	case experimentproto.SequentialTesting_ALPHA_SPENDING:
+		fraction := math.Min(float64(now-startAt)/float64(stopAt-startAt), 1)
+		boundary, err := stats.OBrienFlemingBoundary(alpha, fraction)
+		if err != nil || variance <= 0 {
+			return 0, false
+		}
+		return difference, math.Abs(difference/math.Sqrt(variance)) >= boundary
+	}
+	return 0, false
+}
+
+// conversionRate returns the share of the evaluated users who achieved the goal.
+func conversionRate(vr *ecproto.VariationResult) float64 {
+	total := vr.GetEvaluationCount().GetUserCount()
+	if total == 0 {
+		return 0
+	}
+	return float64(vr.GetExperimentCount().GetUserCount()) / float64(total)
+}
@@ -0,0 +1,297 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	eventcountermock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	experimentmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
+	featuremock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestSequentialTest(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	now := time.Now().Unix()
+	newExperiment := func(st *experimentproto.SequentialTesting) *experimentproto.Experiment {
+		return &experimentproto.Experiment{
+			Id:              "eid",
+			Name:            "name",
+			FeatureId:       "fid",
+			BaseVariationId: "vid-0",
+			StartAt:         now - 50,
+			StopAt:          now + 50,
+			Variations: []*featureproto.Variation{
+				{Id: "vid-0"},
+				{Id: "vid-1"},
+			},
+			SequentialTesting: st,
+		}
+	}
+	newResult := func(conversions int64) *ecproto.GetExperimentResultResponse {
+		return &ecproto.GetExperimentResultResponse{
+			ExperimentResult: &ecproto.ExperimentResult{
+				Id:           "eid",
+				ExperimentId: "eid",
+				GoalResults: []*ecproto.GoalResult{
+					{
+						GoalId: "gid",
+						VariationResults: []*ecproto.VariationResult{
+							{
+								VariationId:     "vid-0",
+								EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+								ExperimentCount: &ecproto.VariationCount{UserCount: 1000},
+							},
+							{
+								VariationId:     "vid-1",
+								EvaluationCount: &ecproto.VariationCount{UserCount: 10000},
+								ExperimentCount: &ecproto.VariationCount{UserCount: conversions},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	msprt := &experimentproto.SequentialTesting{
+		Method: experimentproto.SequentialTesting_MSPRT,
+		Alpha:  0.05,
+		GoalId: "gid",
+	}
+	patterns := map[string]struct {
+		experiment *experimentproto.Experiment
+		setup      func(*experimentSequentialTester)
+		expected   error
+	}{
+		"success: sequential testing is disabled": {
+			experiment: newExperiment(nil),
+			expected:   nil,
+		},
+		"success: no result yet": {
+			experiment: newExperiment(msprt),
+			setup: func(s *experimentSequentialTester) {
+				s.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, gstatus.Error(codes.NotFound, "not found"))
+			},
+			expected: nil,
+		},
+		"success: inconclusive": {
+			experiment: newExperiment(msprt),
+			setup: func(s *experimentSequentialTester) {
+				s.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1020), nil)
+			},
+			expected: nil,
+		},
+		"error: stop experiment fails": {
+			experiment: newExperiment(msprt),
+			setup: func(s *experimentSequentialTester) {
+				s.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1200), nil)
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().StopExperiment(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: mSPRT stops with the variation as winner": {
+			experiment: newExperiment(msprt),
+			setup: func(s *experimentSequentialTester) {
+				s.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(1200), nil)
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().StopExperiment(
+					gomock.Any(), &experimentproto.StopExperimentRequest{
+						EnvironmentNamespace: "ns",
+						Id:                   "eid",
+						Command: &experimentproto.StopExperimentCommand{
+							Reason:            experimentproto.Experiment_SEQUENTIAL_TEST,
+							WinnerVariationId: "vid-1",
+						},
+					},
+				).Return(&experimentproto.StopExperimentResponse{}, nil)
+			},
+			expected: nil,
+		},
+		"success: alpha spending stops with the base variation as winner": {
+			experiment: newExperiment(&experimentproto.SequentialTesting{
+				Method:        experimentproto.SequentialTesting_ALPHA_SPENDING,
+				Alpha:         0.05,
+				GoalId:        "gid",
+				PromoteWinner: true,
+			}),
+			setup: func(s *experimentSequentialTester) {
+				s.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(newResult(800), nil)
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().StopExperiment(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *experimentproto.StopExperimentRequest,
+					_ ...grpc.CallOption,
+				) (*experimentproto.StopExperimentResponse, error) {
+					assert.Equal(t, "vid-0", req.Command.WinnerVariationId)
+					return &experimentproto.StopExperimentResponse{}, nil
+				})
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			tester := newMockExperimentSequentialTester(t, mockController)
+			if p.setup != nil {
+				p.setup(tester)
+			}
+			err := tester.test(context.Background(), "ns", p.experiment)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func TestApplyPendingDefaultVariations(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	experiments := []*experimentproto.Experiment{
+		{
+			Id:                        "eid-0",
+			FeatureId:                 "fid-0",
+			StopReason:                experimentproto.Experiment_SEQUENTIAL_TEST,
+			PendingDefaultVariationId: "vid-1",
+		},
+		{
+			Id:         "eid-1",
+			FeatureId:  "fid-1",
+			StopReason: experimentproto.Experiment_SEQUENTIAL_TEST,
+		},
+		{
+			Id:                        "eid-2",
+			FeatureId:                 "fid-2",
+			StopReason:                experimentproto.Experiment_GUARDRAIL_BREACH,
+			PendingDefaultVariationId: "vid-0",
+		},
+	}
+	patterns := map[string]struct {
+		setup    func(*experimentSequentialTester)
+		expected error
+	}{
+		"error: list experiments fails": {
+			setup: func(s *experimentSequentialTester) {
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().ListExperiments(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"error: feature update fails and the variation stays pending": {
+			setup: func(s *experimentSequentialTester) {
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().ListExperiments(
+					gomock.Any(), gomock.Any(),
+				).Return(&experimentproto.ListExperimentsResponse{Experiments: experiments}, nil)
+				s.featureClient.(*featuremock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success": {
+			setup: func(s *experimentSequentialTester) {
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().ListExperiments(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *experimentproto.ListExperimentsRequest,
+					_ ...grpc.CallOption,
+				) (*experimentproto.ListExperimentsResponse, error) {
+					assert.Equal(t, int32(experimentproto.Experiment_FORCE_STOPPED), req.Status.Value)
+					assert.NotZero(t, req.From)
+					return &experimentproto.ListExperimentsResponse{Experiments: experiments}, nil
+				})
+				s.featureClient.(*featuremock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *featureproto.UpdateFeatureTargetingRequest,
+					_ ...grpc.CallOption,
+				) (*featureproto.UpdateFeatureTargetingResponse, error) {
+					assert.Equal(t, "fid-0", req.Id)
+					assert.Len(t, req.Commands, 1)
+					cmd := &featureproto.ChangeDefaultStrategyCommand{}
+					assert.NoError(t, req.Commands[0].Command.UnmarshalTo(cmd))
+					assert.Equal(t, "vid-1", cmd.Strategy.FixedStrategy.Variation)
+					return &featureproto.UpdateFeatureTargetingResponse{}, nil
+				})
+				s.experimentClient.(*experimentmock.MockClient).EXPECT().UpdateExperiment(
+					gomock.Any(), &experimentproto.UpdateExperimentRequest{
+						Id:                           "eid-0",
+						ApplyDefaultVariationCommand: &experimentproto.ApplyDefaultVariationCommand{},
+						EnvironmentNamespace:         "ns",
+					},
+				).Return(&experimentproto.UpdateExperimentResponse{}, nil)
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			tester := newMockExperimentSequentialTester(t, mockController)
+			p.setup(tester)
+			err := applyPendingDefaultVariations(
+				context.Background(),
+				tester.experimentClient,
+				tester.featureClient,
+				"ns",
+				experimentproto.Experiment_SEQUENTIAL_TEST,
+				func(e *experimentproto.Experiment) string { return "" },
+				tester.logger,
+			)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func newMockExperimentSequentialTester(t *testing.T, c *gomock.Controller) *experimentSequentialTester {
+	return &experimentSequentialTester{
+		experimentClient:   experimentmock.NewMockClient(c),
+		featureClient:      featuremock.NewMockClient(c),
+		eventCounterClient: eventcountermock.NewMockClient(c),
+		opts: &options{
+			timeout: 5 * time.Second,
+		},
+		logger: zap.NewNop().Named("test-experiment-sequential-tester"),
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	v2ecstorage "github.com/bucketeer-io/bucketeer/pkg/eventcounter/storage/v2"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// experimentSRMChecker runs the sample ratio mismatch test for every running experiment.
+// It compares the count of users served by the feature's default strategy in each variation
+// with the rollout weights of the strategy and stores the p-value on the experiment result.
+type experimentSRMChecker struct {
+	environmentClient  environmentclient.Client
+	experimentClient   experimentclient.Client
+	featureClient      featureclient.Client
+	eventCounterClient ecclient.Client
+	resultStorage      v2ecstorage.ExperimentResultStorage
+	opts               *options
+	logger             *zap.Logger
+}
+
+func NewExperimentSRMChecker(
+	environmentClient environmentclient.Client,
+	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	eventCounterClient ecclient.Client,
+	mysqlClient mysql.Client,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &experimentSRMChecker{
+		environmentClient:  environmentClient,
+		experimentClient:   experimentClient,
+		featureClient:      featureClient,
+		eventCounterClient: eventCounterClient,
+		resultStorage:      v2ecstorage.NewExperimentResultStorage(mysqlClient),
+		opts:               dopts,
+		logger:             dopts.logger.Named("srm-checker"),
+	}
+}
+
+func (c *experimentSRMChecker) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+	environments, err := listEnvironments(ctx, c.environmentClient)
+	if err != nil {
+		c.logger.Error("Failed to list environments", zap.Error(err))
+		lastErr = err
+		return
+	}
+	for _, env := range environments {
+		experiments, err := listExperiments(ctx, c.experimentClient, env.Namespace, experimentproto.Experiment_RUNNING)
+		if err != nil {
+			c.logger.Error("Failed to list experiments", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		for _, e := range experiments {
+			if err := c.check(ctx, env.Namespace, e); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return
+}
+
+func (c *experimentSRMChecker) check(
+	ctx context.Context,
+	environmentNamespace string,
+	experiment *experimentproto.Experiment,
+) error {
+	resultResp, err := c.eventCounterClient.GetExperimentResult(ctx, &ecproto.GetExperimentResultRequest{
+		EnvironmentNamespace: environmentNamespace,
+		ExperimentId:         experiment.Id,
+	})
+	if err != nil {
+		if gstatus.Code(err) == codes.NotFound {
+			return nil
+		}
+		c.logger.Error("Failed to get experiment result", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id))
+		return err
+	}
+	featureResp, err := c.featureClient.GetFeature(ctx, &featureproto.GetFeatureRequest{
+		EnvironmentNamespace: environmentNamespace,
+		Id:                   experiment.FeatureId,
+	})
+	if err != nil {
+		c.logger.Error("Failed to get feature", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id),
+			zap.String("featureId", experiment.FeatureId))
+		return err
+	}
+	strategy := featureResp.Feature.DefaultStrategy
+	if strategy == nil || strategy.Type != featureproto.Strategy_ROLLOUT {
+		return nil
+	}
+	endAt := time.Now().Unix()
+	if experiment.StopAt < endAt {
+		endAt = experiment.StopAt
+	}
+	variationIDs := make([]string, 0, len(experiment.Variations))
+	for _, v := range experiment.Variations {
+		variationIDs = append(variationIDs, v.Id)
+	}
+	// The users served by the targets, the rules or the prerequisites aren't split by the rollout weights.
+	countResp, err := c.eventCounterClient.GetEvaluationCountV2(ctx, &ecproto.GetEvaluationCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              experiment.StartAt,
+		EndAt:                endAt,
+		FeatureId:            experiment.FeatureId,
+		FeatureVersion:       experiment.FeatureVersion,
+		VariationIds:         variationIDs,
+		Reason:               featureproto.Reason_DEFAULT.String(),
+	})
+	if err != nil {
+		c.logger.Error("Failed to get evaluation count", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id),
+			zap.String("featureId", experiment.FeatureId))
+		return err
+	}
+	observed, weights := sampleRatio(experiment, strategy, countResp.Count.GetRealtimeCounts())
+	_, pValue, err := stats.SampleRatioMismatchTest(observed, weights)
+	if err != nil {
+		// The split can't be tested yet, e.g. no users were evaluated or the feature isn't rolled out.
+		return nil
+	}
+	err = c.resultStorage.UpdateSampleRatioMismatch(
+		ctx,
+		resultResp.ExperimentResult.Id,
+		environmentNamespace,
+		pValue,
+		time.Now().Unix(),
+	)
+	if err != nil {
+		c.logger.Error("Failed to update sample ratio mismatch", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("id", experiment.Id))
+		return err
+	}
+	return nil
+}
+
+// sampleRatio returns the evaluated user count and the rollout weight of each variation.
+// Variations without weight are excluded from the test.
+func sampleRatio(
+	experiment *experimentproto.Experiment,
+	strategy *featureproto.Strategy,
+	variationCounts []*ecproto.VariationCount,
+) ([]int64, []float64) {
+	weights := make(map[string]int32, len(strategy.RolloutStrategy.Variations))
+	for _, v := range strategy.RolloutStrategy.Variations {
+		weights[v.Variation] = v.Weight
+	}
+	counts := variationUserCounts(variationCounts)
+	observed := make([]int64, 0, len(experiment.Variations))
+	expected := make([]float64, 0, len(experiment.Variations))
+	for _, v := range experiment.Variations {
+		if weights[v.Id] <= 0 {
+			continue
+		}
+		observed = append(observed, counts[v.Id])
+		expected = append(expected, float64(weights[v.Id]))
+	}
+	return observed, expected
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	eventcountermock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	v2ecsmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/storage/v2/mock"
+	featuremock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestCheckSampleRatioMismatch(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	now := time.Now()
+	experiment := &experimentproto.Experiment{
+		Id:             "eid",
+		FeatureId:      "fid",
+		FeatureVersion: 2,
+		Variations: []*featureproto.Variation{
+			{Id: "vid-0"},
+			{Id: "vid-1"},
+		},
+		StartAt: now.Add(-24 * time.Hour).Unix(),
+		StopAt:  now.Add(24 * time.Hour).Unix(),
+	}
+	result := &ecproto.GetExperimentResultResponse{
+		ExperimentResult: &ecproto.ExperimentResult{
+			Id:           "eid",
+			ExperimentId: "eid",
+		},
+	}
+	// Only the users served by the default strategy are counted.
+	countRequest := gomock.AssignableToTypeOf(&ecproto.GetEvaluationCountV2Request{})
+	count := &ecproto.GetEvaluationCountV2Response{
+		Count: &ecproto.EvaluationCount{
+			FeatureId:      "fid",
+			FeatureVersion: 2,
+			RealtimeCounts: []*ecproto.VariationCount{
+				{VariationId: "vid-0", UserCount: 5000},
+				{VariationId: "vid-1", UserCount: 5200},
+			},
+		},
+	}
+	newFeature := func(strategy *featureproto.Strategy) *featureproto.GetFeatureResponse {
+		return &featureproto.GetFeatureResponse{
+			Feature: &featureproto.Feature{Id: "fid", DefaultStrategy: strategy},
+		}
+	}
+	rollout := &featureproto.Strategy{
+		Type: featureproto.Strategy_ROLLOUT,
+		RolloutStrategy: &featureproto.RolloutStrategy{
+			Variations: []*featureproto.RolloutStrategy_Variation{
+				{Variation: "vid-0", Weight: 50000},
+				{Variation: "vid-1", Weight: 50000},
+			},
+		},
+	}
+	patterns := map[string]struct {
+		setup    func(*experimentSRMChecker)
+		expected error
+	}{
+		"success: no result yet": {
+			setup: func(c *experimentSRMChecker) {
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, gstatus.Error(codes.NotFound, "not found"))
+			},
+			expected: nil,
+		},
+		"error: get feature fails": {
+			setup: func(c *experimentSRMChecker) {
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+				c.featureClient.(*featuremock.MockClient).EXPECT().GetFeature(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: fixed strategy is skipped": {
+			setup: func(c *experimentSRMChecker) {
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+				c.featureClient.(*featuremock.MockClient).EXPECT().GetFeature(
+					gomock.Any(), gomock.Any(),
+				).Return(newFeature(&featureproto.Strategy{
+					Type:          featureproto.Strategy_FIXED,
+					FixedStrategy: &featureproto.FixedStrategy{Variation: "vid-0"},
+				}), nil)
+			},
+			expected: nil,
+		},
+		"error: get evaluation count fails": {
+			setup: func(c *experimentSRMChecker) {
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+				c.featureClient.(*featuremock.MockClient).EXPECT().GetFeature(
+					gomock.Any(), gomock.Any(),
+				).Return(newFeature(rollout), nil)
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetEvaluationCountV2(
+					gomock.Any(), countRequest,
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: p-value is stored": {
+			setup: func(c *experimentSRMChecker) {
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+				c.featureClient.(*featuremock.MockClient).EXPECT().GetFeature(
+					gomock.Any(), gomock.Any(),
+				).Return(newFeature(rollout), nil)
+				c.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetEvaluationCountV2(
+					gomock.Any(), countRequest,
+				).DoAndReturn(func(
+					_ context.Context,
+					req *ecproto.GetEvaluationCountV2Request,
+					_ ...grpc.CallOption,
+				) (*ecproto.GetEvaluationCountV2Response, error) {
+					assert.Equal(t, "DEFAULT", req.Reason)
+					assert.Equal(t, int32(2), req.FeatureVersion)
+					assert.Equal(t, experiment.StartAt, req.StartAt)
+					assert.Equal(t, []string{"vid-0", "vid-1"}, req.VariationIds)
+					return count, nil
+				})
+				c.resultStorage.(*v2ecsmock.MockExperimentResultStorage).EXPECT().UpdateSampleRatioMismatch(
+					gomock.Any(), "eid", "ns", gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(_ context.Context, _, _ string, pValue float64, _ int64) error {
+					assert.InDelta(t, 0.047670, pValue, 1e-6)
+					return nil
+				})
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			checker := newMockExperimentSRMChecker(t, mockController)
+			if p.setup != nil {
+				p.setup(checker)
+			}
+			err := checker.check(context.Background(), "ns", experiment)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func newMockExperimentSRMChecker(t *testing.T, c *gomock.Controller) *experimentSRMChecker {
+	return &experimentSRMChecker{
+		featureClient:      featuremock.NewMockClient(c),
+		eventCounterClient: eventcountermock.NewMockClient(c),
+		resultStorage:      v2ecsmock.NewMockExperimentResultStorage(c),
+		opts: &options{
+			timeout: 5 * time.Second,
+		},
+		logger: zap.NewNop().Named("test-experiment-srm-checker"),
+	}
+}
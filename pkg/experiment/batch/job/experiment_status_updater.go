@@ -64,7 +64,7 @@ func NewExperimentStatusUpdater(
 func (u *experimentStatusUpdater) Run(ctx context.Context) (lastErr error) {
 	ctx, cancel := context.WithTimeout(ctx, u.opts.timeout)
 	defer cancel()
-	environments, err := u.listEnvironments(ctx)
+	environments, err := listEnvironments(ctx, u.environmentClient)
 	if err != nil {
 		u.logger.Error("Failed to list environments", zap.Error(err))
 		lastErr = err
@@ -77,7 +77,7 @@ func (u *experimentStatusUpdater) Run(ctx context.Context) (lastErr error) {
 			experimentproto.Experiment_RUNNING,
 		}
 		for _, status := range statuses {
-			exps, err := u.listExperiments(ctx, env.Namespace, status)
+			exps, err := listExperiments(ctx, u.experimentClient, env.Namespace, status)
 			if err != nil {
 				u.logger.Error("Failed to list experiments", zap.Error(err),
 					zap.String("environmentNamespace", env.Namespace),
@@ -174,15 +174,29 @@ func (u *experimentStatusUpdater) updateToStopped(
 	return nil
 }
 
-func (u *experimentStatusUpdater) listExperiments(
+func listExperiments(
 	ctx context.Context,
+	client experimentclient.Client,
 	environmentNamespace string,
 	status experimentproto.Experiment_Status,
+) ([]*experimentproto.Experiment, error) {
+	return listExperimentsStoppedSince(ctx, client, environmentNamespace, status, 0)
+}
+
+// listExperimentsStoppedSince lists the experiments in the status stopped at or after the time.
+// Zero lists them regardless of when they were stopped.
+func listExperimentsStoppedSince(
+	ctx context.Context,
+	client experimentclient.Client,
+	environmentNamespace string,
+	status experimentproto.Experiment_Status,
+	from int64,
 ) ([]*experimentproto.Experiment, error) {
 	experiments := []*experimentproto.Experiment{}
 	cursor := ""
 	for {
-		resp, err := u.experimentClient.ListExperiments(ctx, &experimentproto.ListExperimentsRequest{
+		resp, err := client.ListExperiments(ctx, &experimentproto.ListExperimentsRequest{
+			From:                 from,
 			PageSize:             listRequestSize,
 			Cursor:               cursor,
 			EnvironmentNamespace: environmentNamespace,
@@ -200,11 +214,14 @@ func (u *experimentStatusUpdater) listExperiments(
 	}
 }
 
-func (u *experimentStatusUpdater) listEnvironments(ctx context.Context) ([]*environmentproto.Environment, error) {
+func listEnvironments(
+	ctx context.Context,
+	client environmentclient.Client,
+) ([]*environmentproto.Environment, error) {
 	environments := []*environmentproto.Environment{}
 	cursor := ""
 	for {
-		resp, err := u.environmentClient.ListEnvironments(ctx, &environmentproto.ListEnvironmentsRequest{
+		resp, err := client.ListEnvironments(ctx, &environmentproto.ListEnvironmentsRequest{
 			PageSize: listRequestSize,
 			Cursor:   cursor,
 		})
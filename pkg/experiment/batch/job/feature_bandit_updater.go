@@ -0,0 +1,333 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	wrappersproto "github.com/golang/protobuf/ptypes/wrappers"
+	"go.uber.org/zap"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const (
+	banditSampleSize   = 10000
+	totalBanditWeight  = int32(100000)
+	banditUpdateReason = "Updated the bandit weights by Thompson sampling"
+)
+
+// featureBanditUpdater recomputes the weights of the bandit strategies of the features
+// from the conversion rates of their goals. The evaluations and goals counted since the
+// feature was last updated among the users served by each bandit strategy are added to its arms,
+// and the weights of all of them are updated at once when any of them is due,
+// since every update bumps the feature version.
+type featureBanditUpdater struct {
+	environmentClient  environmentclient.Client
+	featureClient      featureclient.Client
+	eventCounterClient ecclient.Client
+	rand               *rand.Rand
+	opts               *options
+	logger             *zap.Logger
+}
+
+func NewFeatureBanditUpdater(
+	environmentClient environmentclient.Client,
+	featureClient featureclient.Client,
+	eventCounterClient ecclient.Client,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &featureBanditUpdater{
+		environmentClient:  environmentClient,
+		featureClient:      featureClient,
+		eventCounterClient: eventCounterClient,
+		rand:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		opts:               dopts,
+		logger:             dopts.logger.Named("bandit-updater"),
+	}
+}
+
+func (u *featureBanditUpdater) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, u.opts.timeout)
+	defer cancel()
+	environments, err := listEnvironments(ctx, u.environmentClient)
+	if err != nil {
+		u.logger.Error("Failed to list environments", zap.Error(err))
+		lastErr = err
+		return
+	}
+	for _, env := range environments {
+		features, err := u.listFeatures(ctx, env.Namespace)
+		if err != nil {
+			u.logger.Error("Failed to list features", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		for _, f := range features {
+			if err := u.update(ctx, env.Namespace, f, time.Now().Unix()); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return
+}
+
+func (u *featureBanditUpdater) listFeatures(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Feature, error) {
+	features := []*featureproto.Feature{}
+	cursor := ""
+	for {
+		resp, err := u.featureClient.ListFeatures(ctx, &featureproto.ListFeaturesRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+			Enabled:              &wrappersproto.BoolValue{Value: true},
+			Archived:             &wrappersproto.BoolValue{Value: false},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Features {
+			if len(banditStrategies(f)) == 0 {
+				continue
+			}
+			features = append(features, f)
+		}
+		featureSize := len(resp.Features)
+		if featureSize == 0 || featureSize < listRequestSize {
+			return features, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+// banditStrategies returns the bandit strategies of the feature keyed by the rule ID.
+// The default strategy has an empty rule ID.
+func banditStrategies(feature *featureproto.Feature) map[string]*featureproto.Strategy {
+	strategies := map[string]*featureproto.Strategy{}
+	isBandit := func(s *featureproto.Strategy) bool {
+		return s != nil && s.Type == featureproto.Strategy_BANDIT && s.BanditStrategy != nil && s.RolloutStrategy != nil
+	}
+	if isBandit(feature.DefaultStrategy) {
+		strategies[""] = feature.DefaultStrategy
+	}
+	for _, r := range feature.Rules {
+		if isBandit(r.Strategy) {
+			strategies[r.Id] = r.Strategy
+		}
+	}
+	return strategies
+}
+
+func (u *featureBanditUpdater) update(
+	ctx context.Context,
+	environmentNamespace string,
+	feature *featureproto.Feature,
+	now int64,
+) error {
+	strategies := banditStrategies(feature)
+	due := false
+	for _, s := range strategies {
+		if s.BanditStrategy.UpdatedAt+s.BanditStrategy.UpdateInterval <= now {
+			due = true
+			break
+		}
+	}
+	if !due {
+		return nil
+	}
+	cmds := []*featureproto.Command{}
+	for ruleID, s := range strategies {
+		trials, conversions, err := u.strategyCounts(ctx, environmentNamespace, feature, ruleID, s.BanditStrategy.GoalId, now)
+		if err != nil {
+			return err
+		}
+		weights, arms, err := banditWeights(s, trials, conversions, u.rand)
+		if err != nil {
+			u.logger.Error("Failed to compute bandit weights", zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+				zap.String("featureId", feature.Id),
+				zap.String("ruleId", ruleID))
+			return err
+		}
+		cmd, err := ptypes.MarshalAny(&featureproto.UpdateBanditWeightsCommand{
+			RuleId:          ruleID,
+			RolloutStrategy: weights,
+			Arms:            arms,
+			UpdatedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, &featureproto.Command{Command: cmd})
+	}
+	_, err := u.featureClient.UpdateFeatureTargeting(ctx, &featureproto.UpdateFeatureTargetingRequest{
+		Id:                   feature.Id,
+		Commands:             cmds,
+		EnvironmentNamespace: environmentNamespace,
+		Comment:              banditUpdateReason,
+	})
+	if err != nil {
+		u.logger.Error("Failed to update bandit weights", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("featureId", feature.Id))
+		return err
+	}
+	return nil
+}
+
+// strategyCounts returns the users evaluated and converted in each variation
+// among the users served by the strategy of the rule.
+// The default strategy has an empty rule ID.
+func (u *featureBanditUpdater) strategyCounts(
+	ctx context.Context,
+	environmentNamespace string,
+	feature *featureproto.Feature,
+	ruleID, goalID string,
+	now int64,
+) (map[string]int64, map[string]int64, error) {
+	reason := featureproto.Reason_DEFAULT.String()
+	if ruleID != "" {
+		reason = featureproto.Reason_RULE.String()
+	}
+	evaluationResp, err := u.eventCounterClient.GetEvaluationCountV2(ctx, &ecproto.GetEvaluationCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              feature.UpdatedAt,
+		EndAt:                now,
+		FeatureId:            feature.Id,
+		FeatureVersion:       feature.Version,
+		VariationIds:         variationIDs(feature),
+		Reason:               reason,
+		RuleId:               ruleID,
+	})
+	if err != nil {
+		u.logger.Error("Failed to get evaluation count", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("featureId", feature.Id),
+			zap.String("ruleId", ruleID))
+		return nil, nil, err
+	}
+	goalResp, err := u.eventCounterClient.GetGoalCountV2(ctx, &ecproto.GetGoalCountV2Request{
+		EnvironmentNamespace: environmentNamespace,
+		StartAt:              feature.UpdatedAt,
+		EndAt:                now,
+		GoalId:               goalID,
+		FeatureId:            feature.Id,
+		FeatureVersion:       feature.Version,
+		VariationIds:         variationIDs(feature),
+		Reason:               reason,
+		RuleId:               ruleID,
+	})
+	if err != nil {
+		u.logger.Error("Failed to get goal count", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("featureId", feature.Id),
+			zap.String("ruleId", ruleID),
+			zap.String("goalId", goalID))
+		return nil, nil, err
+	}
+	trials := variationUserCounts(evaluationResp.Count.GetRealtimeCounts())
+	conversions := variationUserCounts(goalResp.GoalCounts.GetRealtimeCounts())
+	return trials, conversions, nil
+}
+
+func variationIDs(feature *featureproto.Feature) []string {
+	ids := make([]string, 0, len(feature.Variations))
+	for _, v := range feature.Variations {
+		ids = append(ids, v.Id)
+	}
+	return ids
+}
+
+func variationUserCounts(counts []*ecproto.VariationCount) map[string]int64 {
+	userCounts := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		userCounts[c.VariationId] += c.UserCount
+	}
+	return userCounts
+}
+
+// banditWeights adds the new trials and conversions to the arms of the strategy and
+// returns the weights proportional to the probability of each variation being the best,
+// while every variation keeps at least the minimum exploration weight.
+func banditWeights(
+	strategy *featureproto.Strategy,
+	trials, conversions map[string]int64,
+	r *rand.Rand,
+) (*featureproto.RolloutStrategy, []*featureproto.BanditStrategy_Arm, error) {
+	previous := make(map[string]*featureproto.BanditStrategy_Arm, len(strategy.BanditStrategy.Arms))
+	for _, a := range strategy.BanditStrategy.Arms {
+		previous[a.Variation] = a
+	}
+	variations := strategy.RolloutStrategy.Variations
+	arms := make([]*featureproto.BanditStrategy_Arm, 0, len(variations))
+	banditArms := make([]stats.BanditArm, 0, len(variations))
+	for _, v := range variations {
+		arm := &featureproto.BanditStrategy_Arm{
+			Variation:   v.Variation,
+			Trials:      previous[v.Variation].GetTrials() + trials[v.Variation],
+			Conversions: previous[v.Variation].GetConversions() + conversions[v.Variation],
+		}
+		// Goal events may be attributed to users whose evaluations were counted in the previous window.
+		if arm.Conversions > arm.Trials {
+			arm.Conversions = arm.Trials
+		}
+		arms = append(arms, arm)
+		banditArms = append(banditArms, stats.BanditArm{Trials: arm.Trials, Conversions: arm.Conversions})
+	}
+	probabilities, err := stats.ThompsonSampling(banditArms, banditSampleSize, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	minWeight := strategy.BanditStrategy.MinExplorationWeight
+	explorable := totalBanditWeight - minWeight*int32(len(variations))
+	weights := &featureproto.RolloutStrategy{}
+	sum := int32(0)
+	best := 0
+	for i, v := range variations {
+		w := minWeight + int32(probabilities[i]*float64(explorable))
+		weights.Variations = append(weights.Variations, &featureproto.RolloutStrategy_Variation{
+			Variation: v.Variation,
+			Weight:    w,
+		})
+		sum += w
+		if probabilities[i] > probabilities[best] {
+			best = i
+		}
+	}
+	// The rounding error goes to the best variation so that the weights sum up to 100%.
+	weights.Variations[best].Weight += totalBanditWeight - sum
+	return weights, arms, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	eventcountermock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	featuremock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func newBanditStrategy(updatedAt int64) *featureproto.Strategy {
+	return &featureproto.Strategy{
+		Type: featureproto.Strategy_BANDIT,
+		RolloutStrategy: &featureproto.RolloutStrategy{
+			Variations: []*featureproto.RolloutStrategy_Variation{
+				{Variation: "vid-0", Weight: 50000},
+				{Variation: "vid-1", Weight: 50000},
+			},
+		},
+		BanditStrategy: &featureproto.BanditStrategy{
+			GoalId:               "gid",
+			UpdateInterval:       3600,
+			MinExplorationWeight: 10000,
+			UpdatedAt:            updatedAt,
+			Arms: []*featureproto.BanditStrategy_Arm{
+				{Variation: "vid-0", Trials: 1000, Conversions: 100},
+				{Variation: "vid-1", Trials: 1000, Conversions: 100},
+			},
+		},
+	}
+}
+
+func TestBanditWeights(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	weights, arms, err := banditWeights(
+		newBanditStrategy(0),
+		map[string]int64{"vid-0": 1000, "vid-1": 1000},
+		map[string]int64{"vid-0": 100, "vid-1": 200},
+		r,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []*featureproto.BanditStrategy_Arm{
+		{Variation: "vid-0", Trials: 2000, Conversions: 200},
+		{Variation: "vid-1", Trials: 2000, Conversions: 300},
+	}, arms)
+	require.Len(t, weights.Variations, 2)
+	assert.Equal(t, "vid-0", weights.Variations[0].Variation)
+	assert.Equal(t, int32(10000), weights.Variations[0].Weight)
+	assert.Equal(t, int32(90000), weights.Variations[1].Weight)
+
+	weights, arms, err = banditWeights(
+		newBanditStrategy(0),
+		map[string]int64{"vid-0": 10},
+		map[string]int64{"vid-0": 20, "vid-1": 2000},
+		r,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1010), arms[0].Trials)
+	assert.Equal(t, int64(120), arms[0].Conversions)
+	assert.Equal(t, int64(1000), arms[1].Conversions)
+	assert.Equal(t, totalBanditWeight, weights.Variations[0].Weight+weights.Variations[1].Weight)
+	assert.GreaterOrEqual(t, weights.Variations[1].Weight, int32(10000))
+}
+
+func TestUpdateBandit(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	now := int64(100000)
+	newFeature := func(defaultStrategy, ruleStrategy *featureproto.Strategy) *featureproto.Feature {
+		return &featureproto.Feature{
+			Id:        "fid",
+			Version:   3,
+			UpdatedAt: now - 7200,
+			Variations: []*featureproto.Variation{
+				{Id: "vid-0"},
+				{Id: "vid-1"},
+			},
+			DefaultStrategy: defaultStrategy,
+			Rules: []*featureproto.Rule{
+				{Id: "rid", Strategy: ruleStrategy},
+			},
+		}
+	}
+	fixed := &featureproto.Strategy{
+		Type:          featureproto.Strategy_FIXED,
+		FixedStrategy: &featureproto.FixedStrategy{Variation: "vid-0"},
+	}
+	patterns := map[string]struct {
+		feature  *featureproto.Feature
+		setup    func(*featureBanditUpdater)
+		expected error
+	}{
+		"success: not due": {
+			feature:  newFeature(newBanditStrategy(now-60), fixed),
+			expected: nil,
+		},
+		"error: get evaluation count fails": {
+			feature: newFeature(newBanditStrategy(now-3600), fixed),
+			setup: func(u *featureBanditUpdater) {
+				u.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetEvaluationCountV2(
+					gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("test"))
+			},
+			expected: errors.New("test"),
+		},
+		"success: updates every bandit strategy when one is due": {
+			feature: newFeature(newBanditStrategy(now-3600), newBanditStrategy(now-60)),
+			setup: func(u *featureBanditUpdater) {
+				for _, s := range []struct {
+					reason, ruleID    string
+					trials, converted int64
+				}{
+					{reason: "DEFAULT", trials: 1000, converted: 200},
+					{reason: "RULE", ruleID: "rid", trials: 500, converted: 50},
+				} {
+					u.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetEvaluationCountV2(
+						gomock.Any(), &ecproto.GetEvaluationCountV2Request{
+							EnvironmentNamespace: "ns",
+							StartAt:              now - 7200,
+							EndAt:                now,
+							FeatureId:            "fid",
+							FeatureVersion:       3,
+							VariationIds:         []string{"vid-0", "vid-1"},
+							Reason:               s.reason,
+							RuleId:               s.ruleID,
+						},
+					).Return(&ecproto.GetEvaluationCountV2Response{
+						Count: &ecproto.EvaluationCount{
+							RealtimeCounts: []*ecproto.VariationCount{
+								{VariationId: "vid-0", UserCount: s.trials},
+								{VariationId: "vid-1", UserCount: s.trials},
+							},
+						},
+					}, nil)
+					u.eventCounterClient.(*eventcountermock.MockClient).EXPECT().GetGoalCountV2(
+						gomock.Any(), &ecproto.GetGoalCountV2Request{
+							EnvironmentNamespace: "ns",
+							StartAt:              now - 7200,
+							EndAt:                now,
+							GoalId:               "gid",
+							FeatureId:            "fid",
+							FeatureVersion:       3,
+							VariationIds:         []string{"vid-0", "vid-1"},
+							Reason:               s.reason,
+							RuleId:               s.ruleID,
+						},
+					).Return(&ecproto.GetGoalCountV2Response{
+						GoalCounts: &ecproto.GoalCounts{
+							RealtimeCounts: []*ecproto.VariationCount{
+								{VariationId: "vid-0", UserCount: 100},
+								{VariationId: "vid-1", UserCount: s.converted},
+							},
+						},
+					}, nil)
+				}
+				u.featureClient.(*featuremock.MockClient).EXPECT().UpdateFeatureTargeting(
+					gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(
+					_ context.Context,
+					req *featureproto.UpdateFeatureTargetingRequest,
+					_ ...grpc.CallOption,
+				) (*featureproto.UpdateFeatureTargetingResponse, error) {
+					assert.Equal(t, "fid", req.Id)
+					assert.Equal(t, banditUpdateReason, req.Comment)
+					require.Len(t, req.Commands, 2)
+					arms := map[string][]*featureproto.BanditStrategy_Arm{}
+					for _, c := range req.Commands {
+						cmd := &featureproto.UpdateBanditWeightsCommand{}
+						require.NoError(t, c.Command.UnmarshalTo(cmd))
+						assert.Equal(t, now, cmd.UpdatedAt)
+						arms[cmd.RuleId] = cmd.Arms
+					}
+					assert.Equal(t, map[string][]*featureproto.BanditStrategy_Arm{
+						"": {
+							{Variation: "vid-0", Trials: 2000, Conversions: 200},
+							{Variation: "vid-1", Trials: 2000, Conversions: 300},
+						},
+						"rid": {
+							{Variation: "vid-0", Trials: 1500, Conversions: 200},
+							{Variation: "vid-1", Trials: 1500, Conversions: 150},
+						},
+					}, arms)
+					return &featureproto.UpdateFeatureTargetingResponse{}, nil
+				})
+			},
+			expected: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			updater := newMockFeatureBanditUpdater(t, mockController)
+			if p.setup != nil {
+				p.setup(updater)
+			}
+			err := updater.update(context.Background(), "ns", p.feature, now)
+			assert.Equal(t, p.expected, err)
+		})
+	}
+}
+
+func newMockFeatureBanditUpdater(t *testing.T, c *gomock.Controller) *featureBanditUpdater {
+	return &featureBanditUpdater{
+		featureClient:      featuremock.NewMockClient(c),
+		eventCounterClient: eventcountermock.NewMockClient(c),
+		rand:               rand.New(rand.NewSource(1)),
+		opts:               &options{},
+		logger:             zap.NewNop(),
+	}
+}
@@ -129,6 +129,26 @@ func (mr *MockClientMockRecorder) CreateGoal(ctx, in interface{}, opts ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGoal", reflect.TypeOf((*MockClient)(nil).CreateGoal), varargs...)
 }
 
+// CreateLayer mocks base method.
+func (m *MockClient) CreateLayer(ctx context.Context, in *experiment.CreateLayerRequest, opts ...grpc.CallOption) (*experiment.CreateLayerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateLayer", varargs...)
+	ret0, _ := ret[0].(*experiment.CreateLayerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLayer indicates an expected call of CreateLayer.
+func (mr *MockClientMockRecorder) CreateLayer(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLayer", reflect.TypeOf((*MockClient)(nil).CreateLayer), varargs...)
+}
+
 // DeleteExperiment mocks base method.
 func (m *MockClient) DeleteExperiment(ctx context.Context, in *experiment.DeleteExperimentRequest, opts ...grpc.CallOption) (*experiment.DeleteExperimentResponse, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +189,26 @@ func (mr *MockClientMockRecorder) DeleteGoal(ctx, in interface{}, opts ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGoal", reflect.TypeOf((*MockClient)(nil).DeleteGoal), varargs...)
 }
 
+// EstimateSampleSize mocks base method.
+func (m *MockClient) EstimateSampleSize(ctx context.Context, in *experiment.EstimateSampleSizeRequest, opts ...grpc.CallOption) (*experiment.EstimateSampleSizeResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EstimateSampleSize", varargs...)
+	ret0, _ := ret[0].(*experiment.EstimateSampleSizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateSampleSize indicates an expected call of EstimateSampleSize.
+func (mr *MockClientMockRecorder) EstimateSampleSize(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateSampleSize", reflect.TypeOf((*MockClient)(nil).EstimateSampleSize), varargs...)
+}
+
 // FinishExperiment mocks base method.
 func (m *MockClient) FinishExperiment(ctx context.Context, in *experiment.FinishExperimentRequest, opts ...grpc.CallOption) (*experiment.FinishExperimentResponse, error) {
 	m.ctrl.T.Helper()
@@ -229,6 +269,26 @@ func (mr *MockClientMockRecorder) GetGoal(ctx, in interface{}, opts ...interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGoal", reflect.TypeOf((*MockClient)(nil).GetGoal), varargs...)
 }
 
+// GetLayer mocks base method.
+func (m *MockClient) GetLayer(ctx context.Context, in *experiment.GetLayerRequest, opts ...grpc.CallOption) (*experiment.GetLayerResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetLayer", varargs...)
+	ret0, _ := ret[0].(*experiment.GetLayerResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLayer indicates an expected call of GetLayer.
+func (mr *MockClientMockRecorder) GetLayer(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayer", reflect.TypeOf((*MockClient)(nil).GetLayer), varargs...)
+}
+
 // ListExperiments mocks base method.
 func (m *MockClient) ListExperiments(ctx context.Context, in *experiment.ListExperimentsRequest, opts ...grpc.CallOption) (*experiment.ListExperimentsResponse, error) {
 	m.ctrl.T.Helper()
@@ -269,6 +329,26 @@ func (mr *MockClientMockRecorder) ListGoals(ctx, in interface{}, opts ...interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGoals", reflect.TypeOf((*MockClient)(nil).ListGoals), varargs...)
 }
 
+// ListLayers mocks base method.
+func (m *MockClient) ListLayers(ctx context.Context, in *experiment.ListLayersRequest, opts ...grpc.CallOption) (*experiment.ListLayersResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListLayers", varargs...)
+	ret0, _ := ret[0].(*experiment.ListLayersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLayers indicates an expected call of ListLayers.
+func (mr *MockClientMockRecorder) ListLayers(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLayers", reflect.TypeOf((*MockClient)(nil).ListLayers), varargs...)
+}
+
 // StartExperiment mocks base method.
 func (m *MockClient) StartExperiment(ctx context.Context, in *experiment.StartExperimentRequest, opts ...grpc.CallOption) (*experiment.StartExperimentResponse, error) {
 	m.ctrl.T.Helper()
@@ -24,34 +24,54 @@ import (
 
 	"github.com/bucketeer-io/bucketeer/pkg/cli"
 	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
 	experimentjob "github.com/bucketeer-io/bucketeer/pkg/experiment/batch/job"
 	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/health"
 	"github.com/bucketeer-io/bucketeer/pkg/job"
 	"github.com/bucketeer-io/bucketeer/pkg/metrics"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc/client"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
 )
 
 const command = "batch"
 
 type batch struct {
 	*kingpin.CmdClause
-	port               *int
-	project            *string
-	environmentService *string
-	experimentService  *string
-	certPath           *string
-	keyPath            *string
-	serviceTokenPath   *string
+	port                     *int
+	project                  *string
+	mysqlUser                *string
+	mysqlPass                *string
+	mysqlHost                *string
+	mysqlPort                *int
+	mysqlDBName              *string
+	environmentService       *string
+	experimentService        *string
+	featureService           *string
+	eventCounterService      *string
+	certPath                 *string
+	keyPath                  *string
+	serviceTokenPath         *string
+	scheduleSRMChecker       *string
+	scheduleSequentialTester *string
+	scheduleGuardrailWatcher *string
+	scheduleBanditUpdater    *string
+	scheduleFeatureReleaser  *string
 }
 
 func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 	cmd := p.Command(command, "Start batch layer")
 	batch := &batch{
-		CmdClause: cmd,
-		port:      cmd.Flag("port", "Port to bind to.").Default("9090").Int(),
-		project:   cmd.Flag("project", "Google Cloud project name.").String(),
+		CmdClause:   cmd,
+		port:        cmd.Flag("port", "Port to bind to.").Default("9090").Int(),
+		project:     cmd.Flag("project", "Google Cloud project name.").String(),
+		mysqlUser:   cmd.Flag("mysql-user", "MySQL user.").Required().String(),
+		mysqlPass:   cmd.Flag("mysql-pass", "MySQL password.").Required().String(),
+		mysqlHost:   cmd.Flag("mysql-host", "MySQL host.").Required().String(),
+		mysqlPort:   cmd.Flag("mysql-port", "MySQL port.").Required().Int(),
+		mysqlDBName: cmd.Flag("mysql-db-name", "MySQL database name.").Required().String(),
 		environmentService: cmd.Flag(
 			"environment-service",
 			"bucketeer-environment-service address.",
@@ -60,9 +80,37 @@ func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 			"experiment-service",
 			"bucketeer-experiment-service address.",
 		).Default("experiment:9090").String(),
+		featureService: cmd.Flag(
+			"feature-service",
+			"bucketeer-feature-service address.",
+		).Default("feature:9090").String(),
+		eventCounterService: cmd.Flag(
+			"event-counter-service",
+			"bucketeer-event-counter-service address.",
+		).Default("event-counter-server:9090").String(),
 		certPath:         cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
 		keyPath:          cmd.Flag("key", "Path to TLS key.").Required().String(),
 		serviceTokenPath: cmd.Flag("service-token", "Path to service token.").Required().String(),
+		scheduleSRMChecker: cmd.Flag(
+			"schedule-srm-checker",
+			"Cron style schedule for sample ratio mismatch checker.",
+		).Default("0 0 * * * *").String(),
+		scheduleSequentialTester: cmd.Flag(
+			"schedule-sequential-tester",
+			"Cron style schedule for sequential tester.",
+		).Default("0 */10 * * * *").String(),
+		scheduleGuardrailWatcher: cmd.Flag(
+			"schedule-guardrail-watcher",
+			"Cron style schedule for guardrail watcher.",
+		).Default("0 */10 * * * *").String(),
+		scheduleBanditUpdater: cmd.Flag(
+			"schedule-bandit-updater",
+			"Cron style schedule for bandit weights updater.",
+		).Default("0 */5 * * * *").String(),
+		scheduleFeatureReleaser: cmd.Flag(
+			"schedule-feature-releaser",
+			"Cron style schedule for feature releaser of finished experiments.",
+		).Default("0 */10 * * * *").String(),
 	}
 	r.RegisterCommand(batch)
 	return batch
@@ -75,6 +123,12 @@ func (b *batch) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Lo
 
 	registerer := metrics.DefaultRegisterer()
 
+	mysqlClient, err := b.createMySQLClient(ctx, registerer, logger)
+	if err != nil {
+		return err
+	}
+	defer mysqlClient.Close()
+
 	creds, err := client.NewPerRPCCredentials(*b.serviceTokenPath)
 	if err != nil {
 		return err
@@ -99,13 +153,33 @@ func (b *batch) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Lo
 	}
 	defer experimentClient.Close()
 
+	featureClient, err := featureclient.NewClient(*b.featureService, *b.certPath, clientOptions...)
+	if err != nil {
+		return err
+	}
+	defer featureClient.Close()
+
+	eventCounterClient, err := ecclient.NewClient(*b.eventCounterService, *b.certPath, clientOptions...)
+	if err != nil {
+		return err
+	}
+	defer eventCounterClient.Close()
+
 	manager := job.NewManager(
 		registerer,
 		"experiment_batch",
 		logger,
 	)
 	defer manager.Stop()
-	err = b.registerJobs(manager, environmentClient, experimentClient, logger)
+	err = b.registerJobs(
+		manager,
+		mysqlClient,
+		environmentClient,
+		experimentClient,
+		featureClient,
+		eventCounterClient,
+		logger,
+	)
 	if err != nil {
 		return err
 	}
@@ -132,8 +206,11 @@ func (b *batch) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Lo
 
 func (b *batch) registerJobs(
 	m *job.Manager,
+	mysqlClient mysql.Client,
 	environmentClient environmentclient.Client,
 	experimentClient experimentclient.Client,
+	featureClient featureclient.Client,
+	eventCounterClient ecclient.Client,
 	logger *zap.Logger) error {
 
 	jobs := []struct {
@@ -149,6 +226,55 @@ func (b *batch) registerJobs(
 				experimentClient,
 				experimentjob.WithLogger(logger)),
 		},
+		{
+			cron: *b.scheduleSRMChecker,
+			name: "experiment_srm_checker",
+			job: experimentjob.NewExperimentSRMChecker(
+				environmentClient,
+				experimentClient,
+				featureClient,
+				eventCounterClient,
+				mysqlClient,
+				experimentjob.WithLogger(logger)),
+		},
+		{
+			cron: *b.scheduleSequentialTester,
+			name: "experiment_sequential_tester",
+			job: experimentjob.NewExperimentSequentialTester(
+				environmentClient,
+				experimentClient,
+				featureClient,
+				eventCounterClient,
+				experimentjob.WithLogger(logger)),
+		},
+		{
+			cron: *b.scheduleGuardrailWatcher,
+			name: "experiment_guardrail_watcher",
+			job: experimentjob.NewExperimentGuardrailWatcher(
+				environmentClient,
+				experimentClient,
+				featureClient,
+				eventCounterClient,
+				experimentjob.WithLogger(logger)),
+		},
+		{
+			cron: *b.scheduleBanditUpdater,
+			name: "feature_bandit_updater",
+			job: experimentjob.NewFeatureBanditUpdater(
+				environmentClient,
+				featureClient,
+				eventCounterClient,
+				experimentjob.WithLogger(logger)),
+		},
+		{
+			cron: *b.scheduleFeatureReleaser,
+			name: "experiment_feature_releaser",
+			job: experimentjob.NewExperimentFeatureReleaser(
+				environmentClient,
+				experimentClient,
+				featureClient,
+				experimentjob.WithLogger(logger)),
+		},
 	}
 	for i := range jobs {
 		if err := m.AddCronJob(jobs[i].name, jobs[i].cron, jobs[i].job); err != nil {
@@ -162,6 +288,23 @@ func (b *batch) registerJobs(
 	return nil
 }
 
+func (b *batch) createMySQLClient(
+	ctx context.Context,
+	registerer metrics.Registerer,
+	logger *zap.Logger,
+) (mysql.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return mysql.NewClient(
+		ctx,
+		*b.mysqlUser, *b.mysqlPass, *b.mysqlHost,
+		*b.mysqlPort,
+		*b.mysqlDBName,
+		mysql.WithLogger(logger),
+		mysql.WithMetrics(registerer),
+	)
+}
+
 // for telepresence --swap-deployment
 func (b *batch) insertTelepresenceMountRoot(path string) string {
 	volumeRoot := os.Getenv("TELEPRESENCE_ROOT")
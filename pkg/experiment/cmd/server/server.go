@@ -24,6 +24,7 @@ import (
 
 	accountclient "github.com/bucketeer-io/bucketeer/pkg/account/client"
 	"github.com/bucketeer-io/bucketeer/pkg/cli"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
 	"github.com/bucketeer-io/bucketeer/pkg/experiment/api"
 	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/health"
@@ -40,19 +41,20 @@ const command = "server"
 
 type server struct {
 	*kingpin.CmdClause
-	port             *int
-	project          *string
-	mysqlUser        *string
-	mysqlPass        *string
-	mysqlHost        *string
-	mysqlPort        *int
-	mysqlDBName      *string
-	topic            *string
-	featureService   *string
-	accountService   *string
-	certPath         *string
-	keyPath          *string
-	serviceTokenPath *string
+	port                *int
+	project             *string
+	mysqlUser           *string
+	mysqlPass           *string
+	mysqlHost           *string
+	mysqlPort           *int
+	mysqlDBName         *string
+	topic               *string
+	featureService      *string
+	accountService      *string
+	eventCounterService *string
+	certPath            *string
+	keyPath             *string
+	serviceTokenPath    *string
 
 	oauthKeyPath  *string
 	oauthClientID *string
@@ -62,17 +64,21 @@ type server struct {
 func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 	cmd := p.Command(command, "Start the server")
 	server := &server{
-		CmdClause:        cmd,
-		port:             cmd.Flag("port", "Port to bind to.").Default("9090").Int(),
-		project:          cmd.Flag("project", "Google Cloud project name.").String(),
-		mysqlUser:        cmd.Flag("mysql-user", "MySQL user.").Required().String(),
-		mysqlPass:        cmd.Flag("mysql-pass", "MySQL password.").Required().String(),
-		mysqlHost:        cmd.Flag("mysql-host", "MySQL host.").Required().String(),
-		mysqlPort:        cmd.Flag("mysql-port", "MySQL port.").Required().Int(),
-		mysqlDBName:      cmd.Flag("mysql-db-name", "MySQL database name.").Required().String(),
-		topic:            cmd.Flag("topic", "PubSub topic to publish domain events.").Required().String(),
-		featureService:   cmd.Flag("feature-service", "bucketeer-feature-service address.").Default("feature:9090").String(),
-		accountService:   cmd.Flag("account-service", "bucketeer-account-service address.").Default("account:9090").String(),
+		CmdClause:      cmd,
+		port:           cmd.Flag("port", "Port to bind to.").Default("9090").Int(),
+		project:        cmd.Flag("project", "Google Cloud project name.").String(),
+		mysqlUser:      cmd.Flag("mysql-user", "MySQL user.").Required().String(),
+		mysqlPass:      cmd.Flag("mysql-pass", "MySQL password.").Required().String(),
+		mysqlHost:      cmd.Flag("mysql-host", "MySQL host.").Required().String(),
+		mysqlPort:      cmd.Flag("mysql-port", "MySQL port.").Required().Int(),
+		mysqlDBName:    cmd.Flag("mysql-db-name", "MySQL database name.").Required().String(),
+		topic:          cmd.Flag("topic", "PubSub topic to publish domain events.").Required().String(),
+		featureService: cmd.Flag("feature-service", "bucketeer-feature-service address.").Default("feature:9090").String(),
+		accountService: cmd.Flag("account-service", "bucketeer-account-service address.").Default("account:9090").String(),
+		eventCounterService: cmd.Flag(
+			"event-counter-service",
+			"bucketeer-event-counter-service address.",
+		).Default("event-counter-server:9090").String(),
 		certPath:         cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
 		keyPath:          cmd.Flag("key", "Path to TLS key.").Required().String(),
 		serviceTokenPath: cmd.Flag("service-token", "Path to service token.").Required().String(),
@@ -133,9 +139,22 @@ func (s *server) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.L
 	}
 	defer accountClient.Close()
 
+	eventCounterClient, err := ecclient.NewClient(*s.eventCounterService, *s.certPath,
+		client.WithPerRPCCredentials(creds),
+		client.WithDialTimeout(30*time.Second),
+		client.WithBlock(),
+		client.WithMetrics(registerer),
+		client.WithLogger(logger),
+	)
+	if err != nil {
+		return err
+	}
+	defer eventCounterClient.Close()
+
 	service := api.NewExperimentService(
 		featureClient,
 		accountClient,
+		eventCounterClient,
 		mysqlClient,
 		publisher,
 		api.WithLogger(logger),
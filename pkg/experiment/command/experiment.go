@@ -67,6 +67,10 @@ func (h *experimentCommandHandler) Handle(ctx context.Context, cmd Command) erro
 		return h.archive(ctx, c)
 	case *proto.DeleteExperimentCommand:
 		return h.delete(ctx, c)
+	case *proto.ChangeSampleSizeEstimateCommand:
+		return h.changeSampleSizeEstimate(ctx, c)
+	case *proto.ApplyDefaultVariationCommand:
+		return h.applyDefaultVariation(ctx, c)
 	default:
 		return ErrUnknownCommand
 	}
@@ -74,21 +78,31 @@ func (h *experimentCommandHandler) Handle(ctx context.Context, cmd Command) erro
 
 func (h *experimentCommandHandler) create(ctx context.Context, cmd *proto.CreateExperimentCommand) error {
 	return h.send(ctx, eventproto.Event_EXPERIMENT_CREATED, &eventproto.ExperimentCreatedEvent{
-		Id:              h.experiment.Id,
-		FeatureId:       h.experiment.FeatureId,
-		FeatureVersion:  h.experiment.FeatureVersion,
-		Variations:      h.experiment.Variations,
-		GoalId:          h.experiment.GoalId,
-		GoalIds:         h.experiment.GoalIds,
-		StartAt:         h.experiment.StartAt,
-		StopAt:          h.experiment.StopAt,
-		Stopped:         h.experiment.Stopped,
-		StoppedAt:       h.experiment.StoppedAt,
-		CreatedAt:       h.experiment.CreatedAt,
-		UpdatedAt:       h.experiment.UpdatedAt,
-		Name:            h.experiment.Name,
-		Description:     h.experiment.Description,
-		BaseVariationId: h.experiment.BaseVariationId,
+		Id:                        h.experiment.Id,
+		FeatureId:                 h.experiment.FeatureId,
+		FeatureVersion:            h.experiment.FeatureVersion,
+		Variations:                h.experiment.Variations,
+		GoalId:                    h.experiment.GoalId,
+		GoalIds:                   h.experiment.GoalIds,
+		StartAt:                   h.experiment.StartAt,
+		StopAt:                    h.experiment.StopAt,
+		Stopped:                   h.experiment.Stopped,
+		StoppedAt:                 h.experiment.StoppedAt,
+		CreatedAt:                 h.experiment.CreatedAt,
+		UpdatedAt:                 h.experiment.UpdatedAt,
+		Name:                      h.experiment.Name,
+		Description:               h.experiment.Description,
+		BaseVariationId:           h.experiment.BaseVariationId,
+		SequentialTesting:         h.experiment.SequentialTesting,
+		GoalConfigs:               h.experiment.GoalConfigs,
+		RollbackOnGuardrailBreach: h.experiment.RollbackOnGuardrailBreach,
+		LayerId:                   h.experiment.LayerId,
+		LayerSliceStart:           h.experiment.LayerSliceStart,
+		LayerSliceEnd:             h.experiment.LayerSliceEnd,
+		TrafficAllocation:         h.experiment.TrafficAllocation,
+		Audience:                  h.experiment.Audience,
+		VarianceReduction:         h.experiment.VarianceReduction,
+		SampleSizeEstimate:        h.experiment.SampleSizeEstimate,
 	})
 }
 
@@ -127,13 +141,54 @@ func (h *experimentCommandHandler) changeDescription(
 }
 
 func (h *experimentCommandHandler) stop(ctx context.Context, cmd *proto.StopExperimentCommand) error {
-	if err := h.experiment.Stop(); err != nil {
+	if err := h.experiment.Stop(cmd.Reason, cmd.WinnerVariationId); err != nil {
 		return err
 	}
-	return h.send(ctx, eventproto.Event_EXPERIMENT_STOPPED, &eventproto.ExperimentStoppedEvent{
-		Id:        h.experiment.Id,
-		StoppedAt: h.experiment.StoppedAt,
+	err := h.send(ctx, eventproto.Event_EXPERIMENT_STOPPED, &eventproto.ExperimentStoppedEvent{
+		Id:                h.experiment.Id,
+		StoppedAt:         h.experiment.StoppedAt,
+		Reason:            h.experiment.StopReason,
+		WinnerVariationId: h.experiment.WinnerVariationId,
 	})
+	if err != nil {
+		return err
+	}
+	if cmd.Reason != proto.Experiment_GUARDRAIL_BREACH {
+		return nil
+	}
+	return h.send(ctx, eventproto.Event_EXPERIMENT_GUARDRAIL_BREACHED, &eventproto.ExperimentGuardrailBreachedEvent{
+		Id:       h.experiment.Id,
+		Breaches: cmd.GuardrailBreaches,
+	})
+}
+
+func (h *experimentCommandHandler) changeSampleSizeEstimate(
+	ctx context.Context,
+	cmd *proto.ChangeSampleSizeEstimateCommand,
+) error {
+	h.experiment.SetSampleSizeEstimate(cmd.SampleSizeEstimate)
+	return h.send(ctx, eventproto.Event_EXPERIMENT_SAMPLE_SIZE_ESTIMATED, &eventproto.ExperimentSampleSizeEstimatedEvent{
+		Id:                 h.experiment.Id,
+		SampleSizeEstimate: cmd.SampleSizeEstimate,
+	})
+}
+
+func (h *experimentCommandHandler) applyDefaultVariation(
+	ctx context.Context,
+	cmd *proto.ApplyDefaultVariationCommand,
+) error {
+	variationID := h.experiment.PendingDefaultVariationId
+	if err := h.experiment.ApplyDefaultVariation(); err != nil {
+		return err
+	}
+	return h.send(
+		ctx,
+		eventproto.Event_EXPERIMENT_DEFAULT_VARIATION_APPLIED,
+		&eventproto.ExperimentDefaultVariationAppliedEvent{
+			Id:          h.experiment.Id,
+			VariationId: variationID,
+		},
+	)
 }
 
 func (h *experimentCommandHandler) archive(ctx context.Context, cmd *proto.ArchiveExperimentCommand) error {
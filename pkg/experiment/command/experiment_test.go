@@ -108,6 +108,40 @@ func TestHandleChangeDescriptionExperimentCommand(t *testing.T) {
 	assert.Equal(t, newDesc, e.Description)
 }
 
+func TestHandleChangeSampleSizeEstimateCommand(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	publisher := publishermock.NewMockPublisher(mockController)
+	e := newExperiment(0, 0)
+	h := newExperimentCommandHandler(t, publisher, e)
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	estimate := &experimentproto.SampleSizeEstimate{
+		BaselineConversionRate:  0.1,
+		MinimumDetectableEffect: 0.1,
+		UsersPerVariation:       14751,
+	}
+	cmd := &experimentproto.ChangeSampleSizeEstimateCommand{SampleSizeEstimate: estimate}
+	err := h.Handle(context.Background(), cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, estimate, e.SampleSizeEstimate)
+}
+
+func TestHandleApplyDefaultVariationCommand(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	publisher := publishermock.NewMockPublisher(mockController)
+	e := newExperiment(0, 0)
+	e.PendingDefaultVariationId = "vid-1"
+	h := newExperimentCommandHandler(t, publisher, e)
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+	cmd := &experimentproto.ApplyDefaultVariationCommand{}
+	err := h.Handle(context.Background(), cmd)
+	assert.NoError(t, err)
+	assert.Empty(t, e.PendingDefaultVariationId)
+}
+
 func TestHandleArchiveExperimentCommand(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -0,0 +1,76 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+
+	domainevent "github.com/bucketeer-io/bucketeer/pkg/domainevent/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+type layerCommandHandler struct {
+	editor               *eventproto.Editor
+	layer                *domain.Layer
+	publisher            publisher.Publisher
+	environmentNamespace string
+}
+
+func NewLayerCommandHandler(
+	editor *eventproto.Editor,
+	layer *domain.Layer,
+	p publisher.Publisher,
+	environmentNamespace string,
+) Handler {
+	return &layerCommandHandler{
+		editor:               editor,
+		layer:                layer,
+		publisher:            p,
+		environmentNamespace: environmentNamespace,
+	}
+}
+
+func (h *layerCommandHandler) Handle(ctx context.Context, cmd Command) error {
+	switch c := cmd.(type) {
+	case *proto.CreateLayerCommand:
+		return h.create(ctx, c)
+	default:
+		return ErrUnknownCommand
+	}
+}
+
+func (h *layerCommandHandler) create(ctx context.Context, cmd *proto.CreateLayerCommand) error {
+	return h.send(ctx, eventproto.Event_LAYER_CREATED, &eventproto.LayerCreatedEvent{
+		Id:            h.layer.Id,
+		Name:          h.layer.Name,
+		Description:   h.layer.Description,
+		HoldoutWeight: h.layer.HoldoutWeight,
+		CreatedAt:     h.layer.CreatedAt,
+		UpdatedAt:     h.layer.UpdatedAt,
+	})
+}
+
+func (h *layerCommandHandler) send(ctx context.Context, eventType eventproto.Event_Type, event pb.Message) error {
+	e, err := domainevent.NewEvent(h.editor, eventproto.Event_LAYER, h.layer.Id, eventType, event, h.environmentNamespace)
+	if err != nil {
+		return err
+	}
+	return h.publisher.Publish(ctx, e)
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+func TestHandleCreateLayerCommand(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	publisher := publishermock.NewMockPublisher(mockController)
+	l, err := domain.NewLayer("lName", "lDesc", 10000)
+	assert.NoError(t, err)
+
+	h := NewLayerCommandHandler(
+		&eventproto.Editor{
+			Email: "email",
+			Role:  accountproto.Account_EDITOR,
+		},
+		l,
+		publisher,
+		"ns0",
+	)
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, e *eventproto.Event) error {
+			assert.Equal(t, eventproto.Event_LAYER, e.EntityType)
+			assert.Equal(t, eventproto.Event_LAYER_CREATED, e.Type)
+			assert.Equal(t, l.Id, e.EntityId)
+			return nil
+		},
+	)
+	cmd := &experimentproto.CreateLayerCommand{Name: "lName", Description: "lDesc", HoldoutWeight: 10000}
+	err = h.Handle(context.Background(), cmd)
+	assert.NoError(t, err)
+}
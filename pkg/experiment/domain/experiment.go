@@ -32,6 +32,17 @@ var (
 	ErrExperimentStartIsAfterStop  = errors.New("experiment: start is after stop timestamp")
 	ErrExperimentStopIsBeforeStart = errors.New("experiment: stop is before start timestamp")
 	ErrExperimentStopIsBeforeNow   = errors.New("experiment: stop is same or older than now timestamp")
+	ErrWinnerVariationNotFound     = errors.New("experiment: winner variation not found")
+	ErrNoPendingDefaultVariation   = errors.New("experiment: no pending default variation")
+)
+
+const (
+	defaultSequentialTestingAlpha = 0.05
+	// TotalTrafficAllocation is the traffic allocation of an experiment covering every eligible user.
+	TotalTrafficAllocation = 100000
+	defaultPrePeriodDays   = 14
+	// MaxPrePeriodDays is the longest pre-experiment period whose goal events are used as the covariate.
+	MaxPrePeriodDays = 90
 )
 
 type Experiment struct {
@@ -48,31 +59,45 @@ func NewExperiment(
 	name string,
 	description string,
 	baseVariationID string,
-	maintainer string) (*Experiment, error) {
+	maintainer string,
+	sequentialTesting *experimentproto.SequentialTesting,
+	goalConfigs []*experimentproto.GoalConfig,
+	rollbackOnGuardrailBreach bool) (*Experiment, error) {
 
 	id, err := uuid.NewUUID()
 	if err != nil {
 		return nil, err
 	}
 	goalIDs = removeDuplicated(goalIDs)
+	if sequentialTesting != nil && sequentialTesting.Method != experimentproto.SequentialTesting_NONE {
+		if sequentialTesting.Alpha == 0 {
+			sequentialTesting.Alpha = defaultSequentialTestingAlpha
+		}
+		if sequentialTesting.GoalId == "" && len(goalIDs) > 0 {
+			sequentialTesting.GoalId = goalIDs[0]
+		}
+	}
 	now := time.Now().Unix()
 	return &Experiment{
 		&experimentproto.Experiment{
-			Id:              id.String(),
-			FeatureId:       featureID,
-			FeatureVersion:  featureVersion,
-			Variations:      variations,
-			GoalIds:         goalIDs,
-			StartAt:         startAt,
-			StopAt:          stopAt,
-			StoppedAt:       math.MaxInt64,
-			CreatedAt:       now,
-			UpdatedAt:       now,
-			Name:            name,
-			Description:     description,
-			BaseVariationId: baseVariationID,
-			Status:          experimentproto.Experiment_WAITING,
-			Maintainer:      maintainer,
+			Id:                        id.String(),
+			FeatureId:                 featureID,
+			FeatureVersion:            featureVersion,
+			Variations:                variations,
+			GoalIds:                   goalIDs,
+			StartAt:                   startAt,
+			StopAt:                    stopAt,
+			StoppedAt:                 math.MaxInt64,
+			CreatedAt:                 now,
+			UpdatedAt:                 now,
+			Name:                      name,
+			Description:               description,
+			BaseVariationId:           baseVariationID,
+			Status:                    experimentproto.Experiment_WAITING,
+			Maintainer:                maintainer,
+			SequentialTesting:         sequentialTesting,
+			GoalConfigs:               goalConfigs,
+			RollbackOnGuardrailBreach: rollbackOnGuardrailBreach,
 		},
 	}, nil
 }
@@ -89,6 +114,50 @@ func removeDuplicated(args []string) []string {
 	return results
 }
 
+// SetFeatureVersion sets the feature version whose evaluations the experiment counts.
+func (e *Experiment) SetFeatureVersion(version int32) {
+	e.Experiment.FeatureVersion = version
+}
+
+// AssignLayerSlice makes the experiment own the slice of the layer's hash space.
+func (e *Experiment) AssignLayerSlice(layerID string, start, end int32) {
+	e.Experiment.LayerId = layerID
+	e.Experiment.LayerSliceStart = start
+	e.Experiment.LayerSliceEnd = end
+}
+
+// LimitTraffic restricts the experiment to the audience and to the share of it,
+// out of 100000, given by the traffic allocation. Zero allocates all users.
+func (e *Experiment) LimitTraffic(trafficAllocation int32, audience []*featureproto.Clause) {
+	e.Experiment.TrafficAllocation = trafficAllocation
+	e.Experiment.Audience = audience
+}
+
+// IsTrafficLimited reports whether some users who evaluate the feature are not allocated to the experiment.
+func (e *Experiment) IsTrafficLimited() bool {
+	return len(e.Audience) > 0 || (e.TrafficAllocation > 0 && e.TrafficAllocation < TotalTrafficAllocation)
+}
+
+// ReduceVariance sets how the goal value sum per user of the variations is adjusted.
+// The pre-experiment period defaults to 14 days.
+func (e *Experiment) ReduceVariance(vr *experimentproto.VarianceReduction) {
+	if vr != nil && vr.Method != experimentproto.VarianceReduction_NONE && vr.PrePeriodDays == 0 {
+		vr.PrePeriodDays = defaultPrePeriodDays
+	}
+	e.Experiment.VarianceReduction = vr
+}
+
+// UsesCUPED returns true if the goal values are adjusted by the pre-experiment goal values.
+func (e *Experiment) UsesCUPED() bool {
+	return e.VarianceReduction != nil && e.VarianceReduction.Method == experimentproto.VarianceReduction_CUPED
+}
+
+// SetSampleSizeEstimate stores the estimate so it can be compared with the actual results later.
+func (e *Experiment) SetSampleSizeEstimate(estimate *experimentproto.SampleSizeEstimate) {
+	e.Experiment.SampleSizeEstimate = estimate
+	e.UpdatedAt = time.Now().Unix()
+}
+
 func (e *Experiment) Start() error {
 	if e.Status != experimentproto.Experiment_WAITING {
 		return ErrExperimentStatusInvalid
@@ -115,18 +184,90 @@ func (e *Experiment) Finish() error {
 	return nil
 }
 
-func (e *Experiment) Stop() error {
+// Stop stops the experiment before stop_at and records the reason.
+// The winner variation is set when the experiment is stopped by a sequential test.
+func (e *Experiment) Stop(reason experimentproto.Experiment_StopReason, winnerVariationID string) error {
 	if e.Stopped {
 		return ErrExperimentAlreadyStopped
 	}
+	if winnerVariationID != "" && !e.hasVariation(winnerVariationID) {
+		return ErrWinnerVariationNotFound
+	}
 	now := time.Now().Unix()
+	e.Experiment.StopReason = reason
+	e.Experiment.WinnerVariationId = winnerVariationID
 	e.Experiment.Stopped = true
 	e.Experiment.Status = experimentproto.Experiment_FORCE_STOPPED
 	e.Experiment.StoppedAt = now
 	e.Experiment.UpdatedAt = now
+	e.Experiment.PendingDefaultVariationId = e.defaultVariationOnStop(reason, winnerVariationID)
 	return nil
 }
 
+// defaultVariationOnStop returns the variation the feature's default strategy is fixed to
+// when the experiment is stopped for the reason, or an empty string if it is left as it is.
+func (e *Experiment) defaultVariationOnStop(
+	reason experimentproto.Experiment_StopReason,
+	winnerVariationID string,
+) string {
+	switch reason {
+	case experimentproto.Experiment_SEQUENTIAL_TEST:
+		if e.SequentialTesting.GetPromoteWinner() {
+			return winnerVariationID
+		}
+	case experimentproto.Experiment_GUARDRAIL_BREACH:
+		if e.RollbackOnGuardrailBreach {
+			return e.BaseVariationId
+		}
+	}
+	return ""
+}
+
+// ApplyDefaultVariation records that the pending default variation has been applied to the feature.
+func (e *Experiment) ApplyDefaultVariation() error {
+	if e.PendingDefaultVariationId == "" {
+		return ErrNoPendingDefaultVariation
+	}
+	e.Experiment.PendingDefaultVariationId = ""
+	e.Experiment.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func (e *Experiment) hasVariation(id string) bool {
+	for _, v := range e.Variations {
+		if v.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesSequentialTesting returns true if the experiment can be stopped early by a sequential test.
+func (e *Experiment) UsesSequentialTesting() bool {
+	return e.SequentialTesting != nil && e.SequentialTesting.Method != experimentproto.SequentialTesting_NONE
+}
+
+// GoalRole returns the role of the goal. Goals without config are primary goals.
+func (e *Experiment) GoalRole(goalID string) experimentproto.GoalConfig_Role {
+	for _, gc := range e.GoalConfigs {
+		if gc.GoalId == goalID {
+			return gc.Role
+		}
+	}
+	return experimentproto.GoalConfig_PRIMARY
+}
+
+// Guardrails returns the configs of the guardrail goals.
+func (e *Experiment) Guardrails() []*experimentproto.GoalConfig {
+	guardrails := make([]*experimentproto.GoalConfig, 0, len(e.GoalConfigs))
+	for _, gc := range e.GoalConfigs {
+		if gc.Role == experimentproto.GoalConfig_GUARDRAIL && gc.Guardrail != nil {
+			guardrails = append(guardrails, gc)
+		}
+	}
+	return guardrails
+}
+
 func (e *Experiment) ChangePeriod(startAt, stopAt int64) error {
 	if err := e.validatePeriod(startAt, stopAt); err != nil {
 		return err
@@ -66,6 +66,9 @@ func TestNewExperiment(t *testing.T) {
 		description,
 		baseVariationId,
 		maintainer,
+		nil,
+		nil,
+		false,
 	)
 
 	assert.NoError(t, err)
@@ -240,6 +243,60 @@ func TestRemoveDuplicated(t *testing.T) {
 	}
 }
 
+func TestSetFeatureVersion(t *testing.T) {
+	t.Parallel()
+	e := newExperiment(t)
+	e.SetFeatureVersion(3)
+	assert.Equal(t, int32(3), e.FeatureVersion)
+}
+
+func TestLimitTraffic(t *testing.T) {
+	t.Parallel()
+	audience := []*featureproto.Clause{
+		{Attribute: "country", Operator: featureproto.Clause_EQUALS, Values: []string{"jp"}},
+	}
+	patterns := map[string]*struct {
+		trafficAllocation int32
+		audience          []*featureproto.Clause
+		expected          bool
+	}{
+		"all users":          {trafficAllocation: 0, expected: false},
+		"full allocation":    {trafficAllocation: TotalTrafficAllocation, expected: false},
+		"partial allocation": {trafficAllocation: 20000, expected: true},
+		"audience":           {trafficAllocation: 0, audience: audience, expected: true},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			e := newExperiment(t)
+			e.LimitTraffic(p.trafficAllocation, p.audience)
+			assert.Equal(t, p.trafficAllocation, e.TrafficAllocation)
+			assert.Equal(t, p.audience, e.Audience)
+			assert.Equal(t, p.expected, e.IsTrafficLimited())
+		})
+	}
+}
+
+func TestReduceVariance(t *testing.T) {
+	t.Parallel()
+	e := newExperiment(t)
+	e.ReduceVariance(nil)
+	assert.False(t, e.UsesCUPED())
+
+	e.ReduceVariance(&experimentproto.VarianceReduction{Method: experimentproto.VarianceReduction_NONE})
+	assert.False(t, e.UsesCUPED())
+	assert.Equal(t, int32(0), e.VarianceReduction.PrePeriodDays)
+
+	e.ReduceVariance(&experimentproto.VarianceReduction{Method: experimentproto.VarianceReduction_CUPED})
+	assert.True(t, e.UsesCUPED())
+	assert.Equal(t, int32(defaultPrePeriodDays), e.VarianceReduction.PrePeriodDays)
+
+	e.ReduceVariance(&experimentproto.VarianceReduction{
+		Method:        experimentproto.VarianceReduction_CUPED,
+		PrePeriodDays: 28,
+	})
+	assert.Equal(t, int32(28), e.VarianceReduction.PrePeriodDays)
+}
+
 func TestStartExperiment(t *testing.T) {
 	t.Parallel()
 	patterns := map[string]*struct {
@@ -351,7 +408,7 @@ func TestStopExperiment(t *testing.T) {
 	}
 	for i, p := range patterns {
 		oldStoppedAt := p.input.StoppedAt
-		err := p.input.Stop()
+		err := p.input.Stop(experimentproto.Experiment_MANUAL, "")
 		if p.expectedErr != nil {
 			assert.Equal(t, p.expectedErr, err, "i=%s", i)
 		} else {
@@ -363,6 +420,128 @@ func TestStopExperiment(t *testing.T) {
 	}
 }
 
+func TestStopExperimentBySequentialTest(t *testing.T) {
+	t.Parallel()
+	newExperiment := func() *Experiment {
+		return &Experiment{&experimentproto.Experiment{
+			Id: "eID",
+			Variations: []*featureproto.Variation{
+				{Id: "vid-0"},
+				{Id: "vid-1"},
+			},
+		}}
+	}
+	e := newExperiment()
+	err := e.Stop(experimentproto.Experiment_SEQUENTIAL_TEST, "vid-2")
+	assert.Equal(t, ErrWinnerVariationNotFound, err)
+	assert.False(t, e.Stopped)
+
+	e = newExperiment()
+	err = e.Stop(experimentproto.Experiment_SEQUENTIAL_TEST, "vid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, experimentproto.Experiment_FORCE_STOPPED, e.Status)
+	assert.Equal(t, experimentproto.Experiment_SEQUENTIAL_TEST, e.StopReason)
+	assert.Equal(t, "vid-1", e.WinnerVariationId)
+	assert.Empty(t, e.PendingDefaultVariationId)
+
+	e = newExperiment()
+	e.SequentialTesting = &experimentproto.SequentialTesting{PromoteWinner: true}
+	err = e.Stop(experimentproto.Experiment_SEQUENTIAL_TEST, "vid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "vid-1", e.PendingDefaultVariationId)
+}
+
+func TestStopExperimentByGuardrailBreach(t *testing.T) {
+	t.Parallel()
+	e := &Experiment{&experimentproto.Experiment{Id: "eID", BaseVariationId: "vid-0"}}
+	err := e.Stop(experimentproto.Experiment_GUARDRAIL_BREACH, "")
+	assert.NoError(t, err)
+	assert.Empty(t, e.PendingDefaultVariationId)
+
+	e = &Experiment{&experimentproto.Experiment{
+		Id:                        "eID",
+		BaseVariationId:           "vid-0",
+		RollbackOnGuardrailBreach: true,
+	}}
+	err = e.Stop(experimentproto.Experiment_GUARDRAIL_BREACH, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "vid-0", e.PendingDefaultVariationId)
+}
+
+func TestApplyDefaultVariation(t *testing.T) {
+	t.Parallel()
+	e := &Experiment{&experimentproto.Experiment{Id: "eID"}}
+	assert.Equal(t, ErrNoPendingDefaultVariation, e.ApplyDefaultVariation())
+
+	e.PendingDefaultVariationId = "vid-1"
+	assert.NoError(t, e.ApplyDefaultVariation())
+	assert.Empty(t, e.PendingDefaultVariationId)
+	assert.NotZero(t, e.UpdatedAt)
+}
+
+func TestNewExperimentWithSequentialTesting(t *testing.T) {
+	t.Parallel()
+	e, err := NewExperiment(
+		"fid",
+		1,
+		nil,
+		[]string{"gid-0", "gid-1"},
+		10,
+		20,
+		"name",
+		"description",
+		"vid-0",
+		"bucketeer@example.com",
+		&experimentproto.SequentialTesting{Method: experimentproto.SequentialTesting_MSPRT},
+		nil,
+		false,
+	)
+	assert.NoError(t, err)
+	assert.True(t, e.UsesSequentialTesting())
+	assert.Equal(t, defaultSequentialTestingAlpha, e.SequentialTesting.Alpha)
+	assert.Equal(t, "gid-0", e.SequentialTesting.GoalId)
+
+	e, err = NewExperiment("fid", 1, nil, []string{"gid-0"}, 10, 20, "name", "description", "vid-0", "", nil, nil, false)
+	assert.NoError(t, err)
+	assert.False(t, e.UsesSequentialTesting())
+}
+
+func TestGoalRoles(t *testing.T) {
+	t.Parallel()
+	guardrail := &experimentproto.GoalConfig{
+		GoalId: "gid-2",
+		Role:   experimentproto.GoalConfig_GUARDRAIL,
+		Guardrail: &experimentproto.Guardrail{
+			Direction: experimentproto.Guardrail_MUST_NOT_INCREASE,
+			Threshold: 0.02,
+		},
+	}
+	e, err := NewExperiment(
+		"fid",
+		1,
+		nil,
+		[]string{"gid-0", "gid-1", "gid-2"},
+		10,
+		20,
+		"name",
+		"description",
+		"vid-0",
+		"bucketeer@example.com",
+		nil,
+		[]*experimentproto.GoalConfig{
+			{GoalId: "gid-1", Role: experimentproto.GoalConfig_SECONDARY},
+			guardrail,
+		},
+		true,
+	)
+	assert.NoError(t, err)
+	assert.True(t, e.RollbackOnGuardrailBreach)
+	assert.Equal(t, experimentproto.GoalConfig_PRIMARY, e.GoalRole("gid-0"))
+	assert.Equal(t, experimentproto.GoalConfig_SECONDARY, e.GoalRole("gid-1"))
+	assert.Equal(t, experimentproto.GoalConfig_GUARDRAIL, e.GoalRole("gid-2"))
+	assert.Equal(t, []*experimentproto.GoalConfig{guardrail}, e.Guardrails())
+}
+
 func TestSyncGoalIDs(t *testing.T) {
 	t.Parallel()
 	patterns := []*struct {
@@ -509,6 +688,9 @@ func newExperiment(t *testing.T) *Experiment {
 		description,
 		baseVariationId,
 		maintainer,
+		nil,
+		nil,
+		false,
 	)
 	assert.NoError(t, err)
 	return e
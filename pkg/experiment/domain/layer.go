@@ -0,0 +1,86 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+// LayerHashSpace is the size of a layer's hash space. It has the same scale as rollout weights.
+const LayerHashSpace = int32(100000)
+
+var (
+	ErrInvalidHoldoutWeight  = errors.New("layer: invalid holdout weight")
+	ErrInvalidLayerWeight    = errors.New("layer: invalid weight")
+	ErrLayerSliceUnavailable = errors.New("layer: no free slice is large enough")
+)
+
+type Layer struct {
+	*proto.Layer
+}
+
+func NewLayer(name, description string, holdoutWeight int32) (*Layer, error) {
+	if holdoutWeight < 0 || holdoutWeight >= LayerHashSpace {
+		return nil, ErrInvalidHoldoutWeight
+	}
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	return &Layer{&proto.Layer{
+		Id:            id.String(),
+		Name:          name,
+		Description:   description,
+		HoldoutWeight: holdoutWeight,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}}, nil
+}
+
+// AllocateSlice returns the first free slice of the weight after the holdout.
+// The experiments are the ones currently owning a slice of the layer.
+func (l *Layer) AllocateSlice(experiments []*proto.Experiment, weight int32) (int32, int32, error) {
+	if weight <= 0 || weight > LayerHashSpace-l.HoldoutWeight {
+		return 0, 0, ErrInvalidLayerWeight
+	}
+	occupied := make([]*proto.Experiment, 0, len(experiments))
+	for _, e := range experiments {
+		if e.LayerId == l.Id && e.LayerSliceEnd > e.LayerSliceStart {
+			occupied = append(occupied, e)
+		}
+	}
+	sort.Slice(occupied, func(i, j int) bool {
+		return occupied[i].LayerSliceStart < occupied[j].LayerSliceStart
+	})
+	start := l.HoldoutWeight
+	for _, e := range occupied {
+		if e.LayerSliceStart-start >= weight {
+			break
+		}
+		if e.LayerSliceEnd > start {
+			start = e.LayerSliceEnd
+		}
+	}
+	if LayerHashSpace-start < weight {
+		return 0, 0, ErrLayerSliceUnavailable
+	}
+	return start, start + weight, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+func TestNewLayer(t *testing.T) {
+	t.Parallel()
+	l, err := NewLayer("name", "description", 10000)
+	require.NoError(t, err)
+	assert.NotEmpty(t, l.Id)
+	assert.Equal(t, int32(10000), l.HoldoutWeight)
+	_, err = NewLayer("name", "description", -1)
+	assert.Equal(t, ErrInvalidHoldoutWeight, err)
+	_, err = NewLayer("name", "description", LayerHashSpace)
+	assert.Equal(t, ErrInvalidHoldoutWeight, err)
+}
+
+func TestAllocateSlice(t *testing.T) {
+	t.Parallel()
+	layer := &Layer{&proto.Layer{Id: "layer-id", HoldoutWeight: 10000}}
+	patterns := []struct {
+		desc          string
+		experiments   []*proto.Experiment
+		weight        int32
+		expectedStart int32
+		expectedEnd   int32
+		expectedErr   error
+	}{
+		{
+			desc:          "empty layer",
+			weight:        20000,
+			expectedStart: 10000,
+			expectedEnd:   30000,
+		},
+		{
+			desc: "gap between experiments",
+			experiments: []*proto.Experiment{
+				{LayerId: "layer-id", LayerSliceStart: 50000, LayerSliceEnd: 100000},
+				{LayerId: "layer-id", LayerSliceStart: 10000, LayerSliceEnd: 20000},
+			},
+			weight:        30000,
+			expectedStart: 20000,
+			expectedEnd:   50000,
+		},
+		{
+			desc: "gap too small",
+			experiments: []*proto.Experiment{
+				{LayerId: "layer-id", LayerSliceStart: 10000, LayerSliceEnd: 20000},
+				{LayerId: "layer-id", LayerSliceStart: 30000, LayerSliceEnd: 60000},
+			},
+			weight:        20000,
+			expectedStart: 60000,
+			expectedEnd:   80000,
+		},
+		{
+			desc: "other layer is ignored",
+			experiments: []*proto.Experiment{
+				{LayerId: "other-layer-id", LayerSliceStart: 10000, LayerSliceEnd: 100000},
+			},
+			weight:        90000,
+			expectedStart: 10000,
+			expectedEnd:   100000,
+		},
+		{
+			desc: "full",
+			experiments: []*proto.Experiment{
+				{LayerId: "layer-id", LayerSliceStart: 10000, LayerSliceEnd: 95000},
+			},
+			weight:      10000,
+			expectedErr: ErrLayerSliceUnavailable,
+		},
+		{
+			desc:        "weight exceeds hash space",
+			weight:      90001,
+			expectedErr: ErrInvalidLayerWeight,
+		},
+		{
+			desc:        "zero weight",
+			weight:      0,
+			expectedErr: ErrInvalidLayerWeight,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			start, end, err := layer.AllocateSlice(p.experiments, p.weight)
+			assert.Equal(t, p.expectedErr, err)
+			assert.Equal(t, p.expectedStart, start)
+			assert.Equal(t, p.expectedEnd, end)
+		})
+	}
+}
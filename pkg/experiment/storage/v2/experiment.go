@@ -77,10 +77,25 @@ func (s *experimentStorage) CreateExperiment(
 			base_variation_id,
 			status,
 			maintainer,
+			sequential_testing,
+			stop_reason,
+			winner_variation_id,
+			goal_configs,
+			rollback_on_guardrail_breach,
+			layer_id,
+			layer_slice_start,
+			layer_slice_end,
+			traffic_allocation,
+			audience,
+			variance_reduction,
+			sample_size_estimate,
+			pending_default_variation_id,
 			environment_namespace
 		) VALUES (
 			?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
-			?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			?, ?, ?
 		)
 	`
 	_, err := s.qe.ExecContext(
@@ -105,6 +120,19 @@ func (s *experimentStorage) CreateExperiment(
 		e.BaseVariationId,
 		int32(e.Status),
 		e.Maintainer,
+		mysql.JSONObject{Val: e.SequentialTesting},
+		int32(e.StopReason),
+		e.WinnerVariationId,
+		mysql.JSONObject{Val: e.GoalConfigs},
+		e.RollbackOnGuardrailBreach,
+		e.LayerId,
+		e.LayerSliceStart,
+		e.LayerSliceEnd,
+		e.TrafficAllocation,
+		mysql.JSONObject{Val: e.Audience},
+		mysql.JSONObject{Val: e.VarianceReduction},
+		mysql.JSONObject{Val: e.SampleSizeEstimate},
+		e.PendingDefaultVariationId,
 		environmentNamespace,
 	)
 	if err != nil {
@@ -142,7 +170,20 @@ func (s *experimentStorage) UpdateExperiment(
 			description = ?,
 			base_variation_id = ?,
 			maintainer = ?,
-			status = ?
+			status = ?,
+			sequential_testing = ?,
+			stop_reason = ?,
+			winner_variation_id = ?,
+			goal_configs = ?,
+			rollback_on_guardrail_breach = ?,
+			layer_id = ?,
+			layer_slice_start = ?,
+			layer_slice_end = ?,
+			traffic_allocation = ?,
+			audience = ?,
+			variance_reduction = ?,
+			sample_size_estimate = ?,
+			pending_default_variation_id = ?
 		WHERE
 			id = ? AND
 			environment_namespace = ?
@@ -168,6 +209,19 @@ func (s *experimentStorage) UpdateExperiment(
 		e.BaseVariationId,
 		e.Maintainer,
 		int32(e.Status),
+		mysql.JSONObject{Val: e.SequentialTesting},
+		int32(e.StopReason),
+		e.WinnerVariationId,
+		mysql.JSONObject{Val: e.GoalConfigs},
+		e.RollbackOnGuardrailBreach,
+		e.LayerId,
+		e.LayerSliceStart,
+		e.LayerSliceEnd,
+		e.TrafficAllocation,
+		mysql.JSONObject{Val: e.Audience},
+		mysql.JSONObject{Val: e.VarianceReduction},
+		mysql.JSONObject{Val: e.SampleSizeEstimate},
+		e.PendingDefaultVariationId,
 		e.Id,
 		environmentNamespace,
 	)
@@ -189,7 +243,7 @@ func (s *experimentStorage) GetExperiment(
 	id, environmentNamespace string,
 ) (*domain.Experiment, error) {
 	experiment := proto.Experiment{}
-	var status int32
+	var status, stopReason int32
 	query := `
 		SELECT
 			id,
@@ -210,7 +264,20 @@ func (s *experimentStorage) GetExperiment(
 			description,
 			base_variation_id,
 			maintainer,
-			status
+			status,
+			sequential_testing,
+			stop_reason,
+			winner_variation_id,
+			goal_configs,
+			rollback_on_guardrail_breach,
+			layer_id,
+			layer_slice_start,
+			layer_slice_end,
+			traffic_allocation,
+			audience,
+			variance_reduction,
+			sample_size_estimate,
+			pending_default_variation_id
 		FROM
 			experiment
 		WHERE
@@ -242,6 +309,19 @@ func (s *experimentStorage) GetExperiment(
 		&experiment.BaseVariationId,
 		&experiment.Maintainer,
 		&status,
+		&mysql.JSONObject{Val: &experiment.SequentialTesting},
+		&stopReason,
+		&experiment.WinnerVariationId,
+		&mysql.JSONObject{Val: &experiment.GoalConfigs},
+		&experiment.RollbackOnGuardrailBreach,
+		&experiment.LayerId,
+		&experiment.LayerSliceStart,
+		&experiment.LayerSliceEnd,
+		&experiment.TrafficAllocation,
+		&mysql.JSONObject{Val: &experiment.Audience},
+		&mysql.JSONObject{Val: &experiment.VarianceReduction},
+		&mysql.JSONObject{Val: &experiment.SampleSizeEstimate},
+		&experiment.PendingDefaultVariationId,
 	)
 	if err != nil {
 		if err == mysql.ErrNoRows {
@@ -250,6 +330,7 @@ func (s *experimentStorage) GetExperiment(
 		return nil, err
 	}
 	experiment.Status = proto.Experiment_Status(status)
+	experiment.StopReason = proto.Experiment_StopReason(stopReason)
 	return &domain.Experiment{Experiment: &experiment}, nil
 }
 
@@ -282,7 +363,20 @@ func (s *experimentStorage) ListExperiments(
 			description,
 			base_variation_id,
 			maintainer,
-			status
+			status,
+			sequential_testing,
+			stop_reason,
+			winner_variation_id,
+			goal_configs,
+			rollback_on_guardrail_breach,
+			layer_id,
+			layer_slice_start,
+			layer_slice_end,
+			traffic_allocation,
+			audience,
+			variance_reduction,
+			sample_size_estimate,
+			pending_default_variation_id
 		FROM
 			experiment
 		%s %s %s
@@ -296,7 +390,7 @@ func (s *experimentStorage) ListExperiments(
 	experiments := make([]*proto.Experiment, 0, limit)
 	for rows.Next() {
 		experiment := proto.Experiment{}
-		var status int32
+		var status, stopReason int32
 		err := rows.Scan(
 			&experiment.Id,
 			&experiment.GoalId,
@@ -317,11 +411,25 @@ func (s *experimentStorage) ListExperiments(
 			&experiment.BaseVariationId,
 			&experiment.Maintainer,
 			&status,
+			&mysql.JSONObject{Val: &experiment.SequentialTesting},
+			&stopReason,
+			&experiment.WinnerVariationId,
+			&mysql.JSONObject{Val: &experiment.GoalConfigs},
+			&experiment.RollbackOnGuardrailBreach,
+			&experiment.LayerId,
+			&experiment.LayerSliceStart,
+			&experiment.LayerSliceEnd,
+			&experiment.TrafficAllocation,
+			&mysql.JSONObject{Val: &experiment.Audience},
+			&mysql.JSONObject{Val: &experiment.VarianceReduction},
+			&mysql.JSONObject{Val: &experiment.SampleSizeEstimate},
+			&experiment.PendingDefaultVariationId,
 		)
 		if err != nil {
 			return nil, 0, 0, err
 		}
 		experiment.Status = proto.Experiment_Status(status)
+		experiment.StopReason = proto.Experiment_StopReason(stopReason)
 		experiments = append(experiments, &experiment)
 	}
 	if rows.Err() != nil {
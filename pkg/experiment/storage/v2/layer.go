@@ -0,0 +1,204 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+var (
+	ErrLayerAlreadyExists = errors.New("layer: already exists")
+	ErrLayerNotFound      = errors.New("layer: not found")
+)
+
+type LayerStorage interface {
+	CreateLayer(ctx context.Context, l *domain.Layer, environmentNamespace string) error
+	GetLayer(ctx context.Context, id, environmentNamespace string) (*domain.Layer, error)
+	GetLayerForUpdate(ctx context.Context, id, environmentNamespace string) (*domain.Layer, error)
+	ListLayers(
+		ctx context.Context,
+		whereParts []mysql.WherePart,
+		orders []*mysql.Order,
+		limit, offset int,
+	) ([]*proto.Layer, int, int64, error)
+}
+
+type layerStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewLayerStorage(qe mysql.QueryExecer) LayerStorage {
+	return &layerStorage{qe: qe}
+}
+
+func (s *layerStorage) CreateLayer(ctx context.Context, l *domain.Layer, environmentNamespace string) error {
+	query := `
+		INSERT INTO layer (
+			id,
+			name,
+			description,
+			holdout_weight,
+			created_at,
+			updated_at,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?
+		)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		l.Id,
+		l.Name,
+		l.Description,
+		l.HoldoutWeight,
+		l.CreatedAt,
+		l.UpdatedAt,
+		environmentNamespace,
+	)
+	if err != nil {
+		if err == mysql.ErrDuplicateEntry {
+			return ErrLayerAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *layerStorage) GetLayer(ctx context.Context, id, environmentNamespace string) (*domain.Layer, error) {
+	return s.getLayer(ctx, id, environmentNamespace, "")
+}
+
+// GetLayerForUpdate locks the layer row until the transaction ends,
+// so concurrent slice allocations in the same layer are serialized.
+func (s *layerStorage) GetLayerForUpdate(
+	ctx context.Context,
+	id, environmentNamespace string,
+) (*domain.Layer, error) {
+	return s.getLayer(ctx, id, environmentNamespace, "FOR UPDATE")
+}
+
+func (s *layerStorage) getLayer(
+	ctx context.Context,
+	id, environmentNamespace, lockSQL string,
+) (*domain.Layer, error) {
+	layer := proto.Layer{}
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			name,
+			description,
+			holdout_weight,
+			created_at,
+			updated_at
+		FROM
+			layer
+		WHERE
+			id = ? AND
+			environment_namespace = ?
+		%s
+	`, lockSQL,
+	)
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		id,
+		environmentNamespace,
+	).Scan(
+		&layer.Id,
+		&layer.Name,
+		&layer.Description,
+		&layer.HoldoutWeight,
+		&layer.CreatedAt,
+		&layer.UpdatedAt,
+	)
+	if err != nil {
+		if err == mysql.ErrNoRows {
+			return nil, ErrLayerNotFound
+		}
+		return nil, err
+	}
+	return &domain.Layer{Layer: &layer}, nil
+}
+
+func (s *layerStorage) ListLayers(
+	ctx context.Context,
+	whereParts []mysql.WherePart,
+	orders []*mysql.Order,
+	limit, offset int,
+) ([]*proto.Layer, int, int64, error) {
+	whereSQL, whereArgs := mysql.ConstructWhereSQLString(whereParts)
+	orderBySQL := mysql.ConstructOrderBySQLString(orders)
+	limitOffsetSQL := mysql.ConstructLimitOffsetSQLString(limit, offset)
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			name,
+			description,
+			holdout_weight,
+			created_at,
+			updated_at
+		FROM
+			layer
+		%s %s %s
+		`, whereSQL, orderBySQL, limitOffsetSQL,
+	)
+	rows, err := s.qe.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer rows.Close()
+	layers := make([]*proto.Layer, 0, limit)
+	for rows.Next() {
+		layer := proto.Layer{}
+		err := rows.Scan(
+			&layer.Id,
+			&layer.Name,
+			&layer.Description,
+			&layer.HoldoutWeight,
+			&layer.CreatedAt,
+			&layer.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		layers = append(layers, &layer)
+	}
+	if rows.Err() != nil {
+		return nil, 0, 0, err
+	}
+	nextOffset := offset + len(layers)
+	var totalCount int64
+	countQuery := fmt.Sprintf(`
+		SELECT
+			COUNT(1)
+		FROM
+			layer
+		%s %s
+		`, whereSQL, orderBySQL,
+	)
+	err = s.qe.QueryRowContext(ctx, countQuery, whereArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return layers, nextOffset, totalCount, nil
+}
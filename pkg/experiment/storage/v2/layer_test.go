@@ -0,0 +1,240 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/experiment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	proto "github.com/bucketeer-io/bucketeer/proto/experiment"
+)
+
+func TestNewLayerStorage(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	db := NewLayerStorage(mock.NewMockQueryExecer(mockController))
+	assert.IsType(t, &layerStorage{}, db)
+}
+
+func TestCreateLayer(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup       func(*layerStorage)
+		input       *domain.Layer
+		expectedErr error
+	}{
+		{
+			setup: func(s *layerStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, mysql.ErrDuplicateEntry)
+			},
+			input:       &domain.Layer{Layer: &proto.Layer{Id: "id-0"}},
+			expectedErr: ErrLayerAlreadyExists,
+		},
+		{
+			setup: func(s *layerStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			input:       &domain.Layer{Layer: &proto.Layer{Id: "id-1"}},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newLayerStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		err := storage.CreateLayer(context.Background(), p.input, "ns0")
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestGetLayer(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup       func(*layerStorage)
+		input       string
+		expectedErr error
+	}{
+		{
+			setup: func(s *layerStorage) {
+				row := mock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			input:       "",
+			expectedErr: ErrLayerNotFound,
+		},
+		{
+			setup: func(s *layerStorage) {
+				row := mock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			input:       "id-0",
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newLayerStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		_, err := storage.GetLayer(context.Background(), p.input, "ns0")
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestGetLayerForUpdate(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup       func(*layerStorage)
+		input       string
+		expectedErr error
+	}{
+		{
+			setup: func(s *layerStorage) {
+				row := mock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			input:       "",
+			expectedErr: ErrLayerNotFound,
+		},
+		{
+			setup: func(s *layerStorage) {
+				row := mock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(_ context.Context, query string, _ ...interface{}) mysql.Row {
+					assert.Contains(t, query, "FOR UPDATE")
+					return row
+				})
+			},
+			input:       "id-0",
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newLayerStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		_, err := storage.GetLayerForUpdate(context.Background(), p.input, "ns0")
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestListLayers(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	patterns := []struct {
+		setup          func(*layerStorage)
+		whereParts     []mysql.WherePart
+		orders         []*mysql.Order
+		limit          int
+		offset         int
+		expected       []*proto.Layer
+		expectedCursor int
+		expectedErr    error
+	}{
+		{
+			setup: func(s *layerStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			expected:       nil,
+			expectedCursor: 0,
+			expectedErr:    errors.New("error"),
+		},
+		{
+			setup: func(s *layerStorage) {
+				rows := mock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+				row := mock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			whereParts: []mysql.WherePart{
+				mysql.NewFilter("environment_namespace", "=", "ns0"),
+			},
+			orders: []*mysql.Order{
+				mysql.NewOrder("name", mysql.OrderDirectionAsc),
+			},
+			limit:          10,
+			offset:         5,
+			expected:       []*proto.Layer{},
+			expectedCursor: 5,
+			expectedErr:    nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newLayerStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		layers, cursor, _, err := storage.ListLayers(
+			context.Background(),
+			p.whereParts,
+			p.orders,
+			p.limit,
+			p.offset,
+		)
+		assert.Equal(t, p.expected, layers)
+		assert.Equal(t, p.expectedCursor, cursor)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func newLayerStorageWithMock(t *testing.T, mockController *gomock.Controller) *layerStorage {
+	t.Helper()
+	return &layerStorage{mock.NewMockQueryExecer(mockController)}
+}
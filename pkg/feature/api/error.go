@@ -80,6 +80,8 @@ var (
 	statusUnknownStrategy                 = gstatus.New(codes.InvalidArgument, "feature: unknown strategy")
 	statusMissingFixedStrategy            = gstatus.New(codes.InvalidArgument, "feature: missing fixed strategy")
 	statusMissingRolloutStrategy          = gstatus.New(codes.InvalidArgument, "feature: missing rollout strategy")
+	statusMissingBanditStrategy           = gstatus.New(codes.InvalidArgument, "feature: missing bandit strategy")
+	statusInvalidBanditStrategy           = gstatus.New(codes.InvalidArgument, "feature: invalid bandit strategy")
 	statusExceededMaxSegmentUsersDataSize = gstatus.New(
 		codes.InvalidArgument,
 		fmt.Sprintf("feature: max segment users data size allowed is %d bytes", maxSegmentUsersDataSize),
@@ -129,7 +131,37 @@ var (
 		codes.FailedPrecondition,
 		"feature: can't change or remove this variation because it is used as a prerequsite",
 	)
-	statusInvalidPrerequisite = gstatus.New(codes.FailedPrecondition, "feature: invalid prerequisite")
+	statusInvalidPrerequisite         = gstatus.New(codes.FailedPrecondition, "feature: invalid prerequisite")
+	statusPromotionUnmappedReferences = gstatus.New(
+		codes.FailedPrecondition,
+		"feature: segments or prerequisites can't be mapped to the target environment",
+	)
+	statusMissingLifecyclePolicy = gstatus.New(codes.InvalidArgument, "feature: lifecycle policy must be specified")
+	statusInvalidLifecyclePolicy = gstatus.New(codes.InvalidArgument, "feature: lifecycle policy is invalid")
+	statusNotArchivedByPolicy    = gstatus.New(
+		codes.FailedPrecondition,
+		"feature: feature was not archived by the lifecycle policy",
+	)
+	statusUndoPeriodExpired             = gstatus.New(codes.FailedPrecondition, "feature: undo period has expired")
+	statusInvalidExpectedRemovalDate    = gstatus.New(codes.InvalidArgument, "feature: invalid expected removal date")
+	statusRemovalDateOnPermanentFeature = gstatus.New(
+		codes.InvalidArgument,
+		"feature: permanent feature can't have an expected removal date",
+	)
+	statusInvalidLayerAssignment      = gstatus.New(codes.InvalidArgument, "feature: invalid layer assignment")
+	statusInvalidExperimentAllocation = gstatus.New(
+		codes.InvalidArgument,
+		"feature: invalid experiment allocation",
+	)
+	statusIncidentNotRestorable = gstatus.New(
+		codes.FailedPrecondition,
+		"feature: variations of the incident snapshot no longer exist",
+	)
+	statusMissingIncidentID       = gstatus.New(codes.InvalidArgument, "feature: missing incident id")
+	statusInvalidIncidentAction   = gstatus.New(codes.InvalidArgument, "feature: invalid incident action")
+	statusIncidentNotFound        = gstatus.New(codes.NotFound, "feature: incident not found")
+	statusIncidentAlreadyExists   = gstatus.New(codes.AlreadyExists, "feature: incident already exists")
+	statusIncidentAlreadyRestored = gstatus.New(codes.FailedPrecondition, "feature: incident is already restored")
 
 	errInternalJaJP = status.MustWithDetails(
 		statusInternal,
@@ -404,6 +436,20 @@ var (
 			Message: "rollout strategyは必須です",
 		},
 	)
+	errMissingBanditStrategy = status.MustWithDetails(
+		statusMissingBanditStrategy,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "bandit strategyは必須です",
+		},
+	)
+	errInvalidBanditStrategy = status.MustWithDetails(
+		statusInvalidBanditStrategy,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なbandit strategyです",
+		},
+	)
 	errExceededMaxSegmentUsersDataSizeJaJP = status.MustWithDetails(
 		statusExceededMaxSegmentUsersDataSize,
 		&errdetails.LocalizedMessage{
@@ -537,6 +583,111 @@ var (
 			Message: "不正なprerequisiteです",
 		},
 	)
+	errPromotionUnmappedReferencesJaJP = status.MustWithDetails(
+		statusPromotionUnmappedReferences,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "セグメントまたは前提条件のフラグを反映先のenvironmentに対応付けできません",
+		},
+	)
+	errMissingLifecyclePolicyJaJP = status.MustWithDetails(
+		statusMissingLifecyclePolicy,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "ライフサイクルポリシーは必須です",
+		},
+	)
+	errInvalidLifecyclePolicyJaJP = status.MustWithDetails(
+		statusInvalidLifecyclePolicy,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なライフサイクルポリシーです",
+		},
+	)
+	errNotArchivedByPolicyJaJP = status.MustWithDetails(
+		statusNotArchivedByPolicy,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "このフラグはライフサイクルポリシーによってアーカイブされていません",
+		},
+	)
+	errUndoPeriodExpiredJaJP = status.MustWithDetails(
+		statusUndoPeriodExpired,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "アーカイブを取り消せる期間が過ぎています",
+		},
+	)
+	errInvalidExpectedRemovalDateJaJP = status.MustWithDetails(
+		statusInvalidExpectedRemovalDate,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正な削除予定日です",
+		},
+	)
+	errRemovalDateOnPermanentFeatureJaJP = status.MustWithDetails(
+		statusRemovalDateOnPermanentFeature,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "恒久的なフィーチャーフラグには削除予定日を設定できません",
+		},
+	)
+	errInvalidLayerAssignmentJaJP = status.MustWithDetails(
+		statusInvalidLayerAssignment,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なlayerの割り当てです",
+		},
+	)
+	errInvalidExperimentAllocationJaJP = status.MustWithDetails(
+		statusInvalidExperimentAllocation,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なexperimentの対象ユーザーの設定です",
+		},
+	)
+	errIncidentNotRestorableJaJP = status.MustWithDetails(
+		statusIncidentNotRestorable,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "インシデント前のバリエーションが存在しないため復元できません",
+		},
+	)
+	errMissingIncidentIDJaJP = status.MustWithDetails(
+		statusMissingIncidentID,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "インシデントIDは必須です",
+		},
+	)
+	errInvalidIncidentActionJaJP = status.MustWithDetails(
+		statusInvalidIncidentAction,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "不正なインシデントのアクションです",
+		},
+	)
+	errIncidentNotFoundJaJP = status.MustWithDetails(
+		statusIncidentNotFound,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "インシデントが見つかりません",
+		},
+	)
+	errIncidentAlreadyExistsJaJP = status.MustWithDetails(
+		statusIncidentAlreadyExists,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "同じIDのインシデントがすでに存在します",
+		},
+	)
+	errIncidentAlreadyRestoredJaJP = status.MustWithDetails(
+		statusIncidentAlreadyRestored,
+		&errdetails.LocalizedMessage{
+			Locale:  locale.JaJP,
+			Message: "このインシデントはすでに復元されています",
+		},
+	)
 )
 
 func localizedError(s *gstatus.Status, loc string) error {
@@ -620,6 +771,10 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errMissingFixedStrategy
 	case statusMissingRolloutStrategy:
 		return errMissingRolloutStrategy
+	case statusMissingBanditStrategy:
+		return errMissingBanditStrategy
+	case statusInvalidBanditStrategy:
+		return errInvalidBanditStrategy
 	case statusExceededMaxSegmentUsersDataSize:
 		return errExceededMaxSegmentUsersDataSizeJaJP
 	case statusUnknownSegmentUserState:
@@ -658,6 +813,36 @@ func localizedError(s *gstatus.Status, loc string) error {
 		return errInvalidChangingVariationJaJP
 	case statusInvalidPrerequisite:
 		return errInvalidPrerequisiteJaJP
+	case statusPromotionUnmappedReferences:
+		return errPromotionUnmappedReferencesJaJP
+	case statusMissingLifecyclePolicy:
+		return errMissingLifecyclePolicyJaJP
+	case statusInvalidLifecyclePolicy:
+		return errInvalidLifecyclePolicyJaJP
+	case statusNotArchivedByPolicy:
+		return errNotArchivedByPolicyJaJP
+	case statusUndoPeriodExpired:
+		return errUndoPeriodExpiredJaJP
+	case statusInvalidExpectedRemovalDate:
+		return errInvalidExpectedRemovalDateJaJP
+	case statusRemovalDateOnPermanentFeature:
+		return errRemovalDateOnPermanentFeatureJaJP
+	case statusInvalidLayerAssignment:
+		return errInvalidLayerAssignmentJaJP
+	case statusInvalidExperimentAllocation:
+		return errInvalidExperimentAllocationJaJP
+	case statusIncidentNotRestorable:
+		return errIncidentNotRestorableJaJP
+	case statusMissingIncidentID:
+		return errMissingIncidentIDJaJP
+	case statusInvalidIncidentAction:
+		return errInvalidIncidentActionJaJP
+	case statusIncidentNotFound:
+		return errIncidentNotFoundJaJP
+	case statusIncidentAlreadyExists:
+		return errIncidentAlreadyExistsJaJP
+	case statusIncidentAlreadyRestored:
+		return errIncidentAlreadyRestoredJaJP
 	default:
 		return errInternalJaJP
 	}
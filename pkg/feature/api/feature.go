@@ -56,9 +56,14 @@ func (s *FeatureService) GetFeature(
 		return nil, err
 	}
 	featureStorage := v2fs.NewFeatureStorage(s.mysqlClient)
-	feature, err := featureStorage.GetFeature(ctx, req.Id, req.EnvironmentNamespace)
+	var feature *domain.Feature
+	if req.Version == 0 {
+		feature, err = featureStorage.GetFeature(ctx, req.Id, req.EnvironmentNamespace)
+	} else {
+		feature, err = featureStorage.GetFeatureVersion(ctx, req.Id, req.Version, req.EnvironmentNamespace)
+	}
 	if err != nil {
-		if err == v2fs.ErrFeatureNotFound {
+		if err == v2fs.ErrFeatureNotFound || err == v2fs.ErrFeatureVersionNotFound {
 			return nil, localizedError(statusNotFound, locale.JaJP)
 		}
 		s.logger.Error(
@@ -66,6 +71,7 @@ func (s *FeatureService) GetFeature(
 			log.FieldsFromImcomingContext(ctx).AddFields(
 				zap.Error(err),
 				zap.String("id", req.Id),
+				zap.Int32("version", req.Version),
 				zap.String("environmentNamespace", req.EnvironmentNamespace),
 			)...,
 		)
@@ -143,6 +149,8 @@ func (s *FeatureService) ListFeatures(
 			req.Maintainer,
 			req.Enabled,
 			req.Archived,
+			req.Kinds,
+			req.ExpectedRemovalBefore,
 			req.SearchKeyword,
 			req.OrderBy,
 			req.OrderDirection,
@@ -157,6 +165,8 @@ func (s *FeatureService) ListFeatures(
 			req.Maintainer,
 			req.Enabled,
 			req.Archived,
+			req.Kinds,
+			req.ExpectedRemovalBefore,
 			req.SearchKeyword,
 			req.OrderBy,
 			req.OrderDirection,
@@ -182,6 +192,8 @@ func (s *FeatureService) listFeatures(
 	maintainer string,
 	enabled *wrappers.BoolValue,
 	archived *wrappers.BoolValue,
+	kinds []featureproto.Feature_Kind,
+	expectedRemovalBefore int64,
 	searchKeyword string,
 	orderBy featureproto.ListFeaturesRequest_OrderBy,
 	orderDirection featureproto.ListFeaturesRequest_OrderDirection,
@@ -210,6 +222,20 @@ func (s *FeatureService) listFeatures(
 	if archived != nil {
 		whereParts = append(whereParts, mysql.NewFilter("archived", "=", archived.Value))
 	}
+	if len(kinds) > 0 {
+		kindValues := make([]interface{}, 0, len(kinds))
+		for _, k := range kinds {
+			kindValues = append(kindValues, int32(k))
+		}
+		whereParts = append(whereParts, mysql.NewInFilter("kind", kindValues))
+	}
+	if expectedRemovalBefore != 0 {
+		whereParts = append(
+			whereParts,
+			mysql.NewFilter("expected_removal_at", ">", 0),
+			mysql.NewFilter("expected_removal_at", "<", expectedRemovalBefore),
+		)
+	}
 	if searchKeyword != "" {
 		whereParts = append(whereParts, mysql.NewSearchQuery([]string{"id", "name", "description"}, searchKeyword))
 	}
@@ -264,6 +290,8 @@ func (s *FeatureService) listFeaturesFilteredByExperiment(
 	maintainer string,
 	enabled *wrappers.BoolValue,
 	archived *wrappers.BoolValue,
+	kinds []featureproto.Feature_Kind,
+	expectedRemovalBefore int64,
 	searchKeyword string,
 	orderBy featureproto.ListFeaturesRequest_OrderBy,
 	orderDirection featureproto.ListFeaturesRequest_OrderDirection,
@@ -295,6 +323,20 @@ func (s *FeatureService) listFeaturesFilteredByExperiment(
 	if archived != nil {
 		whereParts = append(whereParts, mysql.NewFilter("feature.archived", "=", archived.Value))
 	}
+	if len(kinds) > 0 {
+		kindValues := make([]interface{}, 0, len(kinds))
+		for _, k := range kinds {
+			kindValues = append(kindValues, int32(k))
+		}
+		whereParts = append(whereParts, mysql.NewInFilter("feature.kind", kindValues))
+	}
+	if expectedRemovalBefore != 0 {
+		whereParts = append(
+			whereParts,
+			mysql.NewFilter("feature.expected_removal_at", ">", 0),
+			mysql.NewFilter("feature.expected_removal_at", "<", expectedRemovalBefore),
+		)
+	}
 	if searchKeyword != "" {
 		whereParts = append(whereParts, mysql.NewSearchQuery([]string{"id", "name", "description"}, searchKeyword))
 	}
@@ -449,6 +491,8 @@ func (s *FeatureService) CreateFeature(
 		int(req.Command.DefaultOnVariationIndex.Value),
 		int(req.Command.DefaultOffVariationIndex.Value),
 		editor.Email,
+		req.Command.Kind,
+		req.Command.ExpectedRemovalAt,
 	)
 	if err != nil {
 		return nil, err
@@ -527,8 +571,8 @@ func (s *FeatureService) UpdateFeatureDetails(
 	if err != nil {
 		return nil, err
 	}
-	if req.Id == "" {
-		return nil, localizedError(statusMissingID, locale.JaJP)
+	if err := validateUpdateFeatureDetailsRequest(req); err != nil {
+		return nil, err
 	}
 	runningExperimentExists, err := s.existsRunningExperiment(ctx, req.Id, req.EnvironmentNamespace)
 	if err != nil {
@@ -621,6 +665,32 @@ func (s *FeatureService) UpdateFeatureDetails(
 				return err
 			}
 		}
+		if req.ChangeFeatureKindCommand != nil {
+			err = handler.Handle(ctx, req.ChangeFeatureKindCommand)
+			if err != nil {
+				s.logger.Error(
+					"Failed to change feature kind",
+					log.FieldsFromImcomingContext(ctx).AddFields(
+						zap.Error(err),
+						zap.String("environmentNamespace", req.EnvironmentNamespace),
+					)...,
+				)
+				return err
+			}
+		}
+		if req.ChangeExpectedRemovalDateCommand != nil {
+			err = handler.Handle(ctx, req.ChangeExpectedRemovalDateCommand)
+			if err != nil {
+				s.logger.Error(
+					"Failed to change feature expected removal date",
+					log.FieldsFromImcomingContext(ctx).AddFields(
+						zap.Error(err),
+						zap.String("environmentNamespace", req.EnvironmentNamespace),
+					)...,
+				)
+				return err
+			}
+		}
 		if req.RemoveTagCommands != nil {
 			for i := range req.RemoveTagCommands {
 				err = handler.Handle(ctx, req.RemoveTagCommands[i])
@@ -650,6 +720,9 @@ func (s *FeatureService) UpdateFeatureDetails(
 		return nil
 	})
 	if err != nil {
+		if err == domain.ErrRemovalDateOnPermanentFeature {
+			return nil, localizedError(statusRemovalDateOnPermanentFeature, locale.JaJP)
+		}
 		return nil, err
 	}
 	if errs := s.publishDomainEvents(ctx, handler.Events); len(errs) > 0 {
@@ -1341,6 +1414,8 @@ func (s *FeatureService) getFeatures(
 		"",
 		nil,
 		nil,
+		nil,
+		0,
 		"",
 		featureproto.ListFeaturesRequest_DEFAULT,
 		featureproto.ListFeaturesRequest_ASC,
@@ -0,0 +1,485 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func (s *FeatureService) CompareFeatureAcrossEnvironments(
+	ctx context.Context,
+	req *featureproto.CompareFeatureAcrossEnvironmentsRequest,
+) (*featureproto.CompareFeatureAcrossEnvironmentsResponse, error) {
+	if err := validateCompareFeatureAcrossEnvironmentsRequest(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace); err != nil {
+		return nil, err
+	}
+	if _, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.TargetEnvironmentNamespace); err != nil {
+		return nil, err
+	}
+	source, err := s.getFeature(ctx, s.mysqlClient, req.Id, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getFeature(ctx, s.mysqlClient, req.Id, req.TargetEnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	promotion, err := s.newFeaturePromotion(
+		ctx,
+		source,
+		target,
+		req.EnvironmentNamespace,
+		req.TargetEnvironmentNamespace,
+		req.SegmentIdMappings,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &featureproto.CompareFeatureAcrossEnvironmentsResponse{Diff: promotion.Diff()}, nil
+}
+
+func (s *FeatureService) PromoteFeature(
+	ctx context.Context,
+	req *featureproto.PromoteFeatureRequest,
+) (*featureproto.PromoteFeatureResponse, error) {
+	if err := validatePromoteFeatureRequest(req); err != nil {
+		return nil, err
+	}
+	if _, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace); err != nil {
+		return nil, err
+	}
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.TargetEnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	runningExperimentExists, err := s.existsRunningExperiment(ctx, req.Id, req.TargetEnvironmentNamespace)
+	if err != nil {
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if runningExperimentExists {
+		return nil, localizedError(statusWaitingOrRunningExperimentExists, locale.JaJP)
+	}
+	source, err := s.getFeature(ctx, s.mysqlClient, req.Id, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		target, err := s.getFeature(ctx, s.mysqlClient, req.Id, req.TargetEnvironmentNamespace)
+		if err != nil {
+			return nil, err
+		}
+		promotion, err := s.newFeaturePromotion(
+			ctx,
+			source,
+			target,
+			req.EnvironmentNamespace,
+			req.TargetEnvironmentNamespace,
+			req.SegmentIdMappings,
+		)
+		if err != nil {
+			return nil, err
+		}
+		diff := promotion.Diff()
+		_, commands, err := s.promoteFeature(ctx, s.mysqlClient, editor, promotion, diff, req)
+		if err != nil {
+			return nil, err
+		}
+		return &featureproto.PromoteFeatureResponse{Diff: diff, Commands: commands}, nil
+	}
+	var diff *featureproto.FeatureDiff
+	var commands []*featureproto.Command
+	var handler *command.FeatureCommandHandler = command.NewEmptyFeatureCommandHandler()
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		target, err := s.getFeature(ctx, tx, req.Id, req.TargetEnvironmentNamespace)
+		if err != nil {
+			return err
+		}
+		promotion, err := s.newFeaturePromotion(
+			ctx,
+			source,
+			target,
+			req.EnvironmentNamespace,
+			req.TargetEnvironmentNamespace,
+			req.SegmentIdMappings,
+		)
+		if err != nil {
+			return err
+		}
+		diff = promotion.Diff()
+		handler, commands, err = s.promoteFeature(ctx, tx, editor, promotion, diff, req)
+		if err != nil {
+			return err
+		}
+		if len(commands) == 0 {
+			return nil
+		}
+		featureStorage := v2fs.NewFeatureStorage(tx)
+		if err := featureStorage.UpdateFeature(ctx, target, req.TargetEnvironmentNamespace); err != nil {
+			s.logger.Error(
+				"Failed to update feature",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+				)...,
+			)
+			return localizedError(statusInternal, locale.JaJP)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errs := s.publishDomainEvents(ctx, handler.Events); len(errs) > 0 {
+		s.logger.Error(
+			"Failed to publish events",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Any("errors", errs),
+				zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &featureproto.PromoteFeatureResponse{Diff: diff, Commands: commands}, nil
+}
+
+// promoteFeature handles the promotion commands on the target feature.
+// It doesn't persist the feature, so it is also used by the dry run.
+func (s *FeatureService) promoteFeature(
+	ctx context.Context,
+	qe mysql.QueryExecer,
+	editor *eventproto.Editor,
+	promotion *domain.FeaturePromotion,
+	diff *featureproto.FeatureDiff,
+	req *featureproto.PromoteFeatureRequest,
+) (*command.FeatureCommandHandler, []*featureproto.Command, error) {
+	if len(diff.UnmappedSegmentIds) > 0 || len(diff.UnmappedPrerequisiteIds) > 0 {
+		s.logger.Info(
+			"Unmapped references",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Strings("segmentIds", diff.UnmappedSegmentIds),
+				zap.Strings("prerequisiteIds", diff.UnmappedPrerequisiteIds),
+				zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusPromotionUnmappedReferences, locale.JaJP)
+	}
+	handler := command.NewFeatureCommandHandler(
+		editor,
+		promotion.Target(),
+		req.TargetEnvironmentNamespace,
+		req.Comment,
+	)
+	if !domain.HasChanges(diff) {
+		return handler, []*featureproto.Command{}, nil
+	}
+	featureStorage := v2fs.NewFeatureStorage(qe)
+	features, _, _, err := featureStorage.ListFeatures(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("archived", "=", false),
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewFilter("environment_namespace", "=", req.TargetEnvironmentNamespace),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list feature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if err := handler.Handle(ctx, &featureproto.IncrementFeatureVersionCommand{}); err != nil {
+		s.logger.Error(
+			"Failed to increment feature version",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	// The copy in the list is only used for validation, so it can be modified by validateAddPrerequisite.
+	tarF, err := findFeature(features, req.Id)
+	if err != nil {
+		return nil, nil, localizedError(statusNotFound, locale.JaJP)
+	}
+	commands := []*featureproto.Command{}
+	// The commands of each step depend on the feature updated by the previous one.
+	steps := []func() ([]command.Command, error){
+		func() ([]command.Command, error) {
+			return command.PromotionVariationCommands(promotion), nil
+		},
+		func() ([]command.Command, error) {
+			return command.PromotionTargetingCommands(promotion)
+		},
+		func() ([]command.Command, error) {
+			return command.PromotionRemoveVariationCommands(promotion), nil
+		},
+	}
+	for _, step := range steps {
+		cmds, err := step()
+		if err != nil {
+			s.logger.Error(
+				"Failed to generate promotion commands",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+				)...,
+			)
+			return nil, nil, localizedError(statusInternal, locale.JaJP)
+		}
+		for _, cmd := range cmds {
+			if err := validatePromotionCommand(features, tarF, cmd); err != nil {
+				s.logger.Info(
+					"Invalid argument",
+					log.FieldsFromImcomingContext(ctx).AddFields(
+						zap.Error(err),
+						zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+					)...,
+				)
+				return nil, nil, err
+			}
+			if err := handler.Handle(ctx, cmd); err != nil {
+				s.logger.Error(
+					"Failed to handle command",
+					log.FieldsFromImcomingContext(ctx).AddFields(
+						zap.Error(err),
+						zap.String("environmentNamespace", req.TargetEnvironmentNamespace),
+					)...,
+				)
+				return nil, nil, localizedError(statusInternal, locale.JaJP)
+			}
+			c, err := ptypes.MarshalAny(cmd.(proto.Message))
+			if err != nil {
+				return nil, nil, localizedError(statusInternal, locale.JaJP)
+			}
+			commands = append(commands, &featureproto.Command{Command: c})
+		}
+	}
+	return handler, commands, nil
+}
+
+func (s *FeatureService) getFeature(
+	ctx context.Context,
+	qe mysql.QueryExecer,
+	id, environmentNamespace string,
+) (*domain.Feature, error) {
+	featureStorage := v2fs.NewFeatureStorage(qe)
+	f, err := featureStorage.GetFeature(ctx, id, environmentNamespace)
+	if err != nil {
+		if err == v2fs.ErrFeatureNotFound {
+			return nil, localizedError(statusNotFound, locale.JaJP)
+		}
+		s.logger.Error(
+			"Failed to get feature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("id", id),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return f, nil
+}
+
+func (s *FeatureService) newFeaturePromotion(
+	ctx context.Context,
+	source, target *domain.Feature,
+	environmentNamespace, targetEnvironmentNamespace string,
+	segmentIDMappings map[string]string,
+) (*domain.FeaturePromotion, error) {
+	segmentIDs, err := s.mapSegmentIDs(
+		ctx,
+		source,
+		environmentNamespace,
+		targetEnvironmentNamespace,
+		segmentIDMappings,
+	)
+	if err != nil {
+		return nil, err
+	}
+	prerequisiteVariationIDs, err := s.mapPrerequisiteVariationIDs(
+		ctx,
+		source,
+		environmentNamespace,
+		targetEnvironmentNamespace,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewFeaturePromotion(source.Feature, target, segmentIDs, prerequisiteVariationIDs), nil
+}
+
+// mapSegmentIDs maps the segments used in the source feature to the target environment.
+// The mappings given in the request take precedence. Other segments are matched by name
+// when exactly one segment in the target environment has the same name.
+func (s *FeatureService) mapSegmentIDs(
+	ctx context.Context,
+	source *domain.Feature,
+	environmentNamespace, targetEnvironmentNamespace string,
+	mappings map[string]string,
+) (map[string]string, error) {
+	segmentIDs := make(map[string]string, len(mappings))
+	missing := false
+	for _, id := range source.ListSegmentIDs() {
+		if tid, ok := mappings[id]; ok {
+			segmentIDs[id] = tid
+			continue
+		}
+		missing = true
+	}
+	if !missing {
+		return segmentIDs, nil
+	}
+	sourceSegments, err := s.listAllSegments(ctx, environmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	targetSegments, err := s.listAllSegments(ctx, targetEnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	targetIDsByName := make(map[string][]string, len(targetSegments))
+	for _, seg := range targetSegments {
+		targetIDsByName[seg.Name] = append(targetIDsByName[seg.Name], seg.Id)
+	}
+	for _, seg := range sourceSegments {
+		if _, ok := segmentIDs[seg.Id]; ok {
+			continue
+		}
+		if ids := targetIDsByName[seg.Name]; len(ids) == 1 {
+			segmentIDs[seg.Id] = ids[0]
+		}
+	}
+	return segmentIDs, nil
+}
+
+func (s *FeatureService) listAllSegments(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Segment, error) {
+	segmentStorage := v2fs.NewSegmentStorage(s.mysqlClient)
+	segments, _, _, err := segmentStorage.ListSegments(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+		nil,
+		environmentNamespace,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list segments",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return segments, nil
+}
+
+// mapPrerequisiteVariationIDs maps the variations used in the source prerequisites to the variations
+// with the same value in the target environment.
+func (s *FeatureService) mapPrerequisiteVariationIDs(
+	ctx context.Context,
+	source *domain.Feature,
+	environmentNamespace, targetEnvironmentNamespace string,
+) (map[string]string, error) {
+	variationIDs := make(map[string]string, len(source.Prerequisites))
+	featureStorage := v2fs.NewFeatureStorage(s.mysqlClient)
+	for _, p := range source.Prerequisites {
+		sf, err := featureStorage.GetFeature(ctx, p.FeatureId, environmentNamespace)
+		if err != nil {
+			if err == v2fs.ErrFeatureNotFound {
+				continue
+			}
+			s.logger.Error(
+				"Failed to get prerequisite feature",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("id", p.FeatureId),
+					zap.String("environmentNamespace", environmentNamespace),
+				)...,
+			)
+			return nil, localizedError(statusInternal, locale.JaJP)
+		}
+		tf, err := featureStorage.GetFeature(ctx, p.FeatureId, targetEnvironmentNamespace)
+		if err != nil {
+			if err == v2fs.ErrFeatureNotFound {
+				continue
+			}
+			s.logger.Error(
+				"Failed to get prerequisite feature",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("id", p.FeatureId),
+					zap.String("environmentNamespace", targetEnvironmentNamespace),
+				)...,
+			)
+			return nil, localizedError(statusInternal, locale.JaJP)
+		}
+		for _, sv := range sf.Variations {
+			if sv.Id != p.VariationId {
+				continue
+			}
+			for _, tv := range tf.Variations {
+				if tv.Value == sv.Value {
+					variationIDs[sv.Id] = tv.Id
+				}
+			}
+		}
+	}
+	return variationIDs, nil
+}
@@ -0,0 +1,310 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	experimentclientmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
+	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// makePromotionFeatures returns the same feature in the source and the target environments.
+// Their variation IDs differ and only their default strategies are different.
+func makePromotionFeatures() (*featureproto.Feature, *featureproto.Feature) {
+	newFeature := func(variationPrefix, defaultVariation string) *featureproto.Feature {
+		return &featureproto.Feature{
+			Id:      "id-0",
+			Version: 1,
+			Variations: []*featureproto.Variation{
+				{Id: variationPrefix + "-a", Value: "A", Name: "A"},
+				{Id: variationPrefix + "-b", Value: "B", Name: "B"},
+			},
+			Targets: []*featureproto.Target{
+				{Variation: variationPrefix + "-a"},
+				{Variation: variationPrefix + "-b"},
+			},
+			Rules: []*featureproto.Rule{},
+			DefaultStrategy: &featureproto.Strategy{
+				Type:          featureproto.Strategy_FIXED,
+				FixedStrategy: &featureproto.FixedStrategy{Variation: variationPrefix + "-" + defaultVariation},
+			},
+			OffVariation: variationPrefix + "-b",
+		}
+	}
+	return newFeature("source", "a"), newFeature("target", "b")
+}
+
+// scanFeature fills the columns selected by the feature storage the same way the MySQL driver does.
+func scanFeature(f *featureproto.Feature) func(dest ...interface{}) error {
+	return func(dest ...interface{}) error {
+		*dest[0].(*string) = f.Id
+		*dest[8].(*int32) = f.Version
+		*dest[16].(*string) = f.OffVariation
+		jsonColumns := map[int]interface{}{
+			12: f.Variations,
+			13: f.Targets,
+			14: f.Rules,
+			15: f.DefaultStrategy,
+			20: f.Prerequisites,
+		}
+		for i, v := range jsonColumns {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(b, dest[i].(*mysql.JSONObject).Val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newFeatureRow(ctrl *gomock.Controller, f *featureproto.Feature) *mysqlmock.MockRow {
+	row := mysqlmock.NewMockRow(ctrl)
+	row.EXPECT().Scan(gomock.Any()).DoAndReturn(scanFeature(f))
+	return row
+}
+
+func newFeatureRows(ctrl *gomock.Controller, f *featureproto.Feature) *mysqlmock.MockRows {
+	rows := mysqlmock.NewMockRows(ctrl)
+	gomock.InOrder(
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Scan(gomock.Any()).DoAndReturn(scanFeature(f)),
+		rows.EXPECT().Next().Return(false),
+	)
+	rows.EXPECT().Err().Return(nil)
+	rows.EXPECT().Close().Return(nil)
+	return rows
+}
+
+func newCountRow(ctrl *gomock.Controller) *mysqlmock.MockRow {
+	row := mysqlmock.NewMockRow(ctrl)
+	row.EXPECT().Scan(gomock.Any()).Return(nil)
+	return row
+}
+
+func assertPromotionCommands(t *testing.T, commands []*featureproto.Command) {
+	t.Helper()
+	require.Len(t, commands, 1)
+	cmd := &featureproto.ChangeDefaultStrategyCommand{}
+	require.NoError(t, ptypes.UnmarshalAny(commands[0].Command, cmd))
+	assert.Equal(t, "target-a", cmd.Strategy.FixedStrategy.Variation)
+}
+
+func TestCompareFeatureAcrossEnvironmentsMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.CompareFeatureAcrossEnvironmentsRequest
+		expectedErr error
+	}{
+		{
+			desc: "err: missing id",
+			req: &featureproto.CompareFeatureAcrossEnvironmentsRequest{
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns1",
+			},
+			expectedErr: errMissingIDJaJP,
+		},
+		{
+			desc: "err: same environment",
+			req: &featureproto.CompareFeatureAcrossEnvironmentsRequest{
+				Id:                         "id-0",
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns0",
+			},
+			expectedErr: errIncorrectDestinationEnvironmentJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				source, target := makePromotionFeatures()
+				gomock.InOrder(
+					s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRow(mockController, source)),
+					s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRow(mockController, target)),
+				)
+			},
+			req: &featureproto.CompareFeatureAcrossEnvironmentsRequest{
+				Id:                         "id-0",
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns1",
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.CompareFeatureAcrossEnvironments(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.Empty(t, resp.Diff.Variations)
+				assert.NotNil(t, resp.Diff.DefaultStrategy)
+				assert.Nil(t, resp.Diff.OffVariation)
+			}
+		})
+	}
+}
+
+func TestPromoteFeatureMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	expectNoRunningExperiment := func(s *FeatureService) {
+		s.experimentClient.(*experimentclientmock.MockClient).EXPECT().ListExperiments(
+			gomock.Any(), gomock.Any(),
+		).Return(&experimentproto.ListExperimentsResponse{}, nil)
+	}
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.PromoteFeatureRequest
+		expectedErr error
+	}{
+		{
+			desc: "err: missing id",
+			req: &featureproto.PromoteFeatureRequest{
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns1",
+			},
+			expectedErr: errMissingIDJaJP,
+		},
+		{
+			desc: "err: same environment",
+			req: &featureproto.PromoteFeatureRequest{
+				Id:                         "id-0",
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns0",
+				DryRun:                     true,
+			},
+			expectedErr: errIncorrectDestinationEnvironmentJaJP,
+		},
+		{
+			desc: "success: dry run",
+			setup: func(s *FeatureService) {
+				expectNoRunningExperiment(s)
+				source, target := makePromotionFeatures()
+				client := s.mysqlClient.(*mysqlmock.MockClient)
+				gomock.InOrder(
+					client.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRow(mockController, source)),
+					client.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRow(mockController, target)),
+					client.EXPECT().QueryContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRows(mockController, target), nil),
+					client.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newCountRow(mockController)),
+				)
+			},
+			req: &featureproto.PromoteFeatureRequest{
+				Id:                         "id-0",
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns1",
+				DryRun:                     true,
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				expectNoRunningExperiment(s)
+				source, target := makePromotionFeatures()
+				client := s.mysqlClient.(*mysqlmock.MockClient)
+				client.EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(newFeatureRow(mockController, source))
+				tx := mysqlmock.NewMockTransaction(mockController)
+				client.EXPECT().BeginTx(gomock.Any()).Return(tx, nil)
+				client.EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).DoAndReturn(func(_ context.Context, _ mysql.Transaction, f func() error) error {
+					return f()
+				})
+				result := mysqlmock.NewMockResult(mockController)
+				result.EXPECT().RowsAffected().Return(int64(1), nil)
+				gomock.InOrder(
+					tx.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRow(mockController, target)),
+					tx.EXPECT().QueryContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newFeatureRows(mockController, target), nil),
+					tx.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newCountRow(mockController)),
+					tx.EXPECT().ExecContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(result, nil),
+					// The feature has no rollback rule, so its version isn't kept.
+					tx.EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(newCountRow(mockController)),
+				)
+				s.domainPublisher.(*publishermock.MockPublisher).EXPECT().PublishMulti(
+					gomock.Any(), gomock.Any(),
+				).Return(nil)
+			},
+			req: &featureproto.PromoteFeatureRequest{
+				Id:                         "id-0",
+				EnvironmentNamespace:       "ns0",
+				TargetEnvironmentNamespace: "ns1",
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.PromoteFeature(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.NotNil(t, resp.Diff.DefaultStrategy)
+				assertPromotionCommands(t, resp.Commands)
+			}
+		})
+	}
+}
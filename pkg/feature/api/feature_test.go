@@ -30,6 +30,7 @@ import (
 	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/storage"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
 	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
 	"github.com/bucketeer-io/bucketeer/pkg/uuid"
 	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
@@ -44,12 +45,25 @@ func TestGetFeatureMySQL(t *testing.T) {
 	patterns := map[string]struct {
 		setup    func(*FeatureService)
 		input    string
+		version  int32
 		expected error
 	}{
 		"error: id is empty": {
 			input:    "",
 			expected: errMissingIDJaJP,
 		},
+		"error: version not found": {
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			input:    "fid",
+			version:  2,
+			expected: errNotFoundJaJP,
+		},
 		"success": {
 			setup: func(s *FeatureService) {
 				row := mysqlmock.NewMockRow(mockController)
@@ -79,6 +93,7 @@ func TestGetFeatureMySQL(t *testing.T) {
 			req := &featureproto.GetFeatureRequest{
 				EnvironmentNamespace: "ns0",
 				Id:                   p.input,
+				Version:              p.version,
 			}
 			_, err := fs.GetFeature(ctx, req)
 			assert.Equal(t, p.expected, err)
@@ -228,6 +243,8 @@ func TestCreateFeatureMySQL(t *testing.T) {
 		variations                                        []*featureproto.Variation
 		tags                                              []string
 		defaultOnVariationIndex, defaultOffVariationIndex *wrappers.Int32Value
+		kind                                              featureproto.Feature_Kind
+		expectedRemovalAt                                 int64
 		environmentNamespace                              string
 		expected                                          error
 	}{
@@ -313,6 +330,20 @@ func TestCreateFeatureMySQL(t *testing.T) {
 			environmentNamespace:     "ns0",
 			expected:                 errMissingDefaultOffVariationJaJP,
 		},
+		{
+			setup:                    nil,
+			id:                       "Bucketeer-id-2019",
+			name:                     "name",
+			description:              "description",
+			variations:               variations,
+			tags:                     tags,
+			defaultOnVariationIndex:  &wrappers.Int32Value{Value: int32(0)},
+			defaultOffVariationIndex: &wrappers.Int32Value{Value: int32(1)},
+			kind:                     featureproto.Feature_OPS,
+			expectedRemovalAt:        time.Now().Unix(),
+			environmentNamespace:     "ns0",
+			expected:                 errRemovalDateOnPermanentFeatureJaJP,
+		},
 		{
 			setup: func(s *FeatureService) {
 				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
@@ -363,6 +394,8 @@ func TestCreateFeatureMySQL(t *testing.T) {
 				Tags:                     p.tags,
 				DefaultOnVariationIndex:  p.defaultOnVariationIndex,
 				DefaultOffVariationIndex: p.defaultOffVariationIndex,
+				Kind:                     p.kind,
+				ExpectedRemovalAt:        p.expectedRemovalAt,
 			},
 			EnvironmentNamespace: p.environmentNamespace,
 		}
@@ -2301,6 +2334,212 @@ func TestChangeDefaultStrategy(t *testing.T) {
 	}
 }
 
+func TestValidateSetLayerAssignment(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	patterns := map[string]*struct {
+		layerAssignment *featureproto.LayerAssignment
+		expectedErr     error
+	}{
+		"fail: nil": {
+			layerAssignment: nil,
+			expectedErr:     localizedError(statusInvalidLayerAssignment, locale.JaJP),
+		},
+		"fail: missing layer id": {
+			layerAssignment: &featureproto.LayerAssignment{
+				ExperimentId:        "experiment-id",
+				SliceStart:          10000,
+				SliceEnd:            20000,
+				BaselineVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidLayerAssignment, locale.JaJP),
+		},
+		"fail: slice overlaps holdout": {
+			layerAssignment: &featureproto.LayerAssignment{
+				LayerId:             "layer-id",
+				ExperimentId:        "experiment-id",
+				HoldoutWeight:       10000,
+				SliceStart:          5000,
+				SliceEnd:            20000,
+				BaselineVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidLayerAssignment, locale.JaJP),
+		},
+		"fail: slice exceeds hash space": {
+			layerAssignment: &featureproto.LayerAssignment{
+				LayerId:             "layer-id",
+				ExperimentId:        "experiment-id",
+				SliceStart:          90000,
+				SliceEnd:            100001,
+				BaselineVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidLayerAssignment, locale.JaJP),
+		},
+		"fail: baseline variation not found": {
+			layerAssignment: &featureproto.LayerAssignment{
+				LayerId:             "layer-id",
+				ExperimentId:        "experiment-id",
+				SliceStart:          10000,
+				SliceEnd:            20000,
+				BaselineVariationId: "variation-Z",
+			},
+			expectedErr: localizedError(statusInvalidLayerAssignment, locale.JaJP),
+		},
+		"success": {
+			layerAssignment: &featureproto.LayerAssignment{
+				LayerId:             "layer-id",
+				ExperimentId:        "experiment-id",
+				HoldoutWeight:       10000,
+				SliceStart:          10000,
+				SliceEnd:            20000,
+				BaselineVariationId: "variation-A",
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			cmd := &featureproto.SetLayerAssignmentCommand{
+				LayerAssignment: p.layerAssignment,
+			}
+			err := validateSetLayerAssignment(f.Variations, cmd)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestValidateSetExperimentAllocation(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	patterns := map[string]*struct {
+		experimentAllocation *featureproto.ExperimentAllocation
+		expectedErr          error
+	}{
+		"fail: nil": {
+			experimentAllocation: nil,
+			expectedErr:          localizedError(statusInvalidExperimentAllocation, locale.JaJP),
+		},
+		"fail: missing experiment id": {
+			experimentAllocation: &featureproto.ExperimentAllocation{
+				TrafficAllocation:  20000,
+				ControlVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidExperimentAllocation, locale.JaJP),
+		},
+		"fail: traffic allocation exceeds 100%": {
+			experimentAllocation: &featureproto.ExperimentAllocation{
+				ExperimentId:       "experiment-id",
+				TrafficAllocation:  100001,
+				ControlVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidExperimentAllocation, locale.JaJP),
+		},
+		"fail: audience clause without values": {
+			experimentAllocation: &featureproto.ExperimentAllocation{
+				ExperimentId:       "experiment-id",
+				Audience:           []*featureproto.Clause{{Attribute: "country"}},
+				ControlVariationId: "variation-A",
+			},
+			expectedErr: localizedError(statusInvalidExperimentAllocation, locale.JaJP),
+		},
+		"fail: control variation not found": {
+			experimentAllocation: &featureproto.ExperimentAllocation{
+				ExperimentId:       "experiment-id",
+				TrafficAllocation:  20000,
+				ControlVariationId: "variation-Z",
+			},
+			expectedErr: localizedError(statusInvalidExperimentAllocation, locale.JaJP),
+		},
+		"success": {
+			experimentAllocation: &featureproto.ExperimentAllocation{
+				ExperimentId:      "experiment-id",
+				TrafficAllocation: 20000,
+				Audience: []*featureproto.Clause{
+					{Attribute: "country", Operator: featureproto.Clause_EQUALS, Values: []string{"jp"}},
+				},
+				ControlVariationId: "variation-A",
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			cmd := &featureproto.SetExperimentAllocationCommand{
+				ExperimentAllocation: p.experimentAllocation,
+			}
+			err := validateSetExperimentAllocation(f.Variations, cmd)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestValidateBanditStrategy(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	weights := &featureproto.RolloutStrategy{
+		Variations: []*featureproto.RolloutStrategy_Variation{
+			{Variation: "variation-A", Weight: 50000},
+			{Variation: "variation-B", Weight: 50000},
+		},
+	}
+	patterns := map[string]*struct {
+		strategy    *featureproto.Strategy
+		expectedErr error
+	}{
+		"fail: missing rollout strategy": {
+			strategy: &featureproto.Strategy{
+				Type:           featureproto.Strategy_BANDIT,
+				BanditStrategy: &featureproto.BanditStrategy{GoalId: "goal-id"},
+			},
+			expectedErr: localizedError(statusMissingRolloutStrategy, locale.JaJP),
+		},
+		"fail: missing bandit strategy": {
+			strategy: &featureproto.Strategy{
+				Type:            featureproto.Strategy_BANDIT,
+				RolloutStrategy: weights,
+			},
+			expectedErr: localizedError(statusMissingBanditStrategy, locale.JaJP),
+		},
+		"fail: missing goal id": {
+			strategy: &featureproto.Strategy{
+				Type:            featureproto.Strategy_BANDIT,
+				RolloutStrategy: weights,
+				BanditStrategy:  &featureproto.BanditStrategy{},
+			},
+			expectedErr: localizedError(statusInvalidBanditStrategy, locale.JaJP),
+		},
+		"fail: exploration exceeds total weight": {
+			strategy: &featureproto.Strategy{
+				Type:            featureproto.Strategy_BANDIT,
+				RolloutStrategy: weights,
+				BanditStrategy: &featureproto.BanditStrategy{
+					GoalId:               "goal-id",
+					MinExplorationWeight: 50001,
+				},
+			},
+			expectedErr: localizedError(statusInvalidBanditStrategy, locale.JaJP),
+		},
+		"success": {
+			strategy: &featureproto.Strategy{
+				Type:            featureproto.Strategy_BANDIT,
+				RolloutStrategy: weights,
+				BanditStrategy: &featureproto.BanditStrategy{
+					GoalId:               "goal-id",
+					UpdateInterval:       3600,
+					MinExplorationWeight: 5000,
+				},
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			err := validateStrategy(f.Variations, p.strategy)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
 func TestValidateFeatureVariationsCommand(t *testing.T) {
 	t.Parallel()
 	fID0 := "fID-0"
@@ -0,0 +1,232 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var errNoFeatureToToggle = errors.New("feature: no enabled feature has the tag")
+
+// BulkToggleByTag applies the action to all the enabled features with the tag and records their previous state
+// under the incident ID, in one transaction.
+// Unlike the updates of a single feature, running experiments don't prevent the toggle during an incident.
+func (s *FeatureService) BulkToggleByTag(
+	ctx context.Context,
+	req *featureproto.BulkToggleByTagRequest,
+) (*featureproto.BulkToggleByTagResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBulkToggleByTagRequest(req); err != nil {
+		return nil, err
+	}
+	incident := domain.NewFeatureIncident(req.IncidentId, req.Tag, req.Action, req.Comment)
+	comment := fmt.Sprintf("Toggled by the incident %s", req.IncidentId)
+	events := []*eventproto.Event{}
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		featureStorage := v2fs.NewFeatureStorage(tx)
+		features, _, _, err := featureStorage.ListFeatures(
+			ctx,
+			[]mysql.WherePart{
+				mysql.NewFilter("enabled", "=", true),
+				mysql.NewFilter("archived", "=", false),
+				mysql.NewFilter("deleted", "=", false),
+				mysql.NewFilter("environment_namespace", "=", req.EnvironmentNamespace),
+				mysql.NewJSONFilter("tags", mysql.JSONContainsString, []interface{}{req.Tag}),
+			},
+			nil,
+			mysql.QueryNoLimit,
+			mysql.QueryNoOffset,
+		)
+		if err != nil {
+			return err
+		}
+		if len(features) == 0 {
+			return errNoFeatureToToggle
+		}
+		for _, f := range features {
+			incident.AddSnapshot(f)
+			feature := &domain.Feature{Feature: f}
+			handler := command.NewFeatureCommandHandler(editor, feature, req.EnvironmentNamespace, comment)
+			if err := handler.Handle(ctx, &featureproto.IncrementFeatureVersionCommand{}); err != nil {
+				return err
+			}
+			for _, cmd := range command.IncidentToggleCommands(feature, req.Action) {
+				if err := handler.Handle(ctx, cmd); err != nil {
+					return err
+				}
+			}
+			if err := featureStorage.UpdateFeature(ctx, feature, req.EnvironmentNamespace); err != nil {
+				return err
+			}
+			events = append(events, handler.Events...)
+		}
+		return v2fs.NewFeatureIncidentStorage(tx).CreateFeatureIncident(ctx, incident, req.EnvironmentNamespace)
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to bulk toggle features by tag",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("incidentId", req.IncidentId),
+				zap.String("tag", req.Tag),
+			)...,
+		)
+		return nil, s.convFeatureIncidentError(err)
+	}
+	if errs := s.publishDomainEvents(ctx, events); len(errs) > 0 {
+		s.logger.Error(
+			"Failed to publish events",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Any("errors", errs),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &featureproto.BulkToggleByTagResponse{Incident: incident.FeatureIncident}, nil
+}
+
+// RestoreBulkToggle returns every feature toggled by the incident to its recorded state in one transaction.
+// The changes made to the features during the incident are overwritten.
+func (s *FeatureService) RestoreBulkToggle(
+	ctx context.Context,
+	req *featureproto.RestoreBulkToggleRequest,
+) (*featureproto.RestoreBulkToggleResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRestoreBulkToggleRequest(req); err != nil {
+		return nil, err
+	}
+	var incident *domain.FeatureIncident
+	comment := fmt.Sprintf("Restored from the incident %s", req.IncidentId)
+	events := []*eventproto.Event{}
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		incidentStorage := v2fs.NewFeatureIncidentStorage(tx)
+		incident, err = incidentStorage.GetFeatureIncident(ctx, req.IncidentId, req.EnvironmentNamespace)
+		if err != nil {
+			return err
+		}
+		if err := incident.Restore(); err != nil {
+			return err
+		}
+		featureStorage := v2fs.NewFeatureStorage(tx)
+		for _, snapshot := range incident.FeatureSnapshots {
+			feature, err := featureStorage.GetFeature(ctx, snapshot.Id, req.EnvironmentNamespace)
+			if err != nil {
+				return err
+			}
+			commands, err := command.SnapshotRestoreCommands(snapshot, feature)
+			if err != nil {
+				return err
+			}
+			if len(commands) == 0 {
+				continue
+			}
+			handler := command.NewFeatureCommandHandler(editor, feature, req.EnvironmentNamespace, comment)
+			if err := handler.Handle(ctx, &featureproto.IncrementFeatureVersionCommand{}); err != nil {
+				return err
+			}
+			for _, cmd := range commands {
+				if err := handler.Handle(ctx, cmd); err != nil {
+					return err
+				}
+			}
+			if err := featureStorage.UpdateFeature(ctx, feature, req.EnvironmentNamespace); err != nil {
+				return err
+			}
+			events = append(events, handler.Events...)
+		}
+		return incidentStorage.UpdateFeatureIncident(ctx, incident, req.EnvironmentNamespace)
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to restore bulk toggle",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+				zap.String("incidentId", req.IncidentId),
+			)...,
+		)
+		return nil, s.convFeatureIncidentError(err)
+	}
+	if errs := s.publishDomainEvents(ctx, events); len(errs) > 0 {
+		s.logger.Error(
+			"Failed to publish events",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Any("errors", errs),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &featureproto.RestoreBulkToggleResponse{Incident: incident.FeatureIncident}, nil
+}
+
+func (s *FeatureService) convFeatureIncidentError(err error) error {
+	switch err {
+	case errNoFeatureToToggle:
+		return localizedError(statusNothingChange, locale.JaJP)
+	case v2fs.ErrFeatureIncidentAlreadyExists:
+		return localizedError(statusIncidentAlreadyExists, locale.JaJP)
+	case v2fs.ErrFeatureIncidentNotFound:
+		return localizedError(statusIncidentNotFound, locale.JaJP)
+	case domain.ErrFeatureIncidentAlreadyRestored:
+		return localizedError(statusIncidentAlreadyRestored, locale.JaJP)
+	case domain.ErrPromotionVariationNotFound:
+		return localizedError(statusIncidentNotRestorable, locale.JaJP)
+	default:
+		return s.convUpdateFeatureError(err)
+	}
+}
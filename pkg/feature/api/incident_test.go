@@ -0,0 +1,187 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
+	publishermock "github.com/bucketeer-io/bucketeer/pkg/pubsub/publisher/mock"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestBulkToggleByTagMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.BulkToggleByTagRequest
+		expectedErr error
+	}{
+		{
+			desc:        "err: missing incident id",
+			req:         &featureproto.BulkToggleByTagRequest{EnvironmentNamespace: "ns0", Tag: "payments"},
+			expectedErr: errMissingIncidentIDJaJP,
+		},
+		{
+			desc:        "err: missing tag",
+			req:         &featureproto.BulkToggleByTagRequest{EnvironmentNamespace: "ns0", IncidentId: "INC-1"},
+			expectedErr: errMissingFeatureTagJaJP,
+		},
+		{
+			desc: "err: invalid action",
+			req: &featureproto.BulkToggleByTagRequest{
+				EnvironmentNamespace: "ns0",
+				Tag:                  "payments",
+				IncidentId:           "INC-1",
+				Action:               featureproto.FeatureIncident_Action(99),
+			},
+			expectedErr: errInvalidIncidentActionJaJP,
+		},
+		{
+			desc: "err: no feature has the tag",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(errNoFeatureToToggle)
+			},
+			req: &featureproto.BulkToggleByTagRequest{
+				EnvironmentNamespace: "ns0",
+				Tag:                  "payments",
+				IncidentId:           "INC-1",
+			},
+			expectedErr: errNothingChangeJaJP,
+		},
+		{
+			desc: "err: incident already exists",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(v2fs.ErrFeatureIncidentAlreadyExists)
+			},
+			req: &featureproto.BulkToggleByTagRequest{
+				EnvironmentNamespace: "ns0",
+				Tag:                  "payments",
+				IncidentId:           "INC-1",
+			},
+			expectedErr: errIncidentAlreadyExistsJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil)
+				s.domainPublisher.(*publishermock.MockPublisher).EXPECT().PublishMulti(
+					gomock.Any(), gomock.Any(),
+				).Return(nil)
+			},
+			req: &featureproto.BulkToggleByTagRequest{
+				EnvironmentNamespace: "ns0",
+				Tag:                  "payments",
+				IncidentId:           "INC-1",
+				Action:               featureproto.FeatureIncident_SERVE_OFF_VARIATION,
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.BulkToggleByTag(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.Equal(t, p.req.IncidentId, resp.Incident.Id)
+				assert.Equal(t, p.req.Action, resp.Incident.Action)
+			}
+		})
+	}
+}
+
+func TestRestoreBulkToggleMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.RestoreBulkToggleRequest
+		expectedErr error
+	}{
+		{
+			desc:        "err: missing incident id",
+			req:         &featureproto.RestoreBulkToggleRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: errMissingIncidentIDJaJP,
+		},
+		{
+			desc: "err: incident not found",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(v2fs.ErrFeatureIncidentNotFound)
+			},
+			req:         &featureproto.RestoreBulkToggleRequest{EnvironmentNamespace: "ns0", IncidentId: "INC-1"},
+			expectedErr: errIncidentNotFoundJaJP,
+		},
+		{
+			desc: "err: incident already restored",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(domain.ErrFeatureIncidentAlreadyRestored)
+			},
+			req:         &featureproto.RestoreBulkToggleRequest{EnvironmentNamespace: "ns0", IncidentId: "INC-1"},
+			expectedErr: errIncidentAlreadyRestoredJaJP,
+		},
+		{
+			desc: "err: variation removed since the incident",
+			setup: func(s *FeatureService) {
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().BeginTx(gomock.Any()).Return(nil, nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().RunInTransaction(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(domain.ErrPromotionVariationNotFound)
+			},
+			req:         &featureproto.RestoreBulkToggleRequest{EnvironmentNamespace: "ns0", IncidentId: "INC-1"},
+			expectedErr: errIncidentNotRestorableJaJP,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			_, err := service.RestoreBulkToggle(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,533 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const autoArchiveComment = "Archived by the feature lifecycle policy"
+
+func (s *FeatureService) GetFeatureLifecyclePolicy(
+	ctx context.Context,
+	req *featureproto.GetFeatureLifecyclePolicyRequest,
+) (*featureproto.GetFeatureLifecyclePolicyResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.getFeatureLifecyclePolicy(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return &featureproto.GetFeatureLifecyclePolicyResponse{Policy: policy.FeatureLifecyclePolicy}, nil
+}
+
+func (s *FeatureService) UpdateFeatureLifecyclePolicy(
+	ctx context.Context,
+	req *featureproto.UpdateFeatureLifecyclePolicyRequest,
+) (*featureproto.UpdateFeatureLifecyclePolicyResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUpdateFeatureLifecyclePolicyRequest(req); err != nil {
+		return nil, err
+	}
+	policy, err := s.getFeatureLifecyclePolicy(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	policy.Update(req.Policy)
+	policyStorage := v2fs.NewFeatureLifecyclePolicyStorage(s.mysqlClient)
+	if err := policyStorage.UpsertFeatureLifecyclePolicy(ctx, policy, req.EnvironmentNamespace); err != nil {
+		s.logger.Error(
+			"Failed to upsert feature lifecycle policy",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &featureproto.UpdateFeatureLifecyclePolicyResponse{}, nil
+}
+
+func (s *FeatureService) ListFeatureCleanupCandidates(
+	ctx context.Context,
+	req *featureproto.ListFeatureCleanupCandidatesRequest,
+) (*featureproto.ListFeatureCleanupCandidatesResponse, error) {
+	_, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.getFeatureLifecyclePolicy(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	features, stored, err := s.listFeaturesAndCleanupCandidates(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	candidates := []*featureproto.FeatureCleanupCandidate{}
+	for _, f := range features {
+		c, ok := stored[f.Id]
+		if f.Archived {
+			// Features archived by the policy are listed until the undo period is over.
+			if ok && c.Status == featureproto.FeatureCleanupCandidate_ARCHIVED &&
+				now.Unix() < policy.UndoDeadline(c.FeatureCleanupCandidate) {
+				candidates = append(candidates, newCleanupCandidateResponse(policy, c, f))
+			}
+			continue
+		}
+		if ok && c.Exempts(f) {
+			continue
+		}
+		reasons := policy.Reasons(f, now)
+		if len(reasons) == 0 {
+			continue
+		}
+		if !ok || c.Status != featureproto.FeatureCleanupCandidate_WARNED {
+			c = domain.NewFeatureCleanupCandidate(f.Id, reasons)
+		} else {
+			c.SetReasons(reasons)
+		}
+		candidates = append(candidates, newCleanupCandidateResponse(policy, c, f))
+	}
+	return &featureproto.ListFeatureCleanupCandidatesResponse{Candidates: candidates}, nil
+}
+
+// ApplyFeatureLifecyclePolicy warns the features that started qualifying, archives the warned ones
+// whose grace period is over, and forgets the ones that no longer qualify.
+// A feature that can't be archived safely stays warned and is retried on the next call.
+// A feature whose archive was undone is skipped until it changes.
+func (s *FeatureService) ApplyFeatureLifecyclePolicy(
+	ctx context.Context,
+	req *featureproto.ApplyFeatureLifecyclePolicyRequest,
+) (*featureproto.ApplyFeatureLifecyclePolicyResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.getFeatureLifecyclePolicy(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	features, stored, err := s.listFeaturesAndCleanupCandidates(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	activeFeatures := make([]*featureproto.Feature, 0, len(features))
+	for _, f := range features {
+		if !f.Archived {
+			activeFeatures = append(activeFeatures, f)
+		}
+	}
+	candidateStorage := v2fs.NewFeatureCleanupCandidateStorage(s.mysqlClient)
+	now := time.Now()
+	resp := &featureproto.ApplyFeatureLifecyclePolicyResponse{}
+	events := []*eventproto.Event{}
+	for _, f := range features {
+		c, ok := stored[f.Id]
+		delete(stored, f.Id)
+		if f.Archived {
+			if ok && (c.Status != featureproto.FeatureCleanupCandidate_ARCHIVED ||
+				now.Unix() >= policy.UndoDeadline(c.FeatureCleanupCandidate)) {
+				if err := s.deleteFeatureCleanupCandidate(ctx, candidateStorage, f.Id, req.EnvironmentNamespace); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if ok && c.Exempts(f) {
+			continue
+		}
+		reasons := policy.Reasons(f, now)
+		if len(reasons) == 0 {
+			if ok {
+				if err := s.deleteFeatureCleanupCandidate(ctx, candidateStorage, f.Id, req.EnvironmentNamespace); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		// A feature unarchived after being archived by the policy, or changed after its archive was undone,
+		// starts a new warning cycle.
+		if !ok || c.Status != featureproto.FeatureCleanupCandidate_WARNED {
+			c = domain.NewFeatureCleanupCandidate(f.Id, reasons)
+			c.Warn(now)
+			if err := s.upsertFeatureCleanupCandidate(ctx, candidateStorage, c, req.EnvironmentNamespace); err != nil {
+				return nil, err
+			}
+			resp.WarnedCandidates = append(resp.WarnedCandidates, newCleanupCandidateResponse(policy, c, f))
+			continue
+		}
+		c.SetReasons(reasons)
+		archiveAt := policy.ArchiveAt(c.FeatureCleanupCandidate)
+		if archiveAt == 0 || now.Unix() < archiveAt {
+			if err := s.upsertFeatureCleanupCandidate(ctx, candidateStorage, c, req.EnvironmentNamespace); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		archived, es, err := s.archiveFeatureByPolicy(ctx, editor, c, activeFeatures, now, req.EnvironmentNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if archived == nil {
+			continue
+		}
+		events = append(events, es...)
+		resp.ArchivedCandidates = append(resp.ArchivedCandidates, newCleanupCandidateResponse(policy, c, archived))
+	}
+	// The remaining candidates belong to deleted features.
+	for id := range stored {
+		if err := s.deleteFeatureCleanupCandidate(ctx, candidateStorage, id, req.EnvironmentNamespace); err != nil {
+			return nil, err
+		}
+	}
+	if errs := s.publishDomainEvents(ctx, events); len(errs) > 0 {
+		s.logger.Error(
+			"Failed to publish events",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Any("errors", errs),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return resp, nil
+}
+
+func (s *FeatureService) UndoFeatureAutoArchive(
+	ctx context.Context,
+	req *featureproto.UndoFeatureAutoArchiveRequest,
+) (*featureproto.UndoFeatureAutoArchiveResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_EDITOR, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUndoFeatureAutoArchiveRequest(req); err != nil {
+		return nil, err
+	}
+	policy, err := s.getFeatureLifecyclePolicy(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	candidateStorage := v2fs.NewFeatureCleanupCandidateStorage(s.mysqlClient)
+	c, err := candidateStorage.GetFeatureCleanupCandidate(ctx, req.Id, req.EnvironmentNamespace)
+	if err != nil {
+		if err == v2fs.ErrFeatureCleanupCandidateNotFound {
+			return nil, localizedError(statusNotArchivedByPolicy, locale.JaJP)
+		}
+		s.logger.Error(
+			"Failed to get feature cleanup candidate",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if c.Status != featureproto.FeatureCleanupCandidate_ARCHIVED {
+		return nil, localizedError(statusNotArchivedByPolicy, locale.JaJP)
+	}
+	if time.Now().Unix() >= policy.UndoDeadline(c.FeatureCleanupCandidate) {
+		return nil, localizedError(statusUndoPeriodExpired, locale.JaJP)
+	}
+	var handler *command.FeatureCommandHandler = command.NewEmptyFeatureCommandHandler()
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		featureStorage := v2fs.NewFeatureStorage(tx)
+		feature, err := featureStorage.GetFeature(ctx, req.Id, req.EnvironmentNamespace)
+		if err != nil {
+			return err
+		}
+		handler = command.NewFeatureCommandHandler(editor, feature, req.EnvironmentNamespace, "")
+		if err := handler.Handle(ctx, &featureproto.IncrementFeatureVersionCommand{}); err != nil {
+			return err
+		}
+		if err := handler.Handle(ctx, &featureproto.UnarchiveFeatureCommand{}); err != nil {
+			return err
+		}
+		if err := featureStorage.UpdateFeature(ctx, feature, req.EnvironmentNamespace); err != nil {
+			return err
+		}
+		// The feature still qualifies, so the candidate is kept to stop the policy from archiving it again.
+		c.Exempt(feature.Version, time.Now())
+		return v2fs.NewFeatureCleanupCandidateStorage(tx).UpsertFeatureCleanupCandidate(
+			ctx,
+			c,
+			req.EnvironmentNamespace,
+		)
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to undo feature auto archive",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, s.convUpdateFeatureError(err)
+	}
+	if errs := s.publishDomainEvents(ctx, handler.Events); len(errs) > 0 {
+		s.logger.Error(
+			"Failed to publish events",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Any("errors", errs),
+				zap.String("environmentNamespace", req.EnvironmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return &featureproto.UndoFeatureAutoArchiveResponse{}, nil
+}
+
+// getFeatureLifecyclePolicy returns the disabled default policy if the environment has none.
+func (s *FeatureService) getFeatureLifecyclePolicy(
+	ctx context.Context,
+	environmentNamespace string,
+) (*domain.FeatureLifecyclePolicy, error) {
+	policyStorage := v2fs.NewFeatureLifecyclePolicyStorage(s.mysqlClient)
+	policy, err := policyStorage.GetFeatureLifecyclePolicy(ctx, environmentNamespace)
+	if err != nil {
+		if err == v2fs.ErrFeatureLifecyclePolicyNotFound {
+			return domain.NewFeatureLifecyclePolicy(), nil
+		}
+		s.logger.Error(
+			"Failed to get feature lifecycle policy",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return policy, nil
+}
+
+// listFeaturesAndCleanupCandidates returns the features that are not deleted with their last used info,
+// and the stored cleanup candidates keyed by the feature ID.
+func (s *FeatureService) listFeaturesAndCleanupCandidates(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*featureproto.Feature, map[string]*domain.FeatureCleanupCandidate, error) {
+	featureStorage := v2fs.NewFeatureStorage(s.mysqlClient)
+	features, _, _, err := featureStorage.ListFeatures(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list features",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if err := s.setLastUsedInfosToFeatureByChunk(ctx, features, environmentNamespace); err != nil {
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	candidateStorage := v2fs.NewFeatureCleanupCandidateStorage(s.mysqlClient)
+	candidates, err := candidateStorage.ListFeatureCleanupCandidates(ctx, environmentNamespace)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list feature cleanup candidates",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	stored := make(map[string]*domain.FeatureCleanupCandidate, len(candidates))
+	for _, c := range candidates {
+		stored[c.FeatureId] = c
+	}
+	return features, stored, nil
+}
+
+// archiveFeatureByPolicy archives the feature of the candidate with the same checks as ArchiveFeature.
+// It returns a nil feature when the feature can't be archived now.
+func (s *FeatureService) archiveFeatureByPolicy(
+	ctx context.Context,
+	editor *eventproto.Editor,
+	c *domain.FeatureCleanupCandidate,
+	activeFeatures []*featureproto.Feature,
+	now time.Time,
+	environmentNamespace string,
+) (*featureproto.Feature, []*eventproto.Event, error) {
+	archiveReq := &featureproto.ArchiveFeatureRequest{
+		Id:                   c.FeatureId,
+		Command:              &featureproto.ArchiveFeatureCommand{},
+		EnvironmentNamespace: environmentNamespace,
+	}
+	if err := validateArchiveFeatureRequest(archiveReq, activeFeatures); err != nil {
+		s.logger.Info(
+			"Skipped archiving a feature used as a prerequisite",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.String("featureId", c.FeatureId),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, nil, nil
+	}
+	runningExperimentExists, err := s.existsRunningExperiment(ctx, c.FeatureId, environmentNamespace)
+	if err != nil {
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	if runningExperimentExists {
+		s.logger.Info(
+			"Skipped archiving a feature used in a running experiment",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.String("featureId", c.FeatureId),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, nil, nil
+	}
+	var handler *command.FeatureCommandHandler = command.NewEmptyFeatureCommandHandler()
+	var feature *domain.Feature
+	tx, err := s.mysqlClient.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error(
+			"Failed to begin transaction",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	err = s.mysqlClient.RunInTransaction(ctx, tx, func() error {
+		featureStorage := v2fs.NewFeatureStorage(tx)
+		feature, err = featureStorage.GetFeature(ctx, c.FeatureId, environmentNamespace)
+		if err != nil {
+			return err
+		}
+		handler = command.NewFeatureCommandHandler(editor, feature, environmentNamespace, autoArchiveComment)
+		if err := handler.Handle(ctx, &featureproto.IncrementFeatureVersionCommand{}); err != nil {
+			return err
+		}
+		if err := handler.Handle(ctx, archiveReq.Command); err != nil {
+			return err
+		}
+		if err := featureStorage.UpdateFeature(ctx, feature, environmentNamespace); err != nil {
+			return err
+		}
+		c.Archive(now)
+		return v2fs.NewFeatureCleanupCandidateStorage(tx).UpsertFeatureCleanupCandidate(ctx, c, environmentNamespace)
+	})
+	if err != nil {
+		s.logger.Error(
+			"Failed to archive feature by the lifecycle policy",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("featureId", c.FeatureId),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, nil, localizedError(statusInternal, locale.JaJP)
+	}
+	return feature.Feature, handler.Events, nil
+}
+
+func (s *FeatureService) upsertFeatureCleanupCandidate(
+	ctx context.Context,
+	candidateStorage v2fs.FeatureCleanupCandidateStorage,
+	c *domain.FeatureCleanupCandidate,
+	environmentNamespace string,
+) error {
+	if err := candidateStorage.UpsertFeatureCleanupCandidate(ctx, c, environmentNamespace); err != nil {
+		s.logger.Error(
+			"Failed to upsert feature cleanup candidate",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("featureId", c.FeatureId),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	return nil
+}
+
+func (s *FeatureService) deleteFeatureCleanupCandidate(
+	ctx context.Context,
+	candidateStorage v2fs.FeatureCleanupCandidateStorage,
+	featureID, environmentNamespace string,
+) error {
+	if err := candidateStorage.DeleteFeatureCleanupCandidate(ctx, featureID, environmentNamespace); err != nil {
+		s.logger.Error(
+			"Failed to delete feature cleanup candidate",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("featureId", featureID),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	return nil
+}
+
+func newCleanupCandidateResponse(
+	policy *domain.FeatureLifecyclePolicy,
+	c *domain.FeatureCleanupCandidate,
+	f *featureproto.Feature,
+) *featureproto.FeatureCleanupCandidate {
+	return &featureproto.FeatureCleanupCandidate{
+		FeatureId:    c.FeatureId,
+		Reasons:      c.Reasons,
+		Status:       c.Status,
+		WarnedAt:     c.WarnedAt,
+		ArchivedAt:   c.ArchivedAt,
+		UpdatedAt:    c.UpdatedAt,
+		Feature:      f,
+		ArchiveAt:    policy.ArchiveAt(c.FeatureCleanupCandidate),
+		UndoDeadline: policy.UndoDeadline(c.FeatureCleanupCandidate),
+	}
+}
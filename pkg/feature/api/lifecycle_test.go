@@ -0,0 +1,206 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestGetFeatureLifecyclePolicyMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		expected    *featureproto.FeatureLifecyclePolicy
+		expectedErr error
+	}{
+		{
+			desc: "success: default policy",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			expected:    &featureproto.FeatureLifecyclePolicy{},
+			expectedErr: nil,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			expected:    &featureproto.FeatureLifecyclePolicy{},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			p.setup(service)
+			resp, err := service.GetFeatureLifecyclePolicy(
+				createContextWithToken(),
+				&featureproto.GetFeatureLifecyclePolicyRequest{EnvironmentNamespace: "ns0"},
+			)
+			assert.Equal(t, p.expectedErr, err)
+			if err == nil {
+				assert.Equal(t, p.expected.NotEvaluatedDays, resp.Policy.NotEvaluatedDays)
+				assert.Equal(t, p.expected.SingleVariation, resp.Policy.SingleVariation)
+				assert.Equal(t, p.expected.AutoArchive, resp.Policy.AutoArchive)
+			}
+		})
+	}
+}
+
+func TestUpdateFeatureLifecyclePolicyMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.UpdateFeatureLifecyclePolicyRequest
+		expectedErr error
+	}{
+		{
+			desc:        "err: missing policy",
+			req:         &featureproto.UpdateFeatureLifecyclePolicyRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: errMissingLifecyclePolicyJaJP,
+		},
+		{
+			desc: "err: negative days",
+			req: &featureproto.UpdateFeatureLifecyclePolicyRequest{
+				EnvironmentNamespace: "ns0",
+				Policy:               &featureproto.FeatureLifecyclePolicy{GracePeriodDays: -1},
+			},
+			expectedErr: errInvalidLifecyclePolicyJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil)
+			},
+			req: &featureproto.UpdateFeatureLifecyclePolicyRequest{
+				EnvironmentNamespace: "ns0",
+				Policy: &featureproto.FeatureLifecyclePolicy{
+					NotEvaluatedDays: 30,
+					GracePeriodDays:  7,
+					UndoPeriodDays:   7,
+					AutoArchive:      true,
+				},
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			_, err := service.UpdateFeatureLifecyclePolicy(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestUndoFeatureAutoArchiveMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.UndoFeatureAutoArchiveRequest
+		expectedErr error
+	}{
+		{
+			desc:        "err: missing id",
+			req:         &featureproto.UndoFeatureAutoArchiveRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: errMissingIDJaJP,
+		},
+		{
+			desc: "err: not archived by the policy",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows).Times(2)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row).Times(2)
+			},
+			req:         &featureproto.UndoFeatureAutoArchiveRequest{Id: "id-0", EnvironmentNamespace: "ns0"},
+			expectedErr: errNotArchivedByPolicyJaJP,
+		},
+		{
+			desc: "err: undo period expired",
+			setup: func(s *FeatureService) {
+				policyRow := mysqlmock.NewMockRow(mockController)
+				policyRow.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				candidateRow := mysqlmock.NewMockRow(mockController)
+				candidateRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+					// status
+					*dest[2].(*int32) = int32(featureproto.FeatureCleanupCandidate_ARCHIVED)
+					// archived_at
+					*dest[4].(*int64) = 1
+					return nil
+				})
+				gomock.InOrder(
+					s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(policyRow),
+					s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+						gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+					).Return(candidateRow),
+				)
+			},
+			req:         &featureproto.UndoFeatureAutoArchiveRequest{Id: "id-0", EnvironmentNamespace: "ns0"},
+			expectedErr: errUndoPeriodExpiredJaJP,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			service := createFeatureServiceNew(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			_, err := service.UndoFeatureAutoArchive(createContextWithToken(), p.req)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/command"
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	v2fs "github.com/bucketeer-io/bucketeer/pkg/feature/storage/v2"
+	"github.com/bucketeer-io/bucketeer/pkg/locale"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	accountproto "github.com/bucketeer-io/bucketeer/proto/account"
+	eventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func (s *FeatureService) LintFeature(
+	ctx context.Context,
+	req *featureproto.LintFeatureRequest,
+) (*featureproto.LintFeatureResponse, error) {
+	editor, err := s.checkRole(ctx, accountproto.Account_VIEWER, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, localizedError(statusMissingID, locale.JaJP)
+	}
+	commands := make([]command.Command, 0, len(req.Commands))
+	for _, c := range req.Commands {
+		cmd, err := command.UnmarshalCommand(c)
+		if err != nil {
+			s.logger.Error(
+				"Failed to unmarshal command",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", req.EnvironmentNamespace),
+				)...,
+			)
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	f, err := s.getFeature(ctx, s.mysqlClient, req.Id, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) > 0 {
+		if err := s.applyLintCommands(ctx, editor, f, commands, req.EnvironmentNamespace); err != nil {
+			return nil, err
+		}
+	}
+	segments, err := s.listAllSegments(ctx, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	lintSegments := make(map[string]*featureproto.Segment, len(segments))
+	for _, sg := range segments {
+		lintSegments[sg.Id] = sg
+	}
+	prerequisites, err := s.listPrerequisiteFeatures(ctx, f.Feature, req.EnvironmentNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return &featureproto.LintFeatureResponse{
+		Findings: domain.LintFeature(f.Feature, lintSegments, prerequisites),
+	}, nil
+}
+
+// applyLintCommands applies the commands to the feature in memory, so the result can be analyzed
+// before it is saved. The commands are validated the same way as in UpdateFeatureTargeting.
+func (s *FeatureService) applyLintCommands(
+	ctx context.Context,
+	editor *eventproto.Editor,
+	f *domain.Feature,
+	commands []command.Command,
+	environmentNamespace string,
+) error {
+	featureStorage := v2fs.NewFeatureStorage(s.mysqlClient)
+	features, _, _, err := featureStorage.ListFeatures(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("archived", "=", false),
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list feature",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return localizedError(statusInternal, locale.JaJP)
+	}
+	for _, cmd := range commands {
+		if err := validateFeatureTargetingCommand(features, f.Feature, cmd); err != nil {
+			s.logger.Info(
+				"Invalid argument",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", environmentNamespace),
+				)...,
+			)
+			return err
+		}
+	}
+	handler := command.NewFeatureCommandHandler(
+		editor,
+		f,
+		environmentNamespace,
+		"",
+	)
+	for _, cmd := range commands {
+		if err := handler.Handle(ctx, cmd); err != nil {
+			s.logger.Error(
+				"Failed to handle command",
+				log.FieldsFromImcomingContext(ctx).AddFields(
+					zap.Error(err),
+					zap.String("environmentNamespace", environmentNamespace),
+				)...,
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// listPrerequisiteFeatures returns the prerequisite features of a feature, including the archived ones.
+func (s *FeatureService) listPrerequisiteFeatures(
+	ctx context.Context,
+	f *featureproto.Feature,
+	environmentNamespace string,
+) (map[string]*featureproto.Feature, error) {
+	prerequisites := make(map[string]*featureproto.Feature, len(f.Prerequisites))
+	if len(f.Prerequisites) == 0 {
+		return prerequisites, nil
+	}
+	ids := make([]interface{}, 0, len(f.Prerequisites))
+	for _, p := range f.Prerequisites {
+		ids = append(ids, p.FeatureId)
+	}
+	featureStorage := v2fs.NewFeatureStorage(s.mysqlClient)
+	features, _, _, err := featureStorage.ListFeatures(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("deleted", "=", false),
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+			mysql.NewInFilter("id", ids),
+		},
+		nil,
+		mysql.QueryNoLimit,
+		mysql.QueryNoOffset,
+	)
+	if err != nil {
+		s.logger.Error(
+			"Failed to list prerequisite features",
+			log.FieldsFromImcomingContext(ctx).AddFields(
+				zap.Error(err),
+				zap.String("environmentNamespace", environmentNamespace),
+			)...,
+		)
+		return nil, localizedError(statusInternal, locale.JaJP)
+	}
+	for _, pf := range features {
+		prerequisites[pf.Id] = pf
+	}
+	return prerequisites, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestLintFeatureMySQL(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		desc        string
+		setup       func(*FeatureService)
+		req         *featureproto.LintFeatureRequest
+		expected    *featureproto.LintFeatureResponse
+		expectedErr error
+	}{
+		{
+			desc:        "err: missing id",
+			req:         &featureproto.LintFeatureRequest{EnvironmentNamespace: "ns0"},
+			expectedErr: errMissingIDJaJP,
+		},
+		{
+			desc: "err: not found",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(mysql.ErrNoRows)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row)
+			},
+			req:         &featureproto.LintFeatureRequest{Id: "id-0", EnvironmentNamespace: "ns0"},
+			expectedErr: errNotFoundJaJP,
+		},
+		{
+			desc: "success",
+			setup: func(s *FeatureService) {
+				row := mysqlmock.NewMockRow(mockController)
+				row.EXPECT().Scan(gomock.Any()).Return(nil).AnyTimes()
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(row).AnyTimes()
+				rows := mysqlmock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			req:         &featureproto.LintFeatureRequest{Id: "id-0", EnvironmentNamespace: "ns0"},
+			expected:    &featureproto.LintFeatureResponse{Findings: []*featureproto.LintFinding{}},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			ctx := createContextWithToken()
+			service := createFeatureService(mockController)
+			if p.setup != nil {
+				p.setup(service)
+			}
+			resp, err := service.LintFeature(ctx, p.req)
+			assert.Equal(t, p.expectedErr, err)
+			assert.Equal(t, p.expected, resp)
+		})
+	}
+}
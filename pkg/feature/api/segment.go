@@ -163,6 +163,8 @@ func (s *FeatureService) checkSegmentInUse(ctx context.Context, segmentID, envir
 			"",
 			nil,
 			nil,
+			nil,
+			0,
 			"",
 			featureproto.ListFeaturesRequest_DEFAULT,
 			featureproto.ListFeaturesRequest_ASC,
@@ -65,6 +65,12 @@ func validateCreateFeatureRequest(cmd *featureproto.CreateFeatureCommand) error
 	if int(cmd.DefaultOffVariationIndex.Value) >= variationSize {
 		return localizedError(statusInvalidDefaultOffVariation, locale.JaJP)
 	}
+	if cmd.ExpectedRemovalAt < 0 {
+		return localizedError(statusInvalidExpectedRemovalDate, locale.JaJP)
+	}
+	if cmd.ExpectedRemovalAt != 0 && (&domain.Feature{Feature: &featureproto.Feature{Kind: cmd.Kind}}).IsPermanent() {
+		return localizedError(statusRemovalDateOnPermanentFeature, locale.JaJP)
+	}
 	return nil
 }
 
@@ -495,6 +501,49 @@ func validateCloneFeatureRequest(req *featureproto.CloneFeatureRequest) error {
 	return nil
 }
 
+func validateCompareFeatureAcrossEnvironmentsRequest(req *featureproto.CompareFeatureAcrossEnvironmentsRequest) error {
+	if req.Id == "" {
+		return localizedError(statusMissingID, locale.JaJP)
+	}
+	if req.TargetEnvironmentNamespace == req.EnvironmentNamespace {
+		return localizedError(statusIncorrectDestinationEnvironment, locale.JaJP)
+	}
+	return nil
+}
+
+func validatePromoteFeatureRequest(req *featureproto.PromoteFeatureRequest) error {
+	if req.Id == "" {
+		return localizedError(statusMissingID, locale.JaJP)
+	}
+	if req.TargetEnvironmentNamespace == req.EnvironmentNamespace {
+		return localizedError(statusIncorrectDestinationEnvironment, locale.JaJP)
+	}
+	return nil
+}
+
+// validatePromotionCommand only checks the references to other features
+// because the strategies are copied from the source feature, which is already valid.
+func validatePromotionCommand(
+	fs []*featureproto.Feature,
+	tarF *featureproto.Feature,
+	cmd command.Command,
+) error {
+	switch c := cmd.(type) {
+	case *featureproto.RemoveVariationCommand:
+		return validateVariationCommand(fs, c.Id)
+	case *featureproto.AddPrerequisiteCommand:
+		return validateAddPrerequisite(fs, tarF, c.Prerequisite)
+	case *featureproto.ChangePrerequisiteVariationCommand:
+		return validateChangePrerequisiteVariation(fs, c.Prerequisite)
+	case *featureproto.SetLayerAssignmentCommand:
+		return validateSetLayerAssignment(tarF.Variations, c)
+	case *featureproto.SetExperimentAllocationCommand:
+		return validateSetExperimentAllocation(tarF.Variations, c)
+	default:
+		return nil
+	}
+}
+
 func validateFeatureTargetingCommand(
 	fs []*featureproto.Feature,
 	tarF *featureproto.Feature,
@@ -515,6 +564,12 @@ func validateFeatureTargetingCommand(
 		return validateAddPrerequisite(fs, tarF, c.Prerequisite)
 	case *featureproto.ChangePrerequisiteVariationCommand:
 		return validateChangePrerequisiteVariation(fs, c.Prerequisite)
+	case *featureproto.SetLayerAssignmentCommand:
+		return validateSetLayerAssignment(tarF.Variations, c)
+	case *featureproto.SetExperimentAllocationCommand:
+		return validateSetExperimentAllocation(tarF.Variations, c)
+	case *featureproto.UpdateBanditWeightsCommand:
+		return validateRolloutStrategy(tarF.Variations, c.RolloutStrategy)
 	default:
 		return nil
 	}
@@ -558,9 +613,29 @@ func validateStrategy(variations []*featureproto.Variation, strategy *featurepro
 	if strategy.Type == featureproto.Strategy_ROLLOUT {
 		return validateRolloutStrategy(variations, strategy.RolloutStrategy)
 	}
+	if strategy.Type == featureproto.Strategy_BANDIT {
+		return validateBanditStrategy(variations, strategy)
+	}
 	return localizedError(statusUnknownStrategy, locale.JaJP)
 }
 
+func validateBanditStrategy(variations []*featureproto.Variation, strategy *featureproto.Strategy) error {
+	if err := validateRolloutStrategy(variations, strategy.RolloutStrategy); err != nil {
+		return err
+	}
+	bs := strategy.BanditStrategy
+	if bs == nil {
+		return localizedError(statusMissingBanditStrategy, locale.JaJP)
+	}
+	if bs.GoalId == "" || bs.UpdateInterval < 0 || bs.MinExplorationWeight < 0 {
+		return localizedError(statusInvalidBanditStrategy, locale.JaJP)
+	}
+	if int64(bs.MinExplorationWeight)*int64(len(variations)) > int64(totalVariationWeight) {
+		return localizedError(statusInvalidBanditStrategy, locale.JaJP)
+	}
+	return nil
+}
+
 func validateChangeFixedStrategy(cmd *featureproto.ChangeFixedStrategyCommand) error {
 	if cmd.RuleId == "" {
 		return localizedError(statusMissingRuleID, locale.JaJP)
@@ -638,6 +713,52 @@ func validateAddPrerequisite(
 	return nil
 }
 
+func validateSetLayerAssignment(
+	variations []*featureproto.Variation,
+	cmd *featureproto.SetLayerAssignmentCommand,
+) error {
+	la := cmd.LayerAssignment
+	if la == nil || la.LayerId == "" || la.ExperimentId == "" {
+		return localizedError(statusInvalidLayerAssignment, locale.JaJP)
+	}
+	if la.HoldoutWeight < 0 || la.SliceStart < la.HoldoutWeight || la.SliceEnd <= la.SliceStart {
+		return localizedError(statusInvalidLayerAssignment, locale.JaJP)
+	}
+	if la.SliceEnd > totalVariationWeight {
+		return localizedError(statusInvalidLayerAssignment, locale.JaJP)
+	}
+	for _, v := range variations {
+		if v.Id == la.BaselineVariationId {
+			return nil
+		}
+	}
+	return localizedError(statusInvalidLayerAssignment, locale.JaJP)
+}
+
+func validateSetExperimentAllocation(
+	variations []*featureproto.Variation,
+	cmd *featureproto.SetExperimentAllocationCommand,
+) error {
+	ea := cmd.ExperimentAllocation
+	if ea == nil || ea.ExperimentId == "" {
+		return localizedError(statusInvalidExperimentAllocation, locale.JaJP)
+	}
+	if ea.TrafficAllocation < 0 || ea.TrafficAllocation > totalVariationWeight {
+		return localizedError(statusInvalidExperimentAllocation, locale.JaJP)
+	}
+	for _, c := range ea.Audience {
+		if c.Attribute == "" || len(c.Values) == 0 {
+			return localizedError(statusInvalidExperimentAllocation, locale.JaJP)
+		}
+	}
+	for _, v := range variations {
+		if v.Id == ea.ControlVariationId {
+			return nil
+		}
+	}
+	return localizedError(statusInvalidExperimentAllocation, locale.JaJP)
+}
+
 func validateChangePrerequisiteVariation(fs []*featureproto.Feature, p *featureproto.Prerequisite) error {
 	if err := validateVariationID(fs, p); err != nil {
 		return err
@@ -657,3 +778,51 @@ func validateVariationID(fs []*featureproto.Feature, p *featureproto.Prerequisit
 	}
 	return localizedError(statusInvalidVariationID, locale.JaJP)
 }
+
+func validateUpdateFeatureLifecyclePolicyRequest(req *featureproto.UpdateFeatureLifecyclePolicyRequest) error {
+	if req.Policy == nil {
+		return localizedError(statusMissingLifecyclePolicy, locale.JaJP)
+	}
+	p := req.Policy
+	if p.NotEvaluatedDays < 0 || p.GracePeriodDays < 0 || p.UndoPeriodDays < 0 {
+		return localizedError(statusInvalidLifecyclePolicy, locale.JaJP)
+	}
+	return nil
+}
+
+func validateUndoFeatureAutoArchiveRequest(req *featureproto.UndoFeatureAutoArchiveRequest) error {
+	if req.Id == "" {
+		return localizedError(statusMissingID, locale.JaJP)
+	}
+	return nil
+}
+
+func validateBulkToggleByTagRequest(req *featureproto.BulkToggleByTagRequest) error {
+	if req.IncidentId == "" {
+		return localizedError(statusMissingIncidentID, locale.JaJP)
+	}
+	if req.Tag == "" {
+		return localizedError(statusMissingFeatureTag, locale.JaJP)
+	}
+	if _, ok := featureproto.FeatureIncident_Action_name[int32(req.Action)]; !ok {
+		return localizedError(statusInvalidIncidentAction, locale.JaJP)
+	}
+	return nil
+}
+
+func validateRestoreBulkToggleRequest(req *featureproto.RestoreBulkToggleRequest) error {
+	if req.IncidentId == "" {
+		return localizedError(statusMissingIncidentID, locale.JaJP)
+	}
+	return nil
+}
+
+func validateUpdateFeatureDetailsRequest(req *featureproto.UpdateFeatureDetailsRequest) error {
+	if req.Id == "" {
+		return localizedError(statusMissingID, locale.JaJP)
+	}
+	if req.ChangeExpectedRemovalDateCommand != nil && req.ChangeExpectedRemovalDateCommand.ExpectedRemovalAt < 0 {
+		return localizedError(statusInvalidExpectedRemovalDate, locale.JaJP)
+	}
+	return nil
+}
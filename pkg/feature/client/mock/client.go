@@ -57,6 +57,26 @@ func (mr *MockClientMockRecorder) AddSegmentUser(ctx, in interface{}, opts ...in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSegmentUser", reflect.TypeOf((*MockClient)(nil).AddSegmentUser), varargs...)
 }
 
+// ApplyFeatureLifecyclePolicy mocks base method.
+func (m *MockClient) ApplyFeatureLifecyclePolicy(ctx context.Context, in *feature.ApplyFeatureLifecyclePolicyRequest, opts ...grpc.CallOption) (*feature.ApplyFeatureLifecyclePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ApplyFeatureLifecyclePolicy", varargs...)
+	ret0, _ := ret[0].(*feature.ApplyFeatureLifecyclePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyFeatureLifecyclePolicy indicates an expected call of ApplyFeatureLifecyclePolicy.
+func (mr *MockClientMockRecorder) ApplyFeatureLifecyclePolicy(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyFeatureLifecyclePolicy", reflect.TypeOf((*MockClient)(nil).ApplyFeatureLifecyclePolicy), varargs...)
+}
+
 // ArchiveFeature mocks base method.
 func (m *MockClient) ArchiveFeature(ctx context.Context, in *feature.ArchiveFeatureRequest, opts ...grpc.CallOption) (*feature.ArchiveFeatureResponse, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +117,26 @@ func (mr *MockClientMockRecorder) BulkDownloadSegmentUsers(ctx, in interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDownloadSegmentUsers", reflect.TypeOf((*MockClient)(nil).BulkDownloadSegmentUsers), varargs...)
 }
 
+// BulkToggleByTag mocks base method.
+func (m *MockClient) BulkToggleByTag(ctx context.Context, in *feature.BulkToggleByTagRequest, opts ...grpc.CallOption) (*feature.BulkToggleByTagResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BulkToggleByTag", varargs...)
+	ret0, _ := ret[0].(*feature.BulkToggleByTagResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkToggleByTag indicates an expected call of BulkToggleByTag.
+func (mr *MockClientMockRecorder) BulkToggleByTag(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkToggleByTag", reflect.TypeOf((*MockClient)(nil).BulkToggleByTag), varargs...)
+}
+
 // BulkUploadSegmentUsers mocks base method.
 func (m *MockClient) BulkUploadSegmentUsers(ctx context.Context, in *feature.BulkUploadSegmentUsersRequest, opts ...grpc.CallOption) (*feature.BulkUploadSegmentUsersResponse, error) {
 	m.ctrl.T.Helper()
@@ -149,6 +189,26 @@ func (mr *MockClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
 }
 
+// CompareFeatureAcrossEnvironments mocks base method.
+func (m *MockClient) CompareFeatureAcrossEnvironments(ctx context.Context, in *feature.CompareFeatureAcrossEnvironmentsRequest, opts ...grpc.CallOption) (*feature.CompareFeatureAcrossEnvironmentsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CompareFeatureAcrossEnvironments", varargs...)
+	ret0, _ := ret[0].(*feature.CompareFeatureAcrossEnvironmentsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareFeatureAcrossEnvironments indicates an expected call of CompareFeatureAcrossEnvironments.
+func (mr *MockClientMockRecorder) CompareFeatureAcrossEnvironments(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareFeatureAcrossEnvironments", reflect.TypeOf((*MockClient)(nil).CompareFeatureAcrossEnvironments), varargs...)
+}
+
 // CreateFeature mocks base method.
 func (m *MockClient) CreateFeature(ctx context.Context, in *feature.CreateFeatureRequest, opts ...grpc.CallOption) (*feature.CreateFeatureResponse, error) {
 	m.ctrl.T.Helper()
@@ -329,6 +389,26 @@ func (mr *MockClientMockRecorder) GetFeature(ctx, in interface{}, opts ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeature", reflect.TypeOf((*MockClient)(nil).GetFeature), varargs...)
 }
 
+// GetFeatureLifecyclePolicy mocks base method.
+func (m *MockClient) GetFeatureLifecyclePolicy(ctx context.Context, in *feature.GetFeatureLifecyclePolicyRequest, opts ...grpc.CallOption) (*feature.GetFeatureLifecyclePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFeatureLifecyclePolicy", varargs...)
+	ret0, _ := ret[0].(*feature.GetFeatureLifecyclePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureLifecyclePolicy indicates an expected call of GetFeatureLifecyclePolicy.
+func (mr *MockClientMockRecorder) GetFeatureLifecyclePolicy(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureLifecyclePolicy", reflect.TypeOf((*MockClient)(nil).GetFeatureLifecyclePolicy), varargs...)
+}
+
 // GetFeatures mocks base method.
 func (m *MockClient) GetFeatures(ctx context.Context, in *feature.GetFeaturesRequest, opts ...grpc.CallOption) (*feature.GetFeaturesResponse, error) {
 	m.ctrl.T.Helper()
@@ -409,6 +489,26 @@ func (mr *MockClientMockRecorder) GetUserEvaluations(ctx, in interface{}, opts .
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvaluations", reflect.TypeOf((*MockClient)(nil).GetUserEvaluations), varargs...)
 }
 
+// LintFeature mocks base method.
+func (m *MockClient) LintFeature(ctx context.Context, in *feature.LintFeatureRequest, opts ...grpc.CallOption) (*feature.LintFeatureResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LintFeature", varargs...)
+	ret0, _ := ret[0].(*feature.LintFeatureResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LintFeature indicates an expected call of LintFeature.
+func (mr *MockClientMockRecorder) LintFeature(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LintFeature", reflect.TypeOf((*MockClient)(nil).LintFeature), varargs...)
+}
+
 // ListEnabledFeatures mocks base method.
 func (m *MockClient) ListEnabledFeatures(ctx context.Context, in *feature.ListEnabledFeaturesRequest, opts ...grpc.CallOption) (*feature.ListEnabledFeaturesResponse, error) {
 	m.ctrl.T.Helper()
@@ -429,6 +529,26 @@ func (mr *MockClientMockRecorder) ListEnabledFeatures(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnabledFeatures", reflect.TypeOf((*MockClient)(nil).ListEnabledFeatures), varargs...)
 }
 
+// ListFeatureCleanupCandidates mocks base method.
+func (m *MockClient) ListFeatureCleanupCandidates(ctx context.Context, in *feature.ListFeatureCleanupCandidatesRequest, opts ...grpc.CallOption) (*feature.ListFeatureCleanupCandidatesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListFeatureCleanupCandidates", varargs...)
+	ret0, _ := ret[0].(*feature.ListFeatureCleanupCandidatesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeatureCleanupCandidates indicates an expected call of ListFeatureCleanupCandidates.
+func (mr *MockClientMockRecorder) ListFeatureCleanupCandidates(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeatureCleanupCandidates", reflect.TypeOf((*MockClient)(nil).ListFeatureCleanupCandidates), varargs...)
+}
+
 // ListFeatures mocks base method.
 func (m *MockClient) ListFeatures(ctx context.Context, in *feature.ListFeaturesRequest, opts ...grpc.CallOption) (*feature.ListFeaturesResponse, error) {
 	m.ctrl.T.Helper()
@@ -509,6 +629,46 @@ func (mr *MockClientMockRecorder) ListTags(ctx, in interface{}, opts ...interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockClient)(nil).ListTags), varargs...)
 }
 
+// PromoteFeature mocks base method.
+func (m *MockClient) PromoteFeature(ctx context.Context, in *feature.PromoteFeatureRequest, opts ...grpc.CallOption) (*feature.PromoteFeatureResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PromoteFeature", varargs...)
+	ret0, _ := ret[0].(*feature.PromoteFeatureResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteFeature indicates an expected call of PromoteFeature.
+func (mr *MockClientMockRecorder) PromoteFeature(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteFeature", reflect.TypeOf((*MockClient)(nil).PromoteFeature), varargs...)
+}
+
+// RestoreBulkToggle mocks base method.
+func (m *MockClient) RestoreBulkToggle(ctx context.Context, in *feature.RestoreBulkToggleRequest, opts ...grpc.CallOption) (*feature.RestoreBulkToggleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreBulkToggle", varargs...)
+	ret0, _ := ret[0].(*feature.RestoreBulkToggleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreBulkToggle indicates an expected call of RestoreBulkToggle.
+func (mr *MockClientMockRecorder) RestoreBulkToggle(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBulkToggle", reflect.TypeOf((*MockClient)(nil).RestoreBulkToggle), varargs...)
+}
+
 // UnarchiveFeature mocks base method.
 func (m *MockClient) UnarchiveFeature(ctx context.Context, in *feature.UnarchiveFeatureRequest, opts ...grpc.CallOption) (*feature.UnarchiveFeatureResponse, error) {
 	m.ctrl.T.Helper()
@@ -529,6 +689,26 @@ func (mr *MockClientMockRecorder) UnarchiveFeature(ctx, in interface{}, opts ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnarchiveFeature", reflect.TypeOf((*MockClient)(nil).UnarchiveFeature), varargs...)
 }
 
+// UndoFeatureAutoArchive mocks base method.
+func (m *MockClient) UndoFeatureAutoArchive(ctx context.Context, in *feature.UndoFeatureAutoArchiveRequest, opts ...grpc.CallOption) (*feature.UndoFeatureAutoArchiveResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UndoFeatureAutoArchive", varargs...)
+	ret0, _ := ret[0].(*feature.UndoFeatureAutoArchiveResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UndoFeatureAutoArchive indicates an expected call of UndoFeatureAutoArchive.
+func (mr *MockClientMockRecorder) UndoFeatureAutoArchive(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndoFeatureAutoArchive", reflect.TypeOf((*MockClient)(nil).UndoFeatureAutoArchive), varargs...)
+}
+
 // UpdateFeatureDetails mocks base method.
 func (m *MockClient) UpdateFeatureDetails(ctx context.Context, in *feature.UpdateFeatureDetailsRequest, opts ...grpc.CallOption) (*feature.UpdateFeatureDetailsResponse, error) {
 	m.ctrl.T.Helper()
@@ -549,6 +729,26 @@ func (mr *MockClientMockRecorder) UpdateFeatureDetails(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFeatureDetails", reflect.TypeOf((*MockClient)(nil).UpdateFeatureDetails), varargs...)
 }
 
+// UpdateFeatureLifecyclePolicy mocks base method.
+func (m *MockClient) UpdateFeatureLifecyclePolicy(ctx context.Context, in *feature.UpdateFeatureLifecyclePolicyRequest, opts ...grpc.CallOption) (*feature.UpdateFeatureLifecyclePolicyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateFeatureLifecyclePolicy", varargs...)
+	ret0, _ := ret[0].(*feature.UpdateFeatureLifecyclePolicyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFeatureLifecyclePolicy indicates an expected call of UpdateFeatureLifecyclePolicy.
+func (mr *MockClientMockRecorder) UpdateFeatureLifecyclePolicy(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFeatureLifecyclePolicy", reflect.TypeOf((*MockClient)(nil).UpdateFeatureLifecyclePolicy), varargs...)
+}
+
 // UpdateFeatureTargeting mocks base method.
 func (m *MockClient) UpdateFeatureTargeting(ctx context.Context, in *feature.UpdateFeatureTargetingRequest, opts ...grpc.CallOption) (*feature.UpdateFeatureTargetingResponse, error) {
 	m.ctrl.T.Helper()
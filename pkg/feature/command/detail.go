@@ -56,6 +56,53 @@ func (h *FeatureCommandHandler) ChangeDescription(ctx context.Context, cmd *prot
 	return nil
 }
 
+func (h *FeatureCommandHandler) ChangeFeatureKind(ctx context.Context, cmd *proto.ChangeFeatureKindCommand) error {
+	expectedRemovalAt := h.feature.ExpectedRemovalAt
+	err := h.feature.ChangeKind(cmd.Kind)
+	if err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(eventproto.Event_FEATURE_KIND_CHANGED, &eventproto.FeatureKindChangedEvent{
+		Id:   h.feature.Id,
+		Kind: cmd.Kind,
+	})
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	// Permanent features have no removal date.
+	if h.feature.ExpectedRemovalAt != expectedRemovalAt {
+		return h.appendExpectedRemovalDateChangedEvent()
+	}
+	return nil
+}
+
+func (h *FeatureCommandHandler) ChangeExpectedRemovalDate(
+	ctx context.Context,
+	cmd *proto.ChangeExpectedRemovalDateCommand,
+) error {
+	err := h.feature.ChangeExpectedRemovalDate(cmd.ExpectedRemovalAt)
+	if err != nil {
+		return err
+	}
+	return h.appendExpectedRemovalDateChangedEvent()
+}
+
+func (h *FeatureCommandHandler) appendExpectedRemovalDateChangedEvent() error {
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_EXPECTED_REMOVAL_DATE_CHANGED,
+		&eventproto.FeatureExpectedRemovalDateChangedEvent{
+			Id:                h.feature.Id,
+			ExpectedRemovalAt: h.feature.ExpectedRemovalAt,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
 func (h *FeatureCommandHandler) AddTag(ctx context.Context, cmd *proto.AddTagCommand) error {
 	err := h.feature.AddTag(cmd.Tag)
 	if err != nil {
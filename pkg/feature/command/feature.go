@@ -111,6 +111,10 @@ func (h *FeatureCommandHandler) Handle(ctx context.Context, cmd Command) error {
 		return h.RenameFeature(ctx, c)
 	case *proto.ChangeDescriptionCommand:
 		return h.ChangeDescription(ctx, c)
+	case *proto.ChangeFeatureKindCommand:
+		return h.ChangeFeatureKind(ctx, c)
+	case *proto.ChangeExpectedRemovalDateCommand:
+		return h.ChangeExpectedRemovalDate(ctx, c)
 	case *proto.AddTagCommand:
 		return h.AddTag(ctx, c)
 	case *proto.RemoveTagCommand:
@@ -121,6 +125,16 @@ func (h *FeatureCommandHandler) Handle(ctx context.Context, cmd Command) error {
 		return h.CloneFeature(ctx, c)
 	case *proto.ResetSamplingSeedCommand:
 		return h.ResetSamplingSeed(ctx, c)
+	case *proto.SetLayerAssignmentCommand:
+		return h.SetLayerAssignment(ctx, c)
+	case *proto.RemoveLayerAssignmentCommand:
+		return h.RemoveLayerAssignment(ctx, c)
+	case *proto.UpdateBanditWeightsCommand:
+		return h.UpdateBanditWeights(ctx, c)
+	case *proto.SetExperimentAllocationCommand:
+		return h.SetExperimentAllocation(ctx, c)
+	case *proto.RemoveExperimentAllocationCommand:
+		return h.RemoveExperimentAllocation(ctx, c)
 	case *proto.AddPrerequisiteCommand:
 		return h.AddPrerequisite(ctx, c)
 	case *proto.ChangePrerequisiteVariationCommand:
@@ -142,6 +156,8 @@ func (h *FeatureCommandHandler) CreateFeature(ctx context.Context, cmd *proto.Cr
 		DefaultOnVariationIndex:  cmd.DefaultOnVariationIndex,
 		DefaultOffVariationIndex: cmd.DefaultOffVariationIndex,
 		VariationType:            cmd.VariationType,
+		Kind:                     cmd.Kind,
+		ExpectedRemovalAt:        cmd.ExpectedRemovalAt,
 	})
 	if err != nil {
 		return err
@@ -280,6 +296,128 @@ func (h *FeatureCommandHandler) ResetSamplingSeed(ctx context.Context, cmd *prot
 	return nil
 }
 
+func (h *FeatureCommandHandler) SetLayerAssignment(ctx context.Context, cmd *proto.SetLayerAssignmentCommand) error {
+	if err := h.feature.SetLayerAssignment(cmd.LayerAssignment); err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_LAYER_ASSIGNMENT_SET,
+		&eventproto.FeatureLayerAssignmentSetEvent{
+			Id:              h.feature.Id,
+			LayerAssignment: cmd.LayerAssignment,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *FeatureCommandHandler) RemoveLayerAssignment(
+	ctx context.Context,
+	cmd *proto.RemoveLayerAssignmentCommand,
+) error {
+	if err := h.feature.RemoveLayerAssignment(cmd.ExperimentId); err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_LAYER_ASSIGNMENT_REMOVED,
+		&eventproto.FeatureLayerAssignmentRemovedEvent{
+			Id:           h.feature.Id,
+			ExperimentId: cmd.ExperimentId,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *FeatureCommandHandler) UpdateBanditWeights(
+	ctx context.Context,
+	cmd *proto.UpdateBanditWeightsCommand,
+) error {
+	var previous *proto.RolloutStrategy
+	if s := h.findStrategy(cmd.RuleId); s != nil {
+		previous = s.RolloutStrategy
+	}
+	err := h.feature.UpdateBanditWeights(cmd.RuleId, cmd.RolloutStrategy, cmd.Arms, cmd.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_BANDIT_WEIGHTS_UPDATED,
+		&eventproto.FeatureBanditWeightsUpdatedEvent{
+			Id:                      h.feature.Id,
+			RuleId:                  cmd.RuleId,
+			PreviousRolloutStrategy: previous,
+			RolloutStrategy:         cmd.RolloutStrategy,
+			Arms:                    cmd.Arms,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *FeatureCommandHandler) findStrategy(ruleID string) *proto.Strategy {
+	if ruleID == "" {
+		return h.feature.DefaultStrategy
+	}
+	for _, r := range h.feature.Rules {
+		if r.Id == ruleID {
+			return r.Strategy
+		}
+	}
+	return nil
+}
+
+func (h *FeatureCommandHandler) SetExperimentAllocation(
+	ctx context.Context,
+	cmd *proto.SetExperimentAllocationCommand,
+) error {
+	if err := h.feature.SetExperimentAllocation(cmd.ExperimentAllocation); err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_EXPERIMENT_ALLOCATION_SET,
+		&eventproto.FeatureExperimentAllocationSetEvent{
+			Id:                   h.feature.Id,
+			ExperimentAllocation: cmd.ExperimentAllocation,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *FeatureCommandHandler) RemoveExperimentAllocation(
+	ctx context.Context,
+	cmd *proto.RemoveExperimentAllocationCommand,
+) error {
+	if err := h.feature.RemoveExperimentAllocation(cmd.ExperimentId); err != nil {
+		return err
+	}
+	event, err := h.eventFactory.CreateEvent(
+		eventproto.Event_FEATURE_EXPERIMENT_ALLOCATION_REMOVED,
+		&eventproto.FeatureExperimentAllocationRemovedEvent{
+			Id:           h.feature.Id,
+			ExperimentId: cmd.ExperimentId,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Events = append(h.Events, event)
+	return nil
+}
+
 func (h *FeatureCommandHandler) AddPrerequisite(ctx context.Context, cmd *proto.AddPrerequisiteCommand) error {
 	if err := h.feature.AddPrerequisite(cmd.Prerequisite.FeatureId, cmd.Prerequisite.VariationId); err != nil {
 		return err
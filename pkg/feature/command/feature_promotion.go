@@ -0,0 +1,284 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// The promotion commands are generated in three steps because the IDs of the added variations
+// are only known once the commands adding them have been handled:
+//  1. PromotionVariationCommands adds the missing variations and updates the existing ones.
+//  2. PromotionTargetingCommands syncs the targets, rules, strategies and prerequisites.
+//  3. PromotionRemoveVariationCommands removes the variations that are no longer used.
+// Each step must be generated after the commands of the previous one have been handled.
+
+func PromotionVariationCommands(p *domain.FeaturePromotion) []Command {
+	commands := []Command{}
+	target := p.Target()
+	for _, sv := range p.Source().Variations {
+		tv := findVariationByValue(sv.Value, target.Variations)
+		if tv == nil {
+			commands = append(commands, &featureproto.AddVariationCommand{
+				Value:       sv.Value,
+				Name:        sv.Name,
+				Description: sv.Description,
+			})
+			continue
+		}
+		if sv.Name != tv.Name {
+			commands = append(commands, &featureproto.ChangeVariationNameCommand{
+				Id:   tv.Id,
+				Name: sv.Name,
+			})
+		}
+		if sv.Description != tv.Description {
+			commands = append(commands, &featureproto.ChangeVariationDescriptionCommand{
+				Id:          tv.Id,
+				Description: sv.Description,
+			})
+		}
+	}
+	return commands
+}
+
+func PromotionTargetingCommands(p *domain.FeaturePromotion) ([]Command, error) {
+	source, err := p.TranslatedSource()
+	if err != nil {
+		return nil, err
+	}
+	target := p.Target()
+	commands := []Command{}
+	commands = append(commands, promotionTargetCommands(source, target.Feature)...)
+	commands = append(commands, promotionRuleCommands(source, target.Feature)...)
+	if !proto.Equal(source.DefaultStrategy, target.DefaultStrategy) {
+		commands = append(commands, &featureproto.ChangeDefaultStrategyCommand{
+			Strategy: source.DefaultStrategy,
+		})
+	}
+	if source.OffVariation != target.OffVariation {
+		commands = append(commands, &featureproto.ChangeOffVariationCommand{
+			Id: source.OffVariation,
+		})
+	}
+	commands = append(commands, promotionPrerequisiteCommands(source, target.Feature)...)
+	return commands, nil
+}
+
+func PromotionRemoveVariationCommands(p *domain.FeaturePromotion) []Command {
+	commands := []Command{}
+	for _, tv := range p.Target().Variations {
+		if findVariationByValue(tv.Value, p.Source().Variations) == nil {
+			commands = append(commands, &featureproto.RemoveVariationCommand{
+				Id: tv.Id,
+			})
+		}
+	}
+	return commands
+}
+
+func promotionTargetCommands(source, target *featureproto.Feature) []Command {
+	commands := []Command{}
+	for _, st := range source.Targets {
+		tarUsers := []string{}
+		for _, tt := range target.Targets {
+			if tt.Variation == st.Variation {
+				tarUsers = tt.Users
+				break
+			}
+		}
+		for _, u := range tarUsers {
+			if !containsString(u, st.Users) {
+				commands = append(commands, &featureproto.RemoveUserFromVariationCommand{
+					Id:   st.Variation,
+					User: u,
+				})
+			}
+		}
+		for _, u := range st.Users {
+			if !containsString(u, tarUsers) {
+				commands = append(commands, &featureproto.AddUserToVariationCommand{
+					Id:   st.Variation,
+					User: u,
+				})
+			}
+		}
+	}
+	return commands
+}
+
+// promotionRuleCommands updates the rules in place when possible.
+// When the order of the rules differs, the rules from the first mismatch are deleted and added again
+// because there is no command to move a rule.
+func promotionRuleCommands(source, target *featureproto.Feature) []Command {
+	kept := []string{}
+	for _, tr := range target.Rules {
+		if findRule(tr.Id, source.Rules) != nil {
+			kept = append(kept, tr.Id)
+		}
+	}
+	planned := append([]string{}, kept...)
+	for _, sr := range source.Rules {
+		if findRule(sr.Id, target.Rules) == nil {
+			planned = append(planned, sr.Id)
+		}
+	}
+	recreated := map[string]bool{}
+	for i, sr := range source.Rules {
+		if planned[i] != sr.Id {
+			for _, r := range source.Rules[i:] {
+				recreated[r.Id] = true
+			}
+			break
+		}
+	}
+	commands := []Command{}
+	for _, tr := range target.Rules {
+		if findRule(tr.Id, source.Rules) == nil || recreated[tr.Id] {
+			commands = append(commands, &featureproto.DeleteRuleCommand{Id: tr.Id})
+		}
+	}
+	for _, sr := range source.Rules {
+		tr := findRule(sr.Id, target.Rules)
+		if tr == nil || recreated[sr.Id] {
+			commands = append(commands, &featureproto.AddRuleCommand{Rule: sr})
+			continue
+		}
+		commands = append(commands, promotionClauseCommands(sr, tr)...)
+		if !proto.Equal(sr.Strategy, tr.Strategy) {
+			commands = append(commands, &featureproto.ChangeRuleStrategyCommand{
+				RuleId:   sr.Id,
+				Strategy: sr.Strategy,
+			})
+		}
+	}
+	return commands
+}
+
+// promotionClauseCommands matches the clauses by position
+// because their IDs are regenerated every time a rule or a clause is added.
+func promotionClauseCommands(source, target *featureproto.Rule) []Command {
+	commands := []Command{}
+	for i, tc := range target.Clauses {
+		if i >= len(source.Clauses) {
+			commands = append(commands, &featureproto.DeleteClauseCommand{
+				Id:     tc.Id,
+				RuleId: target.Id,
+			})
+			continue
+		}
+		sc := source.Clauses[i]
+		if sc.Attribute != tc.Attribute {
+			commands = append(commands, &featureproto.ChangeClauseAttributeCommand{
+				Id:        tc.Id,
+				RuleId:    target.Id,
+				Attribute: sc.Attribute,
+			})
+		}
+		if sc.Operator != tc.Operator {
+			commands = append(commands, &featureproto.ChangeClauseOperatorCommand{
+				Id:       tc.Id,
+				RuleId:   target.Id,
+				Operator: sc.Operator,
+			})
+		}
+		for _, v := range sc.Values {
+			if !containsString(v, tc.Values) {
+				commands = append(commands, &featureproto.AddClauseValueCommand{
+					Id:     tc.Id,
+					RuleId: target.Id,
+					Value:  v,
+				})
+			}
+		}
+		for _, v := range tc.Values {
+			if !containsString(v, sc.Values) {
+				commands = append(commands, &featureproto.RemoveClauseValueCommand{
+					Id:     tc.Id,
+					RuleId: target.Id,
+					Value:  v,
+				})
+			}
+		}
+	}
+	for i := len(target.Clauses); i < len(source.Clauses); i++ {
+		commands = append(commands, &featureproto.AddClauseCommand{
+			RuleId: source.Id,
+			Clause: source.Clauses[i],
+		})
+	}
+	return commands
+}
+
+func promotionPrerequisiteCommands(source, target *featureproto.Feature) []Command {
+	commands := []Command{}
+	for _, tp := range target.Prerequisites {
+		if findPrerequisite(tp.FeatureId, source.Prerequisites) == nil {
+			commands = append(commands, &featureproto.RemovePrerequisiteCommand{
+				FeatureId: tp.FeatureId,
+			})
+		}
+	}
+	for _, sp := range source.Prerequisites {
+		tp := findPrerequisite(sp.FeatureId, target.Prerequisites)
+		if tp == nil {
+			commands = append(commands, &featureproto.AddPrerequisiteCommand{Prerequisite: sp})
+			continue
+		}
+		if tp.VariationId != sp.VariationId {
+			commands = append(commands, &featureproto.ChangePrerequisiteVariationCommand{Prerequisite: sp})
+		}
+	}
+	return commands
+}
+
+func findVariationByValue(value string, vs []*featureproto.Variation) *featureproto.Variation {
+	for _, v := range vs {
+		if v.Value == value {
+			return v
+		}
+	}
+	return nil
+}
+
+func findRule(id string, rules []*featureproto.Rule) *featureproto.Rule {
+	for _, r := range rules {
+		if r.Id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func findPrerequisite(featureID string, ps []*featureproto.Prerequisite) *featureproto.Prerequisite {
+	for _, p := range ps {
+		if p.FeatureId == featureID {
+			return p
+		}
+	}
+	return nil
+}
+
+func containsString(needle string, haystack []string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,365 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"testing"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// makePromotionTarget returns the feature of makeFeature as it is in another environment,
+// where the variation IDs are different.
+func makePromotionTarget() *domain.Feature {
+	f := makeFeature("feature-id")
+	for _, v := range f.Variations {
+		v.Id = "target-" + v.Id
+	}
+	for _, tg := range f.Targets {
+		tg.Variation = "target-" + tg.Variation
+	}
+	for _, r := range f.Rules {
+		r.Strategy.FixedStrategy.Variation = "target-" + r.Strategy.FixedStrategy.Variation
+	}
+	f.DefaultStrategy.FixedStrategy.Variation = "target-" + f.DefaultStrategy.FixedStrategy.Variation
+	return f
+}
+
+func makePromotionRule(id, variation string) *proto.Rule {
+	return &proto.Rule{
+		Id: id,
+		Strategy: &proto.Strategy{
+			Type:          proto.Strategy_FIXED,
+			FixedStrategy: &proto.FixedStrategy{Variation: variation},
+		},
+		Clauses: []*proto.Clause{
+			{
+				Id:        id + "-clause",
+				Attribute: "email",
+				Operator:  proto.Clause_STARTS_WITH,
+				Values:    []string{id},
+			},
+		},
+	}
+}
+
+// assertCommands compares the commands with proto.Equal,
+// since the commands built from cloned messages keep their internal state.
+func assertCommands(t *testing.T, expected, actual []Command) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.True(t, pb.Equal(expected[i].(pb.Message), actual[i].(pb.Message)), "command %d: %v", i, actual[i])
+	}
+}
+
+func fixedStrategy(variation string) *proto.Strategy {
+	return &proto.Strategy{
+		Type:          proto.Strategy_FIXED,
+		FixedStrategy: &proto.FixedStrategy{Variation: variation},
+	}
+}
+
+func TestPromotionVariationCommands(t *testing.T) {
+	t.Parallel()
+	source := makeFeature("feature-id")
+	source.Variations[0].Name = "changed name"
+	source.Variations[1].Description = "changed description"
+	source.Variations = append(source.Variations, &proto.Variation{
+		Id:          "variation-C",
+		Value:       "C",
+		Name:        "Variation C",
+		Description: "Thing does C",
+	})
+	target := makePromotionTarget()
+	p := domain.NewFeaturePromotion(source.Feature, target, map[string]string{}, map[string]string{})
+	expected := []Command{
+		&proto.ChangeVariationNameCommand{Id: "target-variation-A", Name: "changed name"},
+		&proto.ChangeVariationDescriptionCommand{Id: "target-variation-B", Description: "changed description"},
+		&proto.AddVariationCommand{Value: "C", Name: "Variation C", Description: "Thing does C"},
+	}
+	assertCommands(t, expected, PromotionVariationCommands(p))
+}
+
+func TestPromotionRemoveVariationCommands(t *testing.T) {
+	t.Parallel()
+	source := makeFeature("feature-id")
+	source.Variations = source.Variations[:1]
+	target := makePromotionTarget()
+	p := domain.NewFeaturePromotion(source.Feature, target, map[string]string{}, map[string]string{})
+	expected := []Command{&proto.RemoveVariationCommand{Id: "target-variation-B"}}
+	assertCommands(t, expected, PromotionRemoveVariationCommands(p))
+}
+
+func TestPromotionTargetingCommands(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		desc                     string
+		source                   func(f *proto.Feature)
+		target                   func(f *proto.Feature)
+		segmentIDs               map[string]string
+		prerequisiteVariationIDs map[string]string
+		expected                 []Command
+		expectedErr              error
+	}{
+		{
+			desc: "no changes: clause IDs are ignored",
+			target: func(f *proto.Feature) {
+				f.Rules[0].Clauses[0].Id = "regenerated-clause-id"
+			},
+			expected: []Command{},
+		},
+		{
+			desc: "variations mapped by value",
+			source: func(f *proto.Feature) {
+				f.DefaultStrategy = fixedStrategy("variation-A")
+				f.OffVariation = "variation-B"
+			},
+			expected: []Command{
+				&proto.ChangeDefaultStrategyCommand{Strategy: fixedStrategy("target-variation-A")},
+				&proto.ChangeOffVariationCommand{Id: "target-variation-B"},
+			},
+		},
+		{
+			desc: "target users changed",
+			source: func(f *proto.Feature) {
+				f.Targets[0].Users = []string{"user2"}
+			},
+			expected: []Command{
+				&proto.RemoveUserFromVariationCommand{Id: "target-variation-B", User: "user1"},
+				&proto.AddUserToVariationCommand{Id: "target-variation-B", User: "user2"},
+			},
+		},
+		{
+			desc: "rule added",
+			source: func(f *proto.Feature) {
+				f.Rules = append(f.Rules, makePromotionRule("rule-2", "variation-B"))
+			},
+			expected: []Command{
+				&proto.AddRuleCommand{Rule: makePromotionRule("rule-2", "target-variation-B")},
+			},
+		},
+		{
+			desc: "rule removed",
+			target: func(f *proto.Feature) {
+				f.Rules = append(f.Rules, makePromotionRule("rule-2", "target-variation-B"))
+			},
+			expected: []Command{
+				&proto.DeleteRuleCommand{Id: "rule-2"},
+			},
+		},
+		{
+			desc: "rules reordered: the rules from the first mismatch are added again",
+			source: func(f *proto.Feature) {
+				f.Rules = []*proto.Rule{
+					f.Rules[0],
+					makePromotionRule("rule-3", "variation-A"),
+					makePromotionRule("rule-2", "variation-B"),
+				}
+			},
+			target: func(f *proto.Feature) {
+				f.Rules = append(
+					f.Rules,
+					makePromotionRule("rule-2", "target-variation-B"),
+					makePromotionRule("rule-3", "target-variation-A"),
+				)
+			},
+			expected: []Command{
+				&proto.DeleteRuleCommand{Id: "rule-2"},
+				&proto.DeleteRuleCommand{Id: "rule-3"},
+				&proto.AddRuleCommand{Rule: makePromotionRule("rule-3", "target-variation-A")},
+				&proto.AddRuleCommand{Rule: makePromotionRule("rule-2", "target-variation-B")},
+			},
+		},
+		{
+			desc: "rule strategy changed",
+			source: func(f *proto.Feature) {
+				f.Rules[0].Strategy = fixedStrategy("variation-B")
+			},
+			expected: []Command{
+				&proto.ChangeRuleStrategyCommand{RuleId: "rule-1", Strategy: fixedStrategy("target-variation-B")},
+			},
+		},
+		{
+			desc: "clause changed",
+			source: func(f *proto.Feature) {
+				f.Rules[0].Clauses[0].Attribute = "email"
+				f.Rules[0].Clauses[0].Operator = proto.Clause_IN
+				f.Rules[0].Clauses[0].Values = []string{"user2", "user3"}
+			},
+			target: func(f *proto.Feature) {
+				f.Rules[0].Clauses[0].Id = "target-clause-1"
+			},
+			expected: []Command{
+				&proto.ChangeClauseAttributeCommand{Id: "target-clause-1", RuleId: "rule-1", Attribute: "email"},
+				&proto.ChangeClauseOperatorCommand{Id: "target-clause-1", RuleId: "rule-1", Operator: proto.Clause_IN},
+				&proto.AddClauseValueCommand{Id: "target-clause-1", RuleId: "rule-1", Value: "user3"},
+				&proto.RemoveClauseValueCommand{Id: "target-clause-1", RuleId: "rule-1", Value: "user1"},
+			},
+		},
+		{
+			desc: "clause added with a mapped segment",
+			source: func(f *proto.Feature) {
+				f.Rules[0].Clauses = append(f.Rules[0].Clauses, &proto.Clause{
+					Id:       "clause-2",
+					Operator: proto.Clause_SEGMENT,
+					Values:   []string{"source-segment"},
+				})
+			},
+			segmentIDs: map[string]string{"source-segment": "target-segment"},
+			expected: []Command{
+				&proto.AddClauseCommand{
+					RuleId: "rule-1",
+					Clause: &proto.Clause{
+						Id:       "clause-2",
+						Operator: proto.Clause_SEGMENT,
+						Values:   []string{"target-segment"},
+					},
+				},
+			},
+		},
+		{
+			desc: "clause removed",
+			target: func(f *proto.Feature) {
+				f.Rules[0].Clauses = append(f.Rules[0].Clauses, &proto.Clause{
+					Id:        "clause-2",
+					Attribute: "email",
+					Operator:  proto.Clause_ENDS_WITH,
+					Values:    []string{"@example.com"},
+				})
+			},
+			expected: []Command{
+				&proto.DeleteClauseCommand{Id: "clause-2", RuleId: "rule-1"},
+			},
+		},
+		{
+			desc: "prerequisites mapped",
+			source: func(f *proto.Feature) {
+				f.Prerequisites = []*proto.Prerequisite{
+					{FeatureId: "feature-2", VariationId: "source-variation-2"},
+					{FeatureId: "feature-3", VariationId: "source-variation-3"},
+				}
+			},
+			target: func(f *proto.Feature) {
+				f.Prerequisites = []*proto.Prerequisite{
+					{FeatureId: "feature-3", VariationId: "target-old-variation-3"},
+					{FeatureId: "feature-4", VariationId: "target-variation-4"},
+				}
+			},
+			prerequisiteVariationIDs: map[string]string{
+				"source-variation-2": "target-variation-2",
+				"source-variation-3": "target-variation-3",
+			},
+			expected: []Command{
+				&proto.RemovePrerequisiteCommand{FeatureId: "feature-4"},
+				&proto.AddPrerequisiteCommand{
+					Prerequisite: &proto.Prerequisite{FeatureId: "feature-2", VariationId: "target-variation-2"},
+				},
+				&proto.ChangePrerequisiteVariationCommand{
+					Prerequisite: &proto.Prerequisite{FeatureId: "feature-3", VariationId: "target-variation-3"},
+				},
+			},
+		},
+		{
+			desc: "err: variation not in the target",
+			source: func(f *proto.Feature) {
+				f.Variations[0].Value = "missing"
+			},
+			expectedErr: domain.ErrPromotionVariationNotFound,
+		},
+		{
+			desc: "err: segment not mapped",
+			source: func(f *proto.Feature) {
+				f.Rules[0].Clauses[0].Operator = proto.Clause_SEGMENT
+				f.Rules[0].Clauses[0].Values = []string{"source-segment"}
+			},
+			expectedErr: domain.ErrPromotionSegmentNotMapped,
+		},
+		{
+			desc: "err: prerequisite not mapped",
+			source: func(f *proto.Feature) {
+				f.Prerequisites = []*proto.Prerequisite{
+					{FeatureId: "feature-2", VariationId: "source-variation-2"},
+				}
+			},
+			expectedErr: domain.ErrPromotionPrerequisiteNotFound,
+		},
+	}
+	for _, p := range patterns {
+		p := p
+		t.Run(p.desc, func(t *testing.T) {
+			t.Parallel()
+			source := makeFeature("feature-id")
+			if p.source != nil {
+				p.source(source.Feature)
+			}
+			target := makePromotionTarget()
+			if p.target != nil {
+				p.target(target.Feature)
+			}
+			segmentIDs := p.segmentIDs
+			if segmentIDs == nil {
+				segmentIDs = map[string]string{}
+			}
+			prerequisiteVariationIDs := p.prerequisiteVariationIDs
+			if prerequisiteVariationIDs == nil {
+				prerequisiteVariationIDs = map[string]string{}
+			}
+			commands, err := PromotionTargetingCommands(
+				domain.NewFeaturePromotion(source.Feature, target, segmentIDs, prerequisiteVariationIDs),
+			)
+			assert.Equal(t, p.expectedErr, err)
+			assertCommands(t, p.expected, commands)
+		})
+	}
+}
+
+func TestPromotionCommandsApplied(t *testing.T) {
+	t.Parallel()
+	source := makeFeature("feature-id")
+	source.Variations = append(source.Variations, &proto.Variation{Id: "variation-C", Value: "C", Name: "C"})
+	source.Targets = append(source.Targets, &proto.Target{Variation: "variation-C", Users: []string{"user3"}})
+	source.Rules = []*proto.Rule{
+		makePromotionRule("rule-2", "variation-C"),
+		source.Rules[0],
+	}
+	source.Rules[1].Clauses[0].Values = []string{"user2", "user5"}
+	source.DefaultStrategy = fixedStrategy("variation-C")
+	source.OffVariation = "variation-A"
+	target := makePromotionTarget()
+	target.Variations = append(target.Variations, &proto.Variation{Id: "target-variation-D", Value: "D"})
+	target.Targets = append(target.Targets, &proto.Target{Variation: "target-variation-D"})
+	target.Rules = append(target.Rules, makePromotionRule("rule-3", "target-variation-D"))
+
+	newPromotion := func() *domain.FeaturePromotion {
+		return domain.NewFeaturePromotion(source.Feature, target, map[string]string{}, map[string]string{})
+	}
+	handleCommands(t, target, PromotionVariationCommands(newPromotion()))
+	commands, err := PromotionTargetingCommands(newPromotion())
+	require.NoError(t, err)
+	handleCommands(t, target, commands)
+	handleCommands(t, target, PromotionRemoveVariationCommands(newPromotion()))
+
+	assert.False(t, domain.HasChanges(newPromotion().Diff()))
+	require.Len(t, target.Rules, 2)
+	assert.Equal(t, "rule-2", target.Rules[0].Id)
+	assert.Equal(t, "rule-1", target.Rules[1].Id)
+	require.Len(t, target.Variations, 3)
+}
@@ -403,6 +403,185 @@ func TestResetSamplingSeed(t *testing.T) {
 	}
 }
 
+func TestChangeFeatureKind(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	patterns := map[string]struct {
+		kind           proto.Feature_Kind
+		expectedEvents []eventproto.Event_Type
+	}{
+		"temporary": {
+			kind:           proto.Feature_EXPERIMENT,
+			expectedEvents: []eventproto.Event_Type{eventproto.Event_FEATURE_KIND_CHANGED},
+		},
+		"permanent clears the removal date": {
+			kind: proto.Feature_OPS,
+			expectedEvents: []eventproto.Event_Type{
+				eventproto.Event_FEATURE_KIND_CHANGED,
+				eventproto.Event_FEATURE_EXPECTED_REMOVAL_DATE_CHANGED,
+			},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			f := makeFeature("fid")
+			f.ExpectedRemovalAt = time.Now().Unix()
+			cmd := &FeatureCommandHandler{
+				feature:      f,
+				eventFactory: makeEventFactory(f),
+			}
+			err := cmd.Handle(ctx, &proto.ChangeFeatureKindCommand{Kind: p.kind})
+			assert.NoError(t, err)
+			assert.Equal(t, p.kind, f.Kind)
+			actual := make([]eventproto.Event_Type, 0, len(cmd.Events))
+			for _, e := range cmd.Events {
+				actual = append(actual, e.Type)
+			}
+			assert.Equal(t, p.expectedEvents, actual)
+		})
+	}
+}
+
+func TestChangeExpectedRemovalDate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	at := time.Now().Unix()
+	patterns := map[string]struct {
+		kind     proto.Feature_Kind
+		expected error
+	}{
+		"success": {
+			kind:     proto.Feature_RELEASE,
+			expected: nil,
+		},
+		"err: permanent": {
+			kind:     proto.Feature_PERMISSION,
+			expected: domain.ErrRemovalDateOnPermanentFeature,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			f := makeFeature("fid")
+			f.Kind = p.kind
+			cmd := &FeatureCommandHandler{
+				feature:      f,
+				eventFactory: makeEventFactory(f),
+			}
+			err := cmd.Handle(ctx, &proto.ChangeExpectedRemovalDateCommand{ExpectedRemovalAt: at})
+			assert.Equal(t, p.expected, err)
+			if err == nil {
+				assert.Equal(t, at, f.ExpectedRemovalAt)
+				assert.Len(t, cmd.Events, 1)
+			}
+		})
+	}
+}
+
+func TestLayerAssignment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := makeFeature("fid")
+	cmd := &FeatureCommandHandler{
+		feature:      f,
+		eventFactory: makeEventFactory(f),
+	}
+	la := &proto.LayerAssignment{
+		LayerId:             "layer-id",
+		ExperimentId:        "experiment-id",
+		SliceStart:          10000,
+		SliceEnd:            20000,
+		BaselineVariationId: f.Variations[0].Id,
+	}
+	err := cmd.Handle(ctx, &proto.SetLayerAssignmentCommand{LayerAssignment: la})
+	assert.NoError(t, err)
+	assert.Equal(t, la, f.LayerAssignment)
+	err = cmd.Handle(ctx, &proto.RemoveLayerAssignmentCommand{ExperimentId: "other-experiment-id"})
+	assert.Equal(t, domain.ErrLayerAssignmentNotFound, err)
+	err = cmd.Handle(ctx, &proto.RemoveLayerAssignmentCommand{ExperimentId: "experiment-id"})
+	assert.NoError(t, err)
+	assert.Nil(t, f.LayerAssignment)
+	actual := make([]eventproto.Event_Type, 0, len(cmd.Events))
+	for _, e := range cmd.Events {
+		actual = append(actual, e.Type)
+	}
+	expected := []eventproto.Event_Type{
+		eventproto.Event_FEATURE_LAYER_ASSIGNMENT_SET,
+		eventproto.Event_FEATURE_LAYER_ASSIGNMENT_REMOVED,
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestUpdateBanditWeights(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := makeFeature("fid")
+	previous := &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+		{Variation: f.Variations[0].Id, Weight: 50000},
+		{Variation: f.Variations[1].Id, Weight: 50000},
+	}}
+	f.DefaultStrategy = &proto.Strategy{
+		Type:            proto.Strategy_BANDIT,
+		RolloutStrategy: previous,
+		BanditStrategy:  &proto.BanditStrategy{GoalId: "gid"},
+	}
+	cmd := &FeatureCommandHandler{
+		feature:      f,
+		eventFactory: makeEventFactory(f),
+	}
+	weights := &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+		{Variation: f.Variations[0].Id, Weight: 20000},
+		{Variation: f.Variations[1].Id, Weight: 80000},
+	}}
+	arms := []*proto.BanditStrategy_Arm{
+		{Variation: f.Variations[0].Id, Trials: 100, Conversions: 5},
+		{Variation: f.Variations[1].Id, Trials: 100, Conversions: 15},
+	}
+	err := cmd.Handle(ctx, &proto.UpdateBanditWeightsCommand{
+		RolloutStrategy: weights,
+		Arms:            arms,
+		UpdatedAt:       10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, weights, f.DefaultStrategy.RolloutStrategy)
+	assert.Equal(t, arms, f.DefaultStrategy.BanditStrategy.Arms)
+	assert.Len(t, cmd.Events, 1)
+	assert.Equal(t, eventproto.Event_FEATURE_BANDIT_WEIGHTS_UPDATED, cmd.Events[0].Type)
+	err = cmd.Handle(ctx, &proto.UpdateBanditWeightsCommand{RuleId: f.Rules[0].Id, RolloutStrategy: weights})
+	assert.Equal(t, domain.ErrStrategyNotBandit, err)
+}
+
+func TestExperimentAllocation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := makeFeature("fid")
+	cmd := &FeatureCommandHandler{
+		feature:      f,
+		eventFactory: makeEventFactory(f),
+	}
+	ea := &proto.ExperimentAllocation{
+		ExperimentId:       "experiment-id",
+		TrafficAllocation:  20000,
+		ControlVariationId: f.Variations[0].Id,
+	}
+	err := cmd.Handle(ctx, &proto.SetExperimentAllocationCommand{ExperimentAllocation: ea})
+	assert.NoError(t, err)
+	assert.Equal(t, ea, f.ExperimentAllocation)
+	err = cmd.Handle(ctx, &proto.RemoveExperimentAllocationCommand{ExperimentId: "other-experiment-id"})
+	assert.Equal(t, domain.ErrExperimentAllocationNotFound, err)
+	err = cmd.Handle(ctx, &proto.RemoveExperimentAllocationCommand{ExperimentId: "experiment-id"})
+	assert.NoError(t, err)
+	assert.Nil(t, f.ExperimentAllocation)
+	actual := make([]eventproto.Event_Type, 0, len(cmd.Events))
+	for _, e := range cmd.Events {
+		actual = append(actual, e.Type)
+	}
+	expected := []eventproto.Event_Type{
+		eventproto.Event_FEATURE_EXPERIMENT_ALLOCATION_SET,
+		eventproto.Event_FEATURE_EXPERIMENT_ALLOCATION_REMOVED,
+	}
+	assert.Equal(t, expected, actual)
+}
+
 func TestAddPrerequisite(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
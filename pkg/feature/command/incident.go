@@ -0,0 +1,92 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// IncidentToggleCommands returns the commands applying the incident action to the feature.
+// Serving the off variation removes the individual targets and the rules,
+// so that the default strategy is the only one used.
+// A feature running an experiment is disabled instead, because its layer assignment and experiment allocation
+// are evaluated before the default strategy and belong to the experiment's lifecycle.
+func IncidentToggleCommands(f *domain.Feature, action featureproto.FeatureIncident_Action) []Command {
+	if action == featureproto.FeatureIncident_DISABLE || f.LayerAssignment != nil || f.ExperimentAllocation != nil {
+		return []Command{&featureproto.DisableFeatureCommand{}}
+	}
+	commands := []Command{}
+	for _, t := range f.Targets {
+		for _, u := range t.Users {
+			commands = append(commands, &featureproto.RemoveUserFromVariationCommand{
+				Id:   t.Variation,
+				User: u,
+			})
+		}
+	}
+	for _, r := range f.Rules {
+		commands = append(commands, &featureproto.DeleteRuleCommand{Id: r.Id})
+	}
+	commands = append(commands, &featureproto.ChangeDefaultStrategyCommand{
+		Strategy: &featureproto.Strategy{
+			Type:          featureproto.Strategy_FIXED,
+			FixedStrategy: &featureproto.FixedStrategy{Variation: f.OffVariation},
+		},
+	})
+	return commands
+}
+
+// SnapshotRestoreCommands returns the commands returning the targeting and the enabled state
+// of the feature to its snapshot, e.g. taken before an incident.
+// It fails if a variation of the snapshot has been removed since.
+func SnapshotRestoreCommands(snapshot *featureproto.Feature, f *domain.Feature) ([]Command, error) {
+	commands, err := SnapshotTargetingCommands(snapshot, f)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Enabled != f.Enabled {
+		if snapshot.Enabled {
+			commands = append(commands, &featureproto.EnableFeatureCommand{})
+		} else {
+			commands = append(commands, &featureproto.DisableFeatureCommand{})
+		}
+	}
+	return commands, nil
+}
+
+// SnapshotTargetingCommands returns the commands restoring the targeting of the feature to its snapshot.
+// The snapshot is in the same environment, so the segment and prerequisite IDs are kept as they are.
+// It fails if a variation of the snapshot has been removed since.
+func SnapshotTargetingCommands(snapshot *featureproto.Feature, f *domain.Feature) ([]Command, error) {
+	segmentIDs := map[string]string{}
+	for _, r := range snapshot.Rules {
+		for _, c := range r.Clauses {
+			if c.Operator != featureproto.Clause_SEGMENT {
+				continue
+			}
+			for _, v := range c.Values {
+				segmentIDs[v] = v
+			}
+		}
+	}
+	prerequisiteVariationIDs := make(map[string]string, len(snapshot.Prerequisites))
+	for _, p := range snapshot.Prerequisites {
+		prerequisiteVariationIDs[p.VariationId] = p.VariationId
+	}
+	return PromotionTargetingCommands(
+		domain.NewFeaturePromotion(snapshot, f, segmentIDs, prerequisiteVariationIDs),
+	)
+}
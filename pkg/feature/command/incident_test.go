@@ -0,0 +1,150 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func handleCommands(t *testing.T, f *domain.Feature, commands []Command) {
+	t.Helper()
+	handler := &FeatureCommandHandler{
+		feature:      f,
+		eventFactory: makeEventFactory(f),
+	}
+	for _, c := range commands {
+		require.NoError(t, handler.Handle(context.Background(), c))
+	}
+}
+
+func TestIncidentToggleCommandsDisable(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	f.Enabled = true
+	snapshot := pb.Clone(f.Feature).(*proto.Feature)
+	handleCommands(t, f, IncidentToggleCommands(f, proto.FeatureIncident_DISABLE))
+	assert.False(t, f.Enabled)
+	assert.True(t, pb.Equal(snapshot.DefaultStrategy, f.DefaultStrategy))
+	assert.Len(t, f.Rules, 1)
+
+	commands, err := SnapshotRestoreCommands(snapshot, f)
+	require.NoError(t, err)
+	assert.Equal(t, []Command{&proto.EnableFeatureCommand{}}, commands)
+	handleCommands(t, f, commands)
+	assert.True(t, f.Enabled)
+}
+
+func TestIncidentToggleCommandsServeOffVariation(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	f.Enabled = true
+	f.OffVariation = "variation-A"
+	snapshot := pb.Clone(f.Feature).(*proto.Feature)
+	handleCommands(t, f, IncidentToggleCommands(f, proto.FeatureIncident_SERVE_OFF_VARIATION))
+	assert.True(t, f.Enabled)
+	assert.Empty(t, f.Rules)
+	for _, target := range f.Targets {
+		assert.Empty(t, target.Users)
+	}
+	assert.Equal(t, proto.Strategy_FIXED, f.DefaultStrategy.Type)
+	assert.Equal(t, "variation-A", f.DefaultStrategy.FixedStrategy.Variation)
+
+	commands, err := SnapshotRestoreCommands(snapshot, f)
+	require.NoError(t, err)
+	handleCommands(t, f, commands)
+	assert.True(t, f.Enabled)
+	assert.True(t, pb.Equal(snapshot.DefaultStrategy, f.DefaultStrategy))
+	require.Len(t, f.Rules, 1)
+	// The clause IDs are regenerated when the rule is added again.
+	assert.Equal(t, snapshot.Rules[0].Id, f.Rules[0].Id)
+	assert.True(t, pb.Equal(snapshot.Rules[0].Strategy, f.Rules[0].Strategy))
+	require.Len(t, f.Rules[0].Clauses, 1)
+	assert.Equal(t, snapshot.Rules[0].Clauses[0].Values, f.Rules[0].Clauses[0].Values)
+	assert.Equal(t, snapshot.Targets[0].Users, f.Targets[0].Users)
+}
+
+func TestIncidentToggleCommandsServeOffVariationRunningExperiment(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		desc  string
+		setup func(f *domain.Feature)
+	}{
+		{
+			desc: "layer assignment",
+			setup: func(f *domain.Feature) {
+				f.LayerAssignment = &proto.LayerAssignment{
+					LayerId:             "layer-id",
+					ExperimentId:        "experiment-id",
+					SliceStart:          0,
+					SliceEnd:            5000,
+					BaselineVariationId: "variation-A",
+				}
+			},
+		},
+		{
+			desc: "experiment allocation",
+			setup: func(f *domain.Feature) {
+				f.ExperimentAllocation = &proto.ExperimentAllocation{
+					ExperimentId:       "experiment-id",
+					TrafficAllocation:  50,
+					ControlVariationId: "variation-A",
+				}
+			},
+		},
+	}
+	for _, p := range patterns {
+		p := p
+		t.Run(p.desc, func(t *testing.T) {
+			t.Parallel()
+			f := makeFeature("feature-id")
+			f.Enabled = true
+			f.OffVariation = "variation-A"
+			p.setup(f)
+			snapshot := pb.Clone(f.Feature).(*proto.Feature)
+			commands := IncidentToggleCommands(f, proto.FeatureIncident_SERVE_OFF_VARIATION)
+			assert.Equal(t, []Command{&proto.DisableFeatureCommand{}}, commands)
+			handleCommands(t, f, commands)
+			assert.False(t, f.Enabled)
+			assert.True(t, pb.Equal(snapshot.LayerAssignment, f.LayerAssignment))
+			assert.True(t, pb.Equal(snapshot.ExperimentAllocation, f.ExperimentAllocation))
+			assert.Len(t, f.Rules, 1)
+
+			commands, err := SnapshotRestoreCommands(snapshot, f)
+			require.NoError(t, err)
+			assert.Equal(t, []Command{&proto.EnableFeatureCommand{}}, commands)
+			handleCommands(t, f, commands)
+			assert.True(t, f.Enabled)
+			assert.True(t, pb.Equal(snapshot.LayerAssignment, f.LayerAssignment))
+			assert.True(t, pb.Equal(snapshot.ExperimentAllocation, f.ExperimentAllocation))
+		})
+	}
+}
+
+func TestSnapshotRestoreCommandsVariationRemoved(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("feature-id")
+	snapshot := pb.Clone(f.Feature).(*proto.Feature)
+	snapshot.Variations[0].Value = "removed"
+	_, err := SnapshotRestoreCommands(snapshot, f)
+	assert.Equal(t, domain.ErrPromotionVariationNotFound, err)
+}
@@ -51,6 +51,17 @@ var (
 	ErrAlreadyEnabled                = errors.New("feature: already enabled")
 	ErrAlreadyDisabled               = errors.New("feature: already disabled")
 	ErrLastUsedInfoNotFound          = errors.New("feature: last used info not found")
+	ErrRemovalDateOnPermanentFeature = errors.New("feature: permanent feature can't have a removal date")
+	ErrLayerAssignmentNotFound       = errors.New("feature: layer assignment not found")
+	ErrExperimentAllocationNotFound  = errors.New("feature: experiment allocation not found")
+	ErrStrategyNotBandit             = errors.New("feature: strategy is not a bandit")
+
+	// NonExposureReasons are the reasons of evaluations serving users who are not in the experiment.
+	NonExposureReasons = []feature.Reason_Type{
+		feature.Reason_LAYER_HOLDOUT,
+		feature.Reason_LAYER_EXCLUDED,
+		feature.Reason_EXPERIMENT_NOT_ALLOCATED,
+	}
 )
 
 // TODO: think about splitting out ruleset / variation
@@ -68,16 +79,23 @@ func NewFeature(
 	tags []string,
 	defaultOnVariationIndex, defaultOffVariationIndex int,
 	maintainer string,
+	kind feature.Feature_Kind,
+	expectedRemovalAt int64,
 ) (*Feature, error) {
 	f := &Feature{Feature: &feature.Feature{
-		Id:            id,
-		Name:          name,
-		Description:   description,
-		Version:       1,
-		VariationType: variationType,
-		CreatedAt:     time.Now().Unix(),
-		Maintainer:    maintainer,
+		Id:                id,
+		Name:              name,
+		Description:       description,
+		Version:           1,
+		VariationType:     variationType,
+		CreatedAt:         time.Now().Unix(),
+		Maintainer:        maintainer,
+		Kind:              kind,
+		ExpectedRemovalAt: expectedRemovalAt,
 	}}
+	if f.IsPermanent() && expectedRemovalAt != 0 {
+		return nil, ErrRemovalDateOnPermanentFeature
+	}
 	for i := range variations {
 		id, err := uuid.NewUUID()
 		if err != nil {
@@ -132,6 +150,28 @@ func (f *Feature) assignUser(
 			return &feature.Reason{Type: feature.Reason_TARGET}, variation, err
 		}
 	}
+	// serve the baseline to users who are not in the experiment's slice of the layer
+	if f.LayerAssignment != nil {
+		reason, err := f.layerExclusionReason(user.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason != nil {
+			variation, err := findVariation(f.LayerAssignment.BaselineVariationId, f.Variations)
+			return reason, variation, err
+		}
+	}
+	// serve the control variation to users who are not allocated to the experiment
+	if f.ExperimentAllocation != nil {
+		allocated, err := f.isAllocatedToExperiment(user, segmentUsers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allocated {
+			variation, err := findVariation(f.ExperimentAllocation.ControlVariationId, f.Variations)
+			return &feature.Reason{Type: feature.Reason_EXPERIMENT_NOT_ALLOCATED}, variation, err
+		}
+	}
 	// evaluate ruleset
 	rule := f.ruleEvaluator.Evaluate(f.Rules, user, segmentUsers)
 	if rule != nil {
@@ -185,6 +225,42 @@ func (f *Feature) ChangeDescription(description string) error {
 	return nil
 }
 
+// IsPermanent reports whether the feature is meant to stay in the code, so it is never expected to be removed.
+func (f *Feature) IsPermanent() bool {
+	return f.Kind == feature.Feature_OPS || f.Kind == feature.Feature_PERMISSION
+}
+
+// ChangeKind changes the kind of the feature. Changing it to a permanent kind clears the removal date.
+func (f *Feature) ChangeKind(kind feature.Feature_Kind) error {
+	f.Kind = kind
+	if f.IsPermanent() {
+		f.ExpectedRemovalAt = 0
+	}
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func (f *Feature) ChangeExpectedRemovalDate(expectedRemovalAt int64) error {
+	if f.IsPermanent() && expectedRemovalAt != 0 {
+		return ErrRemovalDateOnPermanentFeature
+	}
+	f.ExpectedRemovalAt = expectedRemovalAt
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// IsRemovalDateApproaching reports whether the removal date comes within the period.
+func (f *Feature) IsRemovalDateApproaching(now time.Time, period time.Duration) bool {
+	if f.ExpectedRemovalAt == 0 || f.IsRemovalDatePassed(now) {
+		return false
+	}
+	return f.ExpectedRemovalAt <= now.Add(period).Unix()
+}
+
+func (f *Feature) IsRemovalDatePassed(now time.Time) bool {
+	return f.ExpectedRemovalAt != 0 && f.ExpectedRemovalAt <= now.Unix()
+}
+
 func (f *Feature) ChangeOffVariation(id string) error {
 	_, err := findVariation(id, f.Variations)
 	if err != nil {
@@ -313,7 +389,7 @@ func validateStrategy(strategy *feature.Strategy, variations []*feature.Variatio
 	switch strategy.Type {
 	case feature.Strategy_FIXED:
 		return validateFixedStrategy(strategy.FixedStrategy, variations)
-	case feature.Strategy_ROLLOUT:
+	case feature.Strategy_ROLLOUT, feature.Strategy_BANDIT:
 		return validateRolloutStrategy(strategy.RolloutStrategy, variations)
 	default:
 		return errUnsupportedStrategy
@@ -494,18 +570,23 @@ func (f *Feature) addTarget(variationID string) {
 
 func (f *Feature) addVariationToRules(variationID string) {
 	for _, rule := range f.Rules {
-		if rule.Strategy.Type == feature.Strategy_ROLLOUT {
+		if hasRolloutStrategy(rule.Strategy) {
 			f.addVariationToRolloutStrategy(rule.Strategy.RolloutStrategy, variationID)
 		}
 	}
 }
 
 func (f *Feature) addVariationToDefaultStrategy(variationID string) {
-	if f.DefaultStrategy != nil && f.DefaultStrategy.Type == feature.Strategy_ROLLOUT {
+	if f.DefaultStrategy != nil && hasRolloutStrategy(f.DefaultStrategy) {
 		f.addVariationToRolloutStrategy(f.DefaultStrategy.RolloutStrategy, variationID)
 	}
 }
 
+// hasRolloutStrategy reports whether the strategy serves the weights of its rollout strategy.
+func hasRolloutStrategy(strategy *feature.Strategy) bool {
+	return strategy.Type == feature.Strategy_ROLLOUT || strategy.Type == feature.Strategy_BANDIT
+}
+
 func (f *Feature) addVariationToRolloutStrategy(strategy *feature.RolloutStrategy, variationID string) {
 	strategy.Variations = append(strategy.Variations, &feature.RolloutStrategy_Variation{
 		Variation: variationID,
@@ -558,7 +639,7 @@ func strategyContainsVariation(id string, strategy *feature.Strategy) bool {
 		if strategy.FixedStrategy.Variation == id {
 			return true
 		}
-	} else if strategy.Type == feature.Strategy_ROLLOUT {
+	} else if hasRolloutStrategy(strategy) {
 		for _, v := range strategy.RolloutStrategy.Variations {
 			if v.Variation == id && v.Weight > 0 {
 				return true
@@ -579,7 +660,7 @@ func (f *Feature) removeTarget(variationID string) error {
 
 func (f *Feature) removeVariationFromRules(variationID string) {
 	for _, rule := range f.Rules {
-		if rule.Strategy.Type == feature.Strategy_ROLLOUT {
+		if hasRolloutStrategy(rule.Strategy) {
 			f.removeVariationFromRolloutStrategy(rule.Strategy.RolloutStrategy, variationID)
 			return
 		}
@@ -587,7 +668,7 @@ func (f *Feature) removeVariationFromRules(variationID string) {
 }
 
 func (f *Feature) removeVariationFromDefaultStrategy(variationID string) {
-	if f.DefaultStrategy != nil && f.DefaultStrategy.Type == feature.Strategy_ROLLOUT {
+	if f.DefaultStrategy != nil && hasRolloutStrategy(f.DefaultStrategy) {
 		f.removeVariationFromRolloutStrategy(f.DefaultStrategy.RolloutStrategy, variationID)
 	}
 }
@@ -650,6 +731,35 @@ func (f *Feature) ChangeFixedStrategy(ruleID string, strategy *feature.FixedStra
 	return nil
 }
 
+// UpdateBanditWeights replaces the weights and the arms of the bandit strategy.
+// The default strategy is updated when the rule id is empty.
+func (f *Feature) UpdateBanditWeights(
+	ruleID string,
+	weights *feature.RolloutStrategy,
+	arms []*feature.BanditStrategy_Arm,
+	updatedAt int64,
+) error {
+	strategy := f.DefaultStrategy
+	if ruleID != "" {
+		ruleIdx, err := f.findRule(ruleID)
+		if err != nil {
+			return err
+		}
+		strategy = f.Rules[ruleIdx].Strategy
+	}
+	if strategy == nil || strategy.Type != feature.Strategy_BANDIT || strategy.BanditStrategy == nil {
+		return ErrStrategyNotBandit
+	}
+	if err := validateRolloutStrategy(weights, f.Variations); err != nil {
+		return err
+	}
+	strategy.RolloutStrategy = weights
+	strategy.BanditStrategy.Arms = arms
+	strategy.BanditStrategy.UpdatedAt = updatedAt
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
 func (f *Feature) ChangeRolloutStrategy(ruleID string, strategy *feature.RolloutStrategy) error {
 	ruleIdx, err := f.findRule(ruleID)
 	if err != nil {
@@ -689,6 +799,98 @@ func (f *Feature) IncrementVersion() error {
 	return nil
 }
 
+// layerExclusionReason returns nil when the user is in the experiment's slice of the layer.
+func (f *Feature) layerExclusionReason(userID string) (*feature.Reason, error) {
+	la := f.LayerAssignment
+	// The bucket doesn't depend on the feature, so a user has the same bucket in every feature of the layer.
+	bucket, err := f.strategyEvaluator.bucket100000(userID, la.LayerId)
+	if err != nil {
+		return nil, err
+	}
+	if bucket < la.HoldoutWeight {
+		return &feature.Reason{Type: feature.Reason_LAYER_HOLDOUT}, nil
+	}
+	if bucket < la.SliceStart || bucket >= la.SliceEnd {
+		return &feature.Reason{Type: feature.Reason_LAYER_EXCLUDED}, nil
+	}
+	return nil, nil
+}
+
+func (f *Feature) SetLayerAssignment(la *feature.LayerAssignment) error {
+	if _, err := findVariation(la.BaselineVariationId, f.Variations); err != nil {
+		return err
+	}
+	f.LayerAssignment = la
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// RemoveLayerAssignment keeps the assignment when it belongs to another experiment.
+func (f *Feature) RemoveLayerAssignment(experimentID string) error {
+	if f.LayerAssignment == nil || f.LayerAssignment.ExperimentId != experimentID {
+		return ErrLayerAssignmentNotFound
+	}
+	f.LayerAssignment = nil
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func (f *Feature) isAllocatedToExperiment(
+	user *userproto.User,
+	segmentUsers []*feature.SegmentUser,
+) (bool, error) {
+	ea := f.ExperimentAllocation
+	for _, clause := range ea.Audience {
+		if !f.ruleEvaluator.evaluateClause(clause, user, segmentUsers) {
+			return false, nil
+		}
+	}
+	// Zero allocates all the users matching the audience, as in the experiment API.
+	if ea.TrafficAllocation == 0 {
+		return true, nil
+	}
+	// The bucket doesn't depend on the sampling seed,
+	// so resetting it doesn't move users in or out of the experiment.
+	bucket, err := f.strategyEvaluator.bucket100000(user.Id, ea.ExperimentId)
+	if err != nil {
+		return false, err
+	}
+	return bucket < ea.TrafficAllocation, nil
+}
+
+func (f *Feature) SetExperimentAllocation(ea *feature.ExperimentAllocation) error {
+	if _, err := findVariation(ea.ControlVariationId, f.Variations); err != nil {
+		return err
+	}
+	f.ExperimentAllocation = ea
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// RemoveExperimentAllocation keeps the allocation when it belongs to another experiment.
+func (f *Feature) RemoveExperimentAllocation(experimentID string) error {
+	if f.ExperimentAllocation == nil || f.ExperimentAllocation.ExperimentId != experimentID {
+		return ErrExperimentAllocationNotFound
+	}
+	f.ExperimentAllocation = nil
+	f.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// IsExperimentExposure reports whether an evaluation with the reason counts as an exposure
+// to the experiment running on the feature.
+func IsExperimentExposure(reason *feature.Reason) bool {
+	if reason == nil {
+		return true
+	}
+	for _, t := range NonExposureReasons {
+		if reason.Type == t {
+			return false
+		}
+	}
+	return true
+}
+
 func (f *Feature) ResetSamplingSeed() error {
 	id, err := uuid.NewUUID()
 	if err != nil {
@@ -807,23 +1009,25 @@ func (f *Feature) Clone(
 ) (*Feature, error) {
 	now := time.Now().Unix()
 	newFeature := &Feature{Feature: &feature.Feature{
-		Id:              f.Id,
-		Name:            f.Name,
-		Description:     f.Description,
-		Enabled:         false,
-		Deleted:         false,
-		Version:         1,
-		CreatedAt:       now,
-		UpdatedAt:       now,
-		Variations:      f.Variations,
-		Targets:         f.Targets,
-		Rules:           f.Rules,
-		DefaultStrategy: f.DefaultStrategy,
-		OffVariation:    f.OffVariation,
-		Tags:            f.Tags,
-		Maintainer:      maintainer,
-		VariationType:   f.VariationType,
-		Archived:        false,
+		Id:                f.Id,
+		Name:              f.Name,
+		Description:       f.Description,
+		Enabled:           false,
+		Deleted:           false,
+		Version:           1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Variations:        f.Variations,
+		Targets:           f.Targets,
+		Rules:             f.Rules,
+		DefaultStrategy:   f.DefaultStrategy,
+		OffVariation:      f.OffVariation,
+		Tags:              f.Tags,
+		Maintainer:        maintainer,
+		VariationType:     f.VariationType,
+		Archived:          false,
+		Kind:              f.Kind,
+		ExpectedRemovalAt: f.ExpectedRemovalAt,
 	}}
 	for i := range newFeature.Variations {
 		id, err := uuid.NewUUID()
@@ -859,7 +1063,7 @@ func updateStrategyVariationID(varID, uID string, s *feature.Strategy) error {
 		if varID == s.FixedStrategy.Variation {
 			s.FixedStrategy.Variation = uID
 		}
-	case feature.Strategy_ROLLOUT:
+	case feature.Strategy_ROLLOUT, feature.Strategy_BANDIT:
 		for i := range s.RolloutStrategy.Variations {
 			if s.RolloutStrategy.Variations[i].Variation == varID {
 				s.RolloutStrategy.Variations[i].Variation = uID
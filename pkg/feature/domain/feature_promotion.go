@@ -0,0 +1,430 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"errors"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var (
+	ErrPromotionVariationNotFound    = errors.New("feature: promotion source variation not found in target")
+	ErrPromotionSegmentNotMapped     = errors.New("feature: promotion segment is not mapped")
+	ErrPromotionPrerequisiteNotFound = errors.New("feature: promotion prerequisite is not mapped")
+)
+
+// FeaturePromotion compares a feature in a source environment with the same feature in a target environment.
+// Variations are matched by value because their IDs are regenerated when a feature is cloned.
+// Segment IDs and prerequisite variation IDs must be mapped by the caller.
+type FeaturePromotion struct {
+	source                   *feature.Feature
+	target                   *Feature
+	segmentIDs               map[string]string
+	prerequisiteVariationIDs map[string]string
+}
+
+func NewFeaturePromotion(
+	source *feature.Feature,
+	target *Feature,
+	segmentIDs map[string]string,
+	prerequisiteVariationIDs map[string]string,
+) *FeaturePromotion {
+	return &FeaturePromotion{
+		source:                   source,
+		target:                   target,
+		segmentIDs:               segmentIDs,
+		prerequisiteVariationIDs: prerequisiteVariationIDs,
+	}
+}
+
+func (p *FeaturePromotion) Source() *feature.Feature {
+	return p.source
+}
+
+func (p *FeaturePromotion) Target() *Feature {
+	return p.target
+}
+
+// Diff compares both features in the value space, so the result doesn't depend on the variation IDs.
+func (p *FeaturePromotion) Diff() *feature.FeatureDiff {
+	diff := &feature.FeatureDiff{
+		FeatureId:               p.source.Id,
+		UnmappedSegmentIds:      p.UnmappedSegmentIDs(),
+		UnmappedPrerequisiteIds: p.UnmappedPrerequisiteIDs(),
+	}
+	diff.Variations = p.diffVariations()
+	diff.Targets = p.diffTargets()
+	diff.Rules = p.diffRules()
+	diff.Prerequisites = p.diffPrerequisites()
+	srcValues := variationValues(p.source.Variations)
+	tarValues := variationValues(p.target.Variations)
+	if !proto.Equal(
+		translateStrategy(p.source.DefaultStrategy, srcValues),
+		translateStrategy(p.target.DefaultStrategy, tarValues),
+	) {
+		diff.DefaultStrategy = &feature.FeatureDiff_StrategyChange{
+			Source: p.source.DefaultStrategy,
+			Target: p.target.DefaultStrategy,
+		}
+	}
+	if srcValues[p.source.OffVariation] != tarValues[p.target.OffVariation] {
+		srcOff, _ := findVariation(p.source.OffVariation, p.source.Variations)
+		tarOff, _ := findVariation(p.target.OffVariation, p.target.Variations)
+		diff.OffVariation = &feature.FeatureDiff_OffVariationChange{
+			Source: srcOff,
+			Target: tarOff,
+		}
+	}
+	return diff
+}
+
+// HasChanges reports whether the diff contains at least one change.
+func HasChanges(diff *feature.FeatureDiff) bool {
+	return len(diff.Variations) > 0 ||
+		len(diff.Targets) > 0 ||
+		len(diff.Rules) > 0 ||
+		len(diff.Prerequisites) > 0 ||
+		diff.DefaultStrategy != nil ||
+		diff.OffVariation != nil
+}
+
+func (p *FeaturePromotion) diffVariations() []*feature.FeatureDiff_VariationChange {
+	changes := []*feature.FeatureDiff_VariationChange{}
+	for _, sv := range p.source.Variations {
+		tv := findVariationByValue(sv.Value, p.target.Variations)
+		if tv == nil {
+			changes = append(changes, &feature.FeatureDiff_VariationChange{
+				Type:   feature.FeatureDiff_ADDED,
+				Source: sv,
+			})
+			continue
+		}
+		if sv.Name != tv.Name || sv.Description != tv.Description {
+			changes = append(changes, &feature.FeatureDiff_VariationChange{
+				Type:   feature.FeatureDiff_MODIFIED,
+				Source: sv,
+				Target: tv,
+			})
+		}
+	}
+	for _, tv := range p.target.Variations {
+		if findVariationByValue(tv.Value, p.source.Variations) == nil {
+			changes = append(changes, &feature.FeatureDiff_VariationChange{
+				Type:   feature.FeatureDiff_REMOVED,
+				Target: tv,
+			})
+		}
+	}
+	return changes
+}
+
+func (p *FeaturePromotion) diffTargets() []*feature.FeatureDiff_TargetChange {
+	srcValues := variationValues(p.source.Variations)
+	tarValues := variationValues(p.target.Variations)
+	srcUsers := make(map[string][]string, len(p.source.Targets))
+	for _, t := range p.source.Targets {
+		srcUsers[srcValues[t.Variation]] = t.Users
+	}
+	tarUsers := make(map[string][]string, len(p.target.Targets))
+	for _, t := range p.target.Targets {
+		tarUsers[tarValues[t.Variation]] = t.Users
+	}
+	changes := []*feature.FeatureDiff_TargetChange{}
+	for _, v := range p.source.Variations {
+		added := subtract(srcUsers[v.Value], tarUsers[v.Value])
+		removed := subtract(tarUsers[v.Value], srcUsers[v.Value])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		changes = append(changes, &feature.FeatureDiff_TargetChange{
+			VariationValue: v.Value,
+			AddedUsers:     added,
+			RemovedUsers:   removed,
+		})
+	}
+	for _, v := range p.target.Variations {
+		if findVariationByValue(v.Value, p.source.Variations) != nil || len(tarUsers[v.Value]) == 0 {
+			continue
+		}
+		changes = append(changes, &feature.FeatureDiff_TargetChange{
+			VariationValue: v.Value,
+			RemovedUsers:   tarUsers[v.Value],
+		})
+	}
+	return changes
+}
+
+func (p *FeaturePromotion) diffRules() []*feature.FeatureDiff_RuleChange {
+	srcValues := variationValues(p.source.Variations)
+	tarValues := variationValues(p.target.Variations)
+	srcOrder := commonRuleOrder(p.source.Rules, p.target.Rules)
+	tarOrder := commonRuleOrder(p.target.Rules, p.source.Rules)
+	changes := []*feature.FeatureDiff_RuleChange{}
+	for _, sr := range p.source.Rules {
+		idx, err := p.target.findRule(sr.Id)
+		if err != nil {
+			changes = append(changes, &feature.FeatureDiff_RuleChange{
+				Type:   feature.FeatureDiff_ADDED,
+				RuleId: sr.Id,
+				Source: sr,
+			})
+			continue
+		}
+		tr := p.target.Rules[idx]
+		if srcOrder[sr.Id] != tarOrder[sr.Id] || !equalRules(
+			translateRule(sr, srcValues, p.segmentIDs),
+			translateRule(tr, tarValues, nil),
+		) {
+			changes = append(changes, &feature.FeatureDiff_RuleChange{
+				Type:   feature.FeatureDiff_MODIFIED,
+				RuleId: sr.Id,
+				Source: sr,
+				Target: tr,
+			})
+		}
+	}
+	for _, tr := range p.target.Rules {
+		if findRule(tr.Id, p.source.Rules) == nil {
+			changes = append(changes, &feature.FeatureDiff_RuleChange{
+				Type:   feature.FeatureDiff_REMOVED,
+				RuleId: tr.Id,
+				Target: tr,
+			})
+		}
+	}
+	return changes
+}
+
+// commonRuleOrder returns the position of each rule in a, counting only the rules that also exist in b.
+func commonRuleOrder(a, b []*feature.Rule) map[string]int {
+	order := make(map[string]int, len(a))
+	for _, r := range a {
+		if findRule(r.Id, b) != nil {
+			order[r.Id] = len(order)
+		}
+	}
+	return order
+}
+
+func (p *FeaturePromotion) diffPrerequisites() []*feature.FeatureDiff_PrerequisiteChange {
+	changes := []*feature.FeatureDiff_PrerequisiteChange{}
+	for _, sp := range p.source.Prerequisites {
+		idx, err := p.target.findPrerequisite(sp.FeatureId)
+		if err != nil {
+			changes = append(changes, &feature.FeatureDiff_PrerequisiteChange{
+				Type:              feature.FeatureDiff_ADDED,
+				FeatureId:         sp.FeatureId,
+				SourceVariationId: sp.VariationId,
+			})
+			continue
+		}
+		tp := p.target.Prerequisites[idx]
+		if p.prerequisiteVariationIDs[sp.VariationId] != tp.VariationId {
+			changes = append(changes, &feature.FeatureDiff_PrerequisiteChange{
+				Type:              feature.FeatureDiff_MODIFIED,
+				FeatureId:         sp.FeatureId,
+				SourceVariationId: sp.VariationId,
+				TargetVariationId: tp.VariationId,
+			})
+		}
+	}
+	for _, tp := range p.target.Prerequisites {
+		if findPrerequisite(tp.FeatureId, p.source.Prerequisites) == nil {
+			changes = append(changes, &feature.FeatureDiff_PrerequisiteChange{
+				Type:              feature.FeatureDiff_REMOVED,
+				FeatureId:         tp.FeatureId,
+				TargetVariationId: tp.VariationId,
+			})
+		}
+	}
+	return changes
+}
+
+// UnmappedSegmentIDs returns the source segment IDs used in the rules that have no target segment.
+func (p *FeaturePromotion) UnmappedSegmentIDs() []string {
+	ids := []string{}
+	for _, r := range p.source.Rules {
+		for _, c := range r.Clauses {
+			if c.Operator != feature.Clause_SEGMENT {
+				continue
+			}
+			for _, v := range c.Values {
+				if _, ok := p.segmentIDs[v]; !ok && !contains(v, ids) {
+					ids = append(ids, v)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// UnmappedPrerequisiteIDs returns the prerequisite feature IDs whose variation has no target variation.
+func (p *FeaturePromotion) UnmappedPrerequisiteIDs() []string {
+	ids := []string{}
+	for _, pre := range p.source.Prerequisites {
+		if _, ok := p.prerequisiteVariationIDs[pre.VariationId]; !ok {
+			ids = append(ids, pre.FeatureId)
+		}
+	}
+	return ids
+}
+
+// TranslatedSource returns a copy of the source feature that uses the variation, segment and prerequisite IDs
+// of the target environment.
+// It fails if a source variation doesn't exist in the target, so the missing variations must be added first.
+func (p *FeaturePromotion) TranslatedSource() (*feature.Feature, error) {
+	variationIDs := make(map[string]string, len(p.source.Variations))
+	for _, sv := range p.source.Variations {
+		tv := findVariationByValue(sv.Value, p.target.Variations)
+		if tv == nil {
+			return nil, ErrPromotionVariationNotFound
+		}
+		variationIDs[sv.Id] = tv.Id
+	}
+	if len(p.UnmappedSegmentIDs()) > 0 {
+		return nil, ErrPromotionSegmentNotMapped
+	}
+	if len(p.UnmappedPrerequisiteIDs()) > 0 {
+		return nil, ErrPromotionPrerequisiteNotFound
+	}
+	f := proto.Clone(p.source).(*feature.Feature)
+	for _, v := range f.Variations {
+		v.Id = variationIDs[v.Id]
+	}
+	for _, t := range f.Targets {
+		t.Variation = variationIDs[t.Variation]
+	}
+	for i := range f.Rules {
+		f.Rules[i] = translateRule(f.Rules[i], variationIDs, p.segmentIDs)
+	}
+	f.DefaultStrategy = translateStrategy(f.DefaultStrategy, variationIDs)
+	f.OffVariation = variationIDs[f.OffVariation]
+	for _, pre := range f.Prerequisites {
+		pre.VariationId = p.prerequisiteVariationIDs[pre.VariationId]
+	}
+	return f, nil
+}
+
+func variationValues(vs []*feature.Variation) map[string]string {
+	values := make(map[string]string, len(vs))
+	for _, v := range vs {
+		values[v.Id] = v.Value
+	}
+	return values
+}
+
+func findVariationByValue(value string, vs []*feature.Variation) *feature.Variation {
+	for _, v := range vs {
+		if v.Value == value {
+			return v
+		}
+	}
+	return nil
+}
+
+func findRule(id string, rules []*feature.Rule) *feature.Rule {
+	for _, r := range rules {
+		if r.Id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func findPrerequisite(featureID string, ps []*feature.Prerequisite) *feature.Prerequisite {
+	for _, p := range ps {
+		if p.FeatureId == featureID {
+			return p
+		}
+	}
+	return nil
+}
+
+// translateStrategy returns a copy of the strategy with its variation IDs replaced using the mapping.
+// IDs that are not in the mapping are kept as they are.
+func translateStrategy(s *feature.Strategy, variationIDs map[string]string) *feature.Strategy {
+	if s == nil {
+		return nil
+	}
+	t := proto.Clone(s).(*feature.Strategy)
+	if t.FixedStrategy != nil {
+		t.FixedStrategy.Variation = translateID(t.FixedStrategy.Variation, variationIDs)
+	}
+	if t.RolloutStrategy != nil {
+		for _, v := range t.RolloutStrategy.Variations {
+			v.Variation = translateID(v.Variation, variationIDs)
+		}
+	}
+	return t
+}
+
+// translateRule returns a copy of the rule with its variation and segment IDs replaced using the mappings.
+func translateRule(r *feature.Rule, variationIDs, segmentIDs map[string]string) *feature.Rule {
+	t := proto.Clone(r).(*feature.Rule)
+	t.Strategy = translateStrategy(t.Strategy, variationIDs)
+	for _, c := range t.Clauses {
+		if c.Operator != feature.Clause_SEGMENT {
+			continue
+		}
+		for i := range c.Values {
+			c.Values[i] = translateID(c.Values[i], segmentIDs)
+		}
+	}
+	return t
+}
+
+func translateID(id string, ids map[string]string) string {
+	if t, ok := ids[id]; ok {
+		return t
+	}
+	return id
+}
+
+// equalRules compares two rules ignoring the clause IDs, because they are regenerated when a rule is added,
+// and the order of the clause values.
+func equalRules(a, b *feature.Rule) bool {
+	if a.Id != b.Id || !proto.Equal(a.Strategy, b.Strategy) || len(a.Clauses) != len(b.Clauses) {
+		return false
+	}
+	for i := range a.Clauses {
+		if !equalClauses(a.Clauses[i], b.Clauses[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalClauses(a, b *feature.Clause) bool {
+	if a.Attribute != b.Attribute || a.Operator != b.Operator || len(a.Values) != len(b.Values) {
+		return false
+	}
+	return len(subtract(a.Values, b.Values)) == 0
+}
+
+// subtract returns the values in a that are not in b, sorted.
+func subtract(a, b []string) []string {
+	diff := []string{}
+	for _, v := range a {
+		if !contains(v, b) && !contains(v, diff) {
+			diff = append(diff, v)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
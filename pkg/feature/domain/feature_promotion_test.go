@@ -0,0 +1,134 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gproto "google.golang.org/protobuf/proto"
+
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func makePromotionTarget(t *testing.T) *Feature {
+	t.Helper()
+	f := makeFeature("feature-id")
+	for _, v := range f.Variations {
+		v.Id = "target-" + v.Id
+	}
+	for _, tg := range f.Targets {
+		tg.Variation = "target-" + tg.Variation
+	}
+	for _, r := range f.Rules {
+		r.Strategy.FixedStrategy.Variation = "target-" + r.Strategy.FixedStrategy.Variation
+	}
+	f.DefaultStrategy.FixedStrategy.Variation = "target-" + f.DefaultStrategy.FixedStrategy.Variation
+	return f
+}
+
+func TestFeaturePromotionDiffNoChanges(t *testing.T) {
+	t.Parallel()
+	source := makeFeature("feature-id")
+	target := makePromotionTarget(t)
+	target.Rules[0].Clauses[0].Id = "regenerated-clause-id"
+	p := NewFeaturePromotion(source.Feature, target, map[string]string{}, map[string]string{})
+	diff := p.Diff()
+	assert.False(t, HasChanges(diff))
+}
+
+func TestFeaturePromotionDiff(t *testing.T) {
+	t.Parallel()
+	source := makeFeature("feature-id")
+	source.Variations[0].Name = "changed"
+	source.Targets[0].Users = []string{"user1", "user4"}
+	source.Rules = source.Rules[:1]
+	source.DefaultStrategy.FixedStrategy.Variation = "variation-C"
+	target := makePromotionTarget(t)
+	p := NewFeaturePromotion(source.Feature, target, map[string]string{}, map[string]string{})
+	diff := p.Diff()
+	require.True(t, HasChanges(diff))
+	require.Len(t, diff.Variations, 1)
+	assert.Equal(t, proto.FeatureDiff_MODIFIED, diff.Variations[0].Type)
+	require.Len(t, diff.Targets, 1)
+	assert.Equal(t, "A", diff.Targets[0].VariationValue)
+	assert.Equal(t, []string{"user4"}, diff.Targets[0].AddedUsers)
+	require.Len(t, diff.Rules, 1)
+	assert.Equal(t, proto.FeatureDiff_REMOVED, diff.Rules[0].Type)
+	assert.Equal(t, "rule-2", diff.Rules[0].RuleId)
+	assert.NotNil(t, diff.DefaultStrategy)
+	assert.Nil(t, diff.OffVariation)
+}
+
+func TestFeaturePromotionTranslatedSource(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		desc        string
+		source      func() *proto.Feature
+		segmentIDs  map[string]string
+		expectedErr error
+	}{
+		{
+			desc: "err: variation not found",
+			source: func() *proto.Feature {
+				f := makeFeature("feature-id")
+				f.Variations = append(f.Variations, &proto.Variation{Id: "variation-D", Value: "D"})
+				return f.Feature
+			},
+			expectedErr: ErrPromotionVariationNotFound,
+		},
+		{
+			desc: "err: segment not mapped",
+			source: func() *proto.Feature {
+				f := makeFeature("feature-id")
+				f.Rules[0].Clauses[0].Operator = proto.Clause_SEGMENT
+				f.Rules[0].Clauses[0].Values = []string{"segment-1"}
+				return f.Feature
+			},
+			segmentIDs:  map[string]string{},
+			expectedErr: ErrPromotionSegmentNotMapped,
+		},
+		{
+			desc: "success",
+			source: func() *proto.Feature {
+				f := makeFeature("feature-id")
+				f.Rules[0].Clauses[0].Operator = proto.Clause_SEGMENT
+				f.Rules[0].Clauses[0].Values = []string{"segment-1"}
+				return f.Feature
+			},
+			segmentIDs:  map[string]string{"segment-1": "target-segment-1"},
+			expectedErr: nil,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			source := p.source()
+			original := gproto.Clone(source)
+			promotion := NewFeaturePromotion(source, makePromotionTarget(t), p.segmentIDs, map[string]string{})
+			f, err := promotion.TranslatedSource()
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.True(t, gproto.Equal(original, source))
+			assert.Equal(t, "target-variation-A", f.Variations[0].Id)
+			assert.Equal(t, "target-variation-A", f.Targets[0].Variation)
+			assert.Equal(t, "target-variation-A", f.Rules[0].Strategy.FixedStrategy.Variation)
+			assert.Equal(t, []string{"target-segment-1"}, f.Rules[0].Clauses[0].Values)
+			assert.Equal(t, "target-variation-B", f.DefaultStrategy.FixedStrategy.Variation)
+		})
+	}
+}
@@ -198,6 +198,8 @@ func TestNewFeature(t *testing.T) {
 	defaultOnVariationIndex := 0
 	defaultOffVariationIndex := 2
 	maintainer := "bucketeer@example.com"
+	kind := feature.Feature_RELEASE
+	expectedRemovalAt := time.Now().AddDate(0, 1, 0).Unix()
 	f, err := NewFeature(
 		id,
 		name,
@@ -208,6 +210,8 @@ func TestNewFeature(t *testing.T) {
 		defaultOnVariationIndex,
 		defaultOffVariationIndex,
 		maintainer,
+		kind,
+		expectedRemovalAt,
 	)
 	strategy := &feature.Strategy{
 		Type:          feature.Strategy_FIXED,
@@ -226,6 +230,22 @@ func TestNewFeature(t *testing.T) {
 	assert.Equal(t, f.Variations[defaultOffVariationIndex].Id, f.OffVariation)
 	assert.Equal(t, strategy, f.DefaultStrategy)
 	assert.Equal(t, maintainer, f.Maintainer)
+	assert.Equal(t, kind, f.Kind)
+	assert.Equal(t, expectedRemovalAt, f.ExpectedRemovalAt)
+	_, err = NewFeature(
+		id,
+		name,
+		description,
+		variationType,
+		variations,
+		tags,
+		defaultOnVariationIndex,
+		defaultOffVariationIndex,
+		maintainer,
+		feature.Feature_OPS,
+		expectedRemovalAt,
+	)
+	assert.Equal(t, ErrRemovalDateOnPermanentFeature, err)
 }
 
 func TestAddVariation(t *testing.T) {
@@ -443,6 +463,226 @@ func TestAssignUserTarget(t *testing.T) {
 	}
 }
 
+func TestAssignUserLayer(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("test-feature")
+	bucket, err := f.strategyEvaluator.bucket100000("user4", "layer-id")
+	require.NoError(t, err)
+	patterns := []struct {
+		desc                string
+		userID              string
+		holdoutWeight       int32
+		sliceStart          int32
+		sliceEnd            int32
+		expectedReason      proto.Reason_Type
+		expectedVariationID string
+	}{
+		{
+			desc:                "in holdout",
+			userID:              "user4",
+			holdoutWeight:       bucket + 1,
+			sliceStart:          bucket + 1,
+			sliceEnd:            100000,
+			expectedReason:      proto.Reason_LAYER_HOLDOUT,
+			expectedVariationID: "variation-C",
+		},
+		{
+			desc:                "outside slice",
+			userID:              "user4",
+			sliceStart:          bucket + 1,
+			sliceEnd:            100000,
+			expectedReason:      proto.Reason_LAYER_EXCLUDED,
+			expectedVariationID: "variation-C",
+		},
+		{
+			desc:                "in slice",
+			userID:              "user4",
+			sliceStart:          bucket,
+			sliceEnd:            bucket + 1,
+			expectedReason:      proto.Reason_DEFAULT,
+			expectedVariationID: "variation-B",
+		},
+		{
+			desc:                "targeted user",
+			userID:              "user1",
+			holdoutWeight:       100000,
+			sliceStart:          100000,
+			sliceEnd:            100000,
+			expectedReason:      proto.Reason_TARGET,
+			expectedVariationID: "variation-A",
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			f.LayerAssignment = &proto.LayerAssignment{
+				LayerId:             "layer-id",
+				ExperimentId:        "experiment-id",
+				HoldoutWeight:       p.holdoutWeight,
+				SliceStart:          p.sliceStart,
+				SliceEnd:            p.sliceEnd,
+				BaselineVariationId: "variation-C",
+			}
+			reason, variation, err := f.assignUser(&userproto.User{Id: p.userID}, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, p.expectedReason, reason.Type)
+			assert.Equal(t, p.expectedVariationID, variation.Id)
+		})
+	}
+}
+
+func TestLayerBucketIsSharedAcrossFeatures(t *testing.T) {
+	t.Parallel()
+	f1 := makeFeature("feature-1")
+	f2 := makeFeature("feature-2")
+	f2.SamplingSeed = "seed"
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		b1, err := f1.strategyEvaluator.bucket100000(userID, "layer-id")
+		require.NoError(t, err)
+		b2, err := f2.strategyEvaluator.bucket100000(userID, "layer-id")
+		require.NoError(t, err)
+		assert.Equal(t, b1, b2)
+		assert.True(t, b1 >= 0 && b1 <= 100000)
+	}
+}
+
+func TestSetLayerAssignment(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("test-feature")
+	err := f.SetLayerAssignment(&proto.LayerAssignment{
+		LayerId:             "layer-id",
+		ExperimentId:        "experiment-id",
+		BaselineVariationId: "variation-D",
+	})
+	assert.Equal(t, errVariationNotFound, err)
+	assert.Nil(t, f.LayerAssignment)
+	err = f.SetLayerAssignment(&proto.LayerAssignment{
+		LayerId:             "layer-id",
+		ExperimentId:        "experiment-id",
+		BaselineVariationId: "variation-A",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ErrLayerAssignmentNotFound, f.RemoveLayerAssignment("other-experiment-id"))
+	assert.NotNil(t, f.LayerAssignment)
+	assert.NoError(t, f.RemoveLayerAssignment("experiment-id"))
+	assert.Nil(t, f.LayerAssignment)
+}
+
+func TestAssignUserExperimentAllocation(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("test-feature")
+	bucket, err := f.strategyEvaluator.bucket100000("user4", "experiment-id")
+	require.NoError(t, err)
+	country := &proto.Clause{
+		Attribute: "country",
+		Operator:  proto.Clause_EQUALS,
+		Values:    []string{"jp"},
+	}
+	patterns := []struct {
+		desc                string
+		user                *userproto.User
+		trafficAllocation   int32
+		audience            []*proto.Clause
+		expectedReason      proto.Reason_Type
+		expectedVariationID string
+	}{
+		{
+			desc:                "outside traffic allocation",
+			user:                &userproto.User{Id: "user4"},
+			trafficAllocation:   bucket,
+			expectedReason:      proto.Reason_EXPERIMENT_NOT_ALLOCATED,
+			expectedVariationID: "variation-C",
+		},
+		{
+			desc:                "inside traffic allocation",
+			user:                &userproto.User{Id: "user4"},
+			trafficAllocation:   bucket + 1,
+			expectedReason:      proto.Reason_DEFAULT,
+			expectedVariationID: "variation-B",
+		},
+		{
+			desc:                "all traffic",
+			user:                &userproto.User{Id: "user4"},
+			expectedReason:      proto.Reason_DEFAULT,
+			expectedVariationID: "variation-B",
+		},
+		{
+			desc:                "outside audience",
+			user:                &userproto.User{Id: "user4", Data: map[string]string{"country": "us"}},
+			audience:            []*proto.Clause{country},
+			expectedReason:      proto.Reason_EXPERIMENT_NOT_ALLOCATED,
+			expectedVariationID: "variation-C",
+		},
+		{
+			desc:                "inside audience",
+			user:                &userproto.User{Id: "user4", Data: map[string]string{"country": "jp"}},
+			audience:            []*proto.Clause{country},
+			expectedReason:      proto.Reason_DEFAULT,
+			expectedVariationID: "variation-B",
+		},
+		{
+			desc:                "targeted user",
+			user:                &userproto.User{Id: "user1"},
+			trafficAllocation:   1,
+			audience:            []*proto.Clause{country},
+			expectedReason:      proto.Reason_TARGET,
+			expectedVariationID: "variation-A",
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			f.ExperimentAllocation = &proto.ExperimentAllocation{
+				ExperimentId:       "experiment-id",
+				TrafficAllocation:  p.trafficAllocation,
+				Audience:           p.audience,
+				ControlVariationId: "variation-C",
+			}
+			reason, variation, err := f.assignUser(p.user, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, p.expectedReason, reason.Type)
+			assert.Equal(t, p.expectedVariationID, variation.Id)
+		})
+	}
+}
+
+func TestSetExperimentAllocation(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("test-feature")
+	err := f.SetExperimentAllocation(&proto.ExperimentAllocation{
+		ExperimentId:       "experiment-id",
+		ControlVariationId: "variation-D",
+	})
+	assert.Equal(t, errVariationNotFound, err)
+	assert.Nil(t, f.ExperimentAllocation)
+	err = f.SetExperimentAllocation(&proto.ExperimentAllocation{
+		ExperimentId:       "experiment-id",
+		TrafficAllocation:  20000,
+		ControlVariationId: "variation-A",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ErrExperimentAllocationNotFound, f.RemoveExperimentAllocation("other-experiment-id"))
+	assert.NotNil(t, f.ExperimentAllocation)
+	assert.NoError(t, f.RemoveExperimentAllocation("experiment-id"))
+	assert.Nil(t, f.ExperimentAllocation)
+}
+
+func TestIsExperimentExposure(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		reason   *proto.Reason
+		expected bool
+	}{
+		{reason: nil, expected: true},
+		{reason: &proto.Reason{Type: proto.Reason_DEFAULT}, expected: true},
+		{reason: &proto.Reason{Type: proto.Reason_RULE}, expected: true},
+		{reason: &proto.Reason{Type: proto.Reason_LAYER_HOLDOUT}, expected: false},
+		{reason: &proto.Reason{Type: proto.Reason_LAYER_EXCLUDED}, expected: false},
+		{reason: &proto.Reason{Type: proto.Reason_EXPERIMENT_NOT_ALLOCATED}, expected: false},
+	}
+	for _, p := range patterns {
+		assert.Equal(t, p.expected, IsExperimentExposure(p.reason), p.reason.GetType().String())
+	}
+}
+
 func TestAssignUserRuleSet(t *testing.T) {
 	user := &userproto.User{
 		Id:   "user-id",
@@ -563,6 +803,66 @@ func TestChangeDescription(t *testing.T) {
 	assert.Equal(t, desc, f.Description)
 }
 
+func TestChangeKind(t *testing.T) {
+	f := makeFeature("test-feature")
+	f.ExpectedRemovalAt = time.Now().Unix()
+	assert.NoError(t, f.ChangeKind(proto.Feature_EXPERIMENT))
+	assert.Equal(t, proto.Feature_EXPERIMENT, f.Kind)
+	assert.NotZero(t, f.ExpectedRemovalAt)
+	assert.NoError(t, f.ChangeKind(proto.Feature_PERMISSION))
+	assert.Equal(t, proto.Feature_PERMISSION, f.Kind)
+	assert.Zero(t, f.ExpectedRemovalAt)
+}
+
+func TestChangeExpectedRemovalDate(t *testing.T) {
+	f := makeFeature("test-feature")
+	at := time.Now().Unix()
+	assert.NoError(t, f.ChangeExpectedRemovalDate(at))
+	assert.Equal(t, at, f.ExpectedRemovalAt)
+	assert.NoError(t, f.ChangeExpectedRemovalDate(0))
+	assert.Zero(t, f.ExpectedRemovalAt)
+	f.Kind = proto.Feature_OPS
+	assert.Equal(t, ErrRemovalDateOnPermanentFeature, f.ChangeExpectedRemovalDate(at))
+}
+
+func TestRemovalDate(t *testing.T) {
+	now := time.Now()
+	week := 7 * 24 * time.Hour
+	patterns := []struct {
+		desc                string
+		expectedRemovalAt   int64
+		expectedApproaching bool
+		expectedPassed      bool
+	}{
+		{
+			desc:              "not set",
+			expectedRemovalAt: 0,
+		},
+		{
+			desc:              "far",
+			expectedRemovalAt: now.Add(2 * week).Unix(),
+		},
+		{
+			desc:                "approaching",
+			expectedRemovalAt:   now.Add(week - time.Hour).Unix(),
+			expectedApproaching: true,
+		},
+		{
+			desc:              "passed",
+			expectedRemovalAt: now.Add(-time.Hour).Unix(),
+			expectedPassed:    true,
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			f := makeFeature("test-feature")
+			f.ExpectedRemovalAt = p.expectedRemovalAt
+			assert.Equal(t, p.expectedApproaching, f.IsRemovalDateApproaching(now, week))
+			assert.Equal(t, p.expectedPassed, f.IsRemovalDatePassed(now))
+		})
+	}
+}
+
 func TestAddTag(t *testing.T) {
 	tag := "test-tag"
 	f := makeFeature("test-feature")
@@ -1468,6 +1768,104 @@ func TestChangeFixedStrategy(t *testing.T) {
 	}
 }
 
+func TestUpdateBanditWeights(t *testing.T) {
+	t.Parallel()
+	weights := &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+		{Variation: "variation-A", Weight: 10000},
+		{Variation: "variation-B", Weight: 80000},
+		{Variation: "variation-C", Weight: 10000},
+	}}
+	arms := []*proto.BanditStrategy_Arm{
+		{Variation: "variation-A", Trials: 100, Conversions: 1},
+		{Variation: "variation-B", Trials: 100, Conversions: 20},
+		{Variation: "variation-C", Trials: 100, Conversions: 2},
+	}
+	bandit := func() *proto.Strategy {
+		return &proto.Strategy{
+			Type: proto.Strategy_BANDIT,
+			RolloutStrategy: &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+				{Variation: "variation-A", Weight: 34000},
+				{Variation: "variation-B", Weight: 33000},
+				{Variation: "variation-C", Weight: 33000},
+			}},
+			BanditStrategy: &proto.BanditStrategy{GoalId: "goal-id"},
+		}
+	}
+	patterns := map[string]struct {
+		setup       func(*Feature)
+		ruleID      string
+		weights     *proto.RolloutStrategy
+		expectedErr error
+	}{
+		"err: default strategy is not a bandit": {
+			weights:     weights,
+			expectedErr: ErrStrategyNotBandit,
+		},
+		"err: rule not found": {
+			ruleID:      "rule-3",
+			weights:     weights,
+			expectedErr: errRuleNotFound,
+		},
+		"err: variation not found": {
+			setup: func(f *Feature) { f.DefaultStrategy = bandit() },
+			weights: &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+				{Variation: "variation-D", Weight: 100000},
+			}},
+			expectedErr: errVariationNotFound,
+		},
+		"success: default strategy": {
+			setup:       func(f *Feature) { f.DefaultStrategy = bandit() },
+			weights:     weights,
+			expectedErr: nil,
+		},
+		"success: rule": {
+			setup:       func(f *Feature) { f.Rules[0].Strategy = bandit() },
+			ruleID:      "rule-1",
+			weights:     weights,
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			f := makeFeature("test-feature")
+			if p.setup != nil {
+				p.setup(f)
+			}
+			err := f.UpdateBanditWeights(p.ruleID, p.weights, arms, 10)
+			assert.Equal(t, p.expectedErr, err)
+			if err != nil {
+				return
+			}
+			strategy := f.DefaultStrategy
+			if p.ruleID != "" {
+				strategy = f.Rules[0].Strategy
+			}
+			assert.Equal(t, p.weights, strategy.RolloutStrategy)
+			assert.Equal(t, arms, strategy.BanditStrategy.Arms)
+			assert.Equal(t, int64(10), strategy.BanditStrategy.UpdatedAt)
+			assert.Equal(t, "goal-id", strategy.BanditStrategy.GoalId)
+		})
+	}
+}
+
+func TestAssignUserBandit(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("test-feature")
+	f.DefaultStrategy = &proto.Strategy{
+		Type: proto.Strategy_BANDIT,
+		RolloutStrategy: &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+			{Variation: "variation-A", Weight: 0},
+			{Variation: "variation-B", Weight: 0},
+			{Variation: "variation-C", Weight: 100000},
+		}},
+		BanditStrategy: &proto.BanditStrategy{GoalId: "goal-id"},
+	}
+	reason, variation, err := f.assignUser(&userproto.User{Id: "user4"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, proto.Reason_DEFAULT, reason.Type)
+	assert.Equal(t, "variation-C", variation.Id)
+}
+
 func TestChangeRolloutStrategy(t *testing.T) {
 	f := makeFeature("test-feature")
 	r := f.Rules[0]
@@ -0,0 +1,57 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var ErrFeatureIncidentAlreadyRestored = errors.New("feature: incident is already restored")
+
+type FeatureIncident struct {
+	*feature.FeatureIncident
+}
+
+func NewFeatureIncident(
+	id, tag string,
+	action feature.FeatureIncident_Action,
+	comment string,
+) *FeatureIncident {
+	return &FeatureIncident{FeatureIncident: &feature.FeatureIncident{
+		Id:        id,
+		Tag:       tag,
+		Action:    action,
+		Comment:   comment,
+		CreatedAt: time.Now().Unix(),
+	}}
+}
+
+// AddSnapshot records a copy of the feature, so it must be called before the feature is toggled.
+func (i *FeatureIncident) AddSnapshot(f *feature.Feature) {
+	i.FeatureSnapshots = append(i.FeatureSnapshots, proto.Clone(f).(*feature.Feature))
+}
+
+func (i *FeatureIncident) Restore() error {
+	if i.RestoredAt != 0 {
+		return ErrFeatureIncidentAlreadyRestored
+	}
+	i.RestoredAt = time.Now().Unix()
+	return nil
+}
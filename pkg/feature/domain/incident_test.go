@@ -0,0 +1,42 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestFeatureIncidentAddSnapshot(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("id")
+	i := NewFeatureIncident("INC-1", "payments", proto.FeatureIncident_DISABLE, "")
+	i.AddSnapshot(f.Feature)
+	require.NoError(t, f.Disable())
+	require.Len(t, i.FeatureSnapshots, 1)
+	assert.True(t, i.FeatureSnapshots[0].Enabled)
+}
+
+func TestFeatureIncidentRestore(t *testing.T) {
+	t.Parallel()
+	i := NewFeatureIncident("INC-1", "payments", proto.FeatureIncident_DISABLE, "")
+	require.NoError(t, i.Restore())
+	assert.NotZero(t, i.RestoredAt)
+	assert.Equal(t, ErrFeatureIncidentAlreadyRestored, i.Restore())
+}
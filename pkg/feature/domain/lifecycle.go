@@ -0,0 +1,196 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	"github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+type FeatureLifecyclePolicy struct {
+	*feature.FeatureLifecyclePolicy
+}
+
+// NewFeatureLifecyclePolicy returns the policy used when an environment has none. No feature qualifies under it.
+func NewFeatureLifecyclePolicy() *FeatureLifecyclePolicy {
+	now := time.Now().Unix()
+	return &FeatureLifecyclePolicy{FeatureLifecyclePolicy: &feature.FeatureLifecyclePolicy{
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+}
+
+func (p *FeatureLifecyclePolicy) Update(policy *feature.FeatureLifecyclePolicy) {
+	p.NotEvaluatedDays = policy.NotEvaluatedDays
+	p.SingleVariation = policy.SingleVariation
+	p.GracePeriodDays = policy.GracePeriodDays
+	p.UndoPeriodDays = policy.UndoPeriodDays
+	p.AutoArchive = policy.AutoArchive
+	p.ExpiredRemovalDate = policy.ExpiredRemovalDate
+	p.UpdatedAt = time.Now().Unix()
+}
+
+func (p *FeatureLifecyclePolicy) Enabled() bool {
+	return p.NotEvaluatedDays > 0 || p.SingleVariation || p.ExpiredRemovalDate
+}
+
+// Reasons returns why the feature qualifies for the cleanup. Archived and deleted features never qualify.
+func (p *FeatureLifecyclePolicy) Reasons(f *feature.Feature, now time.Time) []feature.FeatureCleanupCandidate_Reason {
+	reasons := []feature.FeatureCleanupCandidate_Reason{}
+	if f.Archived || f.Deleted {
+		return reasons
+	}
+	if p.NotEvaluatedDays > 0 {
+		// The last used info is tracked per version, so a feature that hasn't been evaluated
+		// since its last change is measured from the change.
+		lastUsedAt := f.UpdatedAt
+		if f.LastUsedInfo != nil && f.LastUsedInfo.LastUsedAt > lastUsedAt {
+			lastUsedAt = f.LastUsedInfo.LastUsedAt
+		}
+		if now.Unix()-lastUsedAt >= int64(p.NotEvaluatedDays)*secondsPerDay {
+			reasons = append(reasons, feature.FeatureCleanupCandidate_NOT_EVALUATED)
+		}
+	}
+	if p.SingleVariation {
+		fd := &Feature{Feature: f}
+		if len(fd.ServedVariations()) == 1 {
+			reasons = append(reasons, feature.FeatureCleanupCandidate_SINGLE_VARIATION)
+		}
+	}
+	if p.ExpiredRemovalDate && f.ExpectedRemovalAt > 0 && now.Unix() >= f.ExpectedRemovalAt {
+		reasons = append(reasons, feature.FeatureCleanupCandidate_EXPIRED_REMOVAL_DATE)
+	}
+	return reasons
+}
+
+// ArchiveAt returns when a warned candidate is archived, or zero if the policy doesn't archive.
+func (p *FeatureLifecyclePolicy) ArchiveAt(c *feature.FeatureCleanupCandidate) int64 {
+	if !p.AutoArchive || c.WarnedAt == 0 {
+		return 0
+	}
+	return c.WarnedAt + int64(p.GracePeriodDays)*secondsPerDay
+}
+
+// UndoDeadline returns until when a candidate archived by the policy can be restored.
+func (p *FeatureLifecyclePolicy) UndoDeadline(c *feature.FeatureCleanupCandidate) int64 {
+	if c.ArchivedAt == 0 {
+		return 0
+	}
+	return c.ArchivedAt + int64(p.UndoPeriodDays)*secondsPerDay
+}
+
+type FeatureCleanupCandidate struct {
+	*feature.FeatureCleanupCandidate
+}
+
+func NewFeatureCleanupCandidate(
+	featureID string,
+	reasons []feature.FeatureCleanupCandidate_Reason,
+) *FeatureCleanupCandidate {
+	return &FeatureCleanupCandidate{FeatureCleanupCandidate: &feature.FeatureCleanupCandidate{
+		FeatureId: featureID,
+		Reasons:   reasons,
+		Status:    feature.FeatureCleanupCandidate_PENDING,
+		UpdatedAt: time.Now().Unix(),
+	}}
+}
+
+func (c *FeatureCleanupCandidate) SetReasons(reasons []feature.FeatureCleanupCandidate_Reason) {
+	c.Reasons = reasons
+	c.UpdatedAt = time.Now().Unix()
+}
+
+func (c *FeatureCleanupCandidate) Warn(now time.Time) {
+	c.Status = feature.FeatureCleanupCandidate_WARNED
+	c.WarnedAt = now.Unix()
+	c.UpdatedAt = now.Unix()
+}
+
+func (c *FeatureCleanupCandidate) Archive(now time.Time) {
+	c.Status = feature.FeatureCleanupCandidate_ARCHIVED
+	c.ArchivedAt = now.Unix()
+	c.UpdatedAt = now.Unix()
+}
+
+// Exempt keeps the feature from qualifying again until its version changes.
+func (c *FeatureCleanupCandidate) Exempt(version int32, now time.Time) {
+	c.Status = feature.FeatureCleanupCandidate_EXEMPTED
+	c.ExemptedVersion = version
+	c.WarnedAt = 0
+	c.ArchivedAt = 0
+	c.UpdatedAt = now.Unix()
+}
+
+func (c *FeatureCleanupCandidate) Exempts(f *feature.Feature) bool {
+	return c.Status == feature.FeatureCleanupCandidate_EXEMPTED && c.ExemptedVersion == f.Version
+}
+
+// ServedVariations returns the IDs of the variations the feature can serve.
+func (f *Feature) ServedVariations() []string {
+	ids := []string{}
+	add := func(id string) {
+		if id != "" && !contains(id, ids) {
+			ids = append(ids, id)
+		}
+	}
+	if !f.Enabled {
+		add(f.OffVariation)
+		return ids
+	}
+	if len(f.Prerequisites) > 0 {
+		add(f.OffVariation)
+	}
+	for _, t := range f.Targets {
+		if len(t.Users) > 0 {
+			add(t.Variation)
+		}
+	}
+	for _, r := range f.Rules {
+		for _, id := range strategyVariations(r.Strategy) {
+			add(id)
+		}
+	}
+	for _, id := range strategyVariations(f.DefaultStrategy) {
+		add(id)
+	}
+	return ids
+}
+
+func strategyVariations(s *feature.Strategy) []string {
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case feature.Strategy_FIXED:
+		if s.FixedStrategy != nil {
+			return []string{s.FixedStrategy.Variation}
+		}
+	case feature.Strategy_ROLLOUT, feature.Strategy_BANDIT:
+		if s.RolloutStrategy == nil {
+			return nil
+		}
+		ids := []string{}
+		for _, v := range s.RolloutStrategy.Variations {
+			if v.Weight > 0 {
+				ids = append(ids, v.Variation)
+			}
+		}
+		return ids
+	}
+	return nil
+}
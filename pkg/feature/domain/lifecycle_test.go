@@ -0,0 +1,219 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestFeatureLifecyclePolicyReasons(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	daysAgo := func(days int) int64 {
+		return now.Add(-time.Duration(days) * 24 * time.Hour).Unix()
+	}
+	singleVariation := func() *Feature {
+		f := makeFeature("id")
+		f.Targets = nil
+		f.Rules = nil
+		return f
+	}
+	patterns := []struct {
+		desc     string
+		policy   *proto.FeatureLifecyclePolicy
+		feature  func() *Feature
+		expected []proto.FeatureCleanupCandidate_Reason
+	}{
+		{
+			desc:     "default policy",
+			policy:   &proto.FeatureLifecyclePolicy{},
+			feature:  singleVariation,
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:   "not evaluated since the last change",
+			policy: &proto.FeatureLifecyclePolicy{NotEvaluatedDays: 30},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.UpdatedAt = daysAgo(31)
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{proto.FeatureCleanupCandidate_NOT_EVALUATED},
+		},
+		{
+			desc:   "evaluated recently",
+			policy: &proto.FeatureLifecyclePolicy{NotEvaluatedDays: 30},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.UpdatedAt = daysAgo(31)
+				f.LastUsedInfo = &proto.FeatureLastUsedInfo{LastUsedAt: daysAgo(1)}
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:   "changed recently",
+			policy: &proto.FeatureLifecyclePolicy{NotEvaluatedDays: 30},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.UpdatedAt = daysAgo(1)
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:     "single variation",
+			policy:   &proto.FeatureLifecyclePolicy{SingleVariation: true},
+			feature:  singleVariation,
+			expected: []proto.FeatureCleanupCandidate_Reason{proto.FeatureCleanupCandidate_SINGLE_VARIATION},
+		},
+		{
+			desc:   "disabled",
+			policy: &proto.FeatureLifecyclePolicy{SingleVariation: true},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.Enabled = false
+				f.OffVariation = "variation-C"
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{proto.FeatureCleanupCandidate_SINGLE_VARIATION},
+		},
+		{
+			desc:     "multiple variations",
+			policy:   &proto.FeatureLifecyclePolicy{SingleVariation: true},
+			feature:  func() *Feature { return makeFeature("id") },
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:   "both",
+			policy: &proto.FeatureLifecyclePolicy{NotEvaluatedDays: 30, SingleVariation: true},
+			feature: func() *Feature {
+				f := singleVariation()
+				f.UpdatedAt = daysAgo(31)
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{
+				proto.FeatureCleanupCandidate_NOT_EVALUATED,
+				proto.FeatureCleanupCandidate_SINGLE_VARIATION,
+			},
+		},
+		{
+			desc:   "expired removal date",
+			policy: &proto.FeatureLifecyclePolicy{ExpiredRemovalDate: true},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.ExpectedRemovalAt = daysAgo(1)
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{proto.FeatureCleanupCandidate_EXPIRED_REMOVAL_DATE},
+		},
+		{
+			desc:   "removal date not reached",
+			policy: &proto.FeatureLifecyclePolicy{ExpiredRemovalDate: true},
+			feature: func() *Feature {
+				f := makeFeature("id")
+				f.ExpectedRemovalAt = daysAgo(-1)
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:     "removal date not set",
+			policy:   &proto.FeatureLifecyclePolicy{ExpiredRemovalDate: true},
+			feature:  func() *Feature { return makeFeature("id") },
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+		{
+			desc:   "archived",
+			policy: &proto.FeatureLifecyclePolicy{NotEvaluatedDays: 30, SingleVariation: true},
+			feature: func() *Feature {
+				f := singleVariation()
+				f.UpdatedAt = daysAgo(31)
+				f.Archived = true
+				return f
+			},
+			expected: []proto.FeatureCleanupCandidate_Reason{},
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			policy := &FeatureLifecyclePolicy{FeatureLifecyclePolicy: p.policy}
+			assert.Equal(t, p.expected, policy.Reasons(p.feature().Feature, now))
+		})
+	}
+}
+
+func TestFeatureLifecyclePolicyDeadlines(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	policy := &FeatureLifecyclePolicy{FeatureLifecyclePolicy: &proto.FeatureLifecyclePolicy{
+		NotEvaluatedDays: 30,
+		GracePeriodDays:  7,
+		UndoPeriodDays:   3,
+	}}
+	c := NewFeatureCleanupCandidate("id", []proto.FeatureCleanupCandidate_Reason{
+		proto.FeatureCleanupCandidate_NOT_EVALUATED,
+	})
+	assert.Equal(t, proto.FeatureCleanupCandidate_PENDING, c.Status)
+	c.Warn(now)
+	assert.Equal(t, proto.FeatureCleanupCandidate_WARNED, c.Status)
+	assert.Equal(t, int64(0), policy.ArchiveAt(c.FeatureCleanupCandidate))
+	policy.AutoArchive = true
+	assert.Equal(t, now.Unix()+7*secondsPerDay, policy.ArchiveAt(c.FeatureCleanupCandidate))
+	assert.Equal(t, int64(0), policy.UndoDeadline(c.FeatureCleanupCandidate))
+	c.Archive(now)
+	assert.Equal(t, proto.FeatureCleanupCandidate_ARCHIVED, c.Status)
+	assert.Equal(t, now.Unix()+3*secondsPerDay, policy.UndoDeadline(c.FeatureCleanupCandidate))
+}
+
+func TestFeatureCleanupCandidateExempts(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	c := NewFeatureCleanupCandidate("id", []proto.FeatureCleanupCandidate_Reason{
+		proto.FeatureCleanupCandidate_SINGLE_VARIATION,
+	})
+	c.Warn(now)
+	c.Archive(now)
+	assert.False(t, c.Exempts(&proto.Feature{Id: "id", Version: 3}))
+	c.Exempt(3, now)
+	assert.Equal(t, proto.FeatureCleanupCandidate_EXEMPTED, c.Status)
+	assert.Equal(t, int64(0), c.WarnedAt)
+	assert.Equal(t, int64(0), c.ArchivedAt)
+	assert.True(t, c.Exempts(&proto.Feature{Id: "id", Version: 3}))
+	assert.False(t, c.Exempts(&proto.Feature{Id: "id", Version: 4}))
+}
+
+func TestServedVariations(t *testing.T) {
+	t.Parallel()
+	f := makeFeature("id")
+	assert.ElementsMatch(t, []string{"variation-A", "variation-B", "variation-C"}, f.ServedVariations())
+	f.Targets = nil
+	f.Rules[1].Strategy = &proto.Strategy{
+		Type: proto.Strategy_ROLLOUT,
+		RolloutStrategy: &proto.RolloutStrategy{Variations: []*proto.RolloutStrategy_Variation{
+			{Variation: "variation-A", Weight: 100000},
+			{Variation: "variation-C", Weight: 0},
+		}},
+	}
+	assert.ElementsMatch(t, []string{"variation-A", "variation-B"}, f.ServedVariations())
+	f.Prerequisites = []*proto.Prerequisite{{FeatureId: "pre", VariationId: "pre-variation"}}
+	f.OffVariation = "variation-C"
+	assert.ElementsMatch(t, []string{"variation-A", "variation-B", "variation-C"}, f.ServedVariations())
+}
@@ -0,0 +1,483 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+// LintFeature statically analyzes the targeting of a feature.
+// The segments and prerequisites are the ones found in the environment, keyed by ID.
+func LintFeature(
+	f *feature.Feature,
+	segments map[string]*feature.Segment,
+	prerequisites map[string]*feature.Feature,
+) []*feature.LintFinding {
+	findings := []*feature.LintFinding{}
+	findings = append(findings, lintRules(f.Rules)...)
+	findings = append(findings, lintSegments(f.Rules, segments)...)
+	findings = append(findings, lintPrerequisites(f.Prerequisites, prerequisites)...)
+	return findings
+}
+
+// numericAssumption qualifies the findings that only hold for numeric attributes.
+// The evaluator compares the GREATER and LESS clauses as semver or strings when the attribute isn't a number,
+// and those orders can satisfy bounds that are disjoint on numbers.
+const numericAssumption = ", assuming the attribute is numeric"
+
+func lintRules(rules []*feature.Rule) []*feature.LintFinding {
+	findings := []*feature.LintFinding{}
+	constraints := make([]map[string]*attributeConstraint, 0, len(rules))
+	contradictory := make([]bool, 0, len(rules))
+	for _, r := range rules {
+		cs := newAttributeConstraints(r)
+		constraints = append(constraints, cs)
+		attribute, ok, assumed := findContradiction(cs)
+		contradictory = append(contradictory, ok)
+		if ok {
+			severity, message := feature.LintFinding_WARNING,
+				fmt.Sprintf("the clauses on the attribute %q can never match together", attribute)
+			if assumed {
+				severity, message = feature.LintFinding_INFO, message+numericAssumption
+			}
+			findings = append(findings, &feature.LintFinding{
+				Type:     feature.LintFinding_CONTRADICTORY_CLAUSES,
+				Severity: severity,
+				RuleId:   r.Id,
+				Message:  message,
+			})
+		}
+	}
+	for j, r := range rules {
+		if contradictory[j] {
+			continue
+		}
+		for i := 0; i < j; i++ {
+			if contradictory[i] {
+				continue
+			}
+			ok, assumed := covers(rules[i], constraints[j])
+			if !ok {
+				continue
+			}
+			severity, message := feature.LintFinding_WARNING, fmt.Sprintf(
+				"the rule can never match because the rule %s matches the same users first",
+				rules[i].Id,
+			)
+			if assumed {
+				severity, message = feature.LintFinding_INFO, message+numericAssumption
+			}
+			findings = append(findings, &feature.LintFinding{
+				Type:        feature.LintFinding_SHADOWED_RULE,
+				Severity:    severity,
+				RuleId:      r.Id,
+				ReferenceId: rules[i].Id,
+				Message:     message,
+			})
+			break
+		}
+	}
+	return findings
+}
+
+func lintSegments(rules []*feature.Rule, segments map[string]*feature.Segment) []*feature.LintFinding {
+	findings := []*feature.LintFinding{}
+	for _, r := range rules {
+		checked := map[string]bool{}
+		for _, c := range r.Clauses {
+			if c.Operator != feature.Clause_SEGMENT {
+				continue
+			}
+			for _, id := range c.Values {
+				if checked[id] {
+					continue
+				}
+				checked[id] = true
+				s, ok := segments[id]
+				if !ok {
+					findings = append(findings, &feature.LintFinding{
+						Type:        feature.LintFinding_MISSING_SEGMENT,
+						Severity:    feature.LintFinding_ERROR,
+						RuleId:      r.Id,
+						ReferenceId: id,
+						Message:     "the segment doesn't exist",
+					})
+					continue
+				}
+				if s.IncludedUserCount == 0 {
+					findings = append(findings, &feature.LintFinding{
+						Type:        feature.LintFinding_EMPTY_SEGMENT,
+						Severity:    feature.LintFinding_WARNING,
+						RuleId:      r.Id,
+						ReferenceId: id,
+						Message:     fmt.Sprintf("the segment %q has no users", s.Name),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func lintPrerequisites(
+	ps []*feature.Prerequisite,
+	prerequisites map[string]*feature.Feature,
+) []*feature.LintFinding {
+	findings := []*feature.LintFinding{}
+	for _, p := range ps {
+		f, ok := prerequisites[p.FeatureId]
+		if !ok || f.Deleted {
+			findings = append(findings, &feature.LintFinding{
+				Type:        feature.LintFinding_MISSING_PREREQUISITE,
+				Severity:    feature.LintFinding_ERROR,
+				ReferenceId: p.FeatureId,
+				Message:     "the prerequisite feature doesn't exist",
+			})
+			continue
+		}
+		if f.Archived {
+			findings = append(findings, &feature.LintFinding{
+				Type:        feature.LintFinding_ARCHIVED_PREREQUISITE,
+				Severity:    feature.LintFinding_WARNING,
+				ReferenceId: p.FeatureId,
+				Message:     fmt.Sprintf("the prerequisite feature %q is archived", f.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// attributeConstraint gathers what a rule requires from one user attribute.
+// Each clause matches when any of its values matches, and a rule matches when all its clauses match.
+type attributeConstraint struct {
+	// values is the intersection of the EQUALS and IN clauses. It is only used when hasValues is true.
+	values    []string
+	hasValues bool
+	interval  interval
+	// numeric is true when the interval comes from a GREATER or LESS clause,
+	// whose bounds only hold if the attribute is a number.
+	numeric  bool
+	prefixes [][]string
+	suffixes [][]string
+	segments [][]string
+}
+
+func newAttributeConstraints(r *feature.Rule) map[string]*attributeConstraint {
+	constraints := map[string]*attributeConstraint{}
+	for _, c := range r.Clauses {
+		ac, ok := constraints[c.Attribute]
+		if !ok {
+			ac = &attributeConstraint{}
+			constraints[c.Attribute] = ac
+		}
+		switch c.Operator {
+		case feature.Clause_EQUALS, feature.Clause_IN:
+			if !ac.hasValues {
+				ac.values = uniqueValues(c.Values)
+				ac.hasValues = true
+			} else {
+				ac.values = intersectValues(ac.values, c.Values)
+			}
+		case feature.Clause_STARTS_WITH:
+			ac.prefixes = append(ac.prefixes, c.Values)
+		case feature.Clause_ENDS_WITH:
+			ac.suffixes = append(ac.suffixes, c.Values)
+		case feature.Clause_SEGMENT:
+			ac.segments = append(ac.segments, c.Values)
+		default:
+			if i, ok := clauseInterval(c); ok {
+				ac.interval = ac.interval.intersect(i)
+				ac.numeric = ac.numeric || assumesNumber(c)
+			}
+		}
+	}
+	return constraints
+}
+
+// findContradiction returns the first attribute whose constraints can't be satisfied,
+// and whether that only holds for a numeric attribute.
+// A contradiction that holds for any attribute is returned first.
+func findContradiction(constraints map[string]*attributeConstraint) (string, bool, bool) {
+	assumedAttribute := ""
+	for attribute, ac := range constraints {
+		if ac.hasValues && len(ac.candidates()) == 0 {
+			return attribute, true, false
+		}
+		if ac.interval.empty() {
+			if !ac.numeric {
+				return attribute, true, false
+			}
+			if assumedAttribute == "" || attribute < assumedAttribute {
+				assumedAttribute = attribute
+			}
+		}
+	}
+	if assumedAttribute != "" {
+		return assumedAttribute, true, true
+	}
+	return "", false, false
+}
+
+// candidates returns the EQUALS and IN values that also satisfy the other clauses.
+func (ac *attributeConstraint) candidates() []string {
+	candidates := []string{}
+	for _, v := range ac.values {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && !ac.interval.contains(f) {
+			continue
+		}
+		if !matchesAll(v, ac.prefixes, strings.HasPrefix) || !matchesAll(v, ac.suffixes, strings.HasSuffix) {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	return candidates
+}
+
+// covers reports whether every user matching the constraints of a rule also matches the given rule,
+// and whether that only holds for numeric attributes.
+func covers(r *feature.Rule, constraints map[string]*attributeConstraint) (bool, bool) {
+	assumed := false
+	for _, c := range r.Clauses {
+		ac, ok := constraints[c.Attribute]
+		if !ok {
+			return false, false
+		}
+		ok, a := implies(ac, c)
+		if !ok {
+			return false, false
+		}
+		assumed = assumed || a
+	}
+	return true, assumed
+}
+
+func implies(ac *attributeConstraint, c *feature.Clause) (bool, bool) {
+	switch c.Operator {
+	case feature.Clause_EQUALS, feature.Clause_IN:
+		return ac.hasValues && isSubset(ac.candidates(), c.Values), false
+	case feature.Clause_STARTS_WITH:
+		return impliesAffix(ac, ac.prefixes, c.Values, strings.HasPrefix), false
+	case feature.Clause_ENDS_WITH:
+		return impliesAffix(ac, ac.suffixes, c.Values, strings.HasSuffix), false
+	case feature.Clause_SEGMENT:
+		for _, s := range ac.segments {
+			if isSubset(s, c.Values) {
+				return true, false
+			}
+		}
+		return false, false
+	default:
+		i, ok := clauseInterval(c)
+		if !ok {
+			return false, false
+		}
+		// The candidates are numbers compared with numbers, so the evaluator compares them numerically.
+		if ac.hasValues && allWithin(ac.candidates(), i) {
+			return true, false
+		}
+		if ac.interval.within(i) {
+			return true, ac.numeric || assumesNumber(c)
+		}
+		return false, false
+	}
+}
+
+func allWithin(values []string, i interval) bool {
+	for _, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || !i.contains(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func impliesAffix(
+	ac *attributeConstraint,
+	groups [][]string,
+	affixes []string,
+	match func(s, affix string) bool,
+) bool {
+	if ac.hasValues && allMatch(ac.candidates(), affixes, match) {
+		return true
+	}
+	for _, g := range groups {
+		if allMatch(g, affixes, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// allMatch reports whether each value matches at least one of the affixes.
+func allMatch(values, affixes []string, match func(s, affix string) bool) bool {
+	for _, v := range values {
+		if !matchesAny(v, affixes, match) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAll(value string, groups [][]string, match func(s, affix string) bool) bool {
+	for _, g := range groups {
+		if !matchesAny(value, g, match) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(value string, affixes []string, match func(s, affix string) bool) bool {
+	for _, a := range affixes {
+		if match(value, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueValues(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !contains(v, unique) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func intersectValues(a, b []string) []string {
+	values := []string{}
+	for _, v := range a {
+		if contains(v, b) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func isSubset(a, b []string) bool {
+	for _, v := range a {
+		if !contains(v, b) {
+			return false
+		}
+	}
+	return true
+}
+
+type bound struct {
+	value     float64
+	inclusive bool
+}
+
+// interval is the range of numbers a user attribute can take. A nil bound is unbounded.
+type interval struct {
+	lower *bound
+	upper *bound
+}
+
+// clauseInterval returns the range matched by a comparison clause.
+// It fails when a value isn't a number, because the values are then compared as semver or strings.
+func clauseInterval(c *feature.Clause) (interval, bool) {
+	if len(c.Values) == 0 {
+		return interval{}, false
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range c.Values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return interval{}, false
+		}
+		min = math.Min(min, f)
+		max = math.Max(max, f)
+	}
+	switch c.Operator {
+	case feature.Clause_GREATER, feature.Clause_AFTER:
+		return interval{lower: &bound{value: min}}, true
+	case feature.Clause_GREATER_OR_EQUAL:
+		return interval{lower: &bound{value: min, inclusive: true}}, true
+	case feature.Clause_LESS, feature.Clause_BEFORE:
+		return interval{upper: &bound{value: max}}, true
+	case feature.Clause_LESS_OR_EQUAL:
+		return interval{upper: &bound{value: max, inclusive: true}}, true
+	}
+	return interval{}, false
+}
+
+// assumesNumber reports whether the interval of a comparison clause only holds for a numeric attribute.
+// BEFORE and AFTER never match an attribute that isn't an integer, so their interval always holds.
+func assumesNumber(c *feature.Clause) bool {
+	switch c.Operator {
+	case feature.Clause_GREATER, feature.Clause_GREATER_OR_EQUAL, feature.Clause_LESS, feature.Clause_LESS_OR_EQUAL:
+		return true
+	}
+	return false
+}
+
+func (i interval) intersect(o interval) interval {
+	result := i
+	if o.lower != nil && (result.lower == nil || o.lower.value > result.lower.value ||
+		(o.lower.value == result.lower.value && !o.lower.inclusive)) {
+		result.lower = o.lower
+	}
+	if o.upper != nil && (result.upper == nil || o.upper.value < result.upper.value ||
+		(o.upper.value == result.upper.value && !o.upper.inclusive)) {
+		result.upper = o.upper
+	}
+	return result
+}
+
+func (i interval) empty() bool {
+	if i.lower == nil || i.upper == nil {
+		return false
+	}
+	if i.lower.value == i.upper.value {
+		return !i.lower.inclusive || !i.upper.inclusive
+	}
+	return i.lower.value > i.upper.value
+}
+
+func (i interval) contains(v float64) bool {
+	if i.lower != nil && (v < i.lower.value || (v == i.lower.value && !i.lower.inclusive)) {
+		return false
+	}
+	if i.upper != nil && (v > i.upper.value || (v == i.upper.value && !i.upper.inclusive)) {
+		return false
+	}
+	return true
+}
+
+// within reports whether the interval is included in the other one.
+func (i interval) within(o interval) bool {
+	if o.lower != nil {
+		if i.lower == nil || i.lower.value < o.lower.value ||
+			(i.lower.value == o.lower.value && i.lower.inclusive && !o.lower.inclusive) {
+			return false
+		}
+	}
+	if o.upper != nil {
+		if i.upper == nil || i.upper.value > o.upper.value ||
+			(i.upper.value == o.upper.value && i.upper.inclusive && !o.upper.inclusive) {
+			return false
+		}
+	}
+	return true
+}
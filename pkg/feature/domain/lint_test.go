@@ -0,0 +1,251 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestLintRules(t *testing.T) {
+	t.Parallel()
+	clause := func(attribute string, operator feature.Clause_Operator, values ...string) *feature.Clause {
+		return &feature.Clause{Attribute: attribute, Operator: operator, Values: values}
+	}
+	rule := func(id string, clauses ...*feature.Clause) *feature.Rule {
+		return &feature.Rule{Id: id, Clauses: clauses}
+	}
+	patterns := []struct {
+		desc     string
+		rules    []*feature.Rule
+		expected []*feature.LintFinding
+	}{
+		{
+			desc: "no findings",
+			rules: []*feature.Rule{
+				rule("rule-1", clause("age", feature.Clause_GREATER, "10")),
+				rule("rule-2", clause("age", feature.Clause_LESS, "5")),
+			},
+			expected: []*feature.LintFinding{},
+		},
+		{
+			desc: "contradictory range",
+			rules: []*feature.Rule{
+				rule(
+					"rule-1",
+					clause("age", feature.Clause_GREATER, "10"),
+					clause("age", feature.Clause_LESS, "5"),
+				),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:     feature.LintFinding_CONTRADICTORY_CLAUSES,
+					Severity: feature.LintFinding_INFO,
+					RuleId:   "rule-1",
+					Message:  `the clauses on the attribute "age" can never match together, assuming the attribute is numeric`,
+				},
+			},
+		},
+		{
+			desc: "contradictory dates",
+			rules: []*feature.Rule{
+				rule(
+					"rule-1",
+					clause("signed_up_at", feature.Clause_AFTER, "1650000000"),
+					clause("signed_up_at", feature.Clause_BEFORE, "1640000000"),
+				),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:     feature.LintFinding_CONTRADICTORY_CLAUSES,
+					Severity: feature.LintFinding_WARNING,
+					RuleId:   "rule-1",
+					Message:  `the clauses on the attribute "signed_up_at" can never match together`,
+				},
+			},
+		},
+		{
+			desc: "contradictory equality",
+			rules: []*feature.Rule{
+				rule(
+					"rule-1",
+					clause("country", feature.Clause_IN, "jp", "us"),
+					clause("country", feature.Clause_STARTS_WITH, "f"),
+				),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:     feature.LintFinding_CONTRADICTORY_CLAUSES,
+					Severity: feature.LintFinding_WARNING,
+					RuleId:   "rule-1",
+					Message:  `the clauses on the attribute "country" can never match together`,
+				},
+			},
+		},
+		{
+			desc: "inclusive bounds are not contradictory",
+			rules: []*feature.Rule{
+				rule(
+					"rule-1",
+					clause("age", feature.Clause_GREATER_OR_EQUAL, "10"),
+					clause("age", feature.Clause_LESS_OR_EQUAL, "10"),
+				),
+			},
+			expected: []*feature.LintFinding{},
+		},
+		{
+			desc: "shadowed by a wider range",
+			rules: []*feature.Rule{
+				rule("rule-1", clause("age", feature.Clause_GREATER, "10")),
+				rule(
+					"rule-2",
+					clause("age", feature.Clause_GREATER_OR_EQUAL, "20"),
+					clause("country", feature.Clause_EQUALS, "jp"),
+				),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:        feature.LintFinding_SHADOWED_RULE,
+					Severity:    feature.LintFinding_INFO,
+					RuleId:      "rule-2",
+					ReferenceId: "rule-1",
+					Message: "the rule can never match because the rule rule-1 matches the same users first, " +
+						"assuming the attribute is numeric",
+				},
+			},
+		},
+		{
+			desc: "shadowed by a range containing the values",
+			rules: []*feature.Rule{
+				rule("rule-1", clause("age", feature.Clause_GREATER, "10")),
+				rule("rule-2", clause("age", feature.Clause_IN, "20", "30")),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:        feature.LintFinding_SHADOWED_RULE,
+					Severity:    feature.LintFinding_WARNING,
+					RuleId:      "rule-2",
+					ReferenceId: "rule-1",
+					Message:     "the rule can never match because the rule rule-1 matches the same users first",
+				},
+			},
+		},
+		{
+			desc: "shadowed by values and prefixes",
+			rules: []*feature.Rule{
+				rule("rule-1", clause("email", feature.Clause_ENDS_WITH, "@example.com")),
+				rule("rule-2", clause("email", feature.Clause_IN, "a@example.com", "b@example.com")),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:        feature.LintFinding_SHADOWED_RULE,
+					Severity:    feature.LintFinding_WARNING,
+					RuleId:      "rule-2",
+					ReferenceId: "rule-1",
+					Message:     "the rule can never match because the rule rule-1 matches the same users first",
+				},
+			},
+		},
+		{
+			desc: "not shadowed when the later rule is wider",
+			rules: []*feature.Rule{
+				rule("rule-1", clause("country", feature.Clause_IN, "jp")),
+				rule("rule-2", clause("country", feature.Clause_IN, "jp", "us")),
+				rule("rule-3", clause("segment", feature.Clause_SEGMENT, "segment-1")),
+			},
+			expected: []*feature.LintFinding{},
+		},
+		{
+			desc: "shadowed by a rule without clauses",
+			rules: []*feature.Rule{
+				rule("rule-1"),
+				rule("rule-2", clause("", feature.Clause_SEGMENT, "segment-1")),
+			},
+			expected: []*feature.LintFinding{
+				{
+					Type:        feature.LintFinding_SHADOWED_RULE,
+					Severity:    feature.LintFinding_WARNING,
+					RuleId:      "rule-2",
+					ReferenceId: "rule-1",
+					Message:     "the rule can never match because the rule rule-1 matches the same users first",
+				},
+			},
+		},
+	}
+	for _, p := range patterns {
+		t.Run(p.desc, func(t *testing.T) {
+			assert.Equal(t, p.expected, lintRules(p.rules))
+		})
+	}
+}
+
+func TestLintFeatureReferences(t *testing.T) {
+	t.Parallel()
+	f := &feature.Feature{
+		Rules: []*feature.Rule{
+			{
+				Id: "rule-1",
+				Clauses: []*feature.Clause{
+					{Operator: feature.Clause_SEGMENT, Values: []string{"segment-1", "segment-2", "segment-3"}},
+				},
+			},
+		},
+		Prerequisites: []*feature.Prerequisite{
+			{FeatureId: "feature-1"},
+			{FeatureId: "feature-2"},
+			{FeatureId: "feature-3"},
+		},
+	}
+	segments := map[string]*feature.Segment{
+		"segment-1": {Id: "segment-1", Name: "empty"},
+		"segment-2": {Id: "segment-2", IncludedUserCount: 1},
+	}
+	prerequisites := map[string]*feature.Feature{
+		"feature-1": {Id: "feature-1", Name: "archived", Archived: true},
+		"feature-2": {Id: "feature-2"},
+	}
+	expected := []*feature.LintFinding{
+		{
+			Type:        feature.LintFinding_EMPTY_SEGMENT,
+			Severity:    feature.LintFinding_WARNING,
+			RuleId:      "rule-1",
+			ReferenceId: "segment-1",
+			Message:     `the segment "empty" has no users`,
+		},
+		{
+			Type:        feature.LintFinding_MISSING_SEGMENT,
+			Severity:    feature.LintFinding_ERROR,
+			RuleId:      "rule-1",
+			ReferenceId: "segment-3",
+			Message:     "the segment doesn't exist",
+		},
+		{
+			Type:        feature.LintFinding_ARCHIVED_PREREQUISITE,
+			Severity:    feature.LintFinding_WARNING,
+			ReferenceId: "feature-1",
+			Message:     `the prerequisite feature "archived" is archived`,
+		},
+		{
+			Type:        feature.LintFinding_MISSING_PREREQUISITE,
+			Severity:    feature.LintFinding_ERROR,
+			ReferenceId: "feature-3",
+			Message:     "the prerequisite feature doesn't exist",
+		},
+	}
+	assert.Equal(t, expected, LintFeature(f, segments, prerequisites))
+}
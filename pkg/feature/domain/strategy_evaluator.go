@@ -38,7 +38,7 @@ func (e *strategyEvaluator) Evaluate(
 	switch strategy.Type {
 	case feature.Strategy_FIXED:
 		return findVariation(strategy.FixedStrategy.Variation, variations)
-	case feature.Strategy_ROLLOUT:
+	case feature.Strategy_ROLLOUT, feature.Strategy_BANDIT:
 		variationID, err := e.rollout(strategy.RolloutStrategy, userID, featureID, samplingSeed)
 		if err != nil {
 			return nil, err
@@ -76,6 +76,15 @@ func (e *strategyEvaluator) bucket(userID string, featureID string, samplingSeed
 	return float64(intVal) / max, nil
 }
 
+// bucket100000 places the user in the key's hash space, out of 100000.
+func (e *strategyEvaluator) bucket100000(userID, key string) (int32, error) {
+	bucket, err := e.bucket(userID, key, "")
+	if err != nil {
+		return 0, err
+	}
+	return int32(bucket * 100000), nil
+}
+
 func (e *strategyEvaluator) hash(userID string, featureID string, samplingSeed string) [16]byte {
 	// concat feature test id and user id
 	// TODO: explain why this makes sense? Why does it make sense to add 'prerequisit' key here?
@@ -22,19 +22,25 @@ import (
 
 	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
 	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 	proto "github.com/bucketeer-io/bucketeer/proto/feature"
 )
 
+// maxFeatureVersions is the number of the latest versions kept for a feature to be rolled back.
+const maxFeatureVersions = 10
+
 var (
 	ErrFeatureAlreadyExists          = errors.New("feature: already exists")
 	ErrFeatureNotFound               = errors.New("feature: not found")
 	ErrFeatureUnexpectedAffectedRows = errors.New("feature: unexpected affected rows")
+	ErrFeatureVersionNotFound        = errors.New("feature: version not found")
 )
 
 type FeatureStorage interface {
 	CreateFeature(ctx context.Context, feature *domain.Feature, environmentNamespace string) error
 	UpdateFeature(ctx context.Context, feature *domain.Feature, environmentNamespace string) error
 	GetFeature(ctx context.Context, key, environmentNamespace string) (*domain.Feature, error)
+	GetFeatureVersion(ctx context.Context, key string, version int32, environmentNamespace string) (*domain.Feature, error)
 	ListFeatures(
 		ctx context.Context,
 		whereParts []mysql.WherePart,
@@ -85,11 +91,15 @@ func (s *featureStorage) CreateFeature(
 			maintainer,
 			sampling_seed,
 			prerequisites,
+			kind,
+			expected_removal_at,
+			layer_assignment,
+			experiment_allocation,
 			environment_namespace
 		) VALUES (
 			?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
 			?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
-			?, ?
+			?, ?, ?, ?, ?, ?
 		)
 	`
 	_, err := s.qe.ExecContext(
@@ -116,6 +126,10 @@ func (s *featureStorage) CreateFeature(
 		feature.Maintainer,
 		feature.SamplingSeed,
 		mysql.JSONObject{Val: feature.Prerequisites},
+		int32(feature.Kind),
+		feature.ExpectedRemovalAt,
+		mysql.JSONObject{Val: feature.LayerAssignment},
+		mysql.JSONObject{Val: feature.ExperimentAllocation},
 		environmentNamespace,
 	)
 	if err != nil {
@@ -124,7 +138,7 @@ func (s *featureStorage) CreateFeature(
 		}
 		return err
 	}
-	return nil
+	return s.putFeatureVersion(ctx, feature, environmentNamespace)
 }
 
 func (s *featureStorage) UpdateFeature(
@@ -155,7 +169,11 @@ func (s *featureStorage) UpdateFeature(
 			tags = ?,
 			maintainer = ?,
 			sampling_seed = ?,
-			prerequisites = ?
+			prerequisites = ?,
+			kind = ?,
+			expected_removal_at = ?,
+			layer_assignment = ?,
+			experiment_allocation = ?
 		WHERE
 			id = ? AND
 			environment_namespace = ?
@@ -183,6 +201,10 @@ func (s *featureStorage) UpdateFeature(
 		feature.Maintainer,
 		feature.SamplingSeed,
 		mysql.JSONObject{Val: feature.Prerequisites},
+		int32(feature.Kind),
+		feature.ExpectedRemovalAt,
+		mysql.JSONObject{Val: feature.LayerAssignment},
+		mysql.JSONObject{Val: feature.ExperimentAllocation},
 		feature.Id,
 		environmentNamespace,
 	)
@@ -196,7 +218,121 @@ func (s *featureStorage) UpdateFeature(
 	if rowsAffected != 1 {
 		return ErrFeatureUnexpectedAffectedRows
 	}
-	return nil
+	return s.putFeatureVersion(ctx, feature, environmentNamespace)
+}
+
+// putFeatureVersion keeps the feature as of its version, so that the auto ops rule rolling it back
+// can restore the version before the change that triggered the rule.
+// Only the latest versions of the features with a rollback rule are kept.
+// It runs in the same transaction as the feature is saved in.
+func (s *featureStorage) putFeatureVersion(
+	ctx context.Context,
+	feature *domain.Feature,
+	environmentNamespace string,
+) error {
+	var rollbackable bool
+	query := `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				auto_ops_rule
+			WHERE
+				feature_id = ? AND
+				ops_type = ? AND
+				deleted = ? AND
+				environment_namespace = ?
+		)
+	`
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		feature.Id,
+		int32(autoopsproto.OpsType_ROLLBACK_FEATURE),
+		false,
+		environmentNamespace,
+	).Scan(&rollbackable)
+	if err != nil {
+		return err
+	}
+	if !rollbackable {
+		return nil
+	}
+	query = `
+		INSERT INTO feature_version (
+			feature_id,
+			version,
+			feature,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?
+		) ON DUPLICATE KEY UPDATE
+			feature = VALUES(feature)
+	`
+	_, err = s.qe.ExecContext(
+		ctx,
+		query,
+		feature.Id,
+		feature.Version,
+		mysql.JSONObject{Val: feature.Feature},
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	query = `
+		DELETE FROM
+			feature_version
+		WHERE
+			feature_id = ? AND
+			version <= ? AND
+			environment_namespace = ?
+	`
+	_, err = s.qe.ExecContext(
+		ctx,
+		query,
+		feature.Id,
+		feature.Version-maxFeatureVersions,
+		environmentNamespace,
+	)
+	return err
+}
+
+// GetFeatureVersion returns the feature as of the version.
+// Only the latest versions saved while the feature has a rollback rule are available.
+func (s *featureStorage) GetFeatureVersion(
+	ctx context.Context,
+	key string,
+	version int32,
+	environmentNamespace string,
+) (*domain.Feature, error) {
+	feature := proto.Feature{}
+	query := `
+		SELECT
+			feature
+		FROM
+			feature_version
+		WHERE
+			feature_id = ? AND
+			version = ? AND
+			environment_namespace = ?
+	`
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		key,
+		version,
+		environmentNamespace,
+	).Scan(
+		&mysql.JSONObject{Val: &feature},
+	)
+	if err != nil {
+		if err == mysql.ErrNoRows {
+			return nil, ErrFeatureVersionNotFound
+		}
+		return nil, err
+	}
+	return &domain.Feature{Feature: &feature}, nil
 }
 
 func (s *featureStorage) GetFeature(
@@ -226,7 +362,11 @@ func (s *featureStorage) GetFeature(
 			tags,
 			maintainer,
 			sampling_seed,
-			prerequisites
+			prerequisites,
+			kind,
+			expected_removal_at,
+			layer_assignment,
+			experiment_allocation
 		FROM
 			feature
 		WHERE
@@ -259,7 +399,11 @@ func (s *featureStorage) GetFeature(
 		&mysql.JSONObject{Val: &feature.Tags},
 		&feature.Maintainer,
 		&feature.SamplingSeed,
-		&mysql.JSONObject{Val: feature.Prerequisites},
+		&mysql.JSONObject{Val: &feature.Prerequisites},
+		&feature.Kind,
+		&feature.ExpectedRemovalAt,
+		&mysql.JSONObject{Val: &feature.LayerAssignment},
+		&mysql.JSONObject{Val: &feature.ExperimentAllocation},
 	)
 	if err != nil {
 		if err == mysql.ErrNoRows {
@@ -301,7 +445,11 @@ func (s *featureStorage) ListFeatures(
 			tags,
 			maintainer,
 			sampling_seed,
-			prerequisites
+			prerequisites,
+			kind,
+			expected_removal_at,
+			layer_assignment,
+			experiment_allocation
 		FROM
 			feature
 		%s %s %s
@@ -336,7 +484,11 @@ func (s *featureStorage) ListFeatures(
 			&mysql.JSONObject{Val: &feature.Tags},
 			&feature.Maintainer,
 			&feature.SamplingSeed,
-			&mysql.JSONObject{Val: feature.Prerequisites},
+			&mysql.JSONObject{Val: &feature.Prerequisites},
+			&feature.Kind,
+			&feature.ExpectedRemovalAt,
+			&mysql.JSONObject{Val: &feature.LayerAssignment},
+			&mysql.JSONObject{Val: &feature.ExperimentAllocation},
 		)
 		if err != nil {
 			return nil, 0, 0, err
@@ -394,7 +546,11 @@ func (s *featureStorage) ListFeaturesFilteredByExperiment(
 			feature.tags,
 			feature.maintainer,
 			feature.sampling_seed,
-			feature.prerequisites
+			feature.prerequisites,
+			feature.kind,
+			feature.expected_removal_at,
+			feature.layer_assignment,
+			feature.experiment_allocation
 		FROM
 			feature
 		LEFT OUTER JOIN
@@ -434,7 +590,11 @@ func (s *featureStorage) ListFeaturesFilteredByExperiment(
 			&mysql.JSONObject{Val: &feature.Tags},
 			&feature.Maintainer,
 			&feature.SamplingSeed,
-			&mysql.JSONObject{Val: feature.Prerequisites},
+			&mysql.JSONObject{Val: &feature.Prerequisites},
+			&feature.Kind,
+			&feature.ExpectedRemovalAt,
+			&mysql.JSONObject{Val: &feature.LayerAssignment},
+			&mysql.JSONObject{Val: &feature.ExperimentAllocation},
 		)
 		if err != nil {
 			return nil, 0, 0, err
@@ -0,0 +1,228 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var (
+	ErrFeatureCleanupCandidateNotFound               = errors.New("featureCleanupCandidate: not found")
+	ErrFeatureCleanupCandidateUnexpectedAffectedRows = errors.New(
+		"featureCleanupCandidate: unexpected affected rows",
+	)
+)
+
+type FeatureCleanupCandidateStorage interface {
+	GetFeatureCleanupCandidate(
+		ctx context.Context,
+		featureID, environmentNamespace string,
+	) (*domain.FeatureCleanupCandidate, error)
+	ListFeatureCleanupCandidates(
+		ctx context.Context,
+		environmentNamespace string,
+	) ([]*domain.FeatureCleanupCandidate, error)
+	UpsertFeatureCleanupCandidate(
+		ctx context.Context,
+		candidate *domain.FeatureCleanupCandidate,
+		environmentNamespace string,
+	) error
+	DeleteFeatureCleanupCandidate(ctx context.Context, featureID, environmentNamespace string) error
+}
+
+type featureCleanupCandidateStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewFeatureCleanupCandidateStorage(qe mysql.QueryExecer) FeatureCleanupCandidateStorage {
+	return &featureCleanupCandidateStorage{qe: qe}
+}
+
+func (s *featureCleanupCandidateStorage) GetFeatureCleanupCandidate(
+	ctx context.Context,
+	featureID, environmentNamespace string,
+) (*domain.FeatureCleanupCandidate, error) {
+	candidate := proto.FeatureCleanupCandidate{}
+	var status int32
+	query := `
+		SELECT
+			feature_id,
+			reasons,
+			status,
+			warned_at,
+			archived_at,
+			updated_at,
+			exempted_version
+		FROM
+			feature_cleanup_candidate
+		WHERE
+			feature_id = ? AND
+			environment_namespace = ?
+	`
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		featureID,
+		environmentNamespace,
+	).Scan(
+		&candidate.FeatureId,
+		&mysql.JSONObject{Val: &candidate.Reasons},
+		&status,
+		&candidate.WarnedAt,
+		&candidate.ArchivedAt,
+		&candidate.UpdatedAt,
+		&candidate.ExemptedVersion,
+	)
+	if err != nil {
+		if err == mysql.ErrNoRows {
+			return nil, ErrFeatureCleanupCandidateNotFound
+		}
+		return nil, err
+	}
+	candidate.Status = proto.FeatureCleanupCandidate_Status(status)
+	return &domain.FeatureCleanupCandidate{FeatureCleanupCandidate: &candidate}, nil
+}
+
+func (s *featureCleanupCandidateStorage) ListFeatureCleanupCandidates(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*domain.FeatureCleanupCandidate, error) {
+	whereParts := []mysql.WherePart{
+		mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+	}
+	whereSQL, whereArgs := mysql.ConstructWhereSQLString(whereParts)
+	query := fmt.Sprintf(`
+		SELECT
+			feature_id,
+			reasons,
+			status,
+			warned_at,
+			archived_at,
+			updated_at,
+			exempted_version
+		FROM
+			feature_cleanup_candidate
+		%s
+	`, whereSQL,
+	)
+	rows, err := s.qe.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	candidates := []*domain.FeatureCleanupCandidate{}
+	for rows.Next() {
+		candidate := proto.FeatureCleanupCandidate{}
+		var status int32
+		err := rows.Scan(
+			&candidate.FeatureId,
+			&mysql.JSONObject{Val: &candidate.Reasons},
+			&status,
+			&candidate.WarnedAt,
+			&candidate.ArchivedAt,
+			&candidate.UpdatedAt,
+			&candidate.ExemptedVersion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidate.Status = proto.FeatureCleanupCandidate_Status(status)
+		candidates = append(candidates, &domain.FeatureCleanupCandidate{FeatureCleanupCandidate: &candidate})
+	}
+	if rows.Err() != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+func (s *featureCleanupCandidateStorage) UpsertFeatureCleanupCandidate(
+	ctx context.Context,
+	candidate *domain.FeatureCleanupCandidate,
+	environmentNamespace string,
+) error {
+	query := `
+		INSERT INTO feature_cleanup_candidate (
+			feature_id,
+			reasons,
+			status,
+			warned_at,
+			archived_at,
+			updated_at,
+			exempted_version,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?
+		) ON DUPLICATE KEY UPDATE
+			reasons = VALUES(reasons),
+			status = VALUES(status),
+			warned_at = VALUES(warned_at),
+			archived_at = VALUES(archived_at),
+			updated_at = VALUES(updated_at),
+			exempted_version = VALUES(exempted_version)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		candidate.FeatureId,
+		mysql.JSONObject{Val: candidate.Reasons},
+		int32(candidate.Status),
+		candidate.WarnedAt,
+		candidate.ArchivedAt,
+		candidate.UpdatedAt,
+		candidate.ExemptedVersion,
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *featureCleanupCandidateStorage) DeleteFeatureCleanupCandidate(
+	ctx context.Context,
+	featureID, environmentNamespace string,
+) error {
+	query := `
+		DELETE FROM
+			feature_cleanup_candidate
+		WHERE
+			feature_id = ? AND
+			environment_namespace = ?
+	`
+	result, err := s.qe.ExecContext(
+		ctx,
+		query,
+		featureID,
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return ErrFeatureCleanupCandidateUnexpectedAffectedRows
+	}
+	return nil
+}
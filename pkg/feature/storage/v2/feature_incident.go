@@ -0,0 +1,166 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var (
+	ErrFeatureIncidentAlreadyExists          = errors.New("featureIncident: already exists")
+	ErrFeatureIncidentNotFound               = errors.New("featureIncident: not found")
+	ErrFeatureIncidentUnexpectedAffectedRows = errors.New("featureIncident: unexpected affected rows")
+)
+
+type FeatureIncidentStorage interface {
+	CreateFeatureIncident(ctx context.Context, incident *domain.FeatureIncident, environmentNamespace string) error
+	UpdateFeatureIncident(ctx context.Context, incident *domain.FeatureIncident, environmentNamespace string) error
+	GetFeatureIncident(ctx context.Context, id, environmentNamespace string) (*domain.FeatureIncident, error)
+}
+
+type featureIncidentStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewFeatureIncidentStorage(qe mysql.QueryExecer) FeatureIncidentStorage {
+	return &featureIncidentStorage{qe: qe}
+}
+
+func (s *featureIncidentStorage) CreateFeatureIncident(
+	ctx context.Context,
+	incident *domain.FeatureIncident,
+	environmentNamespace string,
+) error {
+	query := `
+		INSERT INTO feature_incident (
+			id,
+			tag,
+			action,
+			feature_snapshots,
+			comment,
+			created_at,
+			restored_at,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		incident.Id,
+		incident.Tag,
+		int32(incident.Action),
+		mysql.JSONObject{Val: incident.FeatureSnapshots},
+		incident.Comment,
+		incident.CreatedAt,
+		incident.RestoredAt,
+		environmentNamespace,
+	)
+	if err != nil {
+		if err == mysql.ErrDuplicateEntry {
+			return ErrFeatureIncidentAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *featureIncidentStorage) UpdateFeatureIncident(
+	ctx context.Context,
+	incident *domain.FeatureIncident,
+	environmentNamespace string,
+) error {
+	query := `
+		UPDATE
+			feature_incident
+		SET
+			comment = ?,
+			restored_at = ?
+		WHERE
+			id = ? AND
+			environment_namespace = ?
+	`
+	result, err := s.qe.ExecContext(
+		ctx,
+		query,
+		incident.Comment,
+		incident.RestoredAt,
+		incident.Id,
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return ErrFeatureIncidentUnexpectedAffectedRows
+	}
+	return nil
+}
+
+func (s *featureIncidentStorage) GetFeatureIncident(
+	ctx context.Context,
+	id, environmentNamespace string,
+) (*domain.FeatureIncident, error) {
+	incident := proto.FeatureIncident{}
+	var action int32
+	query := `
+		SELECT
+			id,
+			tag,
+			action,
+			feature_snapshots,
+			comment,
+			created_at,
+			restored_at
+		FROM
+			feature_incident
+		WHERE
+			id = ? AND
+			environment_namespace = ?
+	`
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		id,
+		environmentNamespace,
+	).Scan(
+		&incident.Id,
+		&incident.Tag,
+		&action,
+		&mysql.JSONObject{Val: &incident.FeatureSnapshots},
+		&incident.Comment,
+		&incident.CreatedAt,
+		&incident.RestoredAt,
+	)
+	if err != nil {
+		if err == mysql.ErrNoRows {
+			return nil, ErrFeatureIncidentNotFound
+		}
+		return nil, err
+	}
+	incident.Action = proto.FeatureIncident_Action(action)
+	return &domain.FeatureIncident{FeatureIncident: &incident}, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package v2
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+var ErrFeatureLifecyclePolicyNotFound = errors.New("featureLifecyclePolicy: not found")
+
+type FeatureLifecyclePolicyStorage interface {
+	GetFeatureLifecyclePolicy(
+		ctx context.Context,
+		environmentNamespace string,
+	) (*domain.FeatureLifecyclePolicy, error)
+	UpsertFeatureLifecyclePolicy(
+		ctx context.Context,
+		policy *domain.FeatureLifecyclePolicy,
+		environmentNamespace string,
+	) error
+}
+
+type featureLifecyclePolicyStorage struct {
+	qe mysql.QueryExecer
+}
+
+func NewFeatureLifecyclePolicyStorage(qe mysql.QueryExecer) FeatureLifecyclePolicyStorage {
+	return &featureLifecyclePolicyStorage{qe: qe}
+}
+
+func (s *featureLifecyclePolicyStorage) GetFeatureLifecyclePolicy(
+	ctx context.Context,
+	environmentNamespace string,
+) (*domain.FeatureLifecyclePolicy, error) {
+	policy := proto.FeatureLifecyclePolicy{}
+	query := `
+		SELECT
+			not_evaluated_days,
+			single_variation,
+			grace_period_days,
+			undo_period_days,
+			auto_archive,
+			expired_removal_date,
+			created_at,
+			updated_at
+		FROM
+			feature_lifecycle_policy
+		WHERE
+			environment_namespace = ?
+	`
+	err := s.qe.QueryRowContext(
+		ctx,
+		query,
+		environmentNamespace,
+	).Scan(
+		&policy.NotEvaluatedDays,
+		&policy.SingleVariation,
+		&policy.GracePeriodDays,
+		&policy.UndoPeriodDays,
+		&policy.AutoArchive,
+		&policy.ExpiredRemovalDate,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		if err == mysql.ErrNoRows {
+			return nil, ErrFeatureLifecyclePolicyNotFound
+		}
+		return nil, err
+	}
+	return &domain.FeatureLifecyclePolicy{FeatureLifecyclePolicy: &policy}, nil
+}
+
+func (s *featureLifecyclePolicyStorage) UpsertFeatureLifecyclePolicy(
+	ctx context.Context,
+	policy *domain.FeatureLifecyclePolicy,
+	environmentNamespace string,
+) error {
+	query := `
+		INSERT INTO feature_lifecycle_policy (
+			not_evaluated_days,
+			single_variation,
+			grace_period_days,
+			undo_period_days,
+			auto_archive,
+			expired_removal_date,
+			created_at,
+			updated_at,
+			environment_namespace
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?
+		) ON DUPLICATE KEY UPDATE
+			not_evaluated_days = VALUES(not_evaluated_days),
+			single_variation = VALUES(single_variation),
+			grace_period_days = VALUES(grace_period_days),
+			undo_period_days = VALUES(undo_period_days),
+			auto_archive = VALUES(auto_archive),
+			expired_removal_date = VALUES(expired_removal_date),
+			updated_at = VALUES(updated_at)
+	`
+	_, err := s.qe.ExecContext(
+		ctx,
+		query,
+		policy.NotEvaluatedDays,
+		policy.SingleVariation,
+		policy.GracePeriodDays,
+		policy.UndoPeriodDays,
+		policy.AutoArchive,
+		policy.ExpiredRemovalDate,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+		environmentNamespace,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
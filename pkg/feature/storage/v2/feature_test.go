@@ -15,12 +15,17 @@
 package v2
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
 	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	proto "github.com/bucketeer-io/bucketeer/proto/feature"
 )
 
 func TestNewFeatureStorage(t *testing.T) {
@@ -30,3 +35,115 @@ func TestNewFeatureStorage(t *testing.T) {
 	storage := NewFeatureStorage(mock.NewMockQueryExecer(mockController))
 	assert.IsType(t, &featureStorage{}, storage)
 }
+
+func TestPutFeatureVersion(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	rollbackRow := func(rollbackable bool, err error) *mock.MockRow {
+		row := mock.NewMockRow(mockController)
+		row.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			*dest[0].(*bool) = rollbackable
+			return err
+		})
+		return row
+	}
+	patterns := map[string]struct {
+		setup       func(*featureStorage)
+		expectedErr error
+	}{
+		"error: check of rollback rule fails": {
+			setup: func(s *featureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rollbackRow(false, errors.New("error")))
+			},
+			expectedErr: errors.New("error"),
+		},
+		"success: feature without rollback rule is not kept": {
+			setup: func(s *featureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rollbackRow(false, nil))
+			},
+			expectedErr: nil,
+		},
+		"error: insert fails": {
+			setup: func(s *featureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rollbackRow(true, nil))
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			expectedErr: errors.New("error"),
+		},
+		"success: older versions are pruned": {
+			setup: func(s *featureStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rollbackRow(true, nil))
+				gomock.InOrder(
+					s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+						gomock.Any(), gomock.Any(), "fid", int32(12), gomock.Any(), "ns0",
+					).Return(nil, nil),
+					s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+						gomock.Any(), gomock.Any(), "fid", int32(12-maxFeatureVersions), "ns0",
+					).Return(nil, nil),
+				)
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			storage := newFeatureStorageWithMock(t, mockController)
+			p.setup(storage)
+			err := storage.putFeatureVersion(
+				context.Background(),
+				&domain.Feature{Feature: &proto.Feature{Id: "fid", Version: 12}},
+				"ns0",
+			)
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func TestGetFeatureVersion(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		scanErr     error
+		expectedErr error
+	}{
+		"error: not found": {
+			scanErr:     mysql.ErrNoRows,
+			expectedErr: ErrFeatureVersionNotFound,
+		},
+		"success": {
+			scanErr:     nil,
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			storage := newFeatureStorageWithMock(t, mockController)
+			row := mock.NewMockRow(mockController)
+			row.EXPECT().Scan(gomock.Any()).Return(p.scanErr)
+			storage.qe.(*mock.MockQueryExecer).EXPECT().QueryRowContext(
+				gomock.Any(), gomock.Any(), "fid", int32(11), "ns0",
+			).Return(row)
+			_, err := storage.GetFeatureVersion(context.Background(), "fid", 11, "ns0")
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func newFeatureStorageWithMock(t *testing.T, mockController *gomock.Controller) *featureStorage {
+	t.Helper()
+	return &featureStorage{mock.NewMockQueryExecer(mockController)}
+}
@@ -67,6 +67,21 @@ func (mr *MockFeatureStorageMockRecorder) GetFeature(ctx, key, environmentNamesp
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeature", reflect.TypeOf((*MockFeatureStorage)(nil).GetFeature), ctx, key, environmentNamespace)
 }
 
+// GetFeatureVersion mocks base method.
+func (m *MockFeatureStorage) GetFeatureVersion(ctx context.Context, key string, version int32, environmentNamespace string) (*domain.Feature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeatureVersion", ctx, key, version, environmentNamespace)
+	ret0, _ := ret[0].(*domain.Feature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeatureVersion indicates an expected call of GetFeatureVersion.
+func (mr *MockFeatureStorageMockRecorder) GetFeatureVersion(ctx, key, version, environmentNamespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeatureVersion", reflect.TypeOf((*MockFeatureStorage)(nil).GetFeatureVersion), ctx, key, version, environmentNamespace)
+}
+
 // ListFeatures mocks base method.
 func (m *MockFeatureStorage) ListFeatures(ctx context.Context, whereParts []mysql.WherePart, orders []*mysql.Order, limit, offset int) ([]*feature.Feature, int, int64, error) {
 	m.ctrl.T.Helper()
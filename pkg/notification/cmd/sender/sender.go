@@ -57,6 +57,9 @@ type sender struct {
 	scheduleFeatureStaleWatcher      *string
 	scheduleExperimentRunningWatcher *string
 	scheduleMAUCountWatcher          *string
+	scheduleFeatureLifecycleWatcher  *string
+	scheduleFeatureExpirationWatcher *string
+	scheduleExperimentSRMWatcher     *string
 	maxMPS                           *int
 	numWorkers                       *int
 	certPath                         *string
@@ -111,6 +114,18 @@ func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 			"schedule-mau-count-watcher",
 			"Cron format schedule for mau count watcher.",
 		).Default("0 0 1 1 * *").String(), // on every month 1st 10:00am JST
+		scheduleFeatureLifecycleWatcher: cmd.Flag(
+			"schedule-feature-lifecycle-watcher",
+			"Cron format schedule for feature lifecycle watcher.",
+		).Default("0 0 1 * * *").String(), // on every day 10:00am JST
+		scheduleFeatureExpirationWatcher: cmd.Flag(
+			"schedule-feature-expiration-watcher",
+			"Cron format schedule for feature expiration watcher.",
+		).Default("0 0 1 * * MON").String(), // on every Monday 10:00am JST
+		scheduleExperimentSRMWatcher: cmd.Flag(
+			"schedule-experiment-srm-watcher",
+			"Cron format schedule for experiment sample ratio mismatch watcher.",
+		).Default("0 0 1 * * *").String(), // on every day 10:00am JST
 		maxMPS:           cmd.Flag("max-mps", "Maximum messages should be handled in a second.").Default("5000").Int(),
 		numWorkers:       cmd.Flag("num-workers", "Number of workers.").Default("1").Int(),
 		certPath:         cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
@@ -311,6 +326,26 @@ func (s *sender) createJobs(
 				job.WithTimeout(1*time.Minute),
 				job.WithLogger(logger)),
 		},
+		{
+			Cron: *s.scheduleFeatureLifecycleWatcher,
+			Name: "feature_lifecycle_watcher",
+			Job: job.NewFeatureLifecycleWatcher(
+				environmentClient,
+				featureClient,
+				notificationSender,
+				job.WithTimeout(5*time.Minute),
+				job.WithLogger(logger)),
+		},
+		{
+			Cron: *s.scheduleFeatureExpirationWatcher,
+			Name: "feature_expiration_watcher",
+			Job: job.NewFeatureExpirationWatcher(
+				environmentClient,
+				featureClient,
+				notificationSender,
+				job.WithTimeout(1*time.Minute),
+				job.WithLogger(logger)),
+		},
 		{
 			Cron: *s.scheduleExperimentRunningWatcher,
 			Name: "experiment_running_watcher",
@@ -321,6 +356,17 @@ func (s *sender) createJobs(
 				job.WithTimeout(1*time.Minute),
 				job.WithLogger(logger)),
 		},
+		{
+			Cron: *s.scheduleExperimentSRMWatcher,
+			Name: "experiment_srm_watcher",
+			Job: job.NewExperimentSRMWatcher(
+				environmentClient,
+				experimentClient,
+				eventCounterClient,
+				notificationSender,
+				job.WithTimeout(5*time.Minute),
+				job.WithLogger(logger)),
+		},
 		{
 			Cron: *s.scheduleMAUCountWatcher,
 			Name: "mau_count",
@@ -0,0 +1,207 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	wrappersproto "github.com/golang/protobuf/ptypes/wrappers"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	experimentclient "github.com/bucketeer-io/bucketeer/pkg/experiment/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/notification/sender"
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	notificationproto "github.com/bucketeer-io/bucketeer/proto/notification"
+	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
+)
+
+// srmPValueThreshold is the p-value below which the split of users is considered a mismatch.
+// It is stricter than the usual significance level because the test runs repeatedly on every experiment.
+const srmPValueThreshold = 0.001
+
+type experimentSRMWatcher struct {
+	environmentClient  environmentclient.Client
+	experimentClient   experimentclient.Client
+	eventCounterClient ecclient.Client
+	sender             sender.Sender
+	opts               *options
+	logger             *zap.Logger
+}
+
+// NewExperimentSRMWatcher notifies the running experiments whose sample ratio mismatch p-value,
+// stored by the experiment batch, is below the threshold.
+func NewExperimentSRMWatcher(
+	environmentClient environmentclient.Client,
+	experimentClient experimentclient.Client,
+	eventCounterClient ecclient.Client,
+	sender sender.Sender,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &experimentSRMWatcher{
+		environmentClient:  environmentClient,
+		experimentClient:   experimentClient,
+		eventCounterClient: eventCounterClient,
+		sender:             sender,
+		opts:               dopts,
+		logger:             dopts.logger.Named("experiment-srm-watcher"),
+	}
+}
+
+func (w *experimentSRMWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments, err := w.listEnvironments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, env := range environments {
+		experiments, err := w.listExperiments(ctx, env.Namespace)
+		if err != nil {
+			return err
+		}
+		mismatches := []*senderproto.ExperimentSampleRatioMismatchNotification_Mismatch{}
+		for _, e := range experiments {
+			result, err := w.getExperimentResult(ctx, env.Namespace, e.Id)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if result == nil || result.SrmCheckedAt == 0 || result.SrmPValue >= srmPValueThreshold {
+				continue
+			}
+			mismatches = append(mismatches, &senderproto.ExperimentSampleRatioMismatchNotification_Mismatch{
+				Experiment: e,
+				PValue:     result.SrmPValue,
+			})
+		}
+		if len(mismatches) == 0 {
+			continue
+		}
+		ne, err := w.createNotificationEvent(env, mismatches)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := w.sender.Send(ctx, ne); err != nil {
+			lastErr = err
+		}
+	}
+	return
+}
+
+func (w *experimentSRMWatcher) getExperimentResult(
+	ctx context.Context,
+	environmentNamespace, experimentID string,
+) (*ecproto.ExperimentResult, error) {
+	resp, err := w.eventCounterClient.GetExperimentResult(ctx, &ecproto.GetExperimentResultRequest{
+		EnvironmentNamespace: environmentNamespace,
+		ExperimentId:         experimentID,
+	})
+	if err != nil {
+		if gstatus.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		w.logger.Error("Failed to get experiment result", zap.Error(err),
+			zap.String("environmentNamespace", environmentNamespace),
+			zap.String("experimentId", experimentID))
+		return nil, err
+	}
+	return resp.ExperimentResult, nil
+}
+
+func (w *experimentSRMWatcher) createNotificationEvent(
+	environment *environmentproto.Environment,
+	mismatches []*senderproto.ExperimentSampleRatioMismatchNotification_Mismatch,
+) (*senderproto.NotificationEvent, error) {
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	ne := &senderproto.NotificationEvent{
+		Id:                   id.String(),
+		EnvironmentNamespace: environment.Namespace,
+		SourceType:           notificationproto.Subscription_EXPERIMENT_SAMPLE_RATIO_MISMATCH,
+		Notification: &senderproto.Notification{
+			Type: senderproto.Notification_ExperimentSampleRatioMismatch,
+			ExperimentSampleRatioMismatchNotification: &senderproto.ExperimentSampleRatioMismatchNotification{
+				EnvironmentId: environment.Id,
+				Mismatches:    mismatches,
+			},
+		},
+		IsAdminEvent: false,
+	}
+	return ne, nil
+}
+
+func (w *experimentSRMWatcher) listEnvironments(ctx context.Context) ([]*environmentproto.Environment, error) {
+	environments := []*environmentproto.Environment{}
+	cursor := ""
+	for {
+		resp, err := w.environmentClient.ListEnvironments(ctx, &environmentproto.ListEnvironmentsRequest{
+			PageSize: listRequestSize,
+			Cursor:   cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, resp.Environments...)
+		environmentSize := len(resp.Environments)
+		if environmentSize == 0 || environmentSize < listRequestSize {
+			return environments, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (w *experimentSRMWatcher) listExperiments(
+	ctx context.Context,
+	environmentNamespace string,
+) ([]*experimentproto.Experiment, error) {
+	experiments := []*experimentproto.Experiment{}
+	cursor := ""
+	for {
+		resp, err := w.experimentClient.ListExperiments(ctx, &experimentproto.ListExperimentsRequest{
+			PageSize:             listRequestSize,
+			Cursor:               cursor,
+			EnvironmentNamespace: environmentNamespace,
+			Status:               &wrappersproto.Int32Value{Value: int32(experimentproto.Experiment_RUNNING)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		experiments = append(experiments, resp.Experiments...)
+		size := len(resp.Experiments)
+		if size == 0 || size < listRequestSize {
+			return experiments, nil
+		}
+		cursor = resp.Cursor
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+
+	environmentclientmock "github.com/bucketeer-io/bucketeer/pkg/environment/client/mock"
+	ecclientmock "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client/mock"
+	experimentclientmock "github.com/bucketeer-io/bucketeer/pkg/experiment/client/mock"
+	sendermock "github.com/bucketeer-io/bucketeer/pkg/notification/sender/mock"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	ecproto "github.com/bucketeer-io/bucketeer/proto/eventcounter"
+	experimentproto "github.com/bucketeer-io/bucketeer/proto/experiment"
+	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
+)
+
+func TestExperimentSRMWatcherRun(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup       func(*testing.T, *experimentSRMWatcher)
+		expectedErr error
+	}{
+		"no mismatch": {
+			setup: func(t *testing.T, w *experimentSRMWatcher) {
+				w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+					gomock.Any(), gomock.Any()).Return(
+					&environmentproto.ListEnvironmentsResponse{
+						Environments: []*environmentproto.Environment{{Id: "ns0", Namespace: "ns0"}},
+					}, nil)
+				w.experimentClient.(*experimentclientmock.MockClient).EXPECT().ListExperiments(
+					gomock.Any(), gomock.Any()).Return(
+					&experimentproto.ListExperimentsResponse{
+						Experiments: []*experimentproto.Experiment{{Id: "eid0"}, {Id: "eid1"}, {Id: "eid2"}},
+					}, nil)
+				w.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), &ecproto.GetExperimentResultRequest{EnvironmentNamespace: "ns0", ExperimentId: "eid0"},
+				).Return(nil, gstatus.Error(codes.NotFound, "not found"))
+				w.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), &ecproto.GetExperimentResultRequest{EnvironmentNamespace: "ns0", ExperimentId: "eid1"},
+				).Return(&ecproto.GetExperimentResultResponse{
+					ExperimentResult: &ecproto.ExperimentResult{Id: "eid1"},
+				}, nil)
+				w.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), &ecproto.GetExperimentResultRequest{EnvironmentNamespace: "ns0", ExperimentId: "eid2"},
+				).Return(&ecproto.GetExperimentResultResponse{
+					ExperimentResult: &ecproto.ExperimentResult{Id: "eid2", SrmPValue: 0.2, SrmCheckedAt: 1},
+				}, nil)
+			},
+			expectedErr: nil,
+		},
+		"mismatch exists": {
+			setup: func(t *testing.T, w *experimentSRMWatcher) {
+				w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+					gomock.Any(), gomock.Any()).Return(
+					&environmentproto.ListEnvironmentsResponse{
+						Environments: []*environmentproto.Environment{{Id: "ns0", Namespace: "ns0"}},
+					}, nil)
+				w.experimentClient.(*experimentclientmock.MockClient).EXPECT().ListExperiments(
+					gomock.Any(), gomock.Any()).Return(
+					&experimentproto.ListExperimentsResponse{
+						Experiments: []*experimentproto.Experiment{{Id: "eid0"}},
+					}, nil)
+				w.eventCounterClient.(*ecclientmock.MockClient).EXPECT().GetExperimentResult(
+					gomock.Any(), gomock.Any(),
+				).Return(&ecproto.GetExperimentResultResponse{
+					ExperimentResult: &ecproto.ExperimentResult{Id: "eid0", SrmPValue: 0.0001, SrmCheckedAt: 1},
+				}, nil)
+				w.sender.(*sendermock.MockSender).EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, ne *senderproto.NotificationEvent) error {
+						mismatches := ne.Notification.ExperimentSampleRatioMismatchNotification.Mismatches
+						assert.Len(t, mismatches, 1)
+						assert.Equal(t, "eid0", mismatches[0].Experiment.Id)
+						assert.Equal(t, 0.0001, mismatches[0].PValue)
+						return nil
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newExperimentSRMWatcherWithMock(t, mockController)
+			if p.setup != nil {
+				p.setup(t, w)
+			}
+			err := w.Run(context.Background())
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func newExperimentSRMWatcherWithMock(t *testing.T, c *gomock.Controller) *experimentSRMWatcher {
+	t.Helper()
+	return &experimentSRMWatcher{
+		environmentClient:  environmentclientmock.NewMockClient(c),
+		experimentClient:   experimentclientmock.NewMockClient(c),
+		eventCounterClient: ecclientmock.NewMockClient(c),
+		sender:             sendermock.NewMockSender(c),
+		logger:             zap.NewNop(),
+		opts: &options{
+			timeout: 5 * time.Minute,
+		},
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"go.uber.org/zap"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	featuredomain "github.com/bucketeer-io/bucketeer/pkg/feature/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/notification/sender"
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+	notificationproto "github.com/bucketeer-io/bucketeer/proto/notification"
+	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
+)
+
+// removalDateNoticePeriod is how long before the expected removal date the maintainers are notified.
+const removalDateNoticePeriod = 7 * 24 * time.Hour
+
+type featureExpirationWatcher struct {
+	environmentClient environmentclient.Client
+	featureClient     featureclient.Client
+	sender            sender.Sender
+	opts              *options
+	logger            *zap.Logger
+}
+
+// NewFeatureExpirationWatcher notifies the temporary features whose expected removal date
+// is approaching or has passed, so their maintainers can remove them.
+func NewFeatureExpirationWatcher(
+	environmentClient environmentclient.Client,
+	featureClient featureclient.Client,
+	sender sender.Sender,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &featureExpirationWatcher{
+		environmentClient: environmentClient,
+		featureClient:     featureClient,
+		sender:            sender,
+		opts:              dopts,
+		logger:            dopts.logger.Named("feature-expiration-watcher"),
+	}
+}
+
+func (w *featureExpirationWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments, err := w.listEnvironments(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, env := range environments {
+		features, err := w.listFeatures(ctx, env.Namespace, now.Add(removalDateNoticePeriod))
+		if err != nil {
+			w.logger.Error("Failed to list features", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		approaching := []*featureproto.Feature{}
+		expired := []*featureproto.Feature{}
+		for _, f := range features {
+			fd := &featuredomain.Feature{Feature: f}
+			if fd.IsPermanent() {
+				continue
+			}
+			if fd.IsRemovalDatePassed(now) {
+				expired = append(expired, f)
+				continue
+			}
+			if fd.IsRemovalDateApproaching(now, removalDateNoticePeriod) {
+				approaching = append(approaching, f)
+			}
+		}
+		if len(approaching) == 0 && len(expired) == 0 {
+			continue
+		}
+		ne, err := w.createNotificationEvent(env, approaching, expired)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := w.sender.Send(ctx, ne); err != nil {
+			lastErr = err
+		}
+	}
+	return
+}
+
+func (w *featureExpirationWatcher) createNotificationEvent(
+	environment *environmentproto.Environment,
+	approaching, expired []*featureproto.Feature,
+) (*senderproto.NotificationEvent, error) {
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	ne := &senderproto.NotificationEvent{
+		Id:                   id.String(),
+		EnvironmentNamespace: environment.Namespace,
+		SourceType:           notificationproto.Subscription_FEATURE_EXPIRATION,
+		Notification: &senderproto.Notification{
+			Type: senderproto.Notification_FeatureExpiration,
+			FeatureExpirationNotification: &senderproto.FeatureExpirationNotification{
+				EnvironmentId:       environment.Id,
+				ApproachingFeatures: approaching,
+				ExpiredFeatures:     expired,
+			},
+		},
+		IsAdminEvent: false,
+	}
+	return ne, nil
+}
+
+func (w *featureExpirationWatcher) listEnvironments(ctx context.Context) ([]*environmentproto.Environment, error) {
+	environments := []*environmentproto.Environment{}
+	cursor := ""
+	for {
+		resp, err := w.environmentClient.ListEnvironments(ctx, &environmentproto.ListEnvironmentsRequest{
+			PageSize: listRequestSize,
+			Cursor:   cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, resp.Environments...)
+		environmentSize := len(resp.Environments)
+		if environmentSize == 0 || environmentSize < listRequestSize {
+			return environments, nil
+		}
+		cursor = resp.Cursor
+	}
+}
+
+func (w *featureExpirationWatcher) listFeatures(
+	ctx context.Context,
+	environmentNamespace string,
+	expectedRemovalBefore time.Time,
+) ([]*featureproto.Feature, error) {
+	features := []*featureproto.Feature{}
+	cursor := ""
+	for {
+		resp, err := w.featureClient.ListFeatures(ctx, &featureproto.ListFeaturesRequest{
+			PageSize:              listRequestSize,
+			Cursor:                cursor,
+			EnvironmentNamespace:  environmentNamespace,
+			Archived:              &wrappers.BoolValue{Value: false},
+			ExpectedRemovalBefore: expectedRemovalBefore.Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, resp.Features...)
+		featureSize := len(resp.Features)
+		if featureSize == 0 || featureSize < listRequestSize {
+			return features, nil
+		}
+		cursor = resp.Cursor
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	environmentclientmock "github.com/bucketeer-io/bucketeer/pkg/environment/client/mock"
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	sendermock "github.com/bucketeer-io/bucketeer/pkg/notification/sender/mock"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
+)
+
+func TestFeatureExpirationWatcherRun(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	now := time.Now()
+	patterns := map[string]struct {
+		features            []*featureproto.Feature
+		expectedApproaching []string
+		expectedExpired     []string
+	}{
+		"no features": {
+			features: []*featureproto.Feature{},
+		},
+		"permanent feature": {
+			features: []*featureproto.Feature{{
+				Id:                "fid",
+				Kind:              featureproto.Feature_OPS,
+				ExpectedRemovalAt: now.Add(-time.Hour).Unix(),
+			}},
+		},
+		"approaching and expired": {
+			features: []*featureproto.Feature{
+				{
+					Id:                "fid-0",
+					Kind:              featureproto.Feature_RELEASE,
+					Maintainer:        "bucketeer@example.com",
+					ExpectedRemovalAt: now.Add(time.Hour).Unix(),
+				},
+				{
+					Id:                "fid-1",
+					Kind:              featureproto.Feature_EXPERIMENT,
+					Maintainer:        "bucketeer@example.com",
+					ExpectedRemovalAt: now.Add(-time.Hour).Unix(),
+				},
+			},
+			expectedApproaching: []string{"fid-0"},
+			expectedExpired:     []string{"fid-1"},
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newFeatureExpirationWatcherWithMock(t, mockController)
+			w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+				gomock.Any(), gomock.Any()).Return(
+				&environmentproto.ListEnvironmentsResponse{
+					Environments: []*environmentproto.Environment{{Id: "ns0", Namespace: "ns0"}},
+				}, nil)
+			w.featureClient.(*featureclientmock.MockClient).EXPECT().ListFeatures(
+				gomock.Any(), gomock.Any()).Return(
+				&featureproto.ListFeaturesResponse{Features: p.features}, nil)
+			if len(p.expectedApproaching) > 0 || len(p.expectedExpired) > 0 {
+				w.sender.(*sendermock.MockSender).EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, ne *senderproto.NotificationEvent) error {
+						n := ne.Notification.FeatureExpirationNotification
+						assert.Equal(t, p.expectedApproaching, featureIDs(n.ApproachingFeatures))
+						assert.Equal(t, p.expectedExpired, featureIDs(n.ExpiredFeatures))
+						return nil
+					})
+			}
+			err := w.Run(context.Background())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFeatureExpirationWatcherRunListFeaturesError(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	w := newFeatureExpirationWatcherWithMock(t, mockController)
+	w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+		gomock.Any(), gomock.Any()).Return(
+		&environmentproto.ListEnvironmentsResponse{
+			Environments: []*environmentproto.Environment{
+				{Id: "ns0", Namespace: "ns0"},
+				{Id: "ns1", Namespace: "ns1"},
+			},
+		}, nil)
+	w.featureClient.(*featureclientmock.MockClient).EXPECT().ListFeatures(
+		gomock.Any(), gomock.Any()).Return(nil, errors.New("test"))
+	w.featureClient.(*featureclientmock.MockClient).EXPECT().ListFeatures(
+		gomock.Any(), gomock.Any()).Return(
+		&featureproto.ListFeaturesResponse{Features: []*featureproto.Feature{{
+			Id:                "fid",
+			Kind:              featureproto.Feature_RELEASE,
+			ExpectedRemovalAt: time.Now().Add(-time.Hour).Unix(),
+		}}}, nil)
+	w.sender.(*sendermock.MockSender).EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, ne *senderproto.NotificationEvent) error {
+			assert.Equal(t, "ns1", ne.EnvironmentNamespace)
+			return nil
+		})
+	err := w.Run(context.Background())
+	assert.Equal(t, errors.New("test"), err)
+}
+
+func featureIDs(features []*featureproto.Feature) []string {
+	ids := make([]string, 0, len(features))
+	for _, f := range features {
+		ids = append(ids, f.Id)
+	}
+	return ids
+}
+
+func newFeatureExpirationWatcherWithMock(t *testing.T, c *gomock.Controller) *featureExpirationWatcher {
+	t.Helper()
+	return &featureExpirationWatcher{
+		environmentClient: environmentclientmock.NewMockClient(c),
+		featureClient:     featureclientmock.NewMockClient(c),
+		sender:            sendermock.NewMockSender(c),
+		logger:            zap.NewNop(),
+		opts: &options{
+			timeout: 5 * time.Minute,
+		},
+	}
+}
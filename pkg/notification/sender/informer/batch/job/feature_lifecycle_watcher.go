@@ -0,0 +1,143 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	environmentclient "github.com/bucketeer-io/bucketeer/pkg/environment/client"
+	featureclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/notification/sender"
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+	notificationproto "github.com/bucketeer-io/bucketeer/proto/notification"
+	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
+)
+
+type featureLifecycleWatcher struct {
+	environmentClient environmentclient.Client
+	featureClient     featureclient.Client
+	sender            sender.Sender
+	opts              *options
+	logger            *zap.Logger
+}
+
+// NewFeatureLifecycleWatcher applies the lifecycle policy of each environment
+// and notifies the features warned or archived by it.
+func NewFeatureLifecycleWatcher(
+	environmentClient environmentclient.Client,
+	featureClient featureclient.Client,
+	sender sender.Sender,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &featureLifecycleWatcher{
+		environmentClient: environmentClient,
+		featureClient:     featureClient,
+		sender:            sender,
+		opts:              dopts,
+		logger:            dopts.logger.Named("feature-lifecycle-watcher"),
+	}
+}
+
+func (w *featureLifecycleWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments, err := w.listEnvironments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, env := range environments {
+		resp, err := w.featureClient.ApplyFeatureLifecyclePolicy(ctx, &featureproto.ApplyFeatureLifecyclePolicyRequest{
+			EnvironmentNamespace: env.Namespace,
+		})
+		if err != nil {
+			w.logger.Error("Failed to apply feature lifecycle policy",
+				zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+			)
+			lastErr = err
+			continue
+		}
+		if len(resp.WarnedCandidates) == 0 && len(resp.ArchivedCandidates) == 0 {
+			continue
+		}
+		ne, err := w.createNotificationEvent(env, resp.WarnedCandidates, resp.ArchivedCandidates)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := w.sender.Send(ctx, ne); err != nil {
+			lastErr = err
+		}
+	}
+	return
+}
+
+func (w *featureLifecycleWatcher) createNotificationEvent(
+	environment *environmentproto.Environment,
+	warned, archived []*featureproto.FeatureCleanupCandidate,
+) (*senderproto.NotificationEvent, error) {
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	ne := &senderproto.NotificationEvent{
+		Id:                   id.String(),
+		EnvironmentNamespace: environment.Namespace,
+		SourceType:           notificationproto.Subscription_FEATURE_CLEANUP,
+		Notification: &senderproto.Notification{
+			Type: senderproto.Notification_FeatureCleanup,
+			FeatureCleanupNotification: &senderproto.FeatureCleanupNotification{
+				EnvironmentId:      environment.Id,
+				WarnedCandidates:   warned,
+				ArchivedCandidates: archived,
+			},
+		},
+		IsAdminEvent: false,
+	}
+	return ne, nil
+}
+
+func (w *featureLifecycleWatcher) listEnvironments(ctx context.Context) ([]*environmentproto.Environment, error) {
+	environments := []*environmentproto.Environment{}
+	cursor := ""
+	for {
+		resp, err := w.environmentClient.ListEnvironments(ctx, &environmentproto.ListEnvironmentsRequest{
+			PageSize: listRequestSize,
+			Cursor:   cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, resp.Environments...)
+		environmentSize := len(resp.Environments)
+		if environmentSize == 0 || environmentSize < listRequestSize {
+			return environments, nil
+		}
+		cursor = resp.Cursor
+	}
+}
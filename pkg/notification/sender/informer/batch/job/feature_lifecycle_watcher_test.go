@@ -0,0 +1,115 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	environmentclientmock "github.com/bucketeer-io/bucketeer/pkg/environment/client/mock"
+	featureclientmock "github.com/bucketeer-io/bucketeer/pkg/feature/client/mock"
+	sendermock "github.com/bucketeer-io/bucketeer/pkg/notification/sender/mock"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
+)
+
+func TestFeatureLifecycleWatcherRun(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	errApply := errors.New("apply")
+	patterns := map[string]struct {
+		setup       func(*testing.T, *featureLifecycleWatcher)
+		expectedErr error
+	}{
+		"no candidates": {
+			setup: func(t *testing.T, w *featureLifecycleWatcher) {
+				w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+					gomock.Any(), gomock.Any()).Return(
+					&environmentproto.ListEnvironmentsResponse{
+						Environments: []*environmentproto.Environment{{Id: "ns0", Namespace: "ns0"}},
+					}, nil)
+				w.featureClient.(*featureclientmock.MockClient).EXPECT().ApplyFeatureLifecyclePolicy(
+					gomock.Any(), gomock.Any()).Return(&featureproto.ApplyFeatureLifecyclePolicyResponse{}, nil)
+			},
+		},
+		"candidates exist": {
+			setup: func(t *testing.T, w *featureLifecycleWatcher) {
+				w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+					gomock.Any(), gomock.Any()).Return(
+					&environmentproto.ListEnvironmentsResponse{
+						Environments: []*environmentproto.Environment{{Id: "ns0", Namespace: "ns0"}},
+					}, nil)
+				w.featureClient.(*featureclientmock.MockClient).EXPECT().ApplyFeatureLifecyclePolicy(
+					gomock.Any(), gomock.Any()).Return(&featureproto.ApplyFeatureLifecyclePolicyResponse{
+					WarnedCandidates: []*featureproto.FeatureCleanupCandidate{{FeatureId: "fid"}},
+				}, nil)
+				w.sender.(*sendermock.MockSender).EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		"apply fails in one environment": {
+			setup: func(t *testing.T, w *featureLifecycleWatcher) {
+				w.environmentClient.(*environmentclientmock.MockClient).EXPECT().ListEnvironments(
+					gomock.Any(), gomock.Any()).Return(
+					&environmentproto.ListEnvironmentsResponse{
+						Environments: []*environmentproto.Environment{
+							{Id: "ns0", Namespace: "ns0"},
+							{Id: "ns1", Namespace: "ns1"},
+						},
+					}, nil)
+				gomock.InOrder(
+					w.featureClient.(*featureclientmock.MockClient).EXPECT().ApplyFeatureLifecyclePolicy(
+						gomock.Any(), gomock.Any()).Return(nil, errApply),
+					w.featureClient.(*featureclientmock.MockClient).EXPECT().ApplyFeatureLifecyclePolicy(
+						gomock.Any(), gomock.Any()).Return(&featureproto.ApplyFeatureLifecyclePolicyResponse{
+						ArchivedCandidates: []*featureproto.FeatureCleanupCandidate{{FeatureId: "fid"}},
+					}, nil),
+				)
+				w.sender.(*sendermock.MockSender).EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedErr: errApply,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newFeatureLifecycleWatcherWithMock(t, mockController)
+			if p.setup != nil {
+				p.setup(t, w)
+			}
+			err := w.Run(context.Background())
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
+
+func newFeatureLifecycleWatcherWithMock(t *testing.T, c *gomock.Controller) *featureLifecycleWatcher {
+	t.Helper()
+	return &featureLifecycleWatcher{
+		environmentClient: environmentclientmock.NewMockClient(c),
+		featureClient:     featureclientmock.NewMockClient(c),
+		sender:            sendermock.NewMockSender(c),
+		logger:            zap.NewNop(),
+		opts: &options{
+			timeout: 5 * time.Minute,
+		},
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
 	gcodes "google.golang.org/grpc/codes"
 	gstatus "google.golang.org/grpc/status"
@@ -233,6 +234,9 @@ func (i *domainEventInformer) createNotificationEvent(
 	if err != nil {
 		return nil, err
 	}
+	if event.Type == domaineventproto.Event_AUTOOPS_RULE_EXECUTED {
+		return i.createAutoOpsExecutionNotificationEvent(id.String(), event, environmentID)
+	}
 	st, err := i.convSourceType(event.EntityType)
 	if err != nil {
 		i.logger.Error("Failed to convert source type", zap.Error(err))
@@ -257,6 +261,35 @@ func (i *domainEventInformer) createNotificationEvent(
 	return ne, nil
 }
 
+// createAutoOpsExecutionNotificationEvent creates the dedicated notification
+// so that the message explains what triggered the execution.
+func (i *domainEventInformer) createAutoOpsExecutionNotificationEvent(
+	id string,
+	event *domaineventproto.Event,
+	environmentID string,
+) (*senderproto.NotificationEvent, error) {
+	executed := &domaineventproto.AutoOpsRuleExecutedEvent{}
+	if err := ptypes.UnmarshalAny(event.Data, executed); err != nil {
+		i.logger.Error("Failed to unmarshal auto ops rule executed event", zap.Error(err))
+		return nil, err
+	}
+	ne := &senderproto.NotificationEvent{
+		Id:                   id,
+		EnvironmentNamespace: event.EnvironmentNamespace,
+		SourceType:           notificationproto.Subscription_AUTOOPS_RULE_EXECUTED,
+		Notification: &senderproto.Notification{
+			Type: senderproto.Notification_AutoOpsExecution,
+			AutoOpsExecutionNotification: &senderproto.AutoOpsExecutionNotification{
+				EnvironmentId: environmentID,
+				Editor:        event.Editor,
+				AutoOpsRule:   executed.AutoOpsRule,
+				Execution:     executed.Execution,
+			},
+		},
+	}
+	return ne, nil
+}
+
 func (i *domainEventInformer) convSourceType(
 	entityType domaineventproto.Event_EntityType,
 ) (notificationproto.Subscription_SourceType, error) {
@@ -289,6 +322,8 @@ func (i *domainEventInformer) convSourceType(
 		return notificationproto.Subscription_DOMAIN_EVENT_PROJECT, nil
 	case domaineventproto.Event_WEBHOOK:
 		return notificationproto.Subscription_DOMAIN_EVENT_WEBHOOK, nil
+	case domaineventproto.Event_LAYER:
+		return notificationproto.Subscription_DOMAIN_EVENT_LAYER, nil
 	}
 	return notificationproto.Subscription_SourceType(0), ErrUnknownSourceType
 }
@@ -18,9 +18,13 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 	domaineventproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
 	notificationproto "github.com/bucketeer-io/bucketeer/proto/notification"
 	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
@@ -116,6 +120,45 @@ func TestCreateNotificationEvent(t *testing.T) {
 	}
 }
 
+func TestCreateAutoOpsExecutionNotificationEvent(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	rule := &autoopsproto.AutoOpsRule{Id: "rid", FeatureId: "fid", OpsType: autoopsproto.OpsType_DISABLE_FEATURE}
+	execution := &autoopsproto.AutoOpsExecution{
+		Id:             "eid",
+		AutoOpsRuleId:  "rid",
+		FeatureId:      "fid",
+		Trigger:        &autoopsproto.ExecutionTrigger{ClauseId: "cid", OpsEventCount: 10, EvaluationCount: 20},
+		FeatureVersion: 2,
+	}
+	data, err := ptypes.MarshalAny(&domaineventproto.AutoOpsRuleExecutedEvent{
+		Execution:   execution,
+		AutoOpsRule: rule,
+	})
+	require.NoError(t, err)
+	input := &domaineventproto.Event{
+		Id:                   "did",
+		EntityType:           domaineventproto.Event_AUTOOPS_RULE,
+		EntityId:             "rid",
+		Type:                 domaineventproto.Event_AUTOOPS_RULE_EXECUTED,
+		Data:                 data,
+		Editor:               &domaineventproto.Editor{Email: "test@test.com"},
+		EnvironmentNamespace: "ns0",
+	}
+	i := newDomainEventInformer(t, mockController)
+	actual, err := i.createNotificationEvent(input, "nsid", false)
+	require.NoError(t, err)
+	assert.Equal(t, "ns0", actual.EnvironmentNamespace)
+	assert.Equal(t, notificationproto.Subscription_AUTOOPS_RULE_EXECUTED, actual.SourceType)
+	assert.Equal(t, senderproto.Notification_AutoOpsExecution, actual.Notification.Type)
+	n := actual.Notification.AutoOpsExecutionNotification
+	assert.Equal(t, "nsid", n.EnvironmentId)
+	assert.Equal(t, "test@test.com", n.Editor.Email)
+	assert.True(t, proto.Equal(rule, n.AutoOpsRule))
+	assert.True(t, proto.Equal(execution, n.Execution))
+}
+
 func TestConvSourceType(t *testing.T) {
 	t.Parallel()
 	mockController := gomock.NewController(t)
@@ -29,6 +29,12 @@ const (
 	msgTypeFeatureStale
 	msgTypeExperimentResult
 	msgTypeMAUCount
+	msgTypeFeatureCleanupWarned
+	msgTypeFeatureCleanupArchived
+	msgTypeFeatureRemovalDateApproaching
+	msgTypeFeatureRemovalDatePassed
+	msgTypeExperimentSampleRatioMismatch
+	msgTypeAutoOpsExecution
 )
 
 var (
@@ -45,6 +51,30 @@ var (
 		Locale:  locale.JaJP,
 		Message: "%d月のMAUです。",
 	}
+	msgFeatureCleanupWarnedJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "ライフサイクルポリシーにより削除候補になったフィーチャーフラグがあります。",
+	}
+	msgFeatureCleanupArchivedJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "ライフサイクルポリシーによりアーカイブされたフィーチャーフラグがあります。",
+	}
+	msgFeatureRemovalDateApproachingJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "削除予定日が近づいているフィーチャーフラグがあります。",
+	}
+	msgFeatureRemovalDatePassedJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "削除予定日を過ぎたフィーチャーフラグがあります。",
+	}
+	msgExperimentSampleRatioMismatchJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "ユーザーの割り当て比率が設定と一致しないエクスペリメントがあります。結果が信頼できない可能性があります。",
+	}
+	msgAutoOpsExecutionJaJP = &errdetails.LocalizedMessage{
+		Locale:  locale.JaJP,
+		Message: "自動オペレーションが実行されました。",
+	}
 )
 
 func localizedMessage(t msgType, loc string) (*errdetails.LocalizedMessage, error) {
@@ -56,6 +86,18 @@ func localizedMessage(t msgType, loc string) (*errdetails.LocalizedMessage, erro
 		return msgExperimentResultJaJP, nil
 	case msgTypeMAUCount:
 		return msgMAUCountJaJP, nil
+	case msgTypeFeatureCleanupWarned:
+		return msgFeatureCleanupWarnedJaJP, nil
+	case msgTypeFeatureCleanupArchived:
+		return msgFeatureCleanupArchivedJaJP, nil
+	case msgTypeFeatureRemovalDateApproaching:
+		return msgFeatureRemovalDateApproachingJaJP, nil
+	case msgTypeFeatureRemovalDatePassed:
+		return msgFeatureRemovalDatePassedJaJP, nil
+	case msgTypeExperimentSampleRatioMismatch:
+		return msgExperimentSampleRatioMismatchJaJP, nil
+	case msgTypeAutoOpsExecution:
+		return msgAutoOpsExecutionJaJP, nil
 	default:
 		return nil, errUnknownMsgType
 	}
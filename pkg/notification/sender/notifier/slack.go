@@ -18,8 +18,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 	"golang.org/x/text/language"
@@ -30,7 +32,9 @@ import (
 	"github.com/bucketeer-io/bucketeer/pkg/locale"
 	"github.com/bucketeer-io/bucketeer/pkg/metrics"
 	notificationdomain "github.com/bucketeer-io/bucketeer/pkg/notification/domain"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 	domainproto "github.com/bucketeer-io/bucketeer/proto/event/domain"
+	featureproto "github.com/bucketeer-io/bucketeer/proto/feature"
 	notificationproto "github.com/bucketeer-io/bucketeer/proto/notification"
 	"github.com/bucketeer-io/bucketeer/proto/notification/sender"
 	senderproto "github.com/bucketeer-io/bucketeer/proto/notification/sender"
@@ -148,6 +152,16 @@ func (n *slackNotifier) createAttachment(notification *sender.Notification) (*sl
 		return n.createExperimentRunningAttachment(notification.ExperimentRunningNotification)
 	case sender.Notification_MauCount:
 		return n.createMAUCountAttachment(notification.MauCountNotification)
+	case sender.Notification_FeatureCleanup:
+		return n.createFeatureCleanupAttachment(notification.FeatureCleanupNotification)
+	case sender.Notification_FeatureExpiration:
+		return n.createFeatureExpirationAttachment(notification.FeatureExpirationNotification)
+	case sender.Notification_ExperimentSampleRatioMismatch:
+		return n.createExperimentSampleRatioMismatchAttachment(
+			notification.ExperimentSampleRatioMismatchNotification,
+		)
+	case sender.Notification_AutoOpsExecution:
+		return n.createAutoOpsExecutionAttachment(notification.AutoOpsExecutionNotification)
 	}
 	return nil, ErrUnknownNotification
 }
@@ -211,6 +225,117 @@ func (n *slackNotifier) createFeatureStaleAttachment(
 	return attachment, nil
 }
 
+func (n *slackNotifier) createFeatureCleanupAttachment(
+	notification *senderproto.FeatureCleanupNotification,
+) (*slack.Attachment, error) {
+	text := ""
+	sections := []struct {
+		msgType    msgType
+		candidates []*featureproto.FeatureCleanupCandidate
+	}{
+		{msgTypeFeatureCleanupWarned, notification.WarnedCandidates},
+		{msgTypeFeatureCleanupArchived, notification.ArchivedCandidates},
+	}
+	for _, section := range sections {
+		if len(section.candidates) == 0 {
+			continue
+		}
+		listMsg := ""
+		for _, c := range section.candidates {
+			url, err := domainevent.URL(
+				domainproto.Event_FEATURE,
+				n.webURL,
+				notification.EnvironmentId,
+				c.FeatureId,
+			)
+			if err != nil {
+				return nil, err
+			}
+			name := c.FeatureId
+			if c.Feature != nil {
+				name = c.Feature.Name
+			}
+			reasons := make([]string, 0, len(c.Reasons))
+			for _, r := range c.Reasons {
+				reasons = append(reasons, r.String())
+			}
+			newLine := "- ID: `" + c.FeatureId + "`, Name: *" + fmt.Sprintf(linkTemplate, url, name) + "*" +
+				", Reasons: `" + strings.Join(reasons, ", ") + "`"
+			if c.ArchiveAt != 0 {
+				newLine += ", Archive at: " + time.Unix(c.ArchiveAt, 0).Format(time.RFC3339)
+			}
+			if c.UndoDeadline != 0 {
+				newLine += ", Undo until: " + time.Unix(c.UndoDeadline, 0).Format(time.RFC3339)
+			}
+			listMsg = listMsg + newLine + "\n"
+		}
+		// handle loc if multi-lang is necessary
+		msg, err := localizedMessage(section.msgType, locale.JaJP)
+		if err != nil {
+			return nil, err
+		}
+		text = text + msg.Message + "\n\n" +
+			"Environment: " + notification.EnvironmentId + "\n\n" +
+			"Feature flags: \n\n" +
+			listMsg + "\n"
+	}
+	attachment := &slack.Attachment{
+		Color:      "#F4D03F",
+		MarkdownIn: []string{"text"},
+		Text:       text,
+	}
+	return attachment, nil
+}
+
+func (n *slackNotifier) createFeatureExpirationAttachment(
+	notification *senderproto.FeatureExpirationNotification,
+) (*slack.Attachment, error) {
+	text := ""
+	sections := []struct {
+		msgType  msgType
+		features []*featureproto.Feature
+	}{
+		{msgTypeFeatureRemovalDateApproaching, notification.ApproachingFeatures},
+		{msgTypeFeatureRemovalDatePassed, notification.ExpiredFeatures},
+	}
+	for _, section := range sections {
+		if len(section.features) == 0 {
+			continue
+		}
+		listMsg := ""
+		for _, f := range section.features {
+			url, err := domainevent.URL(
+				domainproto.Event_FEATURE,
+				n.webURL,
+				notification.EnvironmentId,
+				f.Id,
+			)
+			if err != nil {
+				return nil, err
+			}
+			newLine := "- ID: `" + f.Id + "`, Name: *" + fmt.Sprintf(linkTemplate, url, f.Name) + "*" +
+				", Maintainer: " + f.Maintainer +
+				", Removal date: " + time.Unix(f.ExpectedRemovalAt, 0).Format("2006-01-02") + "\n"
+			listMsg = listMsg + newLine
+		}
+		// handle loc if multi-lang is necessary
+		msg, err := localizedMessage(section.msgType, locale.JaJP)
+		if err != nil {
+			return nil, err
+		}
+		text = text + msg.Message + "\n\n" +
+			"Environment: " + notification.EnvironmentId + "\n\n" +
+			"Feature flags: \n\n" +
+			listMsg + "\n"
+	}
+	attachment := &slack.Attachment{
+		Color:      "#F4D03F",
+		MarkdownIn: []string{"text"},
+		Text:       text,
+	}
+	return attachment, nil
+}
+
 func (n *slackNotifier) createExperimentRunningAttachment(
 	notification *senderproto.ExperimentRunningNotification,
 ) (*slack.Attachment, error) {
@@ -246,6 +371,144 @@ func (n *slackNotifier) createExperimentRunningAttachment(
 	return attachment, nil
 }
 
+func (n *slackNotifier) createExperimentSampleRatioMismatchAttachment(
+	notification *senderproto.ExperimentSampleRatioMismatchNotification,
+) (*slack.Attachment, error) {
+	listMsg := ""
+	for _, m := range notification.Mismatches {
+		url, err := domainevent.URL(
+			domainproto.Event_EXPERIMENT,
+			n.webURL,
+			notification.EnvironmentId,
+			m.Experiment.Id,
+		)
+		if err != nil {
+			return nil, err
+		}
+		nameLink := fmt.Sprintf(linkTemplate, url, m.Experiment.Name)
+		newLine := fmt.Sprintf("- Name: *%s*, p-value: `%.6f`\n", nameLink, m.PValue)
+		listMsg = listMsg + newLine
+	}
+	// handle loc if multi-lang is necessary
+	msg, err := localizedMessage(msgTypeExperimentSampleRatioMismatch, locale.JaJP)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &slack.Attachment{
+		Color:      "#E74C3C",
+		MarkdownIn: []string{"text"},
+		Text: msg.Message + "\n\n" +
+			"Environment: " + notification.EnvironmentId + "\n\n" +
+			"Experiments: \n\n" +
+			listMsg,
+	}
+	return attachment, nil
+}
+
+func (n *slackNotifier) createAutoOpsExecutionAttachment(
+	notification *senderproto.AutoOpsExecutionNotification,
+) (*slack.Attachment, error) {
+	execution := notification.Execution
+	url, err := domainevent.URL(
+		domainproto.Event_AUTOOPS_RULE,
+		n.webURL,
+		notification.EnvironmentId,
+		execution.FeatureId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	triggerMsg, err := autoOpsTriggerMessage(notification.AutoOpsRule, execution.Trigger)
+	if err != nil {
+		return nil, err
+	}
+	action := execution.OpsType.String()
+	if execution.Reversed {
+		action = action + " (reversed)"
+	}
+	// handle loc if multi-lang is necessary
+	msg, err := localizedMessage(msgTypeAutoOpsExecution, locale.JaJP)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &slack.Attachment{
+		Color:      "#E67E22",
+		AuthorName: notification.Editor.GetEmail(),
+		MarkdownIn: []string{"text"},
+		Text: msg.Message + "\n\n" +
+			"Environment: " + notification.EnvironmentId + "\n" +
+			"Feature flag: " + fmt.Sprintf(linkTemplate, url, execution.FeatureId) + "\n" +
+			"Auto operation rule ID: `" + execution.AutoOpsRuleId + "`\n" +
+			"Action: `" + action + "`\n" +
+			fmt.Sprintf("Feature flag version: `%d`", execution.FeatureVersion) + "\n\n" +
+			"Trigger: \n\n" +
+			triggerMsg,
+	}
+	return attachment, nil
+}
+
+// autoOpsTriggerMessage explains the clause that triggered the execution with its input values.
+func autoOpsTriggerMessage(
+	rule *autoopsproto.AutoOpsRule,
+	trigger *autoopsproto.ExecutionTrigger,
+) (string, error) {
+	if trigger == nil {
+		return "- Manual execution\n", nil
+	}
+	var clause *autoopsproto.Clause
+	for _, c := range rule.GetClauses() {
+		if c.Id == trigger.ClauseId {
+			clause = c
+			break
+		}
+	}
+	if clause == nil {
+		return "- Clause ID: `" + trigger.ClauseId + "`\n", nil
+	}
+	var c ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(clause.Clause, &c); err != nil {
+		return "", err
+	}
+	msg := "- Clause ID: `" + trigger.ClauseId + "`\n"
+	switch cl := c.Message.(type) {
+	case *autoopsproto.OpsEventRateClause:
+		rate := 0.0
+		if trigger.EvaluationCount > 0 {
+			rate = float64(trigger.OpsEventCount) / float64(trigger.EvaluationCount) * 100
+		}
+		msg += fmt.Sprintf(
+			"- Event rate: goal `%s`, variation `%s`, `%s` `%.2f%%`\n",
+			cl.GoalId, cl.VariationId, cl.Operator.String(), cl.ThreadsholdRate*100,
+		)
+		msg += fmt.Sprintf(
+			"- Goal count: `%d`, Evaluation count: `%d`, Rate: `%.2f%%`\n",
+			trigger.OpsEventCount, trigger.EvaluationCount, rate,
+		)
+	case *autoopsproto.DatetimeClause:
+		msg += "- Datetime: `" + time.Unix(trigger.Time, 0).UTC().Format(time.RFC3339) + "`\n"
+	case *autoopsproto.WebhookClause:
+		msg += "- Webhook ID: `" + cl.WebhookId + "`\n"
+		msg += "- Payload: \n```" + trigger.WebhookPayload + "```\n"
+	case *autoopsproto.PrometheusClause:
+		msg += fmt.Sprintf(
+			"- Prometheus: `%s` `%s` `%g`\n",
+			cl.Query, cl.Operator.String(), cl.Threshold,
+		)
+		msg += fmt.Sprintf("- Value: `%g`\n", trigger.PrometheusValue)
+	case *autoopsproto.ScheduleClause:
+		timeZone := cl.TimeZone
+		if timeZone == "" {
+			timeZone = "UTC"
+		}
+		msg += fmt.Sprintf(
+			"- Schedule: enable `%s`, disable `%s`, `%s`\n",
+			cl.EnableCron, cl.DisableCron, timeZone,
+		)
+		msg += "- Scheduled time: `" + time.Unix(trigger.Time, 0).UTC().Format(time.RFC3339) + "`\n"
+	}
+	return msg, nil
+}
+
 func (n *slackNotifier) createMAUCountAttachment(
 	notification *senderproto.MauCountNotification,
 ) (*slack.Attachment, error) {
@@ -18,7 +18,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 )
 
 func TestLastDays(t *testing.T) {
@@ -51,3 +55,73 @@ func TestLastDays(t *testing.T) {
 		})
 	}
 }
+
+func TestAutoOpsTriggerMessage(t *testing.T) {
+	rateClause, err := ptypes.MarshalAny(&autoopsproto.OpsEventRateClause{
+		GoalId:          "gid",
+		VariationId:     "vid",
+		ThreadsholdRate: 0.5,
+		Operator:        autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+	})
+	require.NoError(t, err)
+	datetimeClause, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: 1})
+	require.NoError(t, err)
+	webhookClause, err := ptypes.MarshalAny(&autoopsproto.WebhookClause{WebhookId: "wid"})
+	require.NoError(t, err)
+	scheduleClause, err := ptypes.MarshalAny(&autoopsproto.ScheduleClause{
+		EnableCron:  "0 18 * * 1-5",
+		DisableCron: "0 22 * * 1-5",
+	})
+	require.NoError(t, err)
+	rule := &autoopsproto.AutoOpsRule{
+		Clauses: []*autoopsproto.Clause{
+			{Id: "c1", Clause: rateClause},
+			{Id: "c2", Clause: datetimeClause},
+			{Id: "c3", Clause: webhookClause},
+			{Id: "c4", Clause: scheduleClause},
+		},
+	}
+	patterns := map[string]struct {
+		trigger  *autoopsproto.ExecutionTrigger
+		expected string
+	}{
+		"manual": {
+			trigger:  nil,
+			expected: "- Manual execution\n",
+		},
+		"unknown clause": {
+			trigger:  &autoopsproto.ExecutionTrigger{ClauseId: "c0"},
+			expected: "- Clause ID: `c0`\n",
+		},
+		"ops event rate": {
+			trigger: &autoopsproto.ExecutionTrigger{ClauseId: "c1", OpsEventCount: 10, EvaluationCount: 20},
+			expected: "- Clause ID: `c1`\n" +
+				"- Event rate: goal `gid`, variation `vid`, `GREATER_OR_EQUAL` `50.00%`\n" +
+				"- Goal count: `10`, Evaluation count: `20`, Rate: `50.00%`\n",
+		},
+		"datetime": {
+			trigger: &autoopsproto.ExecutionTrigger{ClauseId: "c2", Time: 1},
+			expected: "- Clause ID: `c2`\n" +
+				"- Datetime: `1970-01-01T00:00:01Z`\n",
+		},
+		"webhook": {
+			trigger: &autoopsproto.ExecutionTrigger{ClauseId: "c3", WebhookPayload: `{"status":"firing"}`},
+			expected: "- Clause ID: `c3`\n" +
+				"- Webhook ID: `wid`\n" +
+				"- Payload: \n```{\"status\":\"firing\"}```\n",
+		},
+		"schedule": {
+			trigger: &autoopsproto.ExecutionTrigger{ClauseId: "c4", Time: 1},
+			expected: "- Clause ID: `c4`\n" +
+				"- Schedule: enable `0 18 * * 1-5`, disable `0 22 * * 1-5`, `UTC`\n" +
+				"- Scheduled time: `1970-01-01T00:00:01Z`\n",
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			actual, err := autoOpsTriggerMessage(rule, p.trigger)
+			assert.NoError(t, err)
+			assert.Equal(t, p.expected, actual)
+		})
+	}
+}
@@ -37,7 +37,7 @@ func WithLogger(l *zap.Logger) Option {
 }
 
 type AutoOpsExecutor interface {
-	Execute(ctx context.Context, environmentNamespace, ruleID string) error
+	Execute(ctx context.Context, environmentNamespace, ruleID string, trigger *autoopsproto.ExecutionTrigger) error
 }
 
 type autoOpsExecutor struct {
@@ -58,11 +58,16 @@ func NewAutoOpsExecutor(autoOpsClient autoopsclient.Client, opts ...Option) Auto
 	}
 }
 
-func (e *autoOpsExecutor) Execute(ctx context.Context, environmentNamespace, ruleID string) error {
+func (e *autoOpsExecutor) Execute(
+	ctx context.Context,
+	environmentNamespace, ruleID string,
+	trigger *autoopsproto.ExecutionTrigger,
+) error {
 	resp, err := e.autoOpsClient.ExecuteAutoOps(ctx, &autoopsproto.ExecuteAutoOpsRequest{
 		EnvironmentNamespace:                environmentNamespace,
 		Id:                                  ruleID,
 		ChangeAutoOpsRuleTriggeredAtCommand: &autoopsproto.ChangeAutoOpsRuleTriggeredAtCommand{},
+		Trigger:                             trigger,
 	})
 	if err != nil {
 		e.logger.Error("Failed to execute auto ops", zap.Error(err),
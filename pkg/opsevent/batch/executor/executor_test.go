@@ -76,7 +76,7 @@ func TestExecute(t *testing.T) {
 			if p.setup != nil {
 				p.setup(e)
 			}
-			err := e.Execute(context.Background(), "ns0", "rid1")
+			err := e.Execute(context.Background(), "ns0", "rid1", &autoopsproto.ExecutionTrigger{ClauseId: "cid"})
 			assert.Equal(t, p.expectedErr, err)
 		})
 	}
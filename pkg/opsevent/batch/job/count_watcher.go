@@ -23,6 +23,7 @@ import (
 	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
 	environmentdomain "github.com/bucketeer-io/bucketeer/pkg/environment/domain"
 	ecclient "github.com/bucketeer-io/bucketeer/pkg/eventcounter/client"
+	"github.com/bucketeer-io/bucketeer/pkg/eventcounter/stats"
 	ftclient "github.com/bucketeer-io/bucketeer/pkg/feature/client"
 	"github.com/bucketeer-io/bucketeer/pkg/job"
 	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor"
@@ -84,14 +85,14 @@ func (w *countWatcher) Run(ctx context.Context) (lastErr error) {
 	for _, env := range environments {
 		autoOpsRules := w.autoOpsRuleLister.GetAutoOpsRules(ctx, env.Namespace)
 		for _, a := range autoOpsRules {
-			asmt, err := w.assessAutoOpsRule(ctx, env, a)
+			trigger, err := w.assessAutoOpsRule(ctx, env, a)
 			if err != nil {
 				lastErr = err
 			}
-			if !asmt {
+			if trigger == nil {
 				continue
 			}
-			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id); err != nil {
+			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id, trigger); err != nil {
 				lastErr = err
 			}
 		}
@@ -99,11 +100,12 @@ func (w *countWatcher) Run(ctx context.Context) (lastErr error) {
 	return
 }
 
+// assessAutoOpsRule returns the trigger of the rule, or nil if the rule is not satisfied.
 func (w *countWatcher) assessAutoOpsRule(
 	ctx context.Context,
 	env *environmentdomain.Environment,
 	a *autoopsdomain.AutoOpsRule,
-) (bool, error) {
+) (*autoopsproto.ExecutionTrigger, error) {
 	opsEventRateClauses, err := a.ExtractOpsEventRateClauses()
 	if err != nil {
 		w.logger.Error("Failed to extract ops event rate clauses", zap.Error(err),
@@ -111,7 +113,7 @@ func (w *countWatcher) assessAutoOpsRule(
 			zap.String("featureId", a.FeatureId),
 			zap.String("autoOpsRuleId", a.Id),
 		)
-		return false, err
+		return nil, err
 	}
 	featureVersion, err := w.getLatestFeatureVersion(ctx, a.FeatureId, env.Namespace)
 	if err != nil {
@@ -120,9 +122,11 @@ func (w *countWatcher) assessAutoOpsRule(
 			zap.String("featureId", a.FeatureId),
 			zap.String("autoOpsRuleId", a.Id),
 		)
-		return false, err
+		return nil, err
 	}
 	var lastErr error
+	var trigger *autoopsproto.ExecutionTrigger
+	results := make(map[string]bool, len(opsEventRateClauses))
 	for id, c := range opsEventRateClauses {
 		logFunc := func(msg string) {
 			w.logger.Debug(msg,
@@ -163,21 +167,126 @@ func (w *countWatcher) assessAutoOpsRule(
 			continue
 		}
 		opsCount := opseventdomain.NewOpsCount(a.FeatureId, a.Id, id, opsEventCount.UserCount, evaluationCount.UserCount)
+		opsCount.Satisfied = w.assessRule(c, evaluationCount, opsEventCount)
+		if c.BaselineVariationId != "" {
+			significant, err := w.assessSignificance(
+				ctx,
+				logFunc,
+				env.Namespace,
+				a.FeatureId,
+				featureVersion,
+				c,
+				opsCount,
+			)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			opsCount.Satisfied = opsCount.Satisfied && significant
+		}
 		if err = w.persistOpsCount(ctx, env.Namespace, opsCount); err != nil {
 			lastErr = err
 			continue
 		}
-		if asmt := w.assessRule(c, evaluationCount, opsEventCount); asmt {
-			w.logger.Info("Clause satisfies condition",
+		if !opsCount.Satisfied {
+			continue
+		}
+		sustained, err := w.isSustained(ctx, env.Namespace, a, id)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !sustained {
+			logFunc("Clause has not satisfied condition for the sustained evaluations")
+			continue
+		}
+		w.logger.Info("Clause satisfies condition",
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+			zap.Any("opsEventRateClause", c),
+		)
+		results[id] = true
+		trigger = &autoopsproto.ExecutionTrigger{
+			ClauseId:        id,
+			OpsEventCount:   opsCount.OpsEventCount,
+			EvaluationCount: opsCount.EvaluationCount,
+			FeatureVersion:  featureVersion,
+		}
+	}
+	now := time.Now().Unix()
+	if a.ClauseOperator == autoopsproto.AutoOpsRule_AND {
+		datetimeResults, err := a.AssessDatetimeClauses(now)
+		if err != nil {
+			w.logger.Error("Failed to assess datetime clauses", zap.Error(err),
 				zap.String("environmentNamespace", env.Namespace),
 				zap.String("featureId", a.FeatureId),
 				zap.String("autoOpsRuleId", a.Id),
-				zap.Any("opsEventRateClause", c),
 			)
-			return true, nil
+			return nil, err
+		}
+		for id, r := range datetimeResults {
+			results[id] = r
+		}
+	}
+	if !a.Assess(results) {
+		return nil, lastErr
+	}
+	w.logger.Info("Rule satisfies condition",
+		zap.String("environmentNamespace", env.Namespace),
+		zap.String("featureId", a.FeatureId),
+		zap.String("autoOpsRuleId", a.Id),
+	)
+	if trigger == nil {
+		// The rule is satisfied only by its datetime clauses.
+		if trigger, err = a.LatestDatetimeTrigger(now); err != nil {
+			return nil, err
+		}
+	}
+	return trigger, lastErr
+}
+
+// isSustained reports whether the clause has been satisfied for the sustained evaluations of the rule
+// since the rule was armed.
+// It must be called after the current assessment is persisted.
+func (w *countWatcher) isSustained(
+	ctx context.Context,
+	environmentNamespace string,
+	a *autoopsdomain.AutoOpsRule,
+	clauseID string,
+) (bool, error) {
+	if a.SustainedEvaluations <= 1 {
+		return true, nil
+	}
+	storage := v2os.NewOpsCountHistoryStorage(w.mysqlClient)
+	history, _, err := storage.ListOpsCountHistory(
+		ctx,
+		[]mysql.WherePart{
+			mysql.NewFilter("environment_namespace", "=", environmentNamespace),
+			mysql.NewFilter("auto_ops_rule_id", "=", a.Id),
+			mysql.NewFilter("clause_id", "=", clauseID),
+			mysql.NewFilter("updated_at", ">=", a.ArmedSince()),
+		},
+		[]*mysql.Order{mysql.NewOrder("updated_at", mysql.OrderDirectionDesc)},
+		int(a.SustainedEvaluations),
+		0,
+	)
+	if err != nil {
+		w.logger.Error("Failed to list ops count history", zap.Error(err),
+			zap.String("autoOpsRuleId", a.Id),
+			zap.String("clauseId", clauseID),
+			zap.String("environmentNamespace", environmentNamespace))
+		return false, err
+	}
+	if len(history) < int(a.SustainedEvaluations) {
+		return false, nil
+	}
+	for _, h := range history {
+		if !h.Satisfied {
+			return false, nil
 		}
 	}
-	return false, lastErr
+	return true, nil
 }
 
 func (w *countWatcher) getLatestFeatureVersion(
@@ -200,21 +309,68 @@ func (w *countWatcher) assessRule(
 	evaluationCount,
 	opsCount *ecproto.VariationCount,
 ) bool {
-	rate := float64(opsCount.UserCount) / float64(evaluationCount.UserCount)
-	if opsCount.UserCount < opsEventRateClause.MinCount {
-		return false
-	}
-	switch opsEventRateClause.Operator {
-	case autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL:
-		if rate >= opsEventRateClause.ThreadsholdRate {
-			return true
-		}
-	case autoopsproto.OpsEventRateClause_LESS_OR_EQUAL:
-		if rate <= opsEventRateClause.ThreadsholdRate {
-			return true
+	return autoopsdomain.AssessOpsEventRate(opsEventRateClause, opsCount.UserCount, evaluationCount.UserCount)
+}
+
+// assessSignificance reports whether the ops event rate of the variation differs from the baseline variation
+// in the direction of the clause operator with statistical significance.
+// The baseline counts and the test statistic are set to the ops count.
+func (w *countWatcher) assessSignificance(
+	ctx context.Context,
+	logFunc func(string),
+	environmentNamespace, featureID string,
+	featureVersion int32,
+	opsEventRateClause *autoopsproto.OpsEventRateClause,
+	opsCount *opseventdomain.OpsCount,
+) (bool, error) {
+	baselineEvaluationCount, err := w.getTargetEvaluationCount(
+		ctx,
+		logFunc,
+		environmentNamespace,
+		featureID,
+		opsEventRateClause.BaselineVariationId,
+		featureVersion,
+	)
+	if err != nil {
+		return false, err
+	}
+	if baselineEvaluationCount == nil {
+		return false, nil
+	}
+	baselineOpsEventCount, err := w.getTargetOpsEventCount(
+		ctx,
+		logFunc,
+		environmentNamespace,
+		featureID,
+		opsEventRateClause.BaselineVariationId,
+		opsEventRateClause.GoalId,
+		featureVersion,
+	)
+	if err != nil {
+		return false, err
+	}
+	if baselineOpsEventCount == nil {
+		return false, nil
+	}
+	opsCount.BaselineEvaluationCount = baselineEvaluationCount.UserCount
+	opsCount.BaselineOpsEventCount = baselineOpsEventCount.UserCount
+	significant, z, pValue, err := autoopsdomain.AssessOpsEventRateSignificance(
+		opsEventRateClause,
+		baselineOpsEventCount.UserCount,
+		baselineEvaluationCount.UserCount,
+		opsCount.OpsEventCount,
+		opsCount.EvaluationCount,
+	)
+	if err != nil {
+		if err == stats.ErrZeroVariance {
+			logFunc("ops event rates have no variance")
+			return false, nil
 		}
+		return false, err
 	}
-	return false
+	opsCount.ZScore = z
+	opsCount.PValue = pValue
+	return significant, nil
 }
 
 func (w *countWatcher) getTargetEvaluationCount(
@@ -350,5 +506,17 @@ func (w *countWatcher) persistOpsCount(
 			zap.String("environmentNamespace", environmentNamespace))
 		return err
 	}
+	history, err := oc.NewHistory()
+	if err != nil {
+		return err
+	}
+	historyStorage := v2os.NewOpsCountHistoryStorage(w.mysqlClient)
+	if err := historyStorage.CreateOpsCountHistory(ctx, environmentNamespace, history); err != nil {
+		w.logger.Error("Failed to create ops count history", zap.Error(err),
+			zap.String("autoOpsRuleId", oc.AutoOpsRuleId),
+			zap.String("clauseId", oc.ClauseId),
+			zap.String("environmentNamespace", environmentNamespace))
+		return err
+	}
 	return nil
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/bucketeer-io/bucketeer/pkg/log"
 	executormock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor/mock"
 	targetstoremock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore/mock"
+	opseventdomain "github.com/bucketeer-io/bucketeer/pkg/opsevent/domain"
 	mysqlmock "github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
 	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
@@ -159,6 +160,143 @@ func TestRunCountWatcher(t *testing.T) {
 			},
 			expectedErr: status.Errorf(codes.NotFound, "test"),
 		},
+		"success: assess: true": {
+			setup: func(t *testing.T, w *countWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				oerc1, _ := newOpsEventRateClauses(t)
+				c1, err := ptypes.MarshalAny(oerc1)
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:        "id-0",
+							FeatureId: "fid-0",
+							Clauses:   []*autoopsproto.Clause{{Id: "c1", Clause: c1}},
+						}},
+					},
+				)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetEvaluationCountV2Response{Count: &ecproto.EvaluationCount{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 20}},
+					}}, nil)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetGoalCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetGoalCountV2Response{GoalCounts: &ecproto.GoalCounts{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 10}},
+					}}, nil)
+				w.featureClient.(*ftmock.MockClient).EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&ftproto.GetFeatureResponse{
+						Feature: &ftproto.Feature{
+							Version: 1,
+						},
+					}, nil)
+				w.mysqlClient.(*mysqlmock.MockClient).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil).Times(2)
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(
+					gomock.Any(), "ns0", "id-0",
+					&autoopsproto.ExecutionTrigger{
+						ClauseId:        "c1",
+						OpsEventCount:   10,
+						EvaluationCount: 20,
+						FeatureVersion:  1,
+					},
+				).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		"success: not sustained": {
+			setup: func(t *testing.T, w *countWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				oerc1, _ := newOpsEventRateClauses(t)
+				c1, err := ptypes.MarshalAny(oerc1)
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:                   "id-0",
+							FeatureId:            "fid-0",
+							Clauses:              []*autoopsproto.Clause{{Id: "c1", Clause: c1}},
+							SustainedEvaluations: 3,
+						}},
+					},
+				)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetEvaluationCountV2Response{Count: &ecproto.EvaluationCount{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 20}},
+					}}, nil)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetGoalCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetGoalCountV2Response{GoalCounts: &ecproto.GoalCounts{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 10}},
+					}}, nil)
+				w.featureClient.(*ftmock.MockClient).EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&ftproto.GetFeatureResponse{
+						Feature: &ftproto.Feature{
+							Version: 1,
+						},
+					}, nil)
+				w.mysqlClient.(*mysqlmock.MockClient).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil).Times(2)
+				rows := mysqlmock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				w.mysqlClient.(*mysqlmock.MockClient).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			expectedErr: nil,
+		},
+		"success: and: datetime clause not satisfied": {
+			setup: func(t *testing.T, w *countWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				oerc1, _ := newOpsEventRateClauses(t)
+				c1, err := ptypes.MarshalAny(oerc1)
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().AddDate(0, 0, 1).Unix()})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:             "id-0",
+							FeatureId:      "fid-0",
+							Clauses:        []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							ClauseOperator: autoopsproto.AutoOpsRule_AND,
+						}},
+					},
+				)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetEvaluationCountV2Response{Count: &ecproto.EvaluationCount{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 20}},
+					}}, nil)
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetGoalCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetGoalCountV2Response{GoalCounts: &ecproto.GoalCounts{
+						RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid1", UserCount: 10}},
+					}}, nil)
+				w.featureClient.(*ftmock.MockClient).EXPECT().GetFeature(gomock.Any(), gomock.Any()).Return(
+					&ftproto.GetFeatureResponse{
+						Feature: &ftproto.Feature{
+							Version: 1,
+						},
+					}, nil)
+				w.mysqlClient.(*mysqlmock.MockClient).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, nil).Times(2)
+			},
+			expectedErr: nil,
+		},
 	}
 	for msg, p := range patterns {
 		t.Run(msg, func(t *testing.T) {
@@ -289,6 +427,90 @@ func TestCountWatcherAssessRule(t *testing.T) {
 	}
 }
 
+func TestCountWatcherAssessSignificance(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		setup           func(*countWatcher)
+		operator        autoopsproto.OpsEventRateClause_Operator
+		confidenceLevel float64
+		expected        bool
+		expectedZScore  float64
+	}{
+		"false: no baseline evaluation": {
+			setup: func(w *countWatcher) {
+				w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+					&ecproto.GetEvaluationCountV2Response{Count: &ecproto.EvaluationCount{}}, nil)
+			},
+			expected: false,
+		},
+		"true: greater": {
+			setup: func(w *countWatcher) {
+				setBaselineCounts(w, 100, 1000)
+			},
+			operator:       autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+			expected:       true,
+			expectedZScore: 2.102741,
+		},
+		"false: greater: high confidence level": {
+			setup: func(w *countWatcher) {
+				setBaselineCounts(w, 100, 1000)
+			},
+			operator:        autoopsproto.OpsEventRateClause_GREATER_OR_EQUAL,
+			confidenceLevel: 0.99,
+			expected:        false,
+			expectedZScore:  2.102741,
+		},
+		"false: less": {
+			setup: func(w *countWatcher) {
+				setBaselineCounts(w, 100, 1000)
+			},
+			operator:       autoopsproto.OpsEventRateClause_LESS_OR_EQUAL,
+			expected:       false,
+			expectedZScore: 2.102741,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newNewCountWatcherWithMock(t, mockController)
+			p.setup(w)
+			clause := &autoopsproto.OpsEventRateClause{
+				VariationId:         "vid1",
+				GoalId:              "gid1",
+				Operator:            p.operator,
+				BaselineVariationId: "vid0",
+				ConfidenceLevel:     p.confidenceLevel,
+			}
+			opsCount := opseventdomain.NewOpsCount("fid-0", "id-0", "c1", 130, 1000)
+			actual, err := w.assessSignificance(
+				context.Background(),
+				func(string) {},
+				"ns0",
+				"fid-0",
+				1,
+				clause,
+				opsCount,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, p.expected, actual)
+			assert.InDelta(t, p.expectedZScore, opsCount.ZScore, 1e-6)
+		})
+	}
+}
+
+func setBaselineCounts(w *countWatcher, opsEventCount, evaluationCount int64) {
+	w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetEvaluationCountV2(gomock.Any(), gomock.Any()).Return(
+		&ecproto.GetEvaluationCountV2Response{Count: &ecproto.EvaluationCount{
+			RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid0", UserCount: evaluationCount}},
+		}}, nil)
+	w.eventCounterClient.(*eccmock.MockClient).EXPECT().GetGoalCountV2(gomock.Any(), gomock.Any()).Return(
+		&ecproto.GetGoalCountV2Response{GoalCounts: &ecproto.GoalCounts{
+			RealtimeCounts: []*ecproto.VariationCount{{VariationId: "vid0", UserCount: opsEventCount}},
+		}}, nil)
+}
+
 func newOpsEventRateClauses(t *testing.T) (*autoopsproto.OpsEventRateClause, *autoopsproto.OpsEventRateClause) {
 	t.Helper()
 	oerc1 := &autoopsproto.OpsEventRateClause{
@@ -64,14 +64,14 @@ func (w *datetimeWatcher) Run(ctx context.Context) (lastErr error) {
 	for _, env := range environments {
 		autoOpsRules := w.autoOpsRuleLister.GetAutoOpsRules(ctx, env.Namespace)
 		for _, a := range autoOpsRules {
-			asmt, err := w.assessAutoOpsRule(ctx, env, a)
+			trigger, err := w.assessAutoOpsRule(ctx, env, a)
 			if err != nil {
 				lastErr = err
 			}
-			if !asmt {
+			if trigger == nil {
 				continue
 			}
-			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id); err != nil {
+			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id, trigger); err != nil {
 				lastErr = err
 			}
 		}
@@ -79,11 +79,15 @@ func (w *datetimeWatcher) Run(ctx context.Context) (lastErr error) {
 	return
 }
 
+// assessAutoOpsRule returns the trigger of the rule, or nil if the rule is not satisfied.
 func (w *datetimeWatcher) assessAutoOpsRule(
 	ctx context.Context,
 	env *environmentdomain.Environment,
 	a *autoopsdomain.AutoOpsRule,
-) (bool, error) {
+) (*autoopsproto.ExecutionTrigger, error) {
+	if a.ClauseOperator == autoopsproto.AutoOpsRule_AND {
+		return w.assessAllClauses(env, a)
+	}
 	datetimeClauses, err := a.ExtractDatetimeClauses()
 	if err != nil {
 		w.logger.Error("Failed to extract datetime clauses", zap.Error(err),
@@ -91,22 +95,70 @@ func (w *datetimeWatcher) assessAutoOpsRule(
 			zap.String("featureId", a.FeatureId),
 			zap.String("autoOpsRuleId", a.Id),
 		)
-		return false, err
+		return nil, err
 	}
 	var lastErr error
 	nowTimestamp := time.Now().Unix()
 	for _, c := range datetimeClauses {
-		if asmt := w.assessRule(c, nowTimestamp); asmt {
+		if asmt := w.assessRule(c, nowTimestamp) && a.DatetimeClauseArmed(c); asmt {
 			w.logger.Info("Clause satisfies condition",
 				zap.String("environmentNamespace", env.Namespace),
 				zap.String("featureId", a.FeatureId),
 				zap.String("autoOpsRuleId", a.Id),
 				zap.Any("datetimeClause", c),
 			)
-			return true, nil
+			return w.trigger(env, a, nowTimestamp)
 		}
 	}
-	return false, lastErr
+	return nil, lastErr
+}
+
+// assessAllClauses assesses the rule whose clauses must all be satisfied.
+// The rule that has other than datetime clauses is left to the other watchers.
+func (w *datetimeWatcher) assessAllClauses(
+	env *environmentdomain.Environment,
+	a *autoopsdomain.AutoOpsRule,
+) (*autoopsproto.ExecutionTrigger, error) {
+	nowTimestamp := time.Now().Unix()
+	results, err := a.AssessDatetimeClauses(nowTimestamp)
+	if err != nil {
+		w.logger.Error("Failed to assess datetime clauses", zap.Error(err),
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+		)
+		return nil, err
+	}
+	if len(results) < len(a.Clauses) {
+		return nil, nil
+	}
+	if !a.Assess(results) {
+		return nil, nil
+	}
+	w.logger.Info("Rule satisfies condition",
+		zap.String("environmentNamespace", env.Namespace),
+		zap.String("featureId", a.FeatureId),
+		zap.String("autoOpsRuleId", a.Id),
+	)
+	return w.trigger(env, a, nowTimestamp)
+}
+
+// trigger returns the datetime clause satisfied last as the trigger of the satisfied rule.
+func (w *datetimeWatcher) trigger(
+	env *environmentdomain.Environment,
+	a *autoopsdomain.AutoOpsRule,
+	nowTimestamp int64,
+) (*autoopsproto.ExecutionTrigger, error) {
+	trigger, err := a.LatestDatetimeTrigger(nowTimestamp)
+	if err != nil {
+		w.logger.Error("Failed to get the datetime trigger", zap.Error(err),
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+		)
+		return nil, err
+	}
+	return trigger, nil
 }
 
 func (w *datetimeWatcher) assessRule(datetimeClause *autoopsproto.DatetimeClause, nowTimestamp int64) bool {
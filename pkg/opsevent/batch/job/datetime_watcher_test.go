@@ -102,7 +102,158 @@ func TestRunDatetimeWatcher(t *testing.T) {
 						}},
 					},
 				)
-				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		"success: cooldown passed: datetime before the rule was re-armed": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				now := time.Now()
+				dc := &autoopsproto.DatetimeClause{Time: now.Add(-2 * time.Hour).Unix()}
+				c, err := ptypes.MarshalAny(dc)
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:              "id-0",
+							FeatureId:       "fid-0",
+							Clauses:         []*autoopsproto.Clause{{Id: "c1", Clause: c}},
+							TriggeredAt:     dc.Time,
+							CooldownSeconds: 60,
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: cooldown passed: datetime after the rule was re-armed": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				now := time.Now()
+				c1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: now.Add(-2 * time.Hour).Unix()})
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: now.Unix()})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:              "id-0",
+							FeatureId:       "fid-0",
+							Clauses:         []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							TriggeredAt:     now.Add(-2 * time.Hour).Unix(),
+							CooldownSeconds: 60,
+						}},
+					},
+				)
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(
+					gomock.Any(), "ns0", "id-0", &autoopsproto.ExecutionTrigger{ClauseId: "c2", Time: now.Unix()},
+				).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		"success: and: assess: false": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				c1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().Unix()})
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().AddDate(0, 0, 1).Unix()})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:             "id-0",
+							FeatureId:      "fid-0",
+							Clauses:        []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							ClauseOperator: autoopsproto.AutoOpsRule_AND,
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: and: ops event rate clause is left to count watcher": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				c1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().Unix()})
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.OpsEventRateClause{VariationId: "vid1"})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:             "id-0",
+							FeatureId:      "fid-0",
+							Clauses:        []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							ClauseOperator: autoopsproto.AutoOpsRule_AND,
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: and: prometheus clause is left to prometheus watcher": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				c1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().Unix()})
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.PrometheusClause{Query: "up", LookbackSeconds: 60})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:             "id-0",
+							FeatureId:      "fid-0",
+							Clauses:        []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							ClauseOperator: autoopsproto.AutoOpsRule_AND,
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: and: assess: true": {
+			setup: func(w *datetimeWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				c1, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().Unix()})
+				require.NoError(t, err)
+				c2, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().AddDate(0, 0, -1).Unix()})
+				require.NoError(t, err)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:             "id-0",
+							FeatureId:      "fid-0",
+							Clauses:        []*autoopsproto.Clause{{Id: "c1", Clause: c1}, {Id: "c2", Clause: c2}},
+							ClauseOperator: autoopsproto.AutoOpsRule_AND,
+						}},
+					},
+				)
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 			},
 			expectedErr: nil,
 		},
@@ -0,0 +1,210 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	environmentdomain "github.com/bucketeer-io/bucketeer/pkg/environment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/prometheus"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+const maxPrometheusQueryStep = time.Minute
+
+type prometheusWatcher struct {
+	environmentLister targetstore.EnvironmentLister
+	autoOpsRuleLister targetstore.AutoOpsRuleLister
+	prometheusClient  prometheus.Client
+	autoOpsExecutor   executor.AutoOpsExecutor
+	opts              *options
+	logger            *zap.Logger
+}
+
+func NewPrometheusWatcher(
+	targetStore targetstore.TargetStore,
+	prometheusClient prometheus.Client,
+	autoOpsExecutor executor.AutoOpsExecutor,
+	opts ...Option,
+) job.Job {
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &prometheusWatcher{
+		environmentLister: targetStore,
+		autoOpsRuleLister: targetStore,
+		prometheusClient:  prometheusClient,
+		autoOpsExecutor:   autoOpsExecutor,
+		opts:              dopts,
+		logger:            dopts.logger.Named("prometheus-watcher"),
+	}
+}
+
+func (w *prometheusWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments := w.environmentLister.GetEnvironments(ctx)
+	for _, env := range environments {
+		autoOpsRules := w.autoOpsRuleLister.GetAutoOpsRules(ctx, env.Namespace)
+		for _, a := range autoOpsRules {
+			trigger, err := w.assessAutoOpsRule(ctx, env, a)
+			if err != nil {
+				lastErr = err
+			}
+			if trigger == nil {
+				continue
+			}
+			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id, trigger); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return
+}
+
+// assessAutoOpsRule returns the trigger of the rule, or nil if the rule is not satisfied.
+func (w *prometheusWatcher) assessAutoOpsRule(
+	ctx context.Context,
+	env *environmentdomain.Environment,
+	a *autoopsdomain.AutoOpsRule,
+) (*autoopsproto.ExecutionTrigger, error) {
+	prometheusClauses, err := a.ExtractPrometheusClauses()
+	if err != nil {
+		w.logger.Error("Failed to extract prometheus clauses", zap.Error(err),
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+		)
+		return nil, err
+	}
+	if len(prometheusClauses) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	var lastErr error
+	var trigger *autoopsproto.ExecutionTrigger
+	results := make(map[string]bool, len(prometheusClauses))
+	for id, c := range prometheusClauses {
+		series, err := w.assessClause(ctx, c, now)
+		if err != nil {
+			w.logger.Error("Failed to assess prometheus clause", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+				zap.String("featureId", a.FeatureId),
+				zap.String("autoOpsRuleId", a.Id),
+				zap.Any("prometheusClause", c),
+			)
+			lastErr = err
+			continue
+		}
+		if series == nil {
+			continue
+		}
+		w.logger.Info("Clause satisfies condition",
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+			zap.Any("prometheusClause", c),
+		)
+		results[id] = true
+		trigger = &autoopsproto.ExecutionTrigger{
+			ClauseId:        id,
+			PrometheusValue: series.Values[len(series.Values)-1],
+		}
+	}
+	if a.ClauseOperator == autoopsproto.AutoOpsRule_AND {
+		datetimeResults, err := a.AssessDatetimeClauses(now.Unix())
+		if err != nil {
+			w.logger.Error("Failed to assess datetime clauses", zap.Error(err),
+				zap.String("environmentNamespace", env.Namespace),
+				zap.String("featureId", a.FeatureId),
+				zap.String("autoOpsRuleId", a.Id),
+			)
+			return nil, err
+		}
+		for id, r := range datetimeResults {
+			results[id] = r
+		}
+	}
+	if !a.Assess(results) {
+		return nil, lastErr
+	}
+	w.logger.Info("Rule satisfies condition",
+		zap.String("environmentNamespace", env.Namespace),
+		zap.String("featureId", a.FeatureId),
+		zap.String("autoOpsRuleId", a.Id),
+	)
+	return trigger, lastErr
+}
+
+// assessClause queries the samples over the lookback window of the clause
+// and returns the first series that met the threshold with all of its samples,
+// or nil if no series met it.
+func (w *prometheusWatcher) assessClause(
+	ctx context.Context,
+	c *autoopsproto.PrometheusClause,
+	now time.Time,
+) (*prometheus.Series, error) {
+	lookback := time.Duration(c.LookbackSeconds) * time.Second
+	step := maxPrometheusQueryStep
+	if lookback < step {
+		step = lookback
+	}
+	series, err := w.prometheusClient.QueryRange(ctx, c.Query, now.Add(-lookback), now, step)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range series {
+		if w.assessSeries(c, s) {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (w *prometheusWatcher) assessSeries(c *autoopsproto.PrometheusClause, s *prometheus.Series) bool {
+	if len(s.Values) == 0 {
+		return false
+	}
+	for _, v := range s.Values {
+		if math.IsNaN(v) {
+			return false
+		}
+		switch c.Operator {
+		case autoopsproto.PrometheusClause_GREATER_OR_EQUAL:
+			if v < c.Threshold {
+				return false
+			}
+		case autoopsproto.PrometheusClause_LESS_OR_EQUAL:
+			if v > c.Threshold {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	pb "github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	environmentdomain "github.com/bucketeer-io/bucketeer/pkg/environment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	executormock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor/mock"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/prometheus"
+	targetstoremock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore/mock"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+)
+
+func TestNewPrometheusWatcher(t *testing.T) {
+	w := NewPrometheusWatcher(nil, nil, nil)
+	assert.IsType(t, &prometheusWatcher{}, w)
+}
+
+func newPrometheusWatcherWithFakeServer(
+	t *testing.T,
+	mockController *gomock.Controller,
+	serverURL string,
+) *prometheusWatcher {
+	logger, err := log.NewLogger()
+	require.NoError(t, err)
+	client, err := prometheus.NewClient(serverURL)
+	require.NoError(t, err)
+	return &prometheusWatcher{
+		environmentLister: targetstoremock.NewMockEnvironmentLister(mockController),
+		autoOpsRuleLister: targetstoremock.NewMockAutoOpsRuleLister(mockController),
+		prometheusClient:  client,
+		autoOpsExecutor:   executormock.NewMockAutoOpsExecutor(mockController),
+		logger:            logger,
+		opts: &options{
+			timeout: time.Minute,
+		},
+	}
+}
+
+func TestRunPrometheusWatcher(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	const (
+		errorRateQuery = "sum(rate(http_requests_total{code=~\"5..\"}[1m]))"
+		badQuery       = "sum(rate("
+	)
+	responses := map[string]struct {
+		statusCode int
+		body       string
+	}{
+		errorRateQuery: {
+			statusCode: http.StatusOK,
+			body: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{},"values":[[1600000000,"0.2"],[1600000060,"0.3"]]}]}}`,
+		},
+		badQuery: {
+			statusCode: http.StatusBadRequest,
+			body:       `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[r.URL.Query().Get("query")]
+		w.WriteHeader(resp.statusCode)
+		w.Write([]byte(resp.body)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	marshal := func(t *testing.T, id string, c pb.Message) *autoopsproto.Clause {
+		a, err := ptypes.MarshalAny(c)
+		require.NoError(t, err)
+		return &autoopsproto.Clause{Id: id, Clause: a}
+	}
+	patterns := map[string]struct {
+		clauses        func(t *testing.T) []*autoopsproto.Clause
+		clauseOperator autoopsproto.AutoOpsRule_ClauseOperator
+		executed       bool
+		expectedErr    error
+	}{
+		"success: no prometheus clauses": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.DatetimeClause{Time: time.Now().Unix()}),
+				}
+			},
+		},
+		"err: query failed": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.PrometheusClause{
+						Query:           badQuery,
+						LookbackSeconds: 300,
+					}),
+				}
+			},
+			expectedErr: prometheus.ErrQueryFailed,
+		},
+		"success: assess: false": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.PrometheusClause{
+						Query:           errorRateQuery,
+						Operator:        autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+						Threshold:       0.25,
+						LookbackSeconds: 300,
+					}),
+				}
+			},
+		},
+		"success: assess: true": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.PrometheusClause{
+						Query:           errorRateQuery,
+						Operator:        autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+						Threshold:       0.2,
+						LookbackSeconds: 300,
+					}),
+				}
+			},
+			executed: true,
+		},
+		"success: and: assess: false": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.PrometheusClause{
+						Query:           errorRateQuery,
+						Operator:        autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+						Threshold:       0.2,
+						LookbackSeconds: 300,
+					}),
+					marshal(t, "c2", &autoopsproto.DatetimeClause{Time: time.Now().AddDate(0, 0, 1).Unix()}),
+				}
+			},
+			clauseOperator: autoopsproto.AutoOpsRule_AND,
+		},
+		"success: and: assess: true": {
+			clauses: func(t *testing.T) []*autoopsproto.Clause {
+				return []*autoopsproto.Clause{
+					marshal(t, "c1", &autoopsproto.PrometheusClause{
+						Query:           errorRateQuery,
+						Operator:        autoopsproto.PrometheusClause_LESS_OR_EQUAL,
+						Threshold:       0.3,
+						LookbackSeconds: 300,
+					}),
+					marshal(t, "c2", &autoopsproto.DatetimeClause{Time: time.Now().Unix()}),
+				}
+			},
+			clauseOperator: autoopsproto.AutoOpsRule_AND,
+			executed:       true,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newPrometheusWatcherWithFakeServer(t, mockController, server.URL)
+			w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+				[]*environmentdomain.Environment{
+					{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+				},
+			)
+			w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+				[]*autoopsdomain.AutoOpsRule{
+					{AutoOpsRule: &autoopsproto.AutoOpsRule{
+						Id:             "id-0",
+						FeatureId:      "fid-0",
+						Clauses:        p.clauses(t),
+						ClauseOperator: p.clauseOperator,
+					}},
+				},
+			)
+			if p.executed {
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(gomock.Any(), "ns0", "id-0", gomock.Any()).Return(nil)
+			}
+			err := w.Run(context.Background())
+			assert.True(t, errors.Is(err, p.expectedErr), err)
+		})
+	}
+}
+
+func TestPrometheusWatcherAssessSeries(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := map[string]struct {
+		clause   *autoopsproto.PrometheusClause
+		values   []float64
+		expected bool
+	}{
+		"false: no samples": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+				Threshold: 1,
+			},
+			values:   []float64{},
+			expected: false,
+		},
+		"false: NaN": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+				Threshold: 1,
+			},
+			values:   []float64{1, math.NaN()},
+			expected: false,
+		},
+		"false: greater or equal": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+				Threshold: 1,
+			},
+			values:   []float64{1, 0.9, 2},
+			expected: false,
+		},
+		"true: greater or equal": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_GREATER_OR_EQUAL,
+				Threshold: 1,
+			},
+			values:   []float64{1, 1.5, 2},
+			expected: true,
+		},
+		"false: less or equal": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_LESS_OR_EQUAL,
+				Threshold: 0.99,
+			},
+			values:   []float64{0.98, 0.995},
+			expected: false,
+		},
+		"true: less or equal": {
+			clause: &autoopsproto.PrometheusClause{
+				Operator:  autoopsproto.PrometheusClause_LESS_OR_EQUAL,
+				Threshold: 0.99,
+			},
+			values:   []float64{0.98, 0.99},
+			expected: true,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newPrometheusWatcherWithFakeServer(t, mockController, "http://localhost:9090")
+			actual := w.assessSeries(p.clause, &prometheus.Series{Values: p.values})
+			assert.Equal(t, p.expected, actual)
+		})
+	}
+}
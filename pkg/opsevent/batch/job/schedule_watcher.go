@@ -0,0 +1,110 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	environmentdomain "github.com/bucketeer-io/bucketeer/pkg/environment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/job"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+type scheduleWatcher struct {
+	environmentLister targetstore.EnvironmentLister
+	autoOpsRuleLister targetstore.AutoOpsRuleLister
+	autoOpsExecutor   executor.AutoOpsExecutor
+	opts              *options
+	logger            *zap.Logger
+}
+
+func NewScheduleWatcher(
+	targetStore targetstore.TargetStore,
+	autoOpsExecutor executor.AutoOpsExecutor,
+	opts ...Option) job.Job {
+
+	dopts := &options{
+		timeout: 5 * time.Minute,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	return &scheduleWatcher{
+		environmentLister: targetStore,
+		autoOpsRuleLister: targetStore,
+		autoOpsExecutor:   autoOpsExecutor,
+		opts:              dopts,
+		logger:            dopts.logger.Named("schedule-watcher"),
+	}
+}
+
+func (w *scheduleWatcher) Run(ctx context.Context) (lastErr error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.timeout)
+	defer cancel()
+	environments := w.environmentLister.GetEnvironments(ctx)
+	for _, env := range environments {
+		autoOpsRules := w.autoOpsRuleLister.GetAutoOpsRules(ctx, env.Namespace)
+		for _, a := range autoOpsRules {
+			if !a.HasScheduleClause() {
+				continue
+			}
+			trigger, err := w.assessAutoOpsRule(env, a)
+			if err != nil {
+				lastErr = err
+			}
+			if trigger == nil {
+				continue
+			}
+			if err = w.autoOpsExecutor.Execute(ctx, env.Namespace, a.Id, trigger); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return
+}
+
+// assessAutoOpsRule returns the trigger of the transition that has come, or nil if there is none.
+// The auto ops service decides the transition again when it executes the rule.
+func (w *scheduleWatcher) assessAutoOpsRule(
+	env *environmentdomain.Environment,
+	a *autoopsdomain.AutoOpsRule,
+) (*autoopsproto.ExecutionTrigger, error) {
+	transition, err := a.DueScheduleTransition(time.Now().Unix())
+	if err != nil {
+		w.logger.Error("Failed to assess schedule clauses", zap.Error(err),
+			zap.String("environmentNamespace", env.Namespace),
+			zap.String("featureId", a.FeatureId),
+			zap.String("autoOpsRuleId", a.Id),
+		)
+		return nil, err
+	}
+	if transition == nil {
+		return nil, nil
+	}
+	w.logger.Info("Schedule transition has come",
+		zap.String("environmentNamespace", env.Namespace),
+		zap.String("featureId", a.FeatureId),
+		zap.String("autoOpsRuleId", a.Id),
+		zap.Any("scheduleTransition", transition),
+	)
+	return &autoopsproto.ExecutionTrigger{ClauseId: transition.ClauseId, Time: transition.Time}, nil
+}
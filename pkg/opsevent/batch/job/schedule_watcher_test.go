@@ -0,0 +1,152 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	autoopsdomain "github.com/bucketeer-io/bucketeer/pkg/autoops/domain"
+	environmentdomain "github.com/bucketeer-io/bucketeer/pkg/environment/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/log"
+	executormock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor/mock"
+	targetstoremock "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore/mock"
+	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
+	environmentproto "github.com/bucketeer-io/bucketeer/proto/environment"
+)
+
+func TestNewScheduleWatcher(t *testing.T) {
+	w := NewScheduleWatcher(nil, nil)
+	assert.IsType(t, &scheduleWatcher{}, w)
+}
+
+func newScheduleWatcherWithMock(t *testing.T, mockController *gomock.Controller) *scheduleWatcher {
+	logger, err := log.NewLogger()
+	require.NoError(t, err)
+	return &scheduleWatcher{
+		environmentLister: targetstoremock.NewMockEnvironmentLister(mockController),
+		autoOpsRuleLister: targetstoremock.NewMockAutoOpsRuleLister(mockController),
+		autoOpsExecutor:   executormock.NewMockAutoOpsExecutor(mockController),
+		logger:            logger,
+		opts: &options{
+			timeout: time.Minute,
+		},
+	}
+}
+
+func TestRunScheduleWatcher(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	sc, err := ptypes.MarshalAny(&autoopsproto.ScheduleClause{
+		EnableCron:  "0 18 * * 1-5",
+		DisableCron: "0 22 * * 1-5",
+		TimeZone:    "Asia/Tokyo",
+	})
+	require.NoError(t, err)
+	dc, err := ptypes.MarshalAny(&autoopsproto.DatetimeClause{Time: time.Now().Unix()})
+	require.NoError(t, err)
+	patterns := map[string]struct {
+		setup       func(*scheduleWatcher)
+		expectedErr error
+	}{
+		"success: no schedule clause": {
+			setup: func(w *scheduleWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:        "id-0",
+							FeatureId: "fid-0",
+							Clauses:   []*autoopsproto.Clause{{Id: "c0", Clause: dc}},
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: no transition has come": {
+			setup: func(w *scheduleWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:          "id-0",
+							FeatureId:   "fid-0",
+							Clauses:     []*autoopsproto.Clause{{Id: "c0", Clause: sc}},
+							TriggeredAt: time.Now().Unix(),
+						}},
+					},
+				)
+			},
+			expectedErr: nil,
+		},
+		"success: transition has come": {
+			setup: func(w *scheduleWatcher) {
+				w.environmentLister.(*targetstoremock.MockEnvironmentLister).EXPECT().GetEnvironments(gomock.Any()).Return(
+					[]*environmentdomain.Environment{
+						{Environment: &environmentproto.Environment{Id: "ns0", Namespace: "ns0"}},
+					},
+				)
+				w.autoOpsRuleLister.(*targetstoremock.MockAutoOpsRuleLister).EXPECT().GetAutoOpsRules(gomock.Any(), "ns0").Return(
+					[]*autoopsdomain.AutoOpsRule{
+						{AutoOpsRule: &autoopsproto.AutoOpsRule{
+							Id:        "id-0",
+							FeatureId: "fid-0",
+							Clauses:   []*autoopsproto.Clause{{Id: "c0", Clause: sc}},
+						}},
+					},
+				)
+				w.autoOpsExecutor.(*executormock.MockAutoOpsExecutor).EXPECT().Execute(
+					gomock.Any(), "ns0", "id-0", gomock.Any(),
+				).DoAndReturn(func(
+					ctx context.Context,
+					environmentNamespace, ruleID string,
+					trigger *autoopsproto.ExecutionTrigger,
+				) error {
+					assert.Equal(t, "c0", trigger.ClauseId)
+					assert.NotZero(t, trigger.Time)
+					return nil
+				})
+			},
+			expectedErr: nil,
+		},
+	}
+	for msg, p := range patterns {
+		t.Run(msg, func(t *testing.T) {
+			w := newScheduleWatcherWithMock(t, mockController)
+			if p.setup != nil {
+				p.setup(w)
+			}
+			err := w.Run(context.Background())
+			assert.Equal(t, p.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const queryRangePath = "/api/v1/query_range"
+
+var (
+	ErrQueryFailed          = errors.New("prometheus: query failed")
+	ErrUnexpectedResultType = errors.New("prometheus: unexpected result type")
+	ErrInvalidSample        = errors.New("prometheus: invalid sample")
+)
+
+type options struct {
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+type Option func(*options)
+
+func WithTimeout(t time.Duration) Option {
+	return func(opts *options) {
+		opts.timeout = t
+	}
+}
+
+func WithLogger(l *zap.Logger) Option {
+	return func(opts *options) {
+		opts.logger = l
+	}
+}
+
+// Series is a time series returned by a range query.
+type Series struct {
+	Metric map[string]string
+	Values []float64
+}
+
+// Client queries a Prometheus-compatible HTTP API.
+type Client interface {
+	QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]*Series, error)
+}
+
+type client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+func NewClient(baseURL string, opts ...Option) (Client, error) {
+	dopts := &options{
+		timeout: 30 * time.Second,
+		logger:  zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(dopts)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: dopts.timeout},
+		logger:     dopts.logger.Named("prometheus"),
+	}, nil
+}
+
+type response struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string   `json:"metric"`
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (c *client) QueryRange(
+	ctx context.Context,
+	query string,
+	start, end time.Time,
+	step time.Duration,
+) ([]*Series, error) {
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, queryRangePath)
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", formatTime(start))
+	params.Set("end", formatTime(end))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Error("Failed to query prometheus", zap.Error(err), zap.String("query", query))
+		return nil, err
+	}
+	defer resp.Body.Close()
+	r := &response{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%w: status code %d", ErrQueryFailed, resp.StatusCode)
+		}
+		return nil, err
+	}
+	if r.Status != "success" {
+		c.logger.Error("Prometheus query failed",
+			zap.String("query", query),
+			zap.Int("statusCode", resp.StatusCode),
+			zap.String("errorType", r.ErrorType),
+			zap.String("error", r.Error),
+		)
+		return nil, fmt.Errorf("%w: %s: %s", ErrQueryFailed, r.ErrorType, r.Error)
+	}
+	if r.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedResultType, r.Data.ResultType)
+	}
+	series := make([]*Series, 0, len(r.Data.Result))
+	for _, result := range r.Data.Result {
+		s := &Series{
+			Metric: result.Metric,
+			Values: make([]float64, 0, len(result.Values)),
+		}
+		for _, sample := range result.Values {
+			v, err := parseSampleValue(sample)
+			if err != nil {
+				return nil, err
+			}
+			s.Values = append(s.Values, v)
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// parseSampleValue parses a sample given as a pair of the timestamp and the value in a string,
+// which can also be NaN or Inf.
+func parseSampleValue(sample []json.RawMessage) (float64, error) {
+	if len(sample) != 2 {
+		return 0, ErrInvalidSample
+	}
+	var s string
+	if err := json.Unmarshal(sample[1], &s); err != nil {
+		return 0, ErrInvalidSample
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidSample
+	}
+	return v, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
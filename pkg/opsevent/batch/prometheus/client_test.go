@@ -0,0 +1,117 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+	c, err := NewClient("http://localhost:9090")
+	require.NoError(t, err)
+	assert.IsType(t, &client{}, c)
+	_, err = NewClient("http://[::1")
+	assert.Error(t, err)
+}
+
+func TestQueryRange(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1600000000, 0)
+	end := start.Add(5 * time.Minute)
+	patterns := map[string]struct {
+		statusCode  int
+		body        string
+		expected    []*Series
+		expectedErr error
+	}{
+		"err: bad data": {
+			statusCode:  http.StatusBadRequest,
+			body:        `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			expectedErr: ErrQueryFailed,
+		},
+		"err: not json": {
+			statusCode:  http.StatusBadGateway,
+			body:        `bad gateway`,
+			expectedErr: ErrQueryFailed,
+		},
+		"err: unexpected result type": {
+			statusCode:  http.StatusOK,
+			body:        `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			expectedErr: ErrUnexpectedResultType,
+		},
+		"err: invalid sample": {
+			statusCode: http.StatusOK,
+			body: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{},"values":[[1600000000,1]]}]}}`,
+			expectedErr: ErrInvalidSample,
+		},
+		"success": {
+			statusCode: http.StatusOK,
+			body: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{"code":"500"},"values":[[1600000000,"0.1"],[1600000060,"NaN"]]},` +
+				`{"metric":{"code":"503"},"values":[]}]}}`,
+			expected: []*Series{
+				{Metric: map[string]string{"code": "500"}, Values: []float64{0.1, math.NaN()}},
+				{Metric: map[string]string{"code": "503"}, Values: []float64{}},
+			},
+		},
+	}
+	for msg, p := range patterns {
+		p := p
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/prometheus/api/v1/query_range", r.URL.Path)
+				assert.Equal(t, "sum(rate(errors[1m]))", r.URL.Query().Get("query"))
+				assert.Equal(t, "1600000000", r.URL.Query().Get("start"))
+				assert.Equal(t, "1600000300", r.URL.Query().Get("end"))
+				assert.Equal(t, "60", r.URL.Query().Get("step"))
+				w.WriteHeader(p.statusCode)
+				w.Write([]byte(p.body)) // nolint:errcheck
+			}))
+			defer server.Close()
+			c, err := NewClient(server.URL + "/prometheus")
+			require.NoError(t, err)
+			actual, err := c.QueryRange(context.Background(), "sum(rate(errors[1m]))", start, end, time.Minute)
+			if p.expectedErr != nil {
+				assert.True(t, errors.Is(err, p.expectedErr), err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(p.expected), len(actual))
+			for i, s := range actual {
+				assert.Equal(t, p.expected[i].Metric, s.Metric)
+				require.Equal(t, len(p.expected[i].Values), len(s.Values))
+				for j, v := range s.Values {
+					if math.IsNaN(p.expected[i].Values[j]) {
+						assert.True(t, math.IsNaN(v))
+						continue
+					}
+					assert.Equal(t, p.expected[i].Values[j], v)
+				}
+			}
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/bucketeer-io/bucketeer/pkg/metrics"
 	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/executor"
 	opseventjob "github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/job"
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/prometheus"
 	"github.com/bucketeer-io/bucketeer/pkg/opsevent/batch/targetstore"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc"
 	"github.com/bucketeer-io/bucketeer/pkg/rpc/client"
@@ -42,23 +43,26 @@ const command = "batch"
 
 type batch struct {
 	*kingpin.CmdClause
-	port                    *int
-	project                 *string
-	mysqlUser               *string
-	mysqlPass               *string
-	mysqlHost               *string
-	mysqlPort               *int
-	mysqlDBName             *string
-	environmentService      *string
-	autoOpsService          *string
-	eventCounterService     *string
-	featureService          *string
-	certPath                *string
-	keyPath                 *string
-	serviceTokenPath        *string
-	refreshInterval         *time.Duration
-	scheduleCountWatcher    *string
-	scheduleDatetimeWatcher *string
+	port                      *int
+	project                   *string
+	mysqlUser                 *string
+	mysqlPass                 *string
+	mysqlHost                 *string
+	mysqlPort                 *int
+	mysqlDBName               *string
+	environmentService        *string
+	autoOpsService            *string
+	eventCounterService       *string
+	featureService            *string
+	prometheusURL             *string
+	certPath                  *string
+	keyPath                   *string
+	serviceTokenPath          *string
+	refreshInterval           *time.Duration
+	scheduleCountWatcher      *string
+	scheduleDatetimeWatcher   *string
+	schedulePrometheusWatcher *string
+	scheduleScheduleWatcher   *string
 }
 
 func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
@@ -88,6 +92,10 @@ func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 			"feature-service",
 			"bucketeer-feature-service address.",
 		).Default("feature:9090").String(),
+		prometheusURL: cmd.Flag(
+			"prometheus-url",
+			"Prometheus-compatible HTTP API address. The prometheus watcher is disabled if empty.",
+		).Default("").String(),
 		certPath:         cmd.Flag("cert", "Path to TLS certificate.").Required().String(),
 		keyPath:          cmd.Flag("key", "Path to TLS key.").Required().String(),
 		serviceTokenPath: cmd.Flag("service-token", "Path to service token.").Required().String(),
@@ -103,6 +111,14 @@ func RegisterCommand(r cli.CommandRegistry, p cli.ParentCommand) cli.Command {
 			"schedule-datetime-watcher",
 			"Cron style schedule for datetime watcher.",
 		).Default("0,10,20,30,40,50 * * * * *").String(),
+		schedulePrometheusWatcher: cmd.Flag(
+			"schedule-prometheus-watcher",
+			"Cron style schedule for prometheus watcher.",
+		).Default("0 * * * * *").String(),
+		scheduleScheduleWatcher: cmd.Flag(
+			"schedule-schedule-watcher",
+			"Cron style schedule for schedule watcher.",
+		).Default("0,10,20,30,40,50 * * * * *").String(),
 	}
 	r.RegisterCommand(batch)
 	return batch
@@ -174,6 +190,14 @@ func (b *batch) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Lo
 	defer targetStore.Stop()
 	go targetStore.Run()
 
+	var prometheusClient prometheus.Client
+	if *b.prometheusURL != "" {
+		prometheusClient, err = prometheus.NewClient(*b.prometheusURL, prometheus.WithLogger(logger))
+		if err != nil {
+			return err
+		}
+	}
+
 	autoOpsExecutor := executor.NewAutoOpsExecutor(
 		autoOpsClient,
 		executor.WithLogger(logger),
@@ -186,6 +210,7 @@ func (b *batch) Run(ctx context.Context, metrics metrics.Metrics, logger *zap.Lo
 		targetStore,
 		eventCounterClient,
 		featureClient,
+		prometheusClient,
 		autoOpsExecutor,
 		logger,
 	)
@@ -219,14 +244,16 @@ func (b *batch) registerJobs(
 	targetStore targetstore.TargetStore,
 	eventCounterClient ecclient.Client,
 	featureClient ftclient.Client,
+	prometheusClient prometheus.Client,
 	autoOpsExecutor executor.AutoOpsExecutor,
 	logger *zap.Logger) error {
 
-	jobs := []struct {
+	type cronJob struct {
 		name string
 		cron string
 		job  job.Job
-	}{
+	}
+	jobs := []cronJob{
 		{
 			cron: *b.scheduleCountWatcher,
 			name: "ops_event_count_watcher",
@@ -248,6 +275,27 @@ func (b *batch) registerJobs(
 				opseventjob.WithTimeout(5*time.Minute),
 				opseventjob.WithLogger(logger)),
 		},
+		{
+			cron: *b.scheduleScheduleWatcher,
+			name: "schedule_watcher",
+			job: opseventjob.NewScheduleWatcher(
+				targetStore,
+				autoOpsExecutor,
+				opseventjob.WithTimeout(5*time.Minute),
+				opseventjob.WithLogger(logger)),
+		},
+	}
+	if prometheusClient != nil {
+		jobs = append(jobs, cronJob{
+			cron: *b.schedulePrometheusWatcher,
+			name: "prometheus_watcher",
+			job: opseventjob.NewPrometheusWatcher(
+				targetStore,
+				prometheusClient,
+				autoOpsExecutor,
+				opseventjob.WithTimeout(5*time.Minute),
+				opseventjob.WithLogger(logger)),
+		})
 	}
 	for i := range jobs {
 		if err := m.AddCronJob(jobs[i].name, jobs[i].cron, jobs[i].job); err != nil {
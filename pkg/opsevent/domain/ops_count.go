@@ -17,6 +17,9 @@ package domain
 import (
 	"time"
 
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+
+	"github.com/bucketeer-io/bucketeer/pkg/uuid"
 	autoopsproto "github.com/bucketeer-io/bucketeer/proto/autoops"
 )
 
@@ -42,3 +45,15 @@ func NewOpsCount(
 		},
 	}
 }
+
+// NewHistory returns a copy of the ops count with its own id,
+// so that every assessment of the clause can be kept.
+func (oc *OpsCount) NewHistory() (*OpsCount, error) {
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	h := proto.Clone(oc.OpsCount).(*autoopsproto.OpsCount)
+	h.Id = id.String()
+	return &OpsCount{OpsCount: h}, nil
+}
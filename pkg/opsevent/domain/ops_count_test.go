@@ -31,3 +31,17 @@ func TestNewOpsCount(t *testing.T) {
 	assert.Equal(t, int64(2), oc.EvaluationCount)
 	assert.NotEqual(t, int64(0), oc.UpdatedAt)
 }
+
+func TestNewHistory(t *testing.T) {
+	t.Parallel()
+	oc := NewOpsCount("fid", "aid", "cid", int64(1), int64(2))
+	oc.Satisfied = true
+	h, err := oc.NewHistory()
+	assert.NoError(t, err)
+	assert.NotEqual(t, oc.Id, h.Id)
+	assert.Equal(t, "cid", h.ClauseId)
+	assert.Equal(t, oc.UpdatedAt, h.UpdatedAt)
+	assert.True(t, h.Satisfied)
+	h.OpsEventCount = 3
+	assert.Equal(t, int64(1), oc.OpsEventCount)
+}
@@ -52,16 +52,26 @@ func (s *opsCountStorage) UpsertOpsCount(ctx context.Context, environmentNamespa
 			ops_event_count,
 			evaluation_count,
 			feature_id,
+			satisfied,
+			baseline_ops_event_count,
+			baseline_evaluation_count,
+			z_score,
+			p_value,
 			environment_namespace
 		) VALUES (
-			?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		) ON DUPLICATE KEY UPDATE
 			auto_ops_rule_id = VALUES(auto_ops_rule_id),
 			clause_id = VALUES(clause_id),
 			updated_at = VALUES(updated_at),
 			ops_event_count = VALUES(ops_event_count),
 			evaluation_count = VALUES(evaluation_count),
-			feature_id = VALUES(feature_id)
+			feature_id = VALUES(feature_id),
+			satisfied = VALUES(satisfied),
+			baseline_ops_event_count = VALUES(baseline_ops_event_count),
+			baseline_evaluation_count = VALUES(baseline_evaluation_count),
+			z_score = VALUES(z_score),
+			p_value = VALUES(p_value)
 	`
 	_, err := s.qe.ExecContext(
 		ctx,
@@ -73,6 +83,11 @@ func (s *opsCountStorage) UpsertOpsCount(ctx context.Context, environmentNamespa
 		oc.OpsEventCount,
 		oc.EvaluationCount,
 		oc.FeatureId,
+		oc.Satisfied,
+		oc.BaselineOpsEventCount,
+		oc.BaselineEvaluationCount,
+		oc.ZScore,
+		oc.PValue,
 		environmentNamespace,
 	)
 	if err != nil {
@@ -98,7 +113,12 @@ func (s *opsCountStorage) ListOpsCounts(
 			updated_at,
 			ops_event_count,
 			evaluation_count,
-			feature_id
+			feature_id,
+			satisfied,
+			baseline_ops_event_count,
+			baseline_evaluation_count,
+			z_score,
+			p_value
 		FROM
 			ops_count
 		%s %s %s
@@ -120,6 +140,11 @@ func (s *opsCountStorage) ListOpsCounts(
 			&opsCount.OpsEventCount,
 			&opsCount.EvaluationCount,
 			&opsCount.FeatureId,
+			&opsCount.Satisfied,
+			&opsCount.BaselineOpsEventCount,
+			&opsCount.BaselineEvaluationCount,
+			&opsCount.ZScore,
+			&opsCount.PValue,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -0,0 +1,153 @@
+// Copyright 2022 The Bucketeer Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bucketeer-io/bucketeer/pkg/opsevent/domain"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql"
+	"github.com/bucketeer-io/bucketeer/pkg/storage/v2/mysql/mock"
+	proto "github.com/bucketeer-io/bucketeer/proto/autoops"
+)
+
+func TestNewOpsCountHistoryStorage(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	db := NewOpsCountHistoryStorage(mock.NewMockQueryExecer(mockController))
+	assert.IsType(t, &opsCountHistoryStorage{}, db)
+}
+
+func TestCreateOpsCountHistory(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	patterns := []struct {
+		setup                func(*opsCountHistoryStorage)
+		input                *domain.OpsCount
+		environmentNamespace string
+		expectedErr          error
+	}{
+		{
+			setup: func(s *opsCountHistoryStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			input:                &domain.OpsCount{OpsCount: &proto.OpsCount{}},
+			environmentNamespace: "ns",
+			expectedErr:          errors.New("error"),
+		},
+		{
+			setup: func(s *opsCountHistoryStorage) {
+				result := mock.NewMockResult(mockController)
+				s.qe.(*mock.MockQueryExecer).EXPECT().ExecContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(result, nil)
+			},
+			input:                &domain.OpsCount{OpsCount: &proto.OpsCount{}},
+			environmentNamespace: "ns",
+			expectedErr:          nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newOpsCountHistoryStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		err := storage.CreateOpsCountHistory(context.Background(), p.environmentNamespace, p.input)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func TestListOpsCountHistory(t *testing.T) {
+	t.Parallel()
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	patterns := []struct {
+		setup          func(*opsCountHistoryStorage)
+		whereParts     []mysql.WherePart
+		orders         []*mysql.Order
+		limit          int
+		offset         int
+		expected       []*proto.OpsCount
+		expectedCursor int
+		expectedErr    error
+	}{
+		{
+			setup: func(s *opsCountHistoryStorage) {
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(nil, errors.New("error"))
+			},
+			expected:       nil,
+			expectedCursor: 0,
+			expectedErr:    errors.New("error"),
+		},
+		{
+			setup: func(s *opsCountHistoryStorage) {
+				rows := mock.NewMockRows(mockController)
+				rows.EXPECT().Close().Return(nil)
+				rows.EXPECT().Next().Return(false)
+				rows.EXPECT().Err().Return(nil)
+				s.qe.(*mock.MockQueryExecer).EXPECT().QueryContext(
+					gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(rows, nil)
+			},
+			whereParts: []mysql.WherePart{
+				mysql.NewFilter("clause_id", "=", "cid"),
+			},
+			orders: []*mysql.Order{
+				mysql.NewOrder("updated_at", mysql.OrderDirectionDesc),
+			},
+			limit:          10,
+			offset:         5,
+			expected:       []*proto.OpsCount{},
+			expectedCursor: 5,
+			expectedErr:    nil,
+		},
+	}
+	for _, p := range patterns {
+		storage := newOpsCountHistoryStorageWithMock(t, mockController)
+		if p.setup != nil {
+			p.setup(storage)
+		}
+		opsCounts, cursor, err := storage.ListOpsCountHistory(
+			context.Background(),
+			p.whereParts,
+			p.orders,
+			p.limit,
+			p.offset,
+		)
+		assert.Equal(t, p.expected, opsCounts)
+		assert.Equal(t, p.expectedCursor, cursor)
+		assert.Equal(t, p.expectedErr, err)
+	}
+}
+
+func newOpsCountHistoryStorageWithMock(
+	t *testing.T,
+	mockController *gomock.Controller,
+) *opsCountHistoryStorage {
+	t.Helper()
+	return &opsCountHistoryStorage{mock.NewMockQueryExecer(mockController)}
+}
@@ -354,6 +354,7 @@ class ExperimentCalculator:
                     feature_id=feature_id,
                     feature_version=feature_version,
                     variation_ids=variation_ids,
+                    experiment_exposures_only=True,
                 ),
                 self._grpc_timeout,
             )
@@ -388,6 +389,7 @@ class ExperimentCalculator:
                     feature_id=feature_id,
                     feature_version=feature_version,
                     variation_ids=variation_ids,
+                    experiment_exposures_only=True,
                 ),
                 self._grpc_timeout,
             )
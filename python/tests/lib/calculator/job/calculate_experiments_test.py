@@ -48,6 +48,8 @@ def test_get_evaluation_count(mocker):
     actual = ec._get_evaluation_count("", 0, 0, "", 0, [])
     assert actual["vid0"].user_count == 0
     assert actual["vid1"].user_count == 1
+    req = insmock.GetEvaluationCountV2.call_args[0][0]
+    assert req.experiment_exposures_only
 
 
 def test_get_goal_count(mocker):
@@ -64,6 +66,8 @@ def test_get_goal_count(mocker):
 
     assert actual["vid0"].user_count == 0
     assert actual["vid1"].user_count == 1
+    req = insmock.GetGoalCountV2.call_args[0][0]
+    assert req.experiment_exposures_only
 
 
 def test_create_experiment_result(mocker):
@@ -170,6 +174,12 @@ def test_create_experiment_result(mocker):
     ec._now = lambda: now
     experiment = _create_experiment()
     experiment_result = ec._create_experiment_result("ns", experiment)
+    # The users in the layer holdout, excluded by the layer or not allocated
+    # to the experiment must not be counted in the stored result.
+    for call in ec_insmock.GetEvaluationCountV2.call_args_list:
+        assert call[0][0].experiment_exposures_only
+    for call in ec_insmock.GetGoalCountV2.call_args_list:
+        assert call[0][0].experiment_exposures_only
     goal_result = experiment_result.goal_results[0]
     assert goal_result.goal_id == "gid"
     for vr in goal_result.variation_results: